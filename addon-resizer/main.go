@@ -18,6 +18,7 @@ package main
 
 import (
 	"flag"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -44,6 +45,12 @@ var (
 	memoryPerNode        = flag.String("extra-memory", "0Mi", "The amount of memory to add per node.")
 	baseStorage          = flag.String("storage", noValue, "The base storage resource requirement.")
 	storagePerNode       = flag.String("extra-storage", "0Gi", "The amount of storage to add per node.")
+	cpuPerPod            = flag.String("extra-cpu-per-pod", "0", "The amount of CPU to add per pod in the cluster.")
+	memoryPerPod         = flag.String("extra-memory-per-pod", "0Mi", "The amount of memory to add per pod in the cluster.")
+	storagePerPod        = flag.String("extra-storage-per-pod", "0Gi", "The amount of storage to add per pod in the cluster.")
+	cpuFormula           = flag.String("cpu-formula", "", "A formula computing the CPU resource requirement from the numbers of nodes and pods, e.g. \"100m + 1m*nodes + 0.5m*pods\". Overrides --cpu and --extra-cpu.")
+	memoryFormula        = flag.String("memory-formula", "", "A formula computing the memory resource requirement from the numbers of nodes and pods, e.g. \"100Mi + 2Mi*nodes + 100Ki*pods\". Overrides --memory and --extra-memory.")
+	storageFormula       = flag.String("storage-formula", "", "A formula computing the storage resource requirement from the numbers of nodes and pods. Overrides --storage and --extra-storage.")
 	scaleDownDelay       = flag.Duration("scale-down-delay", time.Duration(0), "The time to wait after the addon-resizer start or last scaling operation before the scale down can be performed.")
 	scaleUpDelay         = flag.Duration("scale-up-delay", time.Duration(0), "The time to wait after the addon-resizer start or last scaling operation before the scale up can be performed.")
 	recommendationOffset = flag.Int("recommendation-offset", 10, "A number from range 0-100. When the dependent's resources are rewritten, they are set to the closer end of the range defined by this percentage threshold.")
@@ -55,6 +62,7 @@ var (
 	containerName = flag.String("container", "pod-nanny", "The name of the container to watch. This defaults to the nanny itself.")
 	// Flags to control runtime behavior.
 	pollPeriodMillis = flag.Int("poll-period", 10000, "The time, in milliseconds, to poll the dependent container.")
+	metricsAddress   = flag.String("metrics-address", "", "The address to expose Prometheus metrics on, e.g. \":8080\". Metrics aren't exposed if empty.")
 )
 
 func checkPercentageFlagBounds(flagName string, flagValue int) {
@@ -63,6 +71,14 @@ func checkPercentageFlagBounds(flagName string, flagValue int) {
 	}
 }
 
+func parseFormulaOrDie(flagName, expression string) *nanny.Formula {
+	formula, err := nanny.ParseFormula(expression)
+	if err != nil {
+		log.Fatalf("%s flag is invalid: %v", flagName, err)
+	}
+	return formula
+}
+
 // GetClientOrDie returns a k8s clientset to the request from inside of cluster
 func GetClientOrDie() kubernetes.Interface {
 	config, err := rest.InClusterConfig()
@@ -126,6 +142,8 @@ func main() {
 	log.Infof("Poll period: %+v", pollPeriod)
 	log.Infof("Watching namespace: %s, pod: %s, container: %s.", *podNamespace, *podName, *containerName)
 	log.Infof("cpu: %s, extra_cpu: %s, memory: %s, extra_memory: %s, storage: %s, extra_storage: %s", *baseCPU, *cpuPerNode, *baseMemory, *memoryPerNode, *baseStorage, *storagePerNode)
+	log.Infof("extra_cpu_per_pod: %s, extra_memory_per_pod: %s, extra_storage_per_pod: %s", *cpuPerPod, *memoryPerPod, *storagePerPod)
+	log.Infof("cpu_formula: %q, memory_formula: %q, storage_formula: %q", *cpuFormula, *memoryFormula, *storageFormula)
 	log.Infof("Accepted range +/-%d%%", *acceptanceOffset)
 	log.Infof("Recommended range +/-%d%%", *recommendationOffset)
 
@@ -137,37 +155,60 @@ func main() {
 		kubeClient = GetClientOrDie()
 	}
 
-	k8s := nanny.NewKubernetesClient(kubeClient, *podNamespace, *deployment, *podName, *containerName)
-
 	var resources []nanny.Resource
 
 	// Monitor only the resources specified.
-	if *baseCPU != noValue {
+	if *cpuFormula != "" {
+		resources = append(resources, nanny.Resource{
+			Formula: parseFormulaOrDie("cpu-formula", *cpuFormula),
+			Name:    "cpu",
+		})
+	} else if *baseCPU != noValue {
 		resources = append(resources, nanny.Resource{
 			Base:         resource.MustParse(*baseCPU),
 			ExtraPerNode: resource.MustParse(*cpuPerNode),
+			ExtraPerPod:  resource.MustParse(*cpuPerPod),
 			Name:         "cpu",
 		})
 	}
 
-	if *baseMemory != noValue {
+	if *memoryFormula != "" {
+		resources = append(resources, nanny.Resource{
+			Formula: parseFormulaOrDie("memory-formula", *memoryFormula),
+			Name:    "memory",
+		})
+	} else if *baseMemory != noValue {
 		resources = append(resources, nanny.Resource{
 			Base:         resource.MustParse(*baseMemory),
 			ExtraPerNode: resource.MustParse(*memoryPerNode),
+			ExtraPerPod:  resource.MustParse(*memoryPerPod),
 			Name:         "memory",
 		})
 	}
 
-	if *baseStorage != noValue {
+	if *storageFormula != "" {
+		resources = append(resources, nanny.Resource{
+			Formula: parseFormulaOrDie("storage-formula", *storageFormula),
+			Name:    "storage",
+		})
+	} else if *baseStorage != noValue {
 		resources = append(resources, nanny.Resource{
 			Base:         resource.MustParse(*baseStorage),
 			ExtraPerNode: resource.MustParse(*memoryPerNode),
+			ExtraPerPod:  resource.MustParse(*storagePerPod),
 			Name:         "storage",
 		})
 	}
 
 	log.Infof("Resources: %+v", resources)
 
+	estimator := nanny.Estimator{
+		AcceptanceOffset:     int64(*acceptanceOffset),
+		RecommendationOffset: int64(*recommendationOffset),
+		Resources:            resources,
+	}
+	k8s := nanny.NewKubernetesClient(kubeClient, *podNamespace, *deployment, *podName, *containerName, estimator.UsesPods())
+
 	// handle termination info
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
@@ -178,14 +219,18 @@ func main() {
 		os.Exit(0)
 	}()
 
+	if *metricsAddress != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", nanny.MetricsHandler())
+			log.Fatalf("Failed to serve metrics: %v", http.ListenAndServe(*metricsAddress, mux))
+		}()
+	}
+
 	// Begin nannying.
 	nanny.PollAPIServer(
 		k8s,
-		nanny.Estimator{
-			AcceptanceOffset:     int64(*acceptanceOffset),
-			RecommendationOffset: int64(*recommendationOffset),
-			Resources:            resources,
-		},
+		estimator,
 		pollPeriod,
 		*scaleDownDelay,
 		*scaleUpDelay)
@@ -26,10 +26,13 @@ import (
 	log "github.com/golang/glog"
 )
 
-// Resource defines the name of a resource, the quantity, and the marginal value.
+// Resource defines the name of a resource, the quantity, and the marginal values
+// per node and per pod. If Formula is set, it's used instead of the quantity and
+// the marginal values.
 type Resource struct {
-	Base, ExtraPerNode resource.Quantity
-	Name               api.ResourceName
+	Base, ExtraPerNode, ExtraPerPod resource.Quantity
+	Name                            api.ResourceName
+	Formula                         *Formula
 }
 
 // ResourceListPair is a pair of ResourceLists, denoting a range.
@@ -68,46 +71,81 @@ func getOffsetNodeCount(nodeCount uint64, offset int64, rounder func(float64) fl
 // of valid values for each of the given resources. The lower bound of each
 // interval is computed using the node count equal to numNodes +
 // floor(numNodes * -offset/100). The uppoer bound of each interval is computed
-// using the node count equal to numNodes + ceil(numNodes * offset/100). Note
-// the ordering of the elements of the lower and upper fields is significant.
-// Element N of each field represents the lower and upper bounds, respectively,
-// of the interval for the resource with index N in res.
-func nodesAndOffsetToRange(numNodes uint64, offset int64, res []Resource) ResourceListPair {
-	numNodesMin := getOffsetNodeCount(numNodes, -offset, math.Floor)
-	numNodesMax := getOffsetNodeCount(numNodes, offset, math.Ceil)
+// using the node count equal to numNodes + ceil(numNodes * offset/100). The
+// pod count is offset the same way. Note the ordering of the elements of the
+// lower and upper fields is significant. Element N of each field represents
+// the lower and upper bounds, respectively, of the interval for the resource
+// with index N in res.
+func nodesAndOffsetToRange(numNodes, numPods uint64, offset int64, res []Resource) ResourceListPair {
+	sizeMin := clusterSize{
+		nodes: getOffsetNodeCount(numNodes, -offset, math.Floor),
+		pods:  getOffsetNodeCount(numPods, -offset, math.Floor),
+	}
+	sizeMax := clusterSize{
+		nodes: getOffsetNodeCount(numNodes, offset, math.Ceil),
+		pods:  getOffsetNodeCount(numPods, offset, math.Ceil),
+	}
 	return ResourceListPair{
-		lower: calculateResources(numNodesMin, res),
-		upper: calculateResources(numNodesMax, res),
+		lower: calculateResources(sizeMin, res),
+		upper: calculateResources(sizeMax, res),
 	}
 }
 
 // Computes the acceptable and recommended resource ranges relative to the base
-// resource values for a cluster with the specified number of nodes.
-func (e Estimator) scaleWithNodes(numNodes uint64) *EstimatorResult {
+// resource values for a cluster with the specified number of nodes and pods.
+func (e Estimator) scaleWithNodesAndPods(numNodes, numPods uint64) *EstimatorResult {
 	return &EstimatorResult{
-		RecommendedRange: nodesAndOffsetToRange(numNodes, e.RecommendationOffset, e.Resources),
-		AcceptableRange:  nodesAndOffsetToRange(numNodes, e.AcceptanceOffset, e.Resources),
+		RecommendedRange: nodesAndOffsetToRange(numNodes, numPods, e.RecommendationOffset, e.Resources),
+		AcceptableRange:  nodesAndOffsetToRange(numNodes, numPods, e.AcceptanceOffset, e.Resources),
+	}
+}
+
+// UsesPods returns true if any of the resources depends on the number of pods,
+// through its formula or its marginal value per pod.
+func (e Estimator) UsesPods() bool {
+	for _, r := range e.Resources {
+		if r.Formula != nil && r.Formula.UsesPods() {
+			return true
+		}
+		if r.Formula == nil && !r.ExtraPerPod.IsZero() {
+			return true
+		}
 	}
+	return false
 }
 
 // Returns a ResourceList containing the resource value for each type of
-// resource given the specified number of nodes and base resource value.
-func calculateResources(numNodes uint64, resources []Resource) api.ResourceList {
+// resource given the specified cluster size and base resource value.
+func calculateResources(size clusterSize, resources []Resource) api.ResourceList {
 	resourceList := make(api.ResourceList)
 	for _, r := range resources {
-		// Since we want to enable passing values smaller than e.g. 1 millicore per node,
-		// we need to have some more hacky solution here than operating on MilliValues.
-		perNodeString := r.ExtraPerNode.String()
-		var perNode float64
-		read, _ := fmt.Sscanf(perNodeString, "%f", &perNode)
-		overhead := resource.MustParse(fmt.Sprintf("%f%s", perNode*float64(numNodes), perNodeString[read:]))
+		if r.Formula != nil {
+			newRes := r.Formula.quantity(size)
+			log.V(4).Infof("New requirement for resource %s with %d nodes and %d pods is %s", r.Name, size.nodes, size.pods, newRes.String())
+			resourceList[r.Name] = newRes
+			continue
+		}
 
 		newRes := r.Base
-		newRes.Add(overhead)
-
-		log.V(4).Infof("New requirement for resource %s with %d nodes is %s", r.Name, numNodes, newRes.String())
+		newRes.Add(multiplyQuantity(r.ExtraPerNode, size.nodes))
+		if r.ExtraPerPod.IsZero() {
+			log.V(4).Infof("New requirement for resource %s with %d nodes is %s", r.Name, size.nodes, newRes.String())
+		} else {
+			newRes.Add(multiplyQuantity(r.ExtraPerPod, size.pods))
+			log.V(4).Infof("New requirement for resource %s with %d nodes and %d pods is %s", r.Name, size.nodes, size.pods, newRes.String())
+		}
 
 		resourceList[r.Name] = newRes
 	}
 	return resourceList
 }
+
+// multiplyQuantity returns the quantity multiplied by count.
+func multiplyQuantity(quantity resource.Quantity, count uint64) resource.Quantity {
+	// Since we want to enable passing values smaller than e.g. 1 millicore per node,
+	// we need to have some more hacky solution here than operating on MilliValues.
+	quantityString := quantity.String()
+	var value float64
+	read, _ := fmt.Sscanf(quantityString, "%f", &value)
+	return resource.MustParse(fmt.Sprintf("%f%s", value*float64(count), quantityString[read:]))
+}
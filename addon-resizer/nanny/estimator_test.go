@@ -362,9 +362,88 @@ func TestEstimateResources(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		got := tc.e.scaleWithNodes(tc.numNodes)
+		got := tc.e.scaleWithNodesAndPods(tc.numNodes, 0)
 		want := &tc.estimatorResult
 		verifyRange(t, tc.lineNum, "AcceptableRange", got.AcceptableRange, want.AcceptableRange)
 		verifyRange(t, tc.lineNum, "RecommendedRange", got.RecommendedRange, want.RecommendedRange)
 	}
 }
+
+func TestEstimateResourcesWithFormula(t *testing.T) {
+	formula, err := ParseFormula("10Mi + 1Mi*nodes + 100Ki*pods")
+	if err != nil {
+		t.Fatalf("ParseFormula failed: %v", err)
+	}
+	e := Estimator{
+		Resources: []Resource{
+			{
+				Base:         resource.MustParse("0.3"),
+				ExtraPerNode: resource.MustParse("1"),
+				Name:         "cpu",
+			},
+			{
+				Formula: formula,
+				Name:    "memory",
+			},
+		},
+		AcceptanceOffset:     20,
+		RecommendationOffset: 10,
+	}
+	if !e.UsesPods() {
+		t.Errorf("estimator with pods in a formula doesn't use pods")
+	}
+
+	// 10 nodes and 100 pods are offset to 9-11 nodes and 90-110 pods.
+	got := e.scaleWithNodesAndPods(10, 100)
+	want := ResourceListPair{
+		lower: api.ResourceList{
+			"cpu":    resource.MustParse("9.3"),
+			"memory": resource.MustParse("28456Ki"),
+		},
+		upper: api.ResourceList{
+			"cpu":    resource.MustParse("11.3"),
+			"memory": resource.MustParse("32504Ki"),
+		},
+	}
+	verifyRange(t, num(), "RecommendedRange", got.RecommendedRange, want)
+}
+
+func TestEstimateResourcesWithExtraPerPod(t *testing.T) {
+	e := Estimator{
+		Resources: []Resource{
+			{
+				Base:         resource.MustParse("0.3"),
+				ExtraPerNode: resource.MustParse("1"),
+				Name:         "cpu",
+			},
+			{
+				Base:         resource.MustParse("10Mi"),
+				ExtraPerNode: resource.MustParse("1Mi"),
+				ExtraPerPod:  resource.MustParse("100Ki"),
+				Name:         "memory",
+			},
+		},
+		AcceptanceOffset:     20,
+		RecommendationOffset: 10,
+	}
+	if !e.UsesPods() {
+		t.Errorf("estimator with an extra requirement per pod doesn't use pods")
+	}
+	if fullEstimator.UsesPods() {
+		t.Errorf("estimator without extra requirements per pod uses pods")
+	}
+
+	// 10 nodes and 100 pods are offset to 9-11 nodes and 90-110 pods.
+	got := e.scaleWithNodesAndPods(10, 100)
+	want := ResourceListPair{
+		lower: api.ResourceList{
+			"cpu":    resource.MustParse("9.3"),
+			"memory": resource.MustParse("28456Ki"),
+		},
+		upper: api.ResourceList{
+			"cpu":    resource.MustParse("11.3"),
+			"memory": resource.MustParse("32504Ki"),
+		},
+	}
+	verifyRange(t, num(), "RecommendedRange", got.RecommendedRange, want)
+}
@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nanny
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	log "github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// NodesVariable is the variable of a formula holding the number of nodes.
+	NodesVariable = "nodes"
+	// PodsVariable is the variable of a formula holding the number of pods.
+	PodsVariable = "pods"
+)
+
+// clusterSize holds the values of the variables of a formula.
+type clusterSize struct {
+	nodes, pods uint64
+}
+
+// Formula is an arithmetic expression computing a resource requirement from
+// the number of nodes and pods in the cluster, e.g.
+// "100Mi + 2Mi*nodes + 100Ki*pods". It supports +, -, *, /, parentheses,
+// resource quantities and the "nodes" and "pods" variables.
+type Formula struct {
+	expression string
+	evaluate   func(clusterSize) float64
+	usesPods   bool
+}
+
+// ParseFormula parses the expression of a Formula.
+func ParseFormula(expression string) (*Formula, error) {
+	p := &formulaParser{input: expression}
+	p.next()
+	evaluate, err := p.parseSum()
+	if err != nil {
+		return nil, fmt.Errorf("invalid formula %q: %v", expression, err)
+	}
+	if p.token != "" {
+		return nil, fmt.Errorf("invalid formula %q: unexpected %q", expression, p.token)
+	}
+	return &Formula{expression: expression, evaluate: evaluate, usesPods: p.usesPods}, nil
+}
+
+// UsesPods returns true if the formula depends on the number of pods.
+func (f *Formula) UsesPods() bool {
+	return f.usesPods
+}
+
+// String returns the expression of the formula.
+func (f *Formula) String() string {
+	return f.expression
+}
+
+// quantity returns the requirement computed by the formula. Negative and NaN
+// requirements are capped at zero, and overflowing ones at MaxInt64.
+func (f *Formula) quantity(size clusterSize) resource.Quantity {
+	value := f.evaluate(size)
+	if value < 0 || math.IsNaN(value) {
+		value = 0
+	}
+	if value > math.MaxInt64 {
+		value = math.MaxInt64
+	}
+	quantity, err := resource.ParseQuantity(strconv.FormatFloat(value, 'f', 6, 64))
+	if err != nil {
+		log.Errorf("Invalid requirement %v computed by formula %q: %v", value, f.expression, err)
+		return resource.Quantity{}
+	}
+	return quantity
+}
+
+// formulaParser is a recursive descent parser of formulas, turning each
+// subexpression into a function evaluating it.
+type formulaParser struct {
+	input    string
+	token    string
+	usesPods bool
+}
+
+// next moves to the next token, which is empty at the end of the input.
+func (p *formulaParser) next() {
+	p.input = strings.TrimLeftFunc(p.input, unicode.IsSpace)
+	if p.input == "" {
+		p.token = ""
+		return
+	}
+	end := 1
+	if isFormulaWordChar(rune(p.input[0])) {
+		for end < len(p.input) && isFormulaWordChar(rune(p.input[end])) {
+			end++
+		}
+	}
+	p.token, p.input = p.input[:end], p.input[end:]
+}
+
+func isFormulaWordChar(r rune) bool {
+	return r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// parseSum parses terms separated by + and -.
+func (p *formulaParser) parseSum() (func(clusterSize) float64, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for p.token == "+" || p.token == "-" {
+		op := p.token
+		p.next()
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "+" {
+			left = func(s clusterSize) float64 { return l(s) + right(s) }
+		} else {
+			left = func(s clusterSize) float64 { return l(s) - right(s) }
+		}
+	}
+	return left, nil
+}
+
+// parseProduct parses factors separated by * and /.
+func (p *formulaParser) parseProduct() (func(clusterSize) float64, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.token == "*" || p.token == "/" {
+		op := p.token
+		p.next()
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		if op == "*" {
+			left = func(s clusterSize) float64 { return l(s) * right(s) }
+		} else {
+			left = func(s clusterSize) float64 {
+				if divisor := right(s); divisor != 0 {
+					return l(s) / divisor
+				}
+				return 0
+			}
+		}
+	}
+	return left, nil
+}
+
+// parseFactor parses a negation, a parenthesized expression, a variable or a
+// quantity.
+func (p *formulaParser) parseFactor() (func(clusterSize) float64, error) {
+	token := p.token
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of formula")
+	case token == "-":
+		p.next()
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return func(s clusterSize) float64 { return -operand(s) }, nil
+	case token == "(":
+		p.next()
+		inner, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		if p.token != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.next()
+		return inner, nil
+	case token == NodesVariable:
+		p.next()
+		return func(s clusterSize) float64 { return float64(s.nodes) }, nil
+	case token == PodsVariable:
+		p.usesPods = true
+		p.next()
+		return func(s clusterSize) float64 { return float64(s.pods) }, nil
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.':
+		quantity, err := resource.ParseQuantity(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q: %v", token, err)
+		}
+		value, err := strconv.ParseFloat(quantity.AsDec().String(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q: %v", token, err)
+		}
+		p.next()
+		return func(clusterSize) float64 { return value }, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nanny
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestFormula(t *testing.T) {
+	testCases := []struct {
+		expression string
+		nodes      uint64
+		pods       uint64
+		want       string
+		usesPods   bool
+	}{
+		{"100Mi", 10, 100, "100Mi", false},
+		{"100Mi + 2Mi*nodes", 10, 100, "120Mi", false},
+		{"100Mi + 2Mi*nodes + 100Ki*pods", 10, 100, "132880Ki", true},
+		{"0.3 + 0.6m * nodes", 3, 0, "0.3018", false},
+		{"(nodes + pods) * 10m", 2, 8, "100m", true},
+		{"1 - 2*nodes / 4", 6, 0, "0", false},
+		{"-1 + nodes", 3, 0, "2", false},
+		{"1 / (nodes - 2)", 2, 0, "0", false},
+		{"1e308 * 1e308 - 1e308 * 1e308", 1, 0, "0", false},
+		{"1e308 * 1e308 * nodes", 1, 0, "9223372036854775808", false},
+	}
+	for _, tc := range testCases {
+		formula, err := ParseFormula(tc.expression)
+		if err != nil {
+			t.Errorf("ParseFormula(%q) failed: %v", tc.expression, err)
+			continue
+		}
+		got := formula.quantity(clusterSize{nodes: tc.nodes, pods: tc.pods})
+		if want := resource.MustParse(tc.want); got.Cmp(want) != 0 {
+			t.Errorf("formula %q with %d nodes and %d pods got %s, want %s", tc.expression, tc.nodes, tc.pods, got.String(), want.String())
+		}
+		if formula.UsesPods() != tc.usesPods {
+			t.Errorf("formula %q UsesPods() got %v, want %v", tc.expression, formula.UsesPods(), tc.usesPods)
+		}
+	}
+}
+
+func TestParseFormulaErrors(t *testing.T) {
+	for _, expression := range []string{"", "1 +", "(1 + nodes", "1 + nodes)", "2 * cores", "1Qi", "1 $ 2", "nodes pods"} {
+		if _, err := ParseFormula(expression); err == nil {
+			t.Errorf("ParseFormula(%q) didn't fail", expression)
+		}
+	}
+}
@@ -20,12 +20,15 @@ import (
 	"fmt"
 	"time"
 
+	log "github.com/golang/glog"
 	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	kube_client "k8s.io/client-go/kubernetes"
 	kube_client_apps "k8s.io/client-go/kubernetes/typed/apps/v1"
+	kube_client_core "k8s.io/client-go/kubernetes/typed/core/v1"
 	v1appslister "k8s.io/client-go/listers/apps/v1"
 	v1lister "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -34,8 +37,10 @@ import (
 type kubernetesClient struct {
 	nodeLister       v1lister.NodeLister
 	podLister        v1lister.PodNamespaceLister
+	allPodLister     v1lister.PodLister
 	deploymentLister v1appslister.DeploymentNamespaceLister
 	deploymentClient kube_client_apps.DeploymentInterface
+	eventClient      kube_client_core.EventInterface
 	namespace        string
 	deployment       string
 	pod              string
@@ -44,9 +49,17 @@ type kubernetesClient struct {
 }
 
 // NewKubernetesClient gives a KubernetesClient with the given dependencies.
-func NewKubernetesClient(kubeClient kube_client.Interface, namespace, deployment, pod, container string) KubernetesClient {
+// Pods of all namespaces are watched only if countPods is true.
+func NewKubernetesClient(kubeClient kube_client.Interface, namespace, deployment, pod, container string, countPods bool) KubernetesClient {
 	stops := []chan<- struct{}{}
 
+	var allPodLister v1lister.PodLister
+	if countPods {
+		var stopCh chan<- struct{}
+		allPodLister, stopCh = newAllPodLister(kubeClient)
+		stops = append(stops, stopCh)
+	}
+
 	nodeLister, stopCh := newReadyNodeLister(kubeClient)
 	stops = append(stops, stopCh)
 
@@ -63,8 +76,10 @@ func NewKubernetesClient(kubeClient kube_client.Interface, namespace, deployment
 		container:        container,
 		nodeLister:       nodeLister,
 		podLister:        podLister,
+		allPodLister:     allPodLister,
 		deploymentLister: deploymentLister,
 		deploymentClient: kubeClient.AppsV1().Deployments(namespace),
+		eventClient:      kubeClient.CoreV1().Events(namespace),
 		stopChannels:     stops,
 	}
 	return result
@@ -81,6 +96,23 @@ func (k *kubernetesClient) CountNodes() (uint64, error) {
 	return uint64(len(nodes)), err
 }
 
+func (k *kubernetesClient) CountPods() (uint64, error) {
+	if k.allPodLister == nil {
+		return 0, fmt.Errorf("pods aren't watched")
+	}
+	pods, err := k.allPodLister.List(labels.Everything())
+	if err != nil {
+		return 0, err
+	}
+	var count uint64
+	for _, pod := range pods {
+		if pod.Status.Phase != core.PodSucceeded && pod.Status.Phase != core.PodFailed {
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (k *kubernetesClient) ContainerResources() (*core.ResourceRequirements, error) {
 	pod, err := k.podLister.Get(k.pod)
 
@@ -116,6 +148,37 @@ func (k *kubernetesClient) UpdateDeployment(resources *core.ResourceRequirements
 	return fmt.Errorf("container %s was not found in the deployment %s in namespace %s", k.container, k.deployment, k.namespace)
 }
 
+func (k *kubernetesClient) RecordEvent(eventType, reason, message string) {
+	involvedObject := core.ObjectReference{
+		Kind:       "Deployment",
+		APIVersion: "apps/v1",
+		Namespace:  k.namespace,
+		Name:       k.deployment,
+	}
+	if dep, err := k.deploymentLister.Get(k.deployment); err == nil {
+		involvedObject.UID = dep.UID
+		involvedObject.ResourceVersion = dep.ResourceVersion
+	}
+	now := metav1.Now()
+	event := &core.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", k.deployment, now.UnixNano()),
+			Namespace: k.namespace,
+		},
+		InvolvedObject: involvedObject,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         core.EventSource{Component: "addon-resizer"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := k.eventClient.Create(event); err != nil {
+		log.Errorf("Failed to post event %s for deployment %s: %v", reason, k.deployment, err)
+	}
+}
+
 func newReadyNodeLister(kubeClient kube_client.Interface) (v1lister.NodeLister, chan<- struct{}) {
 	stopChannel := make(chan struct{})
 	listWatcher := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "nodes", core.NamespaceAll, fields.Everything())
@@ -138,6 +201,16 @@ func newPodListerByNamespace(kubeClient kube_client.Interface, namespace string)
 	return nsLister, stopChannel
 }
 
+func newAllPodLister(kubeClient kube_client.Interface) (v1lister.PodLister, chan<- struct{}) {
+	stopChannel := make(chan struct{})
+	listWatcher := cache.NewListWatchFromClient(kubeClient.CoreV1().RESTClient(), "pods", core.NamespaceAll, fields.Everything())
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	lister := v1lister.NewPodLister(store)
+	reflector := cache.NewReflector(listWatcher, &core.Pod{}, store, time.Hour)
+	go reflector.Run(stopChannel)
+	return lister, stopChannel
+}
+
 func newDeploymentListerByNamespace(kubeClient kube_client.Interface, namespace string) (v1appslister.DeploymentNamespaceLister,
 	chan<- struct{}) {
 	stopChannel := make(chan struct{})
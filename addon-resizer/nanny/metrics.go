@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nanny
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	api "k8s.io/api/core/v1"
+)
+
+// labelValueEscaper escapes label values as required by the Prometheus text
+// exposition format: backslashes, double quotes and line feeds are escaped.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// nannyMetrics holds the metrics of the nanny. They're served in the
+// Prometheus text exposition format, which doesn't need any client library.
+type nannyMetrics struct {
+	sync.Mutex
+	// estimatedResources are bounds of the recommended range by resource.
+	estimatedResources map[api.ResourceName]map[string]float64
+	// appliedResources are the last applied resources by resource and type
+	// (limits or requests).
+	appliedResources map[api.ResourceName]map[string]float64
+	// resizes count successful resizes by operation.
+	resizes map[string]float64
+	// resizeErrors count failed resizes.
+	resizeErrors float64
+}
+
+var metrics = newNannyMetrics()
+
+func newNannyMetrics() *nannyMetrics {
+	return &nannyMetrics{
+		estimatedResources: map[api.ResourceName]map[string]float64{},
+		appliedResources:   map[api.ResourceName]map[string]float64{},
+		resizes:            map[string]float64{},
+	}
+}
+
+// MetricsHandler serves the metrics of the nanny:
+//   - addon_resizer_estimated_resources: bounds of the currently recommended
+//     range of each resource, labeled with resource and bound (lower or upper).
+//   - addon_resizer_applied_resources: resources last applied to the
+//     container, labeled with resource and type (limits or requests).
+//   - addon_resizer_resizes_total: count of resizes, labeled with operation
+//     (scale_up, scale_down or unknown).
+//   - addon_resizer_resize_errors_total: count of resizes which failed.
+//
+// CPU is measured in cores, other resources in their base units.
+func MetricsHandler() http.Handler {
+	return metrics
+}
+
+func (m *nannyMetrics) observeEstimation(estimation *EstimatorResult) {
+	m.Lock()
+	defer m.Unlock()
+	m.estimatedResources = map[api.ResourceName]map[string]float64{}
+	for bound, list := range map[string]api.ResourceList{"lower": estimation.RecommendedRange.lower, "upper": estimation.RecommendedRange.upper} {
+		for name, quantity := range list {
+			if m.estimatedResources[name] == nil {
+				m.estimatedResources[name] = map[string]float64{}
+			}
+			m.estimatedResources[name][bound] = float64(quantity.MilliValue()) / 1000
+		}
+	}
+}
+
+func (m *nannyMetrics) observeResize(op operation, resources *api.ResourceRequirements) {
+	m.Lock()
+	defer m.Unlock()
+	m.resizes[op.String()]++
+	m.appliedResources = map[api.ResourceName]map[string]float64{}
+	for resourceType, list := range map[string]api.ResourceList{"limits": resources.Limits, "requests": resources.Requests} {
+		for name, quantity := range list {
+			if m.appliedResources[name] == nil {
+				m.appliedResources[name] = map[string]float64{}
+			}
+			m.appliedResources[name][resourceType] = float64(quantity.MilliValue()) / 1000
+		}
+	}
+}
+
+func (m *nannyMetrics) observeResizeError() {
+	m.Lock()
+	defer m.Unlock()
+	m.resizeErrors++
+}
+
+func (m *nannyMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.Lock()
+	defer m.Unlock()
+	m.writeResources(w, "addon_resizer_estimated_resources", "Bounds of the currently recommended range of resources.", "bound", m.estimatedResources)
+	m.writeResources(w, "addon_resizer_applied_resources", "Resources last applied to the container.", "type", m.appliedResources)
+	fmt.Fprintf(w, "# HELP addon_resizer_resizes_total Number of resizes of the container.\n# TYPE addon_resizer_resizes_total counter\n")
+	for _, op := range []operation{scaleUp, scaleDown, unknown} {
+		fmt.Fprintf(w, "addon_resizer_resizes_total{operation=\"%s\"} %v\n", labelValueEscaper.Replace(op.String()), m.resizes[op.String()])
+	}
+	fmt.Fprintf(w, "# HELP addon_resizer_resize_errors_total Number of failed resizes of the container.\n# TYPE addon_resizer_resize_errors_total counter\n")
+	fmt.Fprintf(w, "addon_resizer_resize_errors_total %v\n", m.resizeErrors)
+}
+
+func (m *nannyMetrics) writeResources(w io.Writer, name, help, label string, values map[api.ResourceName]map[string]float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	var lines []string
+	for resource, byLabel := range values {
+		for labelValue, value := range byLabel {
+			lines = append(lines, fmt.Sprintf("%s{resource=\"%s\",%s=\"%s\"} %v\n", name, labelValueEscaper.Replace(string(resource)), label, labelValueEscaper.Replace(labelValue), value))
+		}
+	}
+	sort.Strings(lines)
+	io.WriteString(w, strings.Join(lines, ""))
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nanny
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	api "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMetricsEscapeLabelValues(t *testing.T) {
+	m := newNannyMetrics()
+	m.observeResize(scaleUp, &api.ResourceRequirements{
+		Requests: api.ResourceList{api.ResourceName("example.com/\"\\\nx"): resource.MustParse("2")},
+	})
+
+	recorder := httptest.NewRecorder()
+	m.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	want := `addon_resizer_applied_resources{resource="example.com/\"\\\nx",type="requests"} 2` + "\n"
+	if !strings.Contains(recorder.Body.String(), want) {
+		t.Errorf("metrics don't contain %q:\n%s", want, recorder.Body.String())
+	}
+}
@@ -22,6 +22,7 @@ package nanny
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	log "github.com/golang/glog"
@@ -36,12 +37,24 @@ const (
 	scaleUp   operation = iota
 )
 
+func (op operation) String() string {
+	switch op {
+	case scaleDown:
+		return "scale_down"
+	case scaleUp:
+		return "scale_up"
+	default:
+		return "unknown"
+	}
+}
+
 type updateResult int
 
 const (
 	noChange  updateResult = iota
 	postpone  updateResult = iota
 	overwrite updateResult = iota
+	failed    updateResult = iota
 )
 
 // checkResource determines whether a specific resource needs to be over-written.
@@ -86,18 +99,25 @@ func shouldOverwriteResources(estimatorResult *EstimatorResult, limits, reqs api
 // KubernetesClient is an object that performs the nanny's requisite interactions with Kubernetes.
 type KubernetesClient interface {
 	CountNodes() (uint64, error)
+	// CountPods returns the number of running and pending pods in the cluster.
+	CountPods() (uint64, error)
 	ContainerResources() (*api.ResourceRequirements, error)
 	UpdateDeployment(resources *api.ResourceRequirements) error
+	// RecordEvent posts an event about the deployment.
+	RecordEvent(eventType, reason, message string)
 	Stop()
 }
 
 // ResourceEstimator estimates ResourceRequirements for a given criteria. Returned value is a list
 // with acceptable values. First element on that list is the recommended one.
 type ResourceEstimator interface {
-	scaleWithNodes(numNodes uint64) *EstimatorResult
+	scaleWithNodesAndPods(numNodes, numPods uint64) *EstimatorResult
+	// UsesPods returns true if the estimation depends on the number of pods,
+	// so that they need to be counted.
+	UsesPods() bool
 }
 
-// PollAPIServer periodically counts the number of nodes, estimates the expected
+// PollAPIServer periodically counts the number of nodes and pods, estimates the expected
 // ResourceRequirements, compares them to the actual ResourceRequirements, and
 // updates the deployment with the expected ResourceRequirements if necessary.
 func PollAPIServer(k8s KubernetesClient, est ResourceEstimator, pollPeriod, scaleDownDelay, scaleUpDelay time.Duration) {
@@ -116,12 +136,13 @@ func PollAPIServer(k8s KubernetesClient, est ResourceEstimator, pollPeriod, scal
 	}
 }
 
-// updateResources counts the number of nodes, estimates the expected
+// updateResources counts the number of nodes and pods, estimates the expected
 // ResourceRequirements, compares them to the actual ResourceRequirements, and
 // updates the deployment with the expected ResourceRequirements if necessary.
 // It returns overwrite if deployment has been updated, postpone if the change
-// could not be applied due to scale up/down delay and noChange if the estimated
-// expected ResourceRequirements are in line with the actual ResourceRequirements.
+// could not be applied due to scale up/down delay, failed if the deployment
+// could not be updated and noChange if the estimated expected
+// ResourceRequirements are in line with the actual ResourceRequirements.
 func updateResources(k8s KubernetesClient, est ResourceEstimator, now, lastChange time.Time, scaleDownDelay, scaleUpDelay time.Duration, prevResult updateResult) updateResult {
 
 	// Query the apiserver for the number of nodes.
@@ -136,6 +157,21 @@ func updateResources(k8s KubernetesClient, est ResourceEstimator, now, lastChang
 	}
 	log.V(4).Infof("The number of nodes is %d", num)
 
+	var numPods uint64
+	if est.UsesPods() {
+		numPods, err = k8s.CountPods()
+		if err != nil {
+			log.Errorf("Error while counting pods: %v", err)
+			return noChange
+		}
+		if numPods == 0 {
+			// At least this pod is running, the pod lister must not have synced yet.
+			log.V(2).Info("No pods found, probably listers have not synced yet. Skipping current check.")
+			return noChange
+		}
+		log.V(4).Infof("The number of pods is %d", numPods)
+	}
+
 	// Query the apiserver for this pod's information.
 	resources, err := k8s.ContainerResources()
 	if err != nil {
@@ -144,7 +180,8 @@ func updateResources(k8s KubernetesClient, est ResourceEstimator, now, lastChang
 	}
 
 	// Get the expected resource limits.
-	estimation := est.scaleWithNodes(num)
+	estimation := est.scaleWithNodesAndPods(num, numPods)
+	metrics.observeEstimation(estimation)
 
 	// If there's a difference, go ahead and set the new values.
 	overwriteResReq, op := shouldOverwriteResources(estimation, resources.Limits, resources.Requests)
@@ -162,9 +199,15 @@ func updateResources(k8s KubernetesClient, est ResourceEstimator, now, lastChang
 	log.Infof("Resources are not within the expected limits, updating the deployment. Actual: %+v New: %+v", *resources, jsonOrValue(*overwriteResReq))
 	if err := k8s.UpdateDeployment(overwriteResReq); err != nil {
 		log.Error(err)
-		return noChange
+		metrics.observeResizeError()
+		// Don't repeat the event on every poll while the update keeps failing.
+		if prevResult != failed {
+			k8s.RecordEvent(api.EventTypeWarning, "ResizeFailed", fmt.Sprintf("Failed to update resources to %v: %v", jsonOrValue(*overwriteResReq), err))
+		}
+		return failed
 	}
-	lastChange = time.Now()
+	metrics.observeResize(op, overwriteResReq)
+	k8s.RecordEvent(api.EventTypeNormal, "Resized", fmt.Sprintf("Updated resources from %v to %v (%s)", jsonOrValue(*resources), jsonOrValue(*overwriteResReq), op))
 	return overwrite
 }
 
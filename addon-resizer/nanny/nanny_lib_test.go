@@ -17,7 +17,10 @@ limitations under the License.
 package nanny
 
 import (
+	"errors"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -290,10 +293,65 @@ func TestUpdateResources(t *testing.T) {
 	}
 }
 
+func TestUpdateResourcesSkipsWithoutPods(t *testing.T) {
+	now := time.Now()
+	k8s := newFakeKubernetesClient(10, smallCPU, smallCPU)
+	est := newFakeResourceEstimator(standardRecommended)
+	est.usesPods = true
+
+	// Pods aren't listed yet, e.g. right after a restart.
+	if got := updateResources(k8s, est, now, now.Add(-time.Hour), noDelay, noDelay, noChange); got != noChange {
+		t.Errorf("updateResources got %d, want %d.", got, noChange)
+	}
+	k8s.pods = 100
+	if got := updateResources(k8s, est, now, now.Add(-time.Hour), noDelay, noDelay, noChange); got != overwrite {
+		t.Errorf("updateResources got %d, want %d.", got, overwrite)
+	}
+}
+
+func TestUpdateResourcesEventsAndMetrics(t *testing.T) {
+	metrics = newNannyMetrics()
+	now := time.Now()
+	k8s := newFakeKubernetesClient(10, standard, standard)
+	est := newFakeResourceEstimator(standardBelowAcceptable)
+
+	k8s.updateErr = errors.New("exceeded quota")
+	result := noChange
+	for i := 0; i < 2; i++ {
+		result = updateResources(k8s, est, now, now.Add(-time.Hour), 0, 0, result)
+		if result != failed {
+			t.Errorf("updateResources got %d, want %d.", result, failed)
+		}
+	}
+	k8s.updateErr = nil
+	if result = updateResources(k8s, est, now, now.Add(-time.Hour), 0, 0, result); result != overwrite {
+		t.Errorf("updateResources got %d, want %d.", result, overwrite)
+	}
+	if want := []string{"ResizeFailed", "Resized"}; !reflect.DeepEqual(want, k8s.events) {
+		t.Errorf("got events %v, want %v.", k8s.events, want)
+	}
+
+	recorder := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		`addon_resizer_estimated_resources{resource="cpu",bound="lower"} 0.4`,
+		`addon_resizer_applied_resources{resource="memory",type="requests"} 2.62144e+08`,
+		`addon_resizer_resizes_total{operation="scale_up"} 1`,
+		`addon_resizer_resize_errors_total 2`,
+	} {
+		if !strings.Contains(recorder.Body.String(), want) {
+			t.Errorf("metrics don't contain %q:\n%s", want, recorder.Body.String())
+		}
+	}
+}
+
 type fakeKubernetesClient struct {
 	nodes        uint64
+	pods         uint64
 	resources    *api.ResourceRequirements
 	newResources *api.ResourceRequirements
+	updateErr    error
+	events       []string
 }
 
 func newFakeKubernetesClient(nodes uint64, limits, reqs api.ResourceList) *fakeKubernetesClient {
@@ -310,20 +368,32 @@ func (f *fakeKubernetesClient) CountNodes() (uint64, error) {
 	return f.nodes, nil
 }
 
+func (f *fakeKubernetesClient) CountPods() (uint64, error) {
+	return f.pods, nil
+}
+
 func (f *fakeKubernetesClient) ContainerResources() (*api.ResourceRequirements, error) {
 	return f.resources, nil
 }
 
 func (f *fakeKubernetesClient) UpdateDeployment(resources *api.ResourceRequirements) error {
+	if f.updateErr != nil {
+		return f.updateErr
+	}
 	f.newResources = resources
 	return nil
 }
 
+func (f *fakeKubernetesClient) RecordEvent(eventType, reason, message string) {
+	f.events = append(f.events, reason)
+}
+
 func (f *fakeKubernetesClient) Stop() {
 }
 
 type fakeResourceEstimator struct {
-	result *EstimatorResult
+	result   *EstimatorResult
+	usesPods bool
 }
 
 func newFakeResourceEstimator(result *EstimatorResult) *fakeResourceEstimator {
@@ -332,6 +402,10 @@ func newFakeResourceEstimator(result *EstimatorResult) *fakeResourceEstimator {
 	}
 }
 
-func (f *fakeResourceEstimator) scaleWithNodes(numNodes uint64) *EstimatorResult {
+func (f *fakeResourceEstimator) scaleWithNodesAndPods(numNodes, numPods uint64) *EstimatorResult {
 	return f.result
 }
+
+func (f *fakeResourceEstimator) UsesPods() bool {
+	return f.usesPods
+}
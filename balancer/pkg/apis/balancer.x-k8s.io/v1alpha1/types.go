@@ -124,12 +124,17 @@ const (
 	PriorityPolicyName BalancerPolicyName = "priority"
 	// ProportionalPolicyName is the name used in Balancer Spec for proportional policy
 	ProportionalPolicyName BalancerPolicyName = "proportional"
+	// SpilloverPolicyName is the name used in Balancer Spec for spillover policy.
+	SpilloverPolicyName BalancerPolicyName = "spillover"
+	// WeightedPolicyName is the name used in Balancer Spec for weighted policy.
+	WeightedPolicyName BalancerPolicyName = "weighted"
 )
 
 // BalancerPolicy defines Balancer policy for replica distribution.
 type BalancerPolicy struct {
 	// PolicyName decides how to balance replicas across the targets.
-	// Depending on the name one of the fields Priorities or Proportions must be set.
+	// Depending on the name one of the fields Priorities, Proportions, Spillover
+	// or Weighted must be set.
 	// +kubebuilder:validation:Required
 	PolicyName BalancerPolicyName `json:"policyName" protobuf:"bytes,1,name=policyName"`
 
@@ -143,6 +148,16 @@ type BalancerPolicy struct {
 	// +optional
 	Proportions *ProportionalPolicy `json:"proportions,omitempty" protobuf:"bytes,3,opt,name=proportions"`
 
+	// Spillover contains detailed specification of how to balance when
+	// balancer policy name is set to Spillover.
+	// +optional
+	Spillover *SpilloverPolicy `json:"spillover,omitempty" protobuf:"bytes,5,opt,name=spillover"`
+
+	// Weighted contains detailed specification of how to balance when
+	// balancer policy name is set to Weighted.
+	// +optional
+	Weighted *WeightedPolicy `json:"weighted,omitempty" protobuf:"bytes,6,opt,name=weighted"`
+
 	// Fallback contains specification of how to recognize and what to do if some
 	// replicas fail to start in one or more targets. No fallback happens if not-set.
 	// +optional
@@ -174,6 +189,78 @@ type ProportionalPolicy struct {
 	TargetProportions map[string]int32 `json:"targetProportions" protobuf:"bytes,1,opt,name=targetProportions"`
 }
 
+// SpilloverPolicy contains details for Spillover-based policy for Balancer.
+type SpilloverPolicy struct {
+	// Targets is the priority-ordered list of Balancer targets with their
+	// spillover limits. Replicas fill the first target up to its maxReplicas
+	// and spill over to the next target only when the earlier ones are full or
+	// unhealthy, i.e. have replicas that failed to start within the fallback
+	// startup timeout. It's useful to prefer reserved capacity over spot zones.
+	// MinReplicas is guaranteed to be fulfilled, irrespective of the order,
+	// presence on the list, and/or total Balancer's replica count.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=2
+	Targets []SpilloverTarget `json:"targets" protobuf:"bytes,1,rep,name=targets"`
+}
+
+// SpilloverTarget is a Balancer target taking part in the spillover policy.
+type SpilloverTarget struct {
+	// Name of the Balancer target.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+
+	// MaxReplicas is the number of replicas after which the next target gets
+	// the replicas. The target's maxReplicas is used if not provided, or if
+	// it's lower.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxReplicas *int32 `json:"maxReplicas,omitempty" protobuf:"varint,2,opt,name=maxReplicas"`
+}
+
+// WeightedPolicy contains details for Weight-based policy for Balancer.
+type WeightedPolicy struct {
+	// Targets is the list of Balancer targets with their weights and priorities.
+	// Replicas go to the targets of the highest priority first, distributed
+	// among them proportionally to their weights. Once these targets reach their
+	// maxReplicas, or have replicas that failed to start within the fallback
+	// startup timeout, replicas overflow to the targets of the next priority,
+	// and so on. It's useful to fill e.g. on-demand targets up to a limit and
+	// spread the remaining replicas over spot targets. MinReplicas is guaranteed
+	// to be fulfilled, irrespective of the weights, priorities, presence on the
+	// list, and/or total Balancer's replica count.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=2
+	Targets []WeightedTarget `json:"targets" protobuf:"bytes,1,rep,name=targets"`
+}
+
+// WeightedTarget is a Balancer target taking part in the weighted policy.
+type WeightedTarget struct {
+	// Name of the Balancer target.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name" protobuf:"bytes,1,name=name"`
+
+	// Weight is the share of replicas the target gets among the targets of
+	// the same priority. 1 is used if not provided.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Weight *int32 `json:"weight,omitempty" protobuf:"varint,2,opt,name=weight"`
+
+	// Priority of the target. Targets of higher priorities get replicas first.
+	// 0 is used if not provided.
+	// +optional
+	Priority int32 `json:"priority,omitempty" protobuf:"varint,3,opt,name=priority"`
+
+	// MaxReplicas is the number of replicas after which the target doesn't get
+	// more replicas, and the replicas overflow to the targets of lower
+	// priorities. The target's maxReplicas is used if not provided, or if
+	// it's lower.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxReplicas *int32 `json:"maxReplicas,omitempty" protobuf:"varint,4,opt,name=maxReplicas"`
+}
+
 // FallbackPolicy contains information how to recognize and handle replicas
 // that failed to start within the specified time period.
 type FallbackPolicy struct {
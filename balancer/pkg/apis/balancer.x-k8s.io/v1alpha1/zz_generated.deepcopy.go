@@ -100,6 +100,16 @@ func (in *BalancerPolicy) DeepCopyInto(out *BalancerPolicy) {
 		*out = new(ProportionalPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Spillover != nil {
+		in, out := &in.Spillover, &out.Spillover
+		*out = new(SpilloverPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Weighted != nil {
+		in, out := &in.Weighted, &out.Weighted
+		*out = new(WeightedPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Fallback != nil {
 		in, out := &in.Fallback, &out.Fallback
 		*out = new(FallbackPolicy)
@@ -253,3 +263,96 @@ func (in *ProportionalPolicy) DeepCopy() *ProportionalPolicy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpilloverPolicy) DeepCopyInto(out *SpilloverPolicy) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]SpilloverTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpilloverPolicy.
+func (in *SpilloverPolicy) DeepCopy() *SpilloverPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SpilloverPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpilloverTarget) DeepCopyInto(out *SpilloverTarget) {
+	*out = *in
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpilloverTarget.
+func (in *SpilloverTarget) DeepCopy() *SpilloverTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(SpilloverTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedPolicy) DeepCopyInto(out *WeightedPolicy) {
+	*out = *in
+	if in.Targets != nil {
+		in, out := &in.Targets, &out.Targets
+		*out = make([]WeightedTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedPolicy.
+func (in *WeightedPolicy) DeepCopy() *WeightedPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedTarget) DeepCopyInto(out *WeightedTarget) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedTarget.
+func (in *WeightedTarget) DeepCopy() *WeightedTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedTarget)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -49,6 +49,41 @@ func GetPlacement(balancer *v1alpha1.Balancer, summaries map[string]pods.Summary
 		placement, problems := distributeByProportions(balancer.Spec.Replicas, infos)
 		return placement, problems, nil
 
+	case v1alpha1.SpilloverPolicyName:
+		if balancer.Spec.Policy.Spillover == nil {
+			return nil, PlacementProblems{}, fmt.Errorf("incomplete policy definition: missing spillover")
+		}
+		if len(balancer.Spec.Policy.Spillover.Targets) == 0 {
+			return nil, PlacementProblems{}, fmt.Errorf("incomplete policy definition: missing targets")
+		}
+		for _, target := range balancer.Spec.Policy.Spillover.Targets {
+			if _, found := targetMap[target.Name]; !found {
+				return nil, PlacementProblems{}, fmt.Errorf("invalid policy definition: unknown spillover target %s", target.Name)
+			}
+		}
+		infos := buildTargetInfoMapForPriority(targetMap, summaries)
+		placement, problems := distributeBySpillover(balancer.Spec.Replicas, balancer.Spec.Policy.Spillover.Targets, infos)
+		return placement, problems, nil
+
+	case v1alpha1.WeightedPolicyName:
+		if balancer.Spec.Policy.Weighted == nil {
+			return nil, PlacementProblems{}, fmt.Errorf("incomplete policy definition: missing weighted")
+		}
+		if len(balancer.Spec.Policy.Weighted.Targets) == 0 {
+			return nil, PlacementProblems{}, fmt.Errorf("incomplete policy definition: missing targets")
+		}
+		for _, target := range balancer.Spec.Policy.Weighted.Targets {
+			if _, found := targetMap[target.Name]; !found {
+				return nil, PlacementProblems{}, fmt.Errorf("invalid policy definition: unknown weighted target %s", target.Name)
+			}
+			if target.Weight != nil && *target.Weight < 1 {
+				return nil, PlacementProblems{}, fmt.Errorf("invalid policy definition: weight of target %s is lower than 1", target.Name)
+			}
+		}
+		infos := buildTargetInfoMapForPriority(targetMap, summaries)
+		placement, problems := distributeByWeights(balancer.Spec.Replicas, balancer.Spec.Policy.Weighted.Targets, infos)
+		return placement, problems, nil
+
 	default:
 		return nil, PlacementProblems{}, fmt.Errorf("policy not supported: %v", balancer.Spec.Policy.PolicyName)
 	}
@@ -0,0 +1,37 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"k8s.io/autoscaler/balancer/pkg/apis/balancer.x-k8s.io/v1alpha1"
+)
+
+// Main algorithm of the spillover policy. Targets are filled in the given
+// order up to their spillover limits, and the replicas that don't fit, or
+// failed to start, spill over to the next targets. The function returns the
+// desired replica placement and information about problems that possibly
+// happened during placement.
+func distributeBySpillover(replicas int32,
+	targets []v1alpha1.SpilloverTarget, infos map[string]*targetInfo) (ReplicaPlacement, PlacementProblems) {
+
+	priorities := make([]string, 0, len(targets))
+	for _, target := range targets {
+		priorities = append(priorities, target.Name)
+		limitMaxReplicas(infos[target.Name], target.MaxReplicas)
+	}
+	return distributeByPriority(replicas, priorities, infos)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/autoscaler/balancer/pkg/apis/balancer.x-k8s.io/v1alpha1"
+	"k8s.io/autoscaler/balancer/pkg/pods"
+)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+func TestDistributeBySpillover(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		infos    map[string]*targetInfo
+		targets  []v1alpha1.SpilloverTarget
+		expected ReplicaPlacement
+		problems PlacementProblems
+	}{
+		{
+			name:     "10 replicas, no spillover limits",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"reserved": {max: maxReplicas},
+				"spot":     {max: maxReplicas},
+			},
+			targets:  []v1alpha1.SpilloverTarget{{Name: "reserved"}, {Name: "spot"}},
+			expected: ReplicaPlacement{"reserved": 10, "spot": 0},
+		},
+		{
+			name:     "10 replicas, spillover limit",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"reserved": {max: maxReplicas},
+				"spot":     {max: maxReplicas},
+			},
+			targets:  []v1alpha1.SpilloverTarget{{Name: "reserved", MaxReplicas: int32Ptr(4)}, {Name: "spot"}},
+			expected: ReplicaPlacement{"reserved": 4, "spot": 6},
+		},
+		{
+			name:     "10 replicas, target max lower than spillover limit",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"reserved": {max: 3},
+				"spot":     {max: maxReplicas},
+			},
+			targets:  []v1alpha1.SpilloverTarget{{Name: "reserved", MaxReplicas: int32Ptr(6)}, {Name: "spot"}},
+			expected: ReplicaPlacement{"reserved": 3, "spot": 7},
+		},
+		{
+			name:     "10 replicas, spillover limit lower than target min",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"reserved": {min: 5, max: maxReplicas},
+				"spot":     {max: maxReplicas},
+			},
+			targets:  []v1alpha1.SpilloverTarget{{Name: "reserved", MaxReplicas: int32Ptr(2)}, {Name: "spot"}},
+			expected: ReplicaPlacement{"reserved": 5, "spot": 5},
+		},
+		{
+			name:     "10 replicas, all targets full",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"reserved": {max: maxReplicas},
+				"spot":     {max: maxReplicas},
+			},
+			targets:  []v1alpha1.SpilloverTarget{{Name: "reserved", MaxReplicas: int32Ptr(4)}, {Name: "spot", MaxReplicas: int32Ptr(4)}},
+			expected: ReplicaPlacement{"reserved": 4, "spot": 4},
+			problems: PlacementProblems{OverflowReplicas: 2},
+		},
+		{
+			name:     "10 replicas, unhealthy target spills over",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"reserved": {max: maxReplicas,
+					summary: pods.Summary{
+						Total: 6, NotStartedWithinDeadline: 2}},
+				"spot": {max: maxReplicas},
+			},
+			targets:  []v1alpha1.SpilloverTarget{{Name: "reserved", MaxReplicas: int32Ptr(6)}, {Name: "spot"}},
+			expected: ReplicaPlacement{"reserved": 6, "spot": 6},
+		},
+		{
+			name:     "10 replicas, target not on the list",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"reserved": {max: maxReplicas},
+				"spot":     {max: maxReplicas},
+				"other":    {min: 1, max: maxReplicas},
+			},
+			targets:  []v1alpha1.SpilloverTarget{{Name: "spot", MaxReplicas: int32Ptr(3)}, {Name: "reserved"}},
+			expected: ReplicaPlacement{"reserved": 6, "spot": 3, "other": 1},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("%d: %s", i, tc.name), func(t *testing.T) {
+			result, problems := distributeBySpillover(tc.replicas, tc.targets, tc.infos)
+			assert.Equal(t, tc.expected, result)
+			assert.Equal(t, tc.problems, problems)
+		})
+	}
+}
@@ -48,10 +48,24 @@ type targetInfo struct {
 	// count of pods of given type based on pod listener data.
 	summary pods.Summary
 
-	// proportion taken from ProportionalPolicy. 0 for other policies.
+	// proportion taken from ProportionalPolicy or weight taken from
+	// WeightedPolicy. 0 for other policies.
 	proportion int32
 }
 
+// limitMaxReplicas lowers the max replica count of the target to the given
+// policy limit, if set. Target minimum is placed irrespective of the limit.
+func limitMaxReplicas(info *targetInfo, limit *int32) {
+	if limit == nil || *limit >= info.max {
+		return
+	}
+	if *limit < info.min {
+		info.max = info.min
+	} else {
+		info.max = *limit
+	}
+}
+
 // PlacementProblems contains information about replicas that were problematic
 // when applying placement policy and constraints.
 type PlacementProblems struct {
@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"sort"
+
+	"k8s.io/autoscaler/balancer/pkg/apis/balancer.x-k8s.io/v1alpha1"
+)
+
+// Main algorithm of the weighted policy. Targets are grouped by priority and
+// the groups are filled in the order of decreasing priority. Within a group
+// replicas are distributed proportionally to the target weights, up to the
+// target limits. Replicas that don't fit, or failed to start, overflow to the
+// next groups. The function returns the desired replica placement and
+// information about problems that possibly happened during placement.
+func distributeByWeights(replicas int32,
+	targets []v1alpha1.WeightedTarget, infos map[string]*targetInfo) (ReplicaPlacement, PlacementProblems) {
+
+	groups := make(map[int32][]string)
+	priorities := make([]int32, 0)
+	for _, target := range targets {
+		info := infos[target.Name]
+		info.proportion = 1
+		if target.Weight != nil {
+			info.proportion = *target.Weight
+		}
+		limitMaxReplicas(info, target.MaxReplicas)
+		if _, found := groups[target.Priority]; !found {
+			priorities = append(priorities, target.Priority)
+		}
+		groups[target.Priority] = append(groups[target.Priority], target.Name)
+	}
+	sort.Slice(priorities, func(i, j int) bool { return priorities[i] > priorities[j] })
+
+	placement := make(ReplicaPlacement)
+	problems := PlacementProblems{}
+
+	// Place target minimums.
+	for k, info := range infos {
+		placement[k] = info.min
+		replicas -= placement[k]
+	}
+	// continue computations as there still may be fallbacks.
+	if replicas < 0 {
+		problems.MissingReplicas = -replicas
+		replicas = 0
+	}
+
+	for _, priority := range priorities {
+		keys := groups[priority]
+		replicas = distributeGroupProportionally(replicas, keys, infos, placement)
+		// calculate how many may need to fall back to the next group = all new plus
+		// and those that are past deadline.
+		for _, key := range keys {
+			info := infos[key]
+			if info.summary.NotStartedWithinDeadline > 0 {
+				fallback := info.summary.NotStartedWithinDeadline + placement[key] - info.summary.Total
+				if fallback > 0 {
+					replicas += fallback
+				}
+			}
+		}
+	}
+	if replicas > 0 {
+		problems.OverflowReplicas = replicas
+	}
+	return placement, problems
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/autoscaler/balancer/pkg/apis/balancer.x-k8s.io/v1alpha1"
+	"k8s.io/autoscaler/balancer/pkg/pods"
+)
+
+func TestDistributeByWeights(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		infos    map[string]*targetInfo
+		targets  []v1alpha1.WeightedTarget
+		expected ReplicaPlacement
+		problems PlacementProblems
+	}{
+		{
+			name:     "8 replicas, weights 3:1",
+			replicas: 8,
+			infos: map[string]*targetInfo{
+				"a": {max: maxReplicas},
+				"b": {max: maxReplicas},
+			},
+			targets:  []v1alpha1.WeightedTarget{{Name: "a", Weight: int32Ptr(3)}, {Name: "b", Weight: int32Ptr(1)}},
+			expected: ReplicaPlacement{"a": 6, "b": 2},
+		},
+		{
+			name:     "8 replicas, default weights",
+			replicas: 8,
+			infos: map[string]*targetInfo{
+				"a": {max: maxReplicas},
+				"b": {max: maxReplicas},
+			},
+			targets:  []v1alpha1.WeightedTarget{{Name: "a"}, {Name: "b"}},
+			expected: ReplicaPlacement{"a": 4, "b": 4},
+		},
+		{
+			name:     "10 replicas, on-demand filled first, spot takes the rest",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"on-demand": {max: maxReplicas},
+				"spot":      {max: maxReplicas},
+			},
+			targets: []v1alpha1.WeightedTarget{
+				{Name: "spot"},
+				{Name: "on-demand", Priority: 1, MaxReplicas: int32Ptr(4)},
+			},
+			expected: ReplicaPlacement{"on-demand": 4, "spot": 6},
+		},
+		{
+			name:     "12 replicas, weighted group overflows to the next priority",
+			replicas: 12,
+			infos: map[string]*targetInfo{
+				"zone-a": {max: maxReplicas},
+				"zone-b": {max: maxReplicas},
+				"spot":   {max: maxReplicas},
+			},
+			targets: []v1alpha1.WeightedTarget{
+				{Name: "zone-a", Weight: int32Ptr(2), Priority: 1, MaxReplicas: int32Ptr(4)},
+				{Name: "zone-b", Priority: 1, MaxReplicas: int32Ptr(4)},
+				{Name: "spot"},
+			},
+			expected: ReplicaPlacement{"zone-a": 4, "zone-b": 4, "spot": 4},
+		},
+		{
+			name:     "10 replicas, all targets full",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"on-demand": {max: maxReplicas},
+				"spot":      {max: 3},
+			},
+			targets: []v1alpha1.WeightedTarget{
+				{Name: "on-demand", Priority: 1, MaxReplicas: int32Ptr(4)},
+				{Name: "spot"},
+			},
+			expected: ReplicaPlacement{"on-demand": 4, "spot": 3},
+			problems: PlacementProblems{OverflowReplicas: 3},
+		},
+		{
+			name:     "10 replicas, minimum placed irrespective of limit",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"on-demand": {min: 5, max: maxReplicas},
+				"spot":      {max: maxReplicas},
+			},
+			targets: []v1alpha1.WeightedTarget{
+				{Name: "on-demand", Priority: 1, MaxReplicas: int32Ptr(2)},
+				{Name: "spot"},
+			},
+			expected: ReplicaPlacement{"on-demand": 5, "spot": 5},
+		},
+		{
+			name:     "10 replicas, unhealthy target falls back",
+			replicas: 10,
+			infos: map[string]*targetInfo{
+				"on-demand": {max: maxReplicas,
+					summary: pods.Summary{
+						Total: 6, NotStartedWithinDeadline: 2}},
+				"spot": {max: maxReplicas},
+			},
+			targets: []v1alpha1.WeightedTarget{
+				{Name: "on-demand", Priority: 1, MaxReplicas: int32Ptr(6)},
+				{Name: "spot"},
+			},
+			expected: ReplicaPlacement{"on-demand": 6, "spot": 6},
+		},
+	}
+
+	for i, tc := range tests {
+		t.Run(fmt.Sprintf("%d: %s", i, tc.name), func(t *testing.T) {
+			result, problems := distributeByWeights(tc.replicas, tc.targets, tc.infos)
+			assert.Equal(t, tc.expected, result)
+			assert.Equal(t, tc.problems, problems)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package autoscaler allows embedding the Cluster Autoscaler control loop
+// into other binaries, e.g. controller managers of platforms built on top of
+// Kubernetes, instead of running the cluster-autoscaler binary.
+//
+// A minimal embedding creates the autoscaler once, starts it and calls
+// RunOnce periodically, typically only while holding a leader lease:
+//
+//	a, err := autoscaler.New(autoscaler.Options{AutoscalingOptions: opts, KubeClient: client})
+//	...
+//	if err := a.Start(ctx); err != nil { ... }
+//	defer a.Stop()
+//	wait.UntilWithContext(ctx, func(ctx context.Context) { _ = a.RunOnce(ctx) }, scanInterval)
+//
+// Metrics are recorded in the legacy registry, callers that want to expose
+// them have to call metrics.RegisterAll once per process.
+package autoscaler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/core"
+	"k8s.io/autoscaler/cluster-autoscaler/core/podlistprocessor"
+	"k8s.io/autoscaler/cluster-autoscaler/debuggingsnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/loop"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	"k8s.io/client-go/informers"
+	kube_client "k8s.io/client-go/kubernetes"
+)
+
+// Options configure an embedded autoscaler. Only KubeClient is required,
+// components that aren't provided are created the same way the
+// cluster-autoscaler binary creates them.
+type Options struct {
+	config.AutoscalingOptions
+	// KubeClient is used for all calls to the API server.
+	KubeClient kube_client.Interface
+	// InformerFactory is shared with the caller, so that informers aren't
+	// duplicated in the embedding binary. If it isn't set, a new factory is
+	// created. Either way, it's started by Start.
+	InformerFactory informers.SharedInformerFactory
+	// CloudProvider manages node groups. If it isn't set, it's built from
+	// CloudProviderName.
+	CloudProvider cloudprovider.CloudProvider
+	// Processors customize the autoscaling loop. If they aren't set, the
+	// default processors are used.
+	Processors *ca_processors.AutoscalingProcessors
+	// PredicateChecker simulates scheduling. If it isn't set, a checker
+	// based on the scheduler framework configured by SchedulerConfig is used.
+	PredicateChecker predicatechecker.PredicateChecker
+	// ClusterSnapshot is used for simulations. Defaults to a delta snapshot.
+	ClusterSnapshot clustersnapshot.ClusterSnapshot
+	// HealthCheck is updated after every iteration, if set.
+	HealthCheck *metrics.HealthCheck
+	// DebuggingSnapshotter captures debugging snapshots. Disabled by default.
+	DebuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
+}
+
+// Autoscaler is an embeddable instance of the autoscaling loop.
+type Autoscaler struct {
+	autoscaler      core.Autoscaler
+	informerFactory informers.SharedInformerFactory
+	healthCheck     *metrics.HealthCheck
+}
+
+// New creates an autoscaler, filling in the options which aren't set. It
+// doesn't start any background work, see Start.
+func New(opts Options) (*Autoscaler, error) {
+	if opts.KubeClient == nil {
+		return nil, fmt.Errorf("KubeClient is required")
+	}
+	if opts.InformerFactory == nil {
+		// Trim ManagedFields for memory efficiency, the same as the binary does.
+		trim := func(obj interface{}) (interface{}, error) {
+			if accessor, err := meta.Accessor(obj); err == nil {
+				accessor.SetManagedFields(nil)
+			}
+			return obj, nil
+		}
+		opts.InformerFactory = informers.NewSharedInformerFactoryWithOptions(opts.KubeClient, 0, informers.WithTransform(trim))
+	}
+	if opts.PredicateChecker == nil {
+		predicateChecker, err := predicatechecker.NewSchedulerBasedPredicateChecker(opts.InformerFactory, opts.SchedulerConfig)
+		if err != nil {
+			return nil, err
+		}
+		opts.PredicateChecker = predicateChecker
+	}
+	if opts.ClusterSnapshot == nil {
+		opts.ClusterSnapshot = clustersnapshot.NewDeltaClusterSnapshot()
+	}
+	if opts.Processors == nil {
+		opts.Processors = ca_processors.DefaultProcessors(opts.AutoscalingOptions)
+		opts.Processors.PodListProcessor = podlistprocessor.NewDefaultPodListProcessor(opts.PredicateChecker)
+	}
+	if opts.HealthCheck == nil {
+		// Never monitored, only keeps the loop free of nil checks.
+		opts.HealthCheck = metrics.NewHealthCheck(0, 0)
+	}
+	if opts.DebuggingSnapshotter == nil {
+		opts.DebuggingSnapshotter = debuggingsnapshot.NewDebuggingSnapshotter(false, debuggingsnapshot.OutputOptions{})
+	}
+
+	autoscaler, err := core.NewAutoscaler(core.AutoscalerOptions{
+		AutoscalingOptions:   opts.AutoscalingOptions,
+		KubeClient:           opts.KubeClient,
+		InformerFactory:      opts.InformerFactory,
+		CloudProvider:        opts.CloudProvider,
+		PredicateChecker:     opts.PredicateChecker,
+		ClusterSnapshot:      opts.ClusterSnapshot,
+		Processors:           opts.Processors,
+		DebuggingSnapshotter: opts.DebuggingSnapshotter,
+		DeleteOptions:        options.NewNodeDeleteOptions(opts.AutoscalingOptions),
+	}, opts.InformerFactory)
+	if err != nil {
+		return nil, err
+	}
+	return &Autoscaler{
+		autoscaler:      autoscaler,
+		informerFactory: opts.InformerFactory,
+		healthCheck:     opts.HealthCheck,
+	}, nil
+}
+
+// Start starts the informers, waits for their caches to sync and starts
+// components of the autoscaler running in background. Informers run until
+// ctx is done.
+func (a *Autoscaler) Start(ctx context.Context) error {
+	// Informers must be started after the autoscaler is fully constructed,
+	// because additional informers might have been registered in New.
+	a.informerFactory.Start(ctx.Done())
+	for informerType, synced := range a.informerFactory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("failed to sync cache of %v informer", informerType)
+		}
+	}
+	return a.autoscaler.Start()
+}
+
+// RunOnce runs a single iteration of the autoscaling loop and returns its
+// error, if any. Metrics and the health check are updated the same way as in
+// the cluster-autoscaler binary.
+func (a *Autoscaler) RunOnce(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := loop.RunAutoscalerOnce(a.autoscaler, a.healthCheck, time.Now()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Stop cleans up state left by the autoscaler, e.g. taints it placed on nodes.
+// The autoscaler must not be used after it's stopped.
+func (a *Autoscaler) Stop() {
+	a.autoscaler.ExitCleanUp()
+}
+
+// LastScaleUpTime returns the time of the last scale up.
+func (a *Autoscaler) LastScaleUpTime() time.Time {
+	return a.autoscaler.LastScaleUpTime()
+}
+
+// LastScaleDownDeleteTime returns the time of the last scale down.
+func (a *Autoscaler) LastScaleDownDeleteTime() time.Time {
+	return a.autoscaler.LastScaleDownDeleteTime()
+}
+
+// IsActive returns true if the last iteration found unschedulable pods, or
+// scale up or scale down is in flight.
+func (a *Autoscaler) IsActive() bool {
+	return a.autoscaler.IsActive()
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autoscaler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewRequiresKubeClient(t *testing.T) {
+	_, err := New(Options{})
+	assert.Error(t, err)
+}
+
+func TestRunOnce(t *testing.T) {
+	node := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(node, true, time.Now().Add(-time.Hour))
+	scheduledPod := BuildScheduledTestPod("p1", 600, 0, "n1")
+	pendingPod := BuildTestPod("p2", 600, 0, MarkUnschedulable())
+	kubeClient := fake.NewSimpleClientset(node, scheduledPod, pendingPod)
+
+	scaledUp := make(map[string]int)
+	provider := testprovider.NewTestCloudProvider(func(id string, delta int) error {
+		scaledUp[id] += delta
+		return nil
+	}, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", node)
+
+	a, err := New(Options{
+		AutoscalingOptions: config.AutoscalingOptions{
+			NodeGroupDefaults:  config.NodeGroupAutoscalingOptions{ScaleDownUnneededTime: time.Minute},
+			ExpanderNames:      "random",
+			EstimatorName:      "binpacking",
+			MaxNodesTotal:      10,
+			MaxCoresTotal:      10,
+			MaxMemoryTotal:     100000,
+			MaxNodesPerScaleUp: 10,
+		},
+		KubeClient:    kubeClient,
+		CloudProvider: provider,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, a.Start(ctx))
+	assert.NoError(t, a.RunOnce(ctx))
+	assert.Equal(t, map[string]int{"ng1": 1}, scaledUp)
+
+	cancel()
+	assert.Equal(t, context.Canceled, a.RunOnce(ctx))
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+)
+
+// Client lists CapacityBuffers from an informer cache. The CRD has no
+// generated clientset, buffers are read through the dynamic client.
+type Client struct {
+	lister cache.GenericLister
+}
+
+// NewClient configures and returns a CapacityBuffer client.
+func NewClient(kubeConfig *rest.Config) (*Client, error) {
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create Capacity Buffer client: %v", err)
+	}
+	return newClient(dynamicClient, make(chan struct{}))
+}
+
+func newClient(dynamicClient dynamic.Interface, stopChannel <-chan struct{}) (*Client, error) {
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 1*time.Hour)
+	lister := factory.ForResource(CapacityBufferResource).Lister()
+	factory.Start(stopChannel)
+	informersSynced := factory.WaitForCacheSync(stopChannel)
+	for _, synced := range informersSynced {
+		if !synced {
+			return nil, fmt.Errorf("can't create Capacity Buffer lister")
+		}
+	}
+	klog.V(2).Info("Successful initial Capacity Buffer sync")
+	return &Client{lister: lister}, nil
+}
+
+// CapacityBuffers gets all CapacityBuffers. Buffers which can't be decoded
+// are skipped, so that a single malformed buffer doesn't drop the others.
+func (c *Client) CapacityBuffers() ([]*CapacityBuffer, error) {
+	objects, err := c.lister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching capacityBuffers: %w", err)
+	}
+	buffers := make([]*CapacityBuffer, 0, len(objects))
+	for _, object := range objects {
+		u, ok := object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		buffer := &CapacityBuffer{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, buffer); err != nil {
+			klog.Warningf("Failed to decode Capacity Buffer %s/%s: %v", u.GetNamespace(), u.GetName(), err)
+			continue
+		}
+		buffers = append(buffers, buffer)
+	}
+	return buffers, nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestCapacityBuffers(t *testing.T) {
+	valid, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testBuffer(2))
+	require.NoError(t, err)
+	validBuffer := &unstructured.Unstructured{Object: valid}
+	validBuffer.SetAPIVersion(CapacityBufferResource.GroupVersion().String())
+	validBuffer.SetKind("CapacityBuffer")
+
+	malformedBuffer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"podSets": "not a list"},
+	}}
+	malformedBuffer.SetAPIVersion(CapacityBufferResource.GroupVersion().String())
+	malformedBuffer.SetKind("CapacityBuffer")
+	malformedBuffer.SetNamespace("default")
+	malformedBuffer.SetName("malformed")
+
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{CapacityBufferResource: "CapacityBufferList"},
+		validBuffer, malformedBuffer)
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+	client, err := newClient(dynamicClient, stopChannel)
+	require.NoError(t, err)
+
+	buffers, err := client.CapacityBuffers()
+	assert.NoError(t, err)
+	if assert.Len(t, buffers, 1) {
+		assert.Equal(t, "buffer", buffers[0].Name)
+		assert.Equal(t, int32(2), buffers[0].Spec.PodSets[0].Count)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+const (
+	// CapacityBufferPodAnnotationKey is the annotation of virtual pods injected
+	// for a CapacityBuffer, set to the name of the buffer.
+	CapacityBufferPodAnnotationKey = "autoscaling.x-k8s.io/capacity-buffer"
+	// capacityBufferKind is the kind of the CapacityBuffer CRD.
+	capacityBufferKind = "CapacityBuffer"
+)
+
+// PodsForCapacityBuffer returns the virtual pods the buffer keeps room for.
+// The pods are safe to evict, so that they don't block scale-down of nodes
+// whose buffer capacity is available elsewhere in the cluster.
+func PodsForCapacityBuffer(buffer *CapacityBuffer) ([]*apiv1.Pod, error) {
+	if buffer == nil {
+		return nil, nil
+	}
+	pods := make([]*apiv1.Pod, 0)
+	for i, podSet := range buffer.Spec.PodSets {
+		if podSet.Count < 0 {
+			return nil, fmt.Errorf("pod set %d of capacity buffer %s/%s has negative count %d", i, buffer.Namespace, buffer.Name, podSet.Count)
+		}
+		for j := 0; j < int(podSet.Count); j++ {
+			pods = append(pods, podForPodSet(buffer, &podSet.Template, i, j))
+		}
+	}
+	return pods, nil
+}
+
+func podForPodSet(buffer *CapacityBuffer, template *apiv1.PodTemplateSpec, i, j int) *apiv1.Pod {
+	pod := &apiv1.Pod{
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       *template.Spec.DeepCopy(),
+	}
+	pod.Name = fmt.Sprintf("capacity-buffer-%s-%d-%d", buffer.Name, i, j)
+	pod.Namespace = buffer.Namespace
+	pod.UID = types.UID(fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+	pod.CreationTimestamp = buffer.CreationTimestamp
+	// The owner reference groups the pods as coming from one controller,
+	// which simplifies the scale-up simulation.
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: CapacityBufferResource.GroupVersion().String(),
+		Kind:       capacityBufferKind,
+		Name:       buffer.Name,
+		UID:        buffer.UID,
+		Controller: proto.Bool(true),
+	}}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[CapacityBufferPodAnnotationKey] = buffer.Name
+	pod.Annotations[drain.PodSafeToEvictKey] = "true"
+	pod.Annotations[pod_util.VirtualPodAnnotationKey] = "true"
+	return pod
+}
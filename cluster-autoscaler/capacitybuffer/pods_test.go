@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+func testBuffer(counts ...int32) *CapacityBuffer {
+	buffer := &CapacityBuffer{
+		ObjectMeta: metav1.ObjectMeta{Name: "buffer", Namespace: "default", UID: "buffer-uid"},
+	}
+	for _, count := range counts {
+		buffer.Spec.PodSets = append(buffer.Spec.PodSets, PodSet{
+			Count: count,
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "buffer"}},
+				Spec: apiv1.PodSpec{Containers: []apiv1.Container{{
+					Name: "reserve",
+					Resources: apiv1.ResourceRequirements{Requests: apiv1.ResourceList{
+						apiv1.ResourceCPU: resource.MustParse("1"),
+					}},
+				}}},
+			},
+		})
+	}
+	return buffer
+}
+
+func TestPodsForCapacityBuffer(t *testing.T) {
+	pods, err := PodsForCapacityBuffer(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+
+	buffer := testBuffer(2, 1)
+	pods, err = PodsForCapacityBuffer(buffer)
+	assert.NoError(t, err)
+	assert.Len(t, pods, 3)
+	names := map[string]bool{}
+	for _, pod := range pods {
+		names[pod.Name] = true
+		assert.Equal(t, "default", pod.Namespace)
+		assert.Equal(t, "buffer", pod.Labels["app"])
+		assert.Equal(t, "buffer", pod.Annotations[CapacityBufferPodAnnotationKey])
+		assert.True(t, drain.HasSafeToEvictAnnotation(pod))
+		assert.True(t, pod_util.IsVirtualPod(pod))
+		controller := metav1.GetControllerOf(pod)
+		if assert.NotNil(t, controller) {
+			assert.Equal(t, buffer.UID, controller.UID)
+			assert.Equal(t, "CapacityBuffer", controller.Kind)
+		}
+	}
+	assert.Equal(t, map[string]bool{"capacity-buffer-buffer-0-0": true, "capacity-buffer-buffer-0-1": true, "capacity-buffer-buffer-1-0": true}, names)
+
+	// Pods don't share the template.
+	pods[0].Labels["app"] = "changed"
+	assert.Equal(t, "buffer", buffer.Spec.PodSets[0].Template.Labels["app"])
+
+	_, err = PodsForCapacityBuffer(testBuffer(-1))
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CapacityBufferResource is the resource of the CapacityBuffer CRD.
+var CapacityBufferResource = schema.GroupVersionResource{
+	Group:    "autoscaling.x-k8s.io",
+	Version:  "v1alpha1",
+	Resource: "capacitybuffers",
+}
+
+// CapacityBuffer declares spare capacity the autoscaler keeps in the cluster,
+// as if pods of the given templates were running. It replaces overprovisioning
+// with low priority pause pods: no pods are created, the autoscaler accounts
+// for virtual pods instead.
+type CapacityBuffer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec contains the specification of the buffer.
+	Spec CapacityBufferSpec `json:"spec"`
+}
+
+// CapacityBufferSpec is the specification of a CapacityBuffer.
+type CapacityBufferSpec struct {
+	// PodSets lists the pod shapes the buffer keeps room for.
+	PodSets []PodSet `json:"podSets"`
+}
+
+// PodSet is a number of pods of the same template.
+type PodSet struct {
+	// Template is the template of the pods the buffer keeps room for.
+	Template apiv1.PodTemplateSpec `json:"template"`
+	// Count is the number of pods the buffer keeps room for.
+	Count int32 `json:"count"`
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// capacity-planner reports which node groups cluster autoscaler would scale up,
+// and by how much, to fit hypothetical workloads into an existing cluster.
+package main
+
+import (
+	ctx "context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/capacityplanner"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/factory"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/leastnodes"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/mostpods"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/random"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/waste"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
+	"k8s.io/client-go/informers"
+	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_flag "k8s.io/component-base/cli/flag"
+	klog "k8s.io/klog/v2"
+	scheduler_config "k8s.io/kubernetes/pkg/scheduler/apis/config"
+)
+
+var (
+	kubeConfigFile      = flag.String("kubeconfig", "", "Path to kubeconfig file of the cluster to plan for. Either --kubeconfig or --cluster-dump has to be set.")
+	clusterDumpFile     = flag.String("cluster-dump", "", `Path to a YAML or JSON dump of cluster objects, e.g. the output of "kubectl get nodes,pods,daemonsets,pv,pvc -A -o yaml".`)
+	inputFile           = flag.String("input", "", "Path to a YAML or JSON file describing node groups and hypothetical workloads.")
+	expanderFlag        = flag.String("expander", expander.LeastWasteExpanderName, "Type of node group expander to be used when choosing node groups. Available values: ["+strings.Join([]string{expander.RandomExpanderName, expander.MostPodsExpanderName, expander.LeastWasteExpanderName, expander.LeastNodesExpanderName}, ",")+"]. Specifying multiple values separated by commas will call the expanders in succession until there is only one option remaining.")
+	maxNodesTotal       = flag.Int("max-nodes-total", 0, "Maximum number of nodes in the cluster. 0 means no limit.")
+	schedulerConfigFile = flag.String(config.SchedulerConfigFileFlag, "", "scheduler-config allows changing configuration of in-tree scheduler plugins acting on PreFilter and Filter extension points")
+)
+
+func main() {
+	klog.InitFlags(nil)
+	kube_flag.InitFlags()
+
+	if *inputFile == "" {
+		klog.Fatalf("--input has to be set")
+	}
+	if (*kubeConfigFile == "") == (*clusterDumpFile == "") {
+		klog.Fatalf("Exactly one of --kubeconfig and --cluster-dump has to be set")
+	}
+
+	var schedConfig *scheduler_config.KubeSchedulerConfiguration
+	if pflag.CommandLine.Changed(config.SchedulerConfigFileFlag) {
+		var err error
+		schedConfig, err = scheduler_util.ConfigFromPath(*schedulerConfigFile)
+		if err != nil {
+			klog.Fatalf("Failed to get scheduler config: %v", err)
+		}
+	}
+
+	f, err := os.Open(*inputFile)
+	if err != nil {
+		klog.Fatalf("Failed to open input: %v", err)
+	}
+	input, err := capacityplanner.LoadInput(f)
+	f.Close()
+	if err != nil {
+		klog.Fatalf("Failed to read input: %v", err)
+	}
+
+	var kubeClient kube_client.Interface
+	var cluster *capacityplanner.ClusterState
+	if *clusterDumpFile != "" {
+		f, err := os.Open(*clusterDumpFile)
+		if err != nil {
+			klog.Fatalf("Failed to open cluster dump: %v", err)
+		}
+		cluster, err = capacityplanner.LoadClusterDump(f)
+		f.Close()
+		if err != nil {
+			klog.Fatalf("Failed to read cluster dump: %v", err)
+		}
+		// Objects from the dump back scheduler plugins that need listers, e.g. volume binding.
+		kubeClient = fake.NewSimpleClientset(cluster.Objects...)
+	} else {
+		kubeClient = kube_util.CreateKubeClient(config.KubeClientOptions{KubeConfigPath: *kubeConfigFile})
+		cluster, err = listClusterState(kubeClient)
+		if err != nil {
+			klog.Fatalf("Failed to list cluster objects: %v", err)
+		}
+	}
+
+	informerFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	predicateChecker, err := predicatechecker.NewSchedulerBasedPredicateChecker(informerFactory, schedConfig)
+	if err != nil {
+		klog.Fatalf("Failed to create predicate checker: %v", err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	informerFactory.Start(stop)
+	informerFactory.WaitForCacheSync(stop)
+
+	strategy, err := buildStrategy(*expanderFlag)
+	if err != nil {
+		klog.Fatalf("Failed to create expander: %v", err)
+	}
+
+	plan, err := capacityplanner.NewPlanner(predicateChecker, strategy, *maxNodesTotal).Plan(cluster, input)
+	if err != nil {
+		klog.Fatalf("Failed to compute plan: %v", err)
+	}
+	printPlan(os.Stdout, plan)
+}
+
+func listClusterState(kubeClient kube_client.Interface) (*capacityplanner.ClusterState, error) {
+	state := &capacityplanner.ClusterState{}
+	nodes, err := kubeClient.CoreV1().Nodes().List(ctx.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes.Items {
+		state.Add(&nodes.Items[i])
+	}
+	pods, err := kubeClient.CoreV1().Pods("").List(ctx.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		state.Add(&pods.Items[i])
+	}
+	daemonSets, err := kubeClient.AppsV1().DaemonSets("").List(ctx.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		state.Add(&daemonSets.Items[i])
+	}
+	return state, nil
+}
+
+func buildStrategy(names string) (expander.Strategy, error) {
+	f := factory.NewFactory()
+	f.RegisterFilter(expander.RandomExpanderName, random.NewFilter)
+	f.RegisterFilter(expander.MostPodsExpanderName, mostpods.NewFilter)
+	f.RegisterFilter(expander.LeastWasteExpanderName, waste.NewFilter)
+	f.RegisterFilter(expander.LeastNodesExpanderName, leastnodes.NewFilter)
+	strategy, err := f.Build(strings.Split(names, ","))
+	if err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+func printPlan(w io.Writer, plan *capacityplanner.Plan) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NODE GROUP\tCURRENT SIZE\tNEW NODES\tMAX SIZE\tPODS")
+	for _, scaleUp := range plan.ScaleUps {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", scaleUp.NodeGroup, scaleUp.CurrentSize, scaleUp.NewNodes, scaleUp.MaxSize, scaleUp.Pods)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nPods fitting on existing nodes: %d\n", plan.PodsOnExistingNodes)
+	fmt.Fprintf(w, "Pods that would remain unschedulable: %d\n", len(plan.UnschedulablePods))
+	for _, pod := range plan.UnschedulablePods {
+		fmt.Fprintf(w, "  %s/%s\n", pod.Namespace, pod.Name)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// NodeGroupSpec describes a node group the planner may scale up.
+type NodeGroupSpec struct {
+	// Name of the node group.
+	Name string `json:"name"`
+	// MinSize is the minimum size of the node group.
+	MinSize int `json:"minSize,omitempty"`
+	// MaxSize is the maximum size of the node group.
+	MaxSize int `json:"maxSize"`
+	// NodeSelector selects existing nodes that belong to the node group. Nodes
+	// matching it count towards the current size of the group. Empty selector
+	// means the node group has no nodes yet.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Template is the node that would be created when scaling up the group.
+	Template apiv1.Node `json:"template"`
+}
+
+// WorkloadSpec describes a hypothetical workload that should fit in the cluster.
+type WorkloadSpec struct {
+	// Name of the workload, used to name its pods.
+	Name string `json:"name"`
+	// Namespace of the workload.
+	Namespace string `json:"namespace,omitempty"`
+	// Replicas is the number of pods of the workload.
+	Replicas int `json:"replicas"`
+	// Template is the template of workload pods.
+	Template apiv1.PodTemplateSpec `json:"template"`
+}
+
+// Input holds node groups and workloads used for capacity planning.
+type Input struct {
+	NodeGroups []NodeGroupSpec `json:"nodeGroups"`
+	Workloads  []WorkloadSpec  `json:"workloads"`
+}
+
+// ClusterState holds the objects of an existing cluster used for capacity planning.
+type ClusterState struct {
+	Nodes      []*apiv1.Node
+	Pods       []*apiv1.Pod
+	DaemonSets []*appsv1.DaemonSet
+	// Objects contains all objects read from a cluster dump, including ones
+	// not used directly by the planner, e.g. persistent volumes.
+	Objects []runtime.Object
+}
+
+// LoadInput reads planning input in YAML or JSON format.
+func LoadInput(r io.Reader) (*Input, error) {
+	input := &Input{}
+	if err := yaml.NewYAMLOrJSONDecoder(r, 4096).Decode(input); err != nil {
+		return nil, fmt.Errorf("cannot decode planning input: %v", err)
+	}
+	for _, ng := range input.NodeGroups {
+		if ng.Name == "" {
+			return nil, fmt.Errorf("node group without a name")
+		}
+		if ng.MaxSize < ng.MinSize {
+			return nil, fmt.Errorf("node group %s has max size %d lower than min size %d", ng.Name, ng.MaxSize, ng.MinSize)
+		}
+	}
+	for _, w := range input.Workloads {
+		if w.Name == "" {
+			return nil, fmt.Errorf("workload without a name")
+		}
+		if w.Replicas < 0 {
+			return nil, fmt.Errorf("workload %s has negative replica count", w.Name)
+		}
+	}
+	return input, nil
+}
+
+// LoadClusterDump reads cluster objects from a YAML or JSON stream, e.g. the
+// output of `kubectl get nodes,pods,daemonsets -A -o yaml`.
+func LoadClusterDump(r io.Reader) (*ClusterState, error) {
+	state := &ClusterState{}
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("cannot decode cluster dump: %v", err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		if err := state.addRaw(raw); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+func (s *ClusterState) addRaw(raw []byte) error {
+	obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(raw, nil, nil)
+	if err != nil {
+		return fmt.Errorf("cannot decode object: %v", err)
+	}
+	if list, ok := obj.(*apiv1.List); ok {
+		for _, item := range list.Items {
+			if err := s.addRaw(item.Raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	s.Add(obj)
+	return nil
+}
+
+// Add adds an object to the cluster state.
+func (s *ClusterState) Add(obj runtime.Object) {
+	switch o := obj.(type) {
+	case *apiv1.Node:
+		s.Nodes = append(s.Nodes, o)
+	case *apiv1.Pod:
+		s.Pods = append(s.Pods, o)
+	case *appsv1.DaemonSet:
+		s.DaemonSets = append(s.DaemonSets, o)
+	}
+	s.Objects = append(s.Objects, obj)
+}
+
+// workloadPods creates pending pods for the given workload.
+func workloadPods(w WorkloadSpec) []*apiv1.Pod {
+	namespace := w.Namespace
+	if namespace == "" {
+		namespace = apiv1.NamespaceDefault
+	}
+	// All pods share a controller, so that they end up in a single equivalence group.
+	controllerUID := types.UID(fmt.Sprintf("capacity-planner-%s-%s", namespace, w.Name))
+	isController := true
+	var pods []*apiv1.Pod
+	for i := 0; i < w.Replicas; i++ {
+		pod := &apiv1.Pod{
+			ObjectMeta: *w.Template.ObjectMeta.DeepCopy(),
+			Spec:       *w.Template.Spec.DeepCopy(),
+		}
+		pod.Name = fmt.Sprintf("%s-%d", w.Name, i)
+		pod.Namespace = namespace
+		pod.UID = types.UID(fmt.Sprintf("%s-%d", controllerUID, i))
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1",
+			Kind:       "ReplicaSet",
+			Name:       w.Name,
+			UID:        controllerUID,
+			Controller: &isController,
+		}}
+		pods = append(pods, pod)
+	}
+	return pods
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLoadInput(t *testing.T) {
+	input, err := LoadInput(strings.NewReader(`
+nodeGroups:
+- name: small
+  maxSize: 10
+  nodeSelector:
+    pool: small
+  template:
+    metadata:
+      labels:
+        pool: small
+    status:
+      capacity:
+        cpu: "2"
+        memory: 8Gi
+workloads:
+- name: web
+  namespace: prod
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: web
+        resources:
+          requests:
+            cpu: 500m
+`))
+	assert.NoError(t, err)
+	assert.Len(t, input.NodeGroups, 1)
+	assert.Equal(t, "small", input.NodeGroups[0].Name)
+	assert.Equal(t, 10, input.NodeGroups[0].MaxSize)
+	assert.Equal(t, "2", input.NodeGroups[0].Template.Status.Capacity.Cpu().String())
+	assert.Len(t, input.Workloads, 1)
+
+	pods := workloadPods(input.Workloads[0])
+	assert.Len(t, pods, 3)
+	for _, pod := range pods {
+		assert.Equal(t, "prod", pod.Namespace)
+		assert.Equal(t, pods[0].OwnerReferences[0].UID, metav1.GetControllerOf(pod).UID)
+	}
+	assert.Equal(t, "web-2", pods[2].Name)
+
+	_, err = LoadInput(strings.NewReader(`
+nodeGroups:
+- name: invalid
+  minSize: 3
+  maxSize: 1
+`))
+	assert.Error(t, err)
+}
+
+func TestLoadClusterDump(t *testing.T) {
+	state, err := LoadClusterDump(strings.NewReader(`
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: n1
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: p1
+    namespace: default
+  spec:
+    nodeName: n1
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: ds
+  namespace: kube-system
+---
+apiVersion: v1
+kind: PersistentVolume
+metadata:
+  name: pv
+`))
+	assert.NoError(t, err)
+	assert.Len(t, state.Nodes, 1)
+	assert.Len(t, state.Pods, 1)
+	assert.Len(t, state.DaemonSets, 1)
+	assert.Len(t, state.Objects, 4)
+
+	_, err = LoadClusterDump(strings.NewReader("kind: Unknown\napiVersion: v1\n"))
+	assert.Error(t, err)
+}
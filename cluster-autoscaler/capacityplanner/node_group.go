@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// plannedNodeGroup is an in-memory cloudprovider.NodeGroup backed by a NodeGroupSpec.
+// Its target size only changes when the planner decides to scale it up.
+type plannedNodeGroup struct {
+	spec       NodeGroupSpec
+	targetSize int
+	template   *schedulerframework.NodeInfo
+}
+
+var _ cloudprovider.NodeGroup = &plannedNodeGroup{}
+
+func (ng *plannedNodeGroup) MaxSize() int {
+	return ng.spec.MaxSize
+}
+
+func (ng *plannedNodeGroup) MinSize() int {
+	return ng.spec.MinSize
+}
+
+func (ng *plannedNodeGroup) TargetSize() (int, error) {
+	return ng.targetSize, nil
+}
+
+func (ng *plannedNodeGroup) IncreaseSize(delta int) error {
+	if delta <= 0 {
+		return fmt.Errorf("size increase must be positive")
+	}
+	if ng.targetSize+delta > ng.spec.MaxSize {
+		return fmt.Errorf("size increase too large - desired:%d max:%d", ng.targetSize+delta, ng.spec.MaxSize)
+	}
+	ng.targetSize += delta
+	return nil
+}
+
+func (ng *plannedNodeGroup) AtomicIncreaseSize(delta int) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (ng *plannedNodeGroup) DeleteNodes([]*apiv1.Node) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (ng *plannedNodeGroup) DecreaseTargetSize(delta int) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (ng *plannedNodeGroup) Id() string {
+	return ng.spec.Name
+}
+
+func (ng *plannedNodeGroup) Debug() string {
+	return fmt.Sprintf("%s (min: %d, max: %d, target: %d)", ng.spec.Name, ng.spec.MinSize, ng.spec.MaxSize, ng.targetSize)
+}
+
+func (ng *plannedNodeGroup) Nodes() ([]cloudprovider.Instance, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (ng *plannedNodeGroup) TemplateNodeInfo() (*schedulerframework.NodeInfo, error) {
+	return ng.template, nil
+}
+
+func (ng *plannedNodeGroup) Exist() bool {
+	return true
+}
+
+func (ng *plannedNodeGroup) Create() (cloudprovider.NodeGroup, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+func (ng *plannedNodeGroup) Delete() error {
+	return cloudprovider.ErrNotImplemented
+}
+
+func (ng *plannedNodeGroup) Autoprovisioned() bool {
+	return false
+}
+
+func (ng *plannedNodeGroup) GetOptions(defaults config.NodeGroupAutoscalingOptions) (*config.NodeGroupAutoscalingOptions, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"fmt"
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/equivalence"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/scheduling"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	scheduler_utils "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
+	"k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// NodeGroupScaleUp describes how a node group would be scaled up.
+type NodeGroupScaleUp struct {
+	// NodeGroup is the name of the node group.
+	NodeGroup string
+	// CurrentSize is the number of existing nodes in the node group.
+	CurrentSize int
+	// MaxSize is the maximum size of the node group.
+	MaxSize int
+	// NewNodes is the number of nodes that would be added.
+	NewNodes int
+	// Pods is the number of pending pods that would be scheduled on the new nodes.
+	Pods int
+}
+
+// Plan is the result of capacity planning.
+type Plan struct {
+	// ScaleUps lists node groups that would be scaled up, sorted by name.
+	ScaleUps []NodeGroupScaleUp
+	// PodsOnExistingNodes is the number of pending pods that fit on existing nodes.
+	PodsOnExistingNodes int
+	// UnschedulablePods are pending pods that would not fit even after all scale-ups.
+	UnschedulablePods []*apiv1.Pod
+}
+
+// Planner simulates cluster autoscaler scale-ups for pending and hypothetical pods.
+type Planner struct {
+	predicateChecker predicatechecker.PredicateChecker
+	strategy         expander.Strategy
+	maxNodesTotal    int
+}
+
+// NewPlanner creates a new Planner. The strategy is used to choose between node groups,
+// the same way the expander does in cluster autoscaler. maxNodesTotal of 0 means no limit.
+func NewPlanner(predicateChecker predicatechecker.PredicateChecker, strategy expander.Strategy, maxNodesTotal int) *Planner {
+	return &Planner{
+		predicateChecker: predicateChecker,
+		strategy:         strategy,
+		maxNodesTotal:    maxNodesTotal,
+	}
+}
+
+// Plan computes scale-ups needed to schedule pending pods of the cluster along with the
+// pods of hypothetical workloads. Node groups are scaled up one at a time, picking the
+// best option in each step, until all pods are scheduled or no node group can help.
+func (p *Planner) Plan(cluster *ClusterState, input *Input) (*Plan, error) {
+	snapshot := clustersnapshot.NewBasicClusterSnapshot()
+	scheduledPods := make(map[string][]*apiv1.Pod)
+	var pendingPods []*apiv1.Pod
+	for _, pod := range cluster.Pods {
+		if drain.IsPodTerminal(pod) || pod.DeletionTimestamp != nil {
+			continue
+		}
+		if pod.Spec.NodeName == "" {
+			pendingPods = append(pendingPods, pod)
+		} else {
+			scheduledPods[pod.Spec.NodeName] = append(scheduledPods[pod.Spec.NodeName], pod)
+		}
+	}
+	for _, node := range cluster.Nodes {
+		if err := snapshot.AddNodeWithPods(node, scheduledPods[node.Name]); err != nil {
+			return nil, fmt.Errorf("cannot add node %s to snapshot: %v", node.Name, err)
+		}
+	}
+	for _, w := range input.Workloads {
+		pendingPods = append(pendingPods, workloadPods(w)...)
+	}
+
+	nodeGroups, err := p.buildNodeGroups(cluster, input)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &Plan{}
+	scaleUps := make(map[string]*NodeGroupScaleUp)
+	newNodeGroup := make(map[string]string)
+	nodeCount := len(cluster.Nodes)
+	hintingSimulator := scheduling.NewHintingSimulator(p.predicateChecker)
+	for {
+		statuses, _, err := hintingSimulator.TrySchedulePods(snapshot, pendingPods, scheduling.ScheduleAnywhere, false)
+		if err != nil {
+			return nil, fmt.Errorf("cannot simulate scheduling: %v", err)
+		}
+		scheduled := make(map[*apiv1.Pod]bool)
+		for _, status := range statuses {
+			scheduled[status.Pod] = true
+			if ng, found := newNodeGroup[status.NodeName]; found {
+				scaleUps[ng].Pods++
+			} else {
+				plan.PodsOnExistingNodes++
+			}
+		}
+		var stillPending []*apiv1.Pod
+		for _, pod := range pendingPods {
+			if !scheduled[pod] {
+				stillPending = append(stillPending, pod)
+			}
+		}
+		pendingPods = stillPending
+		if len(pendingPods) == 0 {
+			break
+		}
+
+		options, nodeInfos := p.computeExpansionOptions(snapshot, nodeGroups, pendingPods, nodeCount)
+		if len(options) == 0 {
+			break
+		}
+		best := p.strategy.BestOption(options, nodeInfos)
+		if best == nil {
+			break
+		}
+		ng := best.NodeGroup.(*plannedNodeGroup)
+		if err := ng.IncreaseSize(best.NodeCount); err != nil {
+			return nil, fmt.Errorf("cannot scale up node group %s: %v", ng.Id(), err)
+		}
+		klog.V(2).Infof("Scaling up node group %s by %d nodes for %d pods", ng.Id(), best.NodeCount, len(best.Pods))
+		if _, found := scaleUps[ng.Id()]; !found {
+			scaleUps[ng.Id()] = &NodeGroupScaleUp{
+				NodeGroup:   ng.Id(),
+				CurrentSize: ng.targetSize - best.NodeCount,
+				MaxSize:     ng.MaxSize(),
+			}
+		}
+		for i := 0; i < best.NodeCount; i++ {
+			nodeInfo := scheduler_utils.DeepCopyTemplateNode(ng.template, fmt.Sprintf("planned-%d", nodeCount+i))
+			var pods []*apiv1.Pod
+			for _, podInfo := range nodeInfo.Pods {
+				pods = append(pods, podInfo.Pod)
+			}
+			if err := snapshot.AddNodeWithPods(nodeInfo.Node(), pods); err != nil {
+				return nil, fmt.Errorf("cannot add planned node to snapshot: %v", err)
+			}
+			newNodeGroup[nodeInfo.Node().Name] = ng.Id()
+		}
+		scaleUps[ng.Id()].NewNodes += best.NodeCount
+		nodeCount += best.NodeCount
+	}
+
+	for _, scaleUp := range scaleUps {
+		plan.ScaleUps = append(plan.ScaleUps, *scaleUp)
+	}
+	sort.Slice(plan.ScaleUps, func(i, j int) bool { return plan.ScaleUps[i].NodeGroup < plan.ScaleUps[j].NodeGroup })
+	plan.UnschedulablePods = pendingPods
+	return plan, nil
+}
+
+func (p *Planner) buildNodeGroups(cluster *ClusterState, input *Input) ([]*plannedNodeGroup, error) {
+	var nodeGroups []*plannedNodeGroup
+	assigned := make(map[string]bool)
+	for _, spec := range input.NodeGroups {
+		template := spec.Template.DeepCopy()
+		if template.Name == "" {
+			template.Name = fmt.Sprintf("template-node-for-%s", spec.Name)
+		}
+		if len(template.Status.Allocatable) == 0 {
+			template.Status.Allocatable = template.Status.Capacity
+		}
+		nodeInfo, err := simulator.BuildNodeInfoForNode(template, nil, cluster.DaemonSets, true)
+		if err != nil {
+			return nil, fmt.Errorf("cannot build template for node group %s: %v", spec.Name, err)
+		}
+		ng := &plannedNodeGroup{spec: spec, template: nodeInfo}
+		if len(spec.NodeSelector) > 0 {
+			selector := labels.SelectorFromSet(spec.NodeSelector)
+			for _, node := range cluster.Nodes {
+				if !assigned[node.Name] && selector.Matches(labels.Set(node.Labels)) {
+					assigned[node.Name] = true
+					ng.targetSize++
+				}
+			}
+		}
+		nodeGroups = append(nodeGroups, ng)
+	}
+	return nodeGroups, nil
+}
+
+// computeExpansionOptions estimates, for every node group with remaining capacity,
+// how many nodes would be needed to schedule the pending pods that fit its template.
+func (p *Planner) computeExpansionOptions(snapshot clustersnapshot.ClusterSnapshot, nodeGroups []*plannedNodeGroup, pendingPods []*apiv1.Pod, nodeCount int) ([]expander.Option, map[string]*schedulerframework.NodeInfo) {
+	podGroups := equivalence.BuildPodGroups(pendingPods)
+	limiter := estimator.NewThresholdBasedEstimationLimiter([]estimator.Threshold{
+		estimator.NewSngCapacityThreshold(),
+		estimator.NewClusterCapacityThreshold(),
+	})
+	var options []expander.Option
+	nodeInfos := make(map[string]*schedulerframework.NodeInfo)
+	for _, ng := range nodeGroups {
+		if ng.targetSize >= ng.MaxSize() {
+			continue
+		}
+		schedulablePodGroups := p.schedulablePodGroups(snapshot, podGroups, ng)
+		if len(schedulablePodGroups) == 0 {
+			continue
+		}
+		nodeInfos[ng.Id()] = ng.template
+		binpackingEstimator := estimator.NewBinpackingNodeEstimator(p.predicateChecker, snapshot, limiter, estimator.NewDecreasingPodOrderer(), estimator.NewEstimationContext(p.maxNodesTotal, nil, nodeCount), nil, 0)
+		option := expander.Option{NodeGroup: ng, Debug: ng.Debug()}
+		option.NodeCount, option.Pods = binpackingEstimator.Estimate(schedulablePodGroups, ng.template, ng)
+		if option.NodeCount > 0 && len(option.Pods) > 0 {
+			options = append(options, option)
+		}
+	}
+	return options, nodeInfos
+}
+
+// schedulablePodGroups returns pod groups that could be scheduled on a new node from the node group.
+func (p *Planner) schedulablePodGroups(snapshot clustersnapshot.ClusterSnapshot, podGroups []*equivalence.PodGroup, ng *plannedNodeGroup) []estimator.PodEquivalenceGroup {
+	snapshot.Fork()
+	defer snapshot.Revert()
+
+	var pods []*apiv1.Pod
+	for _, podInfo := range ng.template.Pods {
+		pods = append(pods, podInfo.Pod)
+	}
+	if err := snapshot.AddNodeWithPods(ng.template.Node(), pods); err != nil {
+		klog.Errorf("Error while adding template node for %s: %v", ng.Id(), err)
+		return nil
+	}
+	var result []estimator.PodEquivalenceGroup
+	for _, eg := range podGroups {
+		if err := p.predicateChecker.CheckPredicates(snapshot, eg.Pods[0], ng.template.Node().Name); err == nil {
+			result = append(result, estimator.PodEquivalenceGroup{Pods: eg.Pods})
+		} else {
+			klog.V(4).Infof("Pod %s/%s can't be scheduled on %s: %v", eg.Pods[0].Namespace, eg.Pods[0].Name, ng.Id(), err.VerboseMessage())
+		}
+	}
+	return result
+}
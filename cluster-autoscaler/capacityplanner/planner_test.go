@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacityplanner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/random"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func podTemplate(cpu, mem int64, nodeSelector map[string]string) apiv1.PodTemplateSpec {
+	pod := BuildTestPod("template", cpu, mem)
+	pod.Spec.NodeSelector = nodeSelector
+	return apiv1.PodTemplateSpec{ObjectMeta: pod.ObjectMeta, Spec: pod.Spec}
+}
+
+func nodeTemplate(name string, cpu, mem int64, labels map[string]string) apiv1.Node {
+	node := BuildTestNode(name, cpu, mem)
+	for k, v := range labels {
+		node.Labels[k] = v
+	}
+	return *node
+}
+
+func TestPlan(t *testing.T) {
+	existingNode := BuildTestNode("n1", 2000, 2000)
+	existingNode.Labels["pool"] = "small"
+	gpuLabels := map[string]string{"pool": "large"}
+
+	testCases := []struct {
+		name                   string
+		pods                   []*apiv1.Pod
+		input                  Input
+		maxNodesTotal          int
+		expectedScaleUps       []NodeGroupScaleUp
+		expectedOnExisting     int
+		expectedUnschedulables int
+	}{
+		{
+			name: "workload fits on existing nodes",
+			input: Input{
+				NodeGroups: []NodeGroupSpec{{Name: "small", MaxSize: 10, NodeSelector: map[string]string{"pool": "small"}, Template: nodeTemplate("small", 2000, 2000, nil)}},
+				Workloads:  []WorkloadSpec{{Name: "web", Replicas: 3, Template: podTemplate(500, 100, nil)}},
+			},
+			expectedOnExisting: 3,
+		},
+		{
+			name: "workload requires scale-up",
+			pods: []*apiv1.Pod{BuildScheduledTestPod("p1", 1500, 100, "n1")},
+			input: Input{
+				NodeGroups: []NodeGroupSpec{{Name: "small", MaxSize: 10, NodeSelector: map[string]string{"pool": "small"}, Template: nodeTemplate("small", 2000, 2000, nil)}},
+				Workloads:  []WorkloadSpec{{Name: "web", Replicas: 5, Template: podTemplate(900, 100, nil)}},
+			},
+			expectedScaleUps: []NodeGroupScaleUp{{NodeGroup: "small", CurrentSize: 1, MaxSize: 10, NewNodes: 3, Pods: 5}},
+		},
+		{
+			name: "existing pending pods are included",
+			pods: []*apiv1.Pod{BuildTestPod("pending", 1500, 100), BuildScheduledTestPod("p1", 1500, 100, "n1")},
+			input: Input{
+				NodeGroups: []NodeGroupSpec{{Name: "small", MaxSize: 10, Template: nodeTemplate("small", 2000, 2000, nil)}},
+			},
+			expectedScaleUps: []NodeGroupScaleUp{{NodeGroup: "small", CurrentSize: 0, MaxSize: 10, NewNodes: 1, Pods: 1}},
+		},
+		{
+			name: "node group max size limits scale-up",
+			pods: []*apiv1.Pod{BuildScheduledTestPod("p1", 2000, 100, "n1")},
+			input: Input{
+				NodeGroups: []NodeGroupSpec{{Name: "small", MaxSize: 2, NodeSelector: map[string]string{"pool": "small"}, Template: nodeTemplate("small", 2000, 2000, nil)}},
+				Workloads:  []WorkloadSpec{{Name: "web", Replicas: 3, Template: podTemplate(1500, 100, nil)}},
+			},
+			expectedScaleUps:       []NodeGroupScaleUp{{NodeGroup: "small", CurrentSize: 1, MaxSize: 2, NewNodes: 1, Pods: 1}},
+			expectedUnschedulables: 2,
+		},
+		{
+			name: "cluster max nodes limits scale-up",
+			pods: []*apiv1.Pod{BuildScheduledTestPod("p1", 2000, 100, "n1")},
+			input: Input{
+				NodeGroups: []NodeGroupSpec{{Name: "small", MaxSize: 10, Template: nodeTemplate("small", 2000, 2000, nil)}},
+				Workloads:  []WorkloadSpec{{Name: "web", Replicas: 3, Template: podTemplate(1500, 100, nil)}},
+			},
+			maxNodesTotal:          3,
+			expectedScaleUps:       []NodeGroupScaleUp{{NodeGroup: "small", CurrentSize: 0, MaxSize: 10, NewNodes: 2, Pods: 2}},
+			expectedUnschedulables: 1,
+		},
+		{
+			name: "only matching node group is scaled up",
+			input: Input{
+				NodeGroups: []NodeGroupSpec{
+					{Name: "small", MaxSize: 10, Template: nodeTemplate("small", 2000, 2000, nil)},
+					{Name: "large", MaxSize: 10, Template: nodeTemplate("large", 8000, 8000, gpuLabels)},
+				},
+				Workloads: []WorkloadSpec{{Name: "batch", Replicas: 2, Template: podTemplate(3000, 100, gpuLabels)}},
+			},
+			expectedScaleUps: []NodeGroupScaleUp{{NodeGroup: "large", CurrentSize: 0, MaxSize: 10, NewNodes: 1, Pods: 2}},
+		},
+		{
+			name: "pods that don't fit any template stay unschedulable",
+			input: Input{
+				NodeGroups: []NodeGroupSpec{{Name: "small", MaxSize: 10, Template: nodeTemplate("small", 2000, 2000, nil)}},
+				Workloads:  []WorkloadSpec{{Name: "huge", Replicas: 2, Template: podTemplate(5000, 100, nil)}},
+			},
+			expectedUnschedulables: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			predicateChecker, err := predicatechecker.NewTestPredicateChecker()
+			assert.NoError(t, err)
+			cluster := &ClusterState{Nodes: []*apiv1.Node{existingNode}, Pods: tc.pods}
+
+			plan, err := NewPlanner(predicateChecker, random.NewStrategy(), tc.maxNodesTotal).Plan(cluster, &tc.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedScaleUps, plan.ScaleUps)
+			assert.Equal(t, tc.expectedOnExisting, plan.PodsOnExistingNodes)
+			assert.Len(t, plan.UnschedulablePods, tc.expectedUnschedulables)
+		})
+	}
+}
@@ -19,6 +19,7 @@ package aws
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/aws"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/autoscaling"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/ec2"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/eks"
 	"k8s.io/autoscaler/cluster-autoscaler/config/dynamic"
 	klog "k8s.io/klog/v2"
 )
@@ -50,6 +52,25 @@ type asgCache struct {
 	asgAutoDiscoverySpecs []asgAutoDiscoveryConfig
 	explicitlyConfigured  map[AwsRef]bool
 	autoscalingOptions    map[AwsRef]map[string]string
+	taggedInstances       map[AwsInstanceRef]bool
+
+	// detectAZRebalanceTerminations enables finding instances terminated by AZRebalance.
+	detectAZRebalanceTerminations bool
+	azRebalanceTerminations       map[AwsInstanceRef]bool
+
+	// eksNodegroups caches nodegroups of EKS clusters discovered through the EKS API.
+	eksNodegroups map[eksNodegroupRef]eksNodegroupCacheEntry
+}
+
+// eksNodegroupRef identifies a managed nodegroup of an EKS cluster.
+type eksNodegroupRef struct {
+	clusterName   string
+	nodegroupName string
+}
+
+type eksNodegroupCacheEntry struct {
+	nodegroup *eks.Nodegroup
+	fetchTime time.Time
 }
 
 type launchTemplate struct {
@@ -77,6 +98,7 @@ type asg struct {
 	LaunchTemplate          *launchTemplate
 	MixedInstancesPolicy    *mixedInstancesPolicy
 	Tags                    []*autoscaling.TagDescription
+	SuspendedProcesses      []string
 }
 
 func newASGCache(awsService *awsWrapper, explicitSpecs []string, autoDiscoverySpecs []asgAutoDiscoveryConfig) (*asgCache, error) {
@@ -92,6 +114,8 @@ func newASGCache(awsService *awsWrapper, explicitSpecs []string, autoDiscoverySp
 		asgAutoDiscoverySpecs: autoDiscoverySpecs,
 		explicitlyConfigured:  make(map[AwsRef]bool),
 		autoscalingOptions:    make(map[AwsRef]map[string]string),
+		taggedInstances:       make(map[AwsInstanceRef]bool),
+		eksNodegroups:         make(map[eksNodegroupRef]eksNodegroupCacheEntry),
 	}
 
 	if err := registry.parseExplicitAsgs(explicitSpecs); err != nil {
@@ -243,6 +267,22 @@ func (m *asgCache) InstanceStatus(ref AwsInstanceRef) (*string, error) {
 	return nil, fmt.Errorf("could not find instance %v", ref)
 }
 
+// AZRebalanceTerminations returns the instances terminated by AZRebalance.
+func (m *asgCache) AZRebalanceTerminations() map[AwsInstanceRef]bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.azRebalanceTerminations
+}
+
+// IsAZRebalanceTermination returns true if the instance is terminated by AZRebalance.
+func (m *asgCache) IsAZRebalanceTermination(ref AwsInstanceRef) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.azRebalanceTerminations[ref]
+}
+
 func (m *asgCache) findInstanceLifecycle(ref AwsInstanceRef) (*string, error) {
 	if lifecycle, found := m.instanceLifecycle[ref]; found {
 		return lifecycle, nil
@@ -402,6 +442,128 @@ func (m *asgCache) buildAsgTags() map[string]string {
 	return groupTags
 }
 
+func (m *asgCache) buildEKSClusterNames() []string {
+	clusterNames := make([]string, 0)
+	for _, spec := range m.asgAutoDiscoverySpecs {
+		if spec.ClusterName != "" {
+			clusterNames = append(clusterNames, spec.ClusterName)
+		}
+	}
+
+	return clusterNames
+}
+
+// Fetch ASGs backing managed nodegroups of the given EKS clusters, for accounts
+// where tagging policies prevent setting the auto-discovery tags. Returned
+// nodegroups are indexed by the name of their ASGs.
+func (m *asgCache) getAutoscalingGroupsByEKSClusters(clusterNames []string) ([]*autoscaling.Group, map[string]*eks.Nodegroup, error) {
+	now := time.Now()
+	nodegroupsByAsg := make(map[string]*eks.Nodegroup)
+	for _, clusterName := range clusterNames {
+		for _, nodegroup := range m.getEKSNodegroups(clusterName, now) {
+			if nodegroup.Resources == nil {
+				continue
+			}
+			for _, group := range nodegroup.Resources.AutoScalingGroups {
+				if group.Name != nil {
+					nodegroupsByAsg[*group.Name] = nodegroup
+				}
+			}
+		}
+	}
+
+	asgNames := make([]string, 0, len(nodegroupsByAsg))
+	for name := range nodegroupsByAsg {
+		asgNames = append(asgNames, name)
+	}
+	sort.Strings(asgNames)
+	groups, err := m.awsService.getAutoscalingGroupsByNames(asgNames)
+	if err != nil {
+		return nil, nil, err
+	}
+	return groups, nodegroupsByAsg, nil
+}
+
+// getEKSNodegroups returns the managed nodegroups of the EKS cluster. Nodegroups
+// are described again once their cache entry is older than managedNodegroupCachedTTL.
+// Failures degrade per nodegroup: the last known nodegroup is used if it can't be
+// described, and the last known nodegroups of the cluster if they can't be listed,
+// so that their ASGs aren't unregistered because of transient EKS API errors.
+func (m *asgCache) getEKSNodegroups(clusterName string, now time.Time) []*eks.Nodegroup {
+	names, err := m.awsService.listEKSNodegroups(clusterName)
+	if err != nil {
+		klog.Errorf("Failed to list nodegroups of EKS cluster %s, using cached nodegroups: %v", clusterName, err)
+		names = nil
+		for ref := range m.eksNodegroups {
+			if ref.clusterName == clusterName {
+				names = append(names, ref.nodegroupName)
+			}
+		}
+		sort.Strings(names)
+	} else {
+		listed := make(map[string]bool, len(names))
+		for _, name := range names {
+			listed[name] = true
+		}
+		for ref := range m.eksNodegroups {
+			if ref.clusterName == clusterName && !listed[ref.nodegroupName] {
+				delete(m.eksNodegroups, ref)
+			}
+		}
+	}
+
+	nodegroups := make([]*eks.Nodegroup, 0, len(names))
+	for _, name := range names {
+		ref := eksNodegroupRef{clusterName: clusterName, nodegroupName: name}
+		entry, found := m.eksNodegroups[ref]
+		if !found || now.Sub(entry.fetchTime) >= managedNodegroupCachedTTL {
+			nodegroup, err := m.awsService.describeEKSNodegroup(clusterName, name)
+			if err != nil {
+				if !found {
+					klog.Errorf("Failed to describe nodegroup %s of EKS cluster %s, skipping it: %v", name, clusterName, err)
+					continue
+				}
+				klog.Warningf("Failed to describe nodegroup %s of EKS cluster %s, using cached nodegroup: %v", name, clusterName, err)
+			} else {
+				entry = eksNodegroupCacheEntry{nodegroup: nodegroup, fetchTime: now}
+				m.eksNodegroups[ref] = entry
+			}
+		}
+		nodegroups = append(nodegroups, entry.nodegroup)
+	}
+	return nodegroups
+}
+
+// mergeAutoscalingGroups merges ASGs fetched from several sources, keeping the
+// first occurrence of each ASG. Conflicts between scaling configs of EKS
+// nodegroups and their ASGs are reported; the ASG values always take effect.
+func mergeAutoscalingGroups(sources [][]*autoscaling.Group, nodegroupsByAsg map[string]*eks.Nodegroup) []*autoscaling.Group {
+	seen := make(map[string]bool)
+	groups := make([]*autoscaling.Group, 0)
+	for _, source := range sources {
+		for _, group := range source {
+			name := aws.StringValue(group.AutoScalingGroupName)
+			if seen[name] {
+				klog.V(4).Infof("ASG %s discovered by multiple sources", name)
+				continue
+			}
+			seen[name] = true
+			groups = append(groups, group)
+
+			nodegroup, found := nodegroupsByAsg[name]
+			if !found || nodegroup.ScalingConfig == nil {
+				continue
+			}
+			minSize, maxSize := aws.Int64Value(nodegroup.ScalingConfig.MinSize), aws.Int64Value(nodegroup.ScalingConfig.MaxSize)
+			if minSize != aws.Int64Value(group.MinSize) || maxSize != aws.Int64Value(group.MaxSize) {
+				klog.Warningf("Scaling config of EKS nodegroup %s (min %d, max %d) conflicts with its ASG %s (min %d, max %d), using ASG values",
+					aws.StringValue(nodegroup.NodegroupName), minSize, maxSize, name, aws.Int64Value(group.MinSize), aws.Int64Value(group.MaxSize))
+			}
+		}
+	}
+	return groups
+}
+
 func (m *asgCache) buildAsgNames() []string {
 	refreshNames := make([]string, len(m.explicitlyConfigured))
 	i := 0
@@ -438,7 +600,14 @@ func (m *asgCache) regenerate() error {
 		return err
 	}
 
-	groups := append(namedGroups, taggedGroups...)
+	refreshClusterNames := m.buildEKSClusterNames()
+	klog.V(4).Infof("Regenerating instance to ASG map for EKS clusters: %v", refreshClusterNames)
+	eksGroups, nodegroupsByAsg, err := m.getAutoscalingGroupsByEKSClusters(refreshClusterNames)
+	if err != nil {
+		return err
+	}
+
+	groups := mergeAutoscalingGroups([][]*autoscaling.Group{namedGroups, taggedGroups, eksGroups}, nodegroupsByAsg)
 
 	// If currently any ASG has more Desired than running Instances, introduce placeholders
 	// for the instances to come up. This is required to track Desired instances that
@@ -494,6 +663,11 @@ func (m *asgCache) regenerate() error {
 	m.autoscalingOptions = newAutoscalingOptions
 	m.instanceStatus = newInstanceStatusMap
 	m.instanceLifecycle = newInstanceLifecycleMap
+	if m.detectAZRebalanceTerminations {
+		m.azRebalanceTerminations = m.findAZRebalanceTerminations(groups)
+	}
+
+	m.propagateInstanceTags()
 	return nil
 }
 
@@ -581,6 +755,10 @@ func (m *asgCache) buildAsgFromAWS(g *autoscaling.Group) (*asg, error) {
 		Tags:                    g.Tags,
 	}
 
+	for _, process := range g.SuspendedProcesses {
+		asg.SuspendedProcesses = append(asg.SuspendedProcesses, aws.StringValue(process.ProcessName))
+	}
+
 	if g.LaunchTemplate != nil {
 		asg.LaunchTemplate = buildLaunchTemplateFromSpec(g.LaunchTemplate)
 	}
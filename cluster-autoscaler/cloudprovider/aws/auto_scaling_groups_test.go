@@ -22,8 +22,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/aws"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/autoscaling"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/eks"
 )
 
 func TestBuildAsg(t *testing.T) {
@@ -166,3 +168,93 @@ func TestCreatePlaceholders(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeAutoscalingGroups(t *testing.T) {
+	group := func(name string, min, max int64) *autoscaling.Group {
+		return &autoscaling.Group{
+			AutoScalingGroupName: aws.String(name),
+			MinSize:              aws.Int64(min),
+			MaxSize:              aws.Int64(max),
+		}
+	}
+	named := []*autoscaling.Group{group("named", 1, 5)}
+	tagged := []*autoscaling.Group{group("tagged", 0, 3), group("both", 0, 10)}
+	eksDiscovered := []*autoscaling.Group{group("both", 0, 10), group("eks", 2, 4)}
+	nodegroupsByAsg := map[string]*eks.Nodegroup{
+		"both": {
+			NodegroupName: aws.String("ng-both"),
+			ScalingConfig: &eks.NodegroupScalingConfig{MinSize: aws.Int64(0), MaxSize: aws.Int64(10)},
+		},
+		"eks": {
+			// Conflicting scaling config, the ASG values are used.
+			NodegroupName: aws.String("ng-eks"),
+			ScalingConfig: &eks.NodegroupScalingConfig{MinSize: aws.Int64(1), MaxSize: aws.Int64(4)},
+		},
+	}
+
+	groups := mergeAutoscalingGroups([][]*autoscaling.Group{named, tagged, eksDiscovered}, nodegroupsByAsg)
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = *g.AutoScalingGroupName
+	}
+	assert.Equal(t, []string{"named", "tagged", "both", "eks"}, names)
+	assert.Equal(t, int64(2), *groups[3].MinSize)
+}
+
+func TestGetEKSNodegroups(t *testing.T) {
+	clusterName := "cluster"
+	listInput := &eks.ListNodegroupsInput{ClusterName: aws.String(clusterName)}
+	listNodegroups := func(names ...string) func(args mock.Arguments) {
+		return func(args mock.Arguments) {
+			fn := args.Get(1).(func(*eks.ListNodegroupsOutput, bool) bool)
+			fn(&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice(names)}, true)
+		}
+	}
+	describeInput := func(name string) *eks.DescribeNodegroupInput {
+		return &eks.DescribeNodegroupInput{ClusterName: aws.String(clusterName), NodegroupName: aws.String(name)}
+	}
+	nodegroup := func(name string, maxSize int64) *eks.Nodegroup {
+		return &eks.Nodegroup{NodegroupName: aws.String(name), ScalingConfig: &eks.NodegroupScalingConfig{MaxSize: aws.Int64(maxSize)}}
+	}
+	describeOutput := func(name string, maxSize int64) *eks.DescribeNodegroupOutput {
+		return &eks.DescribeNodegroupOutput{Nodegroup: nodegroup(name, maxSize)}
+	}
+	listFunc := mock.AnythingOfType("func(*eks.ListNodegroupsOutput, bool) bool")
+
+	k := &eksMock{}
+	// First refresh: ng2 can't be described and is skipped.
+	k.On("ListNodegroupsPages", listInput, listFunc).Run(listNodegroups("ng1", "ng2")).Return(nil).Once()
+	k.On("DescribeNodegroup", describeInput("ng1")).Return(describeOutput("ng1", 1), nil).Once()
+	k.On("DescribeNodegroup", describeInput("ng2")).Return(nil, errors.New("throttled")).Once()
+	// Second refresh: ng1 is cached, ng2 is described again.
+	k.On("ListNodegroupsPages", listInput, listFunc).Run(listNodegroups("ng1", "ng2")).Return(nil).Once()
+	k.On("DescribeNodegroup", describeInput("ng2")).Return(describeOutput("ng2", 2), nil).Once()
+	// Third refresh, after the cache expired: the cached nodegroups are used for failing calls.
+	k.On("ListNodegroupsPages", listInput, listFunc).Return(errors.New("throttled")).Once()
+	k.On("DescribeNodegroup", describeInput("ng1")).Return(nil, errors.New("throttled")).Once()
+	k.On("DescribeNodegroup", describeInput("ng2")).Return(describeOutput("ng2", 3), nil).Once()
+	// Fourth refresh: ng1 was deleted.
+	k.On("ListNodegroupsPages", listInput, listFunc).Run(listNodegroups("ng2")).Return(nil).Once()
+
+	m := &asgCache{
+		awsService:    &awsWrapper{nil, nil, k},
+		eksNodegroups: make(map[eksNodegroupRef]eksNodegroupCacheEntry),
+	}
+	now := time.Now()
+
+	assert.Equal(t, []*eks.Nodegroup{nodegroup("ng1", 1)}, m.getEKSNodegroups(clusterName, now))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, []*eks.Nodegroup{nodegroup("ng1", 1), nodegroup("ng2", 2)}, m.getEKSNodegroups(clusterName, now))
+
+	now = now.Add(managedNodegroupCachedTTL)
+	assert.Equal(t, []*eks.Nodegroup{nodegroup("ng1", 1), nodegroup("ng2", 3)}, m.getEKSNodegroups(clusterName, now))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, []*eks.Nodegroup{nodegroup("ng2", 3)}, m.getEKSNodegroups(clusterName, now))
+	assert.Equal(t, map[eksNodegroupRef]eksNodegroupCacheEntry{
+		{clusterName: clusterName, nodegroupName: "ng2"}: {nodegroup: nodegroup("ng2", 3), fetchTime: now.Add(-time.Minute)},
+	}, m.eksNodegroups)
+
+	k.AssertExpectations(t)
+}
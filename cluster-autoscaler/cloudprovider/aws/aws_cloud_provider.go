@@ -22,6 +22,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -189,6 +190,18 @@ func (aws *awsCloudProvider) Refresh() error {
 	return aws.awsManager.Refresh()
 }
 
+// MaintenanceEvents returns instances terminated by ASG AZRebalance as termination events, so
+// that their nodes are drained and their pods get replacement capacity before the instances are
+// gone. Returns no events unless detecting AZRebalance terminations is enabled.
+func (aws *awsCloudProvider) MaintenanceEvents() ([]cloudprovider.MaintenanceEvent, error) {
+	return aws.awsManager.azRebalanceMaintenanceEvents(time.Now()), nil
+}
+
+// RediscoverNodeGroups regenerates the ASG cache, regardless of the last refresh.
+func (aws *awsCloudProvider) RediscoverNodeGroups() error {
+	return aws.awsManager.forceRefresh()
+}
+
 // AwsRef contains a reference to some entity in AWS world.
 type AwsRef struct {
 	Name string
@@ -371,6 +384,10 @@ func (ng *AwsNodeGroup) Nodes() ([]cloudprovider.Instance, error) {
 		instanceStatusString, err := ng.awsManager.GetInstanceStatus(asgNode)
 		if err != nil {
 			klog.V(4).Infof("Could not get instance status, continuing anyways: %v", err)
+		} else if ng.awsManager.asgCache.IsAZRebalanceTermination(asgNode) {
+			status = &cloudprovider.InstanceStatus{
+				State: cloudprovider.InstanceDeleting,
+			}
 		} else if instanceStatusString != nil && *instanceStatusString == placeholderUnfulfillableStatus {
 			status = &cloudprovider.InstanceStatus{
 				State: cloudprovider.InstanceCreating,
@@ -423,42 +440,26 @@ func BuildAWS(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscover
 		klog.Fatalf("Failed to create AWS SDK Provider: %v", err)
 	}
 
-	// Generate EC2 list
-	instanceTypes, lastUpdateTime := GetStaticEC2InstanceTypes()
+	// EC2 instance types are fetched from the EC2 API when the manager is refreshed,
+	// falling back to the static list.
+	staticInstanceTypes, lastUpdateTime := GetStaticEC2InstanceTypes()
+	var instanceTypes *instanceTypeCatalog
 	if opts.AWSUseStaticInstanceList {
 		klog.Warningf("Using static EC2 Instance Types, this list could be outdated. Last update time: %s", lastUpdateTime)
+		instanceTypes = newInstanceTypeCatalog(staticInstanceTypes, nil)
 	} else {
-		generatedInstanceTypes, err := GenerateEC2InstanceTypes(sdkProvider.session)
-		if err != nil {
-			klog.Errorf("Failed to generate AWS EC2 Instance Types: %v, falling back to static list with last update time: %s", err, lastUpdateTime)
-		}
-		if generatedInstanceTypes == nil {
-			generatedInstanceTypes = map[string]*InstanceType{}
-		}
-		// fallback on the static list if we miss any instance types in the generated output
-		// credits to: https://github.com/lyft/cni-ipvlan-vpc-k8s/pull/80
-		for k, v := range instanceTypes {
-			_, ok := generatedInstanceTypes[k]
-			if ok {
-				continue
-			}
-			klog.Infof("Using static instance type %s", k)
-			generatedInstanceTypes[k] = v
-		}
-		instanceTypes = generatedInstanceTypes
-
-		keys := make([]string, 0, len(instanceTypes))
-		for key := range instanceTypes {
-			keys = append(keys, key)
-		}
-
-		klog.Infof("Successfully load %d EC2 Instance Types %s", len(keys), keys)
+		instanceTypes = newInstanceTypeCatalog(staticInstanceTypes, func() (map[string]*InstanceType, error) {
+			return GenerateEC2InstanceTypes(sdkProvider.session)
+		})
 	}
 
 	manager, err := CreateAwsManager(sdkProvider, do, instanceTypes)
 	if err != nil {
 		klog.Fatalf("Failed to create AWS Manager: %v", err)
 	}
+	manager.suspendAZRebalanceProcess = opts.AWSSuspendAZRebalance
+	manager.dryRun = opts.DryRun
+	manager.asgCache.detectAZRebalanceTerminations = opts.AWSDrainAZRebalanceTerminations
 
 	provider, err := BuildAwsCloudProvider(manager, rl)
 	if err != nil {
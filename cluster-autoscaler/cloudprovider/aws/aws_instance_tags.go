@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/aws"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/autoscaling"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/ec2"
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	// propagateInstanceTagsTag enables writing the node template labels and
+	// taints of an ASG as tags of its instances.
+	propagateInstanceTagsTag = "k8s.io/cluster-autoscaler/node-template/propagate-to-instance-tags"
+	labelTagsPrefix          = "k8s.io/cluster-autoscaler/node-template/label/"
+	instanceLabelTagsPrefix  = "k8s.io/cluster-autoscaler/label/"
+	instanceTaintTagsPrefix  = "k8s.io/cluster-autoscaler/taint/"
+)
+
+// instanceTagsPropagationEnabled checks whether the ASG opted in to the
+// propagation of its node template labels and taints to instance tags.
+func instanceTagsPropagationEnabled(tags []*autoscaling.TagDescription) bool {
+	for _, tag := range tags {
+		if aws.StringValue(tag.Key) == propagateInstanceTagsTag {
+			return strings.EqualFold(aws.StringValue(tag.Value), "true")
+		}
+	}
+	return false
+}
+
+// buildInstanceTags builds the EC2 tags of instances of an ASG from the node
+// template labels and taints defined by the ASG tags.
+func buildInstanceTags(tags []*autoscaling.TagDescription) []*ec2.Tag {
+	var result []*ec2.Tag
+	for _, tag := range tags {
+		label := strings.TrimPrefix(aws.StringValue(tag.Key), labelTagsPrefix)
+		if label == aws.StringValue(tag.Key) || label == "" {
+			continue
+		}
+		result = append(result, &ec2.Tag{
+			Key:   aws.String(instanceLabelTagsPrefix + label),
+			Value: tag.Value,
+		})
+	}
+	for _, taint := range extractTaintsFromAsg(tags) {
+		result = append(result, &ec2.Tag{
+			Key:   aws.String(instanceTaintTagsPrefix + taint.Key),
+			Value: aws.String(fmt.Sprintf("%s:%s", taint.Value, taint.Effect)),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return aws.StringValue(result[i].Key) < aws.StringValue(result[j].Key)
+	})
+	return result
+}
+
+// propagateInstanceTags tags the instances of ASGs which opted in to the
+// propagation with their node template labels and taints, so that cost
+// allocation tooling can attribute instances to workloads. Instances are
+// tagged once, failures are retried on the next refresh.
+func (m *asgCache) propagateInstanceTags() {
+	tagged := make(map[AwsInstanceRef]bool)
+	for ref, asg := range m.registeredAsgs {
+		if !instanceTagsPropagationEnabled(asg.Tags) {
+			continue
+		}
+		tags := buildInstanceTags(asg.Tags)
+		if len(tags) == 0 {
+			continue
+		}
+
+		var instances []AwsInstanceRef
+		var instanceIds []*string
+		for _, instance := range m.asgToInstances[ref] {
+			if m.isPlaceholderInstance(&instance) {
+				continue
+			}
+			if m.taggedInstances[instance] {
+				tagged[instance] = true
+				continue
+			}
+			instances = append(instances, instance)
+			instanceIds = append(instanceIds, aws.String(instance.Name))
+		}
+		if len(instanceIds) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		_, err := m.awsService.CreateTags(&ec2.CreateTagsInput{
+			Resources: instanceIds,
+			Tags:      tags,
+		})
+		observeAWSRequest("CreateTags", err, start)
+		if err != nil {
+			klog.Warningf("Failed to propagate node template labels and taints of ASG %s to instance tags: %v", ref.Name, err)
+			continue
+		}
+		klog.V(4).Infof("Propagated node template labels and taints of ASG %s to %d instances", ref.Name, len(instanceIds))
+		for _, instance := range instances {
+			tagged[instance] = true
+		}
+	}
+	m.taggedInstances = tagged
+}
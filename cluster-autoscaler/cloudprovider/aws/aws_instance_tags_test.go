@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/aws"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/autoscaling"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/ec2"
+)
+
+func TestBuildInstanceTags(t *testing.T) {
+	tags := []*autoscaling.TagDescription{
+		{Key: aws.String("k8s.io/cluster-autoscaler/node-template/label/team"), Value: aws.String("payments")},
+		{Key: aws.String("k8s.io/cluster-autoscaler/node-template/taint/dedicated"), Value: aws.String("batch:NoSchedule")},
+		{Key: aws.String("k8s.io/cluster-autoscaler/node-template/resources/ephemeral-storage"), Value: aws.String("100G")},
+		{Key: aws.String("eks:cluster-name"), Value: aws.String("test")},
+		{Key: aws.String(propagateInstanceTagsTag), Value: aws.String("true")},
+	}
+
+	assert.Equal(t, []*ec2.Tag{
+		{Key: aws.String("k8s.io/cluster-autoscaler/label/team"), Value: aws.String("payments")},
+		{Key: aws.String("k8s.io/cluster-autoscaler/taint/dedicated"), Value: aws.String("batch:NoSchedule")},
+	}, buildInstanceTags(tags))
+	assert.True(t, instanceTagsPropagationEnabled(tags))
+	assert.False(t, instanceTagsPropagationEnabled(tags[:4]))
+}
+
+func TestPropagateInstanceTags(t *testing.T) {
+	e := &ec2Mock{}
+	awsService := awsWrapper{nil, e, nil}
+	labelTag := &autoscaling.TagDescription{Key: aws.String("k8s.io/cluster-autoscaler/node-template/label/team"), Value: aws.String("payments")}
+	enabled := &asg{
+		AwsRef: AwsRef{Name: "enabled"},
+		Tags:   []*autoscaling.TagDescription{labelTag, {Key: aws.String(propagateInstanceTagsTag), Value: aws.String("true")}},
+	}
+	disabled := &asg{
+		AwsRef: AwsRef{Name: "disabled"},
+		Tags:   []*autoscaling.TagDescription{labelTag},
+	}
+	cache := &asgCache{
+		awsService:     &awsService,
+		registeredAsgs: map[AwsRef]*asg{enabled.AwsRef: enabled, disabled.AwsRef: disabled},
+		asgToInstances: map[AwsRef][]AwsInstanceRef{
+			enabled.AwsRef: {
+				{Name: "i-1"},
+				{Name: placeholderInstanceNamePrefix + "-enabled-1"},
+			},
+			disabled.AwsRef: {{Name: "i-2"}},
+		},
+		taggedInstances: make(map[AwsInstanceRef]bool),
+	}
+	input := func(ids ...string) *ec2.CreateTagsInput {
+		return &ec2.CreateTagsInput{
+			Resources: aws.StringSlice(ids),
+			Tags:      []*ec2.Tag{{Key: aws.String("k8s.io/cluster-autoscaler/label/team"), Value: aws.String("payments")}},
+		}
+	}
+
+	// Failures are retried on the next refresh.
+	e.On("CreateTags", input("i-1")).Return(&ec2.CreateTagsOutput{}, errors.New("throttled")).Once()
+	cache.propagateInstanceTags()
+	assert.Empty(t, cache.taggedInstances)
+
+	e.On("CreateTags", input("i-1")).Return(&ec2.CreateTagsOutput{}, nil).Once()
+	cache.propagateInstanceTags()
+	assert.Equal(t, map[AwsInstanceRef]bool{{Name: "i-1"}: true}, cache.taggedInstances)
+
+	// Only new instances are tagged.
+	cache.asgToInstances[enabled.AwsRef] = append(cache.asgToInstances[enabled.AwsRef], AwsInstanceRef{Name: "i-3"})
+	e.On("CreateTags", input("i-3")).Return(&ec2.CreateTagsOutput{}, nil).Once()
+	cache.propagateInstanceTags()
+	assert.Equal(t, map[AwsInstanceRef]bool{{Name: "i-1"}: true, {Name: "i-3"}: true}, cache.taggedInstances)
+
+	e.AssertExpectations(t)
+	e.AssertNumberOfCalls(t, "CreateTags", 3)
+}
@@ -42,15 +42,17 @@ import (
 )
 
 const (
-	operationWaitTimeout    = 5 * time.Second
-	operationPollInterval   = 100 * time.Millisecond
-	maxRecordsReturnedByAPI = 100
-	maxAsgNamesPerDescribe  = 100
-	refreshInterval         = 1 * time.Minute
-	autoDiscovererTypeASG   = "asg"
-	asgAutoDiscovererKeyTag = "tag"
-	optionsTagsPrefix       = "k8s.io/cluster-autoscaler/node-template/autoscaling-options/"
-	labelAwsCSITopologyZone = "topology.ebs.csi.aws.com/zone"
+	operationWaitTimeout        = 5 * time.Second
+	operationPollInterval       = 100 * time.Millisecond
+	maxRecordsReturnedByAPI     = 100
+	maxAsgNamesPerDescribe      = 100
+	refreshInterval             = 1 * time.Minute
+	autoDiscovererTypeASG       = "asg"
+	asgAutoDiscovererKeyTag     = "tag"
+	autoDiscovererTypeEKS       = "eks"
+	eksAutoDiscovererKeyCluster = "cluster"
+	optionsTagsPrefix           = "k8s.io/cluster-autoscaler/node-template/autoscaling-options/"
+	labelAwsCSITopologyZone     = "topology.ebs.csi.aws.com/zone"
 )
 
 // AwsManager is handles aws communication and data caching.
@@ -58,8 +60,12 @@ type AwsManager struct {
 	awsService            awsWrapper
 	asgCache              *asgCache
 	lastRefresh           time.Time
-	instanceTypes         map[string]*InstanceType
+	instanceTypes         *instanceTypeCatalog
 	managedNodegroupCache *managedNodegroupCache
+	// suspendAZRebalanceProcess enables suspending AZRebalance of the registered ASGs.
+	suspendAZRebalanceProcess bool
+	// dryRun makes suspending AZRebalance only log the ASGs it would be suspended for.
+	dryRun bool
 }
 
 type asgTemplate struct {
@@ -74,7 +80,7 @@ func createAWSManagerInternal(
 	awsSDKProvider *awsSDKProvider,
 	discoveryOpts cloudprovider.NodeGroupDiscoveryOptions,
 	awsService *awsWrapper,
-	instanceTypes map[string]*InstanceType,
+	instanceTypes *instanceTypeCatalog,
 ) (*AwsManager, error) {
 	klog.Infof("AWS SDK Version: %s", aws.SDKVersion)
 
@@ -110,7 +116,7 @@ func createAWSManagerInternal(
 }
 
 // CreateAwsManager constructs awsManager object.
-func CreateAwsManager(awsSDKProvider *awsSDKProvider, discoveryOpts cloudprovider.NodeGroupDiscoveryOptions, instanceTypes map[string]*InstanceType) (*AwsManager, error) {
+func CreateAwsManager(awsSDKProvider *awsSDKProvider, discoveryOpts cloudprovider.NodeGroupDiscoveryOptions, instanceTypes *instanceTypeCatalog) (*AwsManager, error) {
 	return createAWSManagerInternal(awsSDKProvider, discoveryOpts, nil, instanceTypes)
 }
 
@@ -128,6 +134,12 @@ func (m *AwsManager) forceRefresh() error {
 		klog.Errorf("Failed to regenerate ASG cache: %v", err)
 		return err
 	}
+	if m.suspendAZRebalanceProcess {
+		m.suspendAZRebalance()
+	}
+	if m.instanceTypes != nil {
+		m.instanceTypes.refreshIfExpired()
+	}
 	m.lastRefresh = time.Now()
 	klog.V(2).Infof("Refreshed ASG list, next refresh after %v", m.lastRefresh.Add(refreshInterval))
 	return nil
@@ -193,7 +205,7 @@ func (m *AwsManager) getAsgTemplate(asg *asg) (*asgTemplate, error) {
 		return nil, err
 	}
 
-	if t, ok := m.instanceTypes[instanceTypeName]; ok {
+	if t, ok := m.instanceTypes.get(instanceTypeName); ok {
 		return &asgTemplate{
 			InstanceType: t,
 			Region:       region,
@@ -257,6 +269,43 @@ func (m *AwsManager) GetAsgOptions(asg asg, defaults config.NodeGroupAutoscaling
 		}
 	}
 
+	if stringOpt, found := options[config.DefaultMaxConcurrentProvisioningKey]; found {
+		if opt, err := strconv.Atoi(stringOpt); err != nil {
+			klog.Warningf("failed to convert asg %s %s tag to int: %v",
+				asg.Name, config.DefaultMaxConcurrentProvisioningKey, err)
+		} else {
+			defaults.MaxConcurrentProvisioning = opt
+		}
+	}
+
+	if stringOpt, found := options[config.DefaultMaxScaleDownParallelismKey]; found {
+		if opt, err := strconv.Atoi(stringOpt); err != nil {
+			klog.Warningf("failed to convert asg %s %s tag to int: %v",
+				asg.Name, config.DefaultMaxScaleDownParallelismKey, err)
+		} else {
+			defaults.MaxScaleDownParallelism = opt
+		}
+	}
+
+	if stringOpt, found := options[config.DefaultMaxNodeDrainTimeKey]; found {
+		if opt, err := time.ParseDuration(stringOpt); err != nil {
+			klog.Warningf("failed to convert asg %s %s tag to duration: %v",
+				asg.Name, config.DefaultMaxNodeDrainTimeKey, err)
+		} else {
+			defaults.MaxNodeDrainTime = opt
+		}
+	}
+
+	if stringOpt, found := options[config.DefaultNodeDrainTimeoutPolicyKey]; found {
+		if stringOpt != config.DrainTimeoutPolicyAbort && stringOpt != config.DrainTimeoutPolicyForceDelete {
+			klog.Warningf("invalid asg %s %s tag value: %q", asg.Name, config.DefaultNodeDrainTimeoutPolicyKey, stringOpt)
+		} else {
+			defaults.NodeDrainTimeoutPolicy = stringOpt
+		}
+	}
+
+	defaults.Metadata = config.MergeNodeGroupMetadata(defaults.Metadata, options)
+
 	return &defaults
 }
 
@@ -506,6 +555,9 @@ type asgAutoDiscoveryConfig struct {
 	// Tags to match on.
 	// Any ASG with all of the provided tag keys will be autoscaled.
 	Tags map[string]string
+	// ClusterName of an EKS cluster. ASGs backing managed nodegroups of the
+	// cluster will be autoscaled, regardless of their tags.
+	ClusterName string
 }
 
 // ParseASGAutoDiscoverySpecs returns any provided NodeGroupAutoDiscoverySpecs
@@ -530,7 +582,7 @@ func parseASGAutoDiscoverySpec(spec string) (asgAutoDiscoveryConfig, error) {
 		return cfg, fmt.Errorf("invalid node group auto discovery spec specified via --node-group-auto-discovery: %s", spec)
 	}
 	discoverer := tokens[0]
-	if discoverer != autoDiscovererTypeASG {
+	if discoverer != autoDiscovererTypeASG && discoverer != autoDiscovererTypeEKS {
 		return cfg, fmt.Errorf("unsupported discoverer specified: %s", discoverer)
 	}
 	param := tokens[1]
@@ -539,6 +591,16 @@ func parseASGAutoDiscoverySpec(spec string) (asgAutoDiscoveryConfig, error) {
 		return cfg, fmt.Errorf("invalid key=value pair %s", kv)
 	}
 	k, v := kv[0], kv[1]
+	if discoverer == autoDiscovererTypeEKS {
+		if k != eksAutoDiscovererKeyCluster {
+			return cfg, fmt.Errorf("unsupported parameter key \"%s\" is specified for discoverer \"%s\". The only supported key is \"%s\"", k, discoverer, eksAutoDiscovererKeyCluster)
+		}
+		if v == "" {
+			return cfg, errors.New("cluster name not supplied")
+		}
+		cfg.ClusterName = v
+		return cfg, nil
+	}
 	if k != asgAutoDiscovererKeyTag {
 		return cfg, fmt.Errorf("unsupported parameter key \"%s\" is specified for discoverer \"%s\". The only supported key is \"%s\"", k, discoverer, asgAutoDiscovererKeyTag)
 	}
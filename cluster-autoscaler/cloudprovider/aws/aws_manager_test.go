@@ -34,6 +34,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/aws"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/autoscaling"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/ec2"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/eks"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 )
@@ -153,6 +154,10 @@ func TestGetAsgOptions(t *testing.T) {
 				config.DefaultScaleDownUnneededTimeKey:         "not-a-duration",
 				"ScaleDownUnreadyTime":                         "",
 				config.DefaultIgnoreDaemonSetsUtilizationKey:   "not-a-bool",
+				config.DefaultMaxConcurrentProvisioningKey:     "not-an-int",
+				config.DefaultMaxScaleDownParallelismKey:       "not-an-int",
+				config.DefaultMaxNodeDrainTimeKey:              "not-a-duration",
+				config.DefaultNodeDrainTimeoutPolicyKey:        "not-a-policy",
 			},
 			expected: &defaultOptions,
 		},
@@ -179,6 +184,11 @@ func TestGetAsgOptions(t *testing.T) {
 				config.DefaultScaleDownGpuUtilizationThresholdKey: "0.7",
 				config.DefaultScaleDownUnreadyTimeKey:             "25m",
 				config.DefaultIgnoreDaemonSetsUtilizationKey:      "true",
+				config.DefaultMaxConcurrentProvisioningKey:        "3",
+				config.DefaultMaxScaleDownParallelismKey:          "5",
+				config.DefaultMaxNodeDrainTimeKey:                 "30m",
+				config.DefaultNodeDrainTimeoutPolicyKey:           config.DrainTimeoutPolicyForceDelete,
+				config.NodeGroupMetadataKeyPrefix + "costclass":   "spot",
 			},
 			expected: &config.NodeGroupAutoscalingOptions{
 				ScaleDownUtilizationThreshold:    0.42,
@@ -186,6 +196,11 @@ func TestGetAsgOptions(t *testing.T) {
 				ScaleDownUnneededTime:            time.Hour,
 				ScaleDownUnreadyTime:             25 * time.Minute,
 				IgnoreDaemonSetsUtilization:      true,
+				MaxConcurrentProvisioning:        3,
+				MaxScaleDownParallelism:          5,
+				MaxNodeDrainTime:                 30 * time.Minute,
+				NodeDrainTimeoutPolicy:           config.DrainTimeoutPolicyForceDelete,
+				Metadata:                         map[string]string{"costclass": "spot"},
 			},
 		},
 		{
@@ -674,7 +689,7 @@ func TestFetchExplicitAsgs(t *testing.T) {
 	}
 	t.Setenv("AWS_REGION", "fanghorn")
 	instanceTypes, _ := GetStaticEC2InstanceTypes()
-	m, err := createAWSManagerInternal(nil, do, &awsWrapper{a, nil, nil}, instanceTypes)
+	m, err := createAWSManagerInternal(nil, do, &awsWrapper{a, nil, nil}, newInstanceTypeCatalog(instanceTypes, nil))
 	assert.NoError(t, err)
 
 	asgs := m.asgCache.Get()
@@ -737,7 +752,7 @@ func TestGetASGTemplate(t *testing.T) {
 			instanceTypes, _ := GetStaticEC2InstanceTypes()
 			do := cloudprovider.NodeGroupDiscoveryOptions{}
 
-			m, err := createAWSManagerInternal(nil, do, &awsWrapper{nil, e, nil}, instanceTypes)
+			m, err := createAWSManagerInternal(nil, do, &awsWrapper{nil, e, nil}, newInstanceTypeCatalog(instanceTypes, nil))
 			origGetInstanceTypeFunc := getInstanceTypeForAsg
 			defer func() { getInstanceTypeForAsg = origGetInstanceTypeFunc }()
 			getInstanceTypeForAsg = func(m *asgCache, asg *asg) (string, error) {
@@ -834,7 +849,7 @@ func TestFetchAutoAsgs(t *testing.T) {
 	t.Setenv("AWS_REGION", "fanghorn")
 	// fetchAutoASGs is called at manager creation time, via forceRefresh
 	instanceTypes, _ := GetStaticEC2InstanceTypes()
-	m, err := createAWSManagerInternal(nil, do, &awsWrapper{a, nil, nil}, instanceTypes)
+	m, err := createAWSManagerInternal(nil, do, &awsWrapper{a, nil, nil}, newInstanceTypeCatalog(instanceTypes, nil))
 	assert.NoError(t, err)
 
 	asgs := m.asgCache.Get()
@@ -856,6 +871,69 @@ func TestFetchAutoAsgs(t *testing.T) {
 	assert.Empty(t, m.asgCache.Get())
 }
 
+func TestFetchEKSDiscoveredAsgs(t *testing.T) {
+	min, max := 1, 10
+	clusterName, nodegroupName, groupname := "cluster", "nodegroup", "eks-nodegroup-asg"
+	asgRef := AwsRef{Name: groupname}
+
+	k := &eksMock{}
+	k.On("ListNodegroupsPages",
+		&eks.ListNodegroupsInput{ClusterName: aws.String(clusterName)},
+		mock.AnythingOfType("func(*eks.ListNodegroupsOutput, bool) bool"),
+	).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*eks.ListNodegroupsOutput, bool) bool)
+		fn(&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice([]string{nodegroupName})}, true)
+	}).Return(nil)
+	k.On("DescribeNodegroup", &eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: aws.String(nodegroupName),
+	}).Return(&eks.DescribeNodegroupOutput{Nodegroup: &eks.Nodegroup{
+		NodegroupName: aws.String(nodegroupName),
+		Resources: &eks.NodegroupResources{
+			AutoScalingGroups: []*eks.AutoScalingGroup{{Name: aws.String(groupname)}},
+		},
+		ScalingConfig: &eks.NodegroupScalingConfig{MinSize: aws.Int64(int64(min)), MaxSize: aws.Int64(int64(max))},
+	}}, nil)
+
+	a := &autoScalingMock{}
+	a.On("DescribeAutoScalingGroupsPages",
+		&autoscaling.DescribeAutoScalingGroupsInput{
+			AutoScalingGroupNames: aws.StringSlice([]string{groupname}),
+			MaxRecords:            aws.Int64(maxRecordsReturnedByAPI),
+		},
+		mock.AnythingOfType("func(*autoscaling.DescribeAutoScalingGroupsOutput, bool) bool"),
+	).Run(func(args mock.Arguments) {
+		fn := args.Get(1).(func(*autoscaling.DescribeAutoScalingGroupsOutput, bool) bool)
+		zone := "test-1a"
+		fn(&autoscaling.DescribeAutoScalingGroupsOutput{
+			AutoScalingGroups: []*autoscaling.Group{{
+				AvailabilityZones:    []*string{&zone},
+				AutoScalingGroupName: aws.String(groupname),
+				MinSize:              aws.Int64(int64(min)),
+				MaxSize:              aws.Int64(int64(max)),
+				DesiredCapacity:      aws.Int64(int64(min)),
+			}}}, false)
+	}).Return(nil)
+	a.On("DescribeScalingActivities",
+		&autoscaling.DescribeScalingActivitiesInput{
+			AutoScalingGroupName: aws.String(groupname),
+		},
+	).Return(&autoscaling.DescribeScalingActivitiesOutput{}, nil)
+
+	do := cloudprovider.NodeGroupDiscoveryOptions{
+		NodeGroupAutoDiscoverySpecs: []string{fmt.Sprintf("eks:cluster=%s", clusterName)},
+	}
+
+	t.Setenv("AWS_REGION", "fanghorn")
+	instanceTypes, _ := GetStaticEC2InstanceTypes()
+	m, err := createAWSManagerInternal(nil, do, &awsWrapper{a, nil, k}, newInstanceTypeCatalog(instanceTypes, nil))
+	assert.NoError(t, err)
+
+	asgs := m.asgCache.Get()
+	assert.Equal(t, 1, len(asgs))
+	validateAsg(t, asgs[asgRef], groupname, min, max)
+}
+
 type ServiceDescriptor struct {
 	name                         string
 	region                       string
@@ -929,6 +1007,24 @@ func TestParseASGAutoDiscoverySpecs(t *testing.T) {
 			specs:   []string{"asg:tag"},
 			wantErr: true,
 		},
+		{
+			name:  "EKSCluster",
+			specs: []string{"asg:tag=tag", "eks:cluster=my-cluster"},
+			want: []asgAutoDiscoveryConfig{
+				{Tags: map[string]string{"tag": ""}},
+				{ClusterName: "my-cluster"},
+			},
+		},
+		{
+			name:    "EKSWrongKey",
+			specs:   []string{"eks:tag=my-cluster"},
+			wantErr: true,
+		},
+		{
+			name:    "EKSMissingCluster",
+			specs:   []string{"eks:cluster="},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range cases {
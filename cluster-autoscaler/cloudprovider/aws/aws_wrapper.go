@@ -35,11 +35,13 @@ type autoScalingI interface {
 	DescribeLaunchConfigurations(*autoscaling.DescribeLaunchConfigurationsInput) (*autoscaling.DescribeLaunchConfigurationsOutput, error)
 	DescribeScalingActivities(*autoscaling.DescribeScalingActivitiesInput) (*autoscaling.DescribeScalingActivitiesOutput, error)
 	SetDesiredCapacity(input *autoscaling.SetDesiredCapacityInput) (*autoscaling.SetDesiredCapacityOutput, error)
+	SuspendProcesses(input *autoscaling.ScalingProcessQuery) (*autoscaling.SuspendProcessesOutput, error)
 	TerminateInstanceInAutoScalingGroup(input *autoscaling.TerminateInstanceInAutoScalingGroupInput) (*autoscaling.TerminateInstanceInAutoScalingGroupOutput, error)
 }
 
 // ec2I is the interface abstracting specific API calls of the EC2 service provided by AWS SDK for use in CA
 type ec2I interface {
+	CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error)
 	DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error)
 	DescribeLaunchTemplateVersions(input *ec2.DescribeLaunchTemplateVersionsInput) (*ec2.DescribeLaunchTemplateVersionsOutput, error)
 	GetInstanceTypesFromInstanceRequirementsPages(input *ec2.GetInstanceTypesFromInstanceRequirementsInput, fn func(*ec2.GetInstanceTypesFromInstanceRequirementsOutput, bool) bool) error
@@ -48,6 +50,7 @@ type ec2I interface {
 // eksI is the interface that represents a specific aspect of EKS (Elastic Kubernetes Service) which is provided by AWS SDK for use in CA
 type eksI interface {
 	DescribeNodegroup(input *eks.DescribeNodegroupInput) (*eks.DescribeNodegroupOutput, error)
+	ListNodegroupsPages(input *eks.ListNodegroupsInput, fn func(*eks.ListNodegroupsOutput, bool) bool) error
 }
 
 // awsWrapper provides several utility methods over the services provided by the AWS SDK
@@ -234,6 +237,38 @@ func (m *awsWrapper) getAutoscalingGroupsByTags(tags map[string]string) ([]*auto
 	return asgs, nil
 }
 
+func (m *awsWrapper) listEKSNodegroups(clusterName string) ([]string, error) {
+	names := make([]string, 0)
+	input := &eks.ListNodegroupsInput{
+		ClusterName: aws.String(clusterName),
+	}
+	start := time.Now()
+	err := m.ListNodegroupsPages(input, func(output *eks.ListNodegroupsOutput, _ bool) bool {
+		names = append(names, aws.StringValueSlice(output.Nodegroups)...)
+		// We return true while we want to be called with the next page of
+		// results, if any.
+		return true
+	})
+	observeAWSRequest("ListNodegroupsPages", err, start)
+	if err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+func (m *awsWrapper) describeEKSNodegroup(clusterName string, nodegroupName string) (*eks.Nodegroup, error) {
+	start := time.Now()
+	r, err := m.DescribeNodegroup(&eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(clusterName),
+		NodegroupName: aws.String(nodegroupName),
+	})
+	observeAWSRequest("DescribeNodegroup", err, start)
+	if err != nil {
+		return nil, err
+	}
+	return r.Nodegroup, nil
+}
+
 func (m *awsWrapper) getInstanceTypeByLaunchTemplate(launchTemplate *launchTemplate) (string, error) {
 	templateData, err := m.getLaunchTemplateData(launchTemplate.name, launchTemplate.version)
 	if err != nil {
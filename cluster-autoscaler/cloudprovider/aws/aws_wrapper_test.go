@@ -55,6 +55,11 @@ func (a *autoScalingMock) SetDesiredCapacity(input *autoscaling.SetDesiredCapaci
 	return args.Get(0).(*autoscaling.SetDesiredCapacityOutput), nil
 }
 
+func (a *autoScalingMock) SuspendProcesses(input *autoscaling.ScalingProcessQuery) (*autoscaling.SuspendProcessesOutput, error) {
+	args := a.Called(input)
+	return args.Get(0).(*autoscaling.SuspendProcessesOutput), args.Error(1)
+}
+
 func (a *autoScalingMock) TerminateInstanceInAutoScalingGroup(input *autoscaling.TerminateInstanceInAutoScalingGroupInput) (*autoscaling.TerminateInstanceInAutoScalingGroupOutput, error) {
 	args := a.Called(input)
 	return args.Get(0).(*autoscaling.TerminateInstanceInAutoScalingGroupOutput), nil
@@ -64,6 +69,11 @@ type ec2Mock struct {
 	mock.Mock
 }
 
+func (e *ec2Mock) CreateTags(input *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	args := e.Called(input)
+	return args.Get(0).(*ec2.CreateTagsOutput), args.Error(1)
+}
+
 func (e *ec2Mock) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
 	args := e.Called(input)
 	return args.Get(0).(*ec2.DescribeImagesOutput), nil
@@ -97,6 +107,11 @@ func (k *eksMock) DescribeNodegroup(i *eks.DescribeNodegroupInput) (*eks.Describ
 	}
 }
 
+func (k *eksMock) ListNodegroupsPages(i *eks.ListNodegroupsInput, fn func(*eks.ListNodegroupsOutput, bool) bool) error {
+	args := k.Called(i, fn)
+	return args.Error(0)
+}
+
 var testAwsService = awsWrapper{&autoScalingMock{}, &ec2Mock{}, &eksMock{}}
 
 func TestGetManagedNodegroup(t *testing.T) {
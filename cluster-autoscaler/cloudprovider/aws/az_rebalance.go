@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/aws"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/autoscaling"
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	azRebalanceProcess = "AZRebalance"
+	// azRebalanceActivityCause is a part of the cause of scaling activities launching and
+	// terminating instances to balance them across availability zones.
+	azRebalanceActivityCause = "to balance instances in zones"
+	// terminateInstanceActivityPrefix is the prefix of the description of scaling activities
+	// terminating an instance, followed by the instance id.
+	terminateInstanceActivityPrefix = "Terminating EC2 instance: "
+)
+
+func isTerminatingLifecycle(lifecycle *string) bool {
+	switch aws.StringValue(lifecycle) {
+	case autoscaling.LifecycleStateTerminating, autoscaling.LifecycleStateTerminatingWait, autoscaling.LifecycleStateTerminatingProceed:
+		return true
+	}
+	return false
+}
+
+// findAZRebalanceTerminations returns the terminating instances of the groups which are
+// terminated by AZRebalance. Scaling activities are only described for groups with
+// terminating instances.
+func (m *asgCache) findAZRebalanceTerminations(groups []*autoscaling.Group) map[AwsInstanceRef]bool {
+	result := make(map[AwsInstanceRef]bool)
+	for _, group := range groups {
+		terminating := make(map[string]AwsInstanceRef)
+		for _, instance := range group.Instances {
+			if isTerminatingLifecycle(instance.LifecycleState) {
+				ref := m.buildInstanceRefFromAWS(instance)
+				terminating[ref.Name] = ref
+			}
+		}
+		if len(terminating) == 0 {
+			continue
+		}
+
+		input := &autoscaling.DescribeScalingActivitiesInput{
+			AutoScalingGroupName: group.AutoScalingGroupName,
+		}
+		start := time.Now()
+		response, err := m.awsService.DescribeScalingActivities(input)
+		observeAWSRequest("DescribeScalingActivities", err, start)
+		if err != nil {
+			klog.Warningf("Failed to describe scaling activities of ASG %s: %v", aws.StringValue(group.AutoScalingGroupName), err)
+			continue
+		}
+		for _, activity := range response.Activities {
+			if !strings.Contains(aws.StringValue(activity.Cause), azRebalanceActivityCause) {
+				continue
+			}
+			instanceId, found := strings.CutPrefix(aws.StringValue(activity.Description), terminateInstanceActivityPrefix)
+			if !found {
+				continue
+			}
+			if ref, found := terminating[instanceId]; found {
+				klog.V(2).Infof("Instance %s of ASG %s is terminated by %s", instanceId, aws.StringValue(group.AutoScalingGroupName), azRebalanceProcess)
+				result[ref] = true
+			}
+		}
+	}
+	return result
+}
+
+// suspendAZRebalance suspends the AZRebalance process of the registered ASGs which
+// don't have it suspended yet.
+func (m *AwsManager) suspendAZRebalance() {
+	for _, asg := range m.getAsgs() {
+		if slices.Contains(asg.SuspendedProcesses, azRebalanceProcess) {
+			continue
+		}
+		if m.dryRun {
+			klog.Infof("Dry run: would suspend %s process of ASG %s", azRebalanceProcess, asg.Name)
+			continue
+		}
+		params := &autoscaling.ScalingProcessQuery{
+			AutoScalingGroupName: aws.String(asg.Name),
+			ScalingProcesses:     aws.StringSlice([]string{azRebalanceProcess}),
+		}
+		start := time.Now()
+		_, err := m.awsService.SuspendProcesses(params)
+		observeAWSRequest("SuspendProcesses", err, start)
+		if err != nil {
+			klog.Warningf("Failed to suspend %s process of ASG %s: %v", azRebalanceProcess, asg.Name, err)
+			continue
+		}
+		klog.Infof("Suspended %s process of ASG %s", azRebalanceProcess, asg.Name)
+	}
+}
+
+// azRebalanceMaintenanceEvents returns the instances terminated by AZRebalance as termination
+// maintenance events starting immediately. Their nodes are cordoned and drained by Cluster
+// Autoscaler like nodes affected by other maintenance, with replacement capacity provisioned
+// for their pods, instead of being discovered gone after the fact.
+func (m *AwsManager) azRebalanceMaintenanceEvents(now time.Time) []cloudprovider.MaintenanceEvent {
+	var events []cloudprovider.MaintenanceEvent
+	for instance := range m.asgCache.AZRebalanceTerminations() {
+		events = append(events, cloudprovider.MaintenanceEvent{
+			ProviderID:  instance.ProviderID,
+			Type:        cloudprovider.MaintenanceEventTermination,
+			NotBefore:   now,
+			Description: fmt.Sprintf("ASG %s process terminates the instance", azRebalanceProcess),
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ProviderID < events[j].ProviderID })
+	return events
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/aws"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/aws/aws-sdk-go/service/autoscaling"
+)
+
+func TestFindAZRebalanceTerminations(t *testing.T) {
+	instance := func(id, lifecycle string) *autoscaling.Instance {
+		return &autoscaling.Instance{
+			InstanceId:       aws.String(id),
+			AvailabilityZone: aws.String("us-east-1a"),
+			LifecycleState:   aws.String(lifecycle),
+		}
+	}
+	groups := []*autoscaling.Group{
+		{
+			AutoScalingGroupName: aws.String("rebalanced"),
+			Instances: []*autoscaling.Instance{
+				instance("i-1", autoscaling.LifecycleStateInService),
+				instance("i-2", autoscaling.LifecycleStateTerminatingWait),
+				instance("i-3", autoscaling.LifecycleStateTerminating),
+			},
+		},
+		{
+			AutoScalingGroupName: aws.String("in-service"),
+			Instances: []*autoscaling.Instance{
+				instance("i-4", autoscaling.LifecycleStateInService),
+			},
+		},
+	}
+
+	a := &autoScalingMock{}
+	a.On("DescribeScalingActivities", &autoscaling.DescribeScalingActivitiesInput{
+		AutoScalingGroupName: aws.String("rebalanced"),
+	}).Return(&autoscaling.DescribeScalingActivitiesOutput{
+		Activities: []*autoscaling.Activity{
+			{
+				Description: aws.String("Terminating EC2 instance: i-2"),
+				Cause:       aws.String("At 2024-01-01T10:00:00Z instances were launched to balance instances in zones us-east-1a us-east-1b with other zones resulting in more than desired number of instances in the group."),
+			},
+			{
+				Description: aws.String("Terminating EC2 instance: i-3"),
+				Cause:       aws.String("At 2024-01-01T10:00:00Z instance i-3 was taken out of service in response to a user request, shrinking the capacity from 3 to 2."),
+			},
+		},
+	}, nil)
+	m := newTestAwsManagerWithMockServices(a, nil, nil, nil, nil)
+
+	terminations := m.asgCache.findAZRebalanceTerminations(groups)
+	assert.Equal(t, map[AwsInstanceRef]bool{{ProviderID: "aws:///us-east-1a/i-2", Name: "i-2"}: true}, terminations)
+	a.AssertExpectations(t)
+}
+
+func TestSuspendAZRebalance(t *testing.T) {
+	a := &autoScalingMock{}
+	a.On("SuspendProcesses", &autoscaling.ScalingProcessQuery{
+		AutoScalingGroupName: aws.String("active"),
+		ScalingProcesses:     aws.StringSlice([]string{azRebalanceProcess}),
+	}).Return(&autoscaling.SuspendProcessesOutput{}, nil).Once()
+	m := newTestAwsManagerWithMockServices(a, nil, nil, nil, nil)
+	m.asgCache.register(&asg{AwsRef: AwsRef{Name: "active"}})
+	m.asgCache.register(&asg{AwsRef: AwsRef{Name: "suspended"}, SuspendedProcesses: []string{"Launch", azRebalanceProcess}})
+
+	m.dryRun = true
+	m.suspendAZRebalance()
+	a.AssertNotCalled(t, "SuspendProcesses", mock.Anything)
+
+	m.dryRun = false
+	m.suspendAZRebalance()
+	a.AssertExpectations(t)
+}
+
+func TestAZRebalanceMaintenanceEvents(t *testing.T) {
+	m := newTestAwsManagerWithMockServices(&autoScalingMock{}, nil, nil, nil, nil)
+	provider := &awsCloudProvider{awsManager: m}
+	events, err := provider.MaintenanceEvents()
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	m.asgCache.azRebalanceTerminations = map[AwsInstanceRef]bool{
+		{ProviderID: "aws:///us-east-1b/i-2", Name: "i-2"}: true,
+		{ProviderID: "aws:///us-east-1a/i-1", Name: "i-1"}: true,
+	}
+	now := time.Now()
+	events = m.azRebalanceMaintenanceEvents(now)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "aws:///us-east-1a/i-1", events[0].ProviderID)
+		assert.Equal(t, "aws:///us-east-1b/i-2", events[1].ProviderID)
+		for _, event := range events {
+			assert.Equal(t, cloudprovider.MaintenanceEventTermination, event.Type)
+			assert.Equal(t, now, event.NotBefore)
+		}
+	}
+}
+
+func TestNodesWithAZRebalanceTermination(t *testing.T) {
+	m := newTestAwsManagerWithMockServices(&autoScalingMock{}, nil, nil, nil, nil)
+	running := AwsInstanceRef{ProviderID: "aws:///us-east-1a/i-1", Name: "i-1"}
+	terminated := AwsInstanceRef{ProviderID: "aws:///us-east-1a/i-2", Name: "i-2"}
+	a := m.asgCache.register(&asg{AwsRef: AwsRef{Name: "asg"}})
+	m.asgCache.asgToInstances[a.AwsRef] = []AwsInstanceRef{running, terminated}
+	m.asgCache.instanceStatus = map[AwsInstanceRef]*string{running: aws.String("Healthy"), terminated: aws.String("Healthy")}
+	m.asgCache.azRebalanceTerminations = map[AwsInstanceRef]bool{terminated: true}
+
+	instances, err := (&AwsNodeGroup{awsManager: m, asg: a}).Nodes()
+	require.NoError(t, err)
+	assert.Equal(t, []cloudprovider.Instance{
+		{Id: running.ProviderID},
+		{Id: terminated.ProviderID, Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceDeleting}},
+	}, instances)
+}
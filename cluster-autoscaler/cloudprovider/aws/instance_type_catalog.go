@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"sync"
+	"time"
+
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	// instanceTypesRefreshInterval is how often the instance types are fetched from the EC2 API.
+	instanceTypesRefreshInterval = 6 * time.Hour
+	// unknownInstanceTypeRefreshInterval is the minimal time between refreshes triggered by
+	// lookups of instance types missing from the catalog, e.g. of a newly released instance family.
+	// Failed refreshes are also retried after this interval.
+	unknownInstanceTypeRefreshInterval = 10 * time.Minute
+)
+
+// instanceTypeCatalog holds the EC2 instance types known to CA. Instance types fetched
+// from the EC2 DescribeInstanceTypes API are refreshed periodically and take precedence
+// over the static list, which is used for instance types missing from the API response
+// and when the API can't be queried.
+type instanceTypeCatalog struct {
+	mutex         sync.Mutex
+	static        map[string]*InstanceType
+	instanceTypes map[string]*InstanceType
+	// fetch returns the instance types from the EC2 API. If nil, only the static list is used.
+	fetch         func() (map[string]*InstanceType, error)
+	lastRefresh   time.Time
+	refreshFailed bool
+}
+
+// newInstanceTypeCatalog creates an instanceTypeCatalog falling back to the static instance
+// types. Instance types aren't fetched until the first refresh.
+func newInstanceTypeCatalog(static map[string]*InstanceType, fetch func() (map[string]*InstanceType, error)) *instanceTypeCatalog {
+	return &instanceTypeCatalog{
+		static:        static,
+		instanceTypes: static,
+		fetch:         fetch,
+	}
+}
+
+// get returns the instance type of the given name. Unknown instance types trigger
+// a refresh of the catalog, unless one happened recently.
+func (c *instanceTypeCatalog) get(name string) (*InstanceType, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if t, ok := c.instanceTypes[name]; ok {
+		return t, true
+	}
+	if c.fetch == nil || time.Since(c.lastRefresh) < unknownInstanceTypeRefreshInterval {
+		return nil, false
+	}
+	klog.V(2).Infof("EC2 instance type %s is unknown, refreshing instance types", name)
+	c.refreshLocked()
+	t, ok := c.instanceTypes[name]
+	return t, ok
+}
+
+// refreshIfExpired fetches the instance types if they weren't refreshed within
+// instanceTypesRefreshInterval, or unknownInstanceTypeRefreshInterval after a failure.
+func (c *instanceTypeCatalog) refreshIfExpired() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	interval := instanceTypesRefreshInterval
+	if c.refreshFailed {
+		interval = unknownInstanceTypeRefreshInterval
+	}
+	if c.fetch == nil || time.Since(c.lastRefresh) < interval {
+		return
+	}
+	c.refreshLocked()
+}
+
+// refreshLocked fetches the instance types and merges them with the static ones. On
+// failure, the previously known instance types are kept. Must be called with the mutex held.
+func (c *instanceTypeCatalog) refreshLocked() {
+	c.lastRefresh = time.Now()
+
+	start := time.Now()
+	generated, err := c.fetch()
+	observeAWSRequest("DescribeInstanceTypes", err, start)
+	c.refreshFailed = err != nil
+	if err != nil {
+		klog.Errorf("Failed to generate AWS EC2 Instance Types: %v, keeping %d previously known instance types", err, len(c.instanceTypes))
+		return
+	}
+
+	instanceTypes := make(map[string]*InstanceType, len(generated)+len(c.static))
+	for k, v := range generated {
+		instanceTypes[k] = v
+	}
+	// fallback on the static list if we miss any instance types in the generated output
+	// credits to: https://github.com/lyft/cni-ipvlan-vpc-k8s/pull/80
+	for k, v := range c.static {
+		if _, ok := instanceTypes[k]; ok {
+			continue
+		}
+		klog.V(4).Infof("Using static instance type %s", k)
+		instanceTypes[k] = v
+	}
+	c.instanceTypes = instanceTypes
+	klog.Infof("Successfully loaded %d EC2 Instance Types", len(instanceTypes))
+}
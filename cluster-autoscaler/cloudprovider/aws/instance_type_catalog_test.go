@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceTypeCatalog(t *testing.T) {
+	static := map[string]*InstanceType{
+		"m5.large": {InstanceType: "m5.large", VCPU: 2, MemoryMb: 8192},
+		"t2.micro": {InstanceType: "t2.micro", VCPU: 1, MemoryMb: 1024},
+	}
+	fetched := map[string]*InstanceType{
+		"m5.large": {InstanceType: "m5.large", VCPU: 2, MemoryMb: 8000},
+		"m8.large": {InstanceType: "m8.large", VCPU: 2, MemoryMb: 8192},
+	}
+	fetches := 0
+	var fetchErr error
+	catalog := newInstanceTypeCatalog(static, func() (map[string]*InstanceType, error) {
+		fetches++
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return fetched, nil
+	})
+
+	// Before the first refresh, only the static instance types are known.
+	fetchErr = errors.New("throttled")
+	catalog.refreshIfExpired()
+	assert.Equal(t, 1, fetches)
+	instanceType, found := catalog.get("m5.large")
+	assert.True(t, found)
+	assert.Equal(t, int64(8192), instanceType.MemoryMb)
+	_, found = catalog.get("m8.large")
+	assert.False(t, found)
+	assert.Equal(t, 1, fetches, "unknown instance type lookups should be rate limited")
+	catalog.refreshIfExpired()
+	assert.Equal(t, 1, fetches)
+
+	// Failed refreshes are retried sooner than successful ones.
+	catalog.lastRefresh = time.Now().Add(-unknownInstanceTypeRefreshInterval)
+	catalog.refreshIfExpired()
+	assert.Equal(t, 2, fetches)
+
+	// Fetched instance types take precedence over the static ones.
+	fetchErr = nil
+	catalog.lastRefresh = time.Now().Add(-unknownInstanceTypeRefreshInterval)
+	catalog.refreshIfExpired()
+	assert.Equal(t, 3, fetches)
+	instanceType, found = catalog.get("m5.large")
+	assert.True(t, found)
+	assert.Equal(t, int64(8000), instanceType.MemoryMb)
+	_, found = catalog.get("m8.large")
+	assert.True(t, found)
+	_, found = catalog.get("t2.micro")
+	assert.True(t, found)
+
+	// Successful refreshes aren't repeated before they expire.
+	catalog.lastRefresh = time.Now().Add(-unknownInstanceTypeRefreshInterval)
+	catalog.refreshIfExpired()
+	assert.Equal(t, 3, fetches)
+
+	// Unknown instance types trigger a refresh.
+	fetched["m9.large"] = &InstanceType{InstanceType: "m9.large", VCPU: 2, MemoryMb: 8192}
+	catalog.lastRefresh = time.Now().Add(-unknownInstanceTypeRefreshInterval)
+	_, found = catalog.get("m9.large")
+	assert.True(t, found)
+	assert.Equal(t, 4, fetches)
+
+	// Failed refreshes keep the previously fetched instance types.
+	fetchErr = errors.New("throttled")
+	catalog.lastRefresh = time.Now().Add(-instanceTypesRefreshInterval)
+	catalog.refreshIfExpired()
+	assert.Equal(t, 5, fetches)
+	_, found = catalog.get("m9.large")
+	assert.True(t, found)
+}
+
+func TestInstanceTypeCatalogStaticOnly(t *testing.T) {
+	static := map[string]*InstanceType{
+		"m5.large": {InstanceType: "m5.large", VCPU: 2, MemoryMb: 8192},
+	}
+	catalog := newInstanceTypeCatalog(static, nil)
+	catalog.refreshIfExpired()
+	_, found := catalog.get("m5.large")
+	assert.True(t, found)
+	_, found = catalog.get("m8.large")
+	assert.False(t, found)
+}
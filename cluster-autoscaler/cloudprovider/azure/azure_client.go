@@ -274,6 +274,8 @@ type azClient struct {
 	storageAccountsClient           storageaccountclient.Interface
 	skuClient                       compute.ResourceSkusClient
 	agentPoolClient                 AgentPoolsClient
+	spotEvictionClient              SpotEvictionClient
+	vmssETagClient                  VMSSETagClient
 }
 
 // newServicePrincipalTokenFromCredentials creates a new ServicePrincipalToken using values of the
@@ -401,6 +403,12 @@ func newAzClient(cfg *Config, env *azure.Environment) (*azClient, error) {
 	skuClient.Authorizer = azClientConfig.Authorizer
 	klog.V(5).Infof("Created sku client with authorizer: %v", skuClient)
 
+	spotEvictionClient := newSpotEvictionClient(cfg.SubscriptionID, azClientConfig.ResourceManagerEndpoint, azClientConfig.Authorizer)
+	klog.V(5).Infof("Created spot eviction client with authorizer: %v", spotEvictionClient)
+
+	vmssETagClient := newVMSSETagClient(cfg.SubscriptionID, azClientConfig.ResourceManagerEndpoint, azClientConfig.Authorizer)
+	klog.V(5).Infof("Created VMSS entity tag client with authorizer: %v", vmssETagClient)
+
 	agentPoolClient, err := newAgentpoolClient(cfg)
 	if err != nil {
 		// we don't want to fail the whole process so we don't break any existing functionality
@@ -418,5 +426,7 @@ func newAzClient(cfg *Config, env *azure.Environment) (*azClient, error) {
 		storageAccountsClient:           storageAccountsClient,
 		skuClient:                       skuClient,
 		agentPoolClient:                 agentPoolClient,
+		spotEvictionClient:              spotEvictionClient,
+		vmssETagClient:                  vmssETagClient,
 	}, nil
 }
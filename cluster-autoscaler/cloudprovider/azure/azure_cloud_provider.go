@@ -27,6 +27,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/client-go/informers"
 	klog "k8s.io/klog/v2"
 )
 
@@ -154,6 +155,13 @@ func (azure *AzureCloudProvider) Refresh() error {
 	return azure.azureManager.Refresh()
 }
 
+// MaintenanceEvents returns scheduled Spot evictions of instances of the scale sets as termination
+// events, so that their nodes are cordoned, replacement capacity is provisioned and the nodes are
+// drained before the instances disappear.
+func (azure *AzureCloudProvider) MaintenanceEvents() ([]cloudprovider.MaintenanceEvent, error) {
+	return azure.azureManager.getSpotEvictions(), nil
+}
+
 // azureRef contains a reference to some entity in Azure world.
 type azureRef struct {
 	Name string
@@ -170,7 +178,7 @@ func (m *azureRef) String() string {
 }
 
 // BuildAzure builds Azure cloud provider, manager etc.
-func BuildAzure(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter) cloudprovider.CloudProvider {
+func BuildAzure(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter, informerFactory informers.SharedInformerFactory) cloudprovider.CloudProvider {
 	var config io.ReadCloser
 	if opts.CloudConfig != "" {
 		klog.Infof("Creating Azure Manager using cloud-config file: %v", opts.CloudConfig)
@@ -187,6 +195,10 @@ func BuildAzure(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscov
 	if err != nil {
 		klog.Fatalf("Failed to create Azure Manager: %v", err)
 	}
+	manager.dryRun = opts.DryRun
+	if informerFactory != nil {
+		manager.nodeLister = informerFactory.Core().V1().Nodes().Lister()
+	}
 	provider, err := BuildAzureCloudProvider(manager, rl)
 	if err != nil {
 		klog.Fatalf("Failed to create Azure cloud provider: %v", err)
@@ -128,6 +128,24 @@ type Config struct {
 	// Jitter in seconds subtracted from the VMSS cache TTL before the first refresh
 	VmssVmsCacheJitter int `json:"vmssVmsCacheJitter" yaml:"vmssVmsCacheJitter"`
 
+	// Jitter in seconds subtracted from the VMSS metadata cache TTL on every refresh
+	VmssCacheJitter int `json:"vmssCacheJitter" yaml:"vmssCacheJitter"`
+
+	// EnableSpotEvictionSimulation defines whether instances of Spot VMSS are evicted via the simulate eviction API
+	// instead of being deleted on scale-down
+	EnableSpotEvictionSimulation bool `json:"enableSpotEvictionSimulation,omitempty" yaml:"enableSpotEvictionSimulation,omitempty"`
+
+	// EnableSpotEvictionReplacement defines whether Spot VMSS instances whose nodes report scheduled evictions are
+	// marked as being deleted, so that their capacity is replaced by regular scale-ups
+	EnableSpotEvictionReplacement bool `json:"enableSpotEvictionReplacement,omitempty" yaml:"enableSpotEvictionReplacement,omitempty"`
+
+	// EnableVmssVmsDeltaRefresh defines whether VMSS instances are only listed when the scale set model changed
+	// and listing results are merged into the existing instances cache, only applies for vmss type
+	EnableVmssVmsDeltaRefresh bool `json:"enableVmssVmsDeltaRefresh,omitempty" yaml:"enableVmssVmsDeltaRefresh,omitempty"`
+
+	// VmssVmsDeltaRefreshMaxStaleness in seconds bounds how long delta refresh can skip listing VMSS instances
+	VmssVmsDeltaRefreshMaxStaleness int `json:"vmssVmsDeltaRefreshMaxStaleness,omitempty" yaml:"vmssVmsDeltaRefreshMaxStaleness,omitempty"`
+
 	// number of latest deployments that will not be deleted
 	MaxDeploymentsCount int64 `json:"maxDeploymentsCount" yaml:"maxDeploymentsCount"`
 
@@ -233,6 +251,41 @@ func BuildAzureConfig(configReader io.Reader) (*Config, error) {
 			}
 		}
 
+		if vmssCacheJitter := os.Getenv("AZURE_VMSS_CACHE_JITTER"); vmssCacheJitter != "" {
+			cfg.VmssCacheJitter, err = strconv.Atoi(vmssCacheJitter)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AZURE_VMSS_CACHE_JITTER %q: %v", vmssCacheJitter, err)
+			}
+		}
+
+		if enableVmssVmsDeltaRefresh := os.Getenv("AZURE_ENABLE_VMSS_VMS_DELTA_REFRESH"); enableVmssVmsDeltaRefresh != "" {
+			cfg.EnableVmssVmsDeltaRefresh, err = strconv.ParseBool(enableVmssVmsDeltaRefresh)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AZURE_ENABLE_VMSS_VMS_DELTA_REFRESH %q: %v", enableVmssVmsDeltaRefresh, err)
+			}
+		}
+
+		if deltaRefreshMaxStaleness := os.Getenv("AZURE_VMSS_VMS_DELTA_REFRESH_MAX_STALENESS"); deltaRefreshMaxStaleness != "" {
+			cfg.VmssVmsDeltaRefreshMaxStaleness, err = strconv.Atoi(deltaRefreshMaxStaleness)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AZURE_VMSS_VMS_DELTA_REFRESH_MAX_STALENESS %q: %v", deltaRefreshMaxStaleness, err)
+			}
+		}
+
+		if enableSpotEvictionSimulation := os.Getenv("AZURE_ENABLE_SPOT_EVICTION_SIMULATION"); enableSpotEvictionSimulation != "" {
+			cfg.EnableSpotEvictionSimulation, err = strconv.ParseBool(enableSpotEvictionSimulation)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AZURE_ENABLE_SPOT_EVICTION_SIMULATION %q: %v", enableSpotEvictionSimulation, err)
+			}
+		}
+
+		if enableSpotEvictionReplacement := os.Getenv("AZURE_ENABLE_SPOT_EVICTION_REPLACEMENT"); enableSpotEvictionReplacement != "" {
+			cfg.EnableSpotEvictionReplacement, err = strconv.ParseBool(enableSpotEvictionReplacement)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AZURE_ENABLE_SPOT_EVICTION_REPLACEMENT %q: %v", enableSpotEvictionReplacement, err)
+			}
+		}
+
 		if threshold := os.Getenv("AZURE_MAX_DEPLOYMENT_COUNT"); threshold != "" {
 			cfg.MaxDeploymentsCount, err = strconv.ParseInt(threshold, 10, 0)
 			if err != nil {
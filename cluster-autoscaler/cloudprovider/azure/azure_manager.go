@@ -21,8 +21,10 @@ package azure
 import (
 	"fmt"
 	"io"
+	"math/rand"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Azure/go-autorest/autorest/azure"
@@ -30,6 +32,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/config/dynamic"
+	v1lister "k8s.io/client-go/listers/core/v1"
 	kretry "k8s.io/client-go/util/retry"
 	klog "k8s.io/klog/v2"
 	"sigs.k8s.io/cloud-provider-azure/pkg/retry"
@@ -52,8 +55,18 @@ type AzureManager struct {
 
 	azureCache           *azureCache
 	lastRefresh          time.Time
+	refreshJitter        time.Duration
 	autoDiscoverySpecs   []labelAutoDiscoveryConfig
 	explicitlyConfigured map[string]bool
+
+	// nodeLister lists the nodes Spot eviction notices are read from.
+	nodeLister v1lister.NodeLister
+	// spotEvictionsMutex guards spotEvictions.
+	spotEvictionsMutex sync.Mutex
+	// spotEvictions are the scheduled Spot evictions of instances of the scale sets.
+	spotEvictions []cloudprovider.MaintenanceEvent
+	// dryRun makes scale sets only log the instances they would delete or evict.
+	dryRun bool
 }
 
 // createAzureManagerInternal allows for a custom azClient to be passed in by tests.
@@ -98,6 +111,7 @@ func createAzureManagerInternal(configReader io.Reader, discoveryOpts cloudprovi
 		return nil, err
 	}
 	manager.azureCache = cache
+	manager.refreshJitter = time.Duration(cfg.VmssCacheJitter) * time.Second
 
 	specs, err := ParseLabelAutoDiscoverySpecs(discoveryOpts)
 	if err != nil {
@@ -179,6 +193,9 @@ func (m *AzureManager) buildNodeGroupFromSpec(spec string) (cloudprovider.NodeGr
 // Refresh is called before every main loop and can be used to dynamically update cloud provider state.
 // In particular the list of node groups returned by NodeGroups can change as a result of CloudProvider.Refresh().
 func (m *AzureManager) Refresh() error {
+	if m.config.EnableSpotEvictionReplacement {
+		m.handleSpotEvictions()
+	}
 	if m.lastRefresh.Add(m.azureCache.refreshInterval).After(time.Now()) {
 		return nil
 	}
@@ -193,11 +210,20 @@ func (m *AzureManager) forceRefresh() error {
 		klog.Errorf("Failed to regenerate Azure cache: %v", err)
 		return err
 	}
-	m.lastRefresh = time.Now()
+	// Subtract a random splay from the refresh time so that refreshes don't happen in lockstep
+	// with other autoscalers sharing the same subscription and ARM throttling limits.
+	m.lastRefresh = time.Now().Add(-m.refreshSplay())
 	klog.V(2).Infof("Refreshed Azure VM and VMSS list, next refresh after %v", m.lastRefresh.Add(m.azureCache.refreshInterval))
 	return nil
 }
 
+func (m *AzureManager) refreshSplay() time.Duration {
+	if m.refreshJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(m.refreshJitter) + 1))
+}
+
 func (m *AzureManager) invalidateCache() {
 	m.lastRefresh = time.Now().Add(-1 * m.azureCache.refreshInterval)
 	klog.V(2).Infof("Invalidated Azure cache")
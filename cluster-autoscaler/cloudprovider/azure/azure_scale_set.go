@@ -19,6 +19,7 @@ package azure
 import (
 	"fmt"
 	"math/rand"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -33,12 +34,17 @@ import (
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
 )
 
 var (
 	defaultVmssInstancesRefreshPeriod = 5 * time.Minute
 	vmssContextTimeout                = 3 * time.Minute
 	vmssSizeMutex                     sync.Mutex
+
+	// defaultDeltaRefreshMaxStaleness bounds how long the delta refresh logic can keep instances
+	// listed before a full listing is forced.
+	defaultDeltaRefreshMaxStaleness = 15 * time.Minute
 )
 
 const (
@@ -59,6 +65,8 @@ type ScaleSet struct {
 	maxSize int
 
 	enableForceDelete bool
+	// enableSpotEvictionSimulation evicts Spot instances via the simulate eviction API instead of deleting them.
+	enableSpotEvictionSimulation bool
 
 	sizeMutex sync.Mutex
 	curSize   int64
@@ -74,6 +82,18 @@ type ScaleSet struct {
 	instanceMutex       sync.Mutex
 	instanceCache       []cloudprovider.Instance
 	lastInstanceRefresh time.Time
+
+	// enableDeltaRefresh skips listing the VMSS instances when the scale set model didn't
+	// change since the last listing and merges listing results into the existing cache.
+	enableDeltaRefresh bool
+	// deltaRefreshMaxStaleness bounds how long delta refresh can skip listing the VMSS instances.
+	deltaRefreshMaxStaleness time.Duration
+	// instanceCacheETag is the entity tag of the scale set the instance cache was built from.
+	instanceCacheETag string
+	// instanceCacheListTime is the time the VMSS instances were last listed.
+	instanceCacheListTime time.Time
+	// evictedInstances are the provider IDs of instances with scheduled Spot evictions.
+	evictedInstances map[string]bool
 }
 
 // NewScaleSet creates a new NewScaleSet.
@@ -82,14 +102,16 @@ func NewScaleSet(spec *dynamic.NodeGroupSpec, az *AzureManager, curSize int64) (
 		azureRef: azureRef{
 			Name: spec.Name,
 		},
-		minSize:                   spec.MinSize,
-		maxSize:                   spec.MaxSize,
-		manager:                   az,
-		curSize:                   curSize,
-		sizeRefreshPeriod:         az.azureCache.refreshInterval,
-		enableDynamicInstanceList: az.config.EnableDynamicInstanceList,
-		instancesRefreshJitter:    az.config.VmssVmsCacheJitter,
-		enableForceDelete:         az.config.EnableForceDelete,
+		minSize:                      spec.MinSize,
+		maxSize:                      spec.MaxSize,
+		manager:                      az,
+		curSize:                      curSize,
+		sizeRefreshPeriod:            az.azureCache.refreshInterval,
+		enableDynamicInstanceList:    az.config.EnableDynamicInstanceList,
+		instancesRefreshJitter:       az.config.VmssVmsCacheJitter,
+		enableForceDelete:            az.config.EnableForceDelete,
+		enableDeltaRefresh:           az.config.EnableVmssVmsDeltaRefresh,
+		enableSpotEvictionSimulation: az.config.EnableSpotEvictionSimulation,
 	}
 
 	if az.config.VmssVmsCacheTTL != 0 {
@@ -98,6 +120,12 @@ func NewScaleSet(spec *dynamic.NodeGroupSpec, az *AzureManager, curSize int64) (
 		scaleSet.instancesRefreshPeriod = defaultVmssInstancesRefreshPeriod
 	}
 
+	if az.config.VmssVmsDeltaRefreshMaxStaleness != 0 {
+		scaleSet.deltaRefreshMaxStaleness = time.Duration(az.config.VmssVmsDeltaRefreshMaxStaleness) * time.Second
+	} else {
+		scaleSet.deltaRefreshMaxStaleness = defaultDeltaRefreshMaxStaleness
+	}
+
 	return scaleSet, nil
 }
 
@@ -168,6 +196,12 @@ func (scaleSet *ScaleSet) getCurSize() (int64, error) {
 		return -1, err
 	}
 
+	// Flexible orchestration scale sets created without a VM profile don't have a capacity,
+	// their VMs are added individually and can't be autoscaled.
+	if set.Sku == nil || set.Sku.Capacity == nil {
+		return -1, fmt.Errorf("vmss %q has no capacity, scale sets without a VM profile can't be autoscaled", scaleSet.Name)
+	}
+
 	vmssSizeMutex.Lock()
 	curSize := *set.Sku.Capacity
 	vmssSizeMutex.Unlock()
@@ -449,6 +483,26 @@ func (scaleSet *ScaleSet) DeleteInstances(instances []*azureRef, hasUnregistered
 	defer cancel()
 	resourceGroup := scaleSet.manager.config.ResourceGroup
 
+	if scaleSet.manager.dryRun {
+		klog.Infof("Dry run: would delete or evict instances %v of %s", instanceIDs, scaleSet.Name)
+		return nil
+	}
+
+	if scaleSet.enableSpotEvictionSimulation && scaleSet.canSimulateEviction() {
+		scaleSet.instanceMutex.Lock()
+		err := scaleSet.simulateEviction(ctx, instanceIDs)
+		scaleSet.instanceMutex.Unlock()
+		if err != nil {
+			klog.Errorf("Evicting instances %v failed: %v", requiredIds.InstanceIds, err)
+			// Instances evicted before the failure are gone, refresh the caches.
+			scaleSet.invalidateInstanceCache()
+			scaleSet.invalidateLastSizeRefreshWithLock()
+			return err
+		}
+		scaleSet.markInstancesDeleted(instancesToDelete, hasUnregisteredNodes)
+		return nil
+	}
+
 	scaleSet.instanceMutex.Lock()
 	klog.V(3).Infof("Calling virtualMachineScaleSetsClient.DeleteInstancesAsync(%v), force delete set to %v", requiredIds.InstanceIds, scaleSet.enableForceDelete)
 	future, rerr := scaleSet.manager.azClient.virtualMachineScaleSetsClient.DeleteInstancesAsync(ctx, resourceGroup, commonAsg.Id(), *requiredIds, scaleSet.enableForceDelete)
@@ -465,24 +519,30 @@ func (scaleSet *ScaleSet) DeleteInstances(instances []*azureRef, hasUnregistered
 		return rerr.Error()
 	}
 
+	scaleSet.markInstancesDeleted(instancesToDelete, hasUnregisteredNodes)
+
+	go scaleSet.waitForDeleteInstances(future, requiredIds)
+
+	return nil
+}
+
+// markInstancesDeleted updates the cached size and instance states after the
+// instances were deleted or evicted.
+func (scaleSet *ScaleSet) markInstancesDeleted(instances []*azureRef, hasUnregisteredNodes bool) {
 	// Proactively decrement scale set size so that we don't
 	// go below minimum node count if cache data is stale
 	// only do it for non-unregistered nodes
 	if !hasUnregisteredNodes {
 		scaleSet.sizeMutex.Lock()
-		scaleSet.curSize -= int64(len(instanceIDs))
+		scaleSet.curSize -= int64(len(instances))
 		scaleSet.lastSizeRefresh = time.Now()
 		scaleSet.sizeMutex.Unlock()
 	}
 
 	// Proactively set the status of the instances to be deleted in cache
-	for _, instance := range instancesToDelete {
+	for _, instance := range instances {
 		scaleSet.setInstanceStatusByProviderID(instance.Name, cloudprovider.InstanceStatus{State: cloudprovider.InstanceDeleting})
 	}
-
-	go scaleSet.waitForDeleteInstances(future, requiredIds)
-
-	return nil
 }
 
 // DeleteNodes deletes the nodes from the group.
@@ -557,36 +617,57 @@ func (scaleSet *ScaleSet) Nodes() ([]cloudprovider.Instance, error) {
 		return nil, err
 	}
 
+	scaleSet.instanceMutex.Lock()
+	if scaleSet.instanceCacheValid(curSize) {
+		scaleSet.instanceMutex.Unlock()
+		klog.V(4).Infof("Nodes: returns with curSize %d", curSize)
+		return scaleSet.instanceCache, nil
+	}
+	cachedETag := scaleSet.instanceCacheETag
+	scaleSet.instanceMutex.Unlock()
+
+	// The entity tag is requested without holding instanceMutex, so that scale operations
+	// don't wait for the request.
+	var etag string
+	if scaleSet.enableDeltaRefresh {
+		etag = scaleSet.getETag(cachedETag)
+	}
+
 	scaleSet.instanceMutex.Lock()
 	defer scaleSet.instanceMutex.Unlock()
 
-	if int64(len(scaleSet.instanceCache)) == curSize &&
-		scaleSet.lastInstanceRefresh.Add(scaleSet.instancesRefreshPeriod).After(time.Now()) {
+	if scaleSet.instanceCacheValid(curSize) {
 		klog.V(4).Infof("Nodes: returns with curSize %d", curSize)
 		return scaleSet.instanceCache, nil
 	}
 
 	klog.V(4).Infof("Nodes: starts to get VMSS VMs")
-	splay := rand.New(rand.NewSource(time.Now().UnixNano())).Intn(scaleSet.instancesRefreshJitter + 1)
-	lastRefresh := time.Now().Add(-time.Second * time.Duration(splay))
+	lastRefresh := time.Now().Add(-scaleSet.instancesRefreshSplay())
 
-	orchestrationMode, err := scaleSet.getOrchestrationMode()
+	vmss, err := scaleSet.getVMSSFromCache()
 	if err != nil {
 		klog.Errorf("failed to get information for VMSS: %s, error: %v", scaleSet.Name, err)
 		return nil, err
 	}
 
+	if scaleSet.canSkipInstanceRefresh(etag, curSize, time.Now()) {
+		klog.V(4).Infof("Nodes: VMSS %q entity tag %s unchanged, skipping instances refresh", scaleSet.Name, etag)
+		scaleSet.lastInstanceRefresh = lastRefresh
+		return scaleSet.instanceCache, nil
+	}
+
+	orchestrationMode := vmss.OrchestrationMode
 	klog.V(4).Infof("VMSS: orchestration Mode %s", orchestrationMode)
 
 	if orchestrationMode == compute.Uniform {
-		err := scaleSet.buildScaleSetCache(lastRefresh)
+		err := scaleSet.buildScaleSetCache(lastRefresh, etag)
 		if err != nil {
 			return nil, err
 		}
 
 	} else if orchestrationMode == compute.Flexible {
 		if scaleSet.manager.config.EnableVmssFlex {
-			err := scaleSet.buildScaleSetCacheForFlex(lastRefresh)
+			err := scaleSet.buildScaleSetCacheForFlex(lastRefresh, etag)
 			if err != nil {
 				return nil, err
 			}
@@ -602,40 +683,147 @@ func (scaleSet *ScaleSet) Nodes() ([]cloudprovider.Instance, error) {
 	return scaleSet.instanceCache, nil
 }
 
-func (scaleSet *ScaleSet) buildScaleSetCache(lastRefresh time.Time) error {
+// instancesRefreshSplay returns a random duration, bounded by the configured jitter, used to
+// spread instance refreshes of different scale sets over time.
+func (scaleSet *ScaleSet) instancesRefreshSplay() time.Duration {
+	splay := rand.New(rand.NewSource(time.Now().UnixNano())).Intn(scaleSet.instancesRefreshJitter + 1)
+	return time.Second * time.Duration(splay)
+}
+
+// canSkipInstanceRefresh returns true if delta refresh is enabled and the cached instances are
+// known to be current: the entity tag of the scale set is unchanged since the last listing, which
+// is recent enough, the cache size matches the scale set capacity and no instance is in a
+// transitional state. Instance states don't change the entity tag of the scale set, hence the
+// staleness bound.
+// Should be called with instanceMutex held.
+func (scaleSet *ScaleSet) canSkipInstanceRefresh(etag string, curSize int64, now time.Time) bool {
+	if !scaleSet.enableDeltaRefresh || etag == "" || etag != scaleSet.instanceCacheETag {
+		return false
+	}
+	if int64(len(scaleSet.instanceCache)) != curSize {
+		return false
+	}
+	if now.Sub(scaleSet.instanceCacheListTime) >= scaleSet.deltaRefreshMaxStaleness {
+		return false
+	}
+	for _, instance := range scaleSet.instanceCache {
+		if instance.Status == nil || instance.Status.State != cloudprovider.InstanceRunning {
+			return false
+		}
+	}
+	return true
+}
+
+// instanceCacheValid returns true if the instance cache matches the scale set capacity and
+// hasn't expired yet.
+// Should be called with instanceMutex held.
+func (scaleSet *ScaleSet) instanceCacheValid(curSize int64) bool {
+	return int64(len(scaleSet.instanceCache)) == curSize &&
+		scaleSet.lastInstanceRefresh.Add(scaleSet.instancesRefreshPeriod).After(time.Now())
+}
+
+// getETag returns the entity tag of the scale set, or an empty string if it can't be determined.
+// ARM list endpoints don't honour conditional requests, so the scale set itself is requested with
+// the entity tag of the instance cache, cachedETag, in the If-None-Match header.
+// Should be called without instanceMutex held.
+func (scaleSet *ScaleSet) getETag(cachedETag string) string {
+	ctx, cancel := getContextWithCancel()
+	defer cancel()
+	resourceGroup := scaleSet.manager.config.ResourceGroup
+	etag, err := scaleSet.manager.azClient.vmssETagClient.GetETag(ctx, resourceGroup, scaleSet.Name, cachedETag)
+	if err != nil {
+		klog.Warningf("Failed to get entity tag of VMSS %q, instances will be listed: %v", scaleSet.Name, err)
+		return ""
+	}
+	return etag
+}
+
+func (scaleSet *ScaleSet) buildScaleSetCache(lastRefresh time.Time, etag string) error {
 	vms, rerr := scaleSet.GetScaleSetVms()
 	if rerr != nil {
 		if isAzureRequestsThrottled(rerr) {
 			// Log a warning and update the instance refresh time so that it would retry after cache expiration
 			klog.Warningf("GetScaleSetVms() is throttled with message %v, would return the cached instances", rerr)
-			scaleSet.lastInstanceRefresh = lastRefresh
+			scaleSet.lastInstanceRefresh = scaleSet.throttledInstanceRefresh(lastRefresh, rerr)
 			return nil
 		}
 		return rerr.Error()
 	}
 
-	scaleSet.instanceCache = buildInstanceCache(vms)
-	scaleSet.lastInstanceRefresh = lastRefresh
-
+	scaleSet.updateInstanceCache(buildInstanceCache(vms), lastRefresh, etag)
 	return nil
 }
 
-func (scaleSet *ScaleSet) buildScaleSetCacheForFlex(lastRefresh time.Time) error {
+func (scaleSet *ScaleSet) buildScaleSetCacheForFlex(lastRefresh time.Time, etag string) error {
 	vms, rerr := scaleSet.GetFlexibleScaleSetVms()
 	if rerr != nil {
 		if isAzureRequestsThrottled(rerr) {
 			// Log a warning and update the instance refresh time so that it would retry after cache expiration
 			klog.Warningf("GetFlexibleScaleSetVms() is throttled with message %v, would return the cached instances", rerr)
-			scaleSet.lastInstanceRefresh = lastRefresh
+			scaleSet.lastInstanceRefresh = scaleSet.throttledInstanceRefresh(lastRefresh, rerr)
 			return nil
 		}
 		return rerr.Error()
 	}
 
-	scaleSet.instanceCache = buildInstanceCache(vms)
+	scaleSet.updateInstanceCache(buildInstanceCache(vms), lastRefresh, etag)
+	return nil
+}
+
+// updateInstanceCache replaces the instance cache with the listed instances, merging them with
+// the previous content when delta refresh is enabled.
+// Should be called with instanceMutex held.
+func (scaleSet *ScaleSet) updateInstanceCache(instances []cloudprovider.Instance, lastRefresh time.Time, etag string) {
+	if scaleSet.enableDeltaRefresh {
+		instances = mergeInstanceCache(scaleSet.Name, scaleSet.instanceCache, instances)
+	}
+	scaleSet.instanceCache = instances
+	scaleSet.markEvictedInstances()
+	scaleSet.instanceCacheETag = etag
+	scaleSet.instanceCacheListTime = time.Now()
 	scaleSet.lastInstanceRefresh = lastRefresh
+}
 
-	return nil
+// throttledInstanceRefresh returns the instance refresh time to record after a throttled request,
+// so that the next refresh doesn't happen before the time requested by ARM in the Retry-After header.
+func (scaleSet *ScaleSet) throttledInstanceRefresh(lastRefresh time.Time, rerr *retry.Error) time.Time {
+	if rerr.RetryAfter.IsZero() {
+		return lastRefresh
+	}
+	// The cache expires at lastInstanceRefresh+instancesRefreshPeriod, make it expire after RetryAfter plus a splay.
+	retryRefresh := rerr.RetryAfter.Add(scaleSet.instancesRefreshSplay()).Add(-scaleSet.instancesRefreshPeriod)
+	if retryRefresh.After(lastRefresh) {
+		return retryRefresh
+	}
+	return lastRefresh
+}
+
+// mergeInstanceCache merges the freshly listed instances into the previously cached ones. Listing is
+// authoritative for the set of instances, but instances being deleted by the autoscaler keep their
+// deleting state until ARM reports them gone, since the instance view may lag behind the delete request.
+func mergeInstanceCache(name string, cached, listed []cloudprovider.Instance) []cloudprovider.Instance {
+	previous := make(map[string]cloudprovider.Instance, len(cached))
+	for _, instance := range cached {
+		previous[instance.Id] = instance
+	}
+
+	added, updated := 0, 0
+	merged := make([]cloudprovider.Instance, 0, len(listed))
+	for _, instance := range listed {
+		old, found := previous[instance.Id]
+		delete(previous, instance.Id)
+		if !found {
+			added++
+		} else if old.Status != nil && old.Status.State == cloudprovider.InstanceDeleting &&
+			(instance.Status == nil || instance.Status.State == cloudprovider.InstanceRunning) {
+			instance.Status = old.Status
+		} else if !reflect.DeepEqual(old.Status, instance.Status) {
+			updated++
+		}
+		merged = append(merged, instance)
+	}
+	klog.V(4).Infof("VMSS %q instance cache delta: %d added, %d updated, %d removed", name, added, updated, len(previous))
+	return merged
 }
 
 // Note that the GetScaleSetVms() results is not used directly because for the List endpoint,
@@ -647,25 +835,33 @@ func buildInstanceCache(vmList interface{}) []cloudprovider.Instance {
 	case []compute.VirtualMachineScaleSetVM:
 		for _, vm := range vms {
 			powerState := vmPowerStateRunning
-			if vm.InstanceView != nil && vm.InstanceView.Statuses != nil {
-				powerState = vmPowerStateFromStatuses(*vm.InstanceView.Statuses)
+			repairing := false
+			if vm.InstanceView != nil {
+				if vm.InstanceView.Statuses != nil {
+					powerState = vmPowerStateFromStatuses(*vm.InstanceView.Statuses)
+				}
+				repairing = isRepairingVm(vm.ProvisioningState, vm.InstanceView.VMHealth)
 			}
-			addInstanceToCache(&instances, vm.ID, vm.ProvisioningState, powerState)
+			addInstanceToCache(&instances, vm.ID, vm.ProvisioningState, powerState, repairing)
 		}
 	case []compute.VirtualMachine:
 		for _, vm := range vms {
 			powerState := vmPowerStateRunning
-			if vm.InstanceView != nil && vm.InstanceView.Statuses != nil {
-				powerState = vmPowerStateFromStatuses(*vm.InstanceView.Statuses)
+			repairing := false
+			if vm.InstanceView != nil {
+				if vm.InstanceView.Statuses != nil {
+					powerState = vmPowerStateFromStatuses(*vm.InstanceView.Statuses)
+				}
+				repairing = isRepairingVm(vm.ProvisioningState, vm.InstanceView.VMHealth)
 			}
-			addInstanceToCache(&instances, vm.ID, vm.ProvisioningState, powerState)
+			addInstanceToCache(&instances, vm.ID, vm.ProvisioningState, powerState, repairing)
 		}
 	}
 
 	return instances
 }
 
-func addInstanceToCache(instances *[]cloudprovider.Instance, id *string, provisioningState *string, powerState string) {
+func addInstanceToCache(instances *[]cloudprovider.Instance, id *string, provisioningState *string, powerState string, repairing bool) {
 	// The resource ID is empty string, which indicates the instance may be in deleting state.
 	if len(*id) == 0 {
 		return
@@ -678,9 +874,15 @@ func addInstanceToCache(instances *[]cloudprovider.Instance, id *string, provisi
 		return
 	}
 
+	status := instanceStatusFromProvisioningStateAndPowerState(resourceID, provisioningState, powerState)
+	if repairing && (status == nil || status.State != cloudprovider.InstanceDeleting) {
+		klog.V(4).Infof("VM %s is being repaired (provisioning state %s, power state %s)", resourceID, to.String(provisioningState), powerState)
+		status = &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRepairing}
+	}
+
 	*instances = append(*instances, cloudprovider.Instance{
 		Id:     "azure://" + resourceID,
-		Status: instanceStatusFromProvisioningStateAndPowerState(resourceID, provisioningState, powerState),
+		Status: status,
 	})
 }
 
@@ -749,6 +951,7 @@ func (scaleSet *ScaleSet) invalidateInstanceCache() {
 	scaleSet.instanceMutex.Lock()
 	// Set the instanceCache as outdated.
 	scaleSet.lastInstanceRefresh = time.Now().Add(-1 * scaleSet.instancesRefreshPeriod)
+	scaleSet.instanceCacheETag = ""
 	scaleSet.instanceMutex.Unlock()
 }
 
@@ -758,15 +961,6 @@ func (scaleSet *ScaleSet) invalidateLastSizeRefreshWithLock() {
 	scaleSet.sizeMutex.Unlock()
 }
 
-func (scaleSet *ScaleSet) getOrchestrationMode() (compute.OrchestrationMode, error) {
-	vmss, err := scaleSet.getVMSSFromCache()
-	if err != nil {
-		klog.Errorf("failed to get information for VMSS: %s, error: %v", scaleSet.Name, err)
-		return "", err
-	}
-	return vmss.OrchestrationMode, nil
-}
-
 func isOperationNotAllowed(rerr *retry.Error) bool {
 	return rerr != nil && rerr.ServiceErrorCode() == retry.OperationNotAllowed
 }
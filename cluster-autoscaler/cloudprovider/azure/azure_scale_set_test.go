@@ -17,9 +17,11 @@ limitations under the License.
 package azure
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -1009,3 +1011,100 @@ func TestTemplateNodeInfo(t *testing.T) {
 		assert.NotEmpty(t, nodeInfo.Pods)
 	})
 }
+
+func TestScaleSetNodesDeltaRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expectedScaleSets := newTestVMSSList(3, "test-asg", "eastus", compute.Uniform)
+	expectedVMSSVMs := newTestVMSSVMList(3)
+	for i := range expectedVMSSVMs {
+		expectedVMSSVMs[i].ProvisioningState = to.StringPtr(provisioningStateSucceeded)
+	}
+	provider := newTestProvider(t)
+
+	mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+	mockVMSSClient.EXPECT().List(gomock.Any(), provider.azureManager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+	provider.azureManager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+	mockVMClient := mockvmclient.NewMockInterface(ctrl)
+	mockVMClient.EXPECT().List(gomock.Any(), provider.azureManager.config.ResourceGroup).Return([]compute.VirtualMachine{}, nil).AnyTimes()
+	provider.azureManager.azClient.virtualMachinesClient = mockVMClient
+	mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), provider.azureManager.config.ResourceGroup, "test-asg", gomock.Any()).Return(expectedVMSSVMs, nil).Times(3)
+	provider.azureManager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+	scaleSet := newTestScaleSet(provider.azureManager, "test-asg")
+	etagClient := &fakeVMSSETagClient{etag: `"1"`, scaleSet: scaleSet}
+	provider.azureManager.azClient.vmssETagClient = etagClient
+
+	scaleSet.enableDeltaRefresh = true
+	scaleSet.deltaRefreshMaxStaleness = time.Hour
+	provider.azureManager.RegisterNodeGroup(scaleSet)
+	provider.azureManager.explicitlyConfigured["test-asg"] = true
+	err := provider.azureManager.Refresh()
+	assert.NoError(t, err)
+
+	assertNodes := func() {
+		instances, err := scaleSet.Nodes()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(instances))
+	}
+	// The entity tag of the scale set doesn't change, so instances are only listed once.
+	for i := 0; i < 3; i++ {
+		assertNodes()
+	}
+	assert.Equal(t, `"1"`, etagClient.ifNoneMatch)
+	assert.False(t, etagClient.calledWithInstanceMutexHeld)
+
+	// Instances are listed again once the scale set changed.
+	etagClient.etag = `"2"`
+	assertNodes()
+	assert.Equal(t, `"2"`, scaleSet.instanceCacheETag)
+
+	// Instances are listed again once the last listing is too old.
+	scaleSet.instanceCacheListTime = time.Now().Add(-time.Hour)
+	assertNodes()
+}
+
+type fakeVMSSETagClient struct {
+	etag                        string
+	ifNoneMatch                 string
+	scaleSet                    *ScaleSet
+	calledWithInstanceMutexHeld bool
+}
+
+func (c *fakeVMSSETagClient) GetETag(_ context.Context, _, _, ifNoneMatch string) (string, error) {
+	c.ifNoneMatch = ifNoneMatch
+	if c.scaleSet.instanceMutex.TryLock() {
+		c.scaleSet.instanceMutex.Unlock()
+	} else {
+		c.calledWithInstanceMutexHeld = true
+	}
+	return c.etag, nil
+}
+
+func TestMergeInstanceCache(t *testing.T) {
+	running := &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}
+	deleting := &cloudprovider.InstanceStatus{State: cloudprovider.InstanceDeleting}
+	creating := &cloudprovider.InstanceStatus{State: cloudprovider.InstanceCreating}
+
+	cached := []cloudprovider.Instance{
+		{Id: "vm-0", Status: running},
+		{Id: "vm-1", Status: deleting},
+		{Id: "vm-2", Status: creating},
+		{Id: "vm-3", Status: deleting},
+	}
+	listed := []cloudprovider.Instance{
+		{Id: "vm-0", Status: running},
+		{Id: "vm-1", Status: running},
+		{Id: "vm-2", Status: running},
+		{Id: "vm-4", Status: creating},
+	}
+
+	merged := mergeInstanceCache("test-asg", cached, listed)
+	assert.Equal(t, []cloudprovider.Instance{
+		{Id: "vm-0", Status: running},
+		{Id: "vm-1", Status: deleting},
+		{Id: "vm-2", Status: running},
+		{Id: "vm-4", Status: creating},
+	}, merged)
+}
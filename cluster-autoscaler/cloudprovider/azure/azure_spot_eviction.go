@@ -0,0 +1,223 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	// vmEventScheduledCondition is the node condition node problem detector sets when Azure
+	// scheduled an event for the VM of the node.
+	vmEventScheduledCondition apiv1.NodeConditionType = "VMEventScheduled"
+	// preemptEventType is the type of scheduled events of Spot evictions.
+	preemptEventType = "Preempt"
+	// spotEvictionNoticePeriod is the minimum time Azure gives between the scheduled event of a Spot
+	// eviction and the eviction.
+	spotEvictionNoticePeriod = 30 * time.Second
+)
+
+// SpotEvictionClient defines needed functions for azure compute.VirtualMachineScaleSetVMsClient.
+type SpotEvictionClient interface {
+	SimulateEviction(ctx context.Context, resourceGroupName string, VMScaleSetName string, instanceID string) (result autorest.Response, err error)
+}
+
+func newSpotEvictionClient(subscriptionID, endpoint string, authorizer autorest.Authorizer) SpotEvictionClient {
+	client := compute.NewVirtualMachineScaleSetVMsClientWithBaseURI(endpoint, subscriptionID)
+	client.Authorizer = authorizer
+	configureUserAgent(&client.Client)
+	return client
+}
+
+// canSimulateEviction returns true if instances of the scale set can be removed
+// by simulated evictions. Only Spot instances can be evicted, and only with the
+// Delete eviction policy the evicted instances are removed from the scale set
+// capacity like deleted instances. With the Deallocate eviction policy evicted
+// instances would stay in the scale set, so they're deleted instead.
+func (scaleSet *ScaleSet) canSimulateEviction() bool {
+	vmss, err := scaleSet.getVMSSFromCache()
+	if err != nil || vmss.VirtualMachineScaleSetProperties == nil || vmss.VirtualMachineProfile == nil {
+		return false
+	}
+	profile := vmss.VirtualMachineProfile
+	if profile.Priority != compute.Spot {
+		return false
+	}
+	if vmss.OrchestrationMode == compute.Flexible {
+		// Instances of Flexible orchestration scale sets are standalone VMs, which the scale set VM API can't evict.
+		klog.V(2).Infof("Deleting instances of %s instead of evicting them, as it uses Flexible orchestration", scaleSet.Name)
+		return false
+	}
+	if profile.EvictionPolicy != compute.VirtualMachineEvictionPolicyTypesDelete {
+		klog.V(2).Infof("Deleting instances of %s instead of evicting them, as evicted instances would be kept deallocated", scaleSet.Name)
+		return false
+	}
+	return true
+}
+
+// simulateEviction evicts the given instances of the scale set.
+func (scaleSet *ScaleSet) simulateEviction(ctx context.Context, instanceIDs []string) error {
+	resourceGroup := scaleSet.manager.config.ResourceGroup
+	for _, instanceID := range instanceIDs {
+		klog.V(3).Infof("Calling SimulateEviction(%s) for %s", instanceID, scaleSet.Name)
+		resp, err := scaleSet.manager.azClient.spotEvictionClient.SimulateEviction(ctx, resourceGroup, scaleSet.Name, instanceID)
+		if isSuccess, err := isSuccessHTTPResponse(resp.Response, err); !isSuccess {
+			return fmt.Errorf("simulate eviction of instance %s of %s failed: %v", instanceID, scaleSet.Name, err)
+		}
+	}
+	return nil
+}
+
+// handleSpotEvictions marks Spot instances with scheduled evictions in the instance caches of
+// the scale sets, and reports them as maintenance events. Eviction notices are read from the
+// conditions of the nodes, as the scheduled events of the Instance Metadata Service only cover
+// the VM the autoscaler runs on. Nodes with maintenance events are cordoned and their pods are
+// treated as pending, so that evicted capacity is replaced before the nodes disappear.
+func (m *AzureManager) handleSpotEvictions() {
+	if m.nodeLister == nil {
+		return
+	}
+	nodes, err := m.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list nodes, skipping Spot eviction handling: %v", err)
+		return
+	}
+	preempted := make(map[string]cloudprovider.MaintenanceEvent)
+	for _, node := range nodes {
+		if node.Spec.ProviderID == "" {
+			continue
+		}
+		if noticeTime, found := spotEvictionNoticeTime(node); found {
+			preempted[strings.ToLower(node.Spec.ProviderID)] = cloudprovider.MaintenanceEvent{
+				ProviderID:  node.Spec.ProviderID,
+				Type:        cloudprovider.MaintenanceEventTermination,
+				NotBefore:   noticeTime.Add(spotEvictionNoticePeriod),
+				Description: "Azure scheduled the eviction of this Spot instance",
+			}
+		}
+	}
+	var evictions []cloudprovider.MaintenanceEvent
+	for _, nodeGroup := range m.getNodeGroups() {
+		if scaleSet, ok := nodeGroup.(*ScaleSet); ok {
+			for _, id := range scaleSet.handleSpotEvictions(preempted) {
+				evictions = append(evictions, preempted[id])
+			}
+		}
+	}
+	m.spotEvictionsMutex.Lock()
+	defer m.spotEvictionsMutex.Unlock()
+	m.spotEvictions = evictions
+}
+
+// getSpotEvictions returns the scheduled Spot evictions found by the last handleSpotEvictions.
+func (m *AzureManager) getSpotEvictions() []cloudprovider.MaintenanceEvent {
+	m.spotEvictionsMutex.Lock()
+	defer m.spotEvictionsMutex.Unlock()
+	return append([]cloudprovider.MaintenanceEvent{}, m.spotEvictions...)
+}
+
+// spotEvictionNoticeTime returns when the node reported a scheduled Spot eviction of its VM, i.e.
+// a Preempt scheduled event as reported by node problem detector, and false if it didn't.
+func spotEvictionNoticeTime(node *apiv1.Node) (time.Time, bool) {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == vmEventScheduledCondition && condition.Status == apiv1.ConditionTrue &&
+			(strings.Contains(condition.Reason, preemptEventType) || strings.Contains(condition.Message, preemptEventType)) {
+			if condition.LastTransitionTime.IsZero() {
+				return time.Now(), true
+			}
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isSpot returns true if the scale set runs Spot instances.
+func (scaleSet *ScaleSet) isSpot() bool {
+	vmss, err := scaleSet.getVMSSFromCache()
+	if err != nil || vmss.VirtualMachineScaleSetProperties == nil || vmss.VirtualMachineProfile == nil {
+		return false
+	}
+	return vmss.VirtualMachineProfile.Priority == compute.Spot
+}
+
+// handleSpotEvictions marks the cached instances whose lowercased provider IDs are preempted
+// as being deleted. Returns lowercased provider IDs of the marked instances.
+func (scaleSet *ScaleSet) handleSpotEvictions(preempted map[string]cloudprovider.MaintenanceEvent) []string {
+	if !scaleSet.isSpot() {
+		return nil
+	}
+
+	scaleSet.instanceMutex.Lock()
+	defer scaleSet.instanceMutex.Unlock()
+	if scaleSet.evictedInstances == nil {
+		scaleSet.evictedInstances = make(map[string]bool)
+	}
+	cached := make(map[string]bool, len(scaleSet.instanceCache))
+	for _, instance := range scaleSet.instanceCache {
+		cached[instance.Id] = true
+		if _, found := preempted[strings.ToLower(instance.Id)]; scaleSet.evictedInstances[instance.Id] || !found {
+			continue
+		}
+		klog.V(2).Infof("Spot instance %s of %s is scheduled to be evicted", instance.Id, scaleSet.Name)
+		scaleSet.evictedInstances[instance.Id] = true
+	}
+	// Forget evicted instances once they're gone.
+	var evicted []string
+	for id := range scaleSet.evictedInstances {
+		if !cached[id] {
+			delete(scaleSet.evictedInstances, id)
+			continue
+		}
+		if _, found := preempted[strings.ToLower(id)]; found {
+			evicted = append(evicted, strings.ToLower(id))
+		}
+	}
+	scaleSet.markEvictedInstances()
+	sort.Strings(evicted)
+	return evicted
+}
+
+// markEvictedInstances sets the status of the cached instances with scheduled Spot evictions,
+// so they're no longer expected to run, also after the instance cache was refreshed.
+// Should be called with instanceMutex held.
+func (scaleSet *ScaleSet) markEvictedInstances() {
+	for i, instance := range scaleSet.instanceCache {
+		if !scaleSet.evictedInstances[instance.Id] {
+			continue
+		}
+		scaleSet.instanceCache[i].Status = &cloudprovider.InstanceStatus{
+			State: cloudprovider.InstanceDeleting,
+			ErrorInfo: &cloudprovider.InstanceErrorInfo{
+				ErrorClass:   cloudprovider.OtherErrorClass,
+				ErrorCode:    "spot-eviction",
+				ErrorMessage: "Azure scheduled the eviction of this Spot instance",
+			},
+		}
+	}
+}
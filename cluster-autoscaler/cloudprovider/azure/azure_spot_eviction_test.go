@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmclient/mockvmclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmssclient/mockvmssclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmssvmclient/mockvmssvmclient"
+)
+
+type fakeSpotEvictionClient struct {
+	evicted        []string
+	failInstanceID string
+}
+
+func (c *fakeSpotEvictionClient) SimulateEviction(ctx context.Context, resourceGroupName string, VMScaleSetName string, instanceID string) (autorest.Response, error) {
+	if instanceID == c.failInstanceID {
+		return autorest.Response{Response: &http.Response{StatusCode: http.StatusConflict}}, fmt.Errorf("conflict")
+	}
+	c.evicted = append(c.evicted, instanceID)
+	return autorest.Response{Response: &http.Response{StatusCode: http.StatusNoContent}}, nil
+}
+
+func TestDeleteNodesSpotEviction(t *testing.T) {
+	cases := []struct {
+		name              string
+		orchestrationMode compute.OrchestrationMode
+		priority          compute.VirtualMachinePriorityTypes
+		evictionPolicy    compute.VirtualMachineEvictionPolicyTypes
+		dryRun            bool
+		failInstanceID    string
+		expectedEvicted   []string
+		expectedErr       bool
+	}{
+		{
+			name:              "spot with delete eviction policy",
+			orchestrationMode: compute.Uniform,
+			priority:          compute.Spot,
+			evictionPolicy:    compute.VirtualMachineEvictionPolicyTypesDelete,
+			expectedEvicted:   []string{"0", "2"},
+		},
+		{
+			name:              "spot with delete eviction policy in dry-run mode",
+			orchestrationMode: compute.Uniform,
+			priority:          compute.Spot,
+			evictionPolicy:    compute.VirtualMachineEvictionPolicyTypesDelete,
+			dryRun:            true,
+		},
+		{
+			name:              "spot with delete eviction policy failing partway",
+			orchestrationMode: compute.Uniform,
+			priority:          compute.Spot,
+			evictionPolicy:    compute.VirtualMachineEvictionPolicyTypesDelete,
+			failInstanceID:    "2",
+			expectedEvicted:   []string{"0"},
+			expectedErr:       true,
+		},
+		{
+			name:              "spot with deallocate eviction policy",
+			orchestrationMode: compute.Uniform,
+			priority:          compute.Spot,
+			evictionPolicy:    compute.VirtualMachineEvictionPolicyTypesDeallocate,
+		},
+		{
+			name:              "regular",
+			orchestrationMode: compute.Uniform,
+			priority:          compute.Regular,
+		},
+		{
+			name:              "flexible spot with delete eviction policy",
+			orchestrationMode: compute.Flexible,
+			priority:          compute.Spot,
+			evictionPolicy:    compute.VirtualMachineEvictionPolicyTypesDelete,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			manager := newTestAzureManager(t)
+			manager.dryRun = tc.dryRun
+			expectedScaleSets := newTestVMSSList(3, testASG, testLocation, tc.orchestrationMode)
+			expectedScaleSets[0].VirtualMachineProfile = &compute.VirtualMachineScaleSetVMProfile{
+				Priority:       tc.priority,
+				EvictionPolicy: tc.evictionPolicy,
+			}
+
+			mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+			mockVMSSClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+			if tc.expectedEvicted == nil && !tc.dryRun {
+				mockVMSSClient.EXPECT().DeleteInstancesAsync(gomock.Any(), manager.config.ResourceGroup, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+				mockVMSSClient.EXPECT().WaitForDeleteInstancesResult(gomock.Any(), gomock.Any(), manager.config.ResourceGroup).Return(&http.Response{StatusCode: http.StatusOK}, nil).AnyTimes()
+			}
+			manager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+
+			mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+			mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, testASG, gomock.Any()).Return(newTestVMSSVMList(3), nil).AnyTimes()
+			manager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+
+			mockVMClient := mockvmclient.NewMockInterface(ctrl)
+			mockVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return([]compute.VirtualMachine{}, nil).AnyTimes()
+			mockVMClient.EXPECT().ListVmssFlexVMsWithoutInstanceView(gomock.Any(), testASG).Return(newTestVMList(3), nil).AnyTimes()
+			manager.azClient.virtualMachinesClient = mockVMClient
+			manager.config.EnableVmssFlex = true
+
+			spotEvictionClient := &fakeSpotEvictionClient{failInstanceID: tc.failInstanceID}
+			manager.azClient.spotEvictionClient = spotEvictionClient
+
+			scaleSet := newTestScaleSet(manager, testASG)
+			scaleSet.enableSpotEvictionSimulation = true
+			scaleSet.sizeRefreshPeriod = time.Minute
+			assert.True(t, manager.RegisterNodeGroup(scaleSet))
+			manager.explicitlyConfigured[testASG] = true
+			assert.NoError(t, manager.forceRefresh())
+
+			err := scaleSet.DeleteNodes([]*apiv1.Node{
+				newApiNode(tc.orchestrationMode, 0),
+				newApiNode(tc.orchestrationMode, 2),
+			})
+			assert.Equal(t, tc.expectedEvicted, spotEvictionClient.evicted)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				assert.Empty(t, scaleSet.instanceCacheETag)
+				assert.True(t, time.Since(scaleSet.lastInstanceRefresh) >= scaleSet.instancesRefreshPeriod)
+				return
+			}
+			assert.NoError(t, err)
+
+			expectedTargetSize := 1
+			if tc.dryRun {
+				expectedTargetSize = 3
+			}
+			targetSize, err := scaleSet.TargetSize()
+			assert.NoError(t, err)
+			assert.Equal(t, expectedTargetSize, targetSize)
+		})
+	}
+}
+
+func TestHandleSpotEvictions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager := newTestAzureManager(t)
+	manager.config.EnableSpotEvictionReplacement = true
+	expectedScaleSets := newTestVMSSList(3, testASG, testLocation, compute.Uniform)
+	expectedScaleSets[0].VirtualMachineProfile = &compute.VirtualMachineScaleSetVMProfile{
+		Priority:       compute.Spot,
+		EvictionPolicy: compute.VirtualMachineEvictionPolicyTypesDelete,
+	}
+
+	// The scale set is never scaled up, evicted capacity is replaced by regular scale-ups.
+	mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+	mockVMSSClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+	manager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+
+	mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, testASG, gomock.Any()).Return(newTestVMSSVMList(3), nil).AnyTimes()
+	manager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+
+	mockVMClient := mockvmclient.NewMockInterface(ctrl)
+	mockVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return([]compute.VirtualMachine{}, nil).AnyTimes()
+	manager.azClient.virtualMachinesClient = mockVMClient
+
+	evictedID := "azure://" + fmt.Sprintf(fakeVirtualMachineScaleSetVMID, 1)
+	node := func(name, providerID string, conditions ...apiv1.NodeCondition) *apiv1.Node {
+		n := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: providerID}, Status: apiv1.NodeStatus{Conditions: conditions}}
+		n.Name = name
+		return n
+	}
+	nodes := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	noticeTime := time.Now().Truncate(time.Second)
+	assert.NoError(t, nodes.Add(node("evicted", strings.ToUpper(evictedID), apiv1.NodeCondition{
+		Type: vmEventScheduledCondition, Status: apiv1.ConditionTrue, Reason: "VMEventScheduled", Message: "VM Scheduled event: Preempt",
+		LastTransitionTime: metav1.NewTime(noticeTime),
+	})))
+	assert.NoError(t, nodes.Add(node("rebooted", "azure://"+fmt.Sprintf(fakeVirtualMachineScaleSetVMID, 2), apiv1.NodeCondition{
+		Type: vmEventScheduledCondition, Status: apiv1.ConditionTrue, Reason: "VMEventScheduled", Message: "VM Scheduled event: Reboot",
+	})))
+	manager.nodeLister = v1lister.NewNodeLister(nodes)
+
+	scaleSet := newTestScaleSet(manager, testASG)
+	scaleSet.sizeRefreshPeriod = time.Minute
+	assert.True(t, manager.RegisterNodeGroup(scaleSet))
+	manager.explicitlyConfigured[testASG] = true
+	assert.NoError(t, manager.forceRefresh())
+	_, err := scaleSet.Nodes()
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, manager.Refresh())
+		targetSize, err := scaleSet.TargetSize()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, targetSize)
+
+		// Evicted instances stay marked after the instance cache was refreshed.
+		instances, err := scaleSet.Nodes()
+		assert.NoError(t, err)
+		for _, instance := range instances {
+			if instance.Id == evictedID {
+				assert.Equal(t, cloudprovider.InstanceDeleting, instance.Status.State)
+				assert.Equal(t, "spot-eviction", instance.Status.ErrorInfo.ErrorCode)
+			} else {
+				assert.Nil(t, instance.Status)
+			}
+		}
+
+		// Evictions are reported as maintenance events, so replacement capacity is provisioned before the node disappears.
+		events, err := (&AzureCloudProvider{azureManager: manager}).MaintenanceEvents()
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, strings.ToUpper(evictedID), events[0].ProviderID)
+			assert.Equal(t, cloudprovider.MaintenanceEventTermination, events[0].Type)
+			assert.Equal(t, noticeTime.Add(spotEvictionNoticePeriod), events[0].NotBefore)
+		}
+	}
+}
@@ -118,6 +118,12 @@ func buildNodeFromTemplate(scaleSetName string, template compute.VirtualMachineS
 		}
 	}
 
+	// VM sizes can be customized in the scale set profile, e.g. to disable hyper-threading.
+	if vcpusAvailable, found := customizedVCPUs(template); found {
+		klog.V(4).Infof("Using %d vCPUs available on customized VM size %s of %s", vcpusAvailable, *template.Sku.Name, scaleSetName)
+		vcpu = vcpusAvailable
+	}
+
 	node.Status.Capacity[apiv1.ResourcePods] = *resource.NewQuantity(110, resource.DecimalSI)
 	node.Status.Capacity[apiv1.ResourceCPU] = *resource.NewQuantity(vcpu, resource.DecimalSI)
 	// isNPSeries returns if a SKU is an NP-series SKU
@@ -160,6 +166,19 @@ func buildNodeFromTemplate(scaleSetName string, template compute.VirtualMachineS
 	return &node, nil
 }
 
+// customizedVCPUs returns the number of vCPUs available on VMs of the scale set, if the VM size is
+// customized in the hardware profile of the scale set.
+func customizedVCPUs(template compute.VirtualMachineScaleSet) (int64, bool) {
+	if template.VirtualMachineScaleSetProperties == nil || template.VirtualMachineProfile == nil {
+		return 0, false
+	}
+	hardwareProfile := template.VirtualMachineProfile.HardwareProfile
+	if hardwareProfile == nil || hardwareProfile.VMSizeProperties == nil || hardwareProfile.VMSizeProperties.VCPUsAvailable == nil {
+		return 0, false
+	}
+	return int64(*hardwareProfile.VMSizeProperties.VCPUsAvailable), true
+}
+
 func extractLabelsFromScaleSet(tags map[string]*string) map[string]string {
 	result := make(map[string]string)
 
@@ -18,6 +18,7 @@ package azure
 
 import (
 	"fmt"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/stretchr/testify/assert"
 	apiv1 "k8s.io/api/core/v1"
@@ -119,6 +120,26 @@ func TestExtractAllocatableResourcesFromScaleSet(t *testing.T) {
 	assert.Equal(t, (&exepectedCustomAllocatable).String(), labels["nvidia.com/Tesla-P100-PCIE"].String())
 }
 
+func TestCustomizedVCPUs(t *testing.T) {
+	template := compute.VirtualMachineScaleSet{}
+	_, found := customizedVCPUs(template)
+	assert.False(t, found)
+
+	template.VirtualMachineScaleSetProperties = &compute.VirtualMachineScaleSetProperties{
+		OrchestrationMode:     compute.Flexible,
+		VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{},
+	}
+	_, found = customizedVCPUs(template)
+	assert.False(t, found)
+
+	template.VirtualMachineProfile.HardwareProfile = &compute.VirtualMachineScaleSetHardwareProfile{
+		VMSizeProperties: &compute.VMSizeProperties{VCPUsAvailable: to.Int32Ptr(4)},
+	}
+	vcpus, found := customizedVCPUs(template)
+	assert.True(t, found)
+	assert.Equal(t, int64(4), vcpus)
+}
+
 func makeTaintSet(taints []apiv1.Taint) map[apiv1.Taint]bool {
 	set := make(map[apiv1.Taint]bool)
 	for _, taint := range taints {
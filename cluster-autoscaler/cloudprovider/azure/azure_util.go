@@ -92,6 +92,10 @@ const (
 	vmPowerStateDeallocating = "PowerState/deallocating"
 	vmPowerStateDeallocated  = "PowerState/deallocated"
 	vmPowerStateUnknown      = "PowerState/unknown"
+
+	// vmHealthStateUnhealthy is reported by the application health extension for unhealthy VMs,
+	// which are the ones AKS node auto-repair and VMSS automatic instance repairs act on.
+	vmHealthStateUnhealthy = "HealthState/unhealthy"
 )
 
 var (
@@ -636,6 +640,20 @@ func isKnownVmPowerState(powerState string) bool {
 	return knownPowerStates[powerState]
 }
 
+// isRepairingVm returns true if the VM is being repaired, i.e. it is reported unhealthy and
+// is being updated (reimaged or redeployed) by the repair operation. Unhealthy VMs which aren't
+// updating may never recover, so they're not considered as being repaired, which would keep
+// them from being handled as failed indefinitely.
+func isRepairingVm(provisioningState *string, health *compute.VirtualMachineHealthStatus) bool {
+	if health == nil || health.Status == nil || health.Status.Code == nil {
+		return false
+	}
+	if !strings.EqualFold(*health.Status.Code, vmHealthStateUnhealthy) {
+		return false
+	}
+	return provisioningState != nil && *provisioningState == provisioningStateUpdating
+}
+
 func vmPowerStateFromStatuses(statuses []compute.InstanceViewStatus) string {
 	for _, status := range statuses {
 		if status.Code == nil || !isKnownVmPowerState(*status.Code) {
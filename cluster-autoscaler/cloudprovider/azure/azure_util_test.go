@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/stretchr/testify/assert"
 
 	"sigs.k8s.io/cloud-provider-azure/pkg/retry"
@@ -291,6 +292,50 @@ func TestIsAzureRequestsThrottled(t *testing.T) {
 	}
 }
 
+func TestIsRepairingVm(t *testing.T) {
+	unhealthy := &compute.VirtualMachineHealthStatus{Status: &compute.InstanceViewStatus{Code: to.StringPtr(vmHealthStateUnhealthy)}}
+	healthy := &compute.VirtualMachineHealthStatus{Status: &compute.InstanceViewStatus{Code: to.StringPtr("HealthState/healthy")}}
+	tests := []struct {
+		desc              string
+		provisioningState *string
+		health            *compute.VirtualMachineHealthStatus
+		expected          bool
+	}{
+		{
+			desc:     "no health status should return false",
+			expected: false,
+		},
+		{
+			desc:              "healthy updating VM should return false",
+			provisioningState: to.StringPtr(provisioningStateUpdating),
+			health:            healthy,
+			expected:          false,
+		},
+		{
+			desc:              "unhealthy running VM should return false",
+			provisioningState: to.StringPtr(provisioningStateSucceeded),
+			health:            unhealthy,
+			expected:          false,
+		},
+		{
+			desc:              "unhealthy updating VM should return true",
+			provisioningState: to.StringPtr(provisioningStateUpdating),
+			health:            unhealthy,
+			expected:          true,
+		},
+		{
+			desc:              "unhealthy failed VM should return false",
+			provisioningState: to.StringPtr(provisioningStateFailed),
+			health:            unhealthy,
+			expected:          false,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, isRepairingVm(test.provisioningState, test.health), test.desc)
+	}
+}
+
 func TestNormalizeMasterResourcesForScaling(t *testing.T) {
 	templateMap := map[string]interface{}{
 		resourcesFieldName: []interface{}{
@@ -102,7 +102,7 @@ func buildCloudProvider(opts config.AutoscalingOptions,
 	case cloudprovider.AwsProviderName:
 		return aws.BuildAWS(opts, do, rl)
 	case cloudprovider.AzureProviderName:
-		return azure.BuildAzure(opts, do, rl)
+		return azure.BuildAzure(opts, do, rl, informerFactory)
 	case cloudprovider.AlicloudProviderName:
 		return alicloud.BuildAlicloud(opts, do, rl)
 	case cloudprovider.CherryServersProviderName:
@@ -126,7 +126,7 @@ func buildCloudProvider(opts config.AutoscalingOptions,
 	case cloudprovider.OVHcloudProviderName:
 		return ovhcloud.BuildOVHcloud(opts, do, rl)
 	case cloudprovider.HetznerProviderName:
-		return hetzner.BuildHetzner(opts, do, rl)
+		return hetzner.BuildHetzner(opts, do, rl, informerFactory)
 	case cloudprovider.PacketProviderName, cloudprovider.EquinixMetalProviderName:
 		return equinixmetal.BuildCloudProvider(opts, do, rl)
 	case cloudprovider.ClusterAPIProviderName:
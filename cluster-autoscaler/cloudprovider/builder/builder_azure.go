@@ -34,10 +34,10 @@ var AvailableCloudProviders = []string{
 // DefaultCloudProvider on Azure-only build is Azure.
 const DefaultCloudProvider = cloudprovider.AzureProviderName
 
-func buildCloudProvider(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter, _ informers.SharedInformerFactory) cloudprovider.CloudProvider {
+func buildCloudProvider(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter, informerFactory informers.SharedInformerFactory) cloudprovider.CloudProvider {
 	switch opts.CloudProviderName {
 	case cloudprovider.AzureProviderName:
-		return azure.BuildAzure(opts, do, rl)
+		return azure.BuildAzure(opts, do, rl, informerFactory)
 	}
 
 	return nil
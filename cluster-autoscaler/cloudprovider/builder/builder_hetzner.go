@@ -34,10 +34,10 @@ var AvailableCloudProviders = []string{
 // DefaultCloudProvider is Hetzner.
 const DefaultCloudProvider = cloudprovider.HetznerProviderName
 
-func buildCloudProvider(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter, _ informers.SharedInformerFactory) cloudprovider.CloudProvider {
+func buildCloudProvider(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter, informerFactory informers.SharedInformerFactory) cloudprovider.CloudProvider {
 	switch opts.CloudProviderName {
 	case cloudprovider.HetznerProviderName:
-		return hetzner.BuildHetzner(opts, do, rl)
+		return hetzner.BuildHetzner(opts, do, rl, informerFactory)
 	}
 
 	return nil
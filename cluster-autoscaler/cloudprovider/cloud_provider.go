@@ -266,7 +266,7 @@ type InstanceStatus struct {
 	ErrorInfo *InstanceErrorInfo
 }
 
-// InstanceState tells if instance is running, being created or being deleted
+// InstanceState tells if instance is running, being created, being deleted or being repaired
 type InstanceState int
 
 const (
@@ -276,6 +276,10 @@ const (
 	InstanceCreating InstanceState = 2
 	// InstanceDeleting means instance is being deleted
 	InstanceDeleting InstanceState = 3
+	// InstanceRepairing means instance is being repaired (e.g. reimaged or redeployed) by the
+	// cloud provider and is expected to return to running. It is not an error condition: nodes of
+	// such instances are neither counted as unready nor removed when they don't register.
+	InstanceRepairing InstanceState = 4
 )
 
 // InstanceErrorInfo provides information about error condition on instance
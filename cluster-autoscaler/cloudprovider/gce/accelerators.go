@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	gce "google.golang.org/api/compute/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ResourceGoogleTPU is the name of the TPU resource.
+	ResourceGoogleTPU = "google.com/tpu"
+	// TPUAcceleratorLabel is the label added to nodes with TPUs, its value is the TPU type.
+	TPUAcceleratorLabel = "cloud.google.com/gke-tpu-accelerator"
+	// TPUTopologyLabel is the label added to nodes with TPUs, its value is the TPU slice topology.
+	TPUTopologyLabel = "cloud.google.com/gke-tpu-topology"
+
+	// tpuEnvKey is the instance template metadata key describing attached TPUs.
+	tpuEnvKey = "tpu-env"
+	// tpuTaintKey is the key of the taint added to nodes with TPUs.
+	tpuTaintKey = "google.com/tpu"
+)
+
+// acceleratorTemplate holds resources, labels and taints added to template
+// nodes for accelerators other than NVIDIA GPUs.
+type acceleratorTemplate struct {
+	resources apiv1.ResourceList
+	labels    map[string]string
+	taints    []apiv1.Taint
+}
+
+// buildAcceleratorTemplate builds resources, labels and taints of TPUs
+// attached to instances of the template and of custom guest accelerators.
+func buildAcceleratorTemplate(template *gce.InstanceTemplate, kubeEnv KubeEnv) (*acceleratorTemplate, error) {
+	result := &acceleratorTemplate{
+		resources: apiv1.ResourceList{},
+		labels:    map[string]string{},
+	}
+
+	customResources, err := extractAcceleratorResourceNamesFromKubeEnv(kubeEnv)
+	if err != nil {
+		return nil, err
+	}
+	for _, accelerator := range template.Properties.GuestAccelerators {
+		resourceName, found := customResources[accelerator.AcceleratorType]
+		if !found || accelerator.AcceleratorCount <= 0 {
+			continue
+		}
+		quantity := result.resources[resourceName]
+		quantity.Add(*resource.NewQuantity(accelerator.AcceleratorCount, resource.DecimalSI))
+		result.resources[resourceName] = quantity
+	}
+
+	tpuEnv, found, err := extractTpuEnv(template)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		chips, err := tpuChipsPerHost(tpuEnv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s of instance template %s: %v", tpuEnvKey, template.Name, err)
+		}
+		result.resources[ResourceGoogleTPU] = *resource.NewQuantity(chips, resource.DecimalSI)
+		if acceleratorType := tpuEnv["ACCELERATOR_TYPE"]; acceleratorType != "" {
+			result.labels[TPUAcceleratorLabel] = acceleratorType
+		}
+		if topology := tpuEnv["TOPOLOGY"]; topology != "" {
+			result.labels[TPUTopologyLabel] = topology
+		}
+		result.taints = append(result.taints, apiv1.Taint{Key: tpuTaintKey, Value: "present", Effect: apiv1.TaintEffectNoSchedule})
+	}
+
+	return result, nil
+}
+
+// extractAcceleratorResourceNamesFromKubeEnv returns the extended resources
+// custom guest accelerators are advertised as, by accelerator type.
+func extractAcceleratorResourceNamesFromKubeEnv(kubeEnv KubeEnv) (map[string]apiv1.ResourceName, error) {
+	resourceNamesAsString, found, err := extractAutoscalerVarFromKubeEnv(kubeEnv, "accelerator_resource_names")
+	if err != nil {
+		klog.Warningf("error while obtaining accelerator_resource_names from AUTOSCALER_ENV_VARS; %v", err)
+		return nil, err
+	}
+	if !found {
+		return map[string]apiv1.ResourceName{}, nil
+	}
+
+	resourceNamesMap, err := parseKeyValueListToMap(resourceNamesAsString)
+	if err != nil {
+		return nil, err
+	}
+	resourceNames := make(map[string]apiv1.ResourceName, len(resourceNamesMap))
+	for acceleratorType, resourceName := range resourceNamesMap {
+		resourceNames[acceleratorType] = apiv1.ResourceName(resourceName)
+	}
+	return resourceNames, nil
+}
+
+// extractTpuEnv extracts the TPU attachment metadata of the template.
+func extractTpuEnv(template *gce.InstanceTemplate) (map[string]string, bool, error) {
+	if template.Properties.Metadata == nil {
+		return nil, false, nil
+	}
+	for _, item := range template.Properties.Metadata.Items {
+		if item.Key != tpuEnvKey || item.Value == nil {
+			continue
+		}
+		env := make(map[string]string)
+		if err := yaml.Unmarshal([]byte(*item.Value), &env); err != nil {
+			return nil, false, fmt.Errorf("error unmarshalling %s: %v", tpuEnvKey, err)
+		}
+		return env, true, nil
+	}
+	return nil, false, nil
+}
+
+// tpuChipsPerHost returns the number of TPU chips attached to a single host,
+// the product of the CHIPS_PER_HOST_BOUNDS dimensions, e.g. "2,2,1".
+func tpuChipsPerHost(tpuEnv map[string]string) (int64, error) {
+	bounds, found := tpuEnv["CHIPS_PER_HOST_BOUNDS"]
+	if !found {
+		return 0, fmt.Errorf("CHIPS_PER_HOST_BOUNDS not found")
+	}
+	chips := int64(1)
+	for _, dimension := range strings.Split(bounds, ",") {
+		n, err := strconv.ParseInt(strings.TrimSpace(dimension), 10, 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("malformed CHIPS_PER_HOST_BOUNDS: %s", bounds)
+		}
+		chips *= n
+	}
+	return chips, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gce "google.golang.org/api/compute/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/gce/localssdsize"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
+)
+
+func TestBuildNodeFromTemplateAccelerators(t *testing.T) {
+	tpuTaint := apiv1.Taint{Key: tpuTaintKey, Value: "present", Effect: apiv1.TaintEffectNoSchedule}
+	testCases := []struct {
+		name              string
+		kubeEnv           string
+		tpuEnv            string
+		accelerators      []*gce.AcceleratorConfig
+		expectedResources apiv1.ResourceList
+		expectedLabels    map[string]string
+		expectedTaints    []apiv1.Taint
+		expectedErr       bool
+	}{
+		{
+			name:    "tpu",
+			kubeEnv: "AUTOSCALER_ENV_VARS: os=linux\n",
+			tpuEnv:  "ACCELERATOR_TYPE: 'v5litepod-16'\nTOPOLOGY: '4x4'\nCHIPS_PER_HOST_BOUNDS: '2,2,1'\n",
+			expectedResources: apiv1.ResourceList{
+				ResourceGoogleTPU: *resource.NewQuantity(4, resource.DecimalSI),
+			},
+			expectedLabels: map[string]string{
+				TPUAcceleratorLabel: "v5litepod-16",
+				TPUTopologyLabel:    "4x4",
+			},
+			expectedTaints: []apiv1.Taint{tpuTaint},
+		},
+		{
+			name:    "tpu taint from kube-env",
+			kubeEnv: "AUTOSCALER_ENV_VARS: os=linux;node_taints=google.com/tpu=present:NoExecute\n",
+			tpuEnv:  "ACCELERATOR_TYPE: 'v5litepod-4'\nCHIPS_PER_HOST_BOUNDS: '2,2,1'\n",
+			expectedResources: apiv1.ResourceList{
+				ResourceGoogleTPU: *resource.NewQuantity(4, resource.DecimalSI),
+			},
+			expectedLabels: map[string]string{
+				TPUAcceleratorLabel: "v5litepod-4",
+			},
+			expectedTaints: []apiv1.Taint{{Key: tpuTaintKey, Value: "present", Effect: apiv1.TaintEffectNoExecute}},
+		},
+		{
+			name:        "malformed tpu-env",
+			kubeEnv:     "AUTOSCALER_ENV_VARS: os=linux\n",
+			tpuEnv:      "ACCELERATOR_TYPE: 'v5litepod-4'\nCHIPS_PER_HOST_BOUNDS: '2,x'\n",
+			expectedErr: true,
+		},
+		{
+			name:    "custom accelerators",
+			kubeEnv: "AUTOSCALER_ENV_VARS: os=linux;accelerator_resource_names=example-npu=example.com/npu\n",
+			accelerators: []*gce.AcceleratorConfig{
+				{AcceleratorType: "example-npu", AcceleratorCount: 2},
+				{AcceleratorType: "nvidia-tesla-t4", AcceleratorCount: 1},
+				{AcceleratorType: "unknown", AcceleratorCount: 1},
+			},
+			expectedResources: apiv1.ResourceList{
+				"example.com/npu":     *resource.NewQuantity(2, resource.DecimalSI),
+				gpu.ResourceNvidiaGPU: *resource.NewQuantity(1, resource.DecimalSI),
+			},
+		},
+		{
+			name:    "only custom accelerators",
+			kubeEnv: "AUTOSCALER_ENV_VARS: os=linux;accelerator_resource_names=example-npu=example.com/npu\n",
+			accelerators: []*gce.AcceleratorConfig{
+				{AcceleratorType: "example-npu", AcceleratorCount: 2},
+			},
+			expectedResources: apiv1.ResourceList{
+				"example.com/npu": *resource.NewQuantity(2, resource.DecimalSI),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tb := &GceTemplateBuilder{}
+			mig := &gceMig{gceRef: GceRef{Name: "some-name", Project: "some-proj", Zone: "us-central1-b"}}
+			template := &gce.InstanceTemplate{
+				Name: "node-name",
+				Properties: &gce.InstanceProperties{
+					GuestAccelerators: tc.accelerators,
+					Metadata:          &gce.Metadata{Items: []*gce.MetadataItems{{Key: "kube-env", Value: &tc.kubeEnv}}},
+					MachineType:       "irrelevant-type",
+					Disks:             []*gce.AttachedDisk{{Boot: true, InitializeParams: &gce.AttachedDiskInitializeParams{DiskSizeGb: 100}}},
+				},
+			}
+			if tc.tpuEnv != "" {
+				template.Properties.Metadata.Items = append(template.Properties.Metadata.Items, &gce.MetadataItems{Key: tpuEnvKey, Value: &tc.tpuEnv})
+			}
+			kubeEnv, err := ExtractKubeEnv(template)
+			require.NoError(t, err)
+			migOsInfo, err := tb.MigOsInfo(mig.Id(), kubeEnv)
+			require.NoError(t, err)
+
+			node, err := tb.BuildNodeFromTemplate(mig, migOsInfo, template, kubeEnv, 8, 200*units.MiB, nil, &GceReserved{}, localssdsize.NewSimpleLocalSSDProvider())
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for resourceName, quantity := range tc.expectedResources {
+				actual := node.Status.Capacity[resourceName]
+				assert.True(t, quantity.Equal(actual), "capacity of %s: expected %v, got %v", resourceName, quantity.String(), actual.String())
+			}
+			if _, found := tc.expectedResources[gpu.ResourceNvidiaGPU]; !found {
+				assert.NotContains(t, node.Status.Capacity, apiv1.ResourceName(gpu.ResourceNvidiaGPU))
+			}
+			for key, value := range tc.expectedLabels {
+				assert.Equal(t, value, node.Labels[key])
+			}
+			assert.ElementsMatch(t, tc.expectedTaints, node.Spec.Taints)
+		})
+	}
+}
@@ -91,6 +91,11 @@ var (
 		regexp.MustCompile("Zone does not currently have sufficient capacity for the requested resources"),
 		regexp.MustCompile("Reservation (.*) does not have sufficient capacity for the requested resources."),
 	}
+	// Provisioning errors are sometimes reported with a generic error code, they're
+	// classified by their messages then.
+	regexResourcePoolExhaustedError = regexp.MustCompile("does not have enough resources available to fulfill the request")
+	regexQuotaExceededError         = regexp.MustCompile("Quota '(.*)' exceeded")
+	regexIPSpaceExhaustedError      = regexp.MustCompile("IP space of '(.*)' is exhausted")
 )
 
 // GceInstance extends cloudprovider.Instance with GCE specific numeric id.
@@ -119,6 +124,8 @@ type AutoscalingGceClient interface {
 	FetchReservations() ([]*gce.Reservation, error)
 	FetchReservationsInProject(projectId string) ([]*gce.Reservation, error)
 	FetchListManagedInstancesResults(migRef GceRef) (string, error)
+	FetchMigAutoscaler(migRef GceRef) (string, error)
+	FetchMigZones(migRef GceRef) ([]string, error)
 
 	// modifying resources
 	ResizeMig(GceRef, int64) error
@@ -210,6 +217,9 @@ func (client *autoscalingGceClientV1) FetchMachineTypes(zone string) ([]*gce.Mac
 }
 
 func (client *autoscalingGceClientV1) FetchAllMigs(zone string) ([]*gce.InstanceGroupManager, error) {
+	if isRegion(zone) {
+		return client.fetchAllRegionMigs(zone)
+	}
 	registerRequest("instance_group_managers", "list")
 	var migs []*gce.InstanceGroupManager
 	err := client.gceService.InstanceGroupManagers.List(client.projectId, zone).Pages(
@@ -224,11 +234,26 @@ func (client *autoscalingGceClientV1) FetchAllMigs(zone string) ([]*gce.Instance
 	return migs, nil
 }
 
+func (client *autoscalingGceClientV1) fetchAllRegionMigs(region string) ([]*gce.InstanceGroupManager, error) {
+	registerRequest("region_instance_group_managers", "list")
+	var migs []*gce.InstanceGroupManager
+	err := client.gceService.RegionInstanceGroupManagers.List(client.projectId, region).Pages(
+		context.TODO(),
+		func(page *gce.RegionInstanceGroupManagerList) error {
+			migs = append(migs, page.Items...)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return migs, nil
+}
+
 func (client *autoscalingGceClientV1) FetchMigTargetSize(migRef GceRef) (int64, error) {
 	registerRequest("instance_group_managers", "get")
 	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
 	defer cancel()
-	igm, err := client.gceService.InstanceGroupManagers.Get(migRef.Project, migRef.Zone, migRef.Name).Context(ctx).Do()
+	igm, err := client.getMig(ctx, migRef)
 	if err != nil {
 		if err, ok := err.(*googleapi.Error); ok {
 			if err.Code == http.StatusNotFound {
@@ -244,7 +269,7 @@ func (client *autoscalingGceClientV1) FetchMigBasename(migRef GceRef) (string, e
 	registerRequest("instance_group_managers", "get")
 	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
 	defer cancel()
-	igm, err := client.gceService.InstanceGroupManagers.Get(migRef.Project, migRef.Zone, migRef.Name).Context(ctx).Do()
+	igm, err := client.getMig(ctx, migRef)
 	if err != nil {
 		if err, ok := err.(*googleapi.Error); ok && err.Code == http.StatusNotFound {
 			return "", errors.NewAutoscalerError(errors.NodeGroupDoesNotExistError, "%s", err.Error())
@@ -258,7 +283,7 @@ func (client *autoscalingGceClientV1) FetchListManagedInstancesResults(migRef Gc
 	registerRequest("instance_group_managers", "get")
 	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
 	defer cancel()
-	igm, err := client.gceService.InstanceGroupManagers.Get(migRef.Project, migRef.Zone, migRef.Name).Context(ctx).Fields("listManagedInstancesResults").Do()
+	igm, err := client.getMig(ctx, migRef, "listManagedInstancesResults")
 	if err != nil {
 		if err, ok := err.(*googleapi.Error); ok {
 			if err.Code == http.StatusNotFound {
@@ -270,11 +295,90 @@ func (client *autoscalingGceClientV1) FetchListManagedInstancesResults(migRef Gc
 	return igm.ListManagedInstancesResults, nil
 }
 
+func (client *autoscalingGceClientV1) FetchMigAutoscaler(migRef GceRef) (string, error) {
+	registerRequest("instance_group_managers", "get")
+	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
+	defer cancel()
+	igm, err := client.getMig(ctx, migRef, "status/autoscaler")
+	if err != nil {
+		if err, ok := err.(*googleapi.Error); ok {
+			if err.Code == http.StatusNotFound {
+				return "", errors.NewAutoscalerError(errors.NodeGroupDoesNotExistError, "%s", err.Error())
+			}
+		}
+		return "", err
+	}
+	return migAutoscaler(igm), nil
+}
+
+func (client *autoscalingGceClientV1) FetchMigZones(migRef GceRef) ([]string, error) {
+	if !migRef.IsRegional() {
+		return []string{migRef.Zone}, nil
+	}
+	registerRequest("region_instance_group_managers", "get")
+	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
+	defer cancel()
+	igm, err := client.getMig(ctx, migRef, "distributionPolicy")
+	if err != nil {
+		if err, ok := err.(*googleapi.Error); ok && err.Code == http.StatusNotFound {
+			return nil, errors.NewAutoscalerError(errors.NodeGroupDoesNotExistError, "%s", err.Error())
+		}
+		return nil, err
+	}
+	if zones := migDistributionZones(igm); len(zones) > 0 {
+		return zones, nil
+	}
+	return client.FetchZones(migRef.Zone)
+}
+
+// migDistributionZones returns the zones a regional MIG distributes its instances across, if known.
+func migDistributionZones(igm *gce.InstanceGroupManager) []string {
+	if igm.DistributionPolicy == nil {
+		return nil
+	}
+	zones := make([]string, 0, len(igm.DistributionPolicy.Zones))
+	for _, zone := range igm.DistributionPolicy.Zones {
+		zones = append(zones, path.Base(zone.Zone))
+	}
+	return zones
+}
+
+// getMig fetches the given fields of a MIG, or all of them if none are given,
+// using the regional API for regional MIGs.
+func (client *autoscalingGceClientV1) getMig(ctx context.Context, migRef GceRef, fields ...googleapi.Field) (*gce.InstanceGroupManager, error) {
+	if migRef.IsRegional() {
+		call := client.gceService.RegionInstanceGroupManagers.Get(migRef.Project, migRef.Zone, migRef.Name).Context(ctx)
+		if len(fields) > 0 {
+			call = call.Fields(fields...)
+		}
+		return call.Do()
+	}
+	call := client.gceService.InstanceGroupManagers.Get(migRef.Project, migRef.Zone, migRef.Name).Context(ctx)
+	if len(fields) > 0 {
+		call = call.Fields(fields...)
+	}
+	return call.Do()
+}
+
+// migAutoscaler returns the URL of the GCE autoscaler attached to the MIG, if any.
+func migAutoscaler(igm *gce.InstanceGroupManager) string {
+	if igm.Status == nil {
+		return ""
+	}
+	return igm.Status.Autoscaler
+}
+
 func (client *autoscalingGceClientV1) ResizeMig(migRef GceRef, size int64) error {
 	registerRequest("instance_group_managers", "resize")
 	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
 	defer cancel()
-	op, err := client.gceService.InstanceGroupManagers.Resize(migRef.Project, migRef.Zone, migRef.Name, size).Context(ctx).Do()
+	var op *gce.Operation
+	var err error
+	if migRef.IsRegional() {
+		op, err = client.gceService.RegionInstanceGroupManagers.Resize(migRef.Project, migRef.Zone, migRef.Name, size).Context(ctx).Do()
+	} else {
+		op, err = client.gceService.InstanceGroupManagers.Resize(migRef.Project, migRef.Zone, migRef.Name, size).Context(ctx).Do()
+	}
 	if err != nil {
 		return err
 	}
@@ -294,7 +398,14 @@ func (client *autoscalingGceClientV1) CreateInstances(migRef GceRef, baseName st
 		req.Instances = append(req.Instances, &gce.PerInstanceConfig{Name: newInstanceName})
 	}
 
-	op, err := client.gceService.InstanceGroupManagers.CreateInstances(migRef.Project, migRef.Zone, migRef.Name, &req).Context(ctx).Do()
+	var op *gce.Operation
+	var err error
+	if migRef.IsRegional() {
+		regionReq := gce.RegionInstanceGroupManagersCreateInstancesRequest{Instances: req.Instances}
+		op, err = client.gceService.RegionInstanceGroupManagers.CreateInstances(migRef.Project, migRef.Zone, migRef.Name, &regionReq).Context(ctx).Do()
+	} else {
+		op, err = client.gceService.InstanceGroupManagers.CreateInstances(migRef.Project, migRef.Zone, migRef.Name, &req).Context(ctx).Do()
+	}
 	if err != nil {
 		return err
 	}
@@ -324,8 +435,15 @@ func (client *autoscalingGceClientV1) WaitForOperation(operationName, operationT
 
 	for {
 		klog.V(4).Infof("Waiting for operation %s/%s (%s/%s)", operationType, operationName, project, zone)
-		registerRequest("zone_operations", "wait")
-		op, err := client.gceService.ZoneOperations.Wait(project, zone, operationName).Context(ctx).Do()
+		var op *gce.Operation
+		var err error
+		if isRegion(zone) {
+			registerRequest("region_operations", "wait")
+			op, err = client.gceService.RegionOperations.Wait(project, zone, operationName).Context(ctx).Do()
+		} else {
+			registerRequest("zone_operations", "wait")
+			op, err = client.gceService.ZoneOperations.Wait(project, zone, operationName).Context(ctx).Do()
+		}
 		if err != nil {
 			return fmt.Errorf("error while waiting for operation %s/%s: %w", operationType, operationName, err)
 		}
@@ -359,7 +477,17 @@ func (client *autoscalingGceClientV1) DeleteInstances(migRef GceRef, instances [
 	for _, i := range instances {
 		req.Instances = append(req.Instances, GenerateInstanceUrl(client.domainUrl, i))
 	}
-	op, err := client.gceService.InstanceGroupManagers.DeleteInstances(migRef.Project, migRef.Zone, migRef.Name, &req).Context(ctx).Do()
+	var op *gce.Operation
+	var err error
+	if migRef.IsRegional() {
+		regionReq := gce.RegionInstanceGroupManagersDeleteInstancesRequest{
+			Instances:                      req.Instances,
+			SkipInstancesOnValidationError: req.SkipInstancesOnValidationError,
+		}
+		op, err = client.gceService.RegionInstanceGroupManagers.DeleteInstances(migRef.Project, migRef.Zone, migRef.Name, &regionReq).Context(ctx).Do()
+	} else {
+		op, err = client.gceService.InstanceGroupManagers.DeleteInstances(migRef.Project, migRef.Zone, migRef.Name, &req).Context(ctx).Do()
+	}
 	if err != nil {
 		return err
 	}
@@ -431,7 +559,12 @@ func createIgmRef(gceInstance *gce.Instance, loggingQuota *klogx.Quota) GceRef {
 func (client *autoscalingGceClientV1) FetchMigInstances(migRef GceRef) ([]GceInstance, error) {
 	registerRequest("instance_group_managers", "list_managed_instances")
 	b := newInstanceListBuilder(migRef)
-	err := client.gceService.InstanceGroupManagers.ListManagedInstances(migRef.Project, migRef.Zone, migRef.Name).Pages(context.Background(), b.loadPage)
+	var err error
+	if migRef.IsRegional() {
+		err = client.gceService.RegionInstanceGroupManagers.ListManagedInstances(migRef.Project, migRef.Zone, migRef.Name).Pages(context.Background(), b.loadRegionPage)
+	} else {
+		err = client.gceService.InstanceGroupManagers.ListManagedInstances(migRef.Project, migRef.Zone, migRef.Name).Pages(context.Background(), b.loadPage)
+	}
 	if err != nil {
 		klog.V(4).Infof("Failed MIG info request for %s %s %s: %v", migRef.Project, migRef.Zone, migRef.Name, err)
 		return nil, err
@@ -455,10 +588,18 @@ func newInstanceListBuilder(migRef GceRef) *instanceListBuilder {
 }
 
 func (i *instanceListBuilder) loadPage(page *gce.InstanceGroupManagersListManagedInstancesResponse) error {
+	return i.loadManagedInstances(page.ManagedInstances)
+}
+
+func (i *instanceListBuilder) loadRegionPage(page *gce.RegionInstanceGroupManagersListInstancesResponse) error {
+	return i.loadManagedInstances(page.ManagedInstances)
+}
+
+func (i *instanceListBuilder) loadManagedInstances(managedInstances []*gce.ManagedInstance) error {
 	if i.infos == nil {
-		i.infos = make([]GceInstance, 0, len(page.ManagedInstances))
+		i.infos = make([]GceInstance, 0, len(managedInstances))
 	}
-	for _, gceInstance := range page.ManagedInstances {
+	for _, gceInstance := range managedInstances {
 		ref, err := ParseInstanceUrlRef(gceInstance.Instance)
 		if err != nil {
 			klog.Errorf("Received error while parsing of the instance url: %v", err)
@@ -523,28 +664,33 @@ func (i *instanceListBuilder) gceInstanceToInstance(ref GceRef, gceInstance *gce
 func (i *instanceListBuilder) build() []GceInstance {
 	klogx.V(4).Over(i.errorLoggingQuota).Infof("Got %v other GCE instances being created with lastAttemptErrors", -i.errorLoggingQuota.Left())
 	if len(i.errorCodeCounts) > 0 {
-		klog.Warningf("Spotted following instance creation error codes: %#v", i.errorCodeCounts)
+		klog.Warningf("Spotted following instance creation error codes in MIG %s: %#v", i.migRef, i.errorCodeCounts)
 	}
 	return i.infos
 }
 
-// GetErrorInfo maps the error code, error message and instance status to CA instance error info
+// GetErrorInfo maps the error code, error message and instance status to CA instance error info.
+// Provisioning errors, i.e. stockouts, exceeded quota and exhausted IP space, take precedence
+// over the previous error info and aren't overridden by other errors.
 func GetErrorInfo(errorCode, errorMessage, instanceStatus string, previousErrorInfo *cloudprovider.InstanceErrorInfo) *cloudprovider.InstanceErrorInfo {
-	if isResourcePoolExhaustedErrorCode(errorCode) {
+	if isResourcePoolExhaustedError(errorCode, errorMessage) {
 		return &cloudprovider.InstanceErrorInfo{
 			ErrorClass: cloudprovider.OutOfResourcesErrorClass,
 			ErrorCode:  ErrorCodeResourcePoolExhausted,
 		}
-	} else if isQuotaExceededErrorCode(errorCode) {
+	} else if isQuotaExceededError(errorCode, errorMessage) {
 		return &cloudprovider.InstanceErrorInfo{
 			ErrorClass: cloudprovider.OutOfResourcesErrorClass,
 			ErrorCode:  ErrorCodeQuotaExceeded,
 		}
-	} else if isIPSpaceExhaustedErrorCode(errorCode) {
+	} else if isIPSpaceExhaustedError(errorCode, errorMessage) {
 		return &cloudprovider.InstanceErrorInfo{
 			ErrorClass: cloudprovider.OtherErrorClass,
 			ErrorCode:  ErrorIPSpaceExhausted,
 		}
+	} else if previousErrorInfo != nil && isProvisioningErrorInfo(previousErrorInfo) {
+		// keep the more specific error
+		return previousErrorInfo
 	} else if isPermissionsError(errorCode) {
 		return &cloudprovider.InstanceErrorInfo{
 			ErrorClass: cloudprovider.OtherErrorClass,
@@ -607,16 +753,25 @@ func getLastAttemptErrors(instance *gce.ManagedInstance) []*gce.ManagedInstanceL
 	return nil
 }
 
-func isResourcePoolExhaustedErrorCode(errorCode string) bool {
-	return errorCode == "RESOURCE_POOL_EXHAUSTED" || errorCode == "ZONE_RESOURCE_POOL_EXHAUSTED" || errorCode == "ZONE_RESOURCE_POOL_EXHAUSTED_WITH_DETAILS"
+func isResourcePoolExhaustedError(errorCode, errorMessage string) bool {
+	return errorCode == "RESOURCE_POOL_EXHAUSTED" || errorCode == "ZONE_RESOURCE_POOL_EXHAUSTED" || errorCode == "ZONE_RESOURCE_POOL_EXHAUSTED_WITH_DETAILS" ||
+		strings.Contains(errorCode, "STOCKOUT") || regexResourcePoolExhaustedError.MatchString(errorMessage)
 }
 
-func isQuotaExceededErrorCode(errorCode string) bool {
-	return strings.Contains(errorCode, "QUOTA")
+func isQuotaExceededError(errorCode, errorMessage string) bool {
+	return strings.Contains(errorCode, "QUOTA") || regexQuotaExceededError.MatchString(errorMessage)
 }
 
-func isIPSpaceExhaustedErrorCode(errorCode string) bool {
-	return strings.Contains(errorCode, "IP_SPACE_EXHAUSTED")
+func isIPSpaceExhaustedError(errorCode, errorMessage string) bool {
+	return strings.Contains(errorCode, "IP_SPACE_EXHAUSTED") || regexIPSpaceExhaustedError.MatchString(errorMessage)
+}
+
+func isProvisioningErrorInfo(errorInfo *cloudprovider.InstanceErrorInfo) bool {
+	switch errorInfo.ErrorCode {
+	case ErrorCodeResourcePoolExhausted, ErrorCodeQuotaExceeded, ErrorIPSpaceExhausted:
+		return true
+	}
+	return false
 }
 
 func isPermissionsError(errorCode string) bool {
@@ -716,7 +871,7 @@ func (client *autoscalingGceClientV1) FetchMigTemplateName(migRef GceRef) (Insta
 	registerRequest("instance_group_managers", "get")
 	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
 	defer cancel()
-	igm, err := client.gceService.InstanceGroupManagers.Get(migRef.Project, migRef.Zone, migRef.Name).Context(ctx).Do()
+	igm, err := client.getMig(ctx, migRef)
 	if err != nil {
 		if err, ok := err.(*googleapi.Error); ok {
 			if err.Code == http.StatusNotFound {
@@ -742,10 +897,8 @@ func (client *autoscalingGceClientV1) FetchMigTemplate(migRef GceRef, templateNa
 	ctx, cancel := context.WithTimeout(context.Background(), client.operationPerCallTimeout)
 	defer cancel()
 	if regional {
-		zoneHyphenIndex := strings.LastIndex(migRef.Zone, "-")
-		region := migRef.Zone[:zoneHyphenIndex]
 		registerRequest("region_instance_templates", "get")
-		return client.gceService.RegionInstanceTemplates.Get(migRef.Project, region, templateName).Context(ctx).Do()
+		return client.gceService.RegionInstanceTemplates.Get(migRef.Project, migRef.Region(), templateName).Context(ctx).Do()
 	}
 	registerRequest("instance_templates", "get")
 	return client.gceService.InstanceTemplates.Get(migRef.Project, templateName).Context(ctx).Do()
@@ -112,6 +112,46 @@ func TestWaitForOp(t *testing.T) {
 	mock.AssertExpectationsForObjects(t, server)
 }
 
+func TestWaitForRegionOp(t *testing.T) {
+	server := test_util.NewHttpServerMock()
+	defer server.Close()
+	g := newTestAutoscalingGceClient(t, "project1", server.URL, "")
+
+	g.operationPollInterval = 1 * time.Millisecond
+
+	server.On("handle", "/projects/project1/regions/us-central1/operations/operation-1505728466148-d16f5197/wait").Return(operationRunningResponse).Once()
+	server.On("handle", "/projects/project1/regions/us-central1/operations/operation-1505728466148-d16f5197/wait").Return(operationDoneResponse).Once()
+
+	err := g.WaitForOperation("operation-1505728466148-d16f5197", "TestWaitForRegionOp", projectId, "us-central1")
+	assert.NoError(t, err)
+	mock.AssertExpectationsForObjects(t, server)
+}
+
+func TestFetchMigZones(t *testing.T) {
+	server := test_util.NewHttpServerMock()
+	defer server.Close()
+	g := newTestAutoscalingGceClient(t, "project1", server.URL, "")
+
+	server.On("handle", "/projects/project1/regions/us-central1/instanceGroupManagers/mig").Return(`{
+  "name": "mig",
+  "distributionPolicy": {
+    "zones": [
+      {"zone": "https://www.googleapis.com/compute/v1/projects/project1/zones/us-central1-a"},
+      {"zone": "https://www.googleapis.com/compute/v1/projects/project1/zones/us-central1-c"}
+    ]
+  }
+}`).Once()
+
+	zones, err := g.FetchMigZones(GceRef{Project: "project1", Zone: "us-central1", Name: "mig"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-central1-a", "us-central1-c"}, zones)
+
+	zones, err = g.FetchMigZones(GceRef{Project: "project1", Zone: "us-central1-b", Name: "mig"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"us-central1-b"}, zones)
+	mock.AssertExpectationsForObjects(t, server)
+}
+
 func TestWaitForOpError(t *testing.T) {
 	server := test_util.NewHttpServerMock()
 	defer server.Close()
@@ -176,11 +216,34 @@ func TestErrors(t *testing.T) {
 			expectedErrorCode:  "RESOURCE_POOL_EXHAUSTED",
 			expectedErrorClass: cloudprovider.OutOfResourcesErrorClass,
 		},
+		{
+			errorCodes:         []string{"STOCKOUT"},
+			expectedErrorCode:  "RESOURCE_POOL_EXHAUSTED",
+			expectedErrorClass: cloudprovider.OutOfResourcesErrorClass,
+		},
+		{
+			errorCodes:         []string{"CONDITION_NOT_MET"},
+			errorMessage:       "Instance 'myinst' creation failed: The zone 'projects/myprojid/zones/myzone' does not have enough resources available to fulfill the request.",
+			expectedErrorCode:  "RESOURCE_POOL_EXHAUSTED",
+			expectedErrorClass: cloudprovider.OutOfResourcesErrorClass,
+		},
 		{
 			errorCodes:         []string{"QUOTA"},
 			expectedErrorCode:  "QUOTA_EXCEEDED",
 			expectedErrorClass: cloudprovider.OutOfResourcesErrorClass,
 		},
+		{
+			errorCodes:         []string{"CONDITION_NOT_MET"},
+			errorMessage:       "Instance 'myinst' creation failed: Quota 'CPUS' exceeded. Limit: 24.0 in region us-central1.",
+			expectedErrorCode:  "QUOTA_EXCEEDED",
+			expectedErrorClass: cloudprovider.OutOfResourcesErrorClass,
+		},
+		{
+			errorCodes:         []string{"CONDITION_NOT_MET"},
+			errorMessage:       "Instance 'myinst' creation failed: IP space of 'projects/myprojid/regions/myregion/subnetworks/mysubnet' is exhausted.",
+			expectedErrorCode:  "IP_SPACE_EXHAUSTED",
+			expectedErrorClass: cloudprovider.OtherErrorClass,
+		},
 		{
 			errorCodes:         []string{"PERMISSIONS_ERROR"},
 			expectedErrorCode:  "PERMISSIONS_ERROR",
@@ -672,6 +735,13 @@ func TestAutoscalingClientTimeouts(t *testing.T) {
 			},
 			operationPerCallTimeout: &instantTimeout,
 		},
+		"FetchMigAutoscaler_ContextTimeout": {
+			clientFunc: func(client *autoscalingGceClientV1) error {
+				_, err := client.FetchMigAutoscaler(GceRef{})
+				return err
+			},
+			operationPerCallTimeout: &instantTimeout,
+		},
 		"FetchZones_ContextTimeout": {
 			clientFunc: func(client *autoscalingGceClientV1) error {
 				_, err := client.FetchZones("")
@@ -739,6 +809,13 @@ func TestAutoscalingClientTimeouts(t *testing.T) {
 			},
 			httpTimeout: instantTimeout,
 		},
+		"FetchMigAutoscaler_HttpClientTimeout": {
+			clientFunc: func(client *autoscalingGceClientV1) error {
+				_, err := client.FetchMigAutoscaler(GceRef{})
+				return err
+			},
+			httpTimeout: instantTimeout,
+		},
 		"FetchZones_HttpClientTimeout": {
 			clientFunc: func(client *autoscalingGceClientV1) error {
 				_, err := client.FetchZones("")
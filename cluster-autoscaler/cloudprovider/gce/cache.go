@@ -40,6 +40,12 @@ type InstanceTemplateName struct {
 	Regional bool
 }
 
+// InstanceTemplateKey is used to identify the metadata of an instance template.
+type InstanceTemplateKey struct {
+	Name        string
+	Fingerprint string
+}
+
 // GceCache is used for caching cluster resources state.
 //
 // It is needed to:
@@ -75,9 +81,12 @@ type GceCache struct {
 	migBaseNameCache                 map[GceRef]string
 	migInstancesStateCache           map[GceRef]map[cloudprovider.InstanceState]int64
 	listManagedInstancesResultsCache map[GceRef]string
+	migAutoscalerCache               map[GceRef]string
+	migZonesCache                    map[GceRef][]string
 	instanceTemplateNameCache        map[GceRef]InstanceTemplateName
 	instanceTemplatesCache           map[GceRef]*gce.InstanceTemplate
 	kubeEnvCache                     map[GceRef]KubeEnv
+	templateKubeEnvCache             map[InstanceTemplateKey]KubeEnv
 }
 
 // NewGceCache creates empty GceCache.
@@ -94,9 +103,12 @@ func NewGceCache() *GceCache {
 		migBaseNameCache:                 map[GceRef]string{},
 		migInstancesStateCache:           map[GceRef]map[cloudprovider.InstanceState]int64{},
 		listManagedInstancesResultsCache: map[GceRef]string{},
+		migAutoscalerCache:               map[GceRef]string{},
+		migZonesCache:                    map[GceRef][]string{},
 		instanceTemplateNameCache:        map[GceRef]InstanceTemplateName{},
 		instanceTemplatesCache:           map[GceRef]*gce.InstanceTemplate{},
 		kubeEnvCache:                     map[GceRef]KubeEnv{},
+		templateKubeEnvCache:             map[InstanceTemplateKey]KubeEnv{},
 	}
 }
 
@@ -440,6 +452,8 @@ func (gc *GceCache) SetMigKubeEnv(ref GceRef, kubeEnv KubeEnv) {
 	defer gc.cacheMutex.Unlock()
 
 	gc.kubeEnvCache[ref] = kubeEnv
+	gc.templateKubeEnvCache[kubeEnv.templateKey()] = kubeEnv
+	gc.pruneTemplateKubeEnvs()
 }
 
 // InvalidateMigKubeEnv clears the kube-env cache for a mig GceRef
@@ -450,6 +464,7 @@ func (gc *GceCache) InvalidateMigKubeEnv(ref GceRef) {
 	if _, found := gc.kubeEnvCache[ref]; found {
 		klog.V(5).Infof("Kube-env cache invalidated for %s", ref)
 		delete(gc.kubeEnvCache, ref)
+		gc.pruneTemplateKubeEnvs()
 	}
 }
 
@@ -460,6 +475,35 @@ func (gc *GceCache) InvalidateAllMigKubeEnvs() {
 
 	klog.V(5).Infof("Kube-env cache invalidated")
 	gc.kubeEnvCache = map[GceRef]KubeEnv{}
+	gc.templateKubeEnvCache = map[InstanceTemplateKey]KubeEnv{}
+}
+
+// GetTemplateKubeEnv returns the cached KubeEnv extracted from an instance template
+// with the given name and metadata fingerprint.
+func (gc *GceCache) GetTemplateKubeEnv(key InstanceTemplateKey) (KubeEnv, bool) {
+	gc.cacheMutex.Lock()
+	defer gc.cacheMutex.Unlock()
+
+	kubeEnv, found := gc.templateKubeEnvCache[key]
+	if found {
+		klog.V(5).Infof("Kube-env cache hit for instance template %s", key.Name)
+	}
+	return kubeEnv, found
+}
+
+// pruneTemplateKubeEnvs drops kube-envs of instance templates no longer used by
+// any mig. It needs to be called with cacheMutex locked.
+func (gc *GceCache) pruneTemplateKubeEnvs() {
+	used := make(map[InstanceTemplateKey]bool, len(gc.kubeEnvCache))
+	for _, kubeEnv := range gc.kubeEnvCache {
+		used[kubeEnv.templateKey()] = true
+	}
+	for key := range gc.templateKubeEnvCache {
+		if !used[key] {
+			klog.V(5).Infof("Kube-env cache invalidated for instance template %s", key.Name)
+			delete(gc.templateKubeEnvCache, key)
+		}
+	}
 }
 
 // GetMachine retrieves machine type from cache under lock.
@@ -549,6 +593,51 @@ func (gc *GceCache) InvalidateAllListManagedInstancesResults() {
 	gc.listManagedInstancesResultsCache = make(map[GceRef]string)
 }
 
+// SetMigAutoscaler sets the URL of the GCE autoscaler attached to a given mig in cache.
+// Empty URL means that no GCE autoscaler is attached to the mig.
+func (gc *GceCache) SetMigAutoscaler(migRef GceRef, autoscaler string) {
+	gc.cacheMutex.Lock()
+	defer gc.cacheMutex.Unlock()
+	gc.migAutoscalerCache[migRef] = autoscaler
+}
+
+// GetMigAutoscaler gets the URL of the GCE autoscaler attached to a given mig from cache.
+func (gc *GceCache) GetMigAutoscaler(migRef GceRef) (string, bool) {
+	gc.cacheMutex.Lock()
+	defer gc.cacheMutex.Unlock()
+	autoscaler, found := gc.migAutoscalerCache[migRef]
+	return autoscaler, found
+}
+
+// InvalidateAllMigAutoscalers invalidates all mig autoscaler entries.
+func (gc *GceCache) InvalidateAllMigAutoscalers() {
+	gc.cacheMutex.Lock()
+	defer gc.cacheMutex.Unlock()
+	gc.migAutoscalerCache = make(map[GceRef]string)
+}
+
+// SetMigZones sets the zones a given mig distributes its instances across in cache.
+func (gc *GceCache) SetMigZones(migRef GceRef, zones []string) {
+	gc.cacheMutex.Lock()
+	defer gc.cacheMutex.Unlock()
+	gc.migZonesCache[migRef] = zones
+}
+
+// GetMigZones gets the zones a given mig distributes its instances across from cache.
+func (gc *GceCache) GetMigZones(migRef GceRef) ([]string, bool) {
+	gc.cacheMutex.Lock()
+	defer gc.cacheMutex.Unlock()
+	zones, found := gc.migZonesCache[migRef]
+	return zones, found
+}
+
+// InvalidateAllMigZones invalidates all mig zones entries.
+func (gc *GceCache) InvalidateAllMigZones() {
+	gc.cacheMutex.Lock()
+	defer gc.cacheMutex.Unlock()
+	gc.migZonesCache = make(map[GceRef][]string)
+}
+
 // GetMigInstancesState returns instancesState for the given mig from cache.
 func (gc *GceCache) GetMigInstancesState(migRef GceRef) (instanceState map[cloudprovider.InstanceState]int64, found bool) {
 	gc.cacheMutex.Lock()
@@ -88,6 +88,26 @@ func TestMachineCache(t *testing.T) {
 	}
 }
 
+func TestMigAutoscalerCache(t *testing.T) {
+	migRef := GceRef{
+		Project: "project",
+		Zone:    "us-test1",
+		Name:    "mig",
+	}
+	c := NewGceCache()
+	if _, found := c.GetMigAutoscaler(migRef); found {
+		t.Errorf("Didn't expect to find autoscaler for MIG ref: %s", migRef.String())
+	}
+	c.SetMigAutoscaler(migRef, "autoscaler")
+	if autoscaler, found := c.GetMigAutoscaler(migRef); !found || autoscaler != "autoscaler" {
+		t.Errorf("Expected autoscaler %s for MIG ref: %s, but got: %s", "autoscaler", migRef.String(), autoscaler)
+	}
+	c.InvalidateAllMigAutoscalers()
+	if cacheSize := len(c.migAutoscalerCache); cacheSize > 0 {
+		t.Errorf("Expected migAutoscalerCache to be empty, but it still contains %d entries", cacheSize)
+	}
+}
+
 func TestListManagedInstancesResultsCache(t *testing.T) {
 	checkInCache := func(c *GceCache, migRef GceRef, expectedResults string) {
 		result, found := c.GetListManagedInstancesResults(migRef)
@@ -24,10 +24,13 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	kube_client "k8s.io/client-go/kubernetes"
 	klog "k8s.io/klog/v2"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 )
@@ -148,6 +151,12 @@ func (gce *GceCloudProvider) Refresh() error {
 	return gce.gceManager.Refresh()
 }
 
+// RediscoverNodeGroups refetches MIGs and their autoscaling options, regardless
+// of the last refresh.
+func (gce *GceCloudProvider) RediscoverNodeGroups() error {
+	return gce.gceManager.ForceRefresh()
+}
+
 // GceRef contains s reference to some entity in GCE world.
 type GceRef struct {
 	Project string
@@ -159,6 +168,21 @@ func (ref GceRef) String() string {
 	return fmt.Sprintf("%s/%s/%s", ref.Project, ref.Zone, ref.Name)
 }
 
+// IsRegional returns true if the ref points to a regional resource, e.g. a MIG
+// spanning multiple zones, in which case Zone holds a region.
+func (ref GceRef) IsRegional() bool {
+	return isRegion(ref.Zone)
+}
+
+// Region returns the region of the ref.
+func (ref GceRef) Region() string {
+	ix := strings.LastIndex(ref.Zone, "-")
+	if ref.IsRegional() || ix == -1 {
+		return ref.Zone
+	}
+	return ref.Zone[:ix]
+}
+
 // ToProviderId converts GceRef to string in format used as ProviderId in Node object.
 func (ref GceRef) ToProviderId() string {
 	return fmt.Sprintf("gce://%s/%s/%s", ref.Project, ref.Zone, ref.Name)
@@ -383,7 +407,28 @@ func BuildGCE(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscover
 		defer config.Close()
 	}
 
-	manager, err := CreateGceManager(config, do, opts.GCEOptions.LocalSSDDiskSizeProvider, opts.Regional, opts.GCEOptions.ConcurrentRefreshes, opts.UserAgent, opts.GCEOptions.DomainUrl, opts.GCEOptions.MigInstancesMinRefreshWaitTime)
+	var kubeClient kube_client.Interface
+	if opts.GCEOptions.KubeEnvOverridesConfigMap != "" || opts.WriteStatusConfigMap {
+		kubeClient = kube_util.CreateKubeClient(opts.KubeClientOpts)
+	}
+	var kubeEnvOverrides KubeEnvOverridesProvider
+	if opts.GCEOptions.KubeEnvOverridesConfigMap != "" {
+		kubeEnvOverrides = NewConfigMapKubeEnvOverridesProvider(kubeClient, opts.ConfigNamespace, opts.GCEOptions.KubeEnvOverridesConfigMap, wait.NeverStop)
+	}
+	var kubeEnvErrorReporter KubeEnvErrorReporter
+	var templateChangeReporter InstanceTemplateChangeReporter
+	if opts.WriteStatusConfigMap {
+		eventRecorder := kube_util.CreateEventRecorder(kubeClient, opts.RecordDuplicatedEvents)
+		kubeEnvErrorReporter = NewStatusConfigMapKubeEnvErrorReporter(kubeClient, eventRecorder, opts.ConfigNamespace, opts.StatusConfigMapName)
+		templateChangeReporter = NewStatusConfigMapTemplateChangeReporter(kubeClient, eventRecorder, opts.ConfigNamespace, opts.StatusConfigMapName)
+	}
+
+	reservedMemoryModels, err := ParseReservedMemoryModels(opts.GCEOptions.ReservedMemoryModels)
+	if err != nil {
+		klog.Fatalf("Failed to parse GCE reserved memory models: %v", err)
+	}
+
+	manager, err := CreateGceManager(config, do, opts.GCEOptions.LocalSSDDiskSizeProvider, opts.Regional, opts.GCEOptions.ConcurrentRefreshes, opts.UserAgent, opts.GCEOptions.DomainUrl, opts.GCEOptions.MigInstancesMinRefreshWaitTime, kubeEnvOverrides, kubeEnvErrorReporter, templateChangeReporter, reservedMemoryModels)
 	if err != nil {
 		klog.Fatalf("Failed to create GCE Manager: %v", err)
 	}
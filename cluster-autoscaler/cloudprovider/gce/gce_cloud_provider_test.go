@@ -68,6 +68,11 @@ func (m *gceManagerMock) Refresh() error {
 	return args.Error(0)
 }
 
+func (m *gceManagerMock) ForceRefresh() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 func (m *gceManagerMock) Cleanup() error {
 	args := m.Called()
 	return args.Error(0)
@@ -24,8 +24,10 @@ import (
 	"os"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -77,6 +79,9 @@ var (
 type GceManager interface {
 	// Refresh triggers refresh of cached resources.
 	Refresh() error
+	// ForceRefresh refetches MIGs and their autoscaling options, regardless
+	// of the last refresh.
+	ForceRefresh() error
 	// Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
 	Cleanup() error
 
@@ -123,12 +128,27 @@ type gceManagerImpl struct {
 	migAutoDiscoverySpecs    []migAutoDiscoveryConfig
 	reserved                 *GceReserved
 	localSSDDiskSizeProvider localssdsize.LocalSSDSizeProvider
+	kubeEnvOverrides         KubeEnvOverridesProvider
+	kubeEnvErrorReporter     KubeEnvErrorReporter
+	templateChangeReporter   InstanceTemplateChangeReporter
+	// migTemplates holds the last seen instance templates of MIGs, for detecting
+	// template changes affecting scheduling.
+	migTemplates      map[GceRef]migTemplateSnapshot
+	migTemplatesMutex sync.Mutex
+}
+
+// migTemplateSnapshot is an instance template of a MIG together with its kube-env.
+type migTemplateSnapshot struct {
+	template *gce.InstanceTemplate
+	kubeEnv  KubeEnv
 }
 
 // CreateGceManager constructs GceManager object.
 func CreateGceManager(configReader io.Reader, discoveryOpts cloudprovider.NodeGroupDiscoveryOptions,
 	localSSDDiskSizeProvider localssdsize.LocalSSDSizeProvider,
-	regional bool, concurrentGceRefreshes int, userAgent, domainUrl string, migInstancesMinRefreshWaitTime time.Duration) (GceManager, error) {
+	regional bool, concurrentGceRefreshes int, userAgent, domainUrl string, migInstancesMinRefreshWaitTime time.Duration,
+	kubeEnvOverrides KubeEnvOverridesProvider, kubeEnvErrorReporter KubeEnvErrorReporter,
+	templateChangeReporter InstanceTemplateChangeReporter, reservedMemoryModels map[string]ReservedMemoryModel) (GceManager, error) {
 	// Create Google Compute Engine token.
 	var err error
 	tokenSource := google.ComputeTokenSource("")
@@ -192,13 +212,17 @@ func CreateGceManager(configReader io.Reader, discoveryOpts cloudprovider.NodeGr
 		location:                 location,
 		regional:                 regional,
 		projectId:                projectId,
-		templates:                &GceTemplateBuilder{},
+		templates:                NewGceTemplateBuilder(reservedMemoryModels),
 		interrupt:                make(chan struct{}),
 		explicitlyConfigured:     make(map[GceRef]bool),
 		concurrentGceRefreshes:   concurrentGceRefreshes,
 		reserved:                 &GceReserved{},
 		domainUrl:                domainUrl,
 		localSSDDiskSizeProvider: localSSDDiskSizeProvider,
+		kubeEnvOverrides:         kubeEnvOverrides,
+		kubeEnvErrorReporter:     kubeEnvErrorReporter,
+		templateChangeReporter:   templateChangeReporter,
+		migTemplates:             make(map[GceRef]migTemplateSnapshot),
 	}
 
 	if err := manager.fetchExplicitMigs(discoveryOpts.NodeGroupSpecs); err != nil {
@@ -248,6 +272,9 @@ func (m *gceManagerImpl) GetMigSize(mig Mig) (int64, error) {
 
 // SetMigSize sets MIG size.
 func (m *gceManagerImpl) SetMigSize(mig Mig, size int64) error {
+	if err := m.checkMigAutoscalerConflict(mig); err != nil {
+		return err
+	}
 	klog.V(0).Infof("Setting mig size %s to %d", mig.Id(), size)
 	m.cache.InvalidateMigTargetSize(mig.GceRef())
 	err := m.GceService.ResizeMig(mig.GceRef(), size)
@@ -276,6 +303,9 @@ func (m *gceManagerImpl) DeleteInstances(instances []GceRef) error {
 			return fmt.Errorf("cannot delete instances which don't belong to the same MIG.")
 		}
 	}
+	if err := m.checkMigAutoscalerConflict(commonMig); err != nil {
+		return err
+	}
 	m.cache.InvalidateMigTargetSize(commonMig.GceRef())
 	return m.GceService.DeleteInstances(commonMig.GceRef(), instances)
 }
@@ -302,6 +332,9 @@ func (m *gceManagerImpl) Refresh() error {
 	m.cache.InvalidateAllMigBasenames()
 	m.cache.InvalidateAllListManagedInstancesResults()
 	m.cache.InvalidateAllMigInstanceTemplateNames()
+	m.cache.InvalidateAllMigAutoscalers()
+	m.cache.InvalidateAllMigZones()
+	m.updateMigAutoscalerConflicts()
 	if m.lastRefresh.Add(refreshInterval).After(time.Now()) {
 		return nil
 	}
@@ -312,6 +345,9 @@ func (m *gceManagerImpl) CreateInstances(mig Mig, delta int64) error {
 	if delta == 0 {
 		return nil
 	}
+	if err := m.checkMigAutoscalerConflict(mig); err != nil {
+		return err
+	}
 	instances, err := m.GetMigNodes(mig)
 	if err != nil {
 		return err
@@ -328,6 +364,40 @@ func (m *gceManagerImpl) CreateInstances(mig Mig, delta int64) error {
 	return m.GceService.CreateInstances(mig.GceRef(), baseName, delta, instancesNames)
 }
 
+// checkMigAutoscalerConflict returns an error if a GCE autoscaler is attached
+// to the MIG. Such MIGs are not resized, as the two autoscalers would fight
+// over the target size.
+func (m *gceManagerImpl) checkMigAutoscalerConflict(mig Mig) error {
+	autoscaler, err := m.migInfoProvider.GetMigAutoscaler(mig.GceRef())
+	if err != nil {
+		klog.Warningf("Failed to check whether MIG %s is managed by a GCE autoscaler: %v", mig.Id(), err)
+		return nil
+	}
+	if autoscaler != "" {
+		return fmt.Errorf("MIG %s is managed by GCE autoscaler %s, refusing to resize it", mig.Id(), autoscaler)
+	}
+	return nil
+}
+
+// updateMigAutoscalerConflicts reports MIGs to which a GCE autoscaler is attached.
+func (m *gceManagerImpl) updateMigAutoscalerConflicts() {
+	conflicts := 0
+	for _, mig := range m.migLister.GetMigs() {
+		autoscaler, err := m.migInfoProvider.GetMigAutoscaler(mig.GceRef())
+		if err != nil || autoscaler == "" {
+			continue
+		}
+		conflicts++
+		klog.Warningf("MIG %s is managed by GCE autoscaler %s, it won't be resized until the GCE autoscaler is removed", mig.Id(), autoscaler)
+	}
+	updateMigAutoscalerConflictsCount(conflicts)
+}
+
+// ForceRefresh refetches MIGs and their autoscaling options.
+func (m *gceManagerImpl) ForceRefresh() error {
+	return m.forceRefresh()
+}
+
 func (m *gceManagerImpl) forceRefresh() error {
 	m.clearMachinesCache()
 	if err := m.fetchAutoMigs(); err != nil {
@@ -341,6 +411,9 @@ func (m *gceManagerImpl) forceRefresh() error {
 }
 
 func (m *gceManagerImpl) refreshAutoscalingOptions() {
+	kubeEnvParseErrors := make(map[GceRef]*KubeEnvParseError)
+	defer m.reportKubeEnvParseErrors(kubeEnvParseErrors)
+	defer m.pruneMigTemplates()
 	for _, mig := range m.migLister.GetMigs() {
 		template, err := m.migInfoProvider.GetMigInstanceTemplate(mig.GceRef())
 		if err != nil {
@@ -354,8 +427,13 @@ func (m *gceManagerImpl) refreshAutoscalingOptions() {
 		kubeEnv, err := m.migInfoProvider.GetMigKubeEnv(mig.GceRef())
 		if err != nil {
 			klog.Warningf("Failed to extract autoscaling options from %q instance template's metadata: can't get KubeEnv: %v", template.Name, err)
+			var parseError *KubeEnvParseError
+			if errors.As(err, &parseError) {
+				kubeEnvParseErrors[mig.GceRef()] = parseError
+			}
 			continue
 		}
+		m.checkTemplateChange(mig.GceRef(), template, kubeEnv)
 		options, err := extractAutoscalingOptionsFromKubeEnv(kubeEnv)
 		if err != nil {
 			klog.Warningf("Failed to extract autoscaling options from %q instance template's metadata: %v", template.Name, err)
@@ -368,6 +446,56 @@ func (m *gceManagerImpl) refreshAutoscalingOptions() {
 	}
 }
 
+// reportKubeEnvParseErrors records the MIGs whose kube-env can't be parsed, as
+// scale-up of such MIGs silently degrades otherwise.
+func (m *gceManagerImpl) reportKubeEnvParseErrors(parseErrors map[GceRef]*KubeEnvParseError) {
+	updateMigKubeEnvParseErrors(parseErrors)
+	if m.kubeEnvErrorReporter != nil {
+		m.kubeEnvErrorReporter.Report(parseErrors)
+	}
+}
+
+// checkTemplateChange compares the instance template of the MIG with the last seen
+// one and warns if the new version changes fields affecting scheduling, as accidental
+// template edits otherwise silently alter simulations. It's called on refresh and
+// before building template nodes, so changes are reported before they're simulated.
+func (m *gceManagerImpl) checkTemplateChange(migRef GceRef, template *gce.InstanceTemplate, kubeEnv KubeEnv) {
+	m.migTemplatesMutex.Lock()
+	defer m.migTemplatesMutex.Unlock()
+	if m.migTemplates == nil {
+		m.migTemplates = make(map[GceRef]migTemplateSnapshot)
+	}
+	previous, found := m.migTemplates[migRef]
+	m.migTemplates[migRef] = migTemplateSnapshot{template: template, kubeEnv: kubeEnv}
+	if !found || templateKey(previous.template) == templateKey(template) {
+		return
+	}
+	changes := DiffInstanceTemplates(previous.template, previous.kubeEnv, template, kubeEnv)
+	if len(changes) == 0 {
+		return
+	}
+	klog.Warningf("Instance template of MIG %s changed from %s to %s, affecting scheduling: %s", migRef, previous.template.Name, template.Name, formatTemplateChanges(changes))
+	if m.templateChangeReporter != nil {
+		m.templateChangeReporter.Report(migRef, previous.template.Name, template.Name, changes)
+	}
+}
+
+// pruneMigTemplates drops the last seen instance templates of MIGs which are no
+// longer registered.
+func (m *gceManagerImpl) pruneMigTemplates() {
+	m.migTemplatesMutex.Lock()
+	defer m.migTemplatesMutex.Unlock()
+	registered := make(map[GceRef]bool)
+	for _, mig := range m.migLister.GetMigs() {
+		registered[mig.GceRef()] = true
+	}
+	for migRef := range m.migTemplates {
+		if !registered[migRef] {
+			delete(m.migTemplates, migRef)
+		}
+	}
+}
+
 // Fetch explicitly configured MIGs. These MIGs should never be unregistered
 // during refreshes, even if they no longer exist in GCE.
 func (m *gceManagerImpl) fetchExplicitMigs(specs []string) error {
@@ -587,6 +715,7 @@ func (m *gceManagerImpl) GetMigOptions(mig Mig, defaults config.NodeGroupAutosca
 	if opt, ok := getDurationOption(options, migRef.Name, config.DefaultMaxNodeProvisionTimeKey); ok {
 		defaults.MaxNodeProvisionTime = opt
 	}
+	defaults.Metadata = config.MergeNodeGroupMetadata(defaults.Metadata, options)
 
 	return &defaults
 }
@@ -601,6 +730,12 @@ func (m *gceManagerImpl) GetMigTemplateNode(mig Mig) (*apiv1.Node, error) {
 	if err != nil {
 		return nil, err
 	}
+	if template.Properties != nil {
+		m.checkTemplateChange(mig.GceRef(), template, kubeEnv)
+	}
+	if m.kubeEnvOverrides != nil {
+		kubeEnv = kubeEnv.WithOverrides(m.kubeEnvOverrides.GetKubeEnvOverrides(mig.GceRef()))
+	}
 	machineType, err := m.migInfoProvider.GetMigMachineType(mig.GceRef())
 	if err != nil {
 		return nil, err
@@ -609,7 +744,50 @@ func (m *gceManagerImpl) GetMigTemplateNode(mig Mig) (*apiv1.Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	return m.templates.BuildNodeFromTemplate(mig, migOsInfo, template, kubeEnv, machineType.CPU, machineType.Memory, nil, m.reserved, m.localSSDDiskSizeProvider)
+	node, err := m.templates.BuildNodeFromTemplate(mig, migOsInfo, template, kubeEnv, machineType.CPU, machineType.Memory, nil, m.reserved, m.localSSDDiskSizeProvider)
+	if err != nil || !mig.GceRef().IsRegional() {
+		return node, err
+	}
+	zone, err := m.templateZone(mig)
+	if err != nil {
+		return nil, err
+	}
+	setZoneLabels(node.Labels, zone)
+	return node, nil
+}
+
+// templateZone returns the zone the next instance of a regional MIG is expected
+// to be created in. Regional MIGs distribute instances evenly across their zones,
+// so this is the zone with the fewest instances of the MIG.
+func (m *gceManagerImpl) templateZone(mig Mig) (string, error) {
+	zones, err := m.migInfoProvider.GetMigZones(mig.GceRef())
+	if err != nil {
+		return "", err
+	}
+	if len(zones) == 0 {
+		return "", fmt.Errorf("no zones found for regional mig %s", mig.GceRef())
+	}
+	instances, err := m.migInfoProvider.GetMigInstances(mig.GceRef())
+	if err != nil {
+		return "", err
+	}
+	instancesPerZone := make(map[string]int, len(zones))
+	for _, instance := range instances {
+		ref, err := GceRefFromProviderId(instance.Id)
+		if err != nil {
+			continue
+		}
+		instancesPerZone[ref.Zone]++
+	}
+	sortedZones := append([]string{}, zones...)
+	sort.Strings(sortedZones)
+	zone := sortedZones[0]
+	for _, z := range sortedZones[1:] {
+		if instancesPerZone[z] < instancesPerZone[zone] {
+			zone = z
+		}
+	}
+	return zone, nil
 }
 
 // parseMIGAutoDiscoverySpecs returns any provided NodeGroupAutoDiscoverySpecs
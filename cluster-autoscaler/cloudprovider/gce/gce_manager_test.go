@@ -31,6 +31,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	gce "google.golang.org/api/compute/v1"
+	apiv1 "k8s.io/api/core/v1"
 )
 
 const (
@@ -346,9 +347,12 @@ func newTestGceManager(t *testing.T, testServerURL string, regional bool) *gceMa
 		instanceTemplateNameCache:        map[GceRef]InstanceTemplateName{},
 		instanceTemplatesCache:           map[GceRef]*gce.InstanceTemplate{},
 		kubeEnvCache:                     map[GceRef]KubeEnv{},
+		templateKubeEnvCache:             map[InstanceTemplateKey]KubeEnv{},
 		migBaseNameCache:                 map[GceRef]string{},
 		migInstancesStateCache:           map[GceRef]map[cloudprovider.InstanceState]int64{},
 		listManagedInstancesResultsCache: map[GceRef]string{},
+		migAutoscalerCache:               map[GceRef]string{},
+		migZonesCache:                    map[GceRef][]string{},
 	}
 	migLister := NewMigLister(cache)
 	manager := &gceManagerImpl{
@@ -643,6 +647,30 @@ func TestGetAndSetMigSize(t *testing.T) {
 	mock.AssertExpectationsForObjects(t, server)
 }
 
+func TestMigAutoscalerConflict(t *testing.T) {
+	server := NewHttpServerMock()
+	defer server.Close()
+	g := newTestGceManager(t, server.URL, false)
+
+	extraPoolMig := setupTestExtraPool(g, true)
+	defaultPoolMig := setupTestDefaultPool(g, true)
+	g.cache.SetMigAutoscaler(extraPoolMig.GceRef(), "https://www.googleapis.com/compute/v1/projects/project1/zones/us-central1-b/autoscalers/extra-pool")
+	g.cache.SetMigAutoscaler(defaultPoolMig.GceRef(), "")
+
+	// resizing a mig managed by GCE autoscaler should fail without any API calls
+	err := g.SetMigSize(extraPoolMig, 4)
+	assert.Error(t, err)
+	err = g.CreateInstances(extraPoolMig, 1)
+	assert.Error(t, err)
+	mock.AssertExpectationsForObjects(t, server)
+
+	server.On("handle", fmt.Sprintf("/projects/project1/zones/us-central1-b/instanceGroupManagers/%s/resize", defaultPoolMigName)).Return(setMigSizeResponse).Once()
+	server.On("handle", "/projects/project1/zones/us-central1-b/operations/operation-1505739408819-5597646964339-eb839c88-28805931/wait").Return(setMigSizeOperationResponse).Once()
+	err = g.SetMigSize(defaultPoolMig, 4)
+	assert.NoError(t, err)
+	mock.AssertExpectationsForObjects(t, server)
+}
+
 func TestGetMigSizeListCallFails(t *testing.T) {
 	server := NewHttpServerMock()
 	defer server.Close()
@@ -962,6 +990,14 @@ func TestGetMigNodesComplex(t *testing.T) {
 			ErrorCodeQuotaExceeded,
 			"We run out of quota!; Ojojojoj!",
 		},
+		{
+			"none-creating-stockout_and_permissions_error",
+			buildManagedInstanceWithCurrentActionAndTwoErrorsResponsePart("europe-west1-b", "none-creating-stockout_and_permissions_error", "CREATING", "ZONE_RESOURCE_POOL_EXHAUSTED", "We run out of resources!", ErrorCodePermissions, "Permission denied!"),
+			cloudprovider.InstanceCreating,
+			cloudprovider.OutOfResourcesErrorClass,
+			ErrorCodeResourcePoolExhausted,
+			"We run out of resources!; Permission denied!",
+		},
 		{
 			"none-deleting-no_error",
 			buildManagedInstanceWithCurrentActionResponsePart("europe-west1-b", "none-deleting-no_error", "DELETING"),
@@ -1347,6 +1383,85 @@ func TestGetMigTemplateNode(t *testing.T) {
 	mock.AssertExpectationsForObjects(t, server)
 }
 
+func TestGetMigTemplateNodeReportsTemplateChange(t *testing.T) {
+	server := NewHttpServerMock()
+	defer server.Close()
+
+	server.On("handle", "/projects/project1/zones/us-central1-b/instanceGroupManagers/default-pool").Return(getInstanceGroupManagerResponse).Once()
+	server.On("handle", "/projects/project1/global/instanceTemplates/gke-cluster-1-default-pool").Return(instanceTemplate).Once()
+
+	regional := false
+	g := newTestGceManager(t, server.URL, regional)
+	reporter := &fakeTemplateChangeReporter{reported: map[GceRef][]TemplateChange{}}
+	g.templateChangeReporter = reporter
+
+	mig := &gceMig{
+		gceRef: GceRef{
+			Project: projectId,
+			Zone:    zoneB,
+			Name:    "default-pool",
+		},
+		gceManager: g,
+		minSize:    0,
+		maxSize:    1000,
+	}
+	// The previous template was seen during the last refresh.
+	previousTemplate, previousKubeEnv := buildTemplateWithKubeEnv(t, "gke-cluster-1-default-pool-v0", "n1-standard-2", "AUTOSCALER_ENV_VARS: node_labels=a=b\n")
+	g.migTemplates = map[GceRef]migTemplateSnapshot{mig.GceRef(): {template: previousTemplate, kubeEnv: previousKubeEnv}}
+
+	// The change is reported before the new template is used.
+	_, err := g.GetMigTemplateNode(mig)
+	assert.NoError(t, err)
+	assert.Contains(t, reporter.reported[mig.GceRef()], TemplateChange{Field: "machineType", Old: "n1-standard-2", New: "n1-standard-1"})
+	mock.AssertExpectationsForObjects(t, server)
+}
+
+const getRegionInstanceGroupManagerResponse = `{
+  "kind": "compute#instanceGroupManager",
+  "name": "default-pool",
+  "region": "https://www.googleapis.com/compute/v1/projects/project1/regions/us-central1",
+  "instanceTemplate": "https://www.googleapis.com/compute/v1/projects/project1/global/instanceTemplates/gke-cluster-1-default-pool",
+  "baseInstanceName": "gke-cluster-1-default-pool-f23aac-grp",
+  "distributionPolicy": {
+    "zones": [
+      {"zone": "https://www.googleapis.com/compute/v1/projects/project1/zones/us-central1-b"},
+      {"zone": "https://www.googleapis.com/compute/v1/projects/project1/zones/us-central1-c"}
+    ]
+  },
+  "targetSize": 1
+}`
+
+func TestGetMigTemplateNodeRegional(t *testing.T) {
+	server := NewHttpServerMock()
+	defer server.Close()
+
+	server.On("handle", "/projects/project1/regions/us-central1/instanceGroupManagers/default-pool").Return(getRegionInstanceGroupManagerResponse).Twice()
+	server.On("handle", "/projects/project1/global/instanceTemplates/gke-cluster-1-default-pool").Return(instanceTemplate).Once()
+	server.On("handle", "/projects/project1/regions/us-central1/instanceGroupManagers/default-pool/listManagedInstances").Return(buildOneRunningInstanceManagedInstancesResponse(zoneB, "default-pool")).Once()
+
+	regional := false
+	g := newTestGceManager(t, server.URL, regional)
+
+	mig := &gceMig{
+		gceRef: GceRef{
+			Project: projectId,
+			Zone:    region,
+			Name:    defaultPool,
+		},
+		gceManager: g,
+		minSize:    0,
+		maxSize:    1000,
+	}
+
+	node, err := g.GetMigTemplateNode(mig)
+	assert.NoError(t, err)
+	// the MIG already has an instance in us-central1-b, so the next one is expected in us-central1-c
+	assert.Equal(t, region, node.Labels[apiv1.LabelTopologyRegion])
+	assert.Equal(t, zoneC, node.Labels[apiv1.LabelTopologyZone])
+	assert.Equal(t, zoneC, node.Labels[gceCSITopologyKeyZone])
+	mock.AssertExpectationsForObjects(t, server)
+}
+
 func validateMigExists(t *testing.T, migs []Mig, zone string, name string, minSize int, maxSize int) {
 	ref := GceRef{
 		projectId,
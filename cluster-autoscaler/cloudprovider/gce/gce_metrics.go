@@ -34,11 +34,42 @@ var (
 			Help:      "Counter of GCE API requests for each verb and API resource.",
 		}, []string{"resource", "verb"},
 	)
+
+	migAutoscalerConflictsCount = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "gce_mig_autoscaler_conflicts_count",
+			Help:      "Number of MIGs which are also managed by a GCE autoscaler and won't be resized.",
+		},
+	)
+
+	migKubeEnvParseErrors = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "gce_mig_kube_env_parse_errors",
+			Help:      "Set to 1 for MIGs whose instance template kube-env can't be parsed, by reason of the failure.",
+		}, []string{"mig", "reason"},
+	)
 )
 
 // RegisterMetrics registers all GCE metrics.
 func RegisterMetrics() {
 	legacyregistry.MustRegister(requestCounter)
+	legacyregistry.MustRegister(migAutoscalerConflictsCount)
+	legacyregistry.MustRegister(migKubeEnvParseErrors)
+}
+
+// updateMigAutoscalerConflictsCount records the number of MIGs managed by a GCE autoscaler.
+func updateMigAutoscalerConflictsCount(count int) {
+	migAutoscalerConflictsCount.Set(float64(count))
+}
+
+// updateMigKubeEnvParseErrors records the MIGs whose kube-env can't be parsed.
+func updateMigKubeEnvParseErrors(parseErrors map[GceRef]*KubeEnvParseError) {
+	migKubeEnvParseErrors.Reset()
+	for migRef, parseError := range parseErrors {
+		migKubeEnvParseErrors.WithLabelValues(migRef.String(), parseError.Reason).Set(1)
+	}
 }
 
 // registerRequest registers request to GCE API.
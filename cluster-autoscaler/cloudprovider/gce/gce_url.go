@@ -27,23 +27,29 @@ const (
 	anyHttpsUrlPattern = "https://.*/"
 )
 
+var regionRegexp = regexp.MustCompile(`^[a-z]+-[a-z]+[0-9]+$`)
+
 // ParseMigUrl expects url in format:
 // https://.*/projects/<project-id>/zones/<zone>/instanceGroups/<name>
+// or, for regional MIGs, in format:
+// https://.*/projects/<project-id>/regions/<region>/instanceGroups/<name>
+// in which case the region is returned as zone.
 func ParseMigUrl(url string) (project string, zone string, name string, err error) {
-	return parseGceUrl(anyHttpsUrlPattern, url, "instanceGroups")
+	return parseGceLocationUrl(anyHttpsUrlPattern, url, "instanceGroups")
 }
 
 // ParseIgmUrl expects url in format:
 // https://.*/<project-id>/zones/<zone>/instanceGroupManagers/<name>
+// or the regional equivalent, see ParseMigUrl.
 func ParseIgmUrl(url string) (project string, zone string, name string, err error) {
-	return parseGceUrl(anyHttpsUrlPattern, url, "instanceGroupManagers")
+	return parseGceLocationUrl(anyHttpsUrlPattern, url, "instanceGroupManagers")
 }
 
 // ParseIgmUrlRef expects url in format:
 // projects/<project-id>/zones/<zone>/instanceGroupManagers/<name>
-// and returns a GceRef struct for it.
+// or the regional equivalent, and returns a GceRef struct for it.
 func ParseIgmUrlRef(url string) (GceRef, error) {
-	project, zone, name, err := parseGceUrl("", url, "instanceGroupManagers")
+	project, zone, name, err := parseGceLocationUrl("", url, "instanceGroupManagers")
 	if err != nil {
 		return GceRef{}, err
 	}
@@ -84,12 +90,15 @@ func GenerateInstanceUrl(domainUrl string, ref GceRef) string {
 	return fmt.Sprintf(instanceUrlTemplate, ref.Project, ref.Zone, ref.Name)
 }
 
-// GenerateMigUrl generates url for instance.
+// GenerateMigUrl generates url for MIG.
 func GenerateMigUrl(domainUrl string, ref GceRef) string {
 	if domainUrl == "" {
 		domainUrl = defaultDomainUrl
 	}
 	migUrlTemplate := domainUrl + projectsSubstring + "%s/zones/%s/instanceGroups/%s"
+	if ref.IsRegional() {
+		migUrlTemplate = domainUrl + projectsSubstring + "%s/regions/%s/instanceGroups/%s"
+	}
 	return fmt.Sprintf(migUrlTemplate, ref.Project, ref.Zone, ref.Name)
 }
 
@@ -98,6 +107,12 @@ func IsInstanceTemplateRegional(templateUrl string) (bool, error) {
 	return regexp.MatchString("(/projects/.*[A-Za-z0-9]+.*/regions/)", templateUrl)
 }
 
+// isRegion determines whether the location is a region, e.g. us-central1, rather
+// than a zone, e.g. us-central1-a.
+func isRegion(location string) bool {
+	return regionRegexp.MatchString(location)
+}
+
 func parseGceUrl(prefix, url, expectedResource string) (project string, zone string, name string, err error) {
 	reg := regexp.MustCompile(fmt.Sprintf("%sprojects/.*/zones/.*/%s/.*", prefix, expectedResource))
 	errMsg := fmt.Errorf("wrong url: expected format %sprojects/<project-id>/zones/<zone>/%s/<name>, got %s", prefix, expectedResource, url)
@@ -111,3 +126,17 @@ func parseGceUrl(prefix, url, expectedResource string) (project string, zone str
 	name = subMatches[3]
 	return project, zone, name, nil
 }
+
+// parseGceLocationUrl works like parseGceUrl, but also accepts urls of regional
+// resources, in which case the region is returned as zone.
+func parseGceLocationUrl(prefix, url, expectedResource string) (project string, zone string, name string, err error) {
+	project, zone, name, err = parseGceUrl(prefix, url, expectedResource)
+	if err == nil {
+		return project, zone, name, nil
+	}
+	subMatches := regexp.MustCompile(fmt.Sprintf("%sprojects/(.*)/regions/(.*)/%s/(.*)", prefix, expectedResource)).FindStringSubmatch(url)
+	if subMatches == nil || !isRegion(subMatches[2]) {
+		return "", "", "", err
+	}
+	return subMatches[1], subMatches[2], subMatches[3], nil
+}
@@ -83,6 +83,15 @@ func TestGenerateMigUrl(t *testing.T) {
 			},
 			want: "https://www.googleapis.com/compute-custom/v2/projects/proj1/zones/us-central1-a/instanceGroups/name1",
 		},
+		{
+			name: "regional",
+			ref: GceRef{
+				Project: "proj1",
+				Name:    "name1",
+				Zone:    "us-central1",
+			},
+			want: "https://www.googleapis.com/compute/v1/projects/proj1/regions/us-central1/instanceGroups/name1",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -287,6 +296,18 @@ func TestParseMigUrl(t *testing.T) {
 			wantName:    "name1",
 			wantZone:    "us-central1-a",
 		},
+		{
+			name:        "regional",
+			url:         "https://www.googleapis.com/compute/v1/projects/proj1/regions/us-central1/instanceGroups/name1",
+			wantProject: "proj1",
+			wantName:    "name1",
+			wantZone:    "us-central1",
+		},
+		{
+			name:    "zone as region",
+			url:     "https://www.googleapis.com/compute/v1/projects/proj1/regions/us-central1-a/instanceGroups/name1",
+			wantErr: fmt.Errorf("wrong url: expected format https://.*/projects/<project-id>/zones/<zone>/instanceGroups/<name>, got https://www.googleapis.com/compute/v1/projects/proj1/regions/us-central1-a/instanceGroups/name1"),
+		},
 		{
 			name:    "incorrect domain",
 			url:     "https://www.googleapis.com/compute_test/v1/projects2/proj1/zones/us-central1-a/instanceGroups/name1",
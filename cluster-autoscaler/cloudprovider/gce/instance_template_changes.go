@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	gce "google.golang.org/api/compute/v1"
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube_client "k8s.io/client-go/kubernetes"
+	kube_record "k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// InstanceTemplateChangedReason is the reason of events emitted for MIGs whose new
+// instance template changes fields affecting scheduling.
+const InstanceTemplateChangedReason = "InstanceTemplateChanged"
+
+// TemplateChange is a change of a field affecting scheduling between two versions
+// of the instance template of a MIG.
+type TemplateChange struct {
+	// Field is the name of the changed field.
+	Field string
+	// Old is the value of the field in the previous template.
+	Old string
+	// New is the value of the field in the new template.
+	New string
+}
+
+// String returns a human readable description of the change.
+func (c TemplateChange) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.Field, c.Old, c.New)
+}
+
+// DiffInstanceTemplates returns the changes between two versions of an instance
+// template in the fields used to build template nodes for scheduling simulations:
+// machine type, accelerators, node labels, node taints and kube-env knobs
+// affecting node allocatable. Fields which can't be extracted from either
+// version aren't compared.
+func DiffInstanceTemplates(oldTemplate *gce.InstanceTemplate, oldKubeEnv KubeEnv, newTemplate *gce.InstanceTemplate, newKubeEnv KubeEnv) []TemplateChange {
+	var changes []TemplateChange
+	compare := func(field string, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, TemplateChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+	compare("machineType", templateMachineType(oldTemplate), templateMachineType(newTemplate))
+	compare("guestAccelerators", templateAccelerators(oldTemplate), templateAccelerators(newTemplate))
+
+	kubeEnvFields := []struct {
+		name  string
+		value func(KubeEnv) (string, error)
+	}{
+		{"labels", func(ke KubeEnv) (string, error) {
+			labels, err := ke.NodeLabels()
+			return formatKeyValues(labels), err
+		}},
+		{"taints", func(ke KubeEnv) (string, error) {
+			taints, err := ke.NodeTaints()
+			return formatTaints(taints), err
+		}},
+		{"kubeReserved", func(ke KubeEnv) (string, error) {
+			// Missing kube-reserved is not an error from the diff perspective.
+			kubeReserved, _ := ke.KubeReserved()
+			return kubeReserved, nil
+		}},
+		{"evictionHard", func(ke KubeEnv) (string, error) {
+			evictionHard, err := ke.EvictionHard()
+			return formatKeyValues(evictionHard), err
+		}},
+	}
+	for _, field := range kubeEnvFields {
+		oldValue, oldErr := field.value(oldKubeEnv)
+		newValue, newErr := field.value(newKubeEnv)
+		if oldErr != nil || newErr != nil {
+			continue
+		}
+		compare(field.name, oldValue, newValue)
+	}
+	return changes
+}
+
+func templateMachineType(template *gce.InstanceTemplate) string {
+	if template.Properties == nil {
+		return ""
+	}
+	return template.Properties.MachineType
+}
+
+func templateAccelerators(template *gce.InstanceTemplate) string {
+	if template.Properties == nil {
+		return ""
+	}
+	accelerators := make([]string, 0, len(template.Properties.GuestAccelerators))
+	for _, accelerator := range template.Properties.GuestAccelerators {
+		accelerators = append(accelerators, fmt.Sprintf("%s=%d", accelerator.AcceleratorType, accelerator.AcceleratorCount))
+	}
+	sort.Strings(accelerators)
+	return strings.Join(accelerators, ",")
+}
+
+func formatKeyValues(values map[string]string) string {
+	pairs := make([]string, 0, len(values))
+	for key, value := range values {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func formatTaints(taints []apiv1.Taint) string {
+	formatted := make([]string, 0, len(taints))
+	for _, taint := range taints {
+		formatted = append(formatted, taint.ToString())
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ",")
+}
+
+// InstanceTemplateChangeReporter reports MIGs whose new instance template changes
+// fields affecting scheduling.
+type InstanceTemplateChangeReporter interface {
+	// Report reports the changes between the previous and the new instance template of the MIG.
+	Report(migRef GceRef, oldTemplateName, newTemplateName string, changes []TemplateChange)
+}
+
+// statusConfigMapTemplateChangeReporter emits warning events for MIGs whose
+// instance template changed, attached to the cluster-autoscaler status ConfigMap.
+type statusConfigMapTemplateChangeReporter struct {
+	kubeClient    kube_client.Interface
+	eventRecorder kube_record.EventRecorder
+	namespace     string
+	configMapName string
+}
+
+// NewStatusConfigMapTemplateChangeReporter creates an InstanceTemplateChangeReporter
+// emitting events on the status ConfigMap of the given name in the given namespace.
+func NewStatusConfigMapTemplateChangeReporter(kubeClient kube_client.Interface, eventRecorder kube_record.EventRecorder, namespace, configMapName string) InstanceTemplateChangeReporter {
+	return &statusConfigMapTemplateChangeReporter{
+		kubeClient:    kubeClient,
+		eventRecorder: eventRecorder,
+		namespace:     namespace,
+		configMapName: configMapName,
+	}
+}
+
+// Report emits a warning event listing the changes of the instance template of the MIG.
+func (r *statusConfigMapTemplateChangeReporter) Report(migRef GceRef, oldTemplateName, newTemplateName string, changes []TemplateChange) {
+	configMap, err := r.kubeClient.CoreV1().ConfigMaps(r.namespace).Get(context.TODO(), r.configMapName, metav1.GetOptions{})
+	if err != nil {
+		if !kube_errors.IsNotFound(err) {
+			klog.Warningf("Failed to get status ConfigMap %s/%s: %v", r.namespace, r.configMapName, err)
+		}
+		return
+	}
+	r.eventRecorder.Eventf(configMap, apiv1.EventTypeWarning, InstanceTemplateChangedReason,
+		"Instance template of MIG %s changed from %s to %s, affecting scheduling: %s", migRef, oldTemplateName, newTemplateName, formatTemplateChanges(changes))
+}
+
+func formatTemplateChanges(changes []TemplateChange) string {
+	formatted := make([]string, 0, len(changes))
+	for _, change := range changes {
+		formatted = append(formatted, change.String())
+	}
+	return strings.Join(formatted, "; ")
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gce "google.golang.org/api/compute/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+)
+
+func buildTemplateWithKubeEnv(t *testing.T, name, machineType, kubeEnvValue string) (*gce.InstanceTemplate, KubeEnv) {
+	template := &gce.InstanceTemplate{
+		Name: name,
+		Properties: &gce.InstanceProperties{
+			MachineType: machineType,
+			Metadata: &gce.Metadata{
+				Fingerprint: name,
+				Items:       []*gce.MetadataItems{{Key: kubeEnvKey, Value: &kubeEnvValue}},
+			},
+		},
+	}
+	kubeEnv, err := ExtractKubeEnv(template)
+	require.NoError(t, err)
+	return template, kubeEnv
+}
+
+func TestDiffInstanceTemplates(t *testing.T) {
+	const baseKubeEnv = "AUTOSCALER_ENV_VARS: node_labels=a=b,c=d;node_taints=e=f:NoSchedule;kube_reserved=cpu=100m;evictionHard=memory.available=100Mi\n"
+	testCases := []struct {
+		name           string
+		newMachineType string
+		newKubeEnv     string
+		newGPUs        int64
+		wantChanges    []TemplateChange
+	}{
+		{
+			name:           "no changes",
+			newMachineType: "n1-standard-1",
+			newKubeEnv:     baseKubeEnv,
+		},
+		{
+			name:           "labels reordered",
+			newMachineType: "n1-standard-1",
+			newKubeEnv:     "AUTOSCALER_ENV_VARS: node_labels=c=d,a=b;node_taints=e=f:NoSchedule;kube_reserved=cpu=100m;evictionHard=memory.available=100Mi\n",
+		},
+		{
+			name:           "machine type changed",
+			newMachineType: "n1-standard-2",
+			newKubeEnv:     baseKubeEnv,
+			wantChanges:    []TemplateChange{{Field: "machineType", Old: "n1-standard-1", New: "n1-standard-2"}},
+		},
+		{
+			name:           "accelerators added",
+			newMachineType: "n1-standard-1",
+			newKubeEnv:     baseKubeEnv,
+			newGPUs:        2,
+			wantChanges:    []TemplateChange{{Field: "guestAccelerators", Old: "", New: "nvidia-tesla-t4=2"}},
+		},
+		{
+			name:           "kube-env changed",
+			newMachineType: "n1-standard-1",
+			newKubeEnv:     "AUTOSCALER_ENV_VARS: node_labels=a=b;node_taints=e=f:NoExecute;kube_reserved=cpu=200m;evictionHard=memory.available=200Mi\n",
+			wantChanges: []TemplateChange{
+				{Field: "labels", Old: "a=b,c=d", New: "a=b"},
+				{Field: "taints", Old: "e=f:NoSchedule", New: "e=f:NoExecute"},
+				{Field: "kubeReserved", Old: "cpu=100m", New: "cpu=200m"},
+				{Field: "evictionHard", Old: "memory.available=100Mi", New: "memory.available=200Mi"},
+			},
+		},
+		{
+			name:           "unparsable labels are not compared",
+			newMachineType: "n1-standard-1",
+			newKubeEnv:     "AUTOSCALER_ENV_VARS: node_labels=a;node_taints=e=f:NoSchedule;kube_reserved=cpu=100m;evictionHard=memory.available=100Mi\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			oldTemplate, oldKubeEnv := buildTemplateWithKubeEnv(t, "old", "n1-standard-1", baseKubeEnv)
+			newTemplate, newKubeEnv := buildTemplateWithKubeEnv(t, "new", tc.newMachineType, tc.newKubeEnv)
+			if tc.newGPUs > 0 {
+				newTemplate.Properties.GuestAccelerators = []*gce.AcceleratorConfig{{AcceleratorType: "nvidia-tesla-t4", AcceleratorCount: tc.newGPUs}}
+			}
+			assert.Equal(t, tc.wantChanges, DiffInstanceTemplates(oldTemplate, oldKubeEnv, newTemplate, newKubeEnv))
+		})
+	}
+}
+
+type fakeTemplateChangeReporter struct {
+	reported map[GceRef][]TemplateChange
+}
+
+func (r *fakeTemplateChangeReporter) Report(migRef GceRef, _, _ string, changes []TemplateChange) {
+	r.reported[migRef] = changes
+}
+
+func TestCheckTemplateChange(t *testing.T) {
+	reporter := &fakeTemplateChangeReporter{reported: map[GceRef][]TemplateChange{}}
+	m := &gceManagerImpl{templateChangeReporter: reporter}
+	mig := GceRef{Project: "project", Zone: "us-central1-b", Name: "mig"}
+	const kubeEnvValue = "AUTOSCALER_ENV_VARS: node_labels=a=b\n"
+
+	// The first seen template is not reported.
+	template, kubeEnv := buildTemplateWithKubeEnv(t, "v1", "n1-standard-1", kubeEnvValue)
+	m.checkTemplateChange(mig, template, kubeEnv)
+	assert.Empty(t, reporter.reported)
+
+	// New template version not affecting scheduling is not reported.
+	template, kubeEnv = buildTemplateWithKubeEnv(t, "v2", "n1-standard-1", kubeEnvValue)
+	m.checkTemplateChange(mig, template, kubeEnv)
+	assert.Empty(t, reporter.reported)
+
+	template, kubeEnv = buildTemplateWithKubeEnv(t, "v3", "n1-standard-2", kubeEnvValue)
+	m.checkTemplateChange(mig, template, kubeEnv)
+	assert.Equal(t, map[GceRef][]TemplateChange{
+		mig: {{Field: "machineType", Old: "n1-standard-1", New: "n1-standard-2"}},
+	}, reporter.reported)
+
+	// The template is compared with the last seen version only.
+	delete(reporter.reported, mig)
+	m.checkTemplateChange(mig, template, kubeEnv)
+	assert.Empty(t, reporter.reported)
+
+	m.migLister = NewMigLister(NewGceCache())
+	m.pruneMigTemplates()
+	assert.Empty(t, m.migTemplates)
+}
+
+func TestStatusConfigMapTemplateChangeReporter(t *testing.T) {
+	const namespace, name = "kube-system", "cluster-autoscaler-status"
+	client := fake.NewSimpleClientset(&apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	})
+	recorder := kube_record.NewFakeRecorder(10)
+	reporter := NewStatusConfigMapTemplateChangeReporter(client, recorder, namespace, name)
+
+	mig := GceRef{Project: "project", Zone: "us-central1-b", Name: "mig"}
+	reporter.Report(mig, "v1", "v2", []TemplateChange{{Field: "machineType", Old: "n1-standard-1", New: "n1-standard-2"}})
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, `Warning InstanceTemplateChanged Instance template of MIG project/us-central1-b/mig changed from v1 to v2, affecting scheduling: machineType: "n1-standard-1" -> "n1-standard-2"`, <-recorder.Events)
+}
@@ -19,19 +19,53 @@ package gce
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 
 	gce "google.golang.org/api/compute/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
 )
 
 const (
 	kubeEnvKey = "kube-env"
+
+	// KubeEnvNoMetadata is the reason of parse errors of instance templates without metadata.
+	KubeEnvNoMetadata = "NoMetadata"
+	// KubeEnvNoValue is the reason of parse errors of instance templates with empty kube-env.
+	KubeEnvNoValue = "NoValue"
+	// KubeEnvMalformed is the reason of parse errors of malformed kube-env.
+	KubeEnvMalformed = "Malformed"
 )
 
+// KubeEnvParseError is returned if kube-env of an instance template can't be parsed.
+type KubeEnvParseError struct {
+	// TemplateName is the name of the instance template.
+	TemplateName string
+	// Reason is a short, machine readable reason of the error.
+	Reason string
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *KubeEnvParseError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *KubeEnvParseError) Unwrap() error {
+	return e.Err
+}
+
 // KubeEnv stores kube-env information from InstanceTemplate
 type KubeEnv struct {
-	templateName string
-	env          map[string]string
+	templateName        string
+	templateFingerprint string
+	env                 map[string]string
+	overrides           *KubeEnvOverrides
 }
 
 // ExtractKubeEnv extracts kube-env from InstanceTemplate
@@ -40,17 +74,27 @@ func ExtractKubeEnv(template *gce.InstanceTemplate) (KubeEnv, error) {
 		return KubeEnv{}, errors.New("instance template is nil")
 	}
 	if template.Properties == nil || template.Properties.Metadata == nil {
-		return KubeEnv{}, fmt.Errorf("instance template %s has no metadata", template.Name)
+		return KubeEnv{}, &KubeEnvParseError{TemplateName: template.Name, Reason: KubeEnvNoMetadata, Err: fmt.Errorf("instance template %s has no metadata", template.Name)}
 	}
 	for _, item := range template.Properties.Metadata.Items {
 		if item.Key == kubeEnvKey {
 			if item.Value == nil {
-				return KubeEnv{}, fmt.Errorf("no kube-env content in metadata")
+				return KubeEnv{}, &KubeEnvParseError{TemplateName: template.Name, Reason: KubeEnvNoValue, Err: errors.New("no kube-env content in metadata")}
+			}
+			kubeEnv, err := ParseKubeEnv(template.Name, *item.Value)
+			if err != nil {
+				return KubeEnv{}, err
 			}
-			return ParseKubeEnv(template.Name, *item.Value)
+			kubeEnv.templateFingerprint = template.Properties.Metadata.Fingerprint
+			return kubeEnv, nil
 		}
 	}
-	return KubeEnv{templateName: template.Name}, nil
+	return KubeEnv{templateName: template.Name, templateFingerprint: template.Properties.Metadata.Fingerprint}, nil
+}
+
+// templateKey returns the key of the instance template the KubeEnv was extracted from.
+func (ke KubeEnv) templateKey() InstanceTemplateKey {
+	return InstanceTemplateKey{Name: ke.templateName, Fingerprint: ke.templateFingerprint}
 }
 
 // ParseKubeEnv parses kube-env from its string representation
@@ -58,7 +102,7 @@ func ParseKubeEnv(templateName, kubeEnvValue string) (KubeEnv, error) {
 	env := make(map[string]string)
 	err := yaml.Unmarshal([]byte(kubeEnvValue), &env)
 	if err != nil {
-		return KubeEnv{}, fmt.Errorf("error unmarshalling kubeEnv: %v", err)
+		return KubeEnv{}, &KubeEnvParseError{TemplateName: templateName, Reason: KubeEnvMalformed, Err: fmt.Errorf("error unmarshalling kubeEnv: %v", err)}
 	}
 	return KubeEnv{templateName: templateName, env: env}, nil
 }
@@ -71,3 +115,148 @@ func (ke KubeEnv) Var(name string) (string, bool) {
 	val, found := ke.env[name]
 	return val, found
 }
+
+// WithOverrides returns the KubeEnv with its labels, taints and kube-reserved
+// overridden by the given overrides, if any.
+func (ke KubeEnv) WithOverrides(overrides *KubeEnvOverrides) KubeEnv {
+	ke.overrides = overrides
+	return ke
+}
+
+// KubeletArgs returns the kubelet flags set by KUBELET_TEST_ARGS, keyed by flag
+// name without the leading dashes. Flags without a value are set to "true".
+func (ke KubeEnv) KubeletArgs() map[string]string {
+	result := make(map[string]string)
+	kubeletArgs, _ := ke.Var("KUBELET_TEST_ARGS")
+	for _, arg := range strings.Fields(kubeletArgs) {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name, value, found := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !found {
+			value = "true"
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// NodeLabels returns the labels of nodes created from the template.
+func (ke KubeEnv) NodeLabels() (map[string]string, error) {
+	// In v1.10+, labels are only exposed for the autoscaler via AUTOSCALER_ENV_VARS
+	// see kubernetes/kubernetes#61119. We try AUTOSCALER_ENV_VARS first, then
+	// fall back to the old way.
+	labels, found, err := extractAutoscalerVarFromKubeEnv(ke, "node_labels")
+	if err != nil {
+		klog.Errorf("error while trying to extract node_labels from AUTOSCALER_ENV_VARS: %v", err)
+	}
+	if !found {
+		labels, _ = ke.Var("NODE_LABELS")
+	}
+	result, err := parseKeyValueListToMap(labels)
+	if err != nil || ke.overrides == nil {
+		return result, err
+	}
+	for key, value := range ke.overrides.Labels {
+		result[key] = value
+	}
+	return result, nil
+}
+
+// NodeTaints returns the taints of nodes created from the template.
+func (ke KubeEnv) NodeTaints() ([]apiv1.Taint, error) {
+	// In v1.10+, taints are only exposed for the autoscaler via AUTOSCALER_ENV_VARS
+	// see kubernetes/kubernetes#61119. We try AUTOSCALER_ENV_VARS first, then
+	// fall back to the old way.
+	taints, found, err := extractAutoscalerVarFromKubeEnv(ke, "node_taints")
+	if err != nil {
+		klog.Errorf("error while trying to extract node_taints from AUTOSCALER_ENV_VARS: %v", err)
+	}
+	if !found {
+		taints, _ = ke.Var("NODE_TAINTS")
+	}
+	taintMap, err := parseKeyValueListToMap(taints)
+	if err != nil {
+		return nil, err
+	}
+	result, err := buildTaints(taintMap)
+	if err != nil || ke.overrides == nil {
+		return result, err
+	}
+	for _, override := range ke.overrides.Taints {
+		result = slices.DeleteFunc(result, func(taint apiv1.Taint) bool {
+			return taint.Key == override.Key && taint.Effect == override.Effect
+		})
+		result = append(result, override)
+	}
+	return result, nil
+}
+
+// KubeReserved returns the unparsed kube-reserved resources of nodes created
+// from the template.
+func (ke KubeEnv) KubeReserved() (string, error) {
+	if ke.overrides != nil && ke.overrides.KubeReserved != "" {
+		return ke.overrides.KubeReserved, nil
+	}
+	// In v1.10+, kube-reserved is only exposed for the autoscaler via AUTOSCALER_ENV_VARS
+	// see kubernetes/kubernetes#61119. We try AUTOSCALER_ENV_VARS first, then
+	// fall back to the old way.
+	kubeReserved, found, err := extractAutoscalerVarFromKubeEnv(ke, "kube_reserved")
+	if err != nil {
+		klog.Errorf("error while trying to extract kube_reserved from AUTOSCALER_ENV_VARS: %v", err)
+	}
+	if !found {
+		if kubeReserved, found := ke.KubeletArgs()["kube-reserved"]; found {
+			return kubeReserved, nil
+		}
+		kubeletArgs, _ := ke.Var("KUBELET_TEST_ARGS")
+		return "", fmt.Errorf("kube-reserved not in kubelet args in kube-env: %q", kubeletArgs)
+	}
+	return kubeReserved, nil
+}
+
+// EvictionHard returns the unparsed hard eviction thresholds of nodes created
+// from the template, keyed by signal.
+func (ke KubeEnv) EvictionHard() (map[string]string, error) {
+	evictionHardAsString, found, err := extractAutoscalerVarFromKubeEnv(ke, "evictionHard")
+	if err != nil {
+		klog.Warningf("error while obtaining eviction-hard from AUTOSCALER_ENV_VARS; %v", err)
+		return nil, err
+	}
+
+	if !found {
+		klog.Warning("no evictionHard defined in AUTOSCALER_ENV_VARS;")
+		return make(map[string]string), nil
+	}
+
+	return parseKeyValueListToMap(evictionHardAsString)
+}
+
+// BootDiskSizeGb returns the boot disk size in GiB of nodes created from the
+// template, if declared by AUTOSCALER_ENV_VARS.
+func (ke KubeEnv) BootDiskSizeGb() (int64, bool) {
+	return ke.autoscalerInt64Var("boot_disk_size_gb")
+}
+
+// LocalSSDCount returns the number of local SSDs attached to nodes created from
+// the template, if declared by AUTOSCALER_ENV_VARS.
+func (ke KubeEnv) LocalSSDCount() (int64, bool) {
+	return ke.autoscalerInt64Var("local_ssd_count")
+}
+
+func (ke KubeEnv) autoscalerInt64Var(name string) (int64, bool) {
+	v, found, err := extractAutoscalerVarFromKubeEnv(ke, name)
+	if err != nil {
+		klog.Warningf("cannot extract %s from kube-env: %v", name, err)
+		return 0, false
+	}
+	if !found {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		klog.Warningf("cannot parse %s value %q from kube-env", name, v)
+		return 0, false
+	}
+	return n, true
+}
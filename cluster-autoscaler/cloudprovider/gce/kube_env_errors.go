@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube_client "k8s.io/client-go/kubernetes"
+	kube_record "k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// KubeEnvParseErrorsKey is the key of the status ConfigMap data holding the MIGs
+	// whose kube-env can't be parsed.
+	KubeEnvParseErrorsKey = "gceKubeEnvParseErrors"
+	// KubeEnvParseFailedReason is the reason of events emitted for MIGs whose
+	// kube-env can't be parsed.
+	KubeEnvParseFailedReason = "KubeEnvParseFailed"
+)
+
+// KubeEnvErrorReporter reports MIGs whose kube-env can't be parsed.
+type KubeEnvErrorReporter interface {
+	// Report reports all MIGs whose kube-env currently can't be parsed.
+	Report(parseErrors map[GceRef]*KubeEnvParseError)
+}
+
+// statusConfigMapKubeEnvErrorReporter emits events for MIGs whose kube-env can't be
+// parsed and lists them in the cluster-autoscaler status ConfigMap.
+type statusConfigMapKubeEnvErrorReporter struct {
+	kubeClient    kube_client.Interface
+	eventRecorder kube_record.EventRecorder
+	namespace     string
+	configMapName string
+	// reported holds the error messages of the last reported MIGs.
+	reported map[string]string
+}
+
+// NewStatusConfigMapKubeEnvErrorReporter creates a KubeEnvErrorReporter writing to
+// the status ConfigMap of the given name in the given namespace.
+func NewStatusConfigMapKubeEnvErrorReporter(kubeClient kube_client.Interface, eventRecorder kube_record.EventRecorder, namespace, configMapName string) KubeEnvErrorReporter {
+	return &statusConfigMapKubeEnvErrorReporter{
+		kubeClient:    kubeClient,
+		eventRecorder: eventRecorder,
+		namespace:     namespace,
+		configMapName: configMapName,
+		reported:      map[string]string{},
+	}
+}
+
+// Report emits a warning event for each MIG which started failing or fails with
+// a different error since the last report and updates the status ConfigMap if
+// the failing MIGs changed.
+func (r *statusConfigMapKubeEnvErrorReporter) Report(parseErrors map[GceRef]*KubeEnvParseError) {
+	messages := make(map[string]string, len(parseErrors))
+	for migRef, parseError := range parseErrors {
+		messages[migRef.String()] = fmt.Sprintf("%s: %v", parseError.Reason, parseError)
+	}
+	if reflect.DeepEqual(messages, r.reported) {
+		return
+	}
+
+	configMap, err := r.kubeClient.CoreV1().ConfigMaps(r.namespace).Get(context.TODO(), r.configMapName, metav1.GetOptions{})
+	if err != nil {
+		if !kube_errors.IsNotFound(err) {
+			klog.Warningf("Failed to get status ConfigMap %s/%s: %v", r.namespace, r.configMapName, err)
+		}
+		return
+	}
+	for mig, message := range messages {
+		if r.reported[mig] != message {
+			r.eventRecorder.Eventf(configMap, apiv1.EventTypeWarning, KubeEnvParseFailedReason, "Failed to parse kube-env of MIG %s: %s", mig, message)
+		}
+	}
+
+	if len(messages) == 0 {
+		delete(configMap.Data, KubeEnvParseErrorsKey)
+	} else {
+		data, err := yaml.Marshal(messages)
+		if err != nil {
+			klog.Warningf("Failed to marshal kube-env parse errors: %v", err)
+			return
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		configMap.Data[KubeEnvParseErrorsKey] = string(data)
+	}
+	if _, err := r.kubeClient.CoreV1().ConfigMaps(r.namespace).Update(context.TODO(), configMap, metav1.UpdateOptions{}); err != nil {
+		klog.Warningf("Failed to update status ConfigMap %s/%s: %v", r.namespace, r.configMapName, err)
+		return
+	}
+	r.reported = messages
+}
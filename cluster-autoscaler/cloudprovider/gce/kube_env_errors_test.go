@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+)
+
+func TestStatusConfigMapKubeEnvErrorReporter(t *testing.T) {
+	const namespace, name = "kube-system", "cluster-autoscaler-status"
+	client := fake.NewSimpleClientset(&apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string]string{"status": "status"},
+	})
+	recorder := kube_record.NewFakeRecorder(10)
+	reporter := NewStatusConfigMapKubeEnvErrorReporter(client, recorder, namespace, name)
+	getData := func() map[string]string {
+		configMap, err := client.CoreV1().ConfigMaps(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return configMap.Data
+	}
+
+	mig := GceRef{Project: "project", Zone: "us-central1-b", Name: "mig"}
+	parseErrors := map[GceRef]*KubeEnvParseError{
+		mig: {TemplateName: "template", Reason: KubeEnvMalformed, Err: errors.New("error unmarshalling kubeEnv")},
+	}
+	reporter.Report(parseErrors)
+	assert.Equal(t, map[string]string{
+		"status":              "status",
+		KubeEnvParseErrorsKey: "project/us-central1-b/mig: 'Malformed: error unmarshalling kubeEnv'\n",
+	}, getData())
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, KubeEnvParseFailedReason)
+
+	// Reporting the same errors again neither emits events nor updates the ConfigMap.
+	reporter.Report(parseErrors)
+	assert.Len(t, recorder.Events, 0)
+
+	reporter.Report(map[GceRef]*KubeEnvParseError{})
+	assert.Equal(t, map[string]string{"status": "status"}, getData())
+	assert.Len(t, recorder.Events, 0)
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// KubeEnvOverrides override or augment the values extracted from the kube-env
+// of a MIG's instance template when building its template nodes.
+type KubeEnvOverrides struct {
+	// Labels are added to the labels from kube-env, replacing labels with the same key.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Taints are added to the taints from kube-env, replacing taints with the same key and effect.
+	Taints []apiv1.Taint `json:"taints,omitempty"`
+	// KubeReserved replaces kube-reserved from kube-env, e.g. "cpu=100m,memory=1Gi".
+	KubeReserved string `json:"kubeReserved,omitempty"`
+}
+
+// KubeEnvOverridesProvider provides the kube-env overrides of MIGs.
+type KubeEnvOverridesProvider interface {
+	// GetKubeEnvOverrides returns the kube-env overrides of the MIG, or nil if there are none.
+	GetKubeEnvOverrides(migRef GceRef) *KubeEnvOverrides
+}
+
+// ParseKubeEnvOverrides parses the data of a kube-env overrides ConfigMap, whose keys
+// are MIG names and values are YAML encoded KubeEnvOverrides. Malformed values are
+// skipped.
+func ParseKubeEnvOverrides(data map[string]string) map[string]*KubeEnvOverrides {
+	result := make(map[string]*KubeEnvOverrides, len(data))
+	for migName, value := range data {
+		overrides := &KubeEnvOverrides{}
+		if err := yaml.UnmarshalStrict([]byte(value), overrides); err != nil {
+			klog.Warningf("Ignoring malformed kube-env overrides of MIG %s: %v", migName, err)
+			continue
+		}
+		result[migName] = overrides
+	}
+	return result
+}
+
+type configMapKubeEnvOverridesProvider struct {
+	mutex     sync.Mutex
+	overrides map[string]*KubeEnvOverrides
+}
+
+// NewConfigMapKubeEnvOverridesProvider returns a KubeEnvOverridesProvider which watches
+// the ConfigMap with the given name and namespace for kube-env overrides.
+func NewConfigMapKubeEnvOverridesProvider(kubeClient kube_client.Interface, namespace, name string, stopCh <-chan struct{}) KubeEnvOverridesProvider {
+	p := &configMapKubeEnvOverridesProvider{}
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, time.Hour,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}))
+	_, _ = informerFactory.Core().V1().ConfigMaps().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    p.update,
+		UpdateFunc: func(_, obj any) { p.update(obj) },
+		DeleteFunc: func(_ any) { p.set(nil) },
+	})
+	informerFactory.Start(stopCh)
+	return p
+}
+
+func (p *configMapKubeEnvOverridesProvider) update(obj any) {
+	configMap, ok := obj.(*apiv1.ConfigMap)
+	if !ok {
+		return
+	}
+	p.set(ParseKubeEnvOverrides(configMap.Data))
+}
+
+func (p *configMapKubeEnvOverridesProvider) set(overrides map[string]*KubeEnvOverrides) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.overrides = overrides
+}
+
+// GetKubeEnvOverrides returns the kube-env overrides of the MIG, or nil if there are none.
+func (p *configMapKubeEnvOverridesProvider) GetKubeEnvOverrides(migRef GceRef) *KubeEnvOverrides {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.overrides[migRef.Name]
+}
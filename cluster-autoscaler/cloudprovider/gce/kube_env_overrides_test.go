@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseKubeEnvOverrides(t *testing.T) {
+	overrides := ParseKubeEnvOverrides(map[string]string{
+		"mig-1": "labels:\n  a: b\ntaints:\n- key: dedicated\n  value: ml\n  effect: NoSchedule\nkubeReserved: cpu=100m\n",
+		"mig-2": "unknownField: 1\n",
+	})
+	assert.Equal(t, map[string]*KubeEnvOverrides{
+		"mig-1": {
+			Labels:       map[string]string{"a": "b"},
+			Taints:       []apiv1.Taint{{Key: "dedicated", Value: "ml", Effect: apiv1.TaintEffectNoSchedule}},
+			KubeReserved: "cpu=100m",
+		},
+	}, overrides)
+}
+
+func TestKubeEnvWithOverrides(t *testing.T) {
+	kubeEnv, err := ParseKubeEnv("test", "NODE_LABELS: a=b,c=d\n"+
+		"NODE_TAINTS: 'dedicated=ml:NoSchedule,test=dev:PreferNoSchedule'\n"+
+		"KUBELET_TEST_ARGS: --kube-reserved=cpu=1000m,memory=300000Mi\n")
+	require.NoError(t, err)
+	kubeEnv = kubeEnv.WithOverrides(&KubeEnvOverrides{
+		Labels:       map[string]string{"c": "overridden", "e": "f"},
+		Taints:       []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}, {Key: "extra", Effect: apiv1.TaintEffectNoExecute}},
+		KubeReserved: "cpu=100m",
+	})
+
+	labels, err := kubeEnv.NodeLabels()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b", "c": "overridden", "e": "f"}, labels)
+
+	taints, err := kubeEnv.NodeTaints()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []apiv1.Taint{
+		{Key: "test", Value: "dev", Effect: apiv1.TaintEffectPreferNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule},
+		{Key: "extra", Effect: apiv1.TaintEffectNoExecute},
+	}, taints)
+
+	kubeReserved, err := kubeEnv.KubeReserved()
+	require.NoError(t, err)
+	assert.Equal(t, "cpu=100m", kubeReserved)
+
+	kubeReserved, err = kubeEnv.WithOverrides(nil).KubeReserved()
+	require.NoError(t, err)
+	assert.Equal(t, "cpu=1000m,memory=300000Mi", kubeReserved)
+}
+
+func TestConfigMapKubeEnvOverridesProvider(t *testing.T) {
+	configMap := &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "kube-env-overrides"},
+		Data:       map[string]string{"mig-1": "kubeReserved: cpu=100m\n"},
+	}
+	kubeClient := fake.NewSimpleClientset(configMap)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	p := NewConfigMapKubeEnvOverridesProvider(kubeClient, "kube-system", "kube-env-overrides", stopCh)
+	assert.Eventually(t, func() bool {
+		overrides := p.GetKubeEnvOverrides(GceRef{Name: "mig-1"})
+		return overrides != nil && overrides.KubeReserved == "cpu=100m"
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Nil(t, p.GetKubeEnvOverrides(GceRef{Name: "mig-2"}))
+
+	require.NoError(t, kubeClient.CoreV1().ConfigMaps("kube-system").Delete(context.Background(), "kube-env-overrides", metav1.DeleteOptions{}))
+	assert.Eventually(t, func() bool {
+		return p.GetKubeEnvOverrides(GceRef{Name: "mig-1"}) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -29,10 +29,11 @@ func TestExtractKubeEnv(t *testing.T) {
 	someValue := "Lorem ipsum dolor sit amet"
 
 	testCases := []struct {
-		name        string
-		template    *gce.InstanceTemplate
-		wantKubeEnv KubeEnv
-		wantErr     bool
+		name            string
+		template        *gce.InstanceTemplate
+		wantKubeEnv     KubeEnv
+		wantErr         bool
+		wantParseReason string
 	}{
 		{
 			name:     "template is nil",
@@ -40,16 +41,18 @@ func TestExtractKubeEnv(t *testing.T) {
 			wantErr:  true,
 		},
 		{
-			name:     "template without instance properties",
-			template: &gce.InstanceTemplate{},
-			wantErr:  true,
+			name:            "template without instance properties",
+			template:        &gce.InstanceTemplate{},
+			wantErr:         true,
+			wantParseReason: KubeEnvNoMetadata,
 		},
 		{
 			name: "template without instance properties metadata",
 			template: &gce.InstanceTemplate{
 				Properties: &gce.InstanceProperties{},
 			},
-			wantErr: true,
+			wantErr:         true,
+			wantParseReason: KubeEnvNoMetadata,
 		},
 		{
 			name: "template without kube-env",
@@ -80,7 +83,8 @@ func TestExtractKubeEnv(t *testing.T) {
 					},
 				},
 			},
-			wantErr: true,
+			wantErr:         true,
+			wantParseReason: KubeEnvNoValue,
 		},
 		{
 			name: "template with incorrect kube-env",
@@ -95,7 +99,8 @@ func TestExtractKubeEnv(t *testing.T) {
 					},
 				},
 			},
-			wantErr: true,
+			wantErr:         true,
+			wantParseReason: KubeEnvMalformed,
 		},
 		{
 			name: "template with correct kube-env",
@@ -125,6 +130,10 @@ func TestExtractKubeEnv(t *testing.T) {
 			kubeEnv, err := ExtractKubeEnv(tc.template)
 			if tc.wantErr {
 				assert.Error(t, err)
+				var parseErr *KubeEnvParseError
+				if tc.wantParseReason != "" && assert.ErrorAs(t, err, &parseErr) {
+					assert.Equal(t, tc.wantParseReason, parseErr.Reason)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.wantKubeEnv, kubeEnv)
@@ -224,3 +233,34 @@ func TestKubeEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestKubeletArgs(t *testing.T) {
+	testCases := []struct {
+		name    string
+		kubeEnv KubeEnv
+		want    map[string]string
+	}{
+		{
+			name:    "no kubelet args",
+			kubeEnv: KubeEnv{},
+			want:    map[string]string{},
+		},
+		{
+			name: "kubelet args",
+			kubeEnv: KubeEnv{
+				env: map[string]string{
+					"KUBELET_TEST_ARGS": "--experimental-allocatable-ignore-eviction --kube-reserved=cpu=1000m,memory=300000Mi ignored",
+				},
+			},
+			want: map[string]string{
+				"experimental-allocatable-ignore-eviction": "true",
+				"kube-reserved": "cpu=1000m,memory=300000Mi",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.kubeEnv.KubeletArgs())
+		})
+	}
+}
@@ -55,6 +55,12 @@ type MigInfoProvider interface {
 	GetMigMachineType(migRef GceRef) (MachineType, error)
 	// Returns the pagination behavior of the listManagedInstances API method for a given MIG ref
 	GetListManagedInstancesResults(migRef GceRef) (string, error)
+	// GetMigAutoscaler returns the URL of the GCE autoscaler attached to a given MIG ref,
+	// or an empty string if there is none
+	GetMigAutoscaler(migRef GceRef) (string, error)
+	// GetMigZones returns the zones a given MIG distributes its instances across,
+	// i.e. its zone for zonal MIGs
+	GetMigZones(migRef GceRef) ([]string, error)
 }
 
 type timeProvider interface {
@@ -169,7 +175,7 @@ func (c *cachingMigInfoProvider) findMigWithMatchingBasename(instanceRef GceRef)
 	for _, mig := range c.migLister.GetMigs() {
 		migRef := mig.GceRef()
 		basename, err := c.GetMigBasename(migRef)
-		if err == nil && migRef.Project == instanceRef.Project && migRef.Zone == instanceRef.Zone && strings.HasPrefix(instanceRef.Name, basename) {
+		if err == nil && migRef.Project == instanceRef.Project && migContainsZone(migRef, instanceRef.Zone) && strings.HasPrefix(instanceRef.Name, basename) {
 			return mig
 		}
 	}
@@ -296,7 +302,7 @@ func (c *cachingMigInfoProvider) GetMigKubeEnv(migRef GceRef) (KubeEnv, error) {
 	}
 
 	kubeEnv, kubeEnvFound := c.cache.GetMigKubeEnv(migRef)
-	if kubeEnvFound && kubeEnv.templateName == instanceTemplateName.Name {
+	if kubeEnvFound && kubeEnv.templateName == instanceTemplateName.Name && !c.templateMetadataChanged(migRef, kubeEnv) {
 		return kubeEnv, nil
 	}
 
@@ -304,14 +310,37 @@ func (c *cachingMigInfoProvider) GetMigKubeEnv(migRef GceRef) (KubeEnv, error) {
 	if err != nil {
 		return KubeEnv{}, err
 	}
-	kubeEnv, err = ExtractKubeEnv(template)
-	if err != nil {
-		return KubeEnv{}, err
+	// Kube-env of a template shared with other migs, or used by the mig before,
+	// doesn't need to be parsed again.
+	kubeEnv, kubeEnvFound = c.cache.GetTemplateKubeEnv(templateKey(template))
+	if !kubeEnvFound {
+		kubeEnv, err = ExtractKubeEnv(template)
+		if err != nil {
+			return KubeEnv{}, err
+		}
 	}
 	c.cache.SetMigKubeEnv(migRef, kubeEnv)
 	return kubeEnv, nil
 }
 
+// templateMetadataChanged returns true if the cached instance template of the mig
+// has the same name as the one kube-env was extracted from, but different metadata.
+func (c *cachingMigInfoProvider) templateMetadataChanged(migRef GceRef, kubeEnv KubeEnv) bool {
+	template, found := c.cache.GetMigInstanceTemplate(migRef)
+	if !found || template.Name != kubeEnv.templateName {
+		return false
+	}
+	return templateKey(template) != kubeEnv.templateKey()
+}
+
+func templateKey(template *gce.InstanceTemplate) InstanceTemplateKey {
+	key := InstanceTemplateKey{Name: template.Name}
+	if template.Properties != nil && template.Properties.Metadata != nil {
+		key.Fingerprint = template.Properties.Metadata.Fingerprint
+	}
+	return key
+}
+
 // filMigInfoCache needs to be called with migInfoMutex locked
 func (c *cachingMigInfoProvider) fillMigInfoCache() error {
 	var zones []string
@@ -361,6 +390,10 @@ func (c *cachingMigInfoProvider) fillMigInfoCache() error {
 				c.cache.SetMigBasename(zoneMigRef, zoneMig.BaseInstanceName)
 				c.cache.SetListManagedInstancesResults(zoneMigRef, zoneMig.ListManagedInstancesResults)
 				c.cache.SetMigInstancesState(zoneMigRef, createInstancesState(zoneMig.TargetSize, zoneMig.CurrentActions))
+				c.cache.SetMigAutoscaler(zoneMigRef, migAutoscaler(zoneMig))
+				if zones := migDistributionZones(zoneMig); zoneMigRef.IsRegional() && len(zones) > 0 {
+					c.cache.SetMigZones(zoneMigRef, zones)
+				}
 
 				templateUrl, err := url.Parse(zoneMig.InstanceTemplate)
 				if err == nil {
@@ -405,6 +438,17 @@ func (c *cachingMigInfoProvider) GetMigMachineType(migRef GceRef) (MachineType,
 		return NewCustomMachineType(machineName)
 	}
 	zone := migRef.Zone
+	if migRef.IsRegional() {
+		// machine types are zonal, any zone of the MIG will do
+		zones, err := c.GetMigZones(migRef)
+		if err != nil {
+			return MachineType{}, err
+		}
+		if len(zones) == 0 {
+			return MachineType{}, fmt.Errorf("no zones found for regional mig %s", migRef)
+		}
+		zone = zones[0]
+	}
 	machine, found := c.cache.GetMachine(machineName, zone)
 	if !found {
 		rawMachine, err := c.gceClient.FetchMachineType(zone, machineName)
@@ -447,6 +491,68 @@ func (c *cachingMigInfoProvider) GetListManagedInstancesResults(migRef GceRef) (
 	return listManagedInstancesResults, nil
 }
 
+func (c *cachingMigInfoProvider) GetMigAutoscaler(migRef GceRef) (string, error) {
+	c.migInfoMutex.Lock()
+	defer c.migInfoMutex.Unlock()
+
+	autoscaler, found := c.cache.GetMigAutoscaler(migRef)
+	if found {
+		return autoscaler, nil
+	}
+
+	err := c.fillMigInfoCache()
+	autoscaler, found = c.cache.GetMigAutoscaler(migRef)
+	if err == nil && found {
+		return autoscaler, nil
+	}
+
+	// fallback to querying for a single mig
+	autoscaler, err = c.gceClient.FetchMigAutoscaler(migRef)
+	if err != nil {
+		c.migLister.HandleMigIssue(migRef, err)
+		return "", err
+	}
+	c.cache.SetMigAutoscaler(migRef, autoscaler)
+	return autoscaler, nil
+}
+
+func (c *cachingMigInfoProvider) GetMigZones(migRef GceRef) ([]string, error) {
+	if !migRef.IsRegional() {
+		return []string{migRef.Zone}, nil
+	}
+
+	c.migInfoMutex.Lock()
+	defer c.migInfoMutex.Unlock()
+
+	zones, found := c.cache.GetMigZones(migRef)
+	if found {
+		return zones, nil
+	}
+
+	err := c.fillMigInfoCache()
+	zones, found = c.cache.GetMigZones(migRef)
+	if err == nil && found {
+		return zones, nil
+	}
+
+	// fallback to querying for a single mig
+	zones, err = c.gceClient.FetchMigZones(migRef)
+	if err != nil {
+		c.migLister.HandleMigIssue(migRef, err)
+		return nil, err
+	}
+	c.cache.SetMigZones(migRef, zones)
+	return zones, nil
+}
+
+// migContainsZone determines whether instances of the MIG can run in the given zone.
+func migContainsZone(migRef GceRef, zone string) bool {
+	if migRef.IsRegional() {
+		return GceRef{Zone: zone}.Region() == migRef.Zone
+	}
+	return migRef.Zone == zone
+}
+
 func createInstancesState(targetSize int64, actionsSummary *gce.InstanceGroupManagerActionsSummary) map[cloudprovider.InstanceState]int64 {
 	if actionsSummary == nil {
 		return nil
@@ -37,12 +37,13 @@ var (
 	errFetchMigTemplateName             = errors.New("fetch mig template name error")
 	errFetchMigTemplate                 = errors.New("fetch mig template error")
 	errFetchListManagedInstancesResults = errors.New("fetch ListManagedInstancesResults error")
+	errFetchMigAutoscaler               = errors.New("fetch mig autoscaler error")
 	errFetchMachineType                 = errors.New("fetch machine type error")
 
 	mig = &gceMig{
 		gceRef: GceRef{
 			Project: "project",
-			Zone:    "us-test1",
+			Zone:    "us-test1-a",
 			Name:    "mig",
 		},
 	}
@@ -58,6 +59,8 @@ type mockAutoscalingGceClient struct {
 	fetchMigTemplate                 func(GceRef, string, bool) (*gce.InstanceTemplate, error)
 	fetchMachineType                 func(string, string) (*gce.MachineType, error)
 	fetchListManagedInstancesResults func(GceRef) (string, error)
+	fetchMigAutoscaler               func(GceRef) (string, error)
+	fetchMigZones                    func(GceRef) ([]string, error)
 }
 
 func (client *mockAutoscalingGceClient) FetchMachineType(zone, machineName string) (*gce.MachineType, error) {
@@ -88,6 +91,14 @@ func (client *mockAutoscalingGceClient) FetchListManagedInstancesResults(migRef
 	return client.fetchListManagedInstancesResults(migRef)
 }
 
+func (client *mockAutoscalingGceClient) FetchMigAutoscaler(migRef GceRef) (string, error) {
+	return client.fetchMigAutoscaler(migRef)
+}
+
+func (client *mockAutoscalingGceClient) FetchMigZones(migRef GceRef) ([]string, error) {
+	return client.fetchMigZones(migRef)
+}
+
 func (client *mockAutoscalingGceClient) FetchMigInstances(migRef GceRef) ([]GceInstance, error) {
 	return client.fetchMigInstances(migRef)
 }
@@ -224,7 +235,7 @@ func TestFillMigInstances(t *testing.T) {
 
 func TestMigInfoProviderGetMigForInstance(t *testing.T) {
 	instance := GceInstance{
-		Instance:  cloudprovider.Instance{Id: "gce://project/us-test1/base-instance-name-abcd"},
+		Instance:  cloudprovider.Instance{Id: "gce://project/us-test1-a/base-instance-name-abcd"},
 		NumericId: 777,
 	}
 	instanceRef, err := GceRefFromProviderId(instance.Id)
@@ -370,8 +381,8 @@ func TestGetMigInstances(t *testing.T) {
 	oldRefreshTime := time.Now().Add(-time.Hour)
 	newRefreshTime := time.Now()
 	instances := []GceInstance{
-		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1/base-instance-name-abcd"}, NumericId: 7},
-		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1/base-instance-name-efgh"}, NumericId: 88},
+		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1-a/base-instance-name-abcd"}, NumericId: 7},
+		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1-a/base-instance-name-efgh"}, NumericId: 88},
 	}
 
 	testCases := []struct {
@@ -456,18 +467,18 @@ func TestRegenerateMigInstancesCache(t *testing.T) {
 	otherMig := &gceMig{
 		gceRef: GceRef{
 			Project: "project",
-			Zone:    "us-test1",
+			Zone:    "us-test1-a",
 			Name:    "other-mig",
 		},
 	}
 
 	instances := []GceInstance{
-		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1/base-instance-name-abcd"}, NumericId: 1},
-		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1/base-instance-name-efgh"}, NumericId: 2},
+		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1-a/base-instance-name-abcd"}, NumericId: 1},
+		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1-a/base-instance-name-efgh"}, NumericId: 2},
 	}
 	otherInstances := []GceInstance{
-		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1/other-base-instance-name-abcd"}},
-		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1/other-base-instance-name-efgh"}},
+		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1-a/other-base-instance-name-abcd"}},
+		{Instance: cloudprovider.Instance{Id: "gce://project/us-test1-a/other-base-instance-name-efgh"}},
 	}
 
 	var instancesRefs, otherInstancesRefs []GceRef
@@ -860,6 +871,176 @@ func TestGetListManagedInstancesResults(t *testing.T) {
 	}
 }
 
+func TestGetMigAutoscaler(t *testing.T) {
+	autoscaler := "https://www.googleapis.com/compute/v1/projects/project/zones/us-test1-a/autoscalers/mig"
+	instanceGroupManager := &gce.InstanceGroupManager{
+		Zone:   mig.GceRef().Zone,
+		Name:   mig.GceRef().Name,
+		Status: &gce.InstanceGroupManagerStatus{Autoscaler: autoscaler},
+	}
+	testCases := []struct {
+		name               string
+		cache              *GceCache
+		fetchMigs          func(string) ([]*gce.InstanceGroupManager, error)
+		fetchAutoscaler    func(GceRef) (string, error)
+		expectedAutoscaler string
+		expectedErr        error
+	}{
+		{
+			name: "autoscaler in cache",
+			cache: &GceCache{
+				migs:               map[GceRef]Mig{mig.GceRef(): mig},
+				migAutoscalerCache: map[GceRef]string{mig.GceRef(): autoscaler},
+			},
+			expectedAutoscaler: autoscaler,
+		},
+		{
+			name:               "autoscaler from cache fill",
+			cache:              emptyCache(),
+			fetchMigs:          fetchMigsConst([]*gce.InstanceGroupManager{instanceGroupManager}),
+			expectedAutoscaler: autoscaler,
+		},
+		{
+			name:      "no autoscaler from cache fill",
+			cache:     emptyCache(),
+			fetchMigs: fetchMigsConst([]*gce.InstanceGroupManager{{Zone: mig.GceRef().Zone, Name: mig.GceRef().Name}}),
+		},
+		{
+			name:               "cache fill failure, fallback success",
+			cache:              emptyCache(),
+			fetchMigs:          fetchMigsFail,
+			fetchAutoscaler:    fetchMigAutoscalerConst(autoscaler),
+			expectedAutoscaler: autoscaler,
+		},
+		{
+			name:            "cache fill failure, fallback failure",
+			cache:           emptyCache(),
+			fetchMigs:       fetchMigsFail,
+			fetchAutoscaler: fetchMigAutoscalerFail,
+			expectedErr:     errFetchMigAutoscaler,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &mockAutoscalingGceClient{
+				fetchMigs:          tc.fetchMigs,
+				fetchMigAutoscaler: tc.fetchAutoscaler,
+			}
+			migLister := NewMigLister(tc.cache)
+			provider := NewCachingMigInfoProvider(tc.cache, migLister, client, mig.GceRef().Project, 1, 0*time.Second)
+
+			autoscaler, err := provider.GetMigAutoscaler(mig.GceRef())
+			cachedAutoscaler, found := tc.cache.GetMigAutoscaler(mig.GceRef())
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedErr == nil, found)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.expectedAutoscaler, autoscaler)
+				assert.Equal(t, tc.expectedAutoscaler, cachedAutoscaler)
+			}
+		})
+	}
+}
+
+func TestGetMigZones(t *testing.T) {
+	regionalMig := &gceMig{
+		gceRef: GceRef{
+			Project: "project",
+			Zone:    "us-test1",
+			Name:    "regional-mig",
+		},
+	}
+	zones := []string{"us-test1-a", "us-test1-b"}
+	instanceGroupManager := &gce.InstanceGroupManager{
+		Region: regionalMig.GceRef().Zone,
+		Name:   regionalMig.GceRef().Name,
+		DistributionPolicy: &gce.DistributionPolicy{
+			Zones: []*gce.DistributionPolicyZoneConfiguration{
+				{Zone: "https://www.googleapis.com/compute/v1/projects/project/zones/us-test1-a"},
+				{Zone: "https://www.googleapis.com/compute/v1/projects/project/zones/us-test1-b"},
+			},
+		},
+	}
+	regionalCache := func() *GceCache {
+		cache := emptyCache()
+		cache.migs = map[GceRef]Mig{regionalMig.GceRef(): regionalMig}
+		return cache
+	}
+	testCases := []struct {
+		name          string
+		cache         *GceCache
+		fetchMigs     func(string) ([]*gce.InstanceGroupManager, error)
+		fetchMigZones func(GceRef) ([]string, error)
+		expectedZones []string
+		expectedErr   error
+	}{
+		{
+			name: "zones in cache",
+			cache: &GceCache{
+				migs:          map[GceRef]Mig{regionalMig.GceRef(): regionalMig},
+				migZonesCache: map[GceRef][]string{regionalMig.GceRef(): zones},
+			},
+			expectedZones: zones,
+		},
+		{
+			name:          "zones from cache fill",
+			cache:         regionalCache(),
+			fetchMigs:     fetchMigsConst([]*gce.InstanceGroupManager{instanceGroupManager}),
+			expectedZones: zones,
+		},
+		{
+			name:          "cache fill failure, fallback success",
+			cache:         regionalCache(),
+			fetchMigs:     fetchMigsFail,
+			fetchMigZones: func(GceRef) ([]string, error) { return zones, nil },
+			expectedZones: zones,
+		},
+		{
+			name:          "cache fill failure, fallback failure",
+			cache:         regionalCache(),
+			fetchMigs:     fetchMigsFail,
+			fetchMigZones: func(GceRef) ([]string, error) { return nil, errFetchMig },
+			expectedErr:   errFetchMig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &mockAutoscalingGceClient{
+				fetchMigs:     tc.fetchMigs,
+				fetchMigZones: tc.fetchMigZones,
+			}
+			migLister := NewMigLister(tc.cache)
+			provider := NewCachingMigInfoProvider(tc.cache, migLister, client, regionalMig.GceRef().Project, 1, 0*time.Second)
+
+			migZones, err := provider.GetMigZones(regionalMig.GceRef())
+			cachedZones, found := tc.cache.GetMigZones(regionalMig.GceRef())
+
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedErr == nil, found)
+			if tc.expectedErr == nil {
+				assert.Equal(t, tc.expectedZones, migZones)
+				assert.Equal(t, tc.expectedZones, cachedZones)
+			}
+		})
+	}
+
+	t.Run("zonal mig", func(t *testing.T) {
+		provider := NewCachingMigInfoProvider(emptyCache(), NewMigLister(emptyCache()), &mockAutoscalingGceClient{}, mig.GceRef().Project, 1, 0*time.Second)
+		migZones, err := provider.GetMigZones(mig.GceRef())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{mig.GceRef().Zone}, migZones)
+	})
+}
+
+func TestMigContainsZone(t *testing.T) {
+	assert.True(t, migContainsZone(GceRef{Zone: "us-test1-a"}, "us-test1-a"))
+	assert.False(t, migContainsZone(GceRef{Zone: "us-test1-a"}, "us-test1-b"))
+	assert.True(t, migContainsZone(GceRef{Zone: "us-test1"}, "us-test1-b"))
+	assert.False(t, migContainsZone(GceRef{Zone: "us-test1"}, "us-test2-b"))
+}
+
 func TestGetMigInstanceTemplateName(t *testing.T) {
 	templateName := "template-name"
 	instanceGroupManager := &gce.InstanceGroupManager{
@@ -1146,6 +1327,24 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 		},
 	}
 
+	updatedKubeEnvValue := "VAR5: VALUE5"
+	updatedTemplate := &gce.InstanceTemplate{
+		Name:        templateName,
+		Description: "updated instance template",
+		Properties: &gce.InstanceProperties{
+			Metadata: &gce.Metadata{
+				Fingerprint: "updated",
+				Items: []*gce.MetadataItems{
+					{Key: "kube-env", Value: &updatedKubeEnvValue},
+				},
+			},
+		},
+	}
+	updatedKubeEnv, err := ExtractKubeEnv(updatedTemplate)
+	assert.NoError(t, err)
+
+	sharedKubeEnv := KubeEnv{templateName: templateName, env: map[string]string{"VAR1": "SHARED"}}
+
 	testCases := []struct {
 		name                  string
 		cache                 *GceCache
@@ -1162,6 +1361,7 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 				migs:                      map[GceRef]Mig{mig.GceRef(): mig},
 				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
 				kubeEnvCache:              map[GceRef]KubeEnv{mig.GceRef(): kubeEnv},
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{{Name: templateName}: kubeEnv},
 			},
 			expectedKubeEnv:       kubeEnv,
 			expectedCachedKubeEnv: kubeEnv,
@@ -1173,6 +1373,7 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
 				instanceTemplatesCache:    map[GceRef]*gce.InstanceTemplate{mig.GceRef(): template},
 				kubeEnvCache:              make(map[GceRef]KubeEnv),
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{},
 			},
 			expectedKubeEnv:       kubeEnv,
 			expectedCachedKubeEnv: kubeEnv,
@@ -1184,11 +1385,36 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
 				instanceTemplatesCache:    make(map[GceRef]*gce.InstanceTemplate),
 				kubeEnvCache:              make(map[GceRef]KubeEnv),
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{},
 			},
 			fetchMigTemplate:      fetchMigTemplateConst(template),
 			expectedKubeEnv:       kubeEnv,
 			expectedCachedKubeEnv: kubeEnv,
 		},
+		{
+			name: "cache without kube-env, kube-env of template cached",
+			cache: &GceCache{
+				migs:                      map[GceRef]Mig{mig.GceRef(): mig},
+				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
+				instanceTemplatesCache:    map[GceRef]*gce.InstanceTemplate{mig.GceRef(): template},
+				kubeEnvCache:              make(map[GceRef]KubeEnv),
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{{Name: templateName}: sharedKubeEnv},
+			},
+			expectedKubeEnv:       sharedKubeEnv,
+			expectedCachedKubeEnv: sharedKubeEnv,
+		},
+		{
+			name: "cache with kube-env, template metadata changed",
+			cache: &GceCache{
+				migs:                      map[GceRef]Mig{mig.GceRef(): mig},
+				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
+				instanceTemplatesCache:    map[GceRef]*gce.InstanceTemplate{mig.GceRef(): updatedTemplate},
+				kubeEnvCache:              map[GceRef]KubeEnv{mig.GceRef(): kubeEnv},
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{{Name: templateName}: kubeEnv},
+			},
+			expectedKubeEnv:       updatedKubeEnv,
+			expectedCachedKubeEnv: updatedKubeEnv,
+		},
 		{
 			name: "cache with old kube-env, new template cached",
 			cache: &GceCache{
@@ -1196,6 +1422,7 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
 				instanceTemplatesCache:    map[GceRef]*gce.InstanceTemplate{mig.GceRef(): template},
 				kubeEnvCache:              map[GceRef]KubeEnv{mig.GceRef(): oldKubeEnv},
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{},
 			},
 			expectedKubeEnv:       kubeEnv,
 			expectedCachedKubeEnv: kubeEnv,
@@ -1207,6 +1434,7 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
 				instanceTemplatesCache:    map[GceRef]*gce.InstanceTemplate{mig.GceRef(): oldTemplate},
 				kubeEnvCache:              map[GceRef]KubeEnv{mig.GceRef(): oldKubeEnv},
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{},
 			},
 			fetchMigTemplate:      fetchMigTemplateConst(template),
 			expectedKubeEnv:       kubeEnv,
@@ -1219,6 +1447,7 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
 				instanceTemplatesCache:    make(map[GceRef]*gce.InstanceTemplate),
 				kubeEnvCache:              make(map[GceRef]KubeEnv),
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{},
 			},
 			fetchMigTemplate: fetchMigTemplateFail,
 			expectedErr:      errFetchMigTemplate,
@@ -1230,6 +1459,7 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 				instanceTemplateNameCache: map[GceRef]InstanceTemplateName{mig.GceRef(): {templateName, false}},
 				instanceTemplatesCache:    map[GceRef]*gce.InstanceTemplate{mig.GceRef(): oldTemplate},
 				kubeEnvCache:              map[GceRef]KubeEnv{mig.GceRef(): oldKubeEnv},
+				templateKubeEnvCache:      map[InstanceTemplateKey]KubeEnv{},
 			},
 			fetchMigTemplate:      fetchMigTemplateFail,
 			expectedCachedKubeEnv: oldKubeEnv,
@@ -1260,6 +1490,7 @@ func TestGetMigInstanceKubeEnv(t *testing.T) {
 			assert.Equal(t, tc.expectedErr, err)
 			if tc.expectedErr == nil {
 				assert.Equal(t, tc.expectedKubeEnv, kubeEnv)
+				assert.Equal(t, map[InstanceTemplateKey]KubeEnv{kubeEnv.templateKey(): kubeEnv}, tc.cache.templateKubeEnvCache)
 			}
 
 			assert.Equal(t, tc.expectedCachedKubeEnv.env != nil, found)
@@ -1453,6 +1684,8 @@ func emptyCache() *GceCache {
 		migBaseNameCache:                 make(map[GceRef]string),
 		migInstancesStateCache:           make(map[GceRef]map[cloudprovider.InstanceState]int64),
 		listManagedInstancesResultsCache: make(map[GceRef]string),
+		migAutoscalerCache:               make(map[GceRef]string),
+		migZonesCache:                    make(map[GceRef][]string),
 		instanceTemplateNameCache:        make(map[GceRef]InstanceTemplateName),
 		instanceTemplatesCache:           make(map[GceRef]*gce.InstanceTemplate),
 		instancesFromUnknownMig:          make(map[GceRef]bool),
@@ -1542,6 +1775,16 @@ func fetchListManagedInstancesResultsConst(listManagedInstancesResults string) f
 	}
 }
 
+func fetchMigAutoscalerFail(_ GceRef) (string, error) {
+	return "", errFetchMigAutoscaler
+}
+
+func fetchMigAutoscalerConst(autoscaler string) func(GceRef) (string, error) {
+	return func(GceRef) (string, error) {
+		return autoscaler, nil
+	}
+}
+
 func fetchMachineTypeFail(_, _ string) (*gce.MachineType, error) {
 	return nil, errFetchMachineType
 }
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ReservedMemoryModel models the memory of nodes of a machine family which
+// isn't allocatable to pods on top of the estimated kernel reserved memory and
+// eviction threshold. It's used in place of memory kube-reserved when kube-env
+// doesn't specify it, so that template nodes don't overestimate allocatable
+// memory, which would cause repeated scale-ups of nodes pods don't fit on.
+type ReservedMemoryModel struct {
+	// KernelReservedRatio is the ratio of physical memory additionally reserved
+	// by the kernel and firmware on the machine family, e.g. for memory
+	// encryption.
+	KernelReservedRatio float64
+	// SystemReserved is the memory in bytes reserved for system daemons and
+	// the kubelet.
+	SystemReserved int64
+}
+
+// ReservedMemory returns the memory in bytes reserved on a node with the given
+// physical memory.
+func (m ReservedMemoryModel) ReservedMemory(physicalMemory int64) int64 {
+	return int64(math.Ceil(m.KernelReservedRatio*float64(physicalMemory))) + m.SystemReserved
+}
+
+// ParseReservedMemoryModels returns the reserved memory models by machine
+// family. Models have the format
+// <machine-family>:<kernel-reserved-percent>:<system-reserved>, e.g.
+// "n2d:2%:256Mi". There are no default models, machine families without a
+// model use the generic kernel reserved estimate.
+func ParseReservedMemoryModels(specs []string) (map[string]ReservedMemoryModel, error) {
+	models := make(map[string]ReservedMemoryModel, len(specs))
+	for _, spec := range specs {
+		parts := strings.Split(spec, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid reserved memory model %q, expected <machine-family>:<kernel-reserved-percent>:<system-reserved>", spec)
+		}
+		ratio, err := parsePercentageToRatio(parts[1])
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("invalid kernel reserved percentage in reserved memory model %q: %v", spec, err)
+		}
+		systemReserved, err := resource.ParseQuantity(parts[2])
+		if err != nil || systemReserved.Sign() < 0 {
+			return nil, fmt.Errorf("invalid system reserved memory in reserved memory model %q: %v", spec, err)
+		}
+		models[parts[0]] = ReservedMemoryModel{KernelReservedRatio: ratio, SystemReserved: systemReserved.Value()}
+	}
+	return models, nil
+}
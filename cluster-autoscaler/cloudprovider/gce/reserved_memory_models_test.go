@@ -0,0 +1,47 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReservedMemoryModels(t *testing.T) {
+	models, err := ParseReservedMemoryModels(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, models)
+
+	models, err = ParseReservedMemoryModels([]string{"n2d:1.5%:100Mi", "e2:0%:64Mi"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]ReservedMemoryModel{
+		"n2d": {KernelReservedRatio: 0.015, SystemReserved: 100 * MiB},
+		"e2":  {SystemReserved: 64 * MiB},
+	}, models)
+
+	for _, spec := range []string{"n2d", "n2d:2%", ":2%:100Mi", "n2d:2:100Mi", "n2d:200%:100Mi", "n2d:2%:lots", "n2d:2%:-1Mi"} {
+		_, err := ParseReservedMemoryModels([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestReservedMemory(t *testing.T) {
+	model := ReservedMemoryModel{KernelReservedRatio: 0.0625, SystemReserved: 256 * MiB}
+	assert.Equal(t, int64(GiB+256*MiB), model.ReservedMemory(16*GiB))
+	assert.Equal(t, int64(0), ReservedMemoryModel{}.ReservedMemory(16*GiB))
+}
@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -37,7 +36,17 @@ import (
 )
 
 // GceTemplateBuilder builds templates for GCE nodes.
-type GceTemplateBuilder struct{}
+type GceTemplateBuilder struct {
+	// reservedMemoryModels are used by machine family if kube-env doesn't
+	// specify memory kube-reserved.
+	reservedMemoryModels map[string]ReservedMemoryModel
+}
+
+// NewGceTemplateBuilder returns a GceTemplateBuilder using the given reserved
+// memory models by machine family.
+func NewGceTemplateBuilder(reservedMemoryModels map[string]ReservedMemoryModel) *GceTemplateBuilder {
+	return &GceTemplateBuilder{reservedMemoryModels: reservedMemoryModels}
+}
 
 // These annotations are used internally only to store information in node temlate and use it later in CA, the actuall nodes won't have these annotations.
 const (
@@ -79,8 +88,8 @@ func (t *GceTemplateBuilder) BuildCapacity(m MigOsInfo, cpu int64, mem int64, ac
 	memTotal := mem - r.CalculateKernelReserved(m, mem)
 	capacity[apiv1.ResourceMemory] = *resource.NewQuantity(memTotal, resource.DecimalSI)
 
-	if accelerators != nil && len(accelerators) > 0 {
-		capacity[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(t.getAcceleratorCount(accelerators), resource.DecimalSI)
+	if gpuCount := t.getAcceleratorCount(accelerators); gpuCount > 0 {
+		capacity[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(gpuCount, resource.DecimalSI)
 	}
 
 	if ephemeralStorage > 0 {
@@ -103,17 +112,50 @@ func (t *GceTemplateBuilder) BuildCapacity(m MigOsInfo, cpu int64, mem int64, ac
 // BuildAllocatableFromKubeEnv builds node allocatable based on capacity of the node and
 // value of kubeEnv.
 func (t *GceTemplateBuilder) BuildAllocatableFromKubeEnv(capacity apiv1.ResourceList, kubeEnv KubeEnv, evictionHard *EvictionHard) (apiv1.ResourceList, error) {
-	kubeReserved, err := extractKubeReservedFromKubeEnv(kubeEnv)
+	reserved, err := kubeReservedFromKubeEnv(kubeEnv)
 	if err != nil {
 		return nil, err
 	}
-	reserved, err := parseKubeReserved(kubeReserved)
+	return t.CalculateAllocatable(capacity, reserved, evictionHard), nil
+}
+
+// buildAllocatable builds node allocatable like BuildAllocatableFromKubeEnv, but
+// uses the reserved memory model of the machine family if kube-env doesn't
+// specify memory kube-reserved.
+func (t *GceTemplateBuilder) buildAllocatable(capacity apiv1.ResourceList, kubeEnv KubeEnv, evictionHard *EvictionHard, machineType string, physicalMemory int64) (apiv1.ResourceList, error) {
+	reserved, err := kubeReservedFromKubeEnv(kubeEnv)
+	if _, found := reserved[apiv1.ResourceMemory]; !found {
+		if model, found := t.reservedMemoryModel(machineType); found {
+			if err != nil {
+				klog.V(4).Infof("Using reserved memory model of machine type %s: %v", machineType, err)
+				reserved, err = apiv1.ResourceList{}, nil
+			}
+			reserved[apiv1.ResourceMemory] = *resource.NewQuantity(model.ReservedMemory(physicalMemory), resource.BinarySI)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 	return t.CalculateAllocatable(capacity, reserved, evictionHard), nil
 }
 
+func (t *GceTemplateBuilder) reservedMemoryModel(machineType string) (ReservedMemoryModel, bool) {
+	family, err := GetMachineFamily(machineType)
+	if err != nil {
+		return ReservedMemoryModel{}, false
+	}
+	model, found := t.reservedMemoryModels[family]
+	return model, found
+}
+
+func kubeReservedFromKubeEnv(kubeEnv KubeEnv) (apiv1.ResourceList, error) {
+	kubeReserved, err := kubeEnv.KubeReserved()
+	if err != nil {
+		return nil, err
+	}
+	return parseKubeReserved(kubeReserved)
+}
+
 // CalculateAllocatable computes allocatable resources subtracting kube reserved values
 // and kubelet eviction memory buffer from corresponding capacity.
 func (t *GceTemplateBuilder) CalculateAllocatable(capacity apiv1.ResourceList, kubeReserved apiv1.ResourceList, evictionHard *EvictionHard) apiv1.ResourceList {
@@ -177,12 +219,24 @@ func (t *GceTemplateBuilder) BuildNodeFromTemplate(mig Mig, migOsInfo MigOsInfo,
 	if !isBootDiskEphemeralStorageWithInstanceTemplateDisabled(kubeEnv) {
 		// ephemeral storage is backed up by boot disk
 		ephemeralStorage, err = getBootDiskEphemeralStorageFromInstanceTemplateProperties(template.Properties)
+		if sizeGb, found := kubeEnv.BootDiskSizeGb(); err != nil && found {
+			// instance template doesn't describe its disks, fall back to the size declared in kube-env
+			ephemeralStorage, err = sizeGb*units.GiB, nil
+		}
 	} else {
 		// ephemeral storage is backed up by local ssd
 		addAnnotation(&node, EphemeralStorageLocalSsdAnnotation, strconv.FormatBool(true))
 	}
 
 	localSsdCount, err := getLocalSsdCount(template.Properties)
+	if err != nil {
+		// instance template doesn't describe its disks, fall back to the disks declared in kube-env
+		if count, found := kubeEnv.LocalSSDCount(); found {
+			localSsdCount, err = count, nil
+		} else if _, found := kubeEnv.BootDiskSizeGb(); found {
+			localSsdCount, err = 0, nil
+		}
+	}
 	if localSsdCount > 0 {
 		addAnnotation(&node, LocalSsdCountAnnotation, strconv.FormatInt(localSsdCount, 10))
 	}
@@ -201,6 +255,22 @@ func (t *GceTemplateBuilder) BuildNodeFromTemplate(mig Mig, migOsInfo MigOsInfo,
 		klog.Errorf("could not fetch extended resources from instance template: %v", err)
 	}
 
+	accelerators, err := buildAcceleratorTemplate(template, kubeEnv)
+	if err != nil {
+		return nil, err
+	}
+	if len(accelerators.resources) > 0 {
+		if extendedResources == nil {
+			extendedResources = apiv1.ResourceList{}
+		}
+		for resourceName, quantity := range accelerators.resources {
+			if _, found := extendedResources[resourceName]; !found {
+				extendedResources[resourceName] = quantity
+			}
+		}
+	}
+	node.Labels = cloudprovider.JoinStringMaps(node.Labels, accelerators.labels)
+
 	capacity, err := t.BuildCapacity(migOsInfo, cpu, mem, template.Properties.GuestAccelerators, ephemeralStorage, ephemeralStorageLocalSsdCount, pods, reserved, extendedResources)
 	if err != nil {
 		return nil, err
@@ -213,31 +283,37 @@ func (t *GceTemplateBuilder) BuildNodeFromTemplate(mig Mig, migOsInfo MigOsInfo,
 
 	if kubeEnv.env != nil {
 		// Extract labels
-		kubeEnvLabels, err := extractLabelsFromKubeEnv(kubeEnv)
+		kubeEnvLabels, err := kubeEnv.NodeLabels()
 		if err != nil {
 			return nil, err
 		}
 		node.Labels = cloudprovider.JoinStringMaps(node.Labels, kubeEnvLabels)
 
 		// Extract taints
-		kubeEnvTaints, err := extractTaintsFromKubeEnv(kubeEnv)
+		kubeEnvTaints, err := kubeEnv.NodeTaints()
 		if err != nil {
 			return nil, err
 		}
 		node.Spec.Taints = append(node.Spec.Taints, kubeEnvTaints...)
 
 		// Extract Eviction Hard
-		evictionHardFromKubeEnv, err := extractEvictionHardFromKubeEnv(kubeEnv)
+		evictionHardFromKubeEnv, err := kubeEnv.EvictionHard()
 		if err != nil || len(evictionHardFromKubeEnv) == 0 {
 			klog.Warning("unable to get evictionHardFromKubeEnv values, continuing without it.")
 		}
 		evictionHard := ParseEvictionHardOrGetDefault(evictionHardFromKubeEnv)
 
-		if allocatable, err := t.BuildAllocatableFromKubeEnv(node.Status.Capacity, kubeEnv, evictionHard); err == nil {
+		if allocatable, err := t.buildAllocatable(node.Status.Capacity, kubeEnv, evictionHard, template.Properties.MachineType, mem); err == nil {
 			nodeAllocatable = allocatable
 		}
 	}
 
+	for _, taint := range accelerators.taints {
+		if !hasTaintWithKey(node.Spec.Taints, taint.Key) {
+			node.Spec.Taints = append(node.Spec.Taints, taint)
+		}
+	}
+
 	if nodeAllocatable == nil {
 		klog.Warningf("could not extract kube-reserved from kubeEnv for mig %q, setting allocatable to capacity.", mig.GceRef().Name)
 		node.Status.Allocatable = node.Status.Capacity
@@ -256,6 +332,15 @@ func (t *GceTemplateBuilder) BuildNodeFromTemplate(mig Mig, migOsInfo MigOsInfo,
 	return &node, nil
 }
 
+func hasTaintWithKey(taints []apiv1.Taint, key string) bool {
+	for _, taint := range taints {
+		if taint.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
 func ephemeralStorageLocalSSDCount(kubeEnv KubeEnv) int64 {
 	v, found, err := extractAutoscalerVarFromKubeEnv(kubeEnv, "ephemeral_storage_local_ssd_count")
 	if err != nil {
@@ -339,17 +424,26 @@ func BuildGenericLabels(ref GceRef, machineType string, nodeName string, os Oper
 	result[apiv1.LabelOSStable] = string(os)
 
 	result[apiv1.LabelInstanceTypeStable] = machineType
-	ix := strings.LastIndex(ref.Zone, "-")
-	if ix == -1 {
-		return nil, fmt.Errorf("unexpected zone: %s", ref.Zone)
+	if ref.IsRegional() {
+		// zone labels of regional MIG nodes depend on the zone of the instance
+		result[apiv1.LabelTopologyRegion] = ref.Zone
+	} else {
+		ix := strings.LastIndex(ref.Zone, "-")
+		if ix == -1 {
+			return nil, fmt.Errorf("unexpected zone: %s", ref.Zone)
+		}
+		result[apiv1.LabelTopologyRegion] = ref.Zone[:ix]
+		setZoneLabels(result, ref.Zone)
 	}
-	result[apiv1.LabelTopologyRegion] = ref.Zone[:ix]
-	result[apiv1.LabelTopologyZone] = ref.Zone
-	result[gceCSITopologyKeyZone] = ref.Zone
 	result[apiv1.LabelHostname] = nodeName
 	return result, nil
 }
 
+func setZoneLabels(labels map[string]string, zone string) {
+	labels[apiv1.LabelTopologyZone] = zone
+	labels[gceCSITopologyKeyZone] = zone
+}
+
 func parseKubeReserved(kubeReserved string) (apiv1.ResourceList, error) {
 	resourcesMap, err := parseKeyValueListToMap(kubeReserved)
 	if err != nil {
@@ -371,65 +465,12 @@ func parseKubeReserved(kubeReserved string) (apiv1.ResourceList, error) {
 
 // GetLabelsFromKubeEnv returns labels from kube-env
 func GetLabelsFromKubeEnv(kubeEnv KubeEnv) (map[string]string, error) {
-	return extractLabelsFromKubeEnv(kubeEnv)
-}
-
-func extractLabelsFromKubeEnv(kubeEnv KubeEnv) (map[string]string, error) {
-	// In v1.10+, labels are only exposed for the autoscaler via AUTOSCALER_ENV_VARS
-	// see kubernetes/kubernetes#61119. We try AUTOSCALER_ENV_VARS first, then
-	// fall back to the old way.
-	labels, found, err := extractAutoscalerVarFromKubeEnv(kubeEnv, "node_labels")
-	if err != nil {
-		klog.Errorf("error while trying to extract node_labels from AUTOSCALER_ENV_VARS: %v", err)
-	}
-	if !found {
-		labels, _ = kubeEnv.Var("NODE_LABELS")
-	}
-	return parseKeyValueListToMap(labels)
+	return kubeEnv.NodeLabels()
 }
 
 // GetTaintsFromKubeEnv returns labels from kube-env
 func GetTaintsFromKubeEnv(kubeEnv KubeEnv) ([]apiv1.Taint, error) {
-	return extractTaintsFromKubeEnv(kubeEnv)
-}
-
-func extractTaintsFromKubeEnv(kubeEnv KubeEnv) ([]apiv1.Taint, error) {
-	// In v1.10+, taints are only exposed for the autoscaler via AUTOSCALER_ENV_VARS
-	// see kubernetes/kubernetes#61119. We try AUTOSCALER_ENV_VARS first, then
-	// fall back to the old way.
-	taints, found, err := extractAutoscalerVarFromKubeEnv(kubeEnv, "node_taints")
-	if err != nil {
-		klog.Errorf("error while trying to extract node_taints from AUTOSCALER_ENV_VARS: %v", err)
-	}
-	if !found {
-		taints, _ = kubeEnv.Var("NODE_TAINTS")
-	}
-	taintMap, err := parseKeyValueListToMap(taints)
-	if err != nil {
-		return nil, err
-	}
-	return buildTaints(taintMap)
-}
-
-func extractKubeReservedFromKubeEnv(kubeEnv KubeEnv) (string, error) {
-	// In v1.10+, kube-reserved is only exposed for the autoscaler via AUTOSCALER_ENV_VARS
-	// see kubernetes/kubernetes#61119. We try AUTOSCALER_ENV_VARS first, then
-	// fall back to the old way.
-	kubeReserved, found, err := extractAutoscalerVarFromKubeEnv(kubeEnv, "kube_reserved")
-	if err != nil {
-		klog.Errorf("error while trying to extract kube_reserved from AUTOSCALER_ENV_VARS: %v", err)
-	}
-	if !found {
-		kubeletArgs, _ := kubeEnv.Var("KUBELET_TEST_ARGS")
-		resourcesRegexp := regexp.MustCompile(`--kube-reserved=([^ ]+)`)
-
-		matches := resourcesRegexp.FindStringSubmatch(kubeletArgs)
-		if len(matches) > 1 {
-			return matches[1], nil
-		}
-		return "", fmt.Errorf("kube-reserved not in kubelet args in kube-env: %q", kubeletArgs)
-	}
-	return kubeReserved, nil
+	return kubeEnv.NodeTaints()
 }
 
 func extractExtendedResourcesFromKubeEnv(kubeEnv KubeEnv) (apiv1.ResourceList, error) {
@@ -686,21 +727,6 @@ func extractAutoscalingOptionsFromKubeEnv(kubeEnv KubeEnv) (map[string]string, e
 	return parseKeyValueListToMap(optionsAsString)
 }
 
-func extractEvictionHardFromKubeEnv(kubeEnv KubeEnv) (map[string]string, error) {
-	evictionHardAsString, found, err := extractAutoscalerVarFromKubeEnv(kubeEnv, "evictionHard")
-	if err != nil {
-		klog.Warningf("error while obtaining eviction-hard from AUTOSCALER_ENV_VARS; %v", err)
-		return nil, err
-	}
-
-	if !found {
-		klog.Warning("no evictionHard defined in AUTOSCALER_ENV_VARS;")
-		return make(map[string]string), nil
-	}
-
-	return parseKeyValueListToMap(evictionHardAsString)
-}
-
 func extractAutoscalerVarFromKubeEnv(kubeEnv KubeEnv, name string) (value string, found bool, err error) {
 	const autoscalerVars = "AUTOSCALER_ENV_VARS"
 	autoscalerVals, found := kubeEnv.Var(autoscalerVars)
@@ -389,6 +389,21 @@ func TestBuildGenericLabels(t *testing.T) {
 	}
 }
 
+func TestBuildGenericLabelsRegional(t *testing.T) {
+	labels, err := BuildGenericLabels(GceRef{
+		Name:    "kubernetes-minion-group",
+		Project: "mwielgus-proj",
+		Zone:    "us-central1"},
+		"n1-standard-8",
+		"sillyname",
+		OperatingSystemLinux,
+		Amd64)
+	assert.NoError(t, err)
+	assert.Equal(t, "us-central1", labels[apiv1.LabelTopologyRegion])
+	assert.NotContains(t, labels, apiv1.LabelTopologyZone)
+	assert.NotContains(t, labels, gceCSITopologyKeyZone)
+}
+
 func TestCalculateAllocatable(t *testing.T) {
 	type testCase struct {
 		scenario                    string
@@ -502,6 +517,73 @@ func TestBuildAllocatableFromKubeEnv(t *testing.T) {
 	}
 }
 
+func TestBuildAllocatableWithReservedMemoryModel(t *testing.T) {
+	const withoutKubeReserved = "KUBELET_TEST_ARGS: --experimental-allocatable-ignore-eviction\n"
+	const withKubeReservedCpu = "KUBELET_TEST_ARGS: --kube-reserved=cpu=1000m\n"
+	const withKubeReservedMemory = "KUBELET_TEST_ARGS: --kube-reserved=cpu=1000m,memory=1Gi\n"
+	tb := NewGceTemplateBuilder(map[string]ReservedMemoryModel{"n2d": {KernelReservedRatio: 0.02, SystemReserved: 256 * MiB}})
+	physicalMemory := int64(32 * GiB)
+	capacity, err := makeResourceList("8", fmt.Sprintf("%v", 31*GiB), 0, "")
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		scenario       string
+		kubeEnvValue   string
+		machineType    string
+		expectedCpu    int64
+		expectedMemory int64
+		expectedErr    bool
+	}{
+		{
+			scenario:       "kube-reserved memory isn't replaced",
+			kubeEnvValue:   withKubeReservedMemory,
+			machineType:    "n2d-standard-8",
+			expectedCpu:    7,
+			expectedMemory: 30*GiB - defaultKubeletEvictionHardMemory,
+		},
+		{
+			scenario:       "model used for missing kube-reserved memory",
+			kubeEnvValue:   withKubeReservedCpu,
+			machineType:    "n2d-standard-8",
+			expectedCpu:    7,
+			expectedMemory: 31*GiB - int64(math.Ceil(0.02*float64(physicalMemory))) - 256*MiB - defaultKubeletEvictionHardMemory,
+		},
+		{
+			scenario:       "model used for missing kube-reserved",
+			kubeEnvValue:   withoutKubeReserved,
+			machineType:    "n2d-standard-8",
+			expectedCpu:    8,
+			expectedMemory: 31*GiB - int64(math.Ceil(0.02*float64(physicalMemory))) - 256*MiB - defaultKubeletEvictionHardMemory,
+		},
+		{
+			scenario:       "machine family without model",
+			kubeEnvValue:   withKubeReservedCpu,
+			machineType:    "e2-standard-8",
+			expectedCpu:    7,
+			expectedMemory: 31*GiB - defaultKubeletEvictionHardMemory,
+		},
+		{
+			scenario:     "missing kube-reserved without model",
+			kubeEnvValue: withoutKubeReserved,
+			machineType:  "e2-standard-8",
+			expectedErr:  true,
+		},
+	} {
+		t.Run(tc.scenario, func(t *testing.T) {
+			kubeEnv, err := ParseKubeEnv("test", tc.kubeEnvValue)
+			assert.NoError(t, err)
+			allocatable, err := tb.buildAllocatable(capacity, kubeEnv, ParseEvictionHardOrGetDefault(nil), tc.machineType, physicalMemory)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCpu, allocatable.Cpu().Value())
+			assert.Equal(t, tc.expectedMemory, allocatable.Memory().Value())
+		})
+	}
+}
+
 func TestParseEvictionHard(t *testing.T) {
 	type testCase struct {
 		memory                        string
@@ -798,7 +880,7 @@ func TestExtractLabelsFromKubeEnv(t *testing.T) {
 			var labels map[string]string
 			kubeEnv, err := ParseKubeEnv("test", c.kubeEnvValue)
 			if err == nil {
-				labels, err = extractLabelsFromKubeEnv(kubeEnv)
+				labels, err = kubeEnv.NodeLabels()
 			}
 			assert.Equal(t, c.err, err)
 			if c.err != nil {
@@ -895,7 +977,7 @@ func TestExtractTaintsFromKubeEnv(t *testing.T) {
 			var taints []apiv1.Taint
 			kubeEnv, err := ParseKubeEnv("test", c.kubeEnvValue)
 			if err == nil {
-				taints, err = extractTaintsFromKubeEnv(kubeEnv)
+				taints, err = kubeEnv.NodeTaints()
 			}
 			assert.Equal(t, c.err, err)
 			if c.err != nil {
@@ -993,7 +1075,7 @@ func TestExtractKubeReservedFromKubeEnv(t *testing.T) {
 		var reserved string
 		kubeEnv, err := ParseKubeEnv("test", tc.kubeEnvValue)
 		if err == nil {
-			reserved, err = extractKubeReservedFromKubeEnv(kubeEnv)
+			reserved, err = kubeEnv.KubeReserved()
 		}
 		assert.Equal(t, tc.expectedReserved, reserved)
 		if tc.expectedErr {
@@ -1552,3 +1634,64 @@ func stringifyResourceList(resourceList apiv1.ResourceList) string {
 	}
 	return strings.Join(results, ", ")
 }
+
+func TestBuildNodeFromTemplateWithDisksFromKubeEnv(t *testing.T) {
+	testCases := []struct {
+		name                     string
+		kubeEnv                  string
+		expectedEphemeralStorage int64
+		expectedLocalSSDCount    string
+		expectedErr              bool
+	}{
+		{
+			name:        "no disks declared",
+			kubeEnv:     "AUTOSCALER_ENV_VARS: os_distribution=cos;os=linux\n",
+			expectedErr: true,
+		},
+		{
+			name:                     "boot disk declared",
+			kubeEnv:                  "AUTOSCALER_ENV_VARS: os_distribution=cos;os=linux;boot_disk_size_gb=100\n",
+			expectedEphemeralStorage: 100 * units.GiB,
+		},
+		{
+			name:                     "local SSDs declared",
+			kubeEnv:                  "AUTOSCALER_ENV_VARS: os_distribution=cos;os=linux;BLOCK_EPH_STORAGE_BOOT_DISK=true;local_ssd_count=2;ephemeral_storage_local_ssd_count=2\n",
+			expectedEphemeralStorage: 2 * 375 * units.GiB,
+			expectedLocalSSDCount:    "2",
+		},
+		{
+			name:        "too few local SSDs declared",
+			kubeEnv:     "AUTOSCALER_ENV_VARS: os_distribution=cos;os=linux;BLOCK_EPH_STORAGE_BOOT_DISK=true;local_ssd_count=1;ephemeral_storage_local_ssd_count=2\n",
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tb := &GceTemplateBuilder{}
+			mig := &gceMig{gceRef: GceRef{Name: "some-name", Project: "some-proj", Zone: "us-central1-b"}}
+			template := &gce.InstanceTemplate{
+				Name: "node-name",
+				Properties: &gce.InstanceProperties{
+					Metadata:    &gce.Metadata{Items: []*gce.MetadataItems{{Key: "kube-env", Value: &tc.kubeEnv}}},
+					MachineType: "irrelevant-type",
+				},
+			}
+			kubeEnv, err := ExtractKubeEnv(template)
+			assert.NoError(t, err)
+			migOsInfo, err := tb.MigOsInfo(mig.Id(), kubeEnv)
+			assert.NoError(t, err)
+			localSSDDiskSize := localssdsize.NewSimpleLocalSSDProvider()
+			node, err := tb.BuildNodeFromTemplate(mig, migOsInfo, template, kubeEnv, 1, 1, nil, &GceReserved{}, localSSDDiskSize)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedLocalSSDCount, node.Annotations[LocalSsdCountAnnotation])
+			ephemeralStorageLocalSsdCount := ephemeralStorageLocalSSDCount(kubeEnv)
+			capacity, err := tb.BuildCapacity(migOsInfo, 1, 1, nil, tc.expectedEphemeralStorage, ephemeralStorageLocalSsdCount, nil, &GceReserved{}, nil)
+			assert.NoError(t, err)
+			assertEqualResourceLists(t, "Capacity", capacity, node.Status.Capacity)
+		})
+	}
+}
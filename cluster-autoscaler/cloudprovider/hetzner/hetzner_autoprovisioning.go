@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// autoprovisionedLabel marks servers of node groups created by the autoscaler,
+	// so that the node groups can be discovered again after a restart.
+	autoprovisionedLabel           = hcloudLabelNamespace + "/autoprovisioned"
+	autoprovisionedNodeGroupPrefix = "nap"
+	// autoprovisionedSpecLabelPrefix prefixes the labels of servers of
+	// autoprovisioned node groups holding the labels and taints requested
+	// when the node group was created.
+	autoprovisionedSpecLabelPrefix = hcloudLabelNamespace + "/autoprovisioned-spec-"
+	// maxLabelValueLength is the maximum length of hcloud label values.
+	maxLabelValueLength = 63
+)
+
+// autoprovisionedSpecEncoding only uses alphanumeric characters, which are
+// always valid in hcloud label values.
+var autoprovisionedSpecEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// autoprovisionedSpec holds the labels and taints requested by the autoscaler
+// for an autoprovisioned node group.
+type autoprovisionedSpec struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Taints []apiv1.Taint     `json:"taints,omitempty"`
+}
+
+// AutoprovisioningConfig holds the configuration of node groups created by
+// the autoscaler when node autoprovisioning is enabled
+type AutoprovisioningConfig struct {
+	// Location of the servers of autoprovisioned node groups
+	Location string
+	// MaxSize is the maximum size of each autoprovisioned node group
+	MaxSize int
+	// NodeConfig is the template of the configuration of autoprovisioned node
+	// groups. Labels and taints requested by the autoscaler are added to it.
+	NodeConfig
+}
+
+func (m *hetznerManager) autoprovisioningEnabled() bool {
+	return m.clusterConfig.IsUsingNewFormat && m.clusterConfig.Autoprovisioning != nil
+}
+
+// newAutoprovisionedNodeGroup builds a node group from the autoprovisioning
+// config. The node group isn't registered until it's created.
+func (m *hetznerManager) newAutoprovisionedNodeGroup(id, instanceType, region string, labels map[string]string, taints []apiv1.Taint) *hetznerNodeGroup {
+	template := m.clusterConfig.Autoprovisioning
+	nodeConfig := template.NodeConfig
+	nodeConfig.Labels = cloudprovider.JoinStringMaps(template.Labels, labels)
+	nodeConfig.Taints = append(append([]apiv1.Taint{}, template.Taints...), taints...)
+
+	specLabels, err := autoprovisionedSpecLabels(autoprovisionedSpec{Labels: labels, Taints: taints})
+	if err != nil {
+		klog.Warningf("Failed to encode labels and taints of node group %s, they won't be restored after a restart: %v", id, err)
+	}
+
+	return &hetznerNodeGroup{
+		id:                    id,
+		manager:               m,
+		minSize:               0,
+		maxSize:               template.MaxSize,
+		region:                region,
+		instanceType:          instanceType,
+		autoprovisioned:       true,
+		autoprovisionedConfig: &nodeConfig,
+		autoprovisionedLabels: specLabels,
+	}
+}
+
+// autoprovisionedSpecLabels returns server labels holding the spec. Label
+// values are limited to 63 characters, so the encoded spec is split over
+// labels suffixed by their index.
+func autoprovisionedSpecLabels(spec autoprovisionedSpec) (map[string]string, error) {
+	if len(spec.Labels) == 0 && len(spec.Taints) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	encoded := autoprovisionedSpecEncoding.EncodeToString(data)
+	labels := make(map[string]string)
+	for i := 0; len(encoded) > 0; i++ {
+		n := min(len(encoded), maxLabelValueLength)
+		labels[fmt.Sprintf("%s%d", autoprovisionedSpecLabelPrefix, i)] = encoded[:n]
+		encoded = encoded[n:]
+	}
+	return labels, nil
+}
+
+// parseAutoprovisionedSpec returns the spec held by the server labels, or an
+// empty spec if there's none.
+func parseAutoprovisionedSpec(serverLabels map[string]string) (autoprovisionedSpec, error) {
+	var spec autoprovisionedSpec
+	var encoded strings.Builder
+	for i := 0; ; i++ {
+		chunk, found := serverLabels[fmt.Sprintf("%s%d", autoprovisionedSpecLabelPrefix, i)]
+		if !found {
+			break
+		}
+		encoded.WriteString(chunk)
+	}
+	if encoded.Len() == 0 {
+		return spec, nil
+	}
+	data, err := autoprovisionedSpecEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return spec, err
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return autoprovisionedSpec{}, err
+	}
+	return spec, nil
+}
+
+// autoprovisionedNodeGroupId returns the same id for node groups with the
+// same server type, labels and taints.
+func autoprovisionedNodeGroupId(instanceType string, labels map[string]string, taints []apiv1.Taint) string {
+	var keys []string
+	for key, value := range labels {
+		keys = append(keys, key+"="+value)
+	}
+	sort.Strings(keys)
+	for _, taint := range taints {
+		keys = append(keys, taint.ToString())
+	}
+
+	hash := fnv.New32a()
+	for _, key := range keys {
+		_, _ = hash.Write([]byte(key))
+		_, _ = hash.Write([]byte{0})
+	}
+	return fmt.Sprintf("%s-%s-%08x", autoprovisionedNodeGroupPrefix, instanceType, hash.Sum32())
+}
+
+func (m *hetznerManager) addAutoprovisionedNodeGroup(n *hetznerNodeGroup) {
+	m.nodeGroupsMutex.Lock()
+	defer m.nodeGroupsMutex.Unlock()
+	m.nodeGroups[n.id] = n
+	if m.clusterConfig.NodeConfigs == nil {
+		m.clusterConfig.NodeConfigs = make(map[string]*NodeConfig)
+	}
+	m.clusterConfig.NodeConfigs[n.id] = n.autoprovisionedConfig
+	if len(m.autoprovisioningSSHKeys) > 0 {
+		m.nodeGroupSSHKeys[n.id] = m.autoprovisioningSSHKeys
+	}
+}
+
+func (m *hetznerManager) removeAutoprovisionedNodeGroup(n *hetznerNodeGroup) {
+	m.nodeGroupsMutex.Lock()
+	defer m.nodeGroupsMutex.Unlock()
+	delete(m.nodeGroups, n.id)
+	delete(m.clusterConfig.NodeConfigs, n.id)
+	delete(m.nodeGroupSSHKeys, n.id)
+}
+
+// discoverAutoprovisionedNodeGroups registers autoprovisioned node groups
+// which have servers, but aren't known, e.g. after a restart. Labels and
+// taints requested when they were created are restored from the labels of
+// their servers.
+func (m *hetznerManager) discoverAutoprovisionedNodeGroups() error {
+	if !m.autoprovisioningEnabled() {
+		return nil
+	}
+
+	servers, err := m.cachedServers.getAllServers()
+	if err != nil {
+		return fmt.Errorf("failed to get servers for hcloud: %v", err)
+	}
+
+	for _, server := range servers {
+		id := server.Labels[nodeGroupLabel]
+		if server.Labels[autoprovisionedLabel] != "true" || id == "" {
+			continue
+		}
+		if _, found := m.nodeGroup(id); found {
+			continue
+		}
+		if server.ServerType == nil || server.Datacenter == nil || server.Datacenter.Location == nil {
+			continue
+		}
+
+		spec, err := parseAutoprovisionedSpec(server.Labels)
+		if err != nil {
+			klog.Warningf("Failed to parse labels and taints of node group %s from server %s, they aren't restored: %v", id, server.Name, err)
+		}
+
+		klog.Infof("Discovered autoprovisioned node group %s", id)
+		m.addAutoprovisionedNodeGroup(m.newAutoprovisionedNodeGroup(id, server.ServerType.Name, server.Datacenter.Location.Name, spec.Labels, spec.Taints))
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+)
+
+func newAutoprovisioningTestManager(t *testing.T, servers []schema.Server) *hetznerManager {
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/server_types": jsonResponse(schema.ServerTypeListResponse{
+			ServerTypes: []schema.ServerType{{
+				ID: 1, Name: "cx22", Cores: 2, Memory: 4, Disk: 40, Architecture: string(hcloud.ArchitectureX86),
+				Prices: []schema.PricingServerTypePrice{{Location: "fsn1"}},
+			}},
+		}),
+		"/servers": jsonResponse(schema.ServerListResponse{Servers: servers}),
+	})
+	m.clusterConfig = &ClusterConfig{
+		IsUsingNewFormat: true,
+		NodeConfigs:      map[string]*NodeConfig{},
+		Autoprovisioning: &AutoprovisioningConfig{
+			Location: "fsn1",
+			MaxSize:  5,
+			NodeConfig: NodeConfig{
+				Labels: map[string]string{"autoprovisioned": "true"},
+			},
+		},
+	}
+	return m
+}
+
+func TestNewNodeGroup(t *testing.T) {
+	m := newAutoprovisioningTestManager(t, nil)
+	provider := &HetznerCloudProvider{manager: m}
+	taint := apiv1.Taint{Key: "dedicated", Value: "batch", Effect: apiv1.TaintEffectNoSchedule}
+
+	nodeGroup, err := provider.NewNodeGroup("cx22", map[string]string{"workload": "batch"}, nil, []apiv1.Taint{taint}, nil)
+	require.NoError(t, err)
+	assert.True(t, nodeGroup.Autoprovisioned())
+	assert.False(t, nodeGroup.Exist())
+	assert.Equal(t, 0, nodeGroup.MinSize())
+	assert.Equal(t, 5, nodeGroup.MaxSize())
+
+	sameNodeGroup, err := provider.NewNodeGroup("cx22", map[string]string{"workload": "batch"}, nil, []apiv1.Taint{taint}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, nodeGroup.Id(), sameNodeGroup.Id())
+
+	nodeInfo, err := nodeGroup.TemplateNodeInfo()
+	require.NoError(t, err)
+	node := nodeInfo.Node()
+	assert.Equal(t, "batch", node.Labels["workload"])
+	assert.Equal(t, "true", node.Labels["autoprovisioned"])
+	assert.Equal(t, []apiv1.Taint{taint}, node.Spec.Taints)
+
+	_, err = provider.NewNodeGroup("cx52", nil, nil, nil, nil)
+	assert.Error(t, err)
+
+	m.clusterConfig.Autoprovisioning = nil
+	_, err = provider.NewNodeGroup("cx22", nil, nil, nil, nil)
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+}
+
+func TestCreateAndDeleteNodeGroup(t *testing.T) {
+	m := newAutoprovisioningTestManager(t, nil)
+	provider := &HetznerCloudProvider{manager: m}
+
+	nodeGroup, err := provider.NewNodeGroup("cx22", nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	created, err := nodeGroup.Create()
+	require.NoError(t, err)
+	assert.True(t, created.Exist())
+	assert.Contains(t, m.clusterConfig.NodeConfigs, created.Id())
+
+	_, err = nodeGroup.Create()
+	assert.Error(t, err)
+
+	require.NoError(t, created.Delete())
+	assert.False(t, created.Exist())
+	assert.NotContains(t, m.clusterConfig.NodeConfigs, created.Id())
+
+	static := &hetznerNodeGroup{id: "pool1", manager: m}
+	_, err = static.Create()
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+	assert.Error(t, static.Delete())
+}
+
+func TestDeleteNodeGroupWithServers(t *testing.T) {
+	m := newAutoprovisioningTestManager(t, []schema.Server{{
+		ID:     1,
+		Name:   "nap-cx22-1",
+		Labels: map[string]string{nodeGroupLabel: "nap-cx22", autoprovisionedLabel: "true"},
+	}})
+	nodeGroup := m.newAutoprovisionedNodeGroup("nap-cx22", "cx22", "fsn1", nil, nil)
+	m.addAutoprovisionedNodeGroup(nodeGroup)
+
+	assert.Error(t, nodeGroup.Delete())
+	assert.True(t, nodeGroup.Exist())
+}
+
+func TestDiscoverAutoprovisionedNodeGroups(t *testing.T) {
+	taints := []apiv1.Taint{{Key: "example.com/dedicated-to-a-long-team-name", Value: "batch", Effect: apiv1.TaintEffectNoSchedule}}
+	labels := map[string]string{"example.com/workload-class": "batch-with-a-long-name"}
+	serverLabels, err := autoprovisionedSpecLabels(autoprovisionedSpec{Labels: labels, Taints: taints})
+	require.NoError(t, err)
+	require.Greater(t, len(serverLabels), 1)
+	serverLabels[nodeGroupLabel] = "nap-cx22"
+	serverLabels[autoprovisionedLabel] = "true"
+
+	m := newAutoprovisioningTestManager(t, []schema.Server{
+		{
+			ID:         1,
+			Name:       "nap-cx22-1",
+			ServerType: schema.ServerType{Name: "cx22"},
+			Datacenter: schema.Datacenter{Location: schema.Location{Name: "fsn1"}},
+			Labels:     serverLabels,
+		},
+		{
+			ID:         3,
+			Name:       "nap-cx22-plain-1",
+			ServerType: schema.ServerType{Name: "cx22"},
+			Datacenter: schema.Datacenter{Location: schema.Location{Name: "fsn1"}},
+			Labels:     map[string]string{nodeGroupLabel: "nap-cx22-plain", autoprovisionedLabel: "true"},
+		},
+		{
+			ID:         2,
+			Name:       "pool1-1",
+			ServerType: schema.ServerType{Name: "cx22"},
+			Datacenter: schema.Datacenter{Location: schema.Location{Name: "fsn1"}},
+			Labels:     map[string]string{nodeGroupLabel: "pool1"},
+		},
+	})
+
+	require.NoError(t, m.discoverAutoprovisionedNodeGroups())
+	require.Contains(t, m.nodeGroups, "nap-cx22")
+	assert.NotContains(t, m.nodeGroups, "pool1")
+
+	nodeGroup := m.nodeGroups["nap-cx22"]
+	assert.True(t, nodeGroup.Autoprovisioned())
+	assert.Equal(t, "cx22", nodeGroup.instanceType)
+	assert.Equal(t, "fsn1", nodeGroup.region)
+	assert.Equal(t, map[string]string{"autoprovisioned": "true", "example.com/workload-class": "batch-with-a-long-name"}, nodeGroup.autoprovisionedConfig.Labels)
+	assert.Equal(t, taints, nodeGroup.autoprovisionedConfig.Taints)
+	for key, value := range nodeGroup.autoprovisionedLabels {
+		assert.Equal(t, serverLabels[key], value)
+		assert.LessOrEqual(t, len(value), maxLabelValueLength)
+	}
+
+	require.Contains(t, m.nodeGroups, "nap-cx22-plain")
+	assert.Equal(t, map[string]string{"autoprovisioned": "true"}, m.nodeGroups["nap-cx22-plain"].autoprovisionedConfig.Labels)
+	assert.Empty(t, m.nodeGroups["nap-cx22-plain"].autoprovisionedConfig.Taints)
+	assert.Empty(t, m.nodeGroups["nap-cx22-plain"].autoprovisionedLabels)
+}
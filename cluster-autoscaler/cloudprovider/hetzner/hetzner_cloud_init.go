@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"text/template"
+)
+
+// cloudInitTokenBytes is the number of random bytes of cloud-init tokens.
+const cloudInitTokenBytes = 16
+
+// cloudInitVariables are the per-server variables of cloud-init templates.
+type cloudInitVariables struct {
+	// NodeName is the name of the server, which the node registers as.
+	NodeName string
+	// NodePool is the id of the node group of the server.
+	NodePool string
+	// Region is the location the server is created in.
+	Region string
+	// Token is a random hex token unique to the server.
+	Token string
+}
+
+// parseCloudInitTemplate parses the cloud-init of a node group as a Go template.
+// Referring to variables other than cloudInitVariables fails at execution.
+func parseCloudInitTemplate(nodeGroup, cloudInit string) (*template.Template, error) {
+	return template.New(nodeGroup).Option("missingkey=error").Parse(cloudInit)
+}
+
+// renderCloudInit executes the cloud-init template of a node group with the
+// given variables.
+func renderCloudInit(nodeGroup, cloudInit string, variables cloudInitVariables) (string, error) {
+	tmpl, err := parseCloudInitTemplate(nodeGroup, cloudInit)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse cloud-init template of node group %s: %v", nodeGroup, err)
+	}
+	var userData bytes.Buffer
+	if err := tmpl.Execute(&userData, variables); err != nil {
+		return "", fmt.Errorf("failed to execute cloud-init template of node group %s: %v", nodeGroup, err)
+	}
+	return userData.String(), nil
+}
+
+// newCloudInitToken returns a random token for the cloud-init of a server.
+func newCloudInitToken() (string, error) {
+	token := make([]byte, cloudInitTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate cloud-init token: %v", err)
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderCloudInit(t *testing.T) {
+	variables := cloudInitVariables{NodeName: "pool1-1", NodePool: "pool1", Region: "fsn1", Token: "abc"}
+
+	userData, err := renderCloudInit("pool1", "#cloud-config\nhostname: {{ .NodeName }}\nruncmd: [join --pool {{ .NodePool }} --region {{ .Region }} --token {{ .Token }}]\n", variables)
+	assert.NoError(t, err)
+	assert.Equal(t, "#cloud-config\nhostname: pool1-1\nruncmd: [join --pool pool1 --region fsn1 --token abc]\n", userData)
+
+	_, err = renderCloudInit("pool1", "{{ .Unknown }}", variables)
+	assert.Error(t, err)
+	_, err = renderCloudInit("pool1", "{{ .NodeName ", variables)
+	assert.Error(t, err)
+
+	token, err := newCloudInitToken()
+	assert.NoError(t, err)
+	assert.Len(t, token, 2*cloudInitTokenBytes)
+}
@@ -21,7 +21,6 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -30,6 +29,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/client-go/informers"
 	"k8s.io/klog/v2"
 )
 
@@ -37,14 +37,16 @@ var _ cloudprovider.CloudProvider = (*HetznerCloudProvider)(nil)
 
 const (
 	// GPULabel is the label added to nodes with GPU resource.
-	GPULabel                   = hcloudLabelNamespace + "/gpu-node"
-	providerIDPrefix           = "hcloud://"
-	nodeGroupLabel             = hcloudLabelNamespace + "/node-group"
-	hcloudLabelNamespace       = "hcloud"
-	drainingNodePoolId         = "draining-node-pool"
-	serverCreateTimeoutDefault = 5 * time.Minute
-	serverRegisterTimeout      = 10 * time.Minute
-	defaultPodAmountsLimit     = 110
+	GPULabel                        = hcloudLabelNamespace + "/gpu-node"
+	providerIDPrefix                = "hcloud://"
+	nodeGroupLabel                  = hcloudLabelNamespace + "/node-group"
+	hcloudLabelNamespace            = "hcloud"
+	drainingNodePoolId              = "draining-node-pool"
+	serverCreateTimeoutDefault      = 5 * time.Minute
+	serverCreateRetryBackoffDefault = 5 * time.Second
+	serverCreateRetryBackoffMax     = time.Minute
+	serverRegisterTimeout           = 10 * time.Minute
+	defaultPodAmountsLimit          = 110
 )
 
 // HetznerCloudProvider implements CloudProvider interface.
@@ -60,9 +62,10 @@ func (d *HetznerCloudProvider) Name() string {
 
 // NodeGroups returns all node groups configured for this cloud provider.
 func (d *HetznerCloudProvider) NodeGroups() []cloudprovider.NodeGroup {
-	groups := make([]cloudprovider.NodeGroup, 0, len(d.manager.nodeGroups))
-	for groupId := range d.manager.nodeGroups {
-		groups = append(groups, d.manager.nodeGroups[groupId])
+	nodeGroups := d.manager.allNodeGroups()
+	groups := make([]cloudprovider.NodeGroup, 0, len(nodeGroups))
+	for _, nodeGroup := range nodeGroups {
+		groups = append(groups, nodeGroup)
 	}
 	return groups
 }
@@ -71,6 +74,14 @@ func (d *HetznerCloudProvider) NodeGroups() []cloudprovider.NodeGroup {
 // should not be processed by cluster autoscaler, or non-nil error if such
 // occurred. Must be implemented.
 func (d *HetznerCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	if groupId, found := limitExceededNodeGroup(node.Spec.ProviderID); found {
+		group, exists := d.manager.nodeGroup(groupId)
+		if !exists {
+			return nil, nil
+		}
+		return group, nil
+	}
+
 	server, err := d.manager.serverForNode(node)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if server %s exists error: %v", node.Spec.ProviderID, err)
@@ -92,7 +103,7 @@ func (d *HetznerCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider
 		}
 	}
 
-	group, exists := d.manager.nodeGroups[groupId]
+	group, exists := d.manager.nodeGroup(groupId)
 	if !exists {
 		return nil, nil
 	}
@@ -108,7 +119,7 @@ func (d *HetznerCloudProvider) HasInstance(node *apiv1.Node) (bool, error) {
 // Pricing returns pricing model for this cloud provider or error if not
 // available. Implementation optional.
 func (d *HetznerCloudProvider) Pricing() (cloudprovider.PricingModel, errors.AutoscalerError) {
-	return nil, cloudprovider.ErrNotImplemented
+	return &hetznerPriceModel{manager: d.manager}, nil
 }
 
 // GetAvailableMachineTypes get all machine types that can be requested from
@@ -119,7 +130,7 @@ func (d *HetznerCloudProvider) GetAvailableMachineTypes() ([]string, error) {
 		return nil, err
 	}
 
-	types := make([]string, len(serverTypes))
+	types := make([]string, 0, len(serverTypes))
 	for _, server := range serverTypes {
 		types = append(types, server.Name)
 	}
@@ -138,7 +149,22 @@ func (d *HetznerCloudProvider) NewNodeGroup(
 	taints []apiv1.Taint,
 	extraResources map[string]resource.Quantity,
 ) (cloudprovider.NodeGroup, error) {
-	return nil, cloudprovider.ErrNotImplemented
+	if !d.manager.autoprovisioningEnabled() {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+
+	region := d.manager.clusterConfig.Autoprovisioning.Location
+	available, err := serverTypeAvailable(d.manager, machineType, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if type %s is available in region %s error: %v", machineType, region, err)
+	}
+	if !available {
+		return nil, fmt.Errorf("server type %s not available in region %s", machineType, region)
+	}
+
+	nodeLabels := cloudprovider.JoinStringMaps(labels, systemLabels)
+	id := autoprovisionedNodeGroupId(machineType, nodeLabels, taints)
+	return d.manager.newAutoprovisionedNodeGroup(id, machineType, region, nodeLabels, taints), nil
 }
 
 // GetResourceLimiter returns struct containing limits (max, min) for
@@ -154,7 +180,11 @@ func (d *HetznerCloudProvider) GPULabel() string {
 
 // GetAvailableGPUTypes return all available GPU types cloud provider supports.
 func (d *HetznerCloudProvider) GetAvailableGPUTypes() map[string]struct{} {
-	return nil
+	gpuTypes := make(map[string]struct{})
+	for _, serverTypeGPU := range d.manager.clusterConfig.ServerTypeGPUs {
+		gpuTypes[serverTypeGPU.Type] = struct{}{}
+	}
+	return gpuTypes
 }
 
 // GetNodeGpuConfig returns the label, type and resource name for the GPU added to node. If node doesn't have
@@ -173,30 +203,42 @@ func (d *HetznerCloudProvider) Cleanup() error {
 // update cloud provider state. In particular the list of node groups returned
 // by NodeGroups() can change as a result of CloudProvider.Refresh().
 func (d *HetznerCloudProvider) Refresh() error {
-	for _, group := range d.manager.nodeGroups {
-		group.resetTargetSize(0)
+	if err := d.manager.Refresh(); err != nil {
+		klog.Errorf("failed to refresh servers cache: %v", err)
+	}
+	if err := d.manager.discoverAutoprovisionedNodeGroups(); err != nil {
+		klog.Errorf("failed to discover autoprovisioned node groups: %v", err)
+	}
+	if err := d.manager.deleteUnregisteredServers(time.Now()); err != nil {
+		klog.Errorf("failed to delete unregistered servers: %v", err)
+	}
+	for _, group := range d.manager.allNodeGroups() {
+		group.reconcileTargetSize()
 	}
 	return nil
 }
 
 // BuildHetzner builds the Hetzner cloud provider.
-func BuildHetzner(_ config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter) cloudprovider.CloudProvider {
+func BuildHetzner(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscoveryOptions, rl *cloudprovider.ResourceLimiter, informerFactory informers.SharedInformerFactory) cloudprovider.CloudProvider {
 	manager, err := newManager()
 	if err != nil {
 		klog.Fatalf("Failed to create Hetzner manager: %v", err)
 	}
+	manager.dryRun = opts.DryRun
+	if manager.unregisteredServerTimeout > 0 {
+		manager.nodeLister = informerFactory.Core().V1().Nodes().Lister()
+	}
 
 	provider, err := newHetznerCloudProvider(manager, rl)
 	if err != nil {
 		klog.Fatalf("Failed to create Hetzner cloud provider: %v", err)
 	}
 
-	if manager.clusterConfig.IsUsingNewFormat && len(manager.clusterConfig.NodeConfigs) == 0 {
+	if manager.clusterConfig.IsUsingNewFormat && len(manager.clusterConfig.NodeConfigs) == 0 && manager.clusterConfig.Autoprovisioning == nil {
 		klog.Fatalf("No cluster config present provider: %v", err)
 	}
 
 	validNodePoolName := regexp.MustCompile(`^[a-z0-9A-Z]+[a-z0-9A-Z\-\.\_]*[a-z0-9A-Z]+$|^[a-z0-9A-Z]{1}$`)
-	clusterUpdateLock := sync.Mutex{}
 	for _, nodegroupSpec := range do.NodeGroupSpecs {
 		spec, err := createNodePoolSpec(nodegroupSpec)
 		if err != nil {
@@ -217,14 +259,15 @@ func BuildHetzner(_ config.AutoscalingOptions, do cloudprovider.NodeGroupDiscove
 		}
 
 		manager.nodeGroups[spec.name] = &hetznerNodeGroup{
-			manager:            manager,
-			id:                 spec.name,
-			minSize:            spec.minSize,
-			maxSize:            spec.maxSize,
-			instanceType:       strings.ToLower(spec.instanceType),
-			region:             strings.ToLower(spec.region),
-			targetSize:         len(servers),
-			clusterUpdateMutex: &clusterUpdateLock,
+			manager:      manager,
+			id:           spec.name,
+			minSize:      spec.minSize,
+			maxSize:      spec.maxSize,
+			instanceType: spec.instanceTypes[0],
+			region:       strings.ToLower(spec.region),
+			targetSize:   len(servers),
+
+			fallbackInstanceTypes: spec.instanceTypes[1:],
 		}
 	}
 
@@ -234,13 +277,21 @@ func BuildHetzner(_ config.AutoscalingOptions, do cloudprovider.NodeGroupDiscove
 func createNodePoolSpec(groupSpec string) (*hetznerNodeGroupSpec, error) {
 	tokens := strings.SplitN(groupSpec, ":", 5)
 	if len(tokens) != 5 {
-		return nil, fmt.Errorf("expected format `<min-servers>:<max-servers>:<machine-type>:<region>:<name>` got %s", groupSpec)
+		return nil, fmt.Errorf("expected format `<min-servers>:<max-servers>:<machine-type>[,<machine-type>...]:<region>:<name>` got %s", groupSpec)
 	}
 
 	definition := hetznerNodeGroupSpec{
-		instanceType: tokens[2],
-		region:       tokens[3],
-		name:         tokens[4],
+		region: tokens[3],
+		name:   tokens[4],
+	}
+	// Multiple instance types are tried in order, e.g. `cax31,cpx41`
+	for _, instanceType := range strings.Split(tokens[2], ",") {
+		if instanceType = strings.ToLower(strings.TrimSpace(instanceType)); instanceType != "" {
+			definition.instanceTypes = append(definition.instanceTypes, instanceType)
+		}
+	}
+	if len(definition.instanceTypes) == 0 {
+		return nil, fmt.Errorf("no machine type in %s", groupSpec)
 	}
 	if size, err := strconv.Atoi(tokens[0]); err == nil {
 		definition.minSize = size
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/klog/v2"
+)
+
+// imageRollout tracks the images servers of node groups are created from. A
+// label selector of snapshots resolves to the most recent matching snapshot,
+// so a rebuilt snapshot is picked up by new servers without a restart.
+type imageRollout struct {
+	mutex sync.Mutex
+	// images holds the last image servers were created from by node group and architecture.
+	images map[string]int64
+}
+
+func newImageRollout() *imageRollout {
+	return &imageRollout{
+		images: make(map[string]int64),
+	}
+}
+
+// observe records the image servers of the node group are created from and
+// logs when it changed, e.g. because a new snapshot with the same labels was
+// created.
+func (r *imageRollout) observe(nodeGroup string, architecture hcloud.Architecture, image *hcloud.Image) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := fmt.Sprintf("%s/%s", nodeGroup, architecture)
+	if previous, found := r.images[key]; found && previous != image.ID {
+		klog.Infof("Image of node group %s for architecture %s changed from %d to %d (%s), new servers are created from it", nodeGroup, architecture, previous, image.ID, image.Description)
+	}
+	r.images[key] = image.ID
+}
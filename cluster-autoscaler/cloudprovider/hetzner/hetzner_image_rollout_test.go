@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+)
+
+func TestImageRolloutObserve(t *testing.T) {
+	r := newImageRollout()
+
+	r.observe("pool1", hcloud.ArchitectureX86, &hcloud.Image{ID: 1})
+	r.observe("pool1", hcloud.ArchitectureARM, &hcloud.Image{ID: 3})
+	r.observe("pool2", hcloud.ArchitectureX86, &hcloud.Image{ID: 1})
+	r.observe("pool1", hcloud.ArchitectureX86, &hcloud.Image{ID: 2})
+
+	assert.Equal(t, map[string]int64{
+		"pool1/x86": 2,
+		"pool1/arm": 3,
+		"pool2/x86": 1,
+	}, r.images)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/klog/v2"
+)
+
+// loadBalancerSelector returns the label selector of load balancers which
+// servers of the node group should be registered with. The selector from the
+// node group config takes precedence over HCLOUD_LOAD_BALANCER_SELECTOR.
+func (m *hetznerManager) loadBalancerSelector(nodeGroup string) string {
+	if m.clusterConfig.IsUsingNewFormat {
+		if nodeConfig, found := m.nodeConfig(nodeGroup); found && nodeConfig.LoadBalancerSelector != "" {
+			return nodeConfig.LoadBalancerSelector
+		}
+	}
+	return m.loadBalancerSelectorDefault
+}
+
+func (m *hetznerManager) loadBalancersForNodeGroup(ctx context.Context, nodeGroup string) ([]*hcloud.LoadBalancer, error) {
+	selector := m.loadBalancerSelector(nodeGroup)
+	if selector == "" {
+		return nil, nil
+	}
+	loadBalancers, err := m.client.LoadBalancer.AllWithOpts(ctx, hcloud.LoadBalancerListOpts{
+		ListOpts: hcloud.ListOpts{LabelSelector: selector, PerPage: 50},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list load balancers for selector %s: %v", selector, err)
+	}
+	return loadBalancers, nil
+}
+
+// registerLoadBalancerTargets adds the server as a target of all load
+// balancers configured for its node group. Private IPs are used as targets if
+// the cluster uses a network.
+func (m *hetznerManager) registerLoadBalancerTargets(ctx context.Context, server *hcloud.Server, nodeGroup string) error {
+	loadBalancers, err := m.loadBalancersForNodeGroup(ctx, nodeGroup)
+	if err != nil {
+		return err
+	}
+
+	usePrivateIP := m.network != nil
+	for _, loadBalancer := range loadBalancers {
+		if hasServerTarget(loadBalancer, server) {
+			continue
+		}
+		klog.V(4).Infof("Registering server %s with load balancer %s", server.Name, loadBalancer.Name)
+		action, _, err := m.client.LoadBalancer.AddServerTarget(ctx, loadBalancer, hcloud.LoadBalancerAddServerTargetOpts{
+			Server:       server,
+			UsePrivateIP: &usePrivateIP,
+		})
+		if err != nil {
+			if hcloud.IsError(err, hcloud.ErrorCodeTargetAlreadyDefined) {
+				continue
+			}
+			return fmt.Errorf("failed to register server %s with load balancer %s: %v", server.Name, loadBalancer.Name, err)
+		}
+		if err := m.client.Action.WaitFor(ctx, action); err != nil {
+			return fmt.Errorf("failed to register server %s with load balancer %s: %v", server.Name, loadBalancer.Name, err)
+		}
+	}
+	return nil
+}
+
+// deregisterLoadBalancerTargets removes the server from targets of all load
+// balancers configured for its node group, so that no more traffic is sent to
+// it before it's deleted.
+func (m *hetznerManager) deregisterLoadBalancerTargets(ctx context.Context, server *hcloud.Server) error {
+	loadBalancers, err := m.loadBalancersForNodeGroup(ctx, server.Labels[nodeGroupLabel])
+	if err != nil {
+		return err
+	}
+
+	for _, loadBalancer := range loadBalancers {
+		if !hasServerTarget(loadBalancer, server) {
+			continue
+		}
+		klog.V(4).Infof("Deregistering server %s from load balancer %s", server.Name, loadBalancer.Name)
+		action, _, err := m.client.LoadBalancer.RemoveServerTarget(ctx, loadBalancer, server)
+		if err != nil {
+			return fmt.Errorf("failed to deregister server %s from load balancer %s: %v", server.Name, loadBalancer.Name, err)
+		}
+		if err := m.client.Action.WaitFor(ctx, action); err != nil {
+			return fmt.Errorf("failed to deregister server %s from load balancer %s: %v", server.Name, loadBalancer.Name, err)
+		}
+	}
+	return nil
+}
+
+func hasServerTarget(loadBalancer *hcloud.LoadBalancer, server *hcloud.Server) bool {
+	for _, target := range loadBalancer.Targets {
+		if target.Type == hcloud.LoadBalancerTargetTypeServer && target.Server != nil && target.Server.Server != nil && target.Server.Server.ID == server.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+)
+
+func TestLoadBalancerSelector(t *testing.T) {
+	m := &hetznerManager{
+		clusterConfig: &ClusterConfig{
+			IsUsingNewFormat: true,
+			NodeConfigs: map[string]*NodeConfig{
+				"pool1": {LoadBalancerSelector: "role=ingress"},
+				"pool2": {},
+			},
+		},
+		loadBalancerSelectorDefault: "cluster=test",
+	}
+	assert.Equal(t, "role=ingress", m.loadBalancerSelector("pool1"))
+	assert.Equal(t, "cluster=test", m.loadBalancerSelector("pool2"))
+	assert.Equal(t, "cluster=test", m.loadBalancerSelector("pool3"))
+}
+
+func TestLoadBalancerTargets(t *testing.T) {
+	var labelSelectors, addedTargets, removedTargets []string
+	targets := []schema.LoadBalancerTarget{
+		{Type: "server", Server: &schema.LoadBalancerTargetServer{ID: 2}},
+	}
+	handleTarget := func(targets *[]string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Server struct {
+					ID int64 `json:"id"`
+				} `json:"server"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			*targets = append(*targets, strconv.FormatInt(body.Server.ID, 10))
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(schema.ActionGetResponse{Action: schema.Action{ID: 1, Status: "success"}})
+		}
+	}
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/load_balancers": func(w http.ResponseWriter, r *http.Request) {
+			labelSelectors = append(labelSelectors, r.URL.Query().Get("label_selector"))
+			_ = json.NewEncoder(w).Encode(schema.LoadBalancerListResponse{
+				LoadBalancers: []schema.LoadBalancer{{ID: 1, Name: "lb1", Targets: targets}},
+			})
+		},
+		"/load_balancers/1/actions/add_target":    handleTarget(&addedTargets),
+		"/load_balancers/1/actions/remove_target": handleTarget(&removedTargets),
+	})
+	m.clusterConfig = &ClusterConfig{
+		IsUsingNewFormat: true,
+		NodeConfigs: map[string]*NodeConfig{
+			"pool1": {LoadBalancerSelector: "role=ingress"},
+		},
+	}
+	ctx := context.Background()
+	newServer := &hcloud.Server{ID: 1, Name: "new", Labels: map[string]string{nodeGroupLabel: "pool1"}}
+	registeredServer := &hcloud.Server{ID: 2, Name: "registered", Labels: map[string]string{nodeGroupLabel: "pool1"}}
+	otherServer := &hcloud.Server{ID: 3, Name: "other", Labels: map[string]string{nodeGroupLabel: "pool2"}}
+
+	require.NoError(t, m.registerLoadBalancerTargets(ctx, newServer, "pool1"))
+	require.NoError(t, m.registerLoadBalancerTargets(ctx, registeredServer, "pool1"))
+	assert.Equal(t, []string{"1"}, addedTargets)
+
+	require.NoError(t, m.deregisterLoadBalancerTargets(ctx, newServer))
+	require.NoError(t, m.deregisterLoadBalancerTargets(ctx, registeredServer))
+	assert.Equal(t, []string{"2"}, removedTargets)
+
+	// Servers of node groups without a selector aren't registered.
+	require.NoError(t, m.registerLoadBalancerTargets(ctx, otherServer, "pool2"))
+	require.NoError(t, m.deregisterLoadBalancerTargets(ctx, otherServer))
+	assert.Equal(t, []string{"1"}, addedTargets)
+	assert.Equal(t, []string{"role=ingress", "role=ingress", "role=ingress", "role=ingress"}, labelSelectors)
+}
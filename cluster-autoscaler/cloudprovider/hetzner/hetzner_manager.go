@@ -26,12 +26,15 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
 	"k8s.io/autoscaler/cluster-autoscaler/version"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -43,11 +46,17 @@ var (
 // hetznerManager handles Hetzner communication and data caching of
 // node groups
 type hetznerManager struct {
+	// nodeGroupsMutex guards nodeGroups, clusterConfig.NodeConfigs and
+	// nodeGroupSSHKeys, which change when node groups are autoprovisioned
+	// while servers are created and deleted asynchronously.
+	nodeGroupsMutex sync.RWMutex
+
 	client           *hcloud.Client
 	nodeGroups       map[string]*hetznerNodeGroup
 	apiCallContext   context.Context
 	clusterConfig    *ClusterConfig
 	sshKey           *hcloud.SSHKey
+	nodeGroupSSHKeys map[string][]*hcloud.SSHKey
 	network          *hcloud.Network
 	firewall         *hcloud.Firewall
 	createTimeout    time.Duration
@@ -55,16 +64,58 @@ type hetznerManager struct {
 	publicIPv6       bool
 	cachedServerType *serverTypeCache
 	cachedServers    *serversCache
+
+	loadBalancerSelectorDefault string
+
+	// autoprovisioningSSHKeys are added to servers of autoprovisioned node groups.
+	autoprovisioningSSHKeys []*hcloud.SSHKey
+
+	// createRetries is the number of retries of server creations failing with transient errors.
+	createRetries int
+	// createRetryBackoff is the backoff before the first retry, it's doubled for each further retry.
+	createRetryBackoff time.Duration
+
+	imageRollout *imageRollout
+
+	// shutdownTimeout is the time to wait for servers to power off after a
+	// graceful shutdown before they're deleted, zero disables the shutdown.
+	shutdownTimeout time.Duration
+
+	// rateLimiter throttles requests of the client based on the rate limit headers of the hcloud API.
+	rateLimiter *rateLimiter
+
+	// unregisteredServerTimeout is the time after which servers which didn't register
+	// as nodes are deleted, zero disables the deletion.
+	unregisteredServerTimeout time.Duration
+	// unregisteredServerDeletions tracks deletions of unregistered servers in flight.
+	unregisteredServerDeletions unregisteredServerDeletions
+	// nodeLister lists the nodes servers register as.
+	nodeLister v1lister.NodeLister
+
+	// projectLimits are checked before servers are created.
+	projectLimits projectLimits
+
+	// dryRun makes the background maintenance, i.e. deletion of unregistered
+	// servers and flagging of outdated image servers, only log what it would do.
+	dryRun bool
 }
 
 // ClusterConfig holds the configuration for all the nodepools
 type ClusterConfig struct {
 	ImagesForArch    ImageList
 	NodeConfigs      map[string]*NodeConfig
+	Autoprovisioning *AutoprovisioningConfig
+	ServerTypeGPUs   map[string]ServerTypeGPU
 	IsUsingNewFormat bool
 	LegacyConfig     LegacyConfig
 }
 
+// ServerTypeGPU holds the GPUs attached to servers of a server type
+type ServerTypeGPU struct {
+	Type  string
+	Count int
+}
+
 // ImageList holds the image id/names for the different architectures
 type ImageList struct {
 	Arm64 string
@@ -73,9 +124,16 @@ type ImageList struct {
 
 // NodeConfig holds the configuration for a single nodepool
 type NodeConfig struct {
-	CloudInit string
-	Taints    []apiv1.Taint
-	Labels    map[string]string
+	CloudInit            string
+	ImagesForArch        ImageList
+	SSHKeys              []string
+	Taints               []apiv1.Taint
+	Labels               map[string]string
+	LoadBalancerSelector string
+	FallbackLocations    []string
+	// TemplateCloudInit enables executing CloudInit as a Go template with
+	// per-server variables, see cloudInitVariables.
+	TemplateCloudInit bool
 }
 
 // LegacyConfig holds the configuration in the legacy format
@@ -90,9 +148,20 @@ func newManager() (*hetznerManager, error) {
 		return nil, errors.New("`HCLOUD_TOKEN` is not specified")
 	}
 
+	rateLimitThreshold := rateLimitThresholdDefault
+	rateLimitThresholdStr := os.Getenv("HCLOUD_RATE_LIMIT_THRESHOLD")
+	if rateLimitThresholdStr != "" {
+		v, err := strconv.Atoi(rateLimitThresholdStr)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_RATE_LIMIT_THRESHOLD: %s", rateLimitThresholdStr)
+		}
+		rateLimitThreshold = v
+	}
+	rateLimiter := newRateLimiter(rateLimitThreshold)
+
 	client := hcloud.NewClient(
 		hcloud.WithToken(token),
-		hcloud.WithHTTPClient(httpClient),
+		hcloud.WithHTTPClient(&http.Client{Transport: rateLimiter.roundTripper(httpClient.Transport)}),
 		hcloud.WithApplication("cluster-autoscaler", version.ClusterAutoscalerVersion),
 		hcloud.WithPollBackoffFunc(hcloud.ExponentialBackoff(2, 500*time.Millisecond)),
 		hcloud.WithDebugWriter(&debugWriter{}),
@@ -166,6 +235,38 @@ func newManager() (*hetznerManager, error) {
 		}
 	}
 
+	nodeGroupSSHKeys := make(map[string][]*hcloud.SSHKey)
+	for nodeGroup, nodeConfig := range clusterConfig.NodeConfigs {
+		if nodeConfig.TemplateCloudInit {
+			if _, err := parseCloudInitTemplate(nodeGroup, nodeConfig.CloudInit); err != nil {
+				return nil, fmt.Errorf("failed to parse cloud-init template of node group %s: %v", nodeGroup, err)
+			}
+		}
+		sshKeys, err := getSSHKeys(ctx, client, nodeConfig.SSHKeys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ssh keys of node group %s: %v", nodeGroup, err)
+		}
+		if len(sshKeys) > 0 {
+			nodeGroupSSHKeys[nodeGroup] = sshKeys
+		}
+	}
+
+	var autoprovisioningSSHKeys []*hcloud.SSHKey
+	if autoprovisioning := clusterConfig.Autoprovisioning; autoprovisioning != nil {
+		if autoprovisioning.Location == "" || autoprovisioning.MaxSize <= 0 {
+			return nil, errors.New("autoprovisioning config requires location and a positive maxSize")
+		}
+		if autoprovisioning.TemplateCloudInit {
+			if _, err := parseCloudInitTemplate("autoprovisioning", autoprovisioning.CloudInit); err != nil {
+				return nil, fmt.Errorf("failed to parse cloud-init template of autoprovisioned node groups: %v", err)
+			}
+		}
+		autoprovisioningSSHKeys, err = getSSHKeys(ctx, client, autoprovisioning.SSHKeys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ssh keys of autoprovisioned node groups: %v", err)
+		}
+	}
+
 	var network *hcloud.Network
 	networkIdOrName := os.Getenv("HCLOUD_NETWORK")
 	if networkIdOrName != "" {
@@ -175,6 +276,9 @@ func newManager() (*hetznerManager, error) {
 		}
 
 	}
+	if err := validatePrivateNetwork(network, publicIPv4, publicIPv6); err != nil {
+		return nil, err
+	}
 
 	createTimeout := serverCreateTimeoutDefault
 	v, err := strconv.Atoi(os.Getenv("HCLOUD_SERVER_CREATION_TIMEOUT"))
@@ -182,6 +286,51 @@ func newManager() (*hetznerManager, error) {
 		createTimeout = time.Duration(v) * time.Minute
 	}
 
+	createRetries := 0
+	createRetriesStr := os.Getenv("HCLOUD_SERVER_CREATION_RETRIES")
+	if createRetriesStr != "" {
+		createRetries, err = strconv.Atoi(createRetriesStr)
+		if err != nil || createRetries < 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_SERVER_CREATION_RETRIES: %s", createRetriesStr)
+		}
+	}
+
+	createRetryBackoff := serverCreateRetryBackoffDefault
+	v, err = strconv.Atoi(os.Getenv("HCLOUD_SERVER_CREATION_RETRY_BACKOFF"))
+	if err == nil && v > 0 {
+		createRetryBackoff = time.Duration(v) * time.Second
+	}
+
+	shutdownTimeout := time.Duration(0)
+	shutdownTimeoutStr := os.Getenv("HCLOUD_SERVER_SHUTDOWN_TIMEOUT")
+	if shutdownTimeoutStr != "" {
+		v, err = strconv.Atoi(shutdownTimeoutStr)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_SERVER_SHUTDOWN_TIMEOUT: %s", shutdownTimeoutStr)
+		}
+		shutdownTimeout = time.Duration(v) * time.Second
+	}
+
+	serversCacheTTL := serversCachedTTL
+	serversCacheTTLStr := os.Getenv("HCLOUD_SERVERS_CACHE_TTL")
+	if serversCacheTTLStr != "" {
+		v, err = strconv.Atoi(serversCacheTTLStr)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_SERVERS_CACHE_TTL: %s", serversCacheTTLStr)
+		}
+		serversCacheTTL = time.Duration(v) * time.Second
+	}
+
+	serverTypeCacheTTL := serverTypeCachedTTL
+	serverTypeCacheTTLStr := os.Getenv("HCLOUD_SERVER_TYPES_CACHE_TTL")
+	if serverTypeCacheTTLStr != "" {
+		v, err = strconv.Atoi(serverTypeCacheTTLStr)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_SERVER_TYPES_CACHE_TTL: %s", serverTypeCacheTTLStr)
+		}
+		serverTypeCacheTTL = time.Duration(v) * time.Second
+	}
+
 	var firewall *hcloud.Firewall
 	firewallIdOrName := os.Getenv("HCLOUD_FIREWALL")
 	if firewallIdOrName != "" {
@@ -191,10 +340,39 @@ func newManager() (*hetznerManager, error) {
 		}
 	}
 
+	loadBalancerSelector := os.Getenv("HCLOUD_LOAD_BALANCER_SELECTOR")
+
+	unregisteredServerTimeout := time.Duration(0)
+	unregisteredServerTimeoutStr := os.Getenv("HCLOUD_UNREGISTERED_SERVER_TIMEOUT")
+	if unregisteredServerTimeoutStr != "" {
+		v, err = strconv.Atoi(unregisteredServerTimeoutStr)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_UNREGISTERED_SERVER_TIMEOUT: %s", unregisteredServerTimeoutStr)
+		}
+		unregisteredServerTimeout = time.Duration(v) * time.Minute
+	}
+
+	var limits projectLimits
+	maxServersStr := os.Getenv("HCLOUD_PROJECT_MAX_SERVERS")
+	if maxServersStr != "" {
+		limits.maxServers, err = strconv.Atoi(maxServersStr)
+		if err != nil || limits.maxServers < 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_PROJECT_MAX_SERVERS: %s", maxServersStr)
+		}
+	}
+	maxCoresStr := os.Getenv("HCLOUD_PROJECT_MAX_CORES")
+	if maxCoresStr != "" {
+		limits.maxCores, err = strconv.Atoi(maxCoresStr)
+		if err != nil || limits.maxCores < 0 {
+			return nil, fmt.Errorf("failed to parse HCLOUD_PROJECT_MAX_CORES: %s", maxCoresStr)
+		}
+	}
+
 	m := &hetznerManager{
 		client:           client,
 		nodeGroups:       make(map[string]*hetznerNodeGroup),
 		sshKey:           sshKey,
+		nodeGroupSSHKeys: nodeGroupSSHKeys,
 		network:          network,
 		firewall:         firewall,
 		createTimeout:    createTimeout,
@@ -202,8 +380,18 @@ func newManager() (*hetznerManager, error) {
 		publicIPv4:       publicIPv4,
 		publicIPv6:       publicIPv6,
 		clusterConfig:    clusterConfig,
-		cachedServerType: newServerTypeCache(ctx, client),
-		cachedServers:    newServersCache(ctx, client),
+		cachedServerType: newServerTypeCache(ctx, client, serverTypeCacheTTL),
+		cachedServers:    newServersCache(ctx, client, serversCacheTTL),
+
+		loadBalancerSelectorDefault: loadBalancerSelector,
+		autoprovisioningSSHKeys:     autoprovisioningSSHKeys,
+		createRetries:               createRetries,
+		createRetryBackoff:          createRetryBackoff,
+		imageRollout:                newImageRollout(),
+		shutdownTimeout:             shutdownTimeout,
+		rateLimiter:                 rateLimiter,
+		unregisteredServerTimeout:   unregisteredServerTimeout,
+		projectLimits:               limits,
 	}
 
 	m.nodeGroups[drainingNodePoolId] = &hetznerNodeGroup{
@@ -220,8 +408,48 @@ func newManager() (*hetznerManager, error) {
 }
 
 // Refresh refreshes the cache holding the nodegroups. This is called by the CA
-// based on the `--scan-interval`. By default it's 10 seconds.
+// based on the `--scan-interval`. By default it's 10 seconds. Servers are
+// fetched again if they were changed since the last refresh, otherwise the
+// cached servers are used until they expire.
 func (m *hetznerManager) Refresh() error {
+	return m.cachedServers.refresh()
+}
+
+// nodeGroup returns the node group with the id.
+func (m *hetznerManager) nodeGroup(id string) (*hetznerNodeGroup, bool) {
+	m.nodeGroupsMutex.RLock()
+	defer m.nodeGroupsMutex.RUnlock()
+	nodeGroup, found := m.nodeGroups[id]
+	return nodeGroup, found
+}
+
+// allNodeGroups returns all node groups, including the draining node pool.
+func (m *hetznerManager) allNodeGroups() []*hetznerNodeGroup {
+	m.nodeGroupsMutex.RLock()
+	defer m.nodeGroupsMutex.RUnlock()
+	nodeGroups := make([]*hetznerNodeGroup, 0, len(m.nodeGroups))
+	for _, nodeGroup := range m.nodeGroups {
+		nodeGroups = append(nodeGroups, nodeGroup)
+	}
+	return nodeGroups
+}
+
+// nodeConfig returns the config of the node group, if the new config format is used.
+func (m *hetznerManager) nodeConfig(nodeGroup string) (*NodeConfig, bool) {
+	m.nodeGroupsMutex.RLock()
+	defer m.nodeGroupsMutex.RUnlock()
+	nodeConfig, found := m.clusterConfig.NodeConfigs[nodeGroup]
+	return nodeConfig, found
+}
+
+// serverTypeGPU returns the GPUs attached to servers of the server type, nil if there are none.
+func (m *hetznerManager) serverTypeGPU(instanceType string) *ServerTypeGPU {
+	if m.clusterConfig == nil {
+		return nil
+	}
+	if serverTypeGPU, found := m.clusterConfig.ServerTypeGPUs[instanceType]; found && serverTypeGPU.Count > 0 {
+		return &serverTypeGPU
+	}
 	return nil
 }
 
@@ -234,6 +462,35 @@ func (m *hetznerManager) allServers(nodeGroup string) ([]*hcloud.Server, error)
 	return servers, nil
 }
 
+// sshKeys returns the SSH keys added to servers of the node group. Keys
+// configured for the node group replace the key from HCLOUD_SSH_KEY.
+func (m *hetznerManager) sshKeys(nodeGroup string) []*hcloud.SSHKey {
+	m.nodeGroupsMutex.RLock()
+	defer m.nodeGroupsMutex.RUnlock()
+	if sshKeys, found := m.nodeGroupSSHKeys[nodeGroup]; found {
+		return sshKeys
+	}
+	if m.sshKey != nil {
+		return []*hcloud.SSHKey{m.sshKey}
+	}
+	return nil
+}
+
+func getSSHKeys(ctx context.Context, client *hcloud.Client, sshKeyIdsOrNames []string) ([]*hcloud.SSHKey, error) {
+	var sshKeys []*hcloud.SSHKey
+	for _, sshKeyIdOrName := range sshKeyIdsOrNames {
+		sshKey, _, err := client.SSHKey.Get(ctx, sshKeyIdOrName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ssh key %s error: %s", sshKeyIdOrName, err)
+		}
+		if sshKey == nil {
+			return nil, fmt.Errorf("ssh key %s not found", sshKeyIdOrName)
+		}
+		sshKeys = append(sshKeys, sshKey)
+	}
+	return sshKeys, nil
+}
+
 func (m *hetznerManager) deleteByNode(node *apiv1.Node) error {
 	server, err := m.serverForNode(node)
 	if err != nil {
@@ -244,17 +501,40 @@ func (m *hetznerManager) deleteByNode(node *apiv1.Node) error {
 		return fmt.Errorf("failed to delete node %s server not found", node.Name)
 	}
 
+	if err := m.deregisterLoadBalancerTargets(m.apiCallContext, server); err != nil {
+		return fmt.Errorf("failed to delete node %s error: %v", node.Name, err)
+	}
+
+	if m.shutdownTimeout > 0 {
+		if err := m.shutdownServer(server); err != nil {
+			klog.Warningf("failed to gracefully shut down node %s, deleting it anyway: %v", node.Name, err)
+		}
+	}
+
 	return m.deleteServer(server)
 }
 
 func (m *hetznerManager) deleteServer(server *hcloud.Server) error {
 	_, err := m.client.Server.Delete(m.apiCallContext, server)
+	m.cachedServers.invalidate()
 	return err
 }
 
+// deletePartiallyCreatedServer deletes a server whose creation failed. Servers
+// whose deletion fails are deleted once they exceed the unregistered server timeout.
+func (m *hetznerManager) deletePartiallyCreatedServer(server *hcloud.Server) {
+	if err := m.deleteServer(server); err != nil {
+		klog.Errorf("failed to delete partially created server %s: %v", server.Name, err)
+	}
+}
+
 func (m *hetznerManager) addNodeToDrainingPool(node *apiv1.Node) (*hetznerNodeGroup, error) {
-	m.nodeGroups[drainingNodePoolId].targetSize += 1
-	return m.nodeGroups[drainingNodePoolId], nil
+	drainingNodePool, _ := m.nodeGroup(drainingNodePoolId)
+	drainingNodePool.mutex.Lock()
+	defer drainingNodePool.mutex.Unlock()
+
+	drainingNodePool.targetSize += 1
+	return drainingNodePool, nil
 }
 
 func (m *hetznerManager) validProviderID(providerID string) bool {
@@ -279,5 +559,12 @@ func (m *hetznerManager) serverForNode(node *apiv1.Node) (*hcloud.Server, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get servers for node %s error: %v", node.Name, err)
 	}
+	if server == nil && node.Spec.ProviderID == "" {
+		// Nodes of servers without public IPs may register with their private IP as name.
+		server, err = m.serverForPrivateIP(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get servers for node %s error: %v", node.Name, err)
+		}
+	}
 	return server, nil
 }
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+)
+
+// newTestManager returns a manager using a fake Hetzner Cloud API, which serves
+// requests with the handlers registered by pattern, e.g. "/servers" or
+// "DELETE /servers/1". Requests without a handler fail with 404 Not Found.
+func newTestManager(t *testing.T, handlers map[string]http.HandlerFunc) *hetznerManager {
+	mux := http.NewServeMux()
+	for pattern, handler := range handlers {
+		mux.HandleFunc(pattern, handler)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := hcloud.NewClient(hcloud.WithEndpoint(server.URL))
+	return &hetznerManager{
+		client:           client,
+		apiCallContext:   context.Background(),
+		clusterConfig:    &ClusterConfig{},
+		cachedServers:    newServersCache(context.Background(), client, serversCachedTTL),
+		cachedServerType: newServerTypeCache(context.Background(), client, serverTypeCachedTTL),
+		nodeGroups:       make(map[string]*hetznerNodeGroup),
+		nodeGroupSSHKeys: make(map[string][]*hcloud.SSHKey),
+	}
+}
+
+// jsonResponse returns a handler responding with the response encoded as JSON.
+func jsonResponse(response interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
@@ -32,6 +32,98 @@ import (
 
 const subsystemIdentifier = "api"
 
+var (
+	cacheHitsCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "hcloud_cache_hits_total",
+			Help: "A counter for lookups served from the hcloud API caches per cache.",
+		},
+		[]string{"cache"},
+	)
+
+	cacheMissesCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "hcloud_cache_misses_total",
+			Help: "A counter for lookups fetched from the hcloud API because of empty or expired caches per cache.",
+		},
+		[]string{"cache"},
+	)
+
+	throttledRequestsCounter = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Name: fmt.Sprintf("hcloud_%s_throttled_requests_total", subsystemIdentifier),
+			Help: fmt.Sprintf("A counter for requests to the hcloud %s delayed to avoid exhausting the rate limit.", subsystemIdentifier),
+		},
+	)
+
+	throttledSecondsCounter = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Name: fmt.Sprintf("hcloud_%s_throttled_seconds_total", subsystemIdentifier),
+			Help: fmt.Sprintf("A counter for the time requests to the hcloud %s were delayed to avoid exhausting the rate limit.", subsystemIdentifier),
+		},
+	)
+
+	rateLimitRemainingGauge = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Name: fmt.Sprintf("hcloud_%s_rate_limit_remaining", subsystemIdentifier),
+			Help: fmt.Sprintf("A gauge of the remaining requests to the hcloud %s until the rate limit is exhausted.", subsystemIdentifier),
+		},
+	)
+
+	targetSizeDriftGauge = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Name: "hcloud_node_group_target_size_drift",
+			Help: "A gauge of the difference between the number of servers and the target size per node group at the last reconciliation.",
+		},
+		[]string{"node_group"},
+	)
+
+	targetSizeCorrectionsCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "hcloud_node_group_target_size_corrections_total",
+			Help: "A counter for target sizes corrected to the number of servers per node group.",
+		},
+		[]string{"node_group"},
+	)
+
+	unregisteredServersDeletedCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "hcloud_unregistered_servers_deleted_total",
+			Help: "A counter for servers deleted because they didn't register as nodes in time per node group.",
+		},
+		[]string{"node_group"},
+	)
+
+	unregisteredServerDeletionErrorsCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "hcloud_unregistered_server_deletion_errors_total",
+			Help: "A counter for failed deletions of servers which didn't register as nodes in time per node group.",
+		},
+		[]string{"node_group"},
+	)
+
+	projectLimitExceededCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Name: "hcloud_project_limit_exceeded_servers_total",
+			Help: "A counter for servers not created because they would exceed a project limit per node group and limit.",
+		},
+		[]string{"node_group", "limit"},
+	)
+)
+
+func init() {
+	legacyregistry.MustRegister(cacheHitsCounter)
+	legacyregistry.MustRegister(cacheMissesCounter)
+	legacyregistry.MustRegister(throttledRequestsCounter)
+	legacyregistry.MustRegister(throttledSecondsCounter)
+	legacyregistry.MustRegister(rateLimitRemainingGauge)
+	legacyregistry.MustRegister(targetSizeDriftGauge)
+	legacyregistry.MustRegister(targetSizeCorrectionsCounter)
+	legacyregistry.MustRegister(unregisteredServersDeletedCounter)
+	legacyregistry.MustRegister(unregisteredServerDeletionErrorsCounter)
+	legacyregistry.MustRegister(projectLimitExceededCounter)
+}
+
 func instrumentedRoundTripper() http.RoundTripper {
 	inFlightRequestsGauge := k8smetrics.NewGauge(&k8smetrics.GaugeOpts{
 		Name: fmt.Sprintf("hcloud_%s_in_flight_requests", subsystemIdentifier),
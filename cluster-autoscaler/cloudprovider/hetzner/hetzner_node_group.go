@@ -18,11 +18,13 @@ package hetzner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"math/rand"
 	"strings"
 	"sync"
+	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -30,6 +32,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 	"k8s.io/klog/v2"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 )
@@ -46,15 +49,34 @@ type hetznerNodeGroup struct {
 	region       string
 	instanceType string
 
-	clusterUpdateMutex *sync.Mutex
+	// fallbackInstanceTypes are tried in order on scale-up if instanceType
+	// isn't available in any location of the node group.
+	fallbackInstanceTypes []string
+
+	// autoprovisioned is set for node groups created by the autoscaler.
+	autoprovisioned bool
+	// autoprovisionedConfig is the configuration of an autoprovisioned node
+	// group, it's available before the node group is created.
+	autoprovisionedConfig *NodeConfig
+	// autoprovisionedLabels are added to servers of an autoprovisioned node
+	// group, so that its labels and taints are restored after a restart.
+	autoprovisionedLabels map[string]string
+
+	// limitExceeded are placeholders of servers which weren't created
+	// because of project limits.
+	limitExceeded limitExceededInstances
+
+	// mutex serializes updates of the node group. Node groups are updated
+	// independently, so a slow scale-up of one doesn't block the others.
+	mutex sync.Mutex
 }
 
 type hetznerNodeGroupSpec struct {
-	name         string
-	minSize      int
-	maxSize      int
-	region       string
-	instanceType string
+	name          string
+	minSize       int
+	maxSize       int
+	region        string
+	instanceTypes []string
 }
 
 // MaxSize returns maximum size of the node group.
@@ -97,24 +119,37 @@ func (n *hetznerNodeGroup) IncreaseSize(delta int) error {
 
 	klog.V(4).Infof("Scaling Instance Pool %s to %d", n.id, targetSize)
 
-	n.clusterUpdateMutex.Lock()
-	defer n.clusterUpdateMutex.Unlock()
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	instanceType, locations, err := n.availableInstanceType()
+	if err != nil {
+		return err
+	}
 
-	available, err := serverTypeAvailable(n.manager, n.instanceType, n.region)
+	create := delta
+	capacity, limit, err := n.manager.projectCapacity(instanceType)
 	if err != nil {
-		return fmt.Errorf("failed to check if type %s is available in region %s error: %v", n.instanceType, n.region, err)
+		return err
 	}
-	if !available {
-		return fmt.Errorf("server type %s not available in region %s", n.instanceType, n.region)
+	if capacity < create {
+		create = capacity
+		klog.Warningf("Creating %d instead of %d servers of node group %s, project limit of %s would be exceeded", create, delta, n.id, limit)
+		n.limitExceeded.add(n.id, delta-create, fmt.Sprintf("project limit of %s would be exceeded", limit))
+		projectLimitExceededCounter.WithLabelValues(n.id, limit).Add(float64(delta - create))
 	}
 
 	waitGroup := sync.WaitGroup{}
-	for i := 0; i < delta; i++ {
+	for i := 0; i < create; i++ {
 		waitGroup.Add(1)
 		go func() {
 			defer waitGroup.Done()
-			err := createServer(n)
-			if err != nil {
+			err := createServer(n, instanceType, locations)
+			if err != nil && isProjectLimitExceededError(err) {
+				klog.Warningf("failed to create server of node group %s, project limit exceeded: %v", n.id, err)
+				n.limitExceeded.add(n.id, 1, err.Error())
+				projectLimitExceededCounter.WithLabelValues(n.id, projectLimitAPI).Inc()
+			} else if err != nil {
 				targetSize--
 				klog.Errorf("failed to create error: %v", err)
 			}
@@ -142,17 +177,33 @@ func (n *hetznerNodeGroup) AtomicIncreaseSize(delta int) error {
 // given node doesn't belong to this node group. This function should wait
 // until node group size is updated. Implementation required.
 func (n *hetznerNodeGroup) DeleteNodes(nodes []*apiv1.Node) error {
-	n.clusterUpdateMutex.Lock()
-	defer n.clusterUpdateMutex.Unlock()
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	// Placeholders of servers which weren't created because of project limits
+	// are removed even below the min size, as they never became nodes.
+	var servers []*apiv1.Node
+	limitExceededIds := make(map[string]bool)
+	for _, node := range nodes {
+		if _, found := limitExceededNodeGroup(node.Spec.ProviderID); found {
+			limitExceededIds[node.Spec.ProviderID] = true
+		} else {
+			servers = append(servers, node)
+		}
+	}
 
 	targetSize := n.targetSize - len(nodes)
-	if targetSize < n.MinSize() {
+	if len(servers) > 0 && targetSize < n.MinSize() {
 		return fmt.Errorf("size decrease is too large. current: %d desired: %d min: %d", n.targetSize, targetSize, n.MinSize())
 	}
 
+	if removed := n.limitExceeded.remove(limitExceededIds); removed > 0 {
+		klog.Infof("Removed %d servers of node group %s which weren't created because of project limits", removed, n.id)
+	}
+
 	waitGroup := sync.WaitGroup{}
 
-	for _, node := range nodes {
+	for _, node := range servers {
 		waitGroup.Add(1)
 		go func(node *apiv1.Node) {
 			klog.Infof("Evicting server %s", node.Name)
@@ -183,6 +234,9 @@ func (n *hetznerNodeGroup) DeleteNodes(nodes []*apiv1.Node) error {
 // It is assumed that cloud provider will not delete the existing nodes when there
 // is an option to just decrease the target. Implementation required.
 func (n *hetznerNodeGroup) DecreaseTargetSize(delta int) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
 	n.targetSize = n.targetSize + delta
 	return nil
 }
@@ -208,8 +262,13 @@ func (n *hetznerNodeGroup) Nodes() ([]cloudprovider.Instance, error) {
 
 	instances := make([]cloudprovider.Instance, 0, len(servers))
 	for _, vm := range servers {
-		instances = append(instances, toInstance(vm))
+		instance := toInstance(vm)
+		if status := n.manager.privateNetworkInstanceStatus(vm); status != nil {
+			instance.Status = status
+		}
+		instances = append(instances, instance)
 	}
+	instances = append(instances, n.limitExceeded.list()...)
 
 	return instances, nil
 }
@@ -222,7 +281,13 @@ func (n *hetznerNodeGroup) Nodes() ([]cloudprovider.Instance, error) {
 // that are started on the node by default, using manifest (most likely only
 // kube-proxy). Implementation optional.
 func (n *hetznerNodeGroup) TemplateNodeInfo() (*schedulerframework.NodeInfo, error) {
-	resourceList, err := getMachineTypeResourceList(n.manager, n.instanceType)
+	instanceType, _, err := n.availableInstanceType()
+	if err != nil {
+		klog.V(4).Infof("No instance type of node group %s is available, building template node of %s: %v", n.id, n.instanceType, err)
+		instanceType = n.instanceType
+	}
+
+	resourceList, err := getMachineTypeResourceList(n.manager, instanceType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource list for node group %s error: %v", n.id, err)
 	}
@@ -244,14 +309,14 @@ func (n *hetznerNodeGroup) TemplateNodeInfo() (*schedulerframework.NodeInfo, err
 	node.Status.Allocatable = node.Status.Capacity
 	node.Status.Conditions = cloudprovider.BuildReadyConditions()
 
-	nodeGroupLabels, err := buildNodeGroupLabels(n)
+	nodeGroupLabels, err := buildNodeGroupLabels(n, instanceType)
 	if err != nil {
 		return nil, err
 	}
 	node.Labels = cloudprovider.JoinStringMaps(node.Labels, nodeGroupLabels)
 
-	if n.manager.clusterConfig.IsUsingNewFormat && n.id != drainingNodePoolId {
-		for _, taint := range n.manager.clusterConfig.NodeConfigs[n.id].Taints {
+	if nodeConfig := n.nodeConfig(); nodeConfig != nil {
+		for _, taint := range nodeConfig.Taints {
 			node.Spec.Taints = append(node.Spec.Taints, apiv1.Taint{
 				Key:    taint.Key,
 				Value:  taint.Value,
@@ -270,31 +335,70 @@ func (n *hetznerNodeGroup) TemplateNodeInfo() (*schedulerframework.NodeInfo, err
 // Allows to tell the theoretical node group from the real one. Implementation
 // required.
 func (n *hetznerNodeGroup) Exist() bool {
-	_, exists := n.manager.nodeGroups[n.id]
+	_, exists := n.manager.nodeGroup(n.id)
 	return exists
 }
 
 // Create creates the node group on the cloud provider side. Implementation
 // optional.
 func (n *hetznerNodeGroup) Create() (cloudprovider.NodeGroup, error) {
-	n.manager.nodeGroups[n.id] = n
+	if !n.autoprovisioned {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	if n.Exist() {
+		return nil, fmt.Errorf("node group %s already exists", n.id)
+	}
 
-	return n, cloudprovider.ErrNotImplemented
+	// We do not use actual node groups but all nodes within the Hcloud project are labeled with a group
+	n.manager.addAutoprovisionedNodeGroup(n)
+	klog.Infof("Created autoprovisioned node group %s", n.id)
+
+	return n, nil
 }
 
 // Delete deletes the node group on the cloud provider side.  This will be
 // executed only for autoprovisioned node groups, once their size drops to 0.
 // Implementation optional.
 func (n *hetznerNodeGroup) Delete() error {
-	// We do not use actual node groups but all nodes within the Hcloud project are labeled with a group
+	if !n.autoprovisioned {
+		return fmt.Errorf("node group %s is not autoprovisioned", n.id)
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	servers, err := n.manager.allServers(n.id)
+	if err != nil {
+		return err
+	}
+	if len(servers) > 0 {
+		return fmt.Errorf("node group %s still has %d servers", n.id, len(servers))
+	}
+
+	n.manager.removeAutoprovisionedNodeGroup(n)
+	targetSizeDriftGauge.DeleteLabelValues(n.id)
+	targetSizeCorrectionsCounter.DeleteLabelValues(n.id)
+	klog.Infof("Deleted autoprovisioned node group %s", n.id)
+
 	return nil
 }
 
 // Autoprovisioned returns true if the node group is autoprovisioned. An
 // autoprovisioned group was created by CA and can be deleted when scaled to 0.
 func (n *hetznerNodeGroup) Autoprovisioned() bool {
-	// All groups are auto provisioned
-	return false
+	return n.autoprovisioned
+}
+
+// nodeConfig returns the configuration of the node group, nil if there is none.
+func (n *hetznerNodeGroup) nodeConfig() *NodeConfig {
+	if n.autoprovisionedConfig != nil {
+		return n.autoprovisionedConfig
+	}
+	if !n.manager.clusterConfig.IsUsingNewFormat || n.id == drainingNodePoolId {
+		return nil
+	}
+	nodeConfig, _ := n.manager.nodeConfig(n.id)
+	return nodeConfig
 }
 
 func toInstance(vm *hcloud.Server) cloudprovider.Instance {
@@ -339,23 +443,27 @@ func newNodeName(n *hetznerNodeGroup) string {
 	return fmt.Sprintf("%s-%x", n.id, rand.Int63())
 }
 
-func buildNodeGroupLabels(n *hetznerNodeGroup) (map[string]string, error) {
-	archLabel, err := instanceTypeArch(n.manager, n.instanceType)
+func buildNodeGroupLabels(n *hetznerNodeGroup, instanceType string) (map[string]string, error) {
+	archLabel, err := instanceTypeArch(n.manager, instanceType)
 	if err != nil {
 		return nil, err
 	}
 	klog.V(4).Infof("Build node group label for %s", n.id)
 
 	labels := map[string]string{
-		apiv1.LabelInstanceType:      n.instanceType,
+		apiv1.LabelInstanceType:      instanceType,
 		apiv1.LabelTopologyRegion:    n.region,
 		apiv1.LabelArchStable:        archLabel,
 		"csi.hetzner.cloud/location": n.region,
 		nodeGroupLabel:               n.id,
 	}
 
-	if n.manager.clusterConfig.IsUsingNewFormat && n.id != drainingNodePoolId {
-		maps.Copy(labels, n.manager.clusterConfig.NodeConfigs[n.id].Labels)
+	if serverTypeGPU := n.manager.serverTypeGPU(instanceType); serverTypeGPU != nil {
+		labels[GPULabel] = serverTypeGPU.Type
+	}
+
+	if nodeConfig := n.nodeConfig(); nodeConfig != nil {
+		maps.Copy(labels, nodeConfig.Labels)
 	}
 
 	klog.V(4).Infof("%s nodegroup labels: %s", n.id, labels)
@@ -369,13 +477,18 @@ func getMachineTypeResourceList(m *hetznerManager, instanceType string) (apiv1.R
 		return nil, fmt.Errorf("failed to get machine type %s info error: %v", instanceType, err)
 	}
 
-	return apiv1.ResourceList{
+	resourceList := apiv1.ResourceList{
 		// TODO somehow determine the actual pods that will be running
 		apiv1.ResourcePods:             *resource.NewQuantity(defaultPodAmountsLimit, resource.DecimalSI),
 		apiv1.ResourceCPU:              *resource.NewQuantity(int64(typeInfo.Cores), resource.DecimalSI),
 		apiv1.ResourceMemory:           *resource.NewQuantity(int64(typeInfo.Memory*1024*1024*1024), resource.DecimalSI),
 		apiv1.ResourceEphemeralStorage: *resource.NewQuantity(int64(typeInfo.Disk*1024*1024*1024), resource.DecimalSI),
-	}, nil
+	}
+	if serverTypeGPU := m.serverTypeGPU(instanceType); serverTypeGPU != nil {
+		resourceList[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(int64(serverTypeGPU.Count), resource.DecimalSI)
+	}
+
+	return resourceList, nil
 }
 
 func serverTypeAvailable(manager *hetznerManager, instanceType string, region string) (bool, error) {
@@ -409,11 +522,57 @@ func instanceTypeArch(manager *hetznerManager, instanceType string) (string, err
 	}
 }
 
-func createServer(n *hetznerNodeGroup) error {
+// instanceTypes returns the instance types of the node group in order of preference.
+func (n *hetznerNodeGroup) instanceTypes() []string {
+	return append([]string{n.instanceType}, n.fallbackInstanceTypes...)
+}
+
+// availableInstanceType returns the first instance type of the node group
+// available in any of its locations, along with these locations.
+func (n *hetznerNodeGroup) availableInstanceType() (string, []string, error) {
+	var errs []string
+	for _, instanceType := range n.instanceTypes() {
+		locations, err := n.availableLocations(instanceType)
+		if err == nil {
+			return instanceType, locations, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return "", nil, errors.New(strings.Join(errs, "; "))
+}
+
+// availableLocations returns the location of the node group followed by its
+// fallback locations, skipping the ones the server type isn't available in.
+func (n *hetznerNodeGroup) availableLocations(instanceType string) ([]string, error) {
+	locations := []string{n.region}
+	if nodeConfig := n.nodeConfig(); nodeConfig != nil {
+		locations = append(locations, nodeConfig.FallbackLocations...)
+	}
+
+	var availableLocations []string
+	for _, location := range locations {
+		available, err := serverTypeAvailable(n.manager, instanceType, location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check if type %s is available in region %s error: %v", instanceType, location, err)
+		}
+		if !available {
+			klog.V(4).Infof("server type %s not available in region %s", instanceType, location)
+			continue
+		}
+		availableLocations = append(availableLocations, location)
+	}
+	if len(availableLocations) == 0 {
+		return nil, fmt.Errorf("server type %s not available in regions %s", instanceType, strings.Join(locations, ","))
+	}
+
+	return availableLocations, nil
+}
+
+func createServer(n *hetznerNodeGroup, instanceType string, locations []string) error {
 	ctx, cancel := context.WithTimeout(n.manager.apiCallContext, n.manager.createTimeout)
 	defer cancel()
 
-	serverType, err := n.manager.cachedServerType.getServerType(n.instanceType)
+	serverType, err := n.manager.cachedServerType.getServerType(instanceType)
 	if err != nil {
 		return err
 	}
@@ -422,18 +581,18 @@ func createServer(n *hetznerNodeGroup) error {
 	if err != nil {
 		return err
 	}
+	n.manager.imageRollout.observe(n.id, serverType.Architecture, image)
 
 	cloudInit := n.manager.clusterConfig.LegacyConfig.CloudInit
 
-	if n.manager.clusterConfig.IsUsingNewFormat {
-		cloudInit = n.manager.clusterConfig.NodeConfigs[n.id].CloudInit
+	if nodeConfig := n.nodeConfig(); nodeConfig != nil {
+		cloudInit = nodeConfig.CloudInit
 	}
 
 	StartAfterCreate := true
 	opts := hcloud.ServerCreateOpts{
 		Name:             newNodeName(n),
 		UserData:         cloudInit,
-		Location:         &hcloud.Location{Name: n.region},
 		ServerType:       serverType,
 		Image:            image,
 		StartAfterCreate: &StartAfterCreate,
@@ -445,9 +604,13 @@ func createServer(n *hetznerNodeGroup) error {
 			EnableIPv6: n.manager.publicIPv6,
 		},
 	}
-	if n.manager.sshKey != nil {
-		opts.SSHKeys = []*hcloud.SSHKey{n.manager.sshKey}
+	if n.autoprovisioned {
+		opts.Labels[autoprovisionedLabel] = "true"
+		for key, value := range n.autoprovisionedLabels {
+			opts.Labels[key] = value
+		}
 	}
+	opts.SSHKeys = n.manager.sshKeys(n.id)
 	if n.manager.network != nil {
 		opts.Networks = []*hcloud.Network{n.manager.network}
 	}
@@ -456,9 +619,20 @@ func createServer(n *hetznerNodeGroup) error {
 		opts.Firewalls = []*hcloud.ServerCreateFirewall{serverCreateFirewall}
 	}
 
-	serverCreateResult, _, err := n.manager.client.Server.Create(ctx, opts)
+	backoff := n.manager.createRetryBackoff
+	serverCreateResult, err := createServerInLocations(ctx, n, opts, locations)
+	for retry := 0; err != nil && retry < n.manager.createRetries && isTransientServerCreateError(err); retry++ {
+		klog.Warningf("failed to create server for node group %s, retrying in %v: %v", n.id, backoff, err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("could not create server type %s: %w", instanceType, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, serverCreateRetryBackoffMax)
+		serverCreateResult, err = createServerInLocations(ctx, n, opts, locations)
+	}
 	if err != nil {
-		return fmt.Errorf("could not create server type %s in region %s: %v", n.instanceType, n.region, err)
+		return fmt.Errorf("could not create server type %s: %w", instanceType, err)
 	}
 
 	server := serverCreateResult.Server
@@ -468,13 +642,92 @@ func createServer(n *hetznerNodeGroup) error {
 	// Delete the server if any action (most importantly create_server & start_server) fails
 	err = n.manager.client.Action.WaitFor(ctx, actions...)
 	if err != nil {
-		_ = n.manager.deleteServer(server)
+		n.manager.deletePartiallyCreatedServer(server)
 		return fmt.Errorf("failed to start server %s error: %v", server.Name, err)
 	}
 
+	// Delete the server if it's unreachable without public IPs
+	err = n.manager.checkPrivateIP(ctx, server)
+	if err != nil {
+		n.manager.deletePartiallyCreatedServer(server)
+		return err
+	}
+
+	// Delete the server if it can't receive traffic from the load balancers
+	err = n.manager.registerLoadBalancerTargets(ctx, server, n.id)
+	if err != nil {
+		n.manager.deletePartiallyCreatedServer(server)
+		return err
+	}
+
 	return nil
 }
 
+// createServerInLocations tries to create the server in the locations in order,
+// moving on to the next location if a location has no capacity. If the node
+// group templates its cloud-init, the user data is rendered for each location.
+func createServerInLocations(ctx context.Context, n *hetznerNodeGroup, opts hcloud.ServerCreateOpts, locations []string) (hcloud.ServerCreateResult, error) {
+	var err error
+	cloudInit := opts.UserData
+	templateCloudInit := false
+	var token string
+	if nodeConfig := n.nodeConfig(); nodeConfig != nil && nodeConfig.TemplateCloudInit {
+		templateCloudInit = true
+		if token, err = newCloudInitToken(); err != nil {
+			return hcloud.ServerCreateResult{}, err
+		}
+	}
+	for _, location := range locations {
+		opts.Location = &hcloud.Location{Name: location}
+		if templateCloudInit {
+			opts.UserData, err = renderCloudInit(n.id, cloudInit, cloudInitVariables{
+				NodeName: opts.Name,
+				NodePool: n.id,
+				Region:   location,
+				Token:    token,
+			})
+			if err != nil {
+				return hcloud.ServerCreateResult{}, err
+			}
+		}
+
+		var serverCreateResult hcloud.ServerCreateResult
+		serverCreateResult, _, err = n.manager.client.Server.Create(ctx, opts)
+		if err == nil {
+			n.manager.cachedServers.invalidate()
+			return serverCreateResult, nil
+		}
+		if !isLocationUnavailableError(err) {
+			return hcloud.ServerCreateResult{}, fmt.Errorf("region %s: %w", location, err)
+		}
+		klog.Warningf("failed to create server type %s in region %s, trying next region: %v", n.instanceType, location, err)
+		err = fmt.Errorf("region %s: %w", location, err)
+	}
+	return hcloud.ServerCreateResult{}, err
+}
+
+// isLocationUnavailableError returns true if the server couldn't be created
+// because the location has no capacity for the server type.
+func isLocationUnavailableError(err error) bool {
+	return hcloud.IsError(err, hcloud.ErrorCodeResourceUnavailable) || hcloud.IsError(err, hcloud.ErrorCodePlacementError)
+}
+
+// isTransientServerCreateError returns true if creating the server may succeed
+// when retried later.
+func isTransientServerCreateError(err error) bool {
+	var apiErr hcloud.Error
+	if !errors.As(err, &apiErr) {
+		// Not an error returned by the API, e.g. a network error
+		return !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled)
+	}
+	switch apiErr.Code {
+	case hcloud.ErrorCodeResourceUnavailable, hcloud.ErrorCodePlacementError, hcloud.ErrorCodeRateLimitExceeded,
+		hcloud.ErrorCodeServiceError, hcloud.ErrorCodeLocked, hcloud.ErrorCodeConflict, hcloud.ErrorCodeMaintenance:
+		return true
+	}
+	return false
+}
+
 // findImage searches for an image ID corresponding to the supplied
 // HCLOUD_IMAGE env variable. This value can either be an image ID itself (an
 // int), a name (e.g. "ubuntu-20.04"), or a label selector associated with an
@@ -492,6 +745,17 @@ func findImage(n *hetznerNodeGroup, serverType *hcloud.ServerType) (*hcloud.Imag
 		if serverType.Architecture == hcloud.ArchitectureX86 {
 			imageName = n.manager.clusterConfig.ImagesForArch.Amd64
 		}
+
+		// Images of the node group take precedence over the cluster wide ones
+		if nodeConfig, found := n.manager.nodeConfig(n.id); found {
+			if serverType.Architecture == hcloud.ArchitectureARM && nodeConfig.ImagesForArch.Arm64 != "" {
+				imageName = nodeConfig.ImagesForArch.Arm64
+			}
+
+			if serverType.Architecture == hcloud.ArchitectureX86 && nodeConfig.ImagesForArch.Amd64 != "" {
+				imageName = nodeConfig.ImagesForArch.Amd64
+			}
+		}
 	}
 
 	image, _, err := n.manager.client.Image.GetForArchitecture(context.TODO(), imageName, serverType.Architecture)
@@ -524,13 +788,45 @@ func findImage(n *hetznerNodeGroup, serverType *hcloud.ServerType) (*hcloud.Imag
 	return images[0], nil
 }
 
+// reconcileTargetSize sets the target size to the number of servers of the
+// node group, correcting drift e.g. after failed creations or servers
+// created or deleted outside of the autoscaler. Node groups which are being
+// scaled are skipped, as their servers are expected to differ from the target
+// size until the operation finishes, and waiting for it would block the
+// autoscaler loop.
+func (n *hetznerNodeGroup) reconcileTargetSize() {
+	if !n.mutex.TryLock() {
+		klog.V(4).Infof("Node group %s is being scaled, not reconciling its size", n.id)
+		return
+	}
+	defer n.mutex.Unlock()
+
+	servers, err := n.manager.allServers(n.id)
+	if err != nil {
+		klog.Errorf("failed to reconcile node group %s size: %v", n.id, err)
+		return
+	}
+	// Placeholders of servers which weren't created because of project limits
+	// count towards the target size until the autoscaler removes them.
+	size := len(servers) + n.limitExceeded.len()
+	drift := size - n.targetSize
+	targetSizeDriftGauge.WithLabelValues(n.id).Set(float64(drift))
+	if drift == 0 {
+		return
+	}
+	klog.Warningf("Node group %s has %d servers but target size %d, correcting target size", n.id, size, n.targetSize)
+	targetSizeCorrectionsCounter.WithLabelValues(n.id).Inc()
+	n.targetSize = size
+}
+
 func (n *hetznerNodeGroup) resetTargetSize(expectedDelta int) {
 	servers, err := n.manager.allServers(n.id)
 	if err != nil {
 		klog.Errorf("failed to set node pool %s size, using delta %d error: %v", n.id, expectedDelta, err)
 		n.targetSize = n.targetSize - expectedDelta
 	} else {
-		klog.Infof("Set node group %s size from %d to %d, expected delta %d", n.id, n.targetSize, len(servers), expectedDelta)
-		n.targetSize = len(servers)
+		size := len(servers) + n.limitExceeded.len()
+		klog.Infof("Set node group %s size from %d to %d, expected delta %d", n.id, n.targetSize, size, expectedDelta)
+		n.targetSize = size
 	}
 }
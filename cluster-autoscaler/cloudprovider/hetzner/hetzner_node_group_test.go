@@ -0,0 +1,393 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+)
+
+func TestSSHKeys(t *testing.T) {
+	defaultKey := &hcloud.SSHKey{ID: 1}
+	poolKeys := []*hcloud.SSHKey{{ID: 2}, {ID: 3}}
+	m := &hetznerManager{
+		sshKey:           defaultKey,
+		nodeGroupSSHKeys: map[string][]*hcloud.SSHKey{"pool1": poolKeys},
+	}
+	assert.Equal(t, poolKeys, m.sshKeys("pool1"))
+	assert.Equal(t, []*hcloud.SSHKey{defaultKey}, m.sshKeys("pool2"))
+
+	m.sshKey = nil
+	assert.Nil(t, m.sshKeys("pool2"))
+}
+
+func TestFindImagePerNodeGroup(t *testing.T) {
+	var requestedNames []string
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/images": func(w http.ResponseWriter, r *http.Request) {
+			name := r.URL.Query().Get("name")
+			requestedNames = append(requestedNames, name)
+			_ = json.NewEncoder(w).Encode(schema.ImageListResponse{
+				Images: []schema.Image{{ID: 1, Name: &name, Architecture: string(hcloud.ArchitectureX86)}},
+			})
+		},
+	})
+	m.clusterConfig = &ClusterConfig{
+		IsUsingNewFormat: true,
+		ImagesForArch:    ImageList{Amd64: "ubuntu-22.04", Arm64: "ubuntu-22.04"},
+		NodeConfigs: map[string]*NodeConfig{
+			"pool1": {ImagesForArch: ImageList{Amd64: "debian-12"}},
+			"pool2": {},
+		},
+	}
+	serverType := &hcloud.ServerType{Architecture: hcloud.ArchitectureX86}
+
+	image, err := findImage(&hetznerNodeGroup{id: "pool1", manager: m}, serverType)
+	require.NoError(t, err)
+	assert.Equal(t, "debian-12", image.Name)
+
+	image, err = findImage(&hetznerNodeGroup{id: "pool2", manager: m}, serverType)
+	require.NoError(t, err)
+	assert.Equal(t, "ubuntu-22.04", image.Name)
+	assert.Equal(t, []string{"debian-12", "ubuntu-22.04"}, requestedNames)
+}
+
+func TestTemplateNodeInfoLabelsAndTaints(t *testing.T) {
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/server_types": jsonResponse(schema.ServerTypeListResponse{
+			ServerTypes: []schema.ServerType{{ID: 1, Name: "cx22", Cores: 2, Memory: 4, Disk: 40, Architecture: string(hcloud.ArchitectureX86)}},
+		}),
+	})
+	taint := apiv1.Taint{Key: "dedicated", Value: "batch", Effect: apiv1.TaintEffectNoSchedule}
+	m.clusterConfig = &ClusterConfig{
+		IsUsingNewFormat: true,
+		NodeConfigs: map[string]*NodeConfig{
+			"pool1": {
+				Labels: map[string]string{"workload": "batch"},
+				Taints: []apiv1.Taint{taint},
+			},
+		},
+	}
+	nodeGroup := &hetznerNodeGroup{id: "pool1", manager: m, instanceType: "cx22", region: "fsn1"}
+
+	nodeInfo, err := nodeGroup.TemplateNodeInfo()
+	require.NoError(t, err)
+	node := nodeInfo.Node()
+	assert.Equal(t, "batch", node.Labels["workload"])
+	assert.Equal(t, "pool1", node.Labels[nodeGroupLabel])
+	assert.Equal(t, "cx22", node.Labels[apiv1.LabelInstanceType])
+	assert.Equal(t, []apiv1.Taint{taint}, node.Spec.Taints)
+}
+
+func TestTemplateNodeInfoGPU(t *testing.T) {
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/server_types": jsonResponse(schema.ServerTypeListResponse{
+			ServerTypes: []schema.ServerType{
+				{ID: 1, Name: "cx22", Cores: 2, Memory: 4, Disk: 40, Architecture: string(hcloud.ArchitectureX86)},
+				{ID: 2, Name: "gx11", Cores: 8, Memory: 64, Disk: 240, Architecture: string(hcloud.ArchitectureX86)},
+			},
+		}),
+	})
+	m.clusterConfig.ServerTypeGPUs = map[string]ServerTypeGPU{"gx11": {Type: "nvidia-rtx-4000", Count: 1}}
+	provider := &HetznerCloudProvider{manager: m}
+	assert.Equal(t, map[string]struct{}{"nvidia-rtx-4000": {}}, provider.GetAvailableGPUTypes())
+
+	gpuNodeGroup := &hetznerNodeGroup{id: "gpu", manager: m, instanceType: "gx11", region: "fsn1"}
+	nodeInfo, err := gpuNodeGroup.TemplateNodeInfo()
+	require.NoError(t, err)
+	node := nodeInfo.Node()
+	assert.Equal(t, "nvidia-rtx-4000", node.Labels[GPULabel])
+	gpuCapacity := node.Status.Allocatable[gpu.ResourceNvidiaGPU]
+	assert.Equal(t, int64(1), gpuCapacity.Value())
+	assert.Equal(t, &cloudprovider.GpuConfig{Label: GPULabel, Type: "nvidia-rtx-4000", ResourceName: gpu.ResourceNvidiaGPU}, provider.GetNodeGpuConfig(node))
+
+	cpuNodeGroup := &hetznerNodeGroup{id: "cpu", manager: m, instanceType: "cx22", region: "fsn1"}
+	nodeInfo, err = cpuNodeGroup.TemplateNodeInfo()
+	require.NoError(t, err)
+	node = nodeInfo.Node()
+	assert.NotContains(t, node.Labels, GPULabel)
+	assert.NotContains(t, node.Status.Allocatable, apiv1.ResourceName(gpu.ResourceNvidiaGPU))
+	assert.Nil(t, provider.GetNodeGpuConfig(node))
+}
+
+func TestNodeGroupsUpdatedIndependently(t *testing.T) {
+	pool1 := &hetznerNodeGroup{id: "pool1", targetSize: 3}
+	pool2 := &hetznerNodeGroup{id: "pool2", targetSize: 3}
+
+	// Simulate a slow update of pool1.
+	pool1.mutex.Lock()
+	defer pool1.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, pool2.DecreaseTargetSize(-1))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("update of pool2 blocked by update of pool1")
+	}
+	size, err := pool2.TargetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, size)
+}
+
+func TestAvailableLocations(t *testing.T) {
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/server_types": jsonResponse(schema.ServerTypeListResponse{
+			ServerTypes: []schema.ServerType{{
+				ID: 1, Name: "cx22", Cores: 2, Memory: 4, Disk: 40, Architecture: string(hcloud.ArchitectureX86),
+				Prices: []schema.PricingServerTypePrice{{Location: "fsn1"}, {Location: "hel1"}},
+			}},
+		}),
+	})
+	m.clusterConfig = &ClusterConfig{
+		IsUsingNewFormat: true,
+		NodeConfigs: map[string]*NodeConfig{
+			"pool1": {FallbackLocations: []string{"nbg1", "hel1"}},
+			"pool2": {FallbackLocations: []string{"nbg1"}},
+		},
+	}
+
+	locations, err := (&hetznerNodeGroup{id: "pool1", manager: m, instanceType: "cx22", region: "fsn1"}).availableLocations("cx22")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fsn1", "hel1"}, locations)
+
+	_, err = (&hetznerNodeGroup{id: "pool2", manager: m, instanceType: "cx22", region: "ash"}).availableLocations("cx22")
+	assert.Error(t, err)
+}
+
+func TestAvailableInstanceType(t *testing.T) {
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/server_types": jsonResponse(schema.ServerTypeListResponse{
+			ServerTypes: []schema.ServerType{
+				{
+					ID: 1, Name: "cax31", Cores: 8, Memory: 16, Disk: 160, Architecture: string(hcloud.ArchitectureARM),
+					Prices: []schema.PricingServerTypePrice{{Location: "hel1"}},
+				},
+				{
+					ID: 2, Name: "cpx41", Cores: 8, Memory: 16, Disk: 240, Architecture: string(hcloud.ArchitectureX86),
+					Prices: []schema.PricingServerTypePrice{{Location: "fsn1"}, {Location: "hel1"}},
+				},
+			},
+		}),
+	})
+
+	spec, err := createNodePoolSpec("1:10:CAX31,cpx41:hel1:pool1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cax31", "cpx41"}, spec.instanceTypes)
+
+	nodeGroup := &hetznerNodeGroup{id: "pool1", manager: m, instanceType: "cax31", fallbackInstanceTypes: []string{"cpx41"}, region: "hel1"}
+	instanceType, locations, err := nodeGroup.availableInstanceType()
+	require.NoError(t, err)
+	assert.Equal(t, "cax31", instanceType)
+	assert.Equal(t, []string{"hel1"}, locations)
+
+	nodeGroup.region = "fsn1"
+	instanceType, locations, err = nodeGroup.availableInstanceType()
+	require.NoError(t, err)
+	assert.Equal(t, "cpx41", instanceType)
+	assert.Equal(t, []string{"fsn1"}, locations)
+
+	nodeInfo, err := nodeGroup.TemplateNodeInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "cpx41", nodeInfo.Node().Labels[apiv1.LabelInstanceType])
+	assert.Equal(t, "amd64", nodeInfo.Node().Labels[apiv1.LabelArchStable])
+
+	nodeGroup.region = "nbg1"
+	_, _, err = nodeGroup.availableInstanceType()
+	assert.Error(t, err)
+}
+
+func TestCreateServerInLocations(t *testing.T) {
+	var requestedLocations []string
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"POST /servers": func(w http.ResponseWriter, r *http.Request) {
+			var request schema.ServerCreateRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			requestedLocations = append(requestedLocations, request.Location)
+			w.Header().Set("Content-Type", "application/json")
+
+			switch request.Location {
+			case "fsn1":
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_ = json.NewEncoder(w).Encode(schema.ErrorResponse{Error: schema.Error{Code: string(hcloud.ErrorCodeResourceUnavailable), Message: "unavailable"}})
+			case "nbg1":
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(schema.ErrorResponse{Error: schema.Error{Code: string(hcloud.ErrorCodeInvalidInput), Message: "invalid"}})
+			default:
+				w.WriteHeader(http.StatusCreated)
+				_ = json.NewEncoder(w).Encode(schema.ServerCreateResponse{Server: schema.Server{ID: 1, Name: request.Name}})
+			}
+		},
+	})
+	nodeGroup := &hetznerNodeGroup{id: "pool1", manager: m, instanceType: "cx22"}
+	opts := hcloud.ServerCreateOpts{Name: "pool1-1", ServerType: &hcloud.ServerType{Name: "cx22"}, Image: &hcloud.Image{Name: "ubuntu-22.04"}}
+
+	result, err := createServerInLocations(context.Background(), nodeGroup, opts, []string{"fsn1", "hel1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Server.ID)
+	assert.Equal(t, []string{"fsn1", "hel1"}, requestedLocations)
+	// cached servers are fetched again after the server was created
+	assert.True(t, m.cachedServers.stale)
+
+	m.cachedServers.stale = false
+
+	requestedLocations = nil
+	_, err = createServerInLocations(context.Background(), nodeGroup, opts, []string{"fsn1"})
+	assert.True(t, isLocationUnavailableError(err))
+	assert.True(t, isTransientServerCreateError(err))
+
+	requestedLocations = nil
+	_, err = createServerInLocations(context.Background(), nodeGroup, opts, []string{"nbg1", "hel1"})
+	assert.True(t, hcloud.IsError(err, hcloud.ErrorCodeInvalidInput))
+	assert.False(t, isTransientServerCreateError(err))
+	assert.Equal(t, []string{"nbg1"}, requestedLocations)
+	assert.False(t, m.cachedServers.stale)
+}
+
+func TestCreateServerInLocationsTemplatesCloudInit(t *testing.T) {
+	var userData []string
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"POST /servers": func(w http.ResponseWriter, r *http.Request) {
+			var request schema.ServerCreateRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			userData = append(userData, request.UserData)
+			w.Header().Set("Content-Type", "application/json")
+
+			if request.Location == "fsn1" {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				_ = json.NewEncoder(w).Encode(schema.ErrorResponse{Error: schema.Error{Code: string(hcloud.ErrorCodeResourceUnavailable), Message: "unavailable"}})
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(schema.ServerCreateResponse{Server: schema.Server{ID: 1, Name: request.Name}})
+		},
+	})
+	cloudInit := "name={{ .NodeName }} pool={{ .NodePool }} region={{ .Region }} token={{ .Token }}"
+	m.clusterConfig = &ClusterConfig{
+		IsUsingNewFormat: true,
+		NodeConfigs:      map[string]*NodeConfig{"pool1": {CloudInit: cloudInit, TemplateCloudInit: true}},
+	}
+	nodeGroup := &hetznerNodeGroup{id: "pool1", manager: m, instanceType: "cx22"}
+	opts := hcloud.ServerCreateOpts{Name: "pool1-1", UserData: cloudInit, ServerType: &hcloud.ServerType{Name: "cx22"}, Image: &hcloud.Image{Name: "ubuntu-22.04"}}
+
+	_, err := createServerInLocations(context.Background(), nodeGroup, opts, []string{"fsn1", "hel1"})
+	require.NoError(t, err)
+	require.Len(t, userData, 2)
+	assert.Regexp(t, "^name=pool1-1 pool=pool1 region=fsn1 token=[0-9a-f]{32}$", userData[0])
+	assert.Regexp(t, "^name=pool1-1 pool=pool1 region=hel1 token=[0-9a-f]{32}$", userData[1])
+	// The token is the same in all locations the server is tried in.
+	assert.Equal(t, userData[0][len(userData[0])-32:], userData[1][len(userData[1])-32:])
+
+	userData = nil
+	m.clusterConfig.NodeConfigs["pool1"].TemplateCloudInit = false
+	_, err = createServerInLocations(context.Background(), nodeGroup, opts, []string{"hel1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{cloudInit}, userData)
+}
+
+func TestReconcileTargetSize(t *testing.T) {
+	manager := newTestManager(t, map[string]http.HandlerFunc{
+		"/servers": jsonResponse(schema.ServerListResponse{Servers: []schema.Server{
+			{ID: 1, Name: "pool1-1", Labels: map[string]string{nodeGroupLabel: "pool1"}},
+			{ID: 2, Name: "pool1-2", Labels: map[string]string{nodeGroupLabel: "pool1"}},
+			{ID: 3, Name: "pool2-1", Labels: map[string]string{nodeGroupLabel: "pool2"}},
+		}}),
+	})
+
+	for _, tc := range []struct {
+		name       string
+		nodeGroup  string
+		targetSize int
+		expected   int
+	}{
+		{name: "no drift", nodeGroup: "pool1", targetSize: 2, expected: 2},
+		{name: "failed creation", nodeGroup: "pool1", targetSize: 3, expected: 2},
+		{name: "server created outside of the autoscaler", nodeGroup: "pool2", targetSize: 0, expected: 1},
+		{name: "no servers", nodeGroup: "pool3", targetSize: 1, expected: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			nodeGroup := &hetznerNodeGroup{id: tc.nodeGroup, manager: manager, targetSize: tc.targetSize}
+			nodeGroup.reconcileTargetSize()
+			size, err := nodeGroup.TargetSize()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, size)
+		})
+	}
+}
+
+func TestRefreshDuringDeleteNodes(t *testing.T) {
+	deleting := make(chan struct{})
+	release := make(chan struct{})
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"GET /servers": func(w http.ResponseWriter, r *http.Request) {
+			servers := []schema.Server{
+				{ID: 1, Name: "pool1-1", Labels: map[string]string{nodeGroupLabel: "pool1"}},
+				{ID: 2, Name: "pool1-2", Labels: map[string]string{nodeGroupLabel: "pool1"}},
+			}
+			select {
+			case <-release:
+				servers = servers[1:]
+			default:
+			}
+			_ = json.NewEncoder(w).Encode(schema.ServerListResponse{Servers: servers})
+		},
+		"DELETE /servers/1": func(w http.ResponseWriter, r *http.Request) {
+			close(deleting)
+			<-release
+			_ = json.NewEncoder(w).Encode(schema.ServerDeleteResponse{})
+		},
+	})
+	nodeGroup := &hetznerNodeGroup{id: "pool1", manager: m, maxSize: 10, targetSize: 2}
+	m.nodeGroups[nodeGroup.id] = nodeGroup
+	provider := &HetznerCloudProvider{manager: m}
+
+	deleted := make(chan error)
+	go func() {
+		deleted <- nodeGroup.DeleteNodes([]*apiv1.Node{{Spec: apiv1.NodeSpec{ProviderID: toProviderID(1)}}})
+	}()
+	<-deleting
+
+	refreshed := make(chan error)
+	go func() {
+		refreshed <- provider.Refresh()
+	}()
+	select {
+	case err := <-refreshed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Refresh is blocked by DeleteNodes")
+	}
+	assert.Equal(t, 2, nodeGroup.targetSize)
+
+	close(release)
+	require.NoError(t, <-deleted)
+	assert.Equal(t, 1, nodeGroup.targetSize)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/klog/v2"
+)
+
+// hetznerPriceModel implements the cloudprovider.PricingModel interface with
+// the hourly net prices of server types, in EUR.
+type hetznerPriceModel struct {
+	manager *hetznerManager
+}
+
+var _ cloudprovider.PricingModel = &hetznerPriceModel{}
+
+// NodePrice returns the price of running the server type of the node in the
+// node's location for the given period of time. The price of the cheapest
+// location is used if the node has no location label.
+func (p *hetznerPriceModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	instanceType := node.Labels[apiv1.LabelInstanceTypeStable]
+	if instanceType == "" {
+		instanceType = node.Labels[apiv1.LabelInstanceType]
+	}
+	if instanceType == "" {
+		return 0, fmt.Errorf("node %s has no instance type label", node.Name)
+	}
+	serverType, err := p.manager.cachedServerType.getServerType(instanceType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get server type %s: %v", instanceType, err)
+	}
+	hourlyPrice, err := serverTypeHourlyPrice(serverType, node.Labels[apiv1.LabelTopologyRegion])
+	if err != nil {
+		return 0, err
+	}
+	return hourlyPrice * getHours(startTime, endTime), nil
+}
+
+// PodPrice returns the price of the pod's requests for the given period of
+// time. CPU and memory are priced at the lowest price per core and per GB of
+// all server types, attributing half of a server's price to each, so that a
+// pod costs no more than its share of an efficiently packed server.
+func (p *hetznerPriceModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	corePrice, memoryPrice, err := p.resourcePrices()
+	if err != nil {
+		return 0, err
+	}
+	var cores, memoryGB float64
+	for _, container := range pod.Spec.Containers {
+		cores += float64(container.Resources.Requests.Cpu().MilliValue()) / 1000
+		memoryGB += float64(container.Resources.Requests.Memory().Value()) / (1024 * 1024 * 1024)
+	}
+	return (cores*corePrice + memoryGB*memoryPrice) * getHours(startTime, endTime), nil
+}
+
+// resourcePrices returns the lowest hourly prices per core and per GB of memory.
+func (p *hetznerPriceModel) resourcePrices() (float64, float64, error) {
+	serverTypes, err := p.manager.cachedServerType.getAllServerTypes()
+	if err != nil {
+		return 0, 0, err
+	}
+	corePrice, memoryPrice := math.Inf(1), math.Inf(1)
+	for _, serverType := range serverTypes {
+		if serverType.Cores == 0 || serverType.Memory == 0 {
+			continue
+		}
+		hourlyPrice, err := serverTypeHourlyPrice(serverType, "")
+		if err != nil {
+			klog.V(4).Infof("Skipping server type %s for pod pricing: %v", serverType.Name, err)
+			continue
+		}
+		corePrice = math.Min(corePrice, hourlyPrice/2/float64(serverType.Cores))
+		memoryPrice = math.Min(memoryPrice, hourlyPrice/2/float64(serverType.Memory))
+	}
+	if math.IsInf(corePrice, 1) {
+		return 0, 0, fmt.Errorf("no server type has pricing information")
+	}
+	return corePrice, memoryPrice, nil
+}
+
+// serverTypeHourlyPrice returns the hourly net price of the server type in
+// the location, or in the cheapest location if location is empty.
+func serverTypeHourlyPrice(serverType *hcloud.ServerType, location string) (float64, error) {
+	price := math.Inf(1)
+	for _, pricing := range serverType.Pricings {
+		if pricing.Location == nil || (location != "" && pricing.Location.Name != location) {
+			continue
+		}
+		hourlyPrice, err := strconv.ParseFloat(pricing.Hourly.Net, 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse hourly price %q of server type %s: %v", pricing.Hourly.Net, serverType.Name, err)
+		}
+		price = math.Min(price, hourlyPrice)
+	}
+	if math.IsInf(price, 1) {
+		return 0, fmt.Errorf("no price of server type %s in location %q", serverType.Name, location)
+	}
+	return price, nil
+}
+
+func getHours(startTime time.Time, endTime time.Time) float64 {
+	return endTime.Sub(startTime).Hours()
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestPriceModel(t *testing.T) {
+	hourly := func(location, net string) schema.PricingServerTypePrice {
+		return schema.PricingServerTypePrice{Location: location, PriceHourly: schema.Price{Net: net}}
+	}
+	model := &hetznerPriceModel{manager: newTestManager(t, map[string]http.HandlerFunc{
+		"/server_types": jsonResponse(schema.ServerTypeListResponse{
+			ServerTypes: []schema.ServerType{
+				{ID: 1, Name: "cx22", Cores: 2, Memory: 4, Prices: []schema.PricingServerTypePrice{hourly("fsn1", "0.0060"), hourly("ash", "0.0080")}},
+				{ID: 2, Name: "ccx13", Cores: 2, Memory: 8, Prices: []schema.PricingServerTypePrice{hourly("fsn1", "0.0200")}},
+			},
+		}),
+	})}
+	start := time.Now()
+	end := start.Add(10 * time.Hour)
+
+	newNode := func(instanceType, location string) *apiv1.Node {
+		node := BuildTestNode("node", 2000, 4*1024*1024*1024)
+		node.Labels = map[string]string{apiv1.LabelInstanceTypeStable: instanceType}
+		if location != "" {
+			node.Labels[apiv1.LabelTopologyRegion] = location
+		}
+		return node
+	}
+
+	price, err := model.NodePrice(newNode("cx22", "ash"), start, end)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.08, price, 1e-9)
+
+	// The cheapest location is used for template nodes without location.
+	price, err = model.NodePrice(newNode("cx22", ""), start, end)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.06, price, 1e-9)
+
+	_, err = model.NodePrice(newNode("ccx13", "ash"), start, end)
+	assert.Error(t, err)
+	_, err = model.NodePrice(newNode("unknown", "fsn1"), start, end)
+	assert.Error(t, err)
+	_, err = model.NodePrice(BuildTestNode("unlabeled", 1000, 1000), start, end)
+	assert.Error(t, err)
+
+	// A core costs 0.0015/h and a GB 0.00075/h, half of cx22's price split by its resources.
+	pod := BuildTestPod("pod", 1000, 2*1024*1024*1024)
+	price, err = model.PodPrice(pod, start, end)
+	require.NoError(t, err)
+	assert.InDelta(t, (0.0015+2*0.00075)*10, price, 1e-9)
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/klog/v2"
+)
+
+// networkNotAttachedErrorCode is the error code of instances of servers
+// without public IPs which aren't attached to the network.
+const networkNotAttachedErrorCode = "hcloud-network-not-attached"
+
+// validatePrivateNetwork checks that servers without public IPs are attached
+// to a network, as they are unreachable otherwise. It warns if the network has
+// no default route, as the servers need a NAT gateway to reach the internet,
+// e.g. to pull images, unless they use a proxy.
+func validatePrivateNetwork(network *hcloud.Network, publicIPv4, publicIPv6 bool) error {
+	if publicIPv4 || publicIPv6 {
+		return nil
+	}
+	if network == nil {
+		return errors.New("HCLOUD_NETWORK is required if HCLOUD_PUBLIC_IPV4 and HCLOUD_PUBLIC_IPV6 are disabled, servers without public IPs are unreachable otherwise")
+	}
+	if len(network.Subnets) == 0 {
+		return fmt.Errorf("network %s has no subnets, servers without public IPs can't be assigned private IPs", network.Name)
+	}
+	if !hasDefaultRoute(network) {
+		klog.Warningf("Network %s has no route for 0.0.0.0/0, servers without public IPs can't reach the internet unless a NAT gateway is routed to or a proxy is used", network.Name)
+	}
+	return nil
+}
+
+func hasDefaultRoute(network *hcloud.Network) bool {
+	for _, route := range network.Routes {
+		if route.Destination == nil {
+			continue
+		}
+		if ones, _ := route.Destination.Mask.Size(); ones == 0 && route.Destination.IP.To4() != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// privateNetworkOnly returns true if servers are created without public IPs.
+func (m *hetznerManager) privateNetworkOnly() bool {
+	return !m.publicIPv4 && !m.publicIPv6
+}
+
+// serverPrivateIP returns the IP the network assigned to the server, or nil
+// if the server isn't attached to the network.
+func serverPrivateIP(server *hcloud.Server, network *hcloud.Network) net.IP {
+	if network == nil {
+		return nil
+	}
+	for _, privateNet := range server.PrivateNet {
+		if privateNet.Network != nil && privateNet.Network.ID == network.ID {
+			return privateNet.IP
+		}
+	}
+	return nil
+}
+
+// checkPrivateIP checks that a created server without public IPs was assigned
+// an IP by the network, so that misconfigured node groups fail fast instead of
+// creating servers which never join the cluster.
+func (m *hetznerManager) checkPrivateIP(ctx context.Context, server *hcloud.Server) error {
+	if !m.privateNetworkOnly() || serverPrivateIP(server, m.network) != nil {
+		return nil
+	}
+	// The server returned on creation may not list the network yet.
+	current, _, err := m.client.Server.GetByID(ctx, server.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get server %s: %v", server.Name, err)
+	}
+	if current == nil {
+		return fmt.Errorf("server %s not found after creation", server.Name)
+	}
+	ip := serverPrivateIP(current, m.network)
+	if ip == nil {
+		return fmt.Errorf("server %s without public IPs was not attached to network %s", server.Name, m.network.Name)
+	}
+	klog.V(4).Infof("Server %s was assigned IP %s by network %s", server.Name, ip, m.network.Name)
+	return nil
+}
+
+// privateNetworkInstanceStatus returns an error status for running servers
+// without public IPs which aren't attached to the network, so that they're
+// treated as failed creations and replaced.
+func (m *hetznerManager) privateNetworkInstanceStatus(server *hcloud.Server) *cloudprovider.InstanceStatus {
+	if !m.privateNetworkOnly() || server.Status != hcloud.ServerStatusRunning || serverPrivateIP(server, m.network) != nil {
+		return nil
+	}
+	return &cloudprovider.InstanceStatus{
+		State: cloudprovider.InstanceCreating,
+		ErrorInfo: &cloudprovider.InstanceErrorInfo{
+			ErrorClass:   cloudprovider.OtherErrorClass,
+			ErrorCode:    networkNotAttachedErrorCode,
+			ErrorMessage: fmt.Sprintf("server %s has no public IPs and is not attached to network %s", server.Name, m.network.Name),
+		},
+	}
+}
+
+// serverForPrivateIP returns the server the network assigned one of the
+// internal IPs of the node to, for nodes registered without provider ID and
+// with a name other than the server's.
+func (m *hetznerManager) serverForPrivateIP(node *apiv1.Node) (*hcloud.Server, error) {
+	if m.network == nil {
+		return nil, nil
+	}
+	var nodeIPs []net.IP
+	for _, address := range node.Status.Addresses {
+		if address.Type == apiv1.NodeInternalIP {
+			if ip := net.ParseIP(address.Address); ip != nil {
+				nodeIPs = append(nodeIPs, ip)
+			}
+		}
+	}
+	if len(nodeIPs) == 0 {
+		return nil, nil
+	}
+
+	servers, err := m.cachedServers.getAllServers()
+	if err != nil {
+		return nil, err
+	}
+	for _, server := range servers {
+		serverIP := serverPrivateIP(server, m.network)
+		if serverIP == nil {
+			continue
+		}
+		for _, nodeIP := range nodeIPs {
+			if serverIP.Equal(nodeIP) {
+				return server, nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+)
+
+func TestValidatePrivateNetwork(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	network := &hcloud.Network{ID: 1, Name: "cluster", Subnets: []hcloud.NetworkSubnet{{IPRange: subnet}}}
+
+	assert.NoError(t, validatePrivateNetwork(nil, true, false))
+	assert.NoError(t, validatePrivateNetwork(nil, false, true))
+	assert.Error(t, validatePrivateNetwork(nil, false, false))
+	assert.Error(t, validatePrivateNetwork(&hcloud.Network{ID: 1, Name: "empty"}, false, false))
+	assert.NoError(t, validatePrivateNetwork(network, false, false))
+}
+
+func TestHasDefaultRoute(t *testing.T) {
+	_, defaultRoute, _ := net.ParseCIDR("0.0.0.0/0")
+	_, otherRoute, _ := net.ParseCIDR("10.1.0.0/16")
+
+	assert.False(t, hasDefaultRoute(&hcloud.Network{}))
+	assert.False(t, hasDefaultRoute(&hcloud.Network{Routes: []hcloud.NetworkRoute{{Destination: otherRoute}}}))
+	assert.True(t, hasDefaultRoute(&hcloud.Network{Routes: []hcloud.NetworkRoute{{Destination: otherRoute}, {Destination: defaultRoute}}}))
+}
+
+func newPrivateNetworkTestManager(t *testing.T, servers []schema.Server) *hetznerManager {
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/servers":   jsonResponse(schema.ServerListResponse{Servers: servers}),
+		"/servers/2": jsonResponse(schema.ServerGetResponse{Server: servers[1]}),
+	})
+	m.network = &hcloud.Network{ID: 1, Name: "cluster"}
+	return m
+}
+
+func TestPrivateNetworkServers(t *testing.T) {
+	m := newPrivateNetworkTestManager(t, []schema.Server{
+		{
+			ID:         1,
+			Name:       "pool1-1",
+			Status:     string(hcloud.ServerStatusRunning),
+			PrivateNet: []schema.ServerPrivateNet{{Network: 1, IP: "10.0.0.2"}},
+		},
+		{
+			ID:     2,
+			Name:   "pool1-2",
+			Status: string(hcloud.ServerStatusRunning),
+		},
+	})
+
+	t.Run("server for private IP", func(t *testing.T) {
+		node := &apiv1.Node{Status: apiv1.NodeStatus{Addresses: []apiv1.NodeAddress{
+			{Type: apiv1.NodeHostName, Address: "10.0.0.2"},
+			{Type: apiv1.NodeInternalIP, Address: "10.0.0.2"},
+		}}}
+		node.Name = "ip-10-0-0-2"
+		server, err := m.serverForNode(node)
+		require.NoError(t, err)
+		require.NotNil(t, server)
+		assert.Equal(t, int64(1), server.ID)
+
+		node.Status.Addresses[1].Address = "10.0.0.3"
+		server, err = m.serverForNode(node)
+		require.NoError(t, err)
+		assert.Nil(t, server)
+	})
+
+	t.Run("instance status", func(t *testing.T) {
+		servers, err := m.cachedServers.getAllServers()
+		require.NoError(t, err)
+
+		m.publicIPv4 = true
+		for _, server := range servers {
+			assert.Nil(t, m.privateNetworkInstanceStatus(server))
+		}
+
+		m.publicIPv4 = false
+		assert.Nil(t, m.privateNetworkInstanceStatus(servers[0]))
+		status := m.privateNetworkInstanceStatus(servers[1])
+		require.NotNil(t, status)
+		assert.Equal(t, cloudprovider.InstanceCreating, status.State)
+		assert.Equal(t, networkNotAttachedErrorCode, status.ErrorInfo.ErrorCode)
+	})
+
+	t.Run("check private IP", func(t *testing.T) {
+		servers, err := m.cachedServers.getAllServers()
+		require.NoError(t, err)
+
+		assert.NoError(t, m.checkPrivateIP(context.Background(), servers[0]))
+		assert.Error(t, m.checkPrivateIP(context.Background(), servers[1]))
+	})
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+)
+
+const (
+	// limitExceededProviderIDPrefix is the prefix of the IDs of placeholder
+	// instances of servers which weren't created because of project limits.
+	limitExceededProviderIDPrefix = "hcloud-limit-exceeded://"
+
+	projectLimitServers = "servers"
+	projectLimitCores   = "cores"
+	// projectLimitAPI is reported if the hcloud API refused to create a server
+	// because of a project limit.
+	projectLimitAPI = "api"
+)
+
+// projectLimits are the limits of the hcloud project servers are created in.
+// The hcloud API doesn't expose the limits of a project, so they have to be
+// configured. Zero disables a limit.
+type projectLimits struct {
+	maxServers int
+	maxCores   int
+}
+
+// projectCapacity returns the number of servers of the instance type which can
+// be created without exceeding the project limits, and the limit restricting it.
+// All servers of the project count towards the limits, not only servers of node
+// groups.
+func (m *hetznerManager) projectCapacity(instanceType string) (int, string, error) {
+	if m.projectLimits.maxServers <= 0 && m.projectLimits.maxCores <= 0 {
+		return math.MaxInt, "", nil
+	}
+
+	servers, err := m.cachedServers.getAllServers()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get servers for project limits: %v", err)
+	}
+
+	capacity, limit := math.MaxInt, ""
+	if m.projectLimits.maxServers > 0 {
+		capacity, limit = m.projectLimits.maxServers-len(servers), projectLimitServers
+	}
+	if m.projectLimits.maxCores > 0 {
+		serverType, err := m.cachedServerType.getServerType(instanceType)
+		if err != nil {
+			return 0, "", err
+		}
+		cores := 0
+		for _, server := range servers {
+			if server.ServerType != nil {
+				cores += server.ServerType.Cores
+			}
+		}
+		if serverType.Cores > 0 {
+			if coresCapacity := (m.projectLimits.maxCores - cores) / serverType.Cores; coresCapacity < capacity {
+				capacity, limit = coresCapacity, projectLimitCores
+			}
+		}
+	}
+	return max(capacity, 0), limit, nil
+}
+
+// isProjectLimitExceededError returns true if the server couldn't be created
+// because it would exceed a limit of the project.
+func isProjectLimitExceededError(err error) bool {
+	return hcloud.IsError(err, hcloud.ErrorCodeResourceLimitExceeded)
+}
+
+// limitExceededInstances are placeholder instances of servers of a node group
+// which weren't created because of project limits. They're reported with an
+// out of resources error, so that the autoscaler backs off the node group and
+// removes them again like failed creations.
+type limitExceededInstances struct {
+	mutex     sync.Mutex
+	seq       int
+	instances []cloudprovider.Instance
+}
+
+func (l *limitExceededInstances) add(nodeGroup string, count int, message string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for i := 0; i < count; i++ {
+		l.seq++
+		l.instances = append(l.instances, cloudprovider.Instance{
+			Id: fmt.Sprintf("%s%s/%d", limitExceededProviderIDPrefix, nodeGroup, l.seq),
+			Status: &cloudprovider.InstanceStatus{
+				State: cloudprovider.InstanceCreating,
+				ErrorInfo: &cloudprovider.InstanceErrorInfo{
+					ErrorClass:   cloudprovider.OutOfResourcesErrorClass,
+					ErrorCode:    string(hcloud.ErrorCodeResourceLimitExceeded),
+					ErrorMessage: message,
+				},
+			},
+		})
+	}
+}
+
+func (l *limitExceededInstances) list() []cloudprovider.Instance {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return append([]cloudprovider.Instance(nil), l.instances...)
+}
+
+func (l *limitExceededInstances) len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return len(l.instances)
+}
+
+// remove removes the instances with the given IDs and returns the number of
+// removed instances.
+func (l *limitExceededInstances) remove(ids map[string]bool) int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	remaining := l.instances[:0]
+	for _, instance := range l.instances {
+		if !ids[instance.Id] {
+			remaining = append(remaining, instance)
+		}
+	}
+	removed := len(l.instances) - len(remaining)
+	l.instances = remaining
+	return removed
+}
+
+// limitExceededNodeGroup returns the node group of a placeholder instance of a
+// server which wasn't created because of project limits.
+func limitExceededNodeGroup(providerID string) (string, bool) {
+	if !strings.HasPrefix(providerID, limitExceededProviderIDPrefix) {
+		return "", false
+	}
+	id := strings.TrimPrefix(providerID, limitExceededProviderIDPrefix)
+	separator := strings.LastIndex(id, "/")
+	if separator < 0 {
+		return "", false
+	}
+	return id[:separator], true
+}
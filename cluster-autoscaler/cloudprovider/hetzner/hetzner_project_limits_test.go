@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+)
+
+func newProjectLimitsTestManager(t *testing.T, limits projectLimits) *hetznerManager {
+	cpx21 := schema.ServerType{ID: 1, Name: "cpx21", Cores: 3, Architecture: string(hcloud.ArchitectureX86), Prices: []schema.PricingServerTypePrice{{Location: "fsn1"}}}
+	cpx41 := schema.ServerType{ID: 2, Name: "cpx41", Cores: 8, Architecture: string(hcloud.ArchitectureX86), Prices: []schema.PricingServerTypePrice{{Location: "fsn1"}}}
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"/servers": jsonResponse(schema.ServerListResponse{Servers: []schema.Server{
+			{ID: 1, Name: "pool1-1", ServerType: cpx41, Labels: map[string]string{nodeGroupLabel: "pool1"}},
+			{ID: 2, Name: "pool1-2", ServerType: cpx41, Labels: map[string]string{nodeGroupLabel: "pool1"}},
+			{ID: 3, Name: "database", ServerType: cpx21},
+		}}),
+		"/server_types": jsonResponse(schema.ServerTypeListResponse{ServerTypes: []schema.ServerType{cpx21, cpx41}}),
+	})
+	m.projectLimits = limits
+	return m
+}
+
+func TestProjectCapacity(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		limits           projectLimits
+		instanceType     string
+		expectedCapacity int
+		expectedLimit    string
+	}{
+		{name: "no limits", instanceType: "cpx41", expectedCapacity: math.MaxInt},
+		{name: "servers", limits: projectLimits{maxServers: 5}, instanceType: "cpx41", expectedCapacity: 2, expectedLimit: projectLimitServers},
+		{name: "cores", limits: projectLimits{maxCores: 40}, instanceType: "cpx41", expectedCapacity: 2, expectedLimit: projectLimitCores},
+		{name: "cores of smaller server type", limits: projectLimits{maxCores: 40}, instanceType: "cpx21", expectedCapacity: 7, expectedLimit: projectLimitCores},
+		{name: "stricter limit", limits: projectLimits{maxServers: 10, maxCores: 30}, instanceType: "cpx41", expectedCapacity: 1, expectedLimit: projectLimitCores},
+		{name: "exceeded", limits: projectLimits{maxServers: 2}, instanceType: "cpx41", expectedCapacity: 0, expectedLimit: projectLimitServers},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newProjectLimitsTestManager(t, tc.limits)
+			capacity, limit, err := m.projectCapacity(tc.instanceType)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedCapacity, capacity)
+			assert.Equal(t, tc.expectedLimit, limit)
+		})
+	}
+}
+
+func TestIncreaseSizeExceedingProjectLimits(t *testing.T) {
+	m := newProjectLimitsTestManager(t, projectLimits{maxServers: 3})
+	nodeGroup := &hetznerNodeGroup{id: "pool1", manager: m, instanceType: "cpx41", region: "fsn1", maxSize: 10, targetSize: 2}
+	m.nodeGroups[nodeGroup.id] = nodeGroup
+	provider := &HetznerCloudProvider{manager: m}
+
+	// The project has 3 servers already, so no server is created.
+	require.NoError(t, nodeGroup.IncreaseSize(2))
+	size, err := nodeGroup.TargetSize()
+	require.NoError(t, err)
+	assert.Equal(t, 4, size)
+
+	instances, err := nodeGroup.Nodes()
+	require.NoError(t, err)
+	require.Len(t, instances, 4)
+	var placeholders []*apiv1.Node
+	for _, instance := range instances[2:] {
+		require.NotNil(t, instance.Status)
+		assert.Equal(t, cloudprovider.InstanceCreating, instance.Status.State)
+		require.NotNil(t, instance.Status.ErrorInfo)
+		assert.Equal(t, cloudprovider.OutOfResourcesErrorClass, instance.Status.ErrorInfo.ErrorClass)
+		assert.Equal(t, string(hcloud.ErrorCodeResourceLimitExceeded), instance.Status.ErrorInfo.ErrorCode)
+
+		node := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: instance.Id}}
+		node.Name = instance.Id
+		group, err := provider.NodeGroupForNode(node)
+		require.NoError(t, err)
+		assert.Equal(t, nodeGroup, group)
+		placeholders = append(placeholders, node)
+	}
+
+	// Placeholders count towards the target size until they're removed.
+	nodeGroup.reconcileTargetSize()
+	size, err = nodeGroup.TargetSize()
+	require.NoError(t, err)
+	assert.Equal(t, 4, size)
+
+	require.NoError(t, nodeGroup.DeleteNodes(placeholders))
+	size, err = nodeGroup.TargetSize()
+	require.NoError(t, err)
+	assert.Equal(t, 2, size)
+	instances, err = nodeGroup.Nodes()
+	require.NoError(t, err)
+	assert.Len(t, instances, 2)
+}
+
+func TestLimitExceededNodeGroup(t *testing.T) {
+	nodeGroup, found := limitExceededNodeGroup(limitExceededProviderIDPrefix + "pool1/3")
+	assert.True(t, found)
+	assert.Equal(t, "pool1", nodeGroup)
+
+	_, found = limitExceededNodeGroup(toProviderID(3))
+	assert.False(t, found)
+	_, found = limitExceededNodeGroup(limitExceededProviderIDPrefix + "pool1")
+	assert.False(t, found)
+}
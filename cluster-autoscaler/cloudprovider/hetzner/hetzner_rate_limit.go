@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	rateLimitLimitHeader     = "RateLimit-Limit"
+	rateLimitRemainingHeader = "RateLimit-Remaining"
+	rateLimitResetHeader     = "RateLimit-Reset"
+	// rateLimitThresholdDefault is the number of remaining requests below
+	// which requests to the hcloud API are throttled.
+	rateLimitThresholdDefault = 100
+)
+
+// rateLimiter throttles requests to the hcloud API based on the rate limit
+// headers of its responses. Once the remaining requests drop below the
+// threshold, requests are delayed to the rate the limit is refilled at, so
+// scale events don't exhaust the rate limit.
+type rateLimiter struct {
+	mutex     sync.Mutex
+	threshold int
+	limit     int
+	// remaining is the number of remaining requests, -1 if unknown.
+	remaining int
+	// reset is the time the remaining requests are reset to the limit.
+	reset time.Time
+}
+
+func newRateLimiter(threshold int) *rateLimiter {
+	return &rateLimiter{
+		threshold: threshold,
+		remaining: -1,
+	}
+}
+
+// update records the rate limit headers of the response.
+func (l *rateLimiter) update(resp *http.Response) {
+	limit, err := strconv.Atoi(resp.Header.Get(rateLimitLimitHeader))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(resp.Header.Get(rateLimitRemainingHeader))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get(rateLimitResetHeader), 10, 64)
+	if err != nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.limit = limit
+	l.remaining = remaining
+	l.reset = time.Unix(reset, 0)
+	rateLimitRemainingGauge.Set(float64(remaining))
+}
+
+// delay returns how long the next request should be delayed and reserves
+// one of the remaining requests.
+func (l *rateLimiter) delay(now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.threshold <= 0 || l.remaining < 0 || l.remaining >= l.threshold || !now.Before(l.reset) {
+		return 0
+	}
+
+	untilReset := l.reset.Sub(now)
+	refilled := l.limit - l.remaining
+	if l.remaining > 0 {
+		l.remaining--
+	}
+	if refilled <= 0 {
+		return 0
+	}
+	// The remaining requests are refilled at a constant rate until the reset.
+	return min(untilReset/time.Duration(refilled), untilReset)
+}
+
+// roundTripper returns a round tripper delaying requests while the rate
+// limit is about to be exhausted.
+func (l *rateLimiter) roundTripper(next http.RoundTripper) roundTripperFunc {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if delay := l.delay(time.Now()); delay > 0 {
+			klog.V(4).Infof("Throttling %s request to hcloud API for %v to avoid exhausting the rate limit", r.Method, delay)
+			throttledRequestsCounter.Inc()
+			throttledSecondsCounter.Add(delay.Seconds())
+			select {
+			case <-r.Context().Done():
+				return nil, r.Context().Err()
+			case <-time.After(delay):
+			}
+		}
+
+		resp, err := next.RoundTrip(r)
+		if err == nil {
+			l.update(resp)
+		}
+		return resp, err
+	})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateLimitResponse(limit, remaining int, reset time.Time) *http.Response {
+	header := http.Header{}
+	header.Set(rateLimitLimitHeader, strconv.Itoa(limit))
+	header.Set(rateLimitRemainingHeader, strconv.Itoa(remaining))
+	header.Set(rateLimitResetHeader, strconv.FormatInt(reset.Unix(), 10))
+	return &http.Response{Header: header}
+}
+
+func TestRateLimiterDelay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	testCases := []struct {
+		name          string
+		threshold     int
+		response      *http.Response
+		expectedDelay time.Duration
+	}{
+		{
+			name:          "unknown rate limit",
+			threshold:     100,
+			response:      &http.Response{Header: http.Header{}},
+			expectedDelay: 0,
+		},
+		{
+			name:          "above threshold",
+			threshold:     100,
+			response:      rateLimitResponse(3600, 200, now.Add(3400*time.Second)),
+			expectedDelay: 0,
+		},
+		{
+			name:          "below threshold",
+			threshold:     100,
+			response:      rateLimitResponse(3600, 50, now.Add(3550*time.Second)),
+			expectedDelay: time.Second,
+		},
+		{
+			name:          "exhausted",
+			threshold:     100,
+			response:      rateLimitResponse(3600, 0, now.Add(3600*time.Second)),
+			expectedDelay: time.Second,
+		},
+		{
+			name:          "reset passed",
+			threshold:     100,
+			response:      rateLimitResponse(3600, 0, now.Add(-time.Second)),
+			expectedDelay: 0,
+		},
+		{
+			name:          "disabled",
+			threshold:     0,
+			response:      rateLimitResponse(3600, 0, now.Add(3600*time.Second)),
+			expectedDelay: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newRateLimiter(tc.threshold)
+			l.update(tc.response)
+			assert.Equal(t, tc.expectedDelay, l.delay(now))
+		})
+	}
+}
+
+func TestRateLimiterRoundTripper(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set(rateLimitLimitHeader, "3600")
+		w.Header().Set(rateLimitRemainingHeader, "10")
+		w.Header().Set(rateLimitResetHeader, strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
+	}))
+	defer server.Close()
+
+	l := newRateLimiter(100)
+	client := &http.Client{Transport: l.roundTripper(http.DefaultTransport)}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 10, l.remaining)
+
+	// the second request is throttled as the remaining requests are below the threshold
+	resp, err = client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 10, l.remaining)
+}
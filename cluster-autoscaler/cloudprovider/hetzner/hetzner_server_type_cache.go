@@ -31,6 +31,7 @@ import (
 
 const (
 	serverTypeCacheKey    = "hetzner-server-type-cache"
+	serverTypeCacheName   = "server_types"
 	serverTypeCachedTTL   = time.Minute * 10
 	serverTypeCacheMinTTL = 5
 	serverTypeCacheMaxTTL = 60
@@ -65,7 +66,7 @@ type serverTypeCachedObject struct {
 	serverTypes []*hcloud.ServerType
 }
 
-func newServerTypeCache(ctx context.Context, hcloudClient *hcloud.Client) *serverTypeCache {
+func newServerTypeCache(ctx context.Context, hcloudClient *hcloud.Client, ttl time.Duration) *serverTypeCache {
 	jc := &serverTypeClock{}
 	return newServerTypeCacheWithClock(
 		ctx,
@@ -74,7 +75,7 @@ func newServerTypeCache(ctx context.Context, hcloudClient *hcloud.Client) *serve
 		cache.NewExpirationStore(func(obj interface{}) (s string, e error) {
 			return obj.(serverTypeCachedObject).name, nil
 		}, &cache.TTLPolicy{
-			TTL:   serverTypeCachedTTL,
+			TTL:   ttl,
 			Clock: jc,
 		}),
 	)
@@ -116,10 +117,12 @@ func (m *serverTypeCache) getAllServerTypes() ([]*hcloud.ServerType, error) {
 
 	if obj, found, err := m.GetByKey(serverTypeCacheKey); err == nil && found {
 		foundServerTypes := obj.(serverTypeCachedObject)
+		cacheHitsCounter.WithLabelValues(serverTypeCacheName).Inc()
 
 		return foundServerTypes.serverTypes, nil
 	}
 
+	cacheMissesCounter.WithLabelValues(serverTypeCacheName).Inc()
 	return m.serverTypes()
 }
 
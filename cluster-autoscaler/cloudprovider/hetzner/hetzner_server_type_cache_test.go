@@ -26,7 +26,7 @@ import (
 )
 
 func TestServerTypeCache(t *testing.T) {
-	c := newServerTypeCache(context.Background(), nil)
+	c := newServerTypeCache(context.Background(), nil, serverTypeCachedTTL)
 
 	serverTypes := []*hcloud.ServerType{
 		{
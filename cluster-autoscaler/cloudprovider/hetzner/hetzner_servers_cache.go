@@ -31,6 +31,7 @@ import (
 
 const (
 	serversCacheKey    = "hetzner-servers-cache"
+	serversCacheName   = "servers"
 	serversCachedTTL   = time.Minute * 1
 	serversCacheMinTTL = 5
 	serversCacheMaxTTL = 60
@@ -41,6 +42,12 @@ type serversCache struct {
 	mngJitterClock      clock.Clock
 	hcloudClient        *hcloud.Client
 	hcloudClientContext context.Context
+
+	// refreshMutex is shared by all node groups, so concurrent refreshes don't
+	// overwrite the cache with stale servers.
+	refreshMutex sync.Mutex
+	// stale is set when servers were changed, the cached servers are dropped on the next refresh.
+	stale bool
 }
 
 type serversClock struct {
@@ -65,7 +72,7 @@ type serversCachedObject struct {
 	servers []*hcloud.Server
 }
 
-func newServersCache(ctx context.Context, hcloudClient *hcloud.Client) *serversCache {
+func newServersCache(ctx context.Context, hcloudClient *hcloud.Client, ttl time.Duration) *serversCache {
 	jc := &serversClock{}
 	return newServersCacheWithClock(
 		ctx,
@@ -74,7 +81,7 @@ func newServersCache(ctx context.Context, hcloudClient *hcloud.Client) *serversC
 		cache.NewExpirationStore(func(obj interface{}) (s string, e error) {
 			return obj.(serversCachedObject).name, nil
 		}, &cache.TTLPolicy{
-			TTL:   serversCachedTTL,
+			TTL:   ttl,
 			Clock: jc,
 		}),
 	)
@@ -82,14 +89,17 @@ func newServersCache(ctx context.Context, hcloudClient *hcloud.Client) *serversC
 
 func newServersCacheWithClock(ctx context.Context, hcloudClient *hcloud.Client, jc clock.Clock, store cache.Store) *serversCache {
 	return &serversCache{
-		store,
-		jc,
-		hcloudClient,
-		ctx,
+		Store:               store,
+		mngJitterClock:      jc,
+		hcloudClient:        hcloudClient,
+		hcloudClientContext: ctx,
 	}
 }
 
 func (m *serversCache) servers() ([]*hcloud.Server, error) {
+	m.refreshMutex.Lock()
+	defer m.refreshMutex.Unlock()
+
 	klog.Warning("Fetching servers from Hetzner API")
 
 	servers, err := m.hcloudClient.Server.All(m.hcloudClientContext)
@@ -105,10 +115,35 @@ func (m *serversCache) servers() ([]*hcloud.Server, error) {
 	if err := m.Add(cacheObject); err != nil {
 		return nil, err
 	}
+	m.stale = false
 
 	return servers, nil
 }
 
+// invalidate marks the cached servers as stale after servers were changed.
+func (m *serversCache) invalidate() {
+	m.refreshMutex.Lock()
+	defer m.refreshMutex.Unlock()
+
+	m.stale = true
+}
+
+// refresh drops the cached servers if they're stale, so they're fetched again
+// on the next access.
+func (m *serversCache) refresh() error {
+	m.refreshMutex.Lock()
+	defer m.refreshMutex.Unlock()
+
+	if !m.stale {
+		return nil
+	}
+	m.stale = false
+	if obj, found, err := m.GetByKey(serversCacheKey); err == nil && found {
+		return m.Delete(obj)
+	}
+	return nil
+}
+
 func (m *serversCache) getAllServers() ([]*hcloud.Server, error) {
 	// List expires old entries
 	cacheList := m.List()
@@ -116,10 +151,12 @@ func (m *serversCache) getAllServers() ([]*hcloud.Server, error) {
 
 	if obj, found, err := m.GetByKey(serversCacheKey); err == nil && found {
 		foundServers := obj.(serversCachedObject)
+		cacheHitsCounter.WithLabelValues(serversCacheName).Inc()
 
 		return foundServers.servers, nil
 	}
 
+	cacheMissesCounter.WithLabelValues(serversCacheName).Inc()
 	return m.servers()
 }
 
@@ -26,7 +26,7 @@ import (
 )
 
 func TestServersCache(t *testing.T) {
-	c := newServersCache(context.Background(), nil)
+	c := newServersCache(context.Background(), nil, serversCachedTTL)
 
 	// add initial cache entry, to test that it will be replaced
 	serversOld := []*hcloud.Server{
@@ -74,3 +74,24 @@ func TestServersCache(t *testing.T) {
 	require.Nil(t, server)
 	require.NoError(t, err)
 }
+
+func TestServersCacheRefresh(t *testing.T) {
+	c := newServersCache(context.Background(), nil, serversCachedTTL)
+	require.NoError(t, c.Add(serversCachedObject{
+		name:    serversCacheKey,
+		servers: []*hcloud.Server{{Name: "test1"}},
+	}))
+
+	// cached servers are kept until they're invalidated
+	require.NoError(t, c.refresh())
+	_, found, err := c.GetByKey(serversCacheKey)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	c.invalidate()
+	require.NoError(t, c.refresh())
+	_, found, err = c.GetByKey(serversCacheKey)
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.False(t, c.stale)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+)
+
+// serverShutdownPollInterval is the interval between checks whether a server
+// which was shut down is powered off.
+const serverShutdownPollInterval = 2 * time.Second
+
+// shutdownServer gracefully shuts down the server and waits until it's powered
+// off, so that shutdown hooks of workloads on the node can finish before the
+// server is deleted. It gives up after the configured shutdown timeout.
+func (m *hetznerManager) shutdownServer(server *hcloud.Server) error {
+	if server.Status == hcloud.ServerStatusOff {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(m.apiCallContext, m.shutdownTimeout)
+	defer cancel()
+
+	action, _, err := m.client.Server.Shutdown(ctx, server)
+	if err != nil {
+		return fmt.Errorf("failed to shut down server %s error: %v", server.Name, err)
+	}
+	if err := m.client.Action.WaitFor(ctx, action); err != nil {
+		return fmt.Errorf("failed to shut down server %s error: %v", server.Name, err)
+	}
+
+	for {
+		current, _, err := m.client.Server.GetByID(ctx, server.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get server %s error: %v", server.Name, err)
+		}
+		if current == nil || current.Status == hcloud.ServerStatusOff {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("server %s not powered off within %v", server.Name, m.shutdownTimeout)
+		case <-time.After(serverShutdownPollInterval):
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+)
+
+func TestShutdownServer(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         hcloud.ServerStatus
+		statusAfter    string
+		expectShutdown bool
+		expectErr      bool
+	}{
+		{
+			name:           "powered off",
+			status:         hcloud.ServerStatusRunning,
+			statusAfter:    "off",
+			expectShutdown: true,
+		},
+		{
+			name:           "already off",
+			status:         hcloud.ServerStatusOff,
+			expectShutdown: false,
+		},
+		{
+			name:           "timeout",
+			status:         hcloud.ServerStatusRunning,
+			statusAfter:    "running",
+			expectShutdown: true,
+			expectErr:      true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			shutdown := false
+			m := newTestManager(t, map[string]http.HandlerFunc{
+				"/servers/1/actions/shutdown": func(w http.ResponseWriter, r *http.Request) {
+					shutdown = true
+					w.WriteHeader(http.StatusCreated)
+					_ = json.NewEncoder(w).Encode(schema.ServerActionShutdownResponse{Action: schema.Action{ID: 1, Status: "success"}})
+				},
+				"/servers/1": jsonResponse(schema.ServerGetResponse{Server: schema.Server{ID: 1, Name: "pool1-1", Status: tc.statusAfter}}),
+			})
+			m.shutdownTimeout = 100 * time.Millisecond
+			err := m.shutdownServer(&hcloud.Server{ID: 1, Name: "pool1-1", Status: tc.status})
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectShutdown, shutdown)
+		})
+	}
+}
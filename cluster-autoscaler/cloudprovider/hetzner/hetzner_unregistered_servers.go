@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/klog/v2"
+)
+
+// unregisteredServerDeleteTimeout is the time to wait for the deletion of an
+// unregistered server to complete.
+const unregisteredServerDeleteTimeout = time.Minute
+
+// unregisteredServerDeletions tracks deletions of unregistered servers running
+// in the background.
+type unregisteredServerDeletions struct {
+	mutex    sync.Mutex
+	inFlight map[int64]bool
+}
+
+// start marks the deletion of the server as in flight, returns false if it
+// already is.
+func (d *unregisteredServerDeletions) start(id int64) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.inFlight == nil {
+		d.inFlight = make(map[int64]bool)
+	}
+	if d.inFlight[id] {
+		return false
+	}
+	d.inFlight[id] = true
+	return true
+}
+
+func (d *unregisteredServerDeletions) finish(id int64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.inFlight, id)
+}
+
+// deleteUnregisteredServers deletes servers of node groups which didn't register
+// as nodes within the unregistered server timeout after their creation, e.g.
+// partially created servers whose deletion failed after their creation timed out.
+// Servers are deleted in the background, so that Refresh isn't blocked.
+func (m *hetznerManager) deleteUnregisteredServers(now time.Time) error {
+	if m.nodeLister == nil || m.unregisteredServerTimeout <= 0 {
+		return nil
+	}
+
+	nodes, err := m.nodeLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %v", err)
+	}
+	// Nodes are matched to servers the same way as everywhere else, including
+	// nodes registered with their private IP and without provider ID.
+	registered := make(map[int64]bool, len(nodes))
+	for _, node := range nodes {
+		server, err := m.serverForNode(node)
+		if err != nil {
+			return err
+		}
+		if server != nil {
+			registered[server.ID] = true
+		}
+	}
+
+	for _, nodeGroup := range m.allNodeGroups() {
+		id := nodeGroup.id
+		if id == drainingNodePoolId {
+			continue
+		}
+
+		servers, err := m.allServers(id)
+		if err != nil {
+			return err
+		}
+		for _, server := range servers {
+			if registered[server.ID] || server.Status == hcloud.ServerStatusDeleting ||
+				now.Sub(server.Created) < m.unregisteredServerTimeout {
+				continue
+			}
+			if m.dryRun {
+				klog.Infof("Dry run: would delete server %s of node group %s, it didn't register as a node within %v after its creation", server.Name, id, m.unregisteredServerTimeout)
+				continue
+			}
+			if !m.unregisteredServerDeletions.start(server.ID) {
+				continue
+			}
+
+			klog.Warningf("Deleting server %s of node group %s, it didn't register as a node within %v after its creation", server.Name, id, m.unregisteredServerTimeout)
+			go func(server *hcloud.Server, nodeGroupId string) {
+				defer m.unregisteredServerDeletions.finish(server.ID)
+				if err := m.deleteServerAndWait(server); err != nil {
+					klog.Errorf("failed to delete unregistered server %s: %v", server.Name, err)
+					unregisteredServerDeletionErrorsCounter.WithLabelValues(nodeGroupId).Inc()
+					return
+				}
+				unregisteredServersDeletedCounter.WithLabelValues(nodeGroupId).Inc()
+			}(server, id)
+		}
+	}
+	return nil
+}
+
+// deleteServerAndWait deletes the server and waits for the deletion to complete,
+// at most for unregisteredServerDeleteTimeout.
+func (m *hetznerManager) deleteServerAndWait(server *hcloud.Server) error {
+	ctx, cancel := context.WithTimeout(m.apiCallContext, unregisteredServerDeleteTimeout)
+	defer cancel()
+
+	result, _, err := m.client.Server.DeleteWithResult(ctx, server)
+	m.cachedServers.invalidate()
+	if err != nil {
+		return err
+	}
+	return m.client.Action.WaitFor(ctx, result.Action)
+}
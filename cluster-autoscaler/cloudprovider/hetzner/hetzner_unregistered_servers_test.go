@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hetzner
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/hetzner/hcloud-go/hcloud/schema"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDeleteUnregisteredServers(t *testing.T) {
+	now := time.Now()
+	server := func(id int64, name string, created time.Time, status hcloud.ServerStatus) schema.Server {
+		return schema.Server{ID: id, Name: name, Created: created, Status: string(status), Labels: map[string]string{nodeGroupLabel: "pool1"}}
+	}
+	servers := []schema.Server{
+		server(1, "registered-by-provider-id", now.Add(-time.Hour), hcloud.ServerStatusRunning),
+		server(2, "registered-by-name", now.Add(-time.Hour), hcloud.ServerStatusRunning),
+		server(3, "unregistered", now.Add(-time.Hour), hcloud.ServerStatusRunning),
+		server(4, "recently-created", now.Add(-time.Minute), hcloud.ServerStatusInitializing),
+		server(5, "being-deleted", now.Add(-time.Hour), hcloud.ServerStatusDeleting),
+		{ID: 6, Name: "registered-by-private-ip", Created: now.Add(-time.Hour), Status: string(hcloud.ServerStatusRunning),
+			Labels: map[string]string{nodeGroupLabel: "pool1"}, PrivateNet: []schema.ServerPrivateNet{{Network: 1, IP: "10.0.0.6"}}},
+	}
+
+	var mutex sync.Mutex
+	var deleted []string
+	m := newTestManager(t, map[string]http.HandlerFunc{
+		"GET /servers": jsonResponse(schema.ServerListResponse{Servers: servers}),
+		"DELETE /servers/{id}": func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mutex.Unlock()
+			_ = json.NewEncoder(w).Encode(schema.ServerDeleteResponse{Action: schema.Action{ID: 1, Status: string(hcloud.ActionStatusSuccess)}})
+		},
+	})
+
+	nodes := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	byProviderID := &apiv1.Node{Spec: apiv1.NodeSpec{ProviderID: toProviderID(1)}}
+	byProviderID.Name = "node-1"
+	byName := &apiv1.Node{}
+	byName.Name = "registered-by-name"
+	require.NoError(t, nodes.Add(byProviderID))
+	byPrivateIP := &apiv1.Node{Status: apiv1.NodeStatus{Addresses: []apiv1.NodeAddress{{Type: apiv1.NodeInternalIP, Address: "10.0.0.6"}}}}
+	byPrivateIP.Name = "10.0.0.6"
+	require.NoError(t, nodes.Add(byName))
+	require.NoError(t, nodes.Add(byPrivateIP))
+
+	m.unregisteredServerTimeout = 30 * time.Minute
+	m.nodeLister = v1lister.NewNodeLister(nodes)
+	m.network = &hcloud.Network{ID: 1}
+	m.nodeGroups["pool1"] = &hetznerNodeGroup{id: "pool1", manager: m}
+	m.nodeGroups[drainingNodePoolId] = &hetznerNodeGroup{id: drainingNodePoolId, manager: m}
+
+	getDeleted := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), deleted...)
+	}
+	require.NoError(t, m.deleteUnregisteredServers(now))
+	assert.Eventually(t, func() bool { return len(getDeleted()) > 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"/servers/3"}, getDeleted())
+
+	mutex.Lock()
+	deleted = nil
+	mutex.Unlock()
+	// In dry-run mode nothing is deleted, once the earlier deletion finished.
+	assert.Eventually(t, func() bool {
+		m.unregisteredServerDeletions.mutex.Lock()
+		defer m.unregisteredServerDeletions.mutex.Unlock()
+		return len(m.unregisteredServerDeletions.inFlight) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+	m.dryRun = true
+	require.NoError(t, m.deleteUnregisteredServers(now))
+	assert.Never(t, func() bool { return len(getDeleted()) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	m.dryRun = false
+	m.unregisteredServerTimeout = 0
+	require.NoError(t, m.deleteUnregisteredServers(now))
+	assert.Empty(t, getDeleted())
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"time"
+)
+
+// MaintenanceEventType describes what will happen to an instance during maintenance.
+type MaintenanceEventType string
+
+const (
+	// MaintenanceEventReboot means the instance will be rebooted.
+	MaintenanceEventReboot MaintenanceEventType = "Reboot"
+	// MaintenanceEventTermination means the instance will be terminated, e.g.
+	// retired by the cloud or reclaimed as a spot/preemptible instance.
+	MaintenanceEventTermination MaintenanceEventType = "Termination"
+	// MaintenanceEventMigration means the instance will be live migrated, which
+	// may still degrade or interrupt the workloads.
+	MaintenanceEventMigration MaintenanceEventType = "Migration"
+)
+
+// MaintenanceEvent is a scheduled maintenance or termination notice for a single instance.
+type MaintenanceEvent struct {
+	// ProviderID of the affected instance, matching the ProviderID of its node.
+	ProviderID string
+	// Type of the event.
+	Type MaintenanceEventType
+	// NotBefore is the earliest time the maintenance may start.
+	NotBefore time.Time
+	// Description is a human readable description of the event.
+	Description string
+}
+
+// MaintenanceEventsProvider is an optional interface that can be implemented by
+// a CloudProvider able to surface scheduled maintenance and termination notices.
+// Cluster Autoscaler uses them to provision replacement capacity and drain
+// affected nodes before the cloud provider takes them down.
+type MaintenanceEventsProvider interface {
+	// MaintenanceEvents returns currently known maintenance events for instances
+	// belonging to node groups of this cloud provider.
+	MaintenanceEvents() ([]MaintenanceEvent, error)
+}
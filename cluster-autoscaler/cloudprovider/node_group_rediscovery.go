@@ -0,0 +1,28 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+// NodeGroupRediscoverer is an optional interface that can be implemented by
+// a CloudProvider caching node group definitions between refreshes. Cluster
+// Autoscaler uses it to periodically, or on demand, rebuild the definitions,
+// picking up out-of-band changes such as modified min and max sizes without
+// a restart.
+type NodeGroupRediscoverer interface {
+	// RediscoverNodeGroups rebuilds node group definitions from the cloud
+	// provider, bypassing any caches.
+	RediscoverNodeGroups() error
+}
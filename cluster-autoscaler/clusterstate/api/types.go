@@ -19,6 +19,8 @@ package api
 // TODO: Remove this once Cluster Autoscaler api is approved.
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -123,6 +125,8 @@ type NodeGroupHealthCondition struct {
 type ClusterScaleUpCondition struct {
 	// Status of the scale up.
 	Status ClusterAutoscalerConditionStatus `json:"status,omitempty" yaml:"status,omitempty"`
+	// UpcomingNodes is the number of requested nodes which aren't ready yet in the cluster.
+	UpcomingNodes int `json:"upcomingNodes,omitempty" yaml:"upcomingNodes,omitempty"`
 	// LastProbeTime is the last time we probed the condition.
 	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty" yaml:"lastProbeTime,omitempty"`
 	// LastTransitionTime is the time since when the condition was in the given state.
@@ -141,6 +145,9 @@ type BackoffInfo struct {
 type NodeGroupScaleUpCondition struct {
 	// Status of the scale up.
 	Status ClusterAutoscalerConditionStatus `json:"status,omitempty" yaml:"status,omitempty"`
+	// UpcomingNodes is the number of requested nodes which aren't ready yet in the node group.
+	// They're accounted for as if they were ready when computing further scale-ups.
+	UpcomingNodes int `json:"upcomingNodes,omitempty" yaml:"upcomingNodes,omitempty"`
 	// LastProbeTime is the last time we probed the condition.
 	BackoffInfo BackoffInfo `json:"backoffInfo,omitempty" yaml:"backoffInfo,omitempty"`
 	// LastProbeTime is the last time we probed the condition.
@@ -155,6 +162,8 @@ type ScaleDownCondition struct {
 	Status ClusterAutoscalerConditionStatus `json:"status,omitempty" yaml:"status,omitempty"`
 	// Candidates number for the scale down.
 	Candidates int `json:"candidates,omitempty" yaml:"candidates,omitempty"`
+	// BlockedNodes contains numbers of nodes which can't be scaled down, grouped by reason.
+	BlockedNodes map[string]int `json:"blockedNodes,omitempty" yaml:"blockedNodes,omitempty"`
 	// LastProbeTime is the last time we probed the condition.
 	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty" yaml:"lastProbeTime,omitempty"`
 	// LastTransitionTime is the time since when the condition was in the given state.
@@ -169,6 +178,39 @@ type ClusterWideStatus struct {
 	ScaleUp ClusterScaleUpCondition `json:"scaleUp,omitempty" yaml:"scaleUp,omitempty"`
 	// ScaleDown contains information about scale down condition of the node group.
 	ScaleDown ScaleDownCondition `json:"scaleDown,omitempty" yaml:"scaleDown,omitempty"`
+	// OrphanedNodes contains nodes whose node group was removed from node group discovery.
+	OrphanedNodes []OrphanedNodeStatus `json:"orphanedNodes,omitempty" yaml:"orphanedNodes,omitempty"`
+}
+
+// OrphanedNodeStatus contains status of a node whose node group was removed from node group discovery.
+type OrphanedNodeStatus struct {
+	// Name of the node.
+	Name string `json:"name" yaml:"name"`
+	// NodeGroup is the id of the node group the node was removed from, if known.
+	NodeGroup string `json:"nodeGroup,omitempty" yaml:"nodeGroup,omitempty"`
+	// Policy is the orphaned nodes policy applied to the node.
+	Policy string `json:"policy" yaml:"policy"`
+	// OrphanedSince is the time since when the node doesn't belong to any node group.
+	OrphanedSince metav1.Time `json:"orphanedSince" yaml:"orphanedSince"`
+	// DrainAfter is the time after which the node is drained and deleted, if it's drained.
+	DrainAfter metav1.Time `json:"drainAfter,omitempty" yaml:"drainAfter,omitempty"`
+}
+
+// NodeGroupBackoffState contains the exponential backoff state of scale-ups of a node group.
+// It's persisted, so that restarts of the cluster autoscaler don't reset backoff.
+type NodeGroupBackoffState struct {
+	// Duration is the duration of the last backoff.
+	Duration time.Duration `json:"duration" yaml:"duration"`
+	// BackoffUntil is the time until which scale-ups of the node group are backed off.
+	BackoffUntil metav1.Time `json:"backoffUntil" yaml:"backoffUntil"`
+	// LastFailedScaleUp is the time of the last failed scale-up of the node group.
+	LastFailedScaleUp metav1.Time `json:"lastFailedScaleUp" yaml:"lastFailedScaleUp"`
+	// ErrorClass is the class of the error which caused the last backoff.
+	ErrorClass int `json:"errorClass,omitempty" yaml:"errorClass,omitempty"`
+	// ErrorCode is a specific error code of the error which caused the last backoff.
+	ErrorCode string `json:"errorCode,omitempty" yaml:"errorCode,omitempty"`
+	// ErrorMessage is human readable description of the error which caused the last backoff.
+	ErrorMessage string `json:"errorMessage,omitempty" yaml:"errorMessage,omitempty"`
 }
 
 // NodeGroupStatus contains status of an individual node group on which CA works..
@@ -181,6 +223,8 @@ type NodeGroupStatus struct {
 	ScaleUp NodeGroupScaleUpCondition `json:"scaleUp,omitempty" yaml:"scaleUp,omitempty"`
 	// ScaleDown contains information about scale down condition of the node group.
 	ScaleDown ScaleDownCondition `json:"scaleDown,omitempty" yaml:"scaleDown,omitempty"`
+	// Backoff contains the backoff state of the node group, if it's persisted.
+	Backoff *NodeGroupBackoffState `json:"backoff,omitempty" yaml:"backoff,omitempty"`
 }
 
 // ClusterAutoscalerStatus contains ClusterAutoscaler status.
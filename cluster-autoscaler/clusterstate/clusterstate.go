@@ -29,6 +29,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
@@ -86,6 +87,15 @@ type ClusterStateRegistryConfig struct {
 	// Minimum number of nodes that must be unready for MaxTotalUnreadyPercentage to apply.
 	// This is to ensure that in very small clusters (e.g. 2 nodes) a single node's failure doesn't disable autoscaling.
 	OkTotalUnreadyCount int
+	// OutOfScopeNodeLister lists nodes which aren't managed by the autoscaler, because they don't
+	// match its node selector. Cloud provider instances of these nodes are not considered unregistered.
+	OutOfScopeNodeLister kube_util.NodeLister
+	// PersistBackoff makes the backoff state of node groups part of the status,
+	// so that it can be restored after a restart.
+	PersistBackoff bool
+	// ResetBackoffOnScaleUpSuccess removes backoff of node groups once all nodes
+	// of their scale-ups register, instead of keeping it until it becomes stale.
+	ResetBackoffOnScaleUpSuccess bool
 }
 
 // IncorrectNodeGroupSize contains information about how much the current size of the node group
@@ -132,6 +142,8 @@ type ClusterStateRegistry struct {
 	unregisteredNodes                  map[string]UnregisteredNode
 	deletedNodes                       map[string]struct{}
 	candidatesForScaleDown             map[string][]string
+	blockedForScaleDown                map[string]map[string]int
+	totalBlockedForScaleDown           map[string]int
 	backoff                            backoff.Backoff
 	lastStatus                         *api.ClusterAutoscalerStatus
 	lastScaleDownUpdateTime            time.Time
@@ -168,6 +180,8 @@ func NewClusterStateRegistry(cloudProvider cloudprovider.CloudProvider, config C
 		unregisteredNodes:               make(map[string]UnregisteredNode),
 		deletedNodes:                    make(map[string]struct{}),
 		candidatesForScaleDown:          make(map[string][]string),
+		blockedForScaleDown:             make(map[string]map[string]int),
+		totalBlockedForScaleDown:        make(map[string]int),
 		backoff:                         backoff,
 		lastStatus:                      utils.EmptyClusterAutoscalerStatus(),
 		logRecorder:                     logRecorder,
@@ -265,6 +279,9 @@ func (csr *ClusterStateRegistry) updateScaleRequests(currentTime time.Time) {
 			delete(csr.scaleUpRequests, nodeGroupName)
 			klog.V(4).Infof("Scale up in group %v finished successfully in %v",
 				nodeGroupName, currentTime.Sub(scaleUpRequest.Time))
+			if csr.config.ResetBackoffOnScaleUpSuccess {
+				csr.backoff.RemoveBackoff(scaleUpRequest.NodeGroup, csr.nodeInfosForGroups[nodeGroupName])
+			}
 			continue
 		}
 
@@ -345,7 +362,15 @@ func (csr *ClusterStateRegistry) UpdateNodes(nodes []*apiv1.Node, nodeInfosForGr
 		return err
 	}
 	cloudProviderNodesRemoved := csr.getCloudProviderDeletedNodes(nodes)
-	notRegistered := getNotRegisteredNodes(nodes, cloudProviderNodeInstances, currentTime)
+	registeredNodes := nodes
+	if csr.config.OutOfScopeNodeLister != nil {
+		outOfScopeNodes, err := csr.config.OutOfScopeNodeLister.List()
+		if err != nil {
+			return err
+		}
+		registeredNodes = append(outOfScopeNodes, nodes...)
+	}
+	notRegistered := getNotRegisteredNodes(registeredNodes, cloudProviderNodeInstances, currentTime)
 
 	csr.Lock()
 	defer csr.Unlock()
@@ -367,9 +392,19 @@ func (csr *ClusterStateRegistry) UpdateNodes(nodes []*apiv1.Node, nodeInfosForGr
 	//  recalculate acceptable ranges after removing timed out requests
 	csr.updateAcceptableRanges(targetSizes)
 	csr.updateIncorrectNodeGroupSizes(currentTime)
+	csr.updateUpcomingNodesMetrics()
 	return nil
 }
 
+// To be executed under a lock.
+func (csr *ClusterStateRegistry) updateUpcomingNodesMetrics() {
+	upcomingCounts := map[string]int{}
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		upcomingCounts[nodeGroup.Id()] = csr.upcomingNodesCount(nodeGroup.Id())
+	}
+	metrics.UpdateUpcomingNodes(upcomingCounts)
+}
+
 // Recalculate cluster state after scale-ups or scale-downs were registered.
 func (csr *ClusterStateRegistry) Recalculate() {
 	targetSizes, err := getTargetSizes(csr.cloudProvider)
@@ -430,13 +465,14 @@ func (csr *ClusterStateRegistry) IsNodeGroupHealthy(nodeGroupName string) bool {
 
 	unjustifiedUnready := 0
 	// Too few nodes, something is missing. Below the expected node count.
-	if len(readiness.Ready) < acceptable.MinNodes {
-		unjustifiedUnready += acceptable.MinNodes - len(readiness.Ready)
+	// Nodes being repaired are expected to return, so they're not missing.
+	if available := len(readiness.Ready) + len(readiness.Repairing) + len(readiness.RepairingUnregistered); available < acceptable.MinNodes {
+		unjustifiedUnready += acceptable.MinNodes - available
 	}
 	// TODO: verify against max nodes as well.
 	if unjustifiedUnready > csr.config.OkTotalUnreadyCount &&
 		float64(unjustifiedUnready) > csr.config.MaxTotalUnreadyPercentage/100.0*
-			float64(len(readiness.Ready)+len(readiness.Unready)+len(readiness.NotStarted)) {
+			float64(len(readiness.Ready)+len(readiness.Unready)+len(readiness.NotStarted)+len(readiness.Repairing)+len(readiness.RepairingUnregistered)) {
 		return false
 	}
 
@@ -591,6 +627,14 @@ type Readiness struct {
 	LongUnregistered []string
 	// Names of nodes that haven't yet registered.
 	Unregistered []string
+	// Names of registered nodes whose instances are being repaired by the
+	// cloud provider. They're expected to return, so they're neither unready
+	// nor upcoming.
+	Repairing []string
+	// Ids of cloud provider instances being repaired which have no registered
+	// node, e.g. while they're reimaged. Like Repairing, they're neither
+	// missing nor upcoming.
+	RepairingUnregistered []string
 	// Time when the readiness was measured.
 	Time time.Time
 	// Names of nodes that are Unready due to missing resources.
@@ -602,6 +646,7 @@ type Readiness struct {
 func (csr *ClusterStateRegistry) updateReadinessStats(currentTime time.Time) {
 	perNodeGroup := make(map[string]Readiness)
 	total := Readiness{Time: currentTime}
+	repairing := csr.getRepairingInstances()
 
 	update := func(current Readiness, node *apiv1.Node, nr kube_util.NodeReadiness) Readiness {
 		current.Registered = append(current.Registered, node.Name)
@@ -609,6 +654,8 @@ func (csr *ClusterStateRegistry) updateReadinessStats(currentTime time.Time) {
 			current.Deleted = append(current.Deleted, node.Name)
 		} else if nr.Ready {
 			current.Ready = append(current.Ready, node.Name)
+		} else if _, isRepairing := repairing[node.Spec.ProviderID]; isRepairing {
+			current.Repairing = append(current.Repairing, node.Name)
 		} else if node.CreationTimestamp.Time.Add(MaxNodeStartupTime).After(currentTime) {
 			current.NotStarted = append(current.NotStarted, node.Name)
 		} else {
@@ -620,7 +667,9 @@ func (csr *ClusterStateRegistry) updateReadinessStats(currentTime time.Time) {
 		return current
 	}
 
+	registeredInstances := make(map[string]bool, len(csr.nodes))
 	for _, node := range csr.nodes {
+		registeredInstances[node.Spec.ProviderID] = true
 		nodeGroup, errNg := csr.cloudProvider.NodeGroupForNode(node)
 		nr, errReady := kube_util.GetNodeReadiness(node)
 
@@ -667,6 +716,18 @@ func (csr *ClusterStateRegistry) updateReadinessStats(currentTime time.Time) {
 		klog.V(3).Infof("Found longUnregistered Nodes %s", total.LongUnregistered)
 	}
 
+	// Instances being repaired may have no node while they're reimaged, they
+	// aren't expected to register until the repair is done.
+	for instanceId, nodeGroupId := range repairing {
+		if registeredInstances[instanceId] {
+			continue
+		}
+		perNgCopy := perNodeGroup[nodeGroupId]
+		perNgCopy.RepairingUnregistered = append(perNgCopy.RepairingUnregistered, instanceId)
+		perNodeGroup[nodeGroupId] = perNgCopy
+		total.RepairingUnregistered = append(total.RepairingUnregistered, instanceId)
+	}
+
 	for ngId, ngReadiness := range perNodeGroup {
 		ngReadiness.Time = currentTime
 		perNodeGroup[ngId] = ngReadiness
@@ -763,6 +824,31 @@ func (csr *ClusterStateRegistry) UpdateScaleDownCandidates(nodes []*apiv1.Node,
 	csr.lastScaleDownUpdateTime = now
 }
 
+// UpdateScaleDownBlockedNodes updates the numbers of nodes that can't be scaled
+// down, grouped by node group and blocking reason.
+func (csr *ClusterStateRegistry) UpdateScaleDownBlockedNodes(unremovableNodes []*simulator.UnremovableNode) {
+	result := make(map[string]map[string]int)
+	total := make(map[string]int)
+	for _, unremovableNode := range unremovableNodes {
+		reason := unremovableNode.BlockingReason()
+		total[reason]++
+		group, err := csr.cloudProvider.NodeGroupForNode(unremovableNode.Node)
+		if err != nil {
+			klog.Warningf("Failed to get node group for %s: %v", unremovableNode.Node.Name, err)
+			continue
+		}
+		if group == nil || reflect.ValueOf(group).IsNil() {
+			continue
+		}
+		if result[group.Id()] == nil {
+			result[group.Id()] = make(map[string]int)
+		}
+		result[group.Id()][reason]++
+	}
+	csr.blockedForScaleDown = result
+	csr.totalBlockedForScaleDown = total
+}
+
 // GetStatus returns ClusterAutoscalerStatus with the current cluster autoscaler status.
 func (csr *ClusterStateRegistry) GetStatus(now time.Time) *api.ClusterAutoscalerStatus {
 	result := &api.ClusterAutoscalerStatus{
@@ -773,6 +859,10 @@ func (csr *ClusterStateRegistry) GetStatus(now time.Time) *api.ClusterAutoscaler
 	for _, nodeGroup := range csr.lastStatus.NodeGroups {
 		nodeGroupsLastStatus[nodeGroup.Name] = nodeGroup
 	}
+	var backoffState map[string]backoff.NodeGroupBackoffState
+	if persistentBackoff, ok := csr.backoff.(backoff.PersistentBackoff); ok && csr.config.PersistBackoff {
+		backoffState = persistentBackoff.State()
+	}
 	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
 		nodeGroupStatus := api.NodeGroupStatus{
 			Name: nodeGroup.Id(),
@@ -794,7 +884,12 @@ func (csr *ClusterStateRegistry) GetStatus(now time.Time) *api.ClusterAutoscaler
 
 		// Scale down.
 		nodeGroupStatus.ScaleDown = buildScaleDownStatusNodeGroup(
-			csr.candidatesForScaleDown[nodeGroup.Id()], csr.lastScaleDownUpdateTime, nodeGroupLastStatus.ScaleDown)
+			csr.candidatesForScaleDown[nodeGroup.Id()], csr.blockedForScaleDown[nodeGroup.Id()], csr.lastScaleDownUpdateTime, nodeGroupLastStatus.ScaleDown)
+
+		// Backoff.
+		if state, found := backoffState[nodeGroup.Id()]; found {
+			nodeGroupStatus.Backoff = buildBackoffStateNodeGroup(state)
+		}
 
 		result.NodeGroups = append(result.NodeGroups, nodeGroupStatus)
 	}
@@ -803,12 +898,45 @@ func (csr *ClusterStateRegistry) GetStatus(now time.Time) *api.ClusterAutoscaler
 	result.ClusterWide.ScaleUp =
 		buildScaleUpStatusClusterwide(result.NodeGroups, csr.totalReadiness, csr.lastStatus.ClusterWide.ScaleUp)
 	result.ClusterWide.ScaleDown =
-		buildScaleDownStatusClusterwide(csr.candidatesForScaleDown, csr.lastScaleDownUpdateTime, csr.lastStatus.ClusterWide.ScaleDown)
+		buildScaleDownStatusClusterwide(csr.candidatesForScaleDown, csr.totalBlockedForScaleDown, csr.lastScaleDownUpdateTime, csr.lastStatus.ClusterWide.ScaleDown)
 
 	csr.lastStatus = result
 	return result
 }
 
+func buildBackoffStateNodeGroup(state backoff.NodeGroupBackoffState) *api.NodeGroupBackoffState {
+	return &api.NodeGroupBackoffState{
+		Duration:          state.Duration,
+		BackoffUntil:      metav1.NewTime(state.BackoffUntil),
+		LastFailedScaleUp: metav1.NewTime(state.LastFailedExecution),
+		ErrorClass:        int(state.ErrorInfo.ErrorClass),
+		ErrorCode:         state.ErrorInfo.ErrorCode,
+		ErrorMessage:      state.ErrorInfo.ErrorMessage,
+	}
+}
+
+// BackoffStateFromStatus returns the backoff state of node groups persisted in
+// the status, by node group id.
+func BackoffStateFromStatus(status *api.ClusterAutoscalerStatus) map[string]backoff.NodeGroupBackoffState {
+	result := make(map[string]backoff.NodeGroupBackoffState)
+	for _, nodeGroup := range status.NodeGroups {
+		if nodeGroup.Backoff == nil {
+			continue
+		}
+		result[nodeGroup.Name] = backoff.NodeGroupBackoffState{
+			Duration:            nodeGroup.Backoff.Duration,
+			BackoffUntil:        nodeGroup.Backoff.BackoffUntil.Time,
+			LastFailedExecution: nodeGroup.Backoff.LastFailedScaleUp.Time,
+			ErrorInfo: cloudprovider.InstanceErrorInfo{
+				ErrorClass:   cloudprovider.InstanceErrorClass(nodeGroup.Backoff.ErrorClass),
+				ErrorCode:    nodeGroup.Backoff.ErrorCode,
+				ErrorMessage: nodeGroup.Backoff.ErrorMessage,
+			},
+		}
+	}
+	return result
+}
+
 // GetClusterReadiness returns current readiness stats of cluster
 func (csr *ClusterStateRegistry) GetClusterReadiness() Readiness {
 	return csr.totalReadiness
@@ -856,6 +984,7 @@ func (csr *ClusterStateRegistry) buildScaleUpStatusNodeGroup(nodeGroup cloudprov
 	isScaleUpInProgress := csr.IsNodeGroupScalingUp(nodeGroup.Id())
 	scaleUpSafety := csr.NodeGroupScaleUpSafety(nodeGroup, now)
 	condition := api.NodeGroupScaleUpCondition{
+		UpcomingNodes: csr.upcomingNodesCount(nodeGroup.Id()),
 		LastProbeTime: metav1.Time{Time: readiness.Time},
 	}
 	if isScaleUpInProgress {
@@ -879,9 +1008,10 @@ func (csr *ClusterStateRegistry) buildScaleUpStatusNodeGroup(nodeGroup cloudprov
 	return condition
 }
 
-func buildScaleDownStatusNodeGroup(candidates []string, lastProbed time.Time, lastStatus api.ScaleDownCondition) api.ScaleDownCondition {
+func buildScaleDownStatusNodeGroup(candidates []string, blocked map[string]int, lastProbed time.Time, lastStatus api.ScaleDownCondition) api.ScaleDownCondition {
 	condition := api.ScaleDownCondition{
 		Candidates:    len(candidates),
+		BlockedNodes:  blocked,
 		LastProbeTime: metav1.Time{Time: lastProbed},
 	}
 	if len(candidates) > 0 {
@@ -917,13 +1047,15 @@ func buildHealthStatusClusterwide(isHealthy bool, readiness Readiness, lastStatu
 
 func buildScaleUpStatusClusterwide(nodeGroupsStatuses []api.NodeGroupStatus, readiness Readiness, lastStatus api.ClusterScaleUpCondition) api.ClusterScaleUpCondition {
 	isScaleUpInProgress := false
+	upcomingNodes := 0
 	for _, nodeGroupStatus := range nodeGroupsStatuses {
 		if nodeGroupStatus.ScaleUp.Status == api.ClusterAutoscalerInProgress {
 			isScaleUpInProgress = true
-			break
 		}
+		upcomingNodes += nodeGroupStatus.ScaleUp.UpcomingNodes
 	}
 	condition := api.ClusterScaleUpCondition{
+		UpcomingNodes: upcomingNodes,
 		LastProbeTime: metav1.Time{Time: readiness.Time},
 	}
 	if isScaleUpInProgress {
@@ -939,13 +1071,14 @@ func buildScaleUpStatusClusterwide(nodeGroupsStatuses []api.NodeGroupStatus, rea
 	return condition
 }
 
-func buildScaleDownStatusClusterwide(candidates map[string][]string, lastProbed time.Time, lastStatus api.ScaleDownCondition) api.ScaleDownCondition {
+func buildScaleDownStatusClusterwide(candidates map[string][]string, blocked map[string]int, lastProbed time.Time, lastStatus api.ScaleDownCondition) api.ScaleDownCondition {
 	totalCandidates := 0
 	for _, val := range candidates {
 		totalCandidates += len(val)
 	}
 	condition := api.ScaleDownCondition{
 		Candidates:    totalCandidates,
+		BlockedNodes:  blocked,
 		LastProbeTime: metav1.Time{Time: lastProbed},
 	}
 	if totalCandidates > 0 {
@@ -981,12 +1114,8 @@ func (csr *ClusterStateRegistry) GetUpcomingNodes() (upcomingCounts map[string]i
 	registeredNodeNames = map[string][]string{}
 	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
 		id := nodeGroup.Id()
-		readiness := csr.perNodeGroupReadiness[id]
-		ar := csr.acceptableRanges[id]
-		// newNodes is the number of nodes that
-		newNodes := ar.CurrentTarget - (len(readiness.Ready) + len(readiness.Unready) + len(readiness.LongUnregistered))
+		newNodes := csr.upcomingNodesCount(id)
 		if newNodes <= 0 {
-			// Negative value is unlikely but theoretically possible.
 			continue
 		}
 		upcomingCounts[id] = newNodes
@@ -994,11 +1123,24 @@ func (csr *ClusterStateRegistry) GetUpcomingNodes() (upcomingCounts map[string]i
 		// but haven't registered with k8s yet, and instances that haven't even come up on the cloud provider side yet (but are reflected in the target
 		// size). The first category is categorized as NotStarted in readiness, the other two aren't registered with k8s, so they shouldn't be
 		// included.
-		registeredNodeNames[id] = readiness.NotStarted
+		registeredNodeNames[id] = csr.perNodeGroupReadiness[id].NotStarted
 	}
 	return upcomingCounts, registeredNodeNames
 }
 
+// upcomingNodesCount returns the number of nodes which were requested for the node group, but aren't ready yet.
+// To be executed under a lock.
+func (csr *ClusterStateRegistry) upcomingNodesCount(nodeGroupId string) int {
+	readiness := csr.perNodeGroupReadiness[nodeGroupId]
+	ar := csr.acceptableRanges[nodeGroupId]
+	newNodes := ar.CurrentTarget - (len(readiness.Ready) + len(readiness.Unready) + len(readiness.LongUnregistered) + len(readiness.Repairing) + len(readiness.RepairingUnregistered))
+	if newNodes < 0 {
+		// Negative value is unlikely but theoretically possible.
+		return 0
+	}
+	return newNodes
+}
+
 // getCloudProviderNodeInstances returns map keyed on node group id where value is list of node instances
 // as returned by NodeGroup.Nodes().
 func (csr *ClusterStateRegistry) getCloudProviderNodeInstances() (map[string][]cloudprovider.Instance, error) {
@@ -1033,7 +1175,23 @@ func getNotRegisteredNodes(allNodes []*apiv1.Node, cloudProviderNodeInstances ma
 }
 
 func expectedToRegister(instance cloudprovider.Instance) bool {
-	return instance.Status == nil || (instance.Status.State != cloudprovider.InstanceDeleting && instance.Status.ErrorInfo == nil)
+	return instance.Status == nil || (instance.Status.State != cloudprovider.InstanceDeleting &&
+		instance.Status.State != cloudprovider.InstanceRepairing && instance.Status.ErrorInfo == nil)
+}
+
+// getRepairingInstances returns the ids of the instances being repaired by the
+// cloud provider, mapped to the ids of their node groups.
+// To be executed under a lock.
+func (csr *ClusterStateRegistry) getRepairingInstances() map[string]string {
+	repairing := make(map[string]string)
+	for nodeGroupId, instances := range csr.cloudProviderNodeInstances {
+		for _, instance := range instances {
+			if instance.Status != nil && instance.Status.State == cloudprovider.InstanceRepairing {
+				repairing[instance.Id] = nodeGroupId
+			}
+		}
+	}
+	return repairing
 }
 
 // Calculates which of the registered nodes in Kubernetes that do not exist in cloud provider.
@@ -32,6 +32,9 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
 
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	"k8s.io/client-go/kubernetes/fake"
@@ -291,6 +294,58 @@ func TestOKOneUnreadyNodeWithScaleDownCandidate(t *testing.T) {
 	assert.True(t, ng2Checked)
 }
 
+func TestScaleDownBlockedNodes(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	ng2_1 := BuildTestNode("ng2-1", 1000, 1000)
+	noNgNode := BuildTestNode("no-ng", 1000, 1000)
+	for _, node := range []*apiv1.Node{ng1_1, ng1_2, ng2_1, noNgNode} {
+		SetNodeReadyState(node, true, now.Add(-time.Minute))
+	}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng1", ng1_2)
+	provider.AddNode("ng2", ng2_1)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2, ng2_1, noNgNode}, nil, now)
+	assert.NoError(t, err)
+	clusterstate.UpdateScaleDownBlockedNodes([]*simulator.UnremovableNode{
+		{Node: ng1_1, Reason: simulator.BlockedByPod, BlockingPod: &drain.BlockingPod{Pod: BuildTestPod("p", 100, 100), Reason: drain.NotEnoughPdb}},
+		{Node: ng1_2, Reason: simulator.RecentScaleUp},
+		{Node: ng2_1, Reason: simulator.NotUnderutilized},
+		{Node: noNgNode, Reason: simulator.NotAutoscaled},
+	})
+
+	status := clusterstate.GetStatus(now)
+	assert.Equal(t, map[string]int{"NotEnoughPdb": 1, "RecentScaleUp": 1, "NotUnderutilized": 1, "NotAutoscaled": 1}, status.ClusterWide.ScaleDown.BlockedNodes)
+	for _, nodeGroupStatus := range status.NodeGroups {
+		switch nodeGroupStatus.Name {
+		case "ng1":
+			assert.Equal(t, map[string]int{"NotEnoughPdb": 1, "RecentScaleUp": 1}, nodeGroupStatus.ScaleDown.BlockedNodes)
+		case "ng2":
+			assert.Equal(t, map[string]int{"NotUnderutilized": 1}, nodeGroupStatus.ScaleDown.BlockedNodes)
+		}
+	}
+
+	clusterstate.UpdateScaleDownBlockedNodes(nil)
+	status = clusterstate.GetStatus(now)
+	assert.Empty(t, status.ClusterWide.ScaleDown.BlockedNodes)
+	for _, nodeGroupStatus := range status.NodeGroups {
+		assert.Empty(t, nodeGroupStatus.ScaleDown.BlockedNodes)
+	}
+}
+
 func TestMissingNodes(t *testing.T) {
 	now := time.Now()
 
@@ -553,6 +608,14 @@ func TestUpcomingNodes(t *testing.T) {
 	assert.NotContains(t, upcomingRegistered, "ng4")
 	assert.Equal(t, 0, upcomingNodes["ng5"])
 	assert.Empty(t, upcomingRegistered["ng5"])
+
+	status := clusterstate.GetStatus(now)
+	upcomingInStatus := map[string]int{}
+	for _, nodeGroupStatus := range status.NodeGroups {
+		upcomingInStatus[nodeGroupStatus.Name] = nodeGroupStatus.ScaleUp.UpcomingNodes
+	}
+	assert.Equal(t, map[string]int{"ng1": 6, "ng2": 1, "ng3": 2, "ng4": 0, "ng5": 0}, upcomingInStatus)
+	assert.Equal(t, 9, status.ClusterWide.ScaleUp.UpcomingNodes)
 }
 
 func TestTaintBasedNodeDeletion(t *testing.T) {
@@ -666,6 +729,77 @@ func TestUnregisteredNodes(t *testing.T) {
 	assert.Equal(t, 0, len(clusterstate.GetUnregisteredNodes()))
 }
 
+func TestRepairingInstances(t *testing.T) {
+	now := time.Now()
+	ready := BuildTestNode("ng1-1", 1000, 1000)
+	ready.Spec.ProviderID = "ng1-1"
+	SetNodeReadyState(ready, true, now.Add(-time.Hour))
+	repairedNode := BuildTestNode("ng1-2", 1000, 1000)
+	repairedNode.Spec.ProviderID = "ng1-2"
+	SetNodeReadyState(repairedNode, false, now.Add(-time.Hour))
+	repairedNode.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 3)
+	provider.AddNode("ng1", ready)
+	provider.AddNode("ng1", repairedNode)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       0,
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 10 * time.Second}))
+
+	repairing := &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRepairing}
+	instances := map[string][]cloudprovider.Instance{
+		"ng1": {
+			{Id: "ng1-1", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}},
+			{Id: "ng1-2", Status: repairing},
+			{Id: "ng1-3", Status: repairing},
+		},
+	}
+	nodes := []*apiv1.Node{ready, repairedNode}
+	assert.Empty(t, getNotRegisteredNodes(nodes, instances, now))
+
+	clusterstate.nodes = nodes
+	clusterstate.cloudProviderNodeInstances = instances
+	clusterstate.updateReadinessStats(now)
+	clusterstate.updateAcceptableRanges(map[string]int{"ng1": 3})
+
+	readiness := clusterstate.perNodeGroupReadiness["ng1"]
+	assert.Equal(t, []string{"ng1-1"}, readiness.Ready)
+	assert.Equal(t, []string{"ng1-2"}, readiness.Repairing)
+	assert.Equal(t, []string{"ng1-3"}, readiness.RepairingUnregistered)
+	assert.Empty(t, readiness.Unready)
+	assert.Empty(t, readiness.LongUnregistered)
+	assert.True(t, clusterstate.IsClusterHealthy())
+	assert.True(t, clusterstate.IsNodeGroupHealthy("ng1"))
+	upcoming, _ := clusterstate.GetUpcomingNodes()
+	assert.Zero(t, upcoming["ng1"])
+}
+
+func TestOutOfScopeNodesNotUnregistered(t *testing.T) {
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	ng1_1.Spec.ProviderID = "ng1-1"
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	ng1_2.Spec.ProviderID = "ng1-2"
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng1", ng1_2)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+		OutOfScopeNodeLister:      kube_util.NewTestNodeLister([]*apiv1.Node{ng1_2}),
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 10 * time.Second}))
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, time.Now())
+	assert.NoError(t, err)
+	assert.Empty(t, clusterstate.GetUnregisteredNodes())
+}
+
 func TestCloudProviderDeletedNodes(t *testing.T) {
 	now := time.Now()
 	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
@@ -884,6 +1018,65 @@ func TestScaleUpBackoff(t *testing.T) {
 		}}, clusterstate.backoff.BackoffStatus(ng1, nil, now))
 }
 
+func TestPersistedBackoff(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNodeGroup("ng2", 1, 10, 1)
+	ng1 := provider.GetNodeGroup("ng1")
+	provider.AddNode("ng1", ng1_1)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage:    10,
+		OkTotalUnreadyCount:          1,
+		PersistBackoff:               true,
+		ResetBackoffOnScaleUpSuccess: true,
+	}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 120 * time.Second}))
+
+	// Backoff of a timed out scale-up is part of the status.
+	clusterstate.RegisterScaleUp(ng1, 1, now.Add(-180*time.Second))
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	status := clusterstate.GetStatus(now)
+	assert.Len(t, status.NodeGroups, 2)
+	for _, nodeGroupStatus := range status.NodeGroups {
+		if nodeGroupStatus.Name == "ng2" {
+			assert.Nil(t, nodeGroupStatus.Backoff)
+			continue
+		}
+		assert.Equal(t, &api.NodeGroupBackoffState{
+			Duration:          5 * time.Minute,
+			BackoffUntil:      metav1.NewTime(now.Add(5 * time.Minute)),
+			LastFailedScaleUp: metav1.NewTime(now),
+			ErrorClass:        int(cloudprovider.OtherErrorClass),
+			ErrorCode:         "timeout",
+			ErrorMessage:      "Scale-up timed out for node group ng1 after 3m0s",
+		}, nodeGroupStatus.Backoff)
+	}
+
+	// The persisted state restores backoff after a restart.
+	restored := newBackoff()
+	restored.Restore(BackoffStateFromStatus(status))
+	assert.True(t, restored.BackoffStatus(ng1, nil, now.Add(time.Minute)).IsBackedOff)
+
+	// Successful scale-ups reset backoff.
+	now = now.Add(5 * time.Minute).Add(time.Second)
+	clusterstate.RegisterScaleUp(ng1, 1, now)
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	SetNodeReadyState(ng1_2, true, now.Add(-time.Minute))
+	provider.AddNode("ng1", ng1_2)
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now)
+	assert.NoError(t, err)
+	for _, nodeGroupStatus := range clusterstate.GetStatus(now).NodeGroups {
+		assert.Nil(t, nodeGroupStatus.Backoff)
+	}
+}
+
 func TestGetClusterSize(t *testing.T) {
 	now := time.Now()
 
@@ -1019,7 +1212,7 @@ func TestScaleUpFailures(t *testing.T) {
 	assert.Empty(t, clusterstate.GetScaleUpFailures())
 }
 
-func newBackoff() backoff.Backoff {
+func newBackoff() backoff.PersistentBackoff {
 	return backoff.NewIdBasedExponentialBackoff(5*time.Minute, /*InitialNodeGroupBackoffDuration*/
 		30*time.Minute /*MaxNodeGroupBackoffDuration*/, 3*time.Hour /*NodeGroupBackoffResetTimeout*/)
 }
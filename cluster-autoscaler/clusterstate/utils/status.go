@@ -147,6 +147,23 @@ func WriteStatusConfigMap(kubeClient kube_client.Interface, namespace string, st
 	return configMap, nil
 }
 
+// ReadStatusConfigMap reads the status written to the status ConfigMap. It returns nil
+// if the ConfigMap doesn't exist.
+func ReadStatusConfigMap(kubeClient kube_client.Interface, namespace string, statusConfigMapName string) (*api.ClusterAutoscalerStatus, error) {
+	configMap, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), statusConfigMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve status configmap: %v", err)
+	}
+	status := &api.ClusterAutoscalerStatus{}
+	if err := yaml.Unmarshal([]byte(configMap.Data["status"]), status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal status configmap: %v", err)
+	}
+	return status, nil
+}
+
 // DeleteStatusConfigMap deletes status configmap
 func DeleteStatusConfigMap(kubeClient kube_client.Interface, namespace string, statusConfigMapName string) error {
 	maps := kubeClient.CoreV1().ConfigMaps(namespace)
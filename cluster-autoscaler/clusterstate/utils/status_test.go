@@ -257,3 +257,36 @@ func TestWriteStatusConfigMapMarshal(t *testing.T) {
 	}
 	assert.YAMLEq(t, string(want), result.Data["status"])
 }
+
+func TestReadStatusConfigMap(t *testing.T) {
+	ti := setUpTest(t)
+	want := status
+	want.NodeGroups = []api.NodeGroupStatus{{
+		Name: "ng1",
+		Backoff: &api.NodeGroupBackoffState{
+			Duration:          10 * time.Minute,
+			BackoffUntil:      metav1.Date(2023, 11, 24, 04, 38, 19, 0, time.UTC),
+			LastFailedScaleUp: metav1.Date(2023, 11, 24, 04, 28, 19, 0, time.UTC),
+			ErrorClass:        1,
+			ErrorCode:         "QUOTA_EXCEEDED",
+			ErrorMessage:      "Not enough CPU",
+		},
+	}}
+	configMap, err := WriteStatusConfigMap(ti.client, ti.namespace, want, nil, "my-cool-configmap", time.Date(2023, 11, 24, 4, 28, 19, 0, time.UTC))
+	assert.NoError(t, err)
+	ti.configMap = configMap
+	want.Time = "2023-11-24 04:28:19 +0000 UTC"
+
+	got, err := ReadStatusConfigMap(ti.client, ti.namespace, "my-cool-configmap")
+	assert.NoError(t, err)
+	assert.Equal(t, &want, got)
+
+	ti.getError = kube_errors.NewNotFound(apiv1.Resource("configmap"), "nope, not found")
+	got, err = ReadStatusConfigMap(ti.client, ti.namespace, "my-cool-configmap")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	ti.getError = errors.New("stuff bad")
+	_, err = ReadStatusConfigMap(ti.client, ti.namespace, "my-cool-configmap")
+	assert.Error(t, err)
+}
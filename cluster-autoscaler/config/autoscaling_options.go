@@ -17,8 +17,10 @@ limitations under the License.
 package config
 
 import (
+	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
 	gce_localssdsize "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/gce/localssdsize"
 	kubelet_config "k8s.io/kubernetes/pkg/kubelet/apis/config"
 	scheduler_config "k8s.io/kubernetes/pkg/scheduler/apis/config"
@@ -54,6 +56,44 @@ type NodeGroupAutoscalingOptions struct {
 	ZeroOrMaxNodeScaling bool
 	// IgnoreDaemonSetsUtilization sets if daemonsets utilization should be considered during node scale-down
 	IgnoreDaemonSetsUtilization bool
+	// MaxConcurrentProvisioning is the maximum number of nodes that can be provisioning (upcoming) in a node group
+	// at the same time. Capacity needed above this limit spills to other node groups. 0 means no limit.
+	MaxConcurrentProvisioning int
+	// MaxScaleDownParallelism is the maximum number of nodes of a node group that can be deleted (both empty
+	// and needing drain) at the same time. 0 means no limit apart from the cluster-wide parallelism limits.
+	MaxScaleDownParallelism int
+	// MaxNodeDrainTime caps the total time CA spends draining a node before scaling it down. 0 means no limit.
+	MaxNodeDrainTime time.Duration
+	// NodeDrainTimeoutPolicy defines what happens to a node whose drain didn't complete within MaxNodeDrainTime:
+	// DrainTimeoutPolicyAbort aborts the node deletion, DrainTimeoutPolicyForceDelete force deletes pods
+	// remaining on the node and proceeds with the deletion.
+	NodeDrainTimeoutPolicy string
+	// Metadata holds user-defined key/value metadata of the node group, e.g. its priority or cost class,
+	// for expanders, scale-down planners and processors to consult.
+	Metadata map[string]string
+}
+
+// MergeNodeGroupMetadata returns the metadata overridden by the options prefixed with
+// NodeGroupMetadataKeyPrefix, with the prefix trimmed. The metadata isn't modified.
+func MergeNodeGroupMetadata(metadata map[string]string, options map[string]string) map[string]string {
+	var merged map[string]string
+	for key, value := range options {
+		name, found := strings.CutPrefix(key, NodeGroupMetadataKeyPrefix)
+		if !found || name == "" {
+			continue
+		}
+		if merged == nil {
+			merged = make(map[string]string, len(metadata)+1)
+			for k, v := range metadata {
+				merged[k] = v
+			}
+		}
+		merged[name] = value
+	}
+	if merged == nil {
+		return metadata
+	}
+	return merged
 }
 
 // GCEOptions contain autoscaling options specific to GCE cloud provider.
@@ -66,6 +106,11 @@ type GCEOptions struct {
 	DomainUrl string
 	// LocalSSDDiskSizeProvider provides local ssd disk size based on machine type
 	LocalSSDDiskSizeProvider gce_localssdsize.LocalSSDSizeProvider
+	// KubeEnvOverridesConfigMap is the name of the ConfigMap in ConfigNamespace with kube-env overrides of MIGs.
+	KubeEnvOverridesConfigMap string
+	// ReservedMemoryModels are reserved memory models of machine families used if kube-env doesn't specify memory
+	// kube-reserved, in the format <machine-family>:<kernel-reserved-percent>:<system-reserved>.
+	ReservedMemoryModels []string
 }
 
 const (
@@ -103,8 +148,14 @@ type AutoscalingOptions struct {
 	NodeGroupDefaults NodeGroupAutoscalingOptions
 	// MaxEmptyBulkDelete is a number of empty nodes that can be removed at the same time.
 	MaxEmptyBulkDelete int
+	// NodeSelector selects the nodes managed by the autoscaler. Other nodes are ignored, e.g. because
+	// another autoscaler manages them. Nil selects all nodes.
+	NodeSelector labels.Selector
 	// MaxNodesTotal sets the maximum number of nodes in the whole cluster
 	MaxNodesTotal int
+	// MaxHourlyCost sets the maximum hourly cost of all nodes in the whole cluster, as computed by the
+	// pricing model of the cloud provider. Scale-ups aren't limited by cost if it's 0.
+	MaxHourlyCost float64
 	// MaxCoresTotal sets the maximum number of cores in the whole cluster
 	MaxCoresTotal int64
 	// MinCoresTotal sets the minimum number of cores in the whole cluster
@@ -125,6 +176,8 @@ type AutoscalingOptions struct {
 	GRPCExpanderCert string
 	// GRPCExpanderURL is the url of the gRPC server when using the gRPC expander
 	GRPCExpanderURL string
+	// GRPCExpanderStreaming tells if the gRPC expander streams options to the gRPC server to be scored one by one
+	GRPCExpanderStreaming bool
 	// IgnoreMirrorPodsUtilization is whether CA will ignore Mirror pods when calculating resource utilization for scaling down
 	IgnoreMirrorPodsUtilization bool
 	// MaxGracefulTerminationSec is maximum number of seconds scale down waits for pods to terminate before
@@ -180,6 +233,9 @@ type AutoscalingOptions struct {
 	// ScaleDownSimulationTimeout defines the maximum time that can be
 	// spent on scale down simulation.
 	ScaleDownSimulationTimeout time.Duration
+	// ScaleDownAntiAffinityGroupsEnabled tells if nodes hosting replicas spread by required pod
+	// anti-affinity on hostname are evaluated for scale down as groups, before being evaluated one by one.
+	ScaleDownAntiAffinityGroupsEnabled bool
 	// SchedulerConfig allows changing configuration of in-tree
 	// scheduler plugins acting on PreFilter and Filter extension points
 	SchedulerConfig *scheduler_config.KubeSchedulerConfiguration
@@ -191,6 +247,9 @@ type AutoscalingOptions struct {
 	StatusConfigMapName string
 	// BalanceSimilarNodeGroups enables logic that identifies node groups with similar machines and tries to balance node count between them.
 	BalanceSimilarNodeGroups bool
+	// BalanceScaleDownMaxSkew is the maximal difference between sizes of similar node groups which
+	// scale-down can introduce when BalanceSimilarNodeGroups is enabled. 0 disables balancing on scale-down.
+	BalanceScaleDownMaxSkew int
 	// ConfigNamespace is the namespace cluster-autoscaler is running in and all related configmaps live in
 	ConfigNamespace string
 	// ClusterName if available
@@ -231,6 +290,11 @@ type AutoscalingOptions struct {
 	BalancingLabels []string
 	// AWSUseStaticInstanceList tells if AWS cloud provider use static instance type list or dynamically fetch from remote APIs.
 	AWSUseStaticInstanceList bool
+	// AWSDrainAZRebalanceTerminations tells if AWS cloud provider reports instances terminated by ASG
+	// AZRebalance as maintenance events, so that their nodes are cordoned and drained.
+	AWSDrainAZRebalanceTerminations bool
+	// AWSSuspendAZRebalance tells if AWS cloud provider suspends the AZRebalance process of the ASGs it manages.
+	AWSSuspendAZRebalance bool
 	// GCEOptions contain autoscaling options specific to GCE cloud provider.
 	GCEOptions GCEOptions
 	// KubeClientOpts specify options for kube client
@@ -252,12 +316,28 @@ type AutoscalingOptions struct {
 	MaxNodeGroupBackoffDuration time.Duration
 	// NodeGroupBackoffResetTimeout is the time after last failed scale-up when the backoff duration is reset.
 	NodeGroupBackoffResetTimeout time.Duration
+	// ResetNodeGroupBackoffOnScaleUpSuccess resets the backoff of a NodeGroup once nodes of its scale-up register.
+	ResetNodeGroupBackoffOnScaleUpSuccess bool
+	// PersistNodeGroupBackoff persists the backoff state of NodeGroups in the status ConfigMap, so that it survives restarts.
+	PersistNodeGroupBackoff bool
 	// MaxScaleDownParallelism is the maximum number of nodes (both empty and needing drain) that can be deleted in parallel.
 	MaxScaleDownParallelism int
 	// MaxDrainParallelism is the maximum number of nodes needing drain, that can be drained and deleted in parallel.
 	MaxDrainParallelism int
 	// RecordDuplicatedEvents controls whether events should be duplicated within a 5 minute window.
 	RecordDuplicatedEvents bool
+	// RecordNoScaleUpPodConditions controls whether unschedulable pods get a condition explaining why they didn't trigger scale-up.
+	RecordNoScaleUpPodConditions bool
+	// OrphanedNodesPolicy controls what happens to nodes whose node group was removed from node group discovery.
+	OrphanedNodesPolicy string
+	// OrphanedNodesGracePeriod is how long nodes have to be missing from all node groups before they're orphaned.
+	OrphanedNodesGracePeriod time.Duration
+	// OrphanedNodesDrainTimeout is how long orphaned nodes are kept cordoned before their pods are evicted and
+	// the nodes are deleted, when the drain orphaned nodes policy is used.
+	OrphanedNodesDrainTimeout time.Duration
+	// MaintenanceLeadTime is how long before the maintenance reported by the cloud provider affected nodes are cordoned
+	// and replacement capacity is provisioned for their pods.
+	MaintenanceLeadTime time.Duration
 	// MaxNodesPerScaleUp controls how many nodes can be added in a single scale-up.
 	// Note that this is strictly a performance optimization aimed at limiting binpacking time, not a tool to rate-limit
 	// scale-up. There is nothing stopping CA from adding MaxNodesPerScaleUp every loop.
@@ -279,6 +359,9 @@ type AutoscalingOptions struct {
 	// MinReplicaCount controls the minimum number of replicas that a replica set or replication controller should have
 	// to allow their pods deletion in scale down
 	MinReplicaCount int
+	// SkipNodesWithVpaEvictions tells if nodes with pods whose controller has pods being evicted by the
+	// Vertical Pod Autoscaler updater should be skipped from deletion
+	SkipNodesWithVpaEvictions bool
 	// NodeDeleteDelayAfterTaint is the duration to wait before deleting a node after tainting it
 	NodeDeleteDelayAfterTaint time.Duration
 	// ParallelDrain is whether CA can drain nodes in parallel.
@@ -292,6 +375,62 @@ type AutoscalingOptions struct {
 	BypassedSchedulers map[string]bool
 	// ProvisioningRequestEnabled tells if CA processes ProvisioningRequest.
 	ProvisioningRequestEnabled bool
+	// ProvisioningRequestReservationTime is how long capacity is booked for Provisioned ProvisioningRequests.
+	ProvisioningRequestReservationTime time.Duration
+	// ProvisioningRequestExpirationTime is how long since their creation CA tries to provision capacity
+	// for ProvisioningRequests without the ValidUntilSeconds parameter.
+	ProvisioningRequestExpirationTime time.Duration
+	// AdmissionPolicySimulationEnabled tells if simulated pod placements are checked against ValidatingAdmissionPolicies.
+	AdmissionPolicySimulationEnabled bool
+	// RuntimeClassSimulationEnabled tells if RuntimeClass overhead and handler node labels are simulated on template nodes.
+	RuntimeClassSimulationEnabled bool
+	// StartupCleanupTaintPrefixes is a list of taint key prefixes removed from nodes on startup, in addition to
+	// taints added by a previous run of CA.
+	StartupCleanupTaintPrefixes []string
+	// DryRun makes CA run the full scale-up and scale-down logic and record the decisions it would have
+	// executed through events, the status ConfigMap and metrics, without resizing node groups,
+	// tainting, draining and deleting nodes or mutating cloud resources. Cloud providers receive it
+	// to skip the changes they make on their own, e.g. deleting servers which didn't register.
+	DryRun bool
+	// StartupCleanupTaintsDryRun makes CA only log taints matching StartupCleanupTaintPrefixes instead of removing them.
+	StartupCleanupTaintsDryRun bool
+	// PriorityExpanderFallback defines how the priority expander handles expansion options not matching any priority.
+	PriorityExpanderFallback string
+	// FlapDampingWindow is how long after removing nodes of a shape the flap-damping expander penalizes options
+	// re-creating nodes of the same shape.
+	FlapDampingWindow time.Duration
+	// ScaleDownPreferExpensiveNodeGroups makes CA scale down nodes from more expensive node groups first
+	// among equally removable nodes.
+	ScaleDownPreferExpensiveNodeGroups bool
+	// EstimatorNumaCells is the number of NUMA cells new nodes are treated as when estimating scale-ups. Pods with
+	// the Guaranteed QoS class have to fit in a single cell, as with the single-numa-node topology manager policy.
+	// Values lower than 2 disable it.
+	EstimatorNumaCells int
+	// VerifyClusterSnapshotRevisions makes CA check that pod list processors modify the cluster snapshot only through
+	// its API, and rebuild the snapshot if they don't, as modifications bypassing it leave cached data stale.
+	VerifyClusterSnapshotRevisions bool
+	// CapacityBuffersEnabled tells if CA keeps spare capacity for pods declared by CapacityBuffer CRs.
+	CapacityBuffersEnabled bool
+	// ScaleUpHintsEnabled tells if CA provisions capacity for the pods of imminent workloads declared
+	// by scale-up hints, i.e. ConfigMaps and custom resources with the provision-for annotation.
+	ScaleUpHintsEnabled bool
+	// ScaleUpHintsResources lists the custom resources, in the resource.version.group format,
+	// which are checked for scale-up hints in addition to ConfigMaps.
+	ScaleUpHintsResources []string
+	// NamespaceScaleUpQuotasEnabled tells if CA limits the number of nodes pending pods of a namespace
+	// may trigger, as configured in the namespace quotas ConfigMap.
+	NamespaceScaleUpQuotasEnabled bool
+	// ScaleUpFingerprintsEnabled tells if CA persists recent scale-ups, so that pods which triggered
+	// a scale-up don't trigger another one after a restart, before the nodes register.
+	ScaleUpFingerprintsEnabled bool
+	// NodeGroupRediscoveryInterval is how often node group definitions are rebuilt from the cloud provider,
+	// bypassing its caches. 0 disables periodic rediscovery.
+	NodeGroupRediscoveryInterval time.Duration
+	// HealthProbeInterval is how often CA checks that a synthetic unschedulable pod would trigger
+	// scale-up, without scaling up. 0 disables the health probe.
+	HealthProbeInterval time.Duration
+	// HealthProbeNamespace is the namespace of the health probe pod. Defaults to ConfigNamespace.
+	HealthProbeNamespace string
 }
 
 // KubeClientOptions specify options for kube client
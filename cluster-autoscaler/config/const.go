@@ -40,6 +40,22 @@ const (
 	DefaultMaxNodeProvisionTimeKey = "maxnodeprovisiontime"
 	// DefaultIgnoreDaemonSetsUtilizationKey identifies IgnoreDaemonSetsUtilization autoscaling option
 	DefaultIgnoreDaemonSetsUtilizationKey = "ignoredaemonsetsutilization"
+	// DefaultMaxConcurrentProvisioningKey identifies MaxConcurrentProvisioning autoscaling option
+	DefaultMaxConcurrentProvisioningKey = "maxconcurrentprovisioning"
+	// DefaultMaxScaleDownParallelismKey identifies MaxScaleDownParallelism autoscaling option
+	DefaultMaxScaleDownParallelismKey = "maxscaledownparallelism"
+	// DefaultMaxNodeDrainTimeKey identifies MaxNodeDrainTime autoscaling option
+	DefaultMaxNodeDrainTimeKey = "maxnodedraintime"
+	// DefaultNodeDrainTimeoutPolicyKey identifies NodeDrainTimeoutPolicy autoscaling option
+	DefaultNodeDrainTimeoutPolicyKey = "nodedraintimeoutpolicy"
+	// NodeGroupMetadataKeyPrefix prefixes autoscaling options which are node group Metadata
+	NodeGroupMetadataKeyPrefix = "metadata."
+
+	// DrainTimeoutPolicyAbort aborts deletion of nodes whose drain exceeded MaxNodeDrainTime.
+	DrainTimeoutPolicyAbort = "abort"
+	// DrainTimeoutPolicyForceDelete force deletes pods remaining on nodes whose drain exceeded MaxNodeDrainTime
+	// and proceeds with their deletion.
+	DrainTimeoutPolicyForceDelete = "force-delete"
 
 	// DefaultScaleDownUnneededTime is the default time duration for which CA waits before deleting an unneeded node
 	DefaultScaleDownUnneededTime = 10 * time.Minute
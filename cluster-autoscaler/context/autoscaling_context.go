@@ -124,7 +124,7 @@ func NewAutoscalingContext(
 
 // NewAutoscalingKubeClients builds AutoscalingKubeClients out of basic client.
 func NewAutoscalingKubeClients(opts config.AutoscalingOptions, kubeClient kube_client.Interface, informerFactory informers.SharedInformerFactory) *AutoscalingKubeClients {
-	listerRegistry := kube_util.NewListerRegistryWithDefaultListers(informerFactory)
+	listerRegistry := kube_util.NewListerRegistryWithDefaultListers(informerFactory, opts.NodeSelector)
 	kubeEventRecorder := kube_util.CreateEventRecorder(kubeClient, opts.RecordDuplicatedEvents)
 	logRecorder, err := utils.NewStatusMapRecorder(kubeClient, opts.ConfigNamespace, kubeEventRecorder, opts.WriteStatusConfigMap, opts.StatusConfigMapName)
 	if err != nil {
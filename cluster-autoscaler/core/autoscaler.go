@@ -22,14 +22,19 @@ import (
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	cloudBuilder "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/builder"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/rediscovery"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup"
 	"k8s.io/autoscaler/cluster-autoscaler/debuggingsnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/estimator"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/factory"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/flapdamping"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/priority"
 	"k8s.io/autoscaler/cluster-autoscaler/observers/loopstart"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
@@ -40,6 +45,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
 	"k8s.io/client-go/informers"
 	kube_client "k8s.io/client-go/kubernetes"
+	klog "k8s.io/klog/v2"
 )
 
 // AutoscalerOptions is the whole set of options for configuring an autoscaler
@@ -61,6 +67,7 @@ type AutoscalerOptions struct {
 	ScaleUpOrchestrator    scaleup.Orchestrator
 	DeleteOptions          options.NodeDeleteOptions
 	DrainabilityRules      rules.Rules
+	RediscoveryTrigger     *rediscovery.Trigger
 }
 
 // Autoscaler is the main component of CA which scales up/down node groups according to its configuration
@@ -76,6 +83,8 @@ type Autoscaler interface {
 	LastScaleUpTime() time.Time
 	// LastScaleUpTime is a time of the last scale down
 	LastScaleDownDeleteTime() time.Time
+	// IsActive returns true if the last iteration found unschedulable pods or scaling in flight
+	IsActive() bool
 }
 
 // NewAutoscaler creates an autoscaler of an appropriate type according to the parameters
@@ -84,7 +93,7 @@ func NewAutoscaler(opts AutoscalerOptions, informerFactory informers.SharedInfor
 	if err != nil {
 		return nil, errors.ToAutoscalerError(errors.InternalError, err)
 	}
-	return NewStaticAutoscaler(
+	autoscaler := NewStaticAutoscaler(
 		opts.AutoscalingOptions,
 		opts.PredicateChecker,
 		opts.ClusterSnapshot,
@@ -100,11 +109,21 @@ func NewAutoscaler(opts AutoscalerOptions, informerFactory informers.SharedInfor
 		opts.ScaleUpOrchestrator,
 		opts.DeleteOptions,
 		opts.DrainabilityRules,
-	), nil
+	)
+	autoscaler.nodeGroupRediscovery = rediscovery.NewNodeGroupRediscovery(opts.CloudProvider, opts.NodeGroupRediscoveryInterval, opts.RediscoveryTrigger, time.Now())
+	return autoscaler, nil
 }
 
 // Initialize default options if not provided.
 func initializeDefaultOptions(opts *AutoscalerOptions, informerFactory informers.SharedInformerFactory) error {
+	// Backoff is restored before AutoscalingKubeClients reinitialize the status ConfigMap it's persisted in.
+	if opts.Backoff == nil {
+		nodeGroupBackoff := backoff.NewIdBasedExponentialBackoff(opts.InitialNodeGroupBackoffDuration, opts.MaxNodeGroupBackoffDuration, opts.NodeGroupBackoffResetTimeout)
+		if opts.PersistNodeGroupBackoff {
+			restoreNodeGroupBackoff(opts, nodeGroupBackoff)
+		}
+		opts.Backoff = nodeGroupBackoff
+	}
 	if opts.Processors == nil {
 		opts.Processors = ca_processors.DefaultProcessors(opts.AutoscalingOptions)
 	}
@@ -125,7 +144,12 @@ func initializeDefaultOptions(opts *AutoscalerOptions, informerFactory informers
 	}
 	if opts.ExpanderStrategy == nil {
 		expanderFactory := factory.NewFactory()
-		expanderFactory.RegisterDefaultExpanders(opts.CloudProvider, opts.AutoscalingKubeClients, opts.KubeClient, opts.ConfigNamespace, opts.GRPCExpanderCert, opts.GRPCExpanderURL)
+		expanderFactory.RegisterDefaultExpanders(opts.CloudProvider, opts.AutoscalingKubeClients, opts.KubeClient, opts.ConfigNamespace, opts.GRPCExpanderCert, opts.GRPCExpanderURL, opts.GRPCExpanderStreaming, priority.FallbackStrategy(opts.PriorityExpanderFallback))
+		expanderFactory.RegisterFilter(expander.FlapDampingExpanderName, func() expander.Filter {
+			filter := flapdamping.NewFilter(opts.FlapDampingWindow)
+			opts.Processors.ScaleStateNotifier.Register(filter)
+			return filter
+		})
 		expanderStrategy, err := expanderFactory.Build(strings.Split(opts.ExpanderNames, ","))
 		if err != nil {
 			return err
@@ -143,19 +167,35 @@ func initializeDefaultOptions(opts *AutoscalerOptions, informerFactory informers
 			estimator.NewThresholdBasedEstimationLimiter(thresholds),
 			estimator.NewDecreasingPodOrderer(),
 			/* EstimationAnalyserFunc */ nil,
+			opts.EstimatorNumaCells,
 		)
 		if err != nil {
 			return err
 		}
 		opts.EstimatorBuilder = estimatorBuilder
 	}
-	if opts.Backoff == nil {
-		opts.Backoff =
-			backoff.NewIdBasedExponentialBackoff(opts.InitialNodeGroupBackoffDuration, opts.MaxNodeGroupBackoffDuration, opts.NodeGroupBackoffResetTimeout)
-	}
 	if opts.DrainabilityRules == nil {
 		opts.DrainabilityRules = rules.Default(opts.DeleteOptions)
 	}
 
 	return nil
 }
+
+// restoreNodeGroupBackoff restores the backoff state of node groups persisted in the status ConfigMap.
+func restoreNodeGroupBackoff(opts *AutoscalerOptions, nodeGroupBackoff backoff.PersistentBackoff) {
+	if !opts.WriteStatusConfigMap || opts.KubeClient == nil {
+		klog.Warningf("Node group backoff can't be persisted without writing the status ConfigMap")
+		return
+	}
+	status, err := utils.ReadStatusConfigMap(opts.KubeClient, opts.ConfigNamespace, opts.StatusConfigMapName)
+	if err != nil {
+		klog.Warningf("Failed to restore node group backoff: %v", err)
+		return
+	}
+	if status == nil {
+		return
+	}
+	state := clusterstate.BackoffStateFromStatus(status)
+	nodeGroupBackoff.Restore(state)
+	klog.V(1).Infof("Restored backoff of %d node groups from the status ConfigMap", len(state))
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthprobe
+
+import (
+	ctx "context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const (
+	// ProbePodTemplateName is the name of the optional PodTemplate in the probe
+	// namespace the synthetic pod is built from.
+	ProbePodTemplateName = "cluster-autoscaler-health-probe"
+	// ProbePodName is the name of the synthetic pod.
+	ProbePodName = "cluster-autoscaler-health-probe"
+)
+
+// Prober periodically checks that a synthetic unschedulable pod would trigger a
+// scale-up, without scaling up. The pod goes through the same scale-up logic as
+// real pods in a forked cluster snapshot, including node group limits, resource
+// limits and the expander, so that silent misconfigurations, e.g. broken templates
+// or node groups missing from discovery, which would prevent scale-up of real pods
+// are caught before such pods show up. Results are exported as metrics.
+type Prober struct {
+	interval  time.Duration
+	namespace string
+	simulator scaleup.Simulator
+	lastProbe time.Time
+}
+
+// NewProber returns a new Prober probing every interval with a pod in the given
+// namespace, simulating scale-ups with the simulator, or nil if the interval
+// isn't positive.
+func NewProber(interval time.Duration, namespace string, simulator scaleup.Simulator) *Prober {
+	if interval <= 0 {
+		return nil
+	}
+	return &Prober{
+		interval:  interval,
+		namespace: namespace,
+		simulator: simulator,
+	}
+}
+
+// ProbeIfNeeded probes if the interval passed since the last probe. It has to be
+// called with the cluster snapshot initialized and template node infos of the
+// node groups, before pods are scheduled in the snapshot by the simulations.
+func (p *Prober) ProbeIfNeeded(context *context.AutoscalingContext, nodes []*apiv1.Node, daemonSets []*appsv1.DaemonSet, nodeInfos map[string]*schedulerframework.NodeInfo, now time.Time) {
+	if !p.lastProbe.IsZero() && now.Sub(p.lastProbe) < p.interval {
+		return
+	}
+	p.lastProbe = now
+
+	pod, err := p.probePod(context, now)
+	if err != nil {
+		klog.Errorf("Health probe failed to build the probe pod: %v", err)
+		metrics.RegisterHealthProbe(metrics.HealthProbeError)
+		return
+	}
+	nodeGroups, reasons, aErr := Probe(context, p.simulator, pod, nodes, daemonSets, nodeInfos)
+	if len(nodeGroups) > 0 {
+		klog.V(2).Infof("Health probe pod %s/%s would trigger scale-up of %v", pod.Namespace, pod.Name, nodeGroups)
+		metrics.RegisterHealthProbe(metrics.HealthProbeHealthy)
+		return
+	}
+	if aErr != nil {
+		// Real pods would fail to trigger scale-up the same way, e.g. when the
+		// max node total count is reached.
+		klog.Warningf("Health probe pod %s/%s wouldn't trigger scale-up of any node group: %v", pod.Namespace, pod.Name, aErr)
+	} else {
+		klog.Warningf("Health probe pod %s/%s wouldn't trigger scale-up of any node group: %v", pod.Namespace, pod.Name, reasons)
+	}
+	context.LogRecorder.Eventf(apiv1.EventTypeWarning, "HealthProbeFailed",
+		"Health probe pod %s/%s wouldn't trigger scale-up of any node group", pod.Namespace, pod.Name)
+	metrics.RegisterHealthProbe(metrics.HealthProbeUnhealthy)
+}
+
+// Probe simulates a scale-up for the pod in a forked cluster snapshot. Returns
+// sorted ids of node groups which would be scaled up, and reasons why the other
+// node groups wouldn't be, if known.
+func Probe(context *context.AutoscalingContext, simulator scaleup.Simulator, pod *apiv1.Pod, nodes []*apiv1.Node, daemonSets []*appsv1.DaemonSet, nodeInfos map[string]*schedulerframework.NodeInfo) ([]string, map[string]string, errors.AutoscalerError) {
+	context.ClusterSnapshot.Fork()
+	defer context.ClusterSnapshot.Revert()
+
+	scaleUpStatus, aErr := simulator.SimulateScaleUp([]*apiv1.Pod{pod}, nodes, daemonSets, nodeInfos)
+	if aErr != nil {
+		return nil, nil, aErr
+	}
+	var scaledUp []string
+	if scaleUpStatus.Result == status.ScaleUpSuccessful {
+		for _, info := range scaleUpStatus.ScaleUpInfos {
+			scaledUp = append(scaledUp, info.Group.Id())
+		}
+	}
+	sort.Strings(scaledUp)
+	reasons := make(map[string]string)
+	for _, noScaleUp := range scaleUpStatus.PodsRemainUnschedulable {
+		for _, nodeGroupReasons := range []map[string]status.Reasons{noScaleUp.RejectedNodeGroups, noScaleUp.SkippedNodeGroups} {
+			for id, reason := range nodeGroupReasons {
+				reasons[id] = strings.Join(reason.Reasons(), ", ")
+			}
+		}
+	}
+	return scaledUp, reasons, nil
+}
+
+// probePod builds the synthetic pod from the ProbePodTemplateName PodTemplate,
+// or requests minimal resources if there's no such template.
+func (p *Prober) probePod(context *context.AutoscalingContext, now time.Time) (*apiv1.Pod, error) {
+	pod := &apiv1.Pod{
+		Spec: apiv1.PodSpec{
+			Containers: []apiv1.Container{{
+				Name: "probe",
+				Resources: apiv1.ResourceRequirements{
+					Requests: apiv1.ResourceList{
+						apiv1.ResourceCPU:    resource.MustParse("10m"),
+						apiv1.ResourceMemory: resource.MustParse("10Mi"),
+					},
+				},
+			}},
+		},
+	}
+	template, err := context.ClientSet.CoreV1().PodTemplates(p.namespace).Get(ctx.TODO(), ProbePodTemplateName, metav1.GetOptions{})
+	if err == nil {
+		pod.ObjectMeta = *template.Template.ObjectMeta.DeepCopy()
+		pod.Spec = *template.Template.Spec.DeepCopy()
+	} else if !kube_errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get PodTemplate %s/%s: %v", p.namespace, ProbePodTemplateName, err)
+	}
+	pod.Name = ProbePodName
+	pod.Namespace = p.namespace
+	pod.UID = types.UID(fmt.Sprintf("%s/%s", p.namespace, ProbePodName))
+	pod.CreationTimestamp = metav1.NewTime(now)
+	pod.Status.Phase = apiv1.PodPending
+	pod.Status.Conditions = []apiv1.PodCondition{{
+		Type:   apiv1.PodScheduled,
+		Status: apiv1.ConditionFalse,
+		Reason: apiv1.PodReasonUnschedulable,
+	}}
+	return pod, nil
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthprobe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/orchestrator"
+	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func testContext(t *testing.T, options config.AutoscalingOptions, objects ...*apiv1.PodTemplate) (*context.AutoscalingContext, scaleup.Simulator, map[string]*schedulerframework.NodeInfo) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng-large", 0, 10, 0)
+	provider.AddNodeGroup("ng-small", 0, 10, 0)
+	provider.AddNodeGroup("ng-disabled", 0, 0, 0)
+	provider.AddNodeGroup("ng-full", 0, 1, 1)
+	provider.AddNodeGroup("ng-no-template", 0, 10, 0)
+
+	nodeInfos := make(map[string]*schedulerframework.NodeInfo)
+	for id, millicpu := range map[string]int64{"ng-large": 4000, "ng-small": 5, "ng-disabled": 4000, "ng-full": 4000} {
+		node := BuildTestNode("template-node-for-"+id, millicpu, 16*1024*1024*1024)
+		node.Labels["pool"] = id
+		SetNodeReadyState(node, true, time.Time{})
+		nodeInfo := schedulerframework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		nodeInfos[id] = nodeInfo
+	}
+
+	client := fake.NewSimpleClientset()
+	for _, object := range objects {
+		client.Tracker().Add(object)
+	}
+	options.EstimatorName = estimator.BinpackingEstimatorName
+	autoscalingContext, err := NewScaleTestAutoscalingContext(options, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, autoscalingContext.LogRecorder, NewBackoff(),
+		nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+	assert.NoError(t, clusterState.UpdateNodes(nil, nodeInfos, time.Now()))
+	estimatorBuilder, err := estimator.NewEstimatorBuilder(estimator.BinpackingEstimatorName, estimator.NewThresholdBasedEstimationLimiter(nil), estimator.NewDecreasingPodOrderer(), nil, 0)
+	assert.NoError(t, err)
+	scaleUpOrchestrator := orchestrator.New()
+	scaleUpOrchestrator.Initialize(&autoscalingContext, NewTestProcessors(&autoscalingContext), clusterState, estimatorBuilder, taints.TaintConfig{})
+	return &autoscalingContext, scaleUpOrchestrator, nodeInfos
+}
+
+func defaultOptions() config.AutoscalingOptions {
+	return config.AutoscalingOptions{
+		MaxCoresTotal:  config.DefaultMaxClusterCores,
+		MaxMemoryTotal: config.DefaultMaxClusterMemory,
+	}
+}
+
+func TestProbe(t *testing.T) {
+	now := time.Now()
+	autoscalingContext, simulator, nodeInfos := testContext(t, defaultOptions())
+	prober := NewProber(time.Minute, "kube-system", simulator)
+
+	pod, err := prober.probePod(autoscalingContext, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "kube-system", pod.Namespace)
+	assert.Equal(t, ProbePodName, pod.Name)
+
+	nodeGroups, reasons, aErr := Probe(autoscalingContext, simulator, pod, nil, nil, nodeInfos)
+	assert.Nil(t, aErr)
+	assert.Equal(t, []string{"ng-large"}, nodeGroups)
+	assert.Empty(t, reasons)
+
+	// Nothing is scaled up.
+	for _, nodeGroup := range autoscalingContext.CloudProvider.NodeGroups() {
+		size, err := nodeGroup.TargetSize()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"ng-full": 1}[nodeGroup.Id()], size)
+	}
+	nodeInfoList, err := autoscalingContext.ClusterSnapshot.NodeInfos().List()
+	assert.NoError(t, err)
+	assert.Empty(t, nodeInfoList)
+}
+
+func TestProbeMaxNodesTotalReached(t *testing.T) {
+	options := defaultOptions()
+	// Upcoming node of ng-full is the last one allowed in the cluster.
+	options.MaxNodesTotal = 1
+	autoscalingContext, simulator, nodeInfos := testContext(t, options)
+	prober := NewProber(time.Minute, "kube-system", simulator)
+
+	pod, err := prober.probePod(autoscalingContext, time.Now())
+	assert.NoError(t, err)
+	nodeGroups, _, aErr := Probe(autoscalingContext, simulator, pod, nil, nil, nodeInfos)
+	assert.NotNil(t, aErr)
+	assert.Contains(t, aErr.Error(), "max node total count already reached")
+	assert.Empty(t, nodeGroups)
+}
+
+func TestProbePodFromTemplate(t *testing.T) {
+	template := &apiv1.PodTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: ProbePodTemplateName, Namespace: "probes"},
+		Template: apiv1.PodTemplateSpec{
+			Spec: apiv1.PodSpec{
+				NodeSelector: map[string]string{"pool": "ng-gpu"},
+				Containers:   []apiv1.Container{{Name: "c"}},
+			},
+		},
+	}
+	autoscalingContext, simulator, nodeInfos := testContext(t, defaultOptions(), template)
+	prober := NewProber(time.Minute, "probes", simulator)
+
+	pod, err := prober.probePod(autoscalingContext, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, "probes", pod.Namespace)
+	assert.Equal(t, map[string]string{"pool": "ng-gpu"}, pod.Spec.NodeSelector)
+
+	nodeGroups, reasons, aErr := Probe(autoscalingContext, simulator, pod, nil, nil, nodeInfos)
+	assert.Nil(t, aErr)
+	assert.Empty(t, nodeGroups)
+	assert.Contains(t, reasons, "ng-large")
+}
+
+func TestProbeIfNeeded(t *testing.T) {
+	now := time.Now()
+	autoscalingContext, simulator, nodeInfos := testContext(t, defaultOptions())
+	assert.Nil(t, NewProber(0, "kube-system", simulator))
+	prober := NewProber(time.Minute, "kube-system", simulator)
+
+	prober.ProbeIfNeeded(autoscalingContext, nil, nil, nodeInfos, now)
+	assert.Equal(t, now, prober.lastProbe)
+	prober.ProbeIfNeeded(autoscalingContext, nil, nil, nodeInfos, now.Add(30*time.Second))
+	assert.Equal(t, now, prober.lastProbe)
+	prober.ProbeIfNeeded(autoscalingContext, nil, nil, nodeInfos, now.Add(time.Minute))
+	assert.Equal(t, now.Add(time.Minute), prober.lastProbe)
+}
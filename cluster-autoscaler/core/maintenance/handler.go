@@ -0,0 +1,243 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/actuation"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/scheduling"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Handler reacts to maintenance events reported by the cloud provider. Nodes
+// with maintenance starting within the lead time are tainted and cordoned, so
+// that their pods are treated as pending and replacement capacity is
+// provisioned by scale-up. Once the pods fit elsewhere in the cluster, or the
+// maintenance is about to start, the nodes are drained.
+type Handler struct {
+	provider cloudprovider.MaintenanceEventsProvider
+	leadTime time.Duration
+
+	mutex sync.Mutex
+	// draining holds names of nodes which are currently being drained.
+	draining map[string]bool
+	// drained holds names of nodes which were drained already.
+	drained map[string]bool
+	// drainNode is used to drain nodes, replaced in tests.
+	drainNode func(ctx *context.AutoscalingContext, nodeInfo *schedulerframework.NodeInfo) error
+}
+
+// NewHandler returns a new Handler, or nil if the cloud provider doesn't
+// report maintenance events.
+func NewHandler(cloudProvider cloudprovider.CloudProvider, leadTime time.Duration) *Handler {
+	provider, ok := cloudProvider.(cloudprovider.MaintenanceEventsProvider)
+	if !ok {
+		return nil
+	}
+	return &Handler{
+		provider:  provider,
+		leadTime:  leadTime,
+		draining:  make(map[string]bool),
+		drained:   make(map[string]bool),
+		drainNode: drainNode,
+	}
+}
+
+// Update fetches maintenance events, taints nodes affected by upcoming
+// maintenance and starts draining them when it is safe or can't be postponed
+// anymore. Taints are removed from nodes whose maintenance is no longer
+// reported. Returns nodes affected by upcoming maintenance.
+func (h *Handler) Update(ctx *context.AutoscalingContext, nodes []*apiv1.Node, now time.Time) []*apiv1.Node {
+	events, err := h.provider.MaintenanceEvents()
+	if err != nil {
+		klog.Errorf("Failed to get maintenance events: %v", err)
+		return nil
+	}
+	eventsByProviderID := make(map[string]cloudprovider.MaintenanceEvent, len(events))
+	for _, event := range events {
+		if existing, found := eventsByProviderID[event.ProviderID]; !found || event.NotBefore.Before(existing.NotBefore) {
+			eventsByProviderID[event.ProviderID] = event
+		}
+	}
+
+	var affected []*apiv1.Node
+	affectedEvents := make(map[string]cloudprovider.MaintenanceEvent)
+	seen := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		seen[node.Name] = true
+		event, found := eventsByProviderID[node.Spec.ProviderID]
+		if !found || now.Before(event.NotBefore.Add(-h.leadTime)) {
+			h.release(ctx, node)
+			continue
+		}
+		if !taints.HasTaint(node, taints.MaintenanceScheduledTaint) {
+			h.taint(ctx, node, event)
+		}
+		affected = append(affected, node)
+		affectedEvents[node.Name] = event
+	}
+	h.mutex.Lock()
+	for name := range h.drained {
+		if !seen[name] {
+			delete(h.drained, name)
+		}
+	}
+	h.mutex.Unlock()
+
+	isNodeAcceptable := func(nodeInfo *schedulerframework.NodeInfo) bool {
+		_, found := affectedEvents[nodeInfo.Node().Name]
+		return !found
+	}
+	for _, node := range affected {
+		if h.isDrainingOrDrained(node.Name) {
+			continue
+		}
+		event := affectedEvents[node.Name]
+		deadline := event.NotBefore.Add(-time.Duration(ctx.MaxGracefulTerminationSec) * time.Second)
+		if now.Before(deadline) && !h.podsFitElsewhere(ctx, node, isNodeAcceptable) {
+			klog.V(2).Infof("Waiting for replacement capacity before draining node %s, maintenance starts at %v", node.Name, event.NotBefore)
+			continue
+		}
+		h.startDrain(ctx, node)
+	}
+
+	metrics.UpdateNodesWithScheduledMaintenanceCount(len(affected))
+	if len(affected) > 0 {
+		ctx.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScheduledMaintenance",
+			"%d nodes are affected by upcoming maintenance", len(affected))
+	}
+	return affected
+}
+
+func (h *Handler) taint(ctx *context.AutoscalingContext, node *apiv1.Node, event cloudprovider.MaintenanceEvent) {
+	klog.V(1).Infof("Node %s is affected by %s maintenance starting at %v, cordoning the node", node.Name, event.Type, event.NotBefore)
+	ctx.Recorder.Eventf(node, apiv1.EventTypeWarning, "MaintenanceScheduled",
+		"%s maintenance starts at %v, node is cordoned and will be drained: %s", event.Type, event.NotBefore, event.Description)
+	taint := apiv1.Taint{
+		Key:    taints.MaintenanceScheduledTaint,
+		Value:  fmt.Sprint(event.NotBefore.Unix()),
+		Effect: apiv1.TaintEffectNoSchedule,
+	}
+	if err := taints.AddTaints(node, ctx.ClientSet, []apiv1.Taint{taint}, true); err != nil {
+		klog.Errorf("Failed to taint node %s affected by maintenance: %v", node.Name, err)
+	}
+}
+
+// release removes the maintenance taint from a node which is no longer
+// affected by upcoming maintenance.
+func (h *Handler) release(ctx *context.AutoscalingContext, node *apiv1.Node) {
+	h.mutex.Lock()
+	draining := h.draining[node.Name]
+	delete(h.drained, node.Name)
+	h.mutex.Unlock()
+	if draining || !taints.HasTaint(node, taints.MaintenanceScheduledTaint) {
+		return
+	}
+	if _, err := taints.CleanTaints(node, ctx.ClientSet, []string{taints.MaintenanceScheduledTaint}, true); err != nil {
+		klog.Errorf("Failed to remove %s taint from node %s: %v", taints.MaintenanceScheduledTaint, node.Name, err)
+		return
+	}
+	klog.V(1).Infof("Node %s is no longer affected by maintenance", node.Name)
+	ctx.Recorder.Eventf(node, apiv1.EventTypeNormal, "MaintenanceCompleted", "node is no longer affected by maintenance")
+}
+
+// podsFitElsewhere checks if pods of the node can be scheduled on other nodes
+// not affected by maintenance.
+func (h *Handler) podsFitElsewhere(ctx *context.AutoscalingContext, node *apiv1.Node, isNodeAcceptable func(*schedulerframework.NodeInfo) bool) bool {
+	nodeInfo, err := ctx.ClusterSnapshot.NodeInfos().Get(node.Name)
+	if err != nil {
+		klog.Warningf("Couldn't get node %s info: %v", node.Name, err)
+		return false
+	}
+	var pods []*apiv1.Pod
+	for _, podInfo := range nodeInfo.Pods {
+		if podInfo.Pod.DeletionTimestamp == nil {
+			pods = append(pods, podInfo.Pod)
+		}
+	}
+	pods = pod_util.ClearPodNodeNames(pod_util.FilterRecreatablePods(pods))
+	if len(pods) == 0 {
+		return true
+	}
+
+	ctx.ClusterSnapshot.Fork()
+	defer ctx.ClusterSnapshot.Revert()
+	statuses, _, err := scheduling.NewHintingSimulator(ctx.PredicateChecker).TrySchedulePods(ctx.ClusterSnapshot, pods, isNodeAcceptable, true)
+	if err != nil {
+		klog.Errorf("Failed to simulate scheduling of pods from node %s: %v", node.Name, err)
+		return false
+	}
+	return len(statuses) == len(pods)
+}
+
+func (h *Handler) isDrainingOrDrained(nodeName string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.draining[nodeName] || h.drained[nodeName]
+}
+
+func (h *Handler) startDrain(ctx *context.AutoscalingContext, node *apiv1.Node) {
+	nodeInfo, err := ctx.ClusterSnapshot.NodeInfos().Get(node.Name)
+	if err != nil {
+		klog.Warningf("Couldn't get node %s info, not draining it: %v", node.Name, err)
+		return
+	}
+	nodeInfo = nodeInfo.Snapshot()
+
+	h.mutex.Lock()
+	h.draining[node.Name] = true
+	h.mutex.Unlock()
+	klog.V(1).Infof("Draining node %s ahead of maintenance", node.Name)
+	ctx.Recorder.Eventf(node, apiv1.EventTypeNormal, "MaintenanceDrain", "draining node ahead of maintenance")
+
+	go func() {
+		err := h.drainNode(ctx, nodeInfo)
+		h.mutex.Lock()
+		delete(h.draining, node.Name)
+		if err == nil {
+			h.drained[node.Name] = true
+		}
+		h.mutex.Unlock()
+		if err != nil {
+			klog.Errorf("Failed to drain node %s ahead of maintenance: %v", node.Name, err)
+			ctx.Recorder.Eventf(node, apiv1.EventTypeWarning, "MaintenanceDrainFailed", "failed to drain node ahead of maintenance: %v", err)
+			return
+		}
+		klog.V(1).Infof("Node %s drained ahead of maintenance", node.Name)
+	}()
+}
+
+func drainNode(ctx *context.AutoscalingContext, nodeInfo *schedulerframework.NodeInfo) error {
+	var evictor actuation.Evictor
+	if len(ctx.DrainPriorityConfig) > 0 {
+		evictor = actuation.NewEvictor(nil, ctx.DrainPriorityConfig, true)
+	} else {
+		evictor = actuation.NewEvictor(nil, actuation.SingleRuleDrainConfig(ctx.MaxGracefulTerminationSec), false)
+	}
+	_, err := evictor.DrainNode(ctx, nodeInfo)
+	return err
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package maintenance
+
+import (
+	ctx "context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type maintenanceCloudProvider struct {
+	*testprovider.TestCloudProvider
+	events []cloudprovider.MaintenanceEvent
+}
+
+func (p *maintenanceCloudProvider) MaintenanceEvents() ([]cloudprovider.MaintenanceEvent, error) {
+	return p.events, nil
+}
+
+func TestNewHandler(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	assert.Nil(t, NewHandler(provider, time.Hour))
+	assert.NotNil(t, NewHandler(&maintenanceCloudProvider{TestCloudProvider: provider}, time.Hour))
+}
+
+func TestHandlerUpdate(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	n3 := BuildTestNode("n3", 1000, 1000)
+	p1 := SetRSPodSpec(BuildScheduledTestPod("p1", 600, 100, "n1"), "rs")
+	p2 := SetRSPodSpec(BuildScheduledTestPod("p2", 600, 100, "n2"), "rs")
+	client := fake.NewSimpleClientset(n1, n2, n3)
+
+	provider := &maintenanceCloudProvider{TestCloudProvider: testprovider.NewTestCloudProvider(nil, nil)}
+	autoscalingContext, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{MaxGracefulTerminationSec: 60}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+
+	drained := make(chan string, 10)
+	handler := NewHandler(provider, 30*time.Minute)
+	handler.drainNode = func(_ *context.AutoscalingContext, nodeInfo *schedulerframework.NodeInfo) error {
+		drained <- nodeInfo.Node().Name
+		return nil
+	}
+
+	getNode := func(name string) *apiv1.Node {
+		node, err := client.CoreV1().Nodes().Get(ctx.TODO(), name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return node
+	}
+	update := func(now time.Time, nodes ...*apiv1.Node) []*apiv1.Node {
+		var currentNodes []*apiv1.Node
+		for _, node := range nodes {
+			currentNodes = append(currentNodes, getNode(node.Name))
+		}
+		clustersnapshot.InitializeClusterSnapshotOrDie(t, autoscalingContext.ClusterSnapshot, currentNodes, []*apiv1.Pod{p1, p2})
+		return handler.Update(&autoscalingContext, currentNodes, now)
+	}
+	assertNotDrained := func() {
+		select {
+		case name := <-drained:
+			t.Errorf("unexpected drain of node %s", name)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	assertDrained := func(want string) {
+		select {
+		case name := <-drained:
+			assert.Equal(t, want, name)
+		case <-time.After(time.Second):
+			t.Errorf("node %s wasn't drained", want)
+		}
+	}
+
+	// Maintenance beyond the lead time is ignored.
+	provider.events = []cloudprovider.MaintenanceEvent{{ProviderID: n1.Spec.ProviderID, Type: cloudprovider.MaintenanceEventReboot, NotBefore: now.Add(time.Hour)}}
+	assert.Empty(t, update(now, n1, n2))
+	assert.False(t, taints.HasTaint(getNode("n1"), taints.MaintenanceScheduledTaint))
+
+	// Within the lead time the node is tainted and cordoned, but pods don't fit elsewhere yet.
+	now = now.Add(45 * time.Minute)
+	affected := update(now, n1, n2)
+	assert.Len(t, affected, 1)
+	assert.True(t, taints.HasTaint(getNode("n1"), taints.MaintenanceScheduledTaint))
+	assert.True(t, getNode("n1").Spec.Unschedulable)
+	assertNotDrained()
+
+	// Replacement capacity shows up, the node is drained once.
+	update(now, n1, n2, n3)
+	assertDrained("n1")
+	update(now, n1, n2, n3)
+	assertNotDrained()
+
+	// Maintenance is over, the taint is removed.
+	provider.events = nil
+	assert.Empty(t, update(now, n1, n2, n3))
+	assert.False(t, taints.HasTaint(getNode("n1"), taints.MaintenanceScheduledTaint))
+	assert.False(t, getNode("n1").Spec.Unschedulable)
+
+	// Without replacement capacity the node is drained right before maintenance starts.
+	provider.events = []cloudprovider.MaintenanceEvent{{ProviderID: n2.Spec.ProviderID, Type: cloudprovider.MaintenanceEventTermination, NotBefore: now.Add(10 * time.Minute)}}
+	update(now, n1, n2)
+	assertNotDrained()
+	update(now.Add(9*time.Minute+30*time.Second), n1, n2)
+	assertDrained("n2")
+}
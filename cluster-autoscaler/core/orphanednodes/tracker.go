@@ -0,0 +1,429 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphanednodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
+	acontext "k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/actuation"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/eligibility"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	// AdoptPolicy keeps orphaned nodes as unmanaged. They are annotated with
+	// the scale-down-disabled annotation, so they are never scaled down, and
+	// can still be used to schedule pods.
+	AdoptPolicy = "adopt"
+	// DrainPolicy taints and cordons orphaned nodes. Once a node has been
+	// orphaned for longer than the drain timeout, its pods are evicted. The
+	// node is kept cordoned, as its instance can't be deleted through the
+	// cloud provider without a node group, and has to be removed manually.
+	DrainPolicy = "drain"
+
+	// OrphanedSinceAnnotation is set on orphaned nodes to the time they were
+	// found orphaned, so that it survives restarts of the autoscaler.
+	OrphanedSinceAnnotation = "cluster-autoscaler.kubernetes.io/orphaned-since"
+	// AdoptedAnnotation marks orphaned nodes on which the scale-down-disabled
+	// annotation was set by the adopt policy, so that it is removed once the
+	// node belongs to a node group again.
+	AdoptedAnnotation = "cluster-autoscaler.kubernetes.io/adopted-orphan"
+	// NodeGroupAnnotation is set on nodes of autoscaled node groups to the id
+	// of the node group with DrainPolicy, so that nodes of node groups removed
+	// while the autoscaler wasn't running are drained too. With AdoptPolicy
+	// such nodes are left alone, as nodes without a node group are never
+	// scaled down anyway.
+	NodeGroupAnnotation = "cluster-autoscaler.kubernetes.io/node-group"
+)
+
+// Policies lists all supported orphaned nodes policies.
+var Policies = []string{AdoptPolicy, DrainPolicy}
+
+// Tracker detects orphaned nodes, i.e. nodes whose node group was removed from
+// node group discovery while the nodes still exist in the cluster, and handles
+// them according to the configured policy.
+type Tracker struct {
+	policy       string
+	gracePeriod  time.Duration
+	drainTimeout time.Duration
+	// nodeGroups maps node names to ids of node groups they were last seen in.
+	// Nodes not seen yet fall back to NodeGroupAnnotation.
+	nodeGroups map[string]string
+	// annotated maps node names to ids of node groups they were annotated with.
+	annotated map[string]string
+	// missingSince maps names of nodes without a node group which aren't
+	// orphaned yet to the time their node group went missing.
+	missingSince map[string]time.Time
+	// orphanedSince maps names of orphaned nodes to the time they were found orphaned.
+	orphanedSince map[string]time.Time
+	// orphanedFrom maps names of orphaned nodes to ids of node groups they were removed from.
+	orphanedFrom map[string]string
+	// lastOrphaned identifies the set of orphaned nodes reported in the last event.
+	lastOrphaned string
+
+	mutex sync.Mutex
+	// draining holds names of orphaned nodes which are currently being drained.
+	draining map[string]bool
+	// drained holds names of orphaned nodes which were drained.
+	drained map[string]bool
+	// drainNode is used to drain nodes, replaced in tests.
+	drainNode func(ctx *acontext.AutoscalingContext, nodeInfo *schedulerframework.NodeInfo) error
+}
+
+// NewTracker returns a new Tracker handling orphaned nodes with the given
+// policy. Nodes are orphaned once they haven't belonged to any node group for
+// gracePeriod, so that node groups missing from a single discovery aren't
+// treated as removed. With DrainPolicy, nodes orphaned for longer than
+// drainTimeout are drained.
+func NewTracker(policy string, gracePeriod, drainTimeout time.Duration) *Tracker {
+	return &Tracker{
+		policy:        policy,
+		gracePeriod:   gracePeriod,
+		drainTimeout:  drainTimeout,
+		nodeGroups:    make(map[string]string),
+		annotated:     make(map[string]string),
+		missingSince:  make(map[string]time.Time),
+		orphanedSince: make(map[string]time.Time),
+		orphanedFrom:  make(map[string]string),
+		draining:      make(map[string]bool),
+		drained:       make(map[string]bool),
+		drainNode:     drainNode,
+	}
+}
+
+// Update checks which of the nodes lost their node group since the last call
+// and applies the policy to them. Returns currently orphaned nodes.
+func (t *Tracker) Update(ctx *acontext.AutoscalingContext, nodes []*apiv1.Node, now time.Time) []*apiv1.Node {
+	var orphaned []*apiv1.Node
+	seen := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		seen[node.Name] = true
+		nodeGroup, err := ctx.CloudProvider.NodeGroupForNode(node)
+		if err != nil {
+			klog.Warningf("Failed to get node group for %s: %v", node.Name, err)
+			if _, found := t.orphanedSince[node.Name]; found {
+				orphaned = append(orphaned, node)
+			}
+			continue
+		}
+		if nodeGroup != nil && !reflect.ValueOf(nodeGroup).IsNil() {
+			delete(t.missingSince, node.Name)
+			t.recordNodeGroup(ctx, node, nodeGroup.Id())
+			t.adopt(ctx, node)
+			continue
+		}
+		nodeGroupId, known := t.nodeGroups[node.Name]
+		if !known {
+			// Node group may have been removed while the autoscaler wasn't running.
+			nodeGroupId, known = node.Annotations[NodeGroupAnnotation]
+		}
+		if !known && !isMarkedOrphaned(node) {
+			// Node never belonged to an autoscaled node group.
+			continue
+		}
+		if _, found := t.orphanedSince[node.Name]; !found {
+			if !isMarkedOrphaned(node) && !t.missingForGracePeriod(node.Name, now) {
+				continue
+			}
+			delete(t.missingSince, node.Name)
+			t.orphan(ctx, node, nodeGroupId, now)
+		}
+		orphaned = append(orphaned, node)
+	}
+	for name := range t.nodeGroups {
+		if !seen[name] {
+			delete(t.nodeGroups, name)
+			delete(t.annotated, name)
+		}
+	}
+	for name := range t.missingSince {
+		if !seen[name] {
+			delete(t.missingSince, name)
+		}
+	}
+	for name := range t.orphanedSince {
+		if !seen[name] {
+			delete(t.orphanedSince, name)
+			delete(t.orphanedFrom, name)
+		}
+	}
+	t.mutex.Lock()
+	for name := range t.drained {
+		if _, found := t.orphanedSince[name]; !found {
+			delete(t.drained, name)
+		}
+	}
+	t.mutex.Unlock()
+
+	if t.policy == DrainPolicy {
+		for _, node := range orphaned {
+			if now.Sub(t.orphanedSince[node.Name]) >= t.drainTimeout && !t.isDrainingOrDrained(node.Name) {
+				t.startDrain(ctx, node)
+			}
+		}
+	}
+
+	metrics.UpdateOrphanedNodesCount(len(orphaned))
+	if current := strings.Join(nodeNames(orphaned), ","); current != t.lastOrphaned {
+		t.lastOrphaned = current
+		if len(orphaned) > 0 {
+			ctx.LogRecorder.Eventf(apiv1.EventTypeWarning, "OrphanedNodes",
+				"%d nodes don't belong to any node group anymore, orphaned nodes policy: %s", len(orphaned), t.policy)
+		}
+	}
+	return orphaned
+}
+
+// missingForGracePeriod returns whether the node has been missing from all
+// node groups for at least the grace period.
+func (t *Tracker) missingForGracePeriod(nodeName string, now time.Time) bool {
+	since, found := t.missingSince[nodeName]
+	if !found {
+		since = now
+		t.missingSince[nodeName] = since
+	}
+	if now.Sub(since) < t.gracePeriod {
+		klog.V(4).Infof("Node %s doesn't belong to any node group since %v, waiting for %v before orphaning it", nodeName, since, t.gracePeriod)
+		return false
+	}
+	return true
+}
+
+// OrphanedSince returns the time the node was found orphaned.
+func (t *Tracker) OrphanedSince(nodeName string) (time.Time, bool) {
+	since, found := t.orphanedSince[nodeName]
+	return since, found
+}
+
+// Status returns the status of currently orphaned nodes, sorted by node name,
+// to be reported in the status ConfigMap.
+func (t *Tracker) Status() []api.OrphanedNodeStatus {
+	var result []api.OrphanedNodeStatus
+	for name, since := range t.orphanedSince {
+		status := api.OrphanedNodeStatus{
+			Name:          name,
+			NodeGroup:     t.orphanedFrom[name],
+			Policy:        t.policy,
+			OrphanedSince: metav1.NewTime(since),
+		}
+		if t.policy == DrainPolicy {
+			status.DrainAfter = metav1.NewTime(since.Add(t.drainTimeout))
+		}
+		result = append(result, status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (t *Tracker) orphan(ctx *acontext.AutoscalingContext, node *apiv1.Node, nodeGroupId string, now time.Time) {
+	since := now
+	if value, found := node.Annotations[OrphanedSinceAnnotation]; found {
+		// Orphaned by a previous run of the autoscaler.
+		if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+			since = parsed
+		}
+	}
+	t.orphanedSince[node.Name] = since
+	t.orphanedFrom[node.Name] = nodeGroupId
+	if isMarkedOrphaned(node) {
+		return
+	}
+
+	nodeGroupDesc := "its node group"
+	if nodeGroupId != "" {
+		nodeGroupDesc = fmt.Sprintf("node group %s", nodeGroupId)
+	}
+	switch t.policy {
+	case DrainPolicy:
+		klog.Warningf("Node %s is orphaned: %s was removed from discovery, draining the node", node.Name, nodeGroupDesc)
+		ctx.Recorder.Eventf(node, apiv1.EventTypeWarning, "NodeGroupRemoved",
+			"%s was removed from discovery, node is cordoned and will be drained after %v", nodeGroupDesc, t.drainTimeout)
+		taint := apiv1.Taint{
+			Key:    taints.OrphanedNodeTaint,
+			Value:  fmt.Sprint(now.Unix()),
+			Effect: apiv1.TaintEffectNoSchedule,
+		}
+		if err := taints.AddTaints(node, ctx.ClientSet, []apiv1.Taint{taint}, true); err != nil {
+			klog.Errorf("Failed to taint orphaned node %s: %v", node.Name, err)
+		}
+		if err := patchAnnotations(ctx, node.Name, map[string]*string{
+			OrphanedSinceAnnotation: ptr.To(now.Format(time.RFC3339)),
+		}); err != nil {
+			klog.Errorf("Failed to annotate orphaned node %s: %v", node.Name, err)
+		}
+	default:
+		klog.Warningf("Node %s is orphaned: %s was removed from discovery, keeping the node as unmanaged", node.Name, nodeGroupDesc)
+		ctx.Recorder.Eventf(node, apiv1.EventTypeWarning, "NodeGroupRemoved",
+			"%s was removed from discovery, node is kept as unmanaged and won't be scaled down", nodeGroupDesc)
+		annotations := map[string]*string{OrphanedSinceAnnotation: ptr.To(now.Format(time.RFC3339))}
+		if node.Annotations[eligibility.ScaleDownDisabledKey] != "true" {
+			annotations[eligibility.ScaleDownDisabledKey] = ptr.To("true")
+			annotations[AdoptedAnnotation] = ptr.To("true")
+		}
+		if err := patchAnnotations(ctx, node.Name, annotations); err != nil {
+			klog.Errorf("Failed to annotate orphaned node %s: %v", node.Name, err)
+		}
+	}
+}
+
+// adopt reverts orphaning of a node which is back in a node group.
+func (t *Tracker) adopt(ctx *acontext.AutoscalingContext, node *apiv1.Node) {
+	_, wasOrphaned := t.orphanedSince[node.Name]
+	delete(t.orphanedSince, node.Name)
+	delete(t.orphanedFrom, node.Name)
+	t.mutex.Lock()
+	draining := t.draining[node.Name]
+	delete(t.drained, node.Name)
+	t.mutex.Unlock()
+	if draining {
+		return
+	}
+	if taints.HasTaint(node, taints.OrphanedNodeTaint) {
+		if _, err := taints.CleanTaints(node, ctx.ClientSet, []string{taints.OrphanedNodeTaint}, true); err != nil {
+			klog.Errorf("Failed to remove %s taint from node %s: %v", taints.OrphanedNodeTaint, node.Name, err)
+		}
+		wasOrphaned = true
+	}
+	if _, found := node.Annotations[OrphanedSinceAnnotation]; found {
+		annotations := map[string]*string{OrphanedSinceAnnotation: nil}
+		if node.Annotations[AdoptedAnnotation] == "true" {
+			annotations[AdoptedAnnotation] = nil
+			annotations[eligibility.ScaleDownDisabledKey] = nil
+		}
+		if err := patchAnnotations(ctx, node.Name, annotations); err != nil {
+			klog.Errorf("Failed to remove orphaned node annotations from node %s: %v", node.Name, err)
+		}
+		wasOrphaned = true
+	}
+	if wasOrphaned {
+		klog.V(1).Infof("Node %s belongs to node group %s again", node.Name, t.nodeGroups[node.Name])
+		ctx.Recorder.Eventf(node, apiv1.EventTypeNormal, "NodeGroupRediscovered",
+			"node belongs to node group %s again", t.nodeGroups[node.Name])
+	}
+}
+
+// recordNodeGroup remembers the node group of the node. With DrainPolicy, the
+// node is annotated with it unless it's already annotated.
+func (t *Tracker) recordNodeGroup(ctx *acontext.AutoscalingContext, node *apiv1.Node, nodeGroupId string) {
+	t.nodeGroups[node.Name] = nodeGroupId
+	if t.policy != DrainPolicy || node.Annotations[NodeGroupAnnotation] == nodeGroupId || t.annotated[node.Name] == nodeGroupId {
+		return
+	}
+	if err := patchAnnotations(ctx, node.Name, map[string]*string{NodeGroupAnnotation: &nodeGroupId}); err != nil {
+		klog.Warningf("Failed to annotate node %s with its node group %s: %v", node.Name, nodeGroupId, err)
+		return
+	}
+	t.annotated[node.Name] = nodeGroupId
+}
+
+func (t *Tracker) isDrainingOrDrained(nodeName string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.draining[nodeName] || t.drained[nodeName]
+}
+
+// startDrain evicts pods of an orphaned node in the background. The node is
+// kept cordoned afterwards: its instance can't be deleted through the cloud
+// provider as it doesn't belong to any node group anymore, and deleting only
+// the node object would let its kubelet register it again as a clean node.
+func (t *Tracker) startDrain(ctx *acontext.AutoscalingContext, node *apiv1.Node) {
+	nodeInfo, err := ctx.ClusterSnapshot.NodeInfos().Get(node.Name)
+	if err != nil {
+		klog.Warningf("Couldn't get node %s info, not draining it: %v", node.Name, err)
+		return
+	}
+	nodeInfo = nodeInfo.Snapshot()
+
+	t.mutex.Lock()
+	t.draining[node.Name] = true
+	t.mutex.Unlock()
+	klog.V(1).Infof("Draining orphaned node %s", node.Name)
+	ctx.Recorder.Eventf(node, apiv1.EventTypeNormal, "OrphanedNodeDrain", "draining orphaned node")
+
+	go func() {
+		defer func() {
+			t.mutex.Lock()
+			delete(t.draining, node.Name)
+			t.mutex.Unlock()
+		}()
+		if err := t.drainNode(ctx, nodeInfo); err != nil {
+			klog.Errorf("Failed to drain orphaned node %s: %v", node.Name, err)
+			ctx.Recorder.Eventf(node, apiv1.EventTypeWarning, "OrphanedNodeDrainFailed", "failed to drain orphaned node: %v", err)
+			return
+		}
+		t.mutex.Lock()
+		t.drained[node.Name] = true
+		t.mutex.Unlock()
+		klog.V(1).Infof("Orphaned node %s drained, its instance has to be deleted manually", node.Name)
+		ctx.Recorder.Eventf(node, apiv1.EventTypeNormal, "OrphanedNodeDrained",
+			"orphaned node drained, its instance doesn't belong to any node group and has to be deleted manually")
+	}()
+}
+
+func drainNode(ctx *acontext.AutoscalingContext, nodeInfo *schedulerframework.NodeInfo) error {
+	var evictor actuation.Evictor
+	if len(ctx.DrainPriorityConfig) > 0 {
+		evictor = actuation.NewEvictor(nil, ctx.DrainPriorityConfig, true)
+	} else {
+		evictor = actuation.NewEvictor(nil, actuation.SingleRuleDrainConfig(ctx.MaxGracefulTerminationSec), false)
+	}
+	_, err := evictor.DrainNode(ctx, nodeInfo)
+	return err
+}
+
+func isMarkedOrphaned(node *apiv1.Node) bool {
+	_, annotated := node.Annotations[OrphanedSinceAnnotation]
+	return annotated || taints.HasTaint(node, taints.OrphanedNodeTaint)
+}
+
+// patchAnnotations sets the annotations of the node with a merge patch,
+// removing the ones with nil values.
+func patchAnnotations(ctx *acontext.AutoscalingContext, nodeName string, annotations map[string]*string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = ctx.ClientSet.CoreV1().Nodes().Patch(context.TODO(), nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func nodeNames(nodes []*apiv1.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+	sort.Strings(names)
+	return names
+}
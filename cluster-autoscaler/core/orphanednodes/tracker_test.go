@@ -0,0 +1,341 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package orphanednodes
+
+import (
+	ctx "context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	acontext "k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/eligibility"
+	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestTrackerUpdate(t *testing.T) {
+	now := time.Now()
+	for _, tc := range []struct {
+		name                  string
+		policy                string
+		wantTainted           bool
+		wantUnscheduled       bool
+		wantScaleDownDisabled bool
+	}{
+		{
+			name:                  "adopt",
+			policy:                AdoptPolicy,
+			wantScaleDownDisabled: true,
+		},
+		{
+			name:            "drain",
+			policy:          DrainPolicy,
+			wantTainted:     true,
+			wantUnscheduled: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n1 := BuildTestNode("n1", 1000, 1000)
+			n2 := BuildTestNode("n2", 1000, 1000)
+			unmanaged := BuildTestNode("unmanaged", 1000, 1000)
+			client := fake.NewSimpleClientset(n1, n2, unmanaged)
+
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			provider.AddNodeGroup("ng1", 1, 10, 1)
+			provider.AddNodeGroup("ng2", 1, 10, 1)
+			provider.AddNode("ng1", n1)
+			provider.AddNode("ng2", n2)
+
+			context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+			assert.NoError(t, err)
+
+			getNode := func(name string) *apiv1.Node {
+				node, err := client.CoreV1().Nodes().Get(ctx.TODO(), name, metav1.GetOptions{})
+				assert.NoError(t, err)
+				return node
+			}
+
+			tracker := NewTracker(tc.policy, 0, time.Hour)
+			orphaned := tracker.Update(&context, []*apiv1.Node{n1, n2, unmanaged}, now)
+			assert.Empty(t, orphaned)
+
+			// ng2 disappears from discovery, n2 becomes orphaned. Unmanaged node is never considered orphaned.
+			provider.DeleteNodeGroup("ng2")
+			orphaned = tracker.Update(&context, []*apiv1.Node{n1, getNode("n2"), unmanaged}, now.Add(time.Minute))
+			assert.Equal(t, []string{"n2"}, nodeNames(orphaned))
+			since, found := tracker.OrphanedSince("n2")
+			assert.True(t, found)
+			assert.Equal(t, now.Add(time.Minute), since)
+			assert.Equal(t, tc.wantTainted, taints.HasTaint(getNode("n2"), taints.OrphanedNodeTaint))
+			assert.Equal(t, tc.wantUnscheduled, getNode("n2").Spec.Unschedulable)
+			assert.Equal(t, tc.wantScaleDownDisabled, eligibility.HasNoScaleDownAnnotation(getNode("n2")))
+			assert.Contains(t, getNode("n2").Annotations, OrphanedSinceAnnotation)
+			assert.Equal(t, []api.OrphanedNodeStatus{{
+				Name:          "n2",
+				NodeGroup:     "ng2",
+				Policy:        tc.policy,
+				OrphanedSince: metav1.NewTime(now.Add(time.Minute)),
+			}}, withoutDrainAfter(tracker.Status()))
+
+			// Orphaned node keeps its orphaned since time.
+			orphaned = tracker.Update(&context, []*apiv1.Node{n1, getNode("n2"), unmanaged}, now.Add(2*time.Minute))
+			assert.Equal(t, []string{"n2"}, nodeNames(orphaned))
+			since, _ = tracker.OrphanedSince("n2")
+			assert.Equal(t, now.Add(time.Minute), since)
+
+			// ng2 is rediscovered, n2 is adopted back.
+			provider.AddNodeGroup("ng2", 1, 10, 1)
+			orphaned = tracker.Update(&context, []*apiv1.Node{n1, getNode("n2"), unmanaged}, now.Add(3*time.Minute))
+			assert.Empty(t, orphaned)
+			_, found = tracker.OrphanedSince("n2")
+			assert.False(t, found)
+			assert.False(t, taints.HasTaint(getNode("n2"), taints.OrphanedNodeTaint))
+			assert.False(t, getNode("n2").Spec.Unschedulable)
+			assert.False(t, eligibility.HasNoScaleDownAnnotation(getNode("n2")))
+			assert.NotContains(t, getNode("n2").Annotations, OrphanedSinceAnnotation)
+			assert.Empty(t, tracker.Status())
+		})
+	}
+}
+
+func TestTrackerUpdateAfterRestart(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n1.Spec.Taints = []apiv1.Taint{{Key: taints.OrphanedNodeTaint, Value: "1", Effect: apiv1.TaintEffectNoSchedule}}
+	n1.Spec.Unschedulable = true
+	client := fake.NewSimpleClientset(n1)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+
+	// Nodes tainted by a previous run are recognized as orphaned.
+	tracker := NewTracker(DrainPolicy, 0, time.Hour)
+	orphaned := tracker.Update(&context, []*apiv1.Node{n1}, time.Now())
+	assert.Equal(t, []string{"n1"}, nodeNames(orphaned))
+
+	// Deleted nodes are forgotten.
+	orphaned = tracker.Update(&context, nil, time.Now())
+	assert.Empty(t, orphaned)
+	_, found := tracker.OrphanedSince("n1")
+	assert.False(t, found)
+}
+
+func TestTrackerUpdateNodeGroupRemovedWhileNotRunning(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	client := fake.NewSimpleClientset(n1, n2)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", n1)
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+	getNode := func(name string) *apiv1.Node {
+		node, err := client.CoreV1().Nodes().Get(ctx.TODO(), name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		return node
+	}
+
+	// Node group annotations are only needed to drain nodes.
+	NewTracker(AdoptPolicy, 0, time.Hour).Update(&context, []*apiv1.Node{n1, n2}, time.Now())
+	assert.NotContains(t, getNode("n1").Annotations, NodeGroupAnnotation)
+
+	// Nodes of node groups are annotated with their node group.
+	NewTracker(DrainPolicy, 0, time.Hour).Update(&context, []*apiv1.Node{n1, n2}, time.Now())
+	assert.Equal(t, "ng1", getNode("n1").Annotations[NodeGroupAnnotation])
+	assert.NotContains(t, getNode("n2").Annotations, NodeGroupAnnotation)
+
+	// ng1 is removed while the autoscaler isn't running.
+	provider.DeleteNodeGroup("ng1")
+	tracker := NewTracker(DrainPolicy, 0, time.Hour)
+	orphaned := tracker.Update(&context, []*apiv1.Node{getNode("n1"), getNode("n2")}, time.Now())
+	assert.Equal(t, []string{"n1"}, nodeNames(orphaned))
+	statuses := tracker.Status()
+	if assert.Len(t, statuses, 1) {
+		assert.Equal(t, "ng1", statuses[0].NodeGroup)
+	}
+}
+
+func TestTrackerWaitsForGracePeriod(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	client := fake.NewSimpleClientset(n1)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", n1)
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+
+	tracker := NewTracker(AdoptPolicy, 10*time.Minute, time.Hour)
+	tracker.Update(&context, []*apiv1.Node{n1}, now)
+
+	// ng1 is missing from discovery for less than the grace period.
+	provider.DeleteNodeGroup("ng1")
+	assert.Empty(t, tracker.Update(&context, []*apiv1.Node{n1}, now.Add(time.Minute)))
+	assert.Empty(t, tracker.Update(&context, []*apiv1.Node{n1}, now.Add(5*time.Minute)))
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	assert.Empty(t, tracker.Update(&context, []*apiv1.Node{n1}, now.Add(6*time.Minute)))
+
+	// Grace period starts over once ng1 is missing again.
+	provider.DeleteNodeGroup("ng1")
+	assert.Empty(t, tracker.Update(&context, []*apiv1.Node{n1}, now.Add(7*time.Minute)))
+	assert.Empty(t, tracker.Update(&context, []*apiv1.Node{n1}, now.Add(16*time.Minute)))
+	orphaned := tracker.Update(&context, []*apiv1.Node{n1}, now.Add(17*time.Minute))
+	assert.Equal(t, []string{"n1"}, nodeNames(orphaned))
+	since, _ := tracker.OrphanedSince("n1")
+	assert.Equal(t, now.Add(17*time.Minute), since)
+}
+
+func TestTrackerDoesNotWriteUnchangedNodes(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	client := fake.NewSimpleClientset(n1)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", n1)
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+
+	for _, policy := range Policies {
+		tracker := NewTracker(policy, 0, time.Hour)
+		for i := 0; i < 3; i++ {
+			tracker.Update(&context, []*apiv1.Node{n1}, time.Now())
+		}
+	}
+	var writes []string
+	for _, action := range client.Actions() {
+		if action.GetVerb() != "get" && action.GetVerb() != "list" {
+			writes = append(writes, action.GetVerb())
+		}
+	}
+	// Only the drain policy annotates the node with its node group, once.
+	assert.Equal(t, []string{"patch"}, writes)
+}
+
+func TestTrackerKeepsExistingScaleDownDisabledAnnotation(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n1.Annotations = map[string]string{eligibility.ScaleDownDisabledKey: "true"}
+	client := fake.NewSimpleClientset(n1)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", n1)
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+	getNode := func() *apiv1.Node {
+		node, err := client.CoreV1().Nodes().Get(ctx.TODO(), "n1", metav1.GetOptions{})
+		assert.NoError(t, err)
+		return node
+	}
+
+	tracker := NewTracker(AdoptPolicy, 0, time.Hour)
+	tracker.Update(&context, []*apiv1.Node{n1}, time.Now())
+	provider.DeleteNodeGroup("ng1")
+	tracker.Update(&context, []*apiv1.Node{getNode()}, time.Now())
+	assert.NotContains(t, getNode().Annotations, AdoptedAnnotation)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	tracker.Update(&context, []*apiv1.Node{getNode()}, time.Now())
+
+	// Annotation set by the user is left in place when the node is rediscovered.
+	assert.True(t, eligibility.HasNoScaleDownAnnotation(getNode()))
+}
+
+func TestTrackerDrainsAfterTimeout(t *testing.T) {
+	now := time.Now()
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n1.Annotations = map[string]string{OrphanedSinceAnnotation: now.Format(time.RFC3339)}
+	n1.Spec.Taints = []apiv1.Taint{{Key: taints.OrphanedNodeTaint, Value: "1", Effect: apiv1.TaintEffectNoSchedule}}
+	client := fake.NewSimpleClientset(n1)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, context.ClusterSnapshot.AddNode(n1))
+
+	drained := make(chan string, 1)
+	tracker := NewTracker(DrainPolicy, 0, time.Hour)
+	tracker.drainNode = func(_ *acontext.AutoscalingContext, nodeInfo *schedulerframework.NodeInfo) error {
+		drained <- nodeInfo.Node().Name
+		return nil
+	}
+
+	// Orphaned since time is restored from the annotation, the node isn't drained before the timeout.
+	tracker.Update(&context, []*apiv1.Node{n1}, now.Add(30*time.Minute))
+	since, _ := tracker.OrphanedSince("n1")
+	assert.Equal(t, now.Unix(), since.Unix())
+	assert.Empty(t, drained)
+
+	tracker.Update(&context, []*apiv1.Node{n1}, now.Add(time.Hour))
+	select {
+	case name := <-drained:
+		assert.Equal(t, "n1", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("orphaned node wasn't drained")
+	}
+	assert.Eventually(t, func() bool {
+		tracker.mutex.Lock()
+		defer tracker.mutex.Unlock()
+		return tracker.drained["n1"]
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Drained node is kept cordoned and isn't drained again.
+	_, err = client.CoreV1().Nodes().Get(ctx.TODO(), "n1", metav1.GetOptions{})
+	assert.NoError(t, err)
+	tracker.Update(&context, []*apiv1.Node{n1}, now.Add(2*time.Hour))
+	assert.Empty(t, drained)
+}
+
+func TestTrackerEmitsEventWhenOrphanedNodesChange(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	client := fake.NewSimpleClientset(n1, n2)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng1", n2)
+	context, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{}, client, nil, provider, nil, nil)
+	assert.NoError(t, err)
+	recorder := kube_record.NewFakeRecorder(10)
+	context.LogRecorder, err = utils.NewStatusMapRecorder(client, "kube-system", recorder, true, "status")
+	assert.NoError(t, err)
+
+	tracker := NewTracker(AdoptPolicy, 0, time.Hour)
+	tracker.Update(&context, []*apiv1.Node{n1, n2}, time.Now())
+	provider.DeleteNodeGroup("ng1")
+	tracker.Update(&context, []*apiv1.Node{n1, n2}, time.Now())
+	tracker.Update(&context, []*apiv1.Node{n1, n2}, time.Now())
+	assert.Len(t, recorder.Events, 1)
+	<-recorder.Events
+
+	// n2 is gone, the orphaned nodes set changed.
+	tracker.Update(&context, []*apiv1.Node{n1}, time.Now())
+	tracker.Update(&context, []*apiv1.Node{n1}, time.Now())
+	assert.Len(t, recorder.Events, 1)
+}
+
+func withoutDrainAfter(statuses []api.OrphanedNodeStatus) []api.OrphanedNodeStatus {
+	for i := range statuses {
+		statuses[i].DrainAfter = metav1.Time{}
+	}
+	return statuses
+}
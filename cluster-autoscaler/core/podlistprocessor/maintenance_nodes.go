@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podlistprocessor
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	"k8s.io/klog/v2"
+)
+
+type maintenanceNodesPodListProcessor struct {
+}
+
+// NewMaintenanceNodesPodListProcessor returns a new processor adding pods
+// from nodes affected by upcoming maintenance to the unschedulable pods, so
+// that replacement capacity is provisioned before the nodes are drained.
+func NewMaintenanceNodesPodListProcessor() *maintenanceNodesPodListProcessor {
+	return &maintenanceNodesPodListProcessor{}
+}
+
+// Process adds recreatable pods from nodes affected by upcoming maintenance
+func (p *maintenanceNodesPodListProcessor) Process(context *context.AutoscalingContext, unschedulablePods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	nodeInfos, err := context.ClusterSnapshot.NodeInfos().List()
+	if err != nil {
+		klog.Errorf("Failed to list node infos: %v", err)
+		return unschedulablePods, nil
+	}
+	var pods []*apiv1.Pod
+	for _, nodeInfo := range nodeInfos {
+		if !taints.HasTaint(nodeInfo.Node(), taints.MaintenanceScheduledTaint) {
+			continue
+		}
+		for _, podInfo := range nodeInfo.Pods {
+			if podInfo.Pod.DeletionTimestamp != nil {
+				continue
+			}
+			pods = append(pods, podInfo.Pod)
+		}
+	}
+	recreatablePods := pod_util.FilterRecreatablePods(pods)
+	return append(unschedulablePods, pod_util.ClearPodNodeNames(recreatablePods)...), nil
+}
+
+func (p *maintenanceNodesPodListProcessor) CleanUp() {
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podlistprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestMaintenanceNodesPodListProcessor(t *testing.T) {
+	maintenanceNode := BuildTestNode("m", 1000, 10)
+	maintenanceNode.Spec.Taints = []apiv1.Taint{{Key: taints.MaintenanceScheduledTaint, Effect: apiv1.TaintEffectNoSchedule}}
+
+	testCases := []struct {
+		name              string
+		nodes             []*apiv1.Node
+		pods              []*apiv1.Pod
+		unschedulablePods []*apiv1.Pod
+		wantPods          []*apiv1.Pod
+	}{
+		{
+			name:  "no maintenance nodes",
+			nodes: []*apiv1.Node{BuildTestNode("n", 1000, 10)},
+			pods: []*apiv1.Pod{
+				BuildScheduledTestPod("p1", 100, 1, "n"),
+			},
+			unschedulablePods: []*apiv1.Pod{BuildTestPod("p2", 200, 1)},
+			wantPods:          []*apiv1.Pod{BuildTestPod("p2", 200, 1)},
+		},
+		{
+			name:  "pods from maintenance node are added",
+			nodes: []*apiv1.Node{BuildTestNode("n", 1000, 10), maintenanceNode},
+			pods: []*apiv1.Pod{
+				BuildScheduledTestPod("p1", 100, 1, "n"),
+				BuildScheduledTestPod("p2", 200, 1, "m"),
+				SetRSPodSpec(BuildScheduledTestPod("p3", 300, 1, "m"), "rs"),
+			},
+			unschedulablePods: []*apiv1.Pod{BuildTestPod("p4", 400, 1)},
+			wantPods: []*apiv1.Pod{
+				BuildTestPod("p2", 200, 1),
+				SetRSPodSpec(BuildTestPod("p3", 300, 1), "rs"),
+				BuildTestPod("p4", 400, 1),
+			},
+		},
+		{
+			name:  "non-recreatable and terminating pods are skipped",
+			nodes: []*apiv1.Node{maintenanceNode},
+			pods: []*apiv1.Pod{
+				BuildScheduledTestPod("p1", 100, 1, "m"),
+				BuildTestPod("p2", 200, 1, WithNodeName("m"), WithDeletionTimestamp(time.Now())),
+				SetDSPodSpec(BuildScheduledTestPod("p3", 300, 1, "m")),
+				SetMirrorPodSpec(BuildScheduledTestPod("p4", 400, 1, "m")),
+			},
+			wantPods: []*apiv1.Pod{
+				BuildTestPod("p1", 100, 1),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.AutoscalingContext{
+				ClusterSnapshot: clustersnapshot.NewBasicClusterSnapshot(),
+			}
+			clustersnapshot.InitializeClusterSnapshotOrDie(t, ctx.ClusterSnapshot, tc.nodes, tc.pods)
+
+			processor := NewMaintenanceNodesPodListProcessor()
+			pods, err := processor.Process(&ctx, tc.unschedulablePods)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantPods, pods)
+		})
+	}
+}
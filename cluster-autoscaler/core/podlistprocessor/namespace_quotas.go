@@ -0,0 +1,249 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podlistprocessor
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+const (
+	// NamespaceQuotasConfigMapName is the name of the ConfigMap configuring
+	// per-namespace scale-up quotas.
+	NamespaceQuotasConfigMapName = "cluster-autoscaler-namespace-quotas"
+	// NamespaceQuotasConfigMapKey is the key of the ConfigMap holding a YAML map
+	// from namespaces to the maximum number of nodes their pods may trigger.
+	NamespaceQuotasConfigMapKey = "quotas"
+	// NamespacePendingNodesConfigMapKey is the key of the ConfigMap holding a
+	// YAML map from namespaces to the maximum number of upcoming nodes their
+	// pods may have at once.
+	NamespacePendingNodesConfigMapKey = "pendingNodes"
+	// DefaultNamespaceQuotaKey is the namespace of the quota applying to all
+	// namespaces without their own quota.
+	DefaultNamespaceQuotaKey = "*"
+
+	// upcomingNodeAnnotation marks upcoming nodes in the cluster snapshot. It
+	// mirrors core.NodeUpcomingAnnotation, which can't be imported from here.
+	upcomingNodeAnnotation = "cluster-autoscaler.k8s.io/upcoming-node"
+)
+
+// namespaceQuotas holds the limits read from the NamespaceQuotasConfigMapName
+// ConfigMap. Either map may be empty.
+type namespaceQuotas struct {
+	nodes        map[string]int
+	pendingNodes map[string]int
+}
+
+type namespaceQuotasPodListProcessor struct {
+	configMapLister v1lister.ConfigMapNamespaceLister
+	stopChannel     chan struct{}
+	quotas          namespaceQuotas
+}
+
+// NewNamespaceQuotasPodListProcessor returns a new processor limiting the
+// unschedulable pods of each namespace by the scale-up quota it has left, so
+// that a single namespace can't consume the whole cluster. Quotas are read
+// from the NamespaceQuotasConfigMapName ConfigMap. The stop channel of the
+// lister is closed on CleanUp.
+//
+// Besides the total number of nodes, the ConfigMap can limit the number of
+// upcoming nodes of each namespace, bounding how much of the pending scale-up
+// capacity a single tenant can hold at once.
+func NewNamespaceQuotasPodListProcessor(configMapLister v1lister.ConfigMapNamespaceLister, stopChannel chan struct{}) *namespaceQuotasPodListProcessor {
+	return &namespaceQuotasPodListProcessor{configMapLister: configMapLister, stopChannel: stopChannel}
+}
+
+// Process keeps at most as many unschedulable pods of each namespace as it has
+// nodes left in its quota and in its upcoming nodes limit. As every pod needs at most one new node, the pods
+// kept can't trigger more nodes than the quota allows, even in a single loop.
+// Pods of a namespace which would fit together on fewer nodes are added over
+// the following loops, once the upcoming nodes are in the cluster snapshot.
+//
+// Nodes are attributed to namespaces by countNodesByNamespace. Since this runs
+// after pods fitting on existing and upcoming nodes are added to the cluster
+// snapshot, upcoming nodes are attributed the same way, i.e. to the namespaces
+// whose pending pods will be scheduled on them.
+func (p *namespaceQuotasPodListProcessor) Process(context *context.AutoscalingContext, unschedulablePods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	p.reloadQuotas(context)
+	if (len(p.quotas.nodes) == 0 && len(p.quotas.pendingNodes) == 0) || len(unschedulablePods) == 0 {
+		return unschedulablePods, nil
+	}
+	nodeInfos, err := context.ClusterSnapshot.NodeInfos().List()
+	if err != nil {
+		klog.Errorf("Failed to list node infos: %v", err)
+		return unschedulablePods, nil
+	}
+	nodesByNamespace := countNodesByNamespace(nodeInfos)
+	var upcomingNodeInfos []*schedulerframework.NodeInfo
+	for _, nodeInfo := range nodeInfos {
+		if nodeInfo.Node() != nil && nodeInfo.Node().Annotations[upcomingNodeAnnotation] == "true" {
+			upcomingNodeInfos = append(upcomingNodeInfos, nodeInfo)
+		}
+	}
+	pendingNodesByNamespace := countNodesByNamespace(upcomingNodeInfos)
+
+	var result []*apiv1.Pod
+	kept := make(map[string]int)
+	filteredOut := make(map[string]int)
+	for _, pod := range unschedulablePods {
+		left, reason, limited := p.nodesLeft(pod.Namespace, nodesByNamespace, pendingNodesByNamespace)
+		if !limited {
+			result = append(result, pod)
+			continue
+		}
+		if kept[pod.Namespace] < left {
+			kept[pod.Namespace]++
+			result = append(result, pod)
+			continue
+		}
+		filteredOut[pod.Namespace]++
+		if !pod_util.IsVirtualPod(pod) {
+			context.Recorder.Eventf(pod, apiv1.EventTypeNormal, "NotTriggerScaleUp", "pod didn't trigger scale-up: %s", reason)
+		}
+	}
+	for namespace, count := range filteredOut {
+		klog.V(2).Infof("Filtered out %d pods of namespace %s, which has %d nodes, %d of them upcoming, and %d more pods triggering scale-up within its limits",
+			count, namespace, nodesByNamespace[namespace], pendingNodesByNamespace[namespace], kept[namespace])
+	}
+	return result, nil
+}
+
+func (p *namespaceQuotasPodListProcessor) CleanUp() {
+	if p.stopChannel != nil {
+		close(p.stopChannel)
+		p.stopChannel = nil
+	}
+}
+
+// nodesLeft returns how many more nodes pods of the namespace may trigger
+// under the stricter of its limits, along with the reason reported once none
+// are left. It returns false if the namespace isn't limited at all.
+func (p *namespaceQuotasPodListProcessor) nodesLeft(namespace string, nodesByNamespace, pendingNodesByNamespace map[string]int) (int, string, bool) {
+	left, reason, limited := 0, "", false
+	if quota, found := limitOf(p.quotas.nodes, namespace); found {
+		left, limited = quota-nodesByNamespace[namespace], true
+		reason = fmt.Sprintf("namespace %s has %d nodes and reached its quota of %d nodes", namespace, nodesByNamespace[namespace], quota)
+	}
+	if maxPending, found := limitOf(p.quotas.pendingNodes, namespace); found {
+		if pendingLeft := maxPending - pendingNodesByNamespace[namespace]; !limited || pendingLeft < left {
+			left, limited = pendingLeft, true
+			reason = fmt.Sprintf("namespace %s has %d upcoming nodes and reached its limit of %d upcoming nodes", namespace, pendingNodesByNamespace[namespace], maxPending)
+		}
+	}
+	return left, reason, limited
+}
+
+func limitOf(limits map[string]int, namespace string) (int, bool) {
+	if limit, found := limits[namespace]; found {
+		return limit, true
+	}
+	limit, found := limits[DefaultNamespaceQuotaKey]
+	return limit, found
+}
+
+// reloadQuotas updates the quotas from the ConfigMap. Invalid configurations
+// are reported and the previous quotas are kept.
+func (p *namespaceQuotasPodListProcessor) reloadQuotas(context *context.AutoscalingContext) {
+	cm, err := p.configMapLister.Get(NamespaceQuotasConfigMapName)
+	if kube_errors.IsNotFound(err) {
+		p.quotas = namespaceQuotas{}
+		return
+	}
+	if err != nil {
+		klog.Errorf("Failed to get namespace quotas config map %s: %v", NamespaceQuotasConfigMapName, err)
+		return
+	}
+	quotas, err := parseNamespaceQuotas(cm)
+	if err != nil {
+		msg := fmt.Sprintf("Wrong configuration of namespace quotas: %v. Ignoring update.", err)
+		context.Recorder.Event(cm, apiv1.EventTypeWarning, "NamespaceQuotasConfigMapInvalid", msg)
+		klog.Warning(msg)
+		return
+	}
+	p.quotas = quotas
+}
+
+func parseNamespaceQuotas(cm *apiv1.ConfigMap) (namespaceQuotas, error) {
+	_, hasQuotas := cm.Data[NamespaceQuotasConfigMapKey]
+	_, hasPendingNodes := cm.Data[NamespacePendingNodesConfigMapKey]
+	if !hasQuotas && !hasPendingNodes {
+		return namespaceQuotas{}, fmt.Errorf("config map %s contains neither %s nor %s key", cm.Name, NamespaceQuotasConfigMapKey, NamespacePendingNodesConfigMapKey)
+	}
+	nodes, err := parseNamespaceLimits(cm, NamespaceQuotasConfigMapKey)
+	if err != nil {
+		return namespaceQuotas{}, err
+	}
+	pendingNodes, err := parseNamespaceLimits(cm, NamespacePendingNodesConfigMapKey)
+	if err != nil {
+		return namespaceQuotas{}, err
+	}
+	return namespaceQuotas{nodes: nodes, pendingNodes: pendingNodes}, nil
+}
+
+func parseNamespaceLimits(cm *apiv1.ConfigMap, key string) (map[string]int, error) {
+	limitsYAML, found := cm.Data[key]
+	if !found {
+		return nil, nil
+	}
+	var limits map[string]int
+	if err := yaml.Unmarshal([]byte(limitsYAML), &limits); err != nil {
+		return nil, fmt.Errorf("can't parse YAML with %s: %v", key, err)
+	}
+	for namespace, limit := range limits {
+		if limit < 0 {
+			return nil, fmt.Errorf("%s limit of namespace %s is negative: %d", key, namespace, limit)
+		}
+	}
+	return limits, nil
+}
+
+// countNodesByNamespace returns the number of nodes attributed to each namespace.
+// Nodes can run pods of several namespaces, so this is a heuristic: each node
+// is attributed to the namespace with most pods on it, not counting DaemonSet
+// and mirror pods, with ties broken by namespace name. Nodes running only
+// such pods aren't attributed to any namespace.
+func countNodesByNamespace(nodeInfos []*schedulerframework.NodeInfo) map[string]int {
+	result := make(map[string]int)
+	for _, nodeInfo := range nodeInfos {
+		podsByNamespace := make(map[string]int)
+		for _, podInfo := range nodeInfo.Pods {
+			if pod_util.IsDaemonSetPod(podInfo.Pod) || pod_util.IsMirrorPod(podInfo.Pod) {
+				continue
+			}
+			podsByNamespace[podInfo.Pod.Namespace]++
+		}
+		owner, ownerPods := "", 0
+		for namespace, count := range podsByNamespace {
+			if count > ownerPods || (count == ownerPods && namespace < owner) {
+				owner, ownerPods = namespace, count
+			}
+		}
+		if ownerPods > 0 {
+			result[owner]++
+		}
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podlistprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube_record "k8s.io/client-go/tools/record"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestNamespaceQuotasPodListProcessor(t *testing.T) {
+	upcomingNode := BuildTestNode("n2", 1000, 10)
+	upcomingNode.Annotations = map[string]string{upcomingNodeAnnotation: "true"}
+	nodes := []*apiv1.Node{BuildTestNode("n1", 1000, 10), upcomingNode, BuildTestNode("n3", 1000, 10)}
+	scheduledPod := func(name, namespace, nodeName string) *apiv1.Pod {
+		return BuildTestPod(name, 100, 1, WithNamespace(namespace), WithNodeName(nodeName))
+	}
+	pods := []*apiv1.Pod{
+		scheduledPod("a1", "a", "n1"),
+		scheduledPod("a2", "a", "n2"),
+		scheduledPod("b1", "b", "n2"),
+		scheduledPod("b2", "b", "n3"),
+		SetDSPodSpec(scheduledPod("ds1", "c", "n3")),
+		SetDSPodSpec(scheduledPod("ds2", "c", "n3")),
+	}
+	pendingA := BuildTestPod("pa", 100, 1, WithNamespace("a"))
+	pendingB := BuildTestPod("pb", 100, 1, WithNamespace("b"))
+	pendingC := BuildTestPod("pc", 100, 1, WithNamespace("c"))
+	unschedulablePods := []*apiv1.Pod{pendingA, pendingB, pendingC}
+	pendingA2 := BuildTestPod("pa2", 100, 1, WithNamespace("a"))
+	pendingA3 := BuildTestPod("pa3", 100, 1, WithNamespace("a"))
+
+	testCases := []struct {
+		name              string
+		configMaps        []*apiv1.ConfigMap
+		unschedulablePods []*apiv1.Pod
+		wantPods          []*apiv1.Pod
+		wantEvents        int
+	}{
+		{
+			name:     "no config map",
+			wantPods: unschedulablePods,
+		},
+		{
+			name:       "quotas of namespaces",
+			configMaps: []*apiv1.ConfigMap{quotasConfigMap("a: 2\nb: 2\nc: 0\n")},
+			wantPods:   []*apiv1.Pod{pendingB},
+			wantEvents: 2,
+		},
+		{
+			name:       "default quota",
+			configMaps: []*apiv1.ConfigMap{quotasConfigMap("a: 3\n\"*\": 1\n")},
+			wantPods:   []*apiv1.Pod{pendingA, pendingC},
+			wantEvents: 1,
+		},
+		{
+			name:              "below quota, pods capped by quota left",
+			configMaps:        []*apiv1.ConfigMap{quotasConfigMap("a: 4\n")},
+			unschedulablePods: []*apiv1.Pod{pendingA, pendingA2, pendingA3, pendingB},
+			wantPods:          []*apiv1.Pod{pendingA, pendingA2, pendingB},
+			wantEvents:        1,
+		},
+		{
+			name:       "pending nodes limits",
+			configMaps: []*apiv1.ConfigMap{limitsConfigMap(map[string]string{NamespacePendingNodesConfigMapKey: "a: 1\n\"*\": 2\n"})},
+			wantPods:   []*apiv1.Pod{pendingB, pendingC},
+			wantEvents: 1,
+		},
+		{
+			name: "pods capped by the stricter of quota and pending nodes limit",
+			configMaps: []*apiv1.ConfigMap{limitsConfigMap(map[string]string{
+				NamespaceQuotasConfigMapKey:       "a: 4\n",
+				NamespacePendingNodesConfigMapKey: "a: 2\n",
+			})},
+			unschedulablePods: []*apiv1.Pod{pendingA, pendingA2, pendingA3, pendingB},
+			wantPods:          []*apiv1.Pod{pendingA, pendingB},
+			wantEvents:        2,
+		},
+		{
+			name:       "config map without limits",
+			configMaps: []*apiv1.ConfigMap{limitsConfigMap(map[string]string{})},
+			wantPods:   unschedulablePods,
+			wantEvents: 1,
+		},
+		{
+			name:       "invalid config map",
+			configMaps: []*apiv1.ConfigMap{quotasConfigMap("a: -1\n")},
+			wantPods:   unschedulablePods,
+			wantEvents: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := kube_record.NewFakeRecorder(10)
+			ctx := context.AutoscalingContext{
+				AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: recorder},
+				ClusterSnapshot:        clustersnapshot.NewBasicClusterSnapshot(),
+			}
+			clustersnapshot.InitializeClusterSnapshotOrDie(t, ctx.ClusterSnapshot, nodes, pods)
+			lister, err := kube_util.NewTestConfigMapLister(tc.configMaps)
+			require.NoError(t, err)
+
+			processor := NewNamespaceQuotasPodListProcessor(lister.ConfigMaps("kube-system"), make(chan struct{}))
+			defer processor.CleanUp()
+			pending := unschedulablePods
+			if tc.unschedulablePods != nil {
+				pending = tc.unschedulablePods
+			}
+			gotPods, err := processor.Process(&ctx, pending)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tc.wantPods, gotPods)
+			assert.Len(t, recorder.Events, tc.wantEvents)
+		})
+	}
+}
+
+func quotasConfigMap(quotas string) *apiv1.ConfigMap {
+	return limitsConfigMap(map[string]string{NamespaceQuotasConfigMapKey: quotas})
+}
+
+func limitsConfigMap(data map[string]string) *apiv1.ConfigMap {
+	return &apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: NamespaceQuotasConfigMapName},
+		Data:       data,
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package podlistprocessor
 
 import (
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pipeline"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
 )
@@ -24,11 +25,19 @@ import (
 // NewDefaultPodListProcessor returns a default implementation of the pod list
 // processor, which wraps and sequentially runs other sub-processors.
 func NewDefaultPodListProcessor(predicateChecker predicatechecker.PredicateChecker) *pods.CombinedPodListProcessor {
-	return pods.NewCombinedPodListProcessor([]pods.PodListProcessor{
-		NewClearTPURequestsPodListProcessor(),
-		NewFilterOutExpendablePodListProcessor(),
-		NewCurrentlyDrainedNodesPodListProcessor(),
-		NewFilterOutSchedulablePodListProcessor(predicateChecker),
-		NewFilterOutDaemonSetPodListProcessor(),
-	})
+	processors, _ := pipeline.Build(nil, DefaultPodListProcessorStages(predicateChecker))
+	return pods.NewCombinedPodListProcessor(processors)
+}
+
+// DefaultPodListProcessorStages returns the sub-processors of the default pod
+// list processor in the order they're run, named for the processors pipeline file.
+func DefaultPodListProcessorStages(predicateChecker predicatechecker.PredicateChecker) []pipeline.Stage[pods.PodListProcessor] {
+	return []pipeline.Stage[pods.PodListProcessor]{
+		{Name: "clear-tpu-requests", Processor: NewClearTPURequestsPodListProcessor()},
+		{Name: "filter-out-expendable", Processor: NewFilterOutExpendablePodListProcessor()},
+		{Name: "currently-drained-nodes", Processor: NewCurrentlyDrainedNodesPodListProcessor()},
+		{Name: "maintenance-nodes", Processor: NewMaintenanceNodesPodListProcessor()},
+		{Name: "filter-out-schedulable", Processor: NewFilterOutSchedulablePodListProcessor(predicateChecker)},
+		{Name: "filter-out-daemon-sets", Processor: NewFilterOutDaemonSetPodListProcessor()},
+	}
 }
@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podlistprocessor
+
+import (
+	ctx "context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/equivalence"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+const (
+	// ScaleUpFingerprintsConfigMapName is the name of the ConfigMap persisting
+	// recent scale-ups across autoscaler restarts.
+	ScaleUpFingerprintsConfigMapName = "cluster-autoscaler-scale-up-fingerprints"
+	// ScaleUpFingerprintsConfigMapKey is the key of the ConfigMap holding a JSON
+	// map from pod fingerprints to the scale-ups they triggered.
+	ScaleUpFingerprintsConfigMapKey = "fingerprints"
+)
+
+// scaleUpRecord is a scale-up triggered by pods with the same fingerprint.
+type scaleUpRecord struct {
+	NodeGroups []string `json:"nodeGroups"`
+	// TargetSizes maps the scaled-up node groups to their target sizes after the scale-up.
+	TargetSizes map[string]int `json:"targetSizes,omitempty"`
+	// Nodes is the number of nodes added by the scale-up.
+	Nodes int       `json:"nodes,omitempty"`
+	Pods  int       `json:"pods"`
+	Time  time.Time `json:"time"`
+}
+
+// ScaleUpFingerprints persists recent scale-ups in the ScaleUpFingerprintsConfigMapName
+// ConfigMap, keyed by fingerprints of the pod equivalence groups which triggered them.
+//
+// Pods fitting on upcoming nodes, i.e. nodes of the target sizes reported by the cloud
+// provider which didn't register yet, are already filtered out as schedulable. After a
+// restart, the pods of a scale-up recorded before the restart are filtered out only for
+// the part of the scale-up not reflected in the target sizes yet, e.g. when the cloud
+// provider didn't apply the size increase before the autoscaler restarted. Records are
+// kept until nodes of the scaled-up node groups register or MaxNodeProvisionTime passes.
+// Scale-ups of the current run are already tracked by the cluster state, so they're only
+// persisted.
+type ScaleUpFingerprints struct {
+	startTime time.Time
+	loaded    bool
+	dirty     bool
+	records   map[string]scaleUpRecord
+}
+
+// NewScaleUpFingerprints returns a new ScaleUpFingerprints. Its PodListProcessor and
+// ScaleUpStatusProcessor have to be registered to filter out and record pods respectively.
+func NewScaleUpFingerprints() *ScaleUpFingerprints {
+	return &ScaleUpFingerprints{
+		startTime: time.Now(),
+		records:   map[string]scaleUpRecord{},
+	}
+}
+
+// PodListProcessor returns a processor filtering out unschedulable pods which triggered
+// a scale-up before the restart.
+func (f *ScaleUpFingerprints) PodListProcessor() pods.PodListProcessor {
+	return &scaleUpFingerprintsPodListProcessor{fingerprints: f}
+}
+
+// ScaleUpStatusProcessor returns a processor recording successful scale-ups.
+func (f *ScaleUpFingerprints) ScaleUpStatusProcessor() status.ScaleUpStatusProcessor {
+	return &scaleUpFingerprintsStatusProcessor{fingerprints: f}
+}
+
+type scaleUpFingerprintsPodListProcessor struct {
+	fingerprints *ScaleUpFingerprints
+}
+
+// Process filters out pods matching scale-ups recorded before the restart.
+func (p *scaleUpFingerprintsPodListProcessor) Process(context *context.AutoscalingContext, unschedulablePods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	f := p.fingerprints
+	f.load(context)
+	f.expire(context, time.Now())
+
+	remaining := map[string]int{}
+	var targetSizes map[string]int
+	for fingerprint, record := range f.records {
+		if !record.Time.Before(f.startTime) {
+			continue
+		}
+		if targetSizes == nil {
+			targetSizes = currentTargetSizes(context)
+		}
+		if pods := record.uncoveredPods(targetSizes); pods > 0 {
+			remaining[fingerprint] = pods
+		}
+	}
+	if len(remaining) == 0 || len(unschedulablePods) == 0 {
+		return unschedulablePods, nil
+	}
+
+	var result []*apiv1.Pod
+	for _, pod := range unschedulablePods {
+		fingerprint, found := equivalence.Fingerprint(pod)
+		if !found || remaining[fingerprint] == 0 {
+			result = append(result, pod)
+			continue
+		}
+		remaining[fingerprint]--
+		if !pod_util.IsVirtualPod(pod) {
+			context.Recorder.Eventf(pod, apiv1.EventTypeNormal, "NotTriggerScaleUp",
+				"pod didn't trigger scale-up: it triggered scale-up of %v before cluster autoscaler restarted", f.records[fingerprint].NodeGroups)
+		}
+	}
+	if filteredOut := len(unschedulablePods) - len(result); filteredOut > 0 {
+		klog.V(2).Infof("Filtered out %d pods which triggered scale-ups before cluster autoscaler restarted", filteredOut)
+	}
+	return result, nil
+}
+
+func (p *scaleUpFingerprintsPodListProcessor) CleanUp() {
+}
+
+type scaleUpFingerprintsStatusProcessor struct {
+	fingerprints *ScaleUpFingerprints
+}
+
+// Process records the fingerprints of pods which triggered a successful scale-up and
+// persists changed records.
+func (p *scaleUpFingerprintsStatusProcessor) Process(context *context.AutoscalingContext, status *status.ScaleUpStatus) {
+	f := p.fingerprints
+	f.load(context)
+	if status.WasSuccessful() && !context.DryRun {
+		f.record(status, time.Now())
+	}
+	if f.dirty && f.loaded {
+		if err := f.persist(context); err != nil {
+			klog.Errorf("Failed to persist scale-up fingerprints: %v", err)
+			return
+		}
+		f.dirty = false
+	}
+}
+
+func (p *scaleUpFingerprintsStatusProcessor) CleanUp() {
+}
+
+// uncoveredPods returns how many pods of the scale-up aren't covered by upcoming
+// nodes, proportionally to the nodes of the scale-up missing in the current target
+// sizes of its node groups.
+func (r scaleUpRecord) uncoveredPods(targetSizes map[string]int) int {
+	if len(r.TargetSizes) == 0 || r.Nodes <= 0 {
+		return r.Pods
+	}
+	missing := 0
+	for nodeGroup, targetSize := range r.TargetSizes {
+		if current, found := targetSizes[nodeGroup]; found && current < targetSize {
+			missing += targetSize - current
+		}
+	}
+	if missing >= r.Nodes {
+		return r.Pods
+	}
+	return (r.Pods*missing + r.Nodes - 1) / r.Nodes
+}
+
+// currentTargetSizes returns the target sizes of all node groups.
+func currentTargetSizes(context *context.AutoscalingContext) map[string]int {
+	result := map[string]int{}
+	for _, nodeGroup := range context.CloudProvider.NodeGroups() {
+		targetSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			klog.Warningf("Failed to get target size of node group %s: %v", nodeGroup.Id(), err)
+			continue
+		}
+		result[nodeGroup.Id()] = targetSize
+	}
+	return result
+}
+
+// record adds the scale-up to the records of the fingerprints of the pods which
+// triggered it.
+func (f *ScaleUpFingerprints) record(status *status.ScaleUpStatus, now time.Time) {
+	counts := map[string]int{}
+	for _, pod := range status.PodsTriggeredScaleUp {
+		if fingerprint, found := equivalence.Fingerprint(pod); found {
+			counts[fingerprint]++
+		}
+	}
+	for fingerprint, count := range counts {
+		record := f.records[fingerprint]
+		if record.TargetSizes == nil {
+			record.TargetSizes = map[string]int{}
+		}
+		for _, info := range status.ScaleUpInfos {
+			id := info.Group.Id()
+			if _, found := record.TargetSizes[id]; !found {
+				record.NodeGroups = append(record.NodeGroups, id)
+			}
+			if info.NewSize > record.TargetSizes[id] {
+				record.TargetSizes[id] = info.NewSize
+			}
+			record.Nodes += info.NewSize - info.CurrentSize
+		}
+		record.Pods += count
+		record.Time = now
+		f.records[fingerprint] = record
+		f.dirty = true
+	}
+}
+
+// expire drops records older than MaxNodeProvisionTime and records of scale-ups
+// whose nodes registered.
+func (f *ScaleUpFingerprints) expire(context *context.AutoscalingContext, now time.Time) {
+	if len(f.records) == 0 {
+		return
+	}
+	registered := f.nodeGroupsRegistrationTimes(context)
+	for fingerprint, record := range f.records {
+		expired := now.Sub(record.Time) > context.NodeGroupDefaults.MaxNodeProvisionTime
+		for _, nodeGroup := range record.NodeGroups {
+			if registrationTime, found := registered[nodeGroup]; found && registrationTime.After(record.Time) {
+				expired = true
+			}
+		}
+		if expired {
+			delete(f.records, fingerprint)
+			f.dirty = true
+		}
+	}
+}
+
+// nodeGroupsRegistrationTimes returns the creation time of the newest node of each node group.
+func (f *ScaleUpFingerprints) nodeGroupsRegistrationTimes(context *context.AutoscalingContext) map[string]time.Time {
+	result := map[string]time.Time{}
+	nodeInfos, err := context.ClusterSnapshot.NodeInfos().List()
+	if err != nil {
+		klog.Errorf("Failed to list node infos: %v", err)
+		return result
+	}
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		nodeGroup, err := context.CloudProvider.NodeGroupForNode(node)
+		if err != nil || nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+			continue
+		}
+		if created := node.CreationTimestamp.Time; created.After(result[nodeGroup.Id()]) {
+			result[nodeGroup.Id()] = created
+		}
+	}
+	return result
+}
+
+// load reads the records persisted by the previous run, once. Failed reads are retried.
+func (f *ScaleUpFingerprints) load(context *context.AutoscalingContext) {
+	if f.loaded {
+		return
+	}
+	cm, err := context.ClientSet.CoreV1().ConfigMaps(context.ConfigNamespace).Get(ctx.TODO(), ScaleUpFingerprintsConfigMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		f.loaded = true
+		return
+	}
+	if err != nil {
+		klog.Errorf("Failed to get scale-up fingerprints config map %s: %v", ScaleUpFingerprintsConfigMapName, err)
+		return
+	}
+	f.loaded = true
+	records := map[string]scaleUpRecord{}
+	if err := json.Unmarshal([]byte(cm.Data[ScaleUpFingerprintsConfigMapKey]), &records); err != nil {
+		klog.Warningf("Ignoring invalid scale-up fingerprints in config map %s: %v", ScaleUpFingerprintsConfigMapName, err)
+		f.dirty = true
+		return
+	}
+	for fingerprint, record := range records {
+		if _, found := f.records[fingerprint]; !found {
+			f.records[fingerprint] = record
+		}
+	}
+	klog.V(1).Infof("Loaded %d scale-up fingerprints from config map %s", len(records), ScaleUpFingerprintsConfigMapName)
+}
+
+func (f *ScaleUpFingerprints) persist(context *context.AutoscalingContext) error {
+	content, err := json.Marshal(f.records)
+	if err != nil {
+		return err
+	}
+	maps := context.ClientSet.CoreV1().ConfigMaps(context.ConfigNamespace)
+	cm, err := maps.Get(ctx.TODO(), ScaleUpFingerprintsConfigMapName, metav1.GetOptions{})
+	if kube_errors.IsNotFound(err) {
+		cm = &apiv1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: context.ConfigNamespace,
+				Name:      ScaleUpFingerprintsConfigMapName,
+			},
+			Data: map[string]string{
+				ScaleUpFingerprintsConfigMapKey: string(content),
+			},
+		}
+		_, err = maps.Create(ctx.TODO(), cm, metav1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[ScaleUpFingerprintsConfigMapKey] = string(content)
+	_, err = maps.Update(ctx.TODO(), cm, metav1.UpdateOptions{})
+	return err
+}
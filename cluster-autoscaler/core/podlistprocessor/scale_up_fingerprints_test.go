@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podlistprocessor
+
+import (
+	ctx "context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/equivalence"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestScaleUpFingerprints(t *testing.T) {
+	now := time.Now()
+	pendingPods := func(rsName string, count int) []*apiv1.Pod {
+		var pods []*apiv1.Pod
+		for i := 0; i < count; i++ {
+			pods = append(pods, SetRSPodSpec(BuildTestPod(fmt.Sprintf("%s-%d", rsName, i), 100, 1), rsName))
+		}
+		return pods
+	}
+	rs1Pods := pendingPods("rs1", 3)
+	rs2Pods := pendingPods("rs2", 2)
+	rs1Fingerprint, found := equivalence.Fingerprint(rs1Pods[0])
+	require.True(t, found)
+	rs2Fingerprint, found := equivalence.Fingerprint(rs2Pods[0])
+	require.True(t, found)
+
+	// Scale-ups recorded by the previous run: ng1 from 1 to 3 nodes for two rs1 pods, the cloud
+	// provider didn't apply it before the restart, and ng2 from 1 to 2 nodes for one rs2 pod,
+	// whose upcoming node is already accounted for by filtering out schedulable pods.
+	records, err := json.Marshal(map[string]scaleUpRecord{
+		rs1Fingerprint: {NodeGroups: []string{"ng1"}, TargetSizes: map[string]int{"ng1": 3}, Nodes: 2, Pods: 2, Time: now.Add(-time.Minute)},
+		rs2Fingerprint: {NodeGroups: []string{"ng2"}, TargetSizes: map[string]int{"ng2": 2}, Nodes: 1, Pods: 1, Time: now.Add(-time.Minute)},
+	})
+	require.NoError(t, err)
+	fakeClient := fake.NewSimpleClientset(&apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: ScaleUpFingerprintsConfigMapName},
+		Data:       map[string]string{ScaleUpFingerprintsConfigMapKey: string(records)},
+	})
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 1)
+	provider.AddNodeGroup("ng2", 0, 10, 2)
+	oldNode := BuildTestNode("old", 1000, 1000)
+	oldNode.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	provider.AddNode("ng1", oldNode)
+	autoscalingCtx := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{
+			ConfigNamespace:   "kube-system",
+			NodeGroupDefaults: config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute},
+		},
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			ClientSet: fakeClient,
+			Recorder:  kube_record.NewFakeRecorder(10),
+		},
+		CloudProvider:   provider,
+		ClusterSnapshot: clustersnapshot.NewBasicClusterSnapshot(),
+	}
+	clustersnapshot.InitializeClusterSnapshotOrDie(t, autoscalingCtx.ClusterSnapshot, []*apiv1.Node{oldNode}, nil)
+
+	fingerprints := NewScaleUpFingerprints()
+	podListProcessor := fingerprints.PodListProcessor()
+	statusProcessor := fingerprints.ScaleUpStatusProcessor()
+
+	// Pods which triggered the scale-up before the restart are filtered out, unless upcoming nodes cover them.
+	unschedulablePods := append(append([]*apiv1.Pod{}, rs1Pods...), rs2Pods...)
+	got, err := podListProcessor.Process(autoscalingCtx, unschedulablePods)
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{rs1Pods[2], rs2Pods[0], rs2Pods[1]}, got)
+
+	// Scale-ups of the current run are persisted, but don't filter out pods.
+	ng1 := provider.GetNodeGroup("ng1")
+	statusProcessor.Process(autoscalingCtx, &status.ScaleUpStatus{
+		Result:               status.ScaleUpSuccessful,
+		ScaleUpInfos:         []nodegroupset.ScaleUpInfo{{Group: ng1, CurrentSize: 2, NewSize: 3, MaxSize: 10}},
+		PodsTriggeredScaleUp: []*apiv1.Pod{rs1Pods[2], rs2Pods[0], rs2Pods[1]},
+	})
+	persisted := persistedScaleUpRecords(t, fakeClient)
+	assert.Equal(t, 2, len(persisted))
+	// Counts are accumulated with the records of the previous run.
+	assert.Equal(t, 3, persisted[rs1Fingerprint].Pods)
+	assert.Equal(t, 3, persisted[rs1Fingerprint].Nodes)
+	assert.Equal(t, map[string]int{"ng1": 3}, persisted[rs1Fingerprint].TargetSizes)
+	assert.Equal(t, 3, persisted[rs2Fingerprint].Pods)
+	assert.Equal(t, []string{"ng2", "ng1"}, persisted[rs2Fingerprint].NodeGroups)
+	got, err = podListProcessor.Process(autoscalingCtx, unschedulablePods)
+	assert.NoError(t, err)
+	assert.Equal(t, unschedulablePods, got)
+
+	// Records of scale-ups whose nodes registered are dropped.
+	newNode := BuildTestNode("new", 1000, 1000)
+	newNode.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Second))
+	provider.AddNode("ng1", newNode)
+	clustersnapshot.InitializeClusterSnapshotOrDie(t, autoscalingCtx.ClusterSnapshot, []*apiv1.Node{oldNode, newNode}, nil)
+	_, err = podListProcessor.Process(autoscalingCtx, unschedulablePods)
+	assert.NoError(t, err)
+	statusProcessor.Process(autoscalingCtx, &status.ScaleUpStatus{Result: status.ScaleUpNotNeeded})
+	persisted = persistedScaleUpRecords(t, fakeClient)
+	assert.NotContains(t, persisted, rs1Fingerprint)
+	assert.NotContains(t, persisted, rs2Fingerprint)
+}
+
+func TestScaleUpFingerprintsExpire(t *testing.T) {
+	pod := SetRSPodSpec(BuildTestPod("p", 100, 1), "rs")
+	fingerprint, found := equivalence.Fingerprint(pod)
+	require.True(t, found)
+	records, err := json.Marshal(map[string]scaleUpRecord{
+		fingerprint: {NodeGroups: []string{"ng1"}, Pods: 1, Time: time.Now().Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+	fakeClient := fake.NewSimpleClientset(&apiv1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: ScaleUpFingerprintsConfigMapName},
+		Data:       map[string]string{ScaleUpFingerprintsConfigMapKey: string(records)},
+	})
+	autoscalingCtx := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{
+			ConfigNamespace:   "kube-system",
+			NodeGroupDefaults: config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute},
+		},
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			ClientSet: fakeClient,
+			Recorder:  kube_record.NewFakeRecorder(10),
+		},
+		CloudProvider:   testprovider.NewTestCloudProvider(nil, nil),
+		ClusterSnapshot: clustersnapshot.NewBasicClusterSnapshot(),
+	}
+
+	fingerprints := NewScaleUpFingerprints()
+	got, err := fingerprints.PodListProcessor().Process(autoscalingCtx, []*apiv1.Pod{pod})
+	assert.NoError(t, err)
+	assert.Equal(t, []*apiv1.Pod{pod}, got)
+}
+
+func TestScaleUpRecordUncoveredPods(t *testing.T) {
+	record := scaleUpRecord{TargetSizes: map[string]int{"ng1": 5, "ng2": 4}, Nodes: 4, Pods: 8}
+	assert.Equal(t, 8, record.uncoveredPods(map[string]int{"ng1": 3, "ng2": 2}))
+	assert.Equal(t, 6, record.uncoveredPods(map[string]int{"ng1": 4, "ng2": 2}))
+	assert.Equal(t, 2, record.uncoveredPods(map[string]int{"ng1": 5, "ng2": 3}))
+	assert.Equal(t, 0, record.uncoveredPods(map[string]int{"ng1": 5, "ng2": 4}))
+	// Nodes of removed node groups won't come up, so their pods aren't filtered out.
+	assert.Equal(t, 0, record.uncoveredPods(map[string]int{}))
+	// Records without target sizes filter out all their pods.
+	assert.Equal(t, 3, scaleUpRecord{NodeGroups: []string{"ng1"}, Pods: 3}.uncoveredPods(nil))
+}
+
+func persistedScaleUpRecords(t *testing.T, client *fake.Clientset) map[string]scaleUpRecord {
+	cm, err := client.CoreV1().ConfigMaps("kube-system").Get(ctx.TODO(), ScaleUpFingerprintsConfigMapName, metav1.GetOptions{})
+	require.NoError(t, err)
+	records := map[string]scaleUpRecord{}
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[ScaleUpFingerprintsConfigMapKey]), &records))
+	return records
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rediscovery
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	klog "k8s.io/klog/v2"
+)
+
+// Trigger requests node group rediscovery on demand, e.g. from a signal
+// handler or an HTTP endpoint. It's safe for concurrent use.
+type Trigger struct {
+	requested atomic.Bool
+}
+
+// NewTrigger returns a new Trigger.
+func NewTrigger() *Trigger {
+	return &Trigger{}
+}
+
+// Request requests node group rediscovery before the next autoscaler loop.
+func (t *Trigger) Request() {
+	t.requested.Store(true)
+}
+
+// ServeHTTP requests node group rediscovery. Only POST requests are accepted.
+func (t *Trigger) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	klog.V(1).Info("Node group rediscovery requested over HTTP")
+	t.Request()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// consume returns true if rediscovery was requested, resetting the request.
+func (t *Trigger) consume() bool {
+	return t != nil && t.requested.Swap(false)
+}
+
+// NodeGroupRediscovery periodically, or on demand, rebuilds node group
+// definitions of the cloud provider, so that out-of-band changes such as
+// modified min and max sizes are picked up without a restart.
+type NodeGroupRediscovery struct {
+	rediscoverer    cloudprovider.NodeGroupRediscoverer
+	interval        time.Duration
+	trigger         *Trigger
+	lastRediscovery time.Time
+}
+
+// NewNodeGroupRediscovery returns a new NodeGroupRediscovery rediscovering
+// node groups every interval, if positive, and whenever the trigger, if not
+// nil, is requested. It returns nil if rediscovery is disabled or not
+// supported by the cloud provider.
+func NewNodeGroupRediscovery(cloudProvider cloudprovider.CloudProvider, interval time.Duration, trigger *Trigger, now time.Time) *NodeGroupRediscovery {
+	if interval <= 0 && trigger == nil {
+		return nil
+	}
+	rediscoverer, ok := cloudProvider.(cloudprovider.NodeGroupRediscoverer)
+	if !ok {
+		klog.Warningf("Node group rediscovery is enabled, but not supported by cloud provider %s", cloudProvider.Name())
+		return nil
+	}
+	return &NodeGroupRediscovery{
+		rediscoverer:    rediscoverer,
+		interval:        interval,
+		trigger:         trigger,
+		lastRediscovery: now,
+	}
+}
+
+// RediscoverIfNeeded rebuilds node group definitions if rediscovery was
+// requested or the interval passed since the last rediscovery. Failed
+// rediscoveries are retried in the next loop.
+func (r *NodeGroupRediscovery) RediscoverIfNeeded(now time.Time) error {
+	requested := r.trigger.consume()
+	if !requested && (r.interval <= 0 || now.Sub(r.lastRediscovery) < r.interval) {
+		return nil
+	}
+	klog.V(1).Infof("Rediscovering node groups (requested: %v, last rediscovery: %v)", requested, r.lastRediscovery)
+	if err := r.rediscoverer.RediscoverNodeGroups(); err != nil {
+		if requested {
+			r.trigger.Request()
+		}
+		return err
+	}
+	r.lastRediscovery = now
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rediscovery
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+)
+
+type rediscoveringProvider struct {
+	*testprovider.TestCloudProvider
+	rediscoveries int
+	err           error
+}
+
+func (p *rediscoveringProvider) RediscoverNodeGroups() error {
+	p.rediscoveries++
+	return p.err
+}
+
+func TestNewNodeGroupRediscovery(t *testing.T) {
+	now := time.Now()
+	provider := &rediscoveringProvider{TestCloudProvider: testprovider.NewTestCloudProvider(nil, nil)}
+	assert.Nil(t, NewNodeGroupRediscovery(provider, 0, nil, now))
+	assert.NotNil(t, NewNodeGroupRediscovery(provider, time.Hour, nil, now))
+	assert.NotNil(t, NewNodeGroupRediscovery(provider, 0, NewTrigger(), now))
+	assert.Nil(t, NewNodeGroupRediscovery(testprovider.NewTestCloudProvider(nil, nil), time.Hour, NewTrigger(), now))
+}
+
+func TestRediscoverIfNeeded(t *testing.T) {
+	now := time.Now()
+	provider := &rediscoveringProvider{TestCloudProvider: testprovider.NewTestCloudProvider(nil, nil)}
+	trigger := NewTrigger()
+	r := NewNodeGroupRediscovery(provider, time.Hour, trigger, now)
+
+	assert.NoError(t, r.RediscoverIfNeeded(now.Add(time.Minute)))
+	assert.Equal(t, 0, provider.rediscoveries)
+
+	// Requested rediscovery happens once.
+	trigger.Request()
+	assert.NoError(t, r.RediscoverIfNeeded(now.Add(2*time.Minute)))
+	assert.NoError(t, r.RediscoverIfNeeded(now.Add(3*time.Minute)))
+	assert.Equal(t, 1, provider.rediscoveries)
+
+	// The interval counts from the last rediscovery.
+	assert.NoError(t, r.RediscoverIfNeeded(now.Add(time.Hour+time.Minute)))
+	assert.Equal(t, 1, provider.rediscoveries)
+	assert.NoError(t, r.RediscoverIfNeeded(now.Add(time.Hour+2*time.Minute)))
+	assert.Equal(t, 2, provider.rediscoveries)
+
+	// Failed requested rediscovery is retried.
+	provider.err = fmt.Errorf("cloud provider error")
+	trigger.Request()
+	assert.Error(t, r.RediscoverIfNeeded(now.Add(time.Hour+3*time.Minute)))
+	provider.err = nil
+	assert.NoError(t, r.RediscoverIfNeeded(now.Add(time.Hour+4*time.Minute)))
+	assert.Equal(t, 4, provider.rediscoveries)
+	assert.NoError(t, r.RediscoverIfNeeded(now.Add(time.Hour+5*time.Minute)))
+	assert.Equal(t, 4, provider.rediscoveries)
+}
+
+func TestTriggerServeHTTP(t *testing.T) {
+	trigger := NewTrigger()
+
+	w := httptest.NewRecorder()
+	trigger.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rediscover-node-groups", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.False(t, trigger.consume())
+
+	w = httptest.NewRecorder()
+	trigger.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/rediscover-node-groups", nil))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.True(t, trigger.consume())
+	assert.False(t, trigger.consume())
+}
@@ -68,6 +68,8 @@ type Actuator struct {
 type actuatorNodeGroupConfigGetter interface {
 	// GetIgnoreDaemonSetsUtilization returns IgnoreDaemonSetsUtilization value that should be used for a given NodeGroup.
 	GetIgnoreDaemonSetsUtilization(nodeGroup cloudprovider.NodeGroup) (bool, error)
+	// GetMaxScaleDownParallelism returns MaxScaleDownParallelism value that should be used for a given NodeGroup.
+	GetMaxScaleDownParallelism(nodeGroup cloudprovider.NodeGroup) (int, error)
 }
 
 // NewActuator returns a new instance of Actuator.
@@ -84,7 +86,7 @@ func NewActuator(ctx *context.AutoscalingContext, scaleStateNotifier nodegroupch
 		ctx:                       ctx,
 		nodeDeletionTracker:       ndt,
 		nodeDeletionScheduler:     NewGroupDeletionScheduler(ctx, ndt, ndb, evictor),
-		budgetProcessor:           budgets.NewScaleDownBudgetProcessor(ctx),
+		budgetProcessor:           budgets.NewScaleDownBudgetProcessor(ctx, configGetter),
 		deleteOptions:             deleteOptions,
 		drainabilityRules:         drainabilityRules,
 		configGetter:              configGetter,
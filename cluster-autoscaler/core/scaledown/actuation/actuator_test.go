@@ -1196,7 +1196,7 @@ func TestStartDeletion(t *testing.T) {
 				actuator := Actuator{
 					ctx: &ctx, nodeDeletionTracker: ndt,
 					nodeDeletionScheduler: NewGroupDeletionScheduler(&ctx, ndt, ndb, evictor),
-					budgetProcessor:       budgets.NewScaleDownBudgetProcessor(&ctx),
+					budgetProcessor:       budgets.NewScaleDownBudgetProcessor(&ctx, nodegroupconfig.NewDefaultNodeGroupConfigProcessor(ctx.NodeGroupDefaults)),
 					configGetter:          nodegroupconfig.NewDefaultNodeGroupConfigProcessor(ctx.NodeGroupDefaults),
 				}
 				gotResult, gotScaleDownNodes, gotErr := actuator.StartDeletion(allEmptyNodes, allDrainNodes)
@@ -1435,7 +1435,7 @@ func TestStartDeletionInBatchBasic(t *testing.T) {
 			actuator := Actuator{
 				ctx: &ctx, nodeDeletionTracker: ndt,
 				nodeDeletionScheduler: NewGroupDeletionScheduler(&ctx, ndt, ndb, evictor),
-				budgetProcessor:       budgets.NewScaleDownBudgetProcessor(&ctx),
+				budgetProcessor:       budgets.NewScaleDownBudgetProcessor(&ctx, nodegroupconfig.NewDefaultNodeGroupConfigProcessor(ctx.NodeGroupDefaults)),
 			}
 
 			for _, nodes := range deleteNodes {
@@ -57,6 +57,8 @@ type Evictor struct {
 	evictionRegister                 evictionRegister
 	shutdownGracePeriodByPodPriority []kubelet_config.ShutdownGracePeriodByPodPriority
 	fullDsEviction                   bool
+	// drainDeadline is the time after which the drain is given up, zero if there is none.
+	drainDeadline time.Time
 }
 
 // NewEvictor returns an instance of Evictor.
@@ -85,6 +87,21 @@ func (e Evictor) DrainNode(ctx *acontext.AutoscalingContext, nodeInfo *framework
 	return e.drainNodeWithPodsBasedOnPodPriority(ctx, node, pods, dsPods)
 }
 
+// DrainNodeWithTimeout drains the node like DrainNode, but gives up after drainTimeout. If forceDelete is set,
+// pods remaining on the node after drainTimeout are force deleted and the drain is considered successful.
+func (e Evictor) DrainNodeWithTimeout(ctx *acontext.AutoscalingContext, nodeInfo *framework.NodeInfo, drainTimeout time.Duration, forceDelete bool) (map[string]status.PodEvictionResult, error) {
+	if drainTimeout <= 0 {
+		return e.DrainNode(ctx, nodeInfo)
+	}
+	e.drainDeadline = time.Now().Add(drainTimeout)
+	evictionResults, err := e.DrainNode(ctx, nodeInfo)
+	if err == nil || !forceDelete || !e.drainTimedOut() {
+		return evictionResults, err
+	}
+	klog.Warningf("Drain of node %s didn't complete within %v, force deleting remaining pods: %v", nodeInfo.Node().Name, drainTimeout, err)
+	return e.forceDeletePods(ctx, nodeInfo.Node(), evictionResults)
+}
+
 // EvictDaemonSetPods groups  daemonSet pods in the node in to priority groups and, evicts daemonSet pods in the ascending order of priorities.
 // If priority evictor is not enable, eviction of daemonSet pods is the best effort.
 func (e Evictor) EvictDaemonSetPods(ctx *acontext.AutoscalingContext, nodeInfo *framework.NodeInfo) (map[string]status.PodEvictionResult, error) {
@@ -135,7 +152,7 @@ func (e Evictor) drainNodeWithPodsBasedOnPodPriority(ctx *acontext.AutoscalingCo
 func (e Evictor) waitPodsToDisappear(ctx *acontext.AutoscalingContext, node *apiv1.Node, pods []*apiv1.Pod, evictionResults map[string]status.PodEvictionResult,
 	maxTermination int64) (map[string]status.PodEvictionResult, error) {
 	var allGone bool
-	for start := time.Now(); time.Now().Sub(start) < time.Duration(maxTermination)*time.Second+e.PodEvictionHeadroom; time.Sleep(5 * time.Second) {
+	for start := time.Now(); time.Now().Sub(start) < time.Duration(maxTermination)*time.Second+e.PodEvictionHeadroom && !e.drainTimedOut(); time.Sleep(5 * time.Second) {
 		allGone = true
 		for _, pod := range pods {
 			podReturned, err := ctx.ClientSet.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
@@ -173,6 +190,9 @@ func (e Evictor) initiateEviction(ctx *acontext.AutoscalingContext, node *apiv1.
 	maxTermination int64) (map[string]status.PodEvictionResult, error) {
 
 	retryUntil := time.Now().Add(ctx.MaxPodEvictionTime)
+	if !e.drainDeadline.IsZero() && e.drainDeadline.Before(retryUntil) {
+		retryUntil = e.drainDeadline
+	}
 	fullEvictionConfirmations := make(chan status.PodEvictionResult, len(fullEvictionPods))
 	bestEffortEvictionConfirmations := make(chan status.PodEvictionResult, len(bestEffortEvictionPods))
 
@@ -253,6 +273,38 @@ func (e Evictor) evictPod(ctx *acontext.AutoscalingContext, podToEvict *apiv1.Po
 	return status.PodEvictionResult{Pod: podToEvict, TimedOut: true, Err: fmt.Errorf("failed to evict pod %s/%s within allowed timeout (last error: %v)", podToEvict.Namespace, podToEvict.Name, lastError)}
 }
 
+// forceDeletePods deletes pods which weren't evicted successfully without waiting for their graceful termination.
+func (e Evictor) forceDeletePods(ctx *acontext.AutoscalingContext, node *apiv1.Node, evictionResults map[string]status.PodEvictionResult) (map[string]status.PodEvictionResult, error) {
+	gracePeriod := int64(0)
+	deleteErrs := make([]error, 0)
+	for name, result := range evictionResults {
+		if result.WasEvictionSuccessful() {
+			continue
+		}
+		pod := result.Pod
+		ctx.Recorder.Eventf(pod, apiv1.EventTypeWarning, "ScaleDown", "force deleting pod after node drain timeout")
+		// The UID precondition prevents deleting a pod recreated with the same name, e.g. by a StatefulSet.
+		err := ctx.ClientSet.CoreV1().Pods(pod.Namespace).Delete(context.TODO(), pod.Name, metav1.DeleteOptions{
+			GracePeriodSeconds: &gracePeriod,
+			Preconditions:      metav1.NewUIDPreconditions(string(pod.UID)),
+		})
+		if err != nil && !kube_errors.IsNotFound(err) && !kube_errors.IsConflict(err) {
+			klog.Errorf("Failed to force delete pod %s/%s, error: %v", pod.Namespace, pod.Name, err)
+			deleteErrs = append(deleteErrs, err)
+			continue
+		}
+		evictionResults[name] = status.PodEvictionResult{Pod: pod, TimedOut: false, Err: nil}
+	}
+	if len(deleteErrs) != 0 {
+		return evictionResults, errors.NewAutoscalerError(errors.ApiCallError, "Failed to force delete pods from node %s/%s, due to following errors: %v", node.Namespace, node.Name, deleteErrs)
+	}
+	return evictionResults, nil
+}
+
+func (e Evictor) drainTimedOut() bool {
+	return !e.drainDeadline.IsZero() && time.Now().After(e.drainDeadline)
+}
+
 func podsToEvict(nodeInfo *framework.NodeInfo, evictDsByDefault bool) (dsPods, nonDsPods []*apiv1.Pod) {
 	for _, podInfo := range nodeInfo.Pods {
 		if pod_util.IsMirrorPod(podInfo.Pod) {
@@ -564,6 +564,66 @@ func TestDrainNodeWithPodsEvictionFailure(t *testing.T) {
 	assert.Contains(t, r.pods, p1, p3)
 }
 
+func TestDrainNodeWithTimeout(t *testing.T) {
+	for _, forceDelete := range []bool{false, true} {
+		t.Run(fmt.Sprintf("forceDelete=%v", forceDelete), func(t *testing.T) {
+			fakeClient := &fake.Clientset{}
+
+			n1 := BuildTestNode("n1", 1000, 1000)
+			p1 := BuildTestPod("p1", 100, 0, WithNodeName(n1.Name))
+			p2 := BuildTestPod("p2", 100, 0, WithNodeName(n1.Name))
+			SetNodeReadyState(n1, true, time.Time{})
+
+			fakeClient.Fake.AddReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				eviction := action.(core.CreateAction).GetObject().(*policyv1beta1.Eviction)
+				if eviction.Name == "p2" {
+					return true, nil, errors.NewTooManyRequests("disruption budget exceeded", 0)
+				}
+				return true, nil, nil
+			})
+			fakeClient.Fake.AddReactor("get", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.NewNotFound(apiv1.Resource("pod"), "whatever")
+			})
+			var deletedPods []string
+			var deleteOptions []metav1.DeleteOptions
+			fakeClient.Fake.AddReactor("delete", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				deleteAction := action.(core.DeleteAction)
+				deletedPods = append(deletedPods, deleteAction.GetName())
+				deleteOptions = append(deleteOptions, deleteAction.GetDeleteOptions())
+				return true, nil, nil
+			})
+
+			options := config.AutoscalingOptions{
+				MaxGracefulTerminationSec: 20,
+				MaxPodEvictionTime:        time.Hour,
+			}
+			ctx, err := NewScaleTestAutoscalingContext(options, fakeClient, nil, nil, nil, nil)
+			assert.NoError(t, err)
+			evictor := Evictor{
+				EvictionRetryTime:                10 * time.Millisecond,
+				PodEvictionHeadroom:              DefaultPodEvictionHeadroom,
+				shutdownGracePeriodByPodPriority: SingleRuleDrainConfig(ctx.MaxGracefulTerminationSec),
+			}
+			clustersnapshot.InitializeClusterSnapshotOrDie(t, ctx.ClusterSnapshot, []*apiv1.Node{n1}, []*apiv1.Pod{p1, p2})
+			nodeInfo, err := ctx.ClusterSnapshot.NodeInfos().Get(n1.Name)
+			assert.NoError(t, err)
+
+			evictionResults, err := evictor.DrainNodeWithTimeout(&ctx, nodeInfo, 100*time.Millisecond, forceDelete)
+			assert.True(t, evictionResults["p1"].WasEvictionSuccessful())
+			if !forceDelete {
+				assert.Error(t, err)
+				assert.False(t, evictionResults["p2"].WasEvictionSuccessful())
+				assert.Empty(t, deletedPods)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, evictionResults["p2"].WasEvictionSuccessful())
+			assert.Equal(t, []string{"p2"}, deletedPods)
+			assert.Equal(t, int64(0), *deleteOptions[0].GracePeriodSeconds)
+		})
+	}
+}
+
 func TestDrainWithPodsNodeDisappearanceFailure(t *testing.T) {
 	fakeClient := &fake.Clientset{}
 
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actuation
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/klog/v2"
+)
+
+// DryRunActuator records the scale-down decisions instead of executing them.
+// Nodes passed to StartDeletion are neither tainted, drained nor deleted.
+// Each node is reported once until it stops being a scale-down candidate.
+type DryRunActuator struct {
+	scaledown.Actuator
+	ctx *context.AutoscalingContext
+	// reported holds the names of the nodes already reported as removable.
+	reported map[string]bool
+}
+
+// NewDryRunActuator returns a DryRunActuator wrapping the given actuator,
+// which is only used to report the (always empty) deletion status.
+func NewDryRunActuator(ctx *context.AutoscalingContext, actuator scaledown.Actuator) *DryRunActuator {
+	return &DryRunActuator{
+		Actuator: actuator,
+		ctx:      ctx,
+		reported: make(map[string]bool),
+	}
+}
+
+// StartDeletion emits events for the nodes that would be removed and reports
+// that no node deletion was started. Nodes reported by an earlier call are
+// skipped, nodes no longer passed are forgotten.
+func (a *DryRunActuator) StartDeletion(empty, needDrain []*apiv1.Node) (status.ScaleDownResult, []*status.ScaleDownNode, errors.AutoscalerError) {
+	candidates := make(map[string]bool, len(empty)+len(needDrain))
+	newEmpty, newNeedDrain := 0, 0
+	for _, node := range empty {
+		candidates[node.Name] = true
+		if a.reported[node.Name] {
+			continue
+		}
+		newEmpty++
+		klog.V(0).Infof("Dry run: scale-down would remove empty node %s", node.Name)
+		a.ctx.Recorder.Eventf(node, apiv1.EventTypeNormal, "ScaleDownDryRun", "dry run: node would be removed as empty")
+	}
+	for _, node := range needDrain {
+		candidates[node.Name] = true
+		if a.reported[node.Name] {
+			continue
+		}
+		newNeedDrain++
+		klog.V(0).Infof("Dry run: scale-down would drain and remove node %s", node.Name)
+		a.ctx.Recorder.Eventf(node, apiv1.EventTypeNormal, "ScaleDownDryRun", "dry run: node would be drained and removed")
+	}
+	a.reported = candidates
+	if len(candidates) == 0 {
+		return status.ScaleDownNoUnneeded, nil, nil
+	}
+	if newEmpty+newNeedDrain > 0 {
+		a.ctx.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleDownDryRun",
+			"Dry run: scale-down would remove %d empty and %d non-empty nodes", newEmpty, newNeedDrain)
+		metrics.RegisterDryRunScaleDown(newEmpty + newNeedDrain)
+	}
+	return status.ScaleDownNoNodeDeleted, nil, nil
+}
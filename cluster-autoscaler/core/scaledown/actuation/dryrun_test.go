@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package actuation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+)
+
+func TestDryRunActuatorStartDeletion(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	SetNodeReadyState(n1, true, time.Time{})
+	n2 := BuildTestNode("n2", 1000, 1000)
+	SetNodeReadyState(n2, true, time.Time{})
+	fakeClient := fake.NewSimpleClientset(n1, n2)
+
+	autoscalingCtx, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{DryRun: true}, fakeClient, nil, nil, nil, nil)
+	assert.NoError(t, err)
+	actuator := NewDryRunActuator(&autoscalingCtx, nil)
+
+	result, scaledDownNodes, err := actuator.StartDeletion(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, status.ScaleDownNoUnneeded, result)
+	assert.Empty(t, scaledDownNodes)
+
+	result, scaledDownNodes, err = actuator.StartDeletion([]*apiv1.Node{n1}, []*apiv1.Node{n2})
+	assert.NoError(t, err)
+	assert.Equal(t, status.ScaleDownNoNodeDeleted, result)
+	assert.Empty(t, scaledDownNodes)
+
+	events := autoscalingCtx.Recorder.(*kube_record.FakeRecorder).Events
+	assert.Equal(t, "Normal ScaleDownDryRun dry run: node would be removed as empty", <-events)
+	assert.Equal(t, "Normal ScaleDownDryRun dry run: node would be drained and removed", <-events)
+
+	// Nodes already reported aren't reported again while they stay candidates.
+	_, _, err = actuator.StartDeletion([]*apiv1.Node{n1}, []*apiv1.Node{n2})
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// A node is reported again once it left the candidate set and came back.
+	_, _, err = actuator.StartDeletion([]*apiv1.Node{n1}, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	_, _, err = actuator.StartDeletion([]*apiv1.Node{n1}, []*apiv1.Node{n2})
+	assert.NoError(t, err)
+	assert.Equal(t, "Normal ScaleDownDryRun dry run: node would be drained and removed", <-events)
+	assert.Empty(t, events)
+
+	for _, name := range []string{"n1", "n2"} {
+		node, err := fakeClient.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.False(t, taints.HasToBeDeletedTaint(node), "node %s was tainted in dry-run mode", name)
+	}
+}
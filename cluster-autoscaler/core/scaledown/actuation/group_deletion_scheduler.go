@@ -84,10 +84,11 @@ func (ds *GroupDeletionScheduler) ScheduleDeletion(nodeInfo *framework.NodeInfo,
 		return
 	}
 	if opts == nil {
-		opts = &config.NodeGroupAutoscalingOptions{}
+		defaults := ds.ctx.NodeGroupDefaults
+		opts = &defaults
 	}
 
-	nodeDeleteResult := ds.prepareNodeForDeletion(nodeInfo, drain)
+	nodeDeleteResult := ds.prepareNodeForDeletion(nodeInfo, drain, opts)
 	if nodeDeleteResult.Err != nil {
 		ds.AbortNodeDeletion(nodeInfo.Node(), nodeGroup.Id(), drain, "prepareNodeForDeletion failed", nodeDeleteResult)
 		return
@@ -97,10 +98,11 @@ func (ds *GroupDeletionScheduler) ScheduleDeletion(nodeInfo *framework.NodeInfo,
 }
 
 // prepareNodeForDeletion is a long-running operation, so it needs to avoid locking the AtomicDeletionScheduler object
-func (ds *GroupDeletionScheduler) prepareNodeForDeletion(nodeInfo *framework.NodeInfo, drain bool) status.NodeDeleteResult {
+func (ds *GroupDeletionScheduler) prepareNodeForDeletion(nodeInfo *framework.NodeInfo, drain bool, opts *config.NodeGroupAutoscalingOptions) status.NodeDeleteResult {
 	node := nodeInfo.Node()
 	if drain {
-		if evictionResults, err := ds.evictor.DrainNode(ds.ctx, nodeInfo); err != nil {
+		forceDelete := opts.NodeDrainTimeoutPolicy == config.DrainTimeoutPolicyForceDelete
+		if evictionResults, err := ds.evictor.DrainNodeWithTimeout(ds.ctx, nodeInfo, opts.MaxNodeDrainTime, forceDelete); err != nil {
 			return status.NodeDeleteResult{ResultType: status.NodeDeleteErrorFailedToEvictPods, Err: err, PodEvictionResults: evictionResults}
 		}
 	} else {
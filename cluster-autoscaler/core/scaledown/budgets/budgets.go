@@ -38,13 +38,22 @@ type NodeGroupView struct {
 
 // ScaleDownBudgetProcessor is responsible for keeping the number of nodes deleted in parallel within defined limits.
 type ScaleDownBudgetProcessor struct {
-	ctx *context.AutoscalingContext
+	ctx          *context.AutoscalingContext
+	configGetter budgetsNodeGroupConfigGetter
+}
+
+// budgetsNodeGroupConfigGetter is an interface to limit the functions that can be used
+// from NodeGroupConfigProcessor interface
+type budgetsNodeGroupConfigGetter interface {
+	// GetMaxScaleDownParallelism returns MaxScaleDownParallelism value that should be used for a given NodeGroup.
+	GetMaxScaleDownParallelism(nodeGroup cloudprovider.NodeGroup) (int, error)
 }
 
 // NewScaleDownBudgetProcessor creates a ScaleDownBudgetProcessor instance.
-func NewScaleDownBudgetProcessor(ctx *context.AutoscalingContext) *ScaleDownBudgetProcessor {
+func NewScaleDownBudgetProcessor(ctx *context.AutoscalingContext, configGetter budgetsNodeGroupConfigGetter) *ScaleDownBudgetProcessor {
 	return &ScaleDownBudgetProcessor{
-		ctx: ctx,
+		ctx:          ctx,
+		configGetter: configGetter,
 	}
 }
 
@@ -138,6 +147,7 @@ func (bp *ScaleDownBudgetProcessor) CropNodes(as scaledown.ActuationStatus, empt
 		canOverflow = false
 	}
 
+	emptyIndividual, drainIndividual = bp.cropToNodeGroupParallelism(as, emptyIndividual, drainIndividual)
 	emptyToDelete, allowedCount := cropIndividualNodes(emptyToDelete, emptyIndividual, parallelismBudget)
 	parallelismBudget -= allowedCount
 	drainBudget = min(parallelismBudget, drainBudget)
@@ -173,6 +183,48 @@ func cropIndividualNodes(toDelete []*NodeGroupView, groups []*NodeGroupView, bud
 	return toDelete, budget - remainingBudget
 }
 
+// cropToNodeGroupParallelism crops the nodes of each node group, so that together
+// with deletions in progress they don't exceed MaxScaleDownParallelism of the node
+// group, if it's set. Empty nodes take precedence over nodes needing drain. Node
+// groups cropped to no nodes at all are dropped.
+func (bp *ScaleDownBudgetProcessor) cropToNodeGroupParallelism(as scaledown.ActuationStatus, empty, drain []*NodeGroupView) (emptyCropped, drainCropped []*NodeGroupView) {
+	groupBudgets := map[string]int{}
+	crop := func(buckets []*NodeGroupView) []*NodeGroupView {
+		cropped := []*NodeGroupView{}
+		for _, bucket := range buckets {
+			budget, found := groupBudgets[bucket.Group.Id()]
+			if !found {
+				maxParallelism := bp.maxScaleDownParallelism(bucket.Group)
+				if maxParallelism <= 0 {
+					cropped = append(cropped, bucket)
+					continue
+				}
+				budget = max(maxParallelism-as.DeletionsCount(bucket.Group.Id()), 0)
+			}
+			if budget < len(bucket.Nodes) {
+				klog.V(4).Infof("Limiting scale-down of node group %s to %d nodes due to max scale-down parallelism", bucket.Group.Id(), budget)
+				bucket.Nodes = bucket.Nodes[:budget]
+			}
+			groupBudgets[bucket.Group.Id()] = budget - len(bucket.Nodes)
+			if len(bucket.Nodes) > 0 {
+				cropped = append(cropped, bucket)
+			}
+		}
+		return cropped
+	}
+	return crop(empty), crop(drain)
+}
+
+// maxScaleDownParallelism returns MaxScaleDownParallelism of the node group, 0 if it's not limited.
+func (bp *ScaleDownBudgetProcessor) maxScaleDownParallelism(nodeGroup cloudprovider.NodeGroup) int {
+	maxParallelism, err := bp.configGetter.GetMaxScaleDownParallelism(nodeGroup)
+	if err != nil {
+		klog.Errorf("Failed to get max scale-down parallelism for node group %s: %v", nodeGroup.Id(), err)
+		return bp.ctx.NodeGroupDefaults.MaxScaleDownParallelism
+	}
+	return maxParallelism
+}
+
 func (bp *ScaleDownBudgetProcessor) group(nodes []*apiv1.Node) []*NodeGroupView {
 	groupMap := map[string]int{}
 	grouped := []*NodeGroupView{}
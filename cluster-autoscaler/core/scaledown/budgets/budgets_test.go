@@ -32,6 +32,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/deletiontracker"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 )
 
 func TestCropNodesToBudgets(t *testing.T) {
@@ -455,7 +456,7 @@ func TestCropNodesToBudgets(t *testing.T) {
 				drainList = append(drainList, bucket.Nodes...)
 			}
 
-			budgeter := NewScaleDownBudgetProcessor(ctx)
+			budgeter := NewScaleDownBudgetProcessor(ctx, nodegroupconfig.NewDefaultNodeGroupConfigProcessor(ctx.NodeGroupDefaults))
 			gotEmpty, gotDrain := budgeter.CropNodes(ndt, emptyList, drainList)
 			if diff := cmp.Diff(tc.wantEmpty, gotEmpty, cmpopts.EquateEmpty(), transformNodeGroupView); diff != "" {
 				t.Errorf("cropNodesToBudgets empty nodes diff (-want +got):\n%s", diff)
@@ -467,6 +468,60 @@ func TestCropNodesToBudgets(t *testing.T) {
 	}
 }
 
+func TestCropNodesToNodeGroupParallelism(t *testing.T) {
+	limited := testprovider.NewTestNodeGroup("limited", 0, 100, 10, true, false, "n1-standard-2", nil, nil)
+	limited.SetOptions(&config.NodeGroupAutoscalingOptions{MaxScaleDownParallelism: 3})
+	unlimited := testprovider.NewTestNodeGroup("unlimited", 0, 100, 10, true, false, "n1-standard-2", nil, nil)
+	unlimited.SetOptions(&config.NodeGroupAutoscalingOptions{})
+	defaulted := testprovider.NewTestNodeGroup("defaulted", 0, 100, 10, true, false, "n1-standard-2", nil, nil)
+
+	empty := append(generateNodeGroupViewList(limited, 0, 1), generateNodeGroupViewList(unlimited, 0, 2)...)
+	drain := append(append(
+		generateNodeGroupViewList(limited, 1, 5),
+		generateNodeGroupViewList(unlimited, 2, 5)...),
+		generateNodeGroupViewList(defaulted, 0, 3)...)
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	for _, bucket := range append(empty, drain...) {
+		bucket.Group.(*testprovider.TestNodeGroup).SetCloudProvider(provider)
+		provider.InsertNodeGroup(bucket.Group)
+		for _, node := range bucket.Nodes {
+			provider.AddNode(bucket.Group.Id(), node)
+		}
+	}
+	ctx := &context.AutoscalingContext{
+		AutoscalingOptions: config.AutoscalingOptions{
+			NodeGroupDefaults:       config.NodeGroupAutoscalingOptions{MaxScaleDownParallelism: 2},
+			MaxScaleDownParallelism: 100,
+			MaxDrainParallelism:     100,
+		},
+		CloudProvider: provider,
+	}
+	ndt := deletiontracker.NewNodeDeletionTracker(1 * time.Hour)
+	ndt.StartDeletionWithDrain("limited", "limited-in-progress")
+
+	emptyList, drainList := []*apiv1.Node{}, []*apiv1.Node{}
+	for _, bucket := range empty {
+		emptyList = append(emptyList, bucket.Nodes...)
+	}
+	for _, bucket := range drain {
+		drainList = append(drainList, bucket.Nodes...)
+	}
+	gotEmpty, gotDrain := NewScaleDownBudgetProcessor(ctx, nodegroupconfig.NewDefaultNodeGroupConfigProcessor(ctx.NodeGroupDefaults)).CropNodes(ndt, emptyList, drainList)
+
+	wantEmpty := append(generateNodeGroupViewList(limited, 0, 1), generateNodeGroupViewList(unlimited, 0, 2)...)
+	wantDrain := append(append(
+		generateNodeGroupViewList(limited, 1, 2),
+		generateNodeGroupViewList(unlimited, 2, 5)...),
+		generateNodeGroupViewList(defaulted, 0, 2)...)
+	if diff := cmp.Diff(wantEmpty, gotEmpty, cmpopts.EquateEmpty(), transformNodeGroupView); diff != "" {
+		t.Errorf("CropNodes empty nodes diff (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(wantDrain, gotDrain, cmpopts.EquateEmpty(), transformNodeGroupView); diff != "" {
+		t.Errorf("CropNodes drain nodes diff (-want +got):\n%s", diff)
+	}
+}
+
 // transformNodeGroupView transforms a NodeGroupView to a structure that can be directly compared with other node bucket.
 var transformNodeGroupView = cmp.Transformer("transformNodeGroupView", func(b NodeGroupView) interface{} {
 	return struct {
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planner
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	klog "k8s.io/klog/v2"
+)
+
+// antiAffinitySet identifies replicas of a controller spread over nodes by
+// required pod anti-affinity on hostname.
+type antiAffinitySet struct {
+	namespace string
+	owner     types.UID
+}
+
+// antiAffinityGroups returns groups of the given nodes hosting replicas of the
+// same anti-affinity set. Nodes hosting replicas of several sets end up in a
+// single group. Only groups of at least two nodes are returned, in the order
+// of their first node in nodeNames.
+func antiAffinityGroups(snapshot clustersnapshot.ClusterSnapshot, nodeNames []string) [][]string {
+	parent := make(map[string]string, len(nodeNames))
+	var find func(node string) string
+	find = func(node string) string {
+		if parent[node] != node {
+			parent[node] = find(parent[node])
+		}
+		return parent[node]
+	}
+
+	firstNodeOfSet := make(map[antiAffinitySet]string)
+	for _, nodeName := range nodeNames {
+		parent[nodeName] = nodeName
+		nodeInfo, err := snapshot.NodeInfos().Get(nodeName)
+		if err != nil {
+			klog.Errorf("Can't retrieve node %s from snapshot, err: %v", nodeName, err)
+			continue
+		}
+		for _, podInfo := range nodeInfo.Pods {
+			set, found := podAntiAffinitySet(podInfo.Pod)
+			if !found {
+				continue
+			}
+			if first, found := firstNodeOfSet[set]; found {
+				parent[find(nodeName)] = find(first)
+			} else {
+				firstNodeOfSet[set] = nodeName
+			}
+		}
+	}
+
+	members := make(map[string][]string)
+	var roots []string
+	for _, nodeName := range nodeNames {
+		root := find(nodeName)
+		if _, found := members[root]; !found {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], nodeName)
+	}
+	var groups [][]string
+	for _, root := range roots {
+		if len(members[root]) > 1 {
+			groups = append(groups, members[root])
+		}
+	}
+	return groups
+}
+
+// podAntiAffinitySet returns the anti-affinity set of a controlled pod whose
+// required anti-affinity on hostname selects its own replicas.
+func podAntiAffinitySet(pod *apiv1.Pod) (antiAffinitySet, bool) {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		return antiAffinitySet{}, false
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return antiAffinitySet{}, false
+	}
+	for _, term := range pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term.TopologyKey != apiv1.LabelHostname || term.LabelSelector == nil {
+			continue
+		}
+		if len(term.Namespaces) > 0 || term.NamespaceSelector != nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return antiAffinitySet{namespace: pod.Namespace, owner: owner.UID}, true
+		}
+	}
+	return antiAffinitySet{}, false
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestAntiAffinityGroups(t *testing.T) {
+	spread := func(name, nodeName, app string, topologyKey string) *apiv1.Pod {
+		pod := BuildTestPod(name, 100, 100)
+		pod.Spec.NodeName = nodeName
+		pod.Labels = map[string]string{"app": app}
+		pod.OwnerReferences = GenerateOwnerReferences(app, "ReplicaSet", "apps/v1", types.UID(app))
+		pod.Spec.Affinity = &apiv1.Affinity{
+			PodAntiAffinity: &apiv1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+					TopologyKey:   topologyKey,
+				}},
+			},
+		}
+		return pod
+	}
+	uncontrolled := spread("uncontrolled", "n5", "a", apiv1.LabelHostname)
+	uncontrolled.OwnerReferences = nil
+	otherSelector := spread("other-selector", "n6", "a", apiv1.LabelHostname)
+	otherSelector.Labels = map[string]string{"app": "other"}
+
+	testCases := []struct {
+		name      string
+		pods      []*apiv1.Pod
+		nodeNames []string
+		want      [][]string
+	}{
+		{
+			name: "replicas of one set",
+			pods: []*apiv1.Pod{
+				spread("a1", "n1", "a", apiv1.LabelHostname),
+				spread("a2", "n2", "a", apiv1.LabelHostname),
+				spread("a3", "n3", "a", apiv1.LabelHostname),
+			},
+			nodeNames: []string{"n3", "n1", "n2", "n4"},
+			want:      [][]string{{"n3", "n1", "n2"}},
+		},
+		{
+			name: "replicas of two sets",
+			pods: []*apiv1.Pod{
+				spread("a1", "n1", "a", apiv1.LabelHostname),
+				spread("a2", "n2", "a", apiv1.LabelHostname),
+				spread("b1", "n3", "b", apiv1.LabelHostname),
+				spread("b2", "n4", "b", apiv1.LabelHostname),
+			},
+			nodeNames: []string{"n1", "n2", "n3", "n4"},
+			want:      [][]string{{"n1", "n2"}, {"n3", "n4"}},
+		},
+		{
+			name: "sets sharing a node are merged",
+			pods: []*apiv1.Pod{
+				spread("a1", "n1", "a", apiv1.LabelHostname),
+				spread("a2", "n2", "a", apiv1.LabelHostname),
+				spread("b1", "n2", "b", apiv1.LabelHostname),
+				spread("b2", "n3", "b", apiv1.LabelHostname),
+			},
+			nodeNames: []string{"n1", "n2", "n3"},
+			want:      [][]string{{"n1", "n2", "n3"}},
+		},
+		{
+			name: "replicas on nodes which are not candidates",
+			pods: []*apiv1.Pod{
+				spread("a1", "n1", "a", apiv1.LabelHostname),
+				spread("a2", "n2", "a", apiv1.LabelHostname),
+			},
+			nodeNames: []string{"n1", "n3"},
+		},
+		{
+			name: "anti-affinity on other topology, uncontrolled pods and not matching selectors are ignored",
+			pods: []*apiv1.Pod{
+				spread("a1", "n1", "a", apiv1.LabelTopologyZone),
+				spread("a2", "n2", "a", apiv1.LabelTopologyZone),
+				uncontrolled,
+				otherSelector,
+			},
+			nodeNames: []string{"n1", "n2", "n5", "n6"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var nodes []*apiv1.Node
+			for _, name := range []string{"n1", "n2", "n3", "n4", "n5", "n6"} {
+				nodes = append(nodes, BuildTestNode(name, 1000, 1000))
+			}
+			snapshot := clustersnapshot.NewBasicClusterSnapshot()
+			clustersnapshot.InitializeClusterSnapshotOrDie(t, snapshot, nodes, tc.pods)
+			assert.Equal(t, tc.want, antiAffinityGroups(snapshot, tc.nodeNames))
+		})
+	}
+}
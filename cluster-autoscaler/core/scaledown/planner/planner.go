@@ -51,6 +51,7 @@ type eligibilityChecker interface {
 type removalSimulator interface {
 	DropOldHints()
 	SimulateNodeRemoval(node string, podDestinations map[string]bool, timestamp time.Time, remainingPdbTracker pdb.RemainingPdbTracker) (*simulator.NodeToBeRemoved, *simulator.UnremovableNode)
+	SimulateNodesRemoval(nodes []string, podDestinations map[string]bool, timestamp time.Time, remainingPdbTracker pdb.RemainingPdbTracker) ([]simulator.NodeToBeRemoved, *simulator.UnremovableNode)
 }
 
 // controllerReplicasCalculator calculates a number of target and expected replicas for a given controller.
@@ -271,7 +272,45 @@ func (p *Planner) categorizeNodes(podDestinations map[string]bool, scaleDownCand
 	p.nodeUtilizationMap = utilizationMap
 	timer := time.NewTimer(p.context.ScaleDownSimulationTimeout)
 
+	addRemovable := func(removable simulator.NodeToBeRemoved) {
+		_, inParallel, _ := p.context.RemainingPdbTracker.CanRemovePods(removable.PodsToReschedule)
+		if !inParallel {
+			removable.IsRisky = true
+		}
+		delete(podDestinations, removable.Node.Name)
+		p.context.RemainingPdbTracker.RemovePods(removable.PodsToReschedule)
+		removableList = append(removableList, removable)
+		if p.atomicScaleDownNode(&removable) {
+			atomicScaleDownNodesCount++
+			klog.V(2).Infof("Considering node %s for atomic scale down. Total atomic scale down nodes count: %d", removable.Node.Name, atomicScaleDownNodesCount)
+		}
+	}
+
+	// Nodes hosting replicas spread by pod anti-affinity are first evaluated as
+	// groups, so that all replicas are repacked jointly. Groups which can't be
+	// removed as a whole are evaluated node by node below.
+	removedInGroup := make(map[string]bool)
+	if p.context.AutoscalingOptions.ScaleDownAntiAffinityGroupsEnabled {
+		for _, group := range antiAffinityGroups(p.context.ClusterSnapshot, currentlyUnneededNodeNames) {
+			if timedOut(timer) {
+				klog.Warningf("Scale down simulation of pod anti-affinity node groups skipped due to timeout.")
+				break
+			}
+			if len(removableList)-atomicScaleDownNodesCount+len(group) > p.unneededNodesLimit() {
+				continue
+			}
+			removable, _ := p.rs.SimulateNodesRemoval(group, podDestinations, p.latestUpdate, p.context.RemainingPdbTracker)
+			for _, r := range removable {
+				removedInGroup[r.Node.Name] = true
+				addRemovable(r)
+			}
+		}
+	}
+
 	for i, node := range currentlyUnneededNodeNames {
+		if removedInGroup[node] {
+			continue
+		}
 		if timedOut(timer) {
 			klog.Warningf("%d out of %d nodes skipped in scale down simulation due to timeout.", len(currentlyUnneededNodeNames)-i, len(currentlyUnneededNodeNames))
 			break
@@ -282,17 +321,7 @@ func (p *Planner) categorizeNodes(podDestinations map[string]bool, scaleDownCand
 		}
 		removable, unremovable := p.rs.SimulateNodeRemoval(node, podDestinations, p.latestUpdate, p.context.RemainingPdbTracker)
 		if removable != nil {
-			_, inParallel, _ := p.context.RemainingPdbTracker.CanRemovePods(removable.PodsToReschedule)
-			if !inParallel {
-				removable.IsRisky = true
-			}
-			delete(podDestinations, removable.Node.Name)
-			p.context.RemainingPdbTracker.RemovePods(removable.PodsToReschedule)
-			removableList = append(removableList, *removable)
-			if p.atomicScaleDownNode(removable) {
-				atomicScaleDownNodesCount++
-				klog.V(2).Infof("Considering node %s for atomic scale down. Total atomic scale down nodes count: %d", removable.Node.Name, atomicScaleDownNodesCount)
-			}
+			addRemovable(*removable)
 		}
 		if unremovable != nil {
 			unremovableCount += 1
@@ -993,3 +993,12 @@ func (r *fakeRemovalSimulator) SimulateNodeRemoval(name string, _ map[string]boo
 	}
 	return &simulator.NodeToBeRemoved{Node: node}, nil
 }
+
+func (r *fakeRemovalSimulator) SimulateNodesRemoval(names []string, destinations map[string]bool, timestamp time.Time, tracker pdb.RemainingPdbTracker) ([]simulator.NodeToBeRemoved, *simulator.UnremovableNode) {
+	var removable []simulator.NodeToBeRemoved
+	for _, name := range names {
+		r, _ := r.SimulateNodeRemoval(name, destinations, timestamp, tracker)
+		removable = append(removable, *r)
+	}
+	return removable, nil
+}
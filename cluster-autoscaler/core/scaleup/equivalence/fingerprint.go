@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package equivalence
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	pod_utils "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+// Fingerprint returns an identifier of the equivalence group of the pod, which,
+// unlike group IDs, is stable across autoscaler restarts. Pods which aren't
+// grouped with other pods, i.e. without a controller or run by a DaemonSet,
+// don't have a fingerprint.
+func Fingerprint(pod *apiv1.Pod) (string, bool) {
+	controllerRef := drain.ControllerRef(pod)
+	if controllerRef == nil || pod_utils.IsDaemonSetPod(pod) {
+		return "", false
+	}
+	content, err := json.Marshal(struct {
+		Controller types.UID
+		Labels     map[string]string
+		Spec       apiv1.PodSpec
+	}{controllerRef.UID, pod.Labels, utils.SanitizePodSpec(pod.Spec)})
+	if err != nil {
+		return "", false
+	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:16]), true
+}
@@ -184,3 +184,24 @@ func TestEquivalenceGroupIgnoresDaemonSets(t *testing.T) {
 	podGroups := groupPodsBySchedulingProperties(pods)
 	assert.Equal(t, 2, len(podGroups))
 }
+
+func TestFingerprint(t *testing.T) {
+	rsPod := func(name, rsName string) *apiv1.Pod {
+		pod := SetRSPodSpec(BuildTestPod(name, 100, 1), rsName)
+		pod.Spec.Volumes = []apiv1.Volume{{Name: "kube-api-access-" + name, VolumeSource: apiv1.VolumeSource{Projected: BuildServiceTokenProjectedVolumeSource("path")}}}
+		return pod
+	}
+	p1, found := Fingerprint(rsPod("p1", "rs1"))
+	assert.True(t, found)
+	p2, found := Fingerprint(rsPod("p2", "rs1"))
+	assert.True(t, found)
+	p3, found := Fingerprint(rsPod("p3", "rs2"))
+	assert.True(t, found)
+	assert.Equal(t, p1, p2)
+	assert.NotEqual(t, p1, p3)
+
+	_, found = Fingerprint(BuildTestPod("standalone", 100, 1))
+	assert.False(t, found)
+	_, found = Fingerprint(SetDSPodSpec(BuildTestPod("ds", 100, 1)))
+	assert.False(t, found)
+}
@@ -159,6 +159,13 @@ func (e *scaleUpExecutor) executeScaleUp(
 ) errors.AutoscalerError {
 	gpuConfig := e.autoscalingContext.CloudProvider.GetNodeGpuConfig(nodeInfo.Node())
 	gpuResourceName, gpuType := gpu.GetGpuInfoForMetrics(gpuConfig, availableGPUTypes, nodeInfo.Node(), nil)
+	if e.autoscalingContext.DryRun {
+		klog.V(0).Infof("Dry run: scale-up would set group %s size to %d", info.Group.Id(), info.NewSize)
+		e.autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleUpDryRun",
+			"Dry run: scale-up would set group %s size to %d instead of %d (max: %d)", info.Group.Id(), info.NewSize, info.CurrentSize, info.MaxSize)
+		metrics.RegisterDryRunScaleUp(info.NewSize - info.CurrentSize)
+		return nil
+	}
 	klog.V(0).Infof("Scale-up: setting group %s size to %d", info.Group.Id(), info.NewSize)
 	e.autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaledUpGroup",
 		"Scale-up: setting group %s size to %d instead of %d (max: %d)", info.Group.Id(), info.NewSize, info.CurrentSize, info.MaxSize)
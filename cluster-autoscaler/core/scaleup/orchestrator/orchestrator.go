@@ -35,6 +35,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/cost"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroups"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
@@ -89,6 +90,30 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 	daemonSets []*appsv1.DaemonSet,
 	nodeInfos map[string]*schedulerframework.NodeInfo,
 	allOrNothing bool, // Either request enough capacity for all unschedulablePods, or don't request it at all.
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	return o.scaleUp(unschedulablePods, nodes, daemonSets, nodeInfos, allOrNothing, false)
+}
+
+// SimulateScaleUp computes the scale-up ScaleUp would execute for the pods, the
+// same way, without creating node groups or increasing their sizes. The returned
+// status is successful if a scale-up would be executed, with the planned sizes
+// of node groups in ScaleUpInfos.
+func (o *ScaleUpOrchestrator) SimulateScaleUp(
+	unschedulablePods []*apiv1.Pod,
+	nodes []*apiv1.Node,
+	daemonSets []*appsv1.DaemonSet,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	return o.scaleUp(unschedulablePods, nodes, daemonSets, nodeInfos, false, true)
+}
+
+func (o *ScaleUpOrchestrator) scaleUp(
+	unschedulablePods []*apiv1.Pod,
+	nodes []*apiv1.Node,
+	daemonSets []*appsv1.DaemonSet,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+	allOrNothing bool,
+	simulate bool,
 ) (*status.ScaleUpStatus, errors.AutoscalerError) {
 	if !o.initialized {
 		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, errors.NewAutoscalerError(errors.InternalError, "ScaleUpOrchestrator is not initialized"))
@@ -129,8 +154,13 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 
 	now := time.Now()
 
+	costLeft, aErr := o.hourlyCostLeft(nodes, upcomingNodes, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr.AddPrefix("could not compute hourly cost: "))
+	}
+
 	// Filter out invalid node groups
-	validNodeGroups, skippedNodeGroups := o.filterValidScaleUpNodeGroups(nodeGroups, nodeInfos, resourcesLeft, len(nodes)+len(upcomingNodes), now)
+	validNodeGroups, skippedNodeGroups := o.filterValidScaleUpNodeGroups(nodeGroups, nodeInfos, resourcesLeft, costLeft, len(nodes)+len(upcomingNodes), now)
 
 	// Mark skipped node groups as processed.
 	for nodegroupID := range skippedNodeGroups {
@@ -212,6 +242,12 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 			&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods},
 			aErr)
 	}
+	newNodes, aErr = o.GetCostCappedNewNodeCount(costLeft, newNodes, nodeInfo, bestOption.NodeGroup, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(
+			&status.ScaleUpStatus{PodsTriggeredScaleUp: bestOption.Pods},
+			aErr)
+	}
 
 	if newNodes < bestOption.NodeCount {
 		klog.V(1).Infof("Only %d nodes can be added to %s due to cluster-wide limits", newNodes, bestOption.NodeGroup.Id())
@@ -223,6 +259,18 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 		}
 	}
 
+	if simulate && !bestOption.NodeGroup.Exist() {
+		if newNodes > bestOption.NodeGroup.MaxSize() {
+			newNodes = bestOption.NodeGroup.MaxSize()
+		}
+		return simulatedScaleUpStatus(podEquivalenceGroups, skippedNodeGroups, nodeGroups, bestOption, []nodegroupset.ScaleUpInfo{{
+			Group:       bestOption.NodeGroup,
+			CurrentSize: 0,
+			NewSize:     newNodes,
+			MaxSize:     bestOption.NodeGroup.MaxSize(),
+		}}), nil
+	}
+
 	// If necessary, create the node group. This is no longer simulation, an empty node group will be created by cloud provider if supported.
 	createNodeGroupResults := make([]nodegroups.CreateNodeGroupResult, 0)
 	if !bestOption.NodeGroup.Exist() {
@@ -275,6 +323,17 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 			aErr)
 	}
 
+	scaleUpInfos = o.capToProvisioningHeadroom(scaleUpInfos)
+
+	// Similar node groups may provide different resources per node, so limits
+	// are enforced again for the final scale-up of each group.
+	scaleUpInfos, aErr = o.applyLimitsToScaleUpInfos(scaleUpInfos, nodeInfos, resource.LimitsWithNodes(resourcesLeft, o.autoscalingContext.MaxNodesTotal, len(nodes)+len(upcomingNodes)), costLeft, now)
+	if aErr != nil {
+		return status.UpdateScaleUpError(
+			&status.ScaleUpStatus{CreateNodeGroupResults: createNodeGroupResults, PodsTriggeredScaleUp: bestOption.Pods},
+			aErr)
+	}
+
 	// Last check before scale-up. Node group capacity (both due to max size limits & current size) is only checked when balancing.
 	totalCapacity := 0
 	for _, sui := range scaleUpInfos {
@@ -290,6 +349,10 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 		}
 	}
 
+	if simulate {
+		return simulatedScaleUpStatus(podEquivalenceGroups, skippedNodeGroups, nodeGroups, bestOption, scaleUpInfos), nil
+	}
+
 	// Execute scale up.
 	klog.V(1).Infof("Final scale-up plan: %v", scaleUpInfos)
 	aErr, failedNodeGroups := o.scaleUpExecutor.ExecuteScaleUps(scaleUpInfos, nodeInfos, now, allOrNothing)
@@ -316,6 +379,29 @@ func (o *ScaleUpOrchestrator) ScaleUp(
 	}, nil
 }
 
+// simulatedScaleUpStatus returns the status of a simulated scale-up, successful
+// if any node would be added.
+func simulatedScaleUpStatus(egs []*equivalence.PodGroup, skipped map[string]status.Reasons, ngs []cloudprovider.NodeGroup, bestOption *expander.Option, scaleUpInfos []nodegroupset.ScaleUpInfo) *status.ScaleUpStatus {
+	var planned []nodegroupset.ScaleUpInfo
+	for _, info := range scaleUpInfos {
+		if info.NewSize > info.CurrentSize {
+			planned = append(planned, info)
+		}
+	}
+	if len(planned) == 0 {
+		klog.V(1).Info("Simulated scale-up wouldn't add any node")
+		return buildNoOptionsAvailableStatus(egs, skipped, ngs)
+	}
+	klog.V(4).Infof("Simulated scale-up plan: %v", planned)
+	return &status.ScaleUpStatus{
+		Result:                  status.ScaleUpSuccessful,
+		ScaleUpInfos:            planned,
+		PodsRemainUnschedulable: GetRemainingPods(egs, skipped),
+		ConsideredNodeGroups:    ngs,
+		PodsTriggeredScaleUp:    bestOption.Pods,
+	}
+}
+
 // ScaleUpToNodeGroupMinSize tries to scale up node groups that have less nodes
 // than the configured min size. The source of truth for the current node group
 // size is the TargetSize queried directly from cloud providers. Returns
@@ -332,10 +418,18 @@ func (o *ScaleUpOrchestrator) ScaleUpToNodeGroupMinSize(
 	nodeGroups := o.autoscalingContext.CloudProvider.NodeGroups()
 	scaleUpInfos := make([]nodegroupset.ScaleUpInfo, 0)
 
+	upcomingNodes, aErr := o.UpcomingNodes(nodeInfos)
+	if aErr != nil {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr.AddPrefix("could not get upcoming nodes: "))
+	}
+
 	resourcesLeft, aErr := o.resourceManager.ResourcesLeft(o.autoscalingContext, nodeInfos, nodes)
 	if aErr != nil {
 		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, aErr.AddPrefix("could not compute total resources: "))
 	}
+	// Limits are shared by all node groups, so that scale-ups of multiple
+	// groups can't exceed them together.
+	limitsLeft := resource.LimitsWithNodes(resourcesLeft, o.autoscalingContext.MaxNodesTotal, len(nodes)+len(upcomingNodes))
 
 	for _, ng := range nodeGroups {
 		if !ng.Exist() {
@@ -365,21 +459,22 @@ func (o *ScaleUpOrchestrator) ScaleUpToNodeGroupMinSize(
 			continue
 		}
 
-		if skipReason := o.IsNodeGroupResourceExceeded(resourcesLeft, ng, nodeInfo, 1); skipReason != nil {
+		if skipReason := o.IsNodeGroupResourceExceeded(limitsLeft, ng, nodeInfo, 1); skipReason != nil {
 			klog.Warningf("ScaleUpToNodeGroupMinSize: node group resource excceded: %v", skipReason)
 			continue
 		}
 
-		newNodeCount := ng.MinSize() - targetSize
-		newNodeCount, err = o.resourceManager.ApplyLimits(o.autoscalingContext, newNodeCount, resourcesLeft, nodeInfo, ng)
-		if err != nil {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: failed to apply resource limits: %v", err)
+		delta, aErr := o.resourceManager.DeltaForNode(o.autoscalingContext, nodeInfo, ng)
+		if aErr != nil {
+			klog.Warningf("ScaleUpToNodeGroupMinSize: failed to get node resources of %s: %v", ng.Id(), aErr)
 			continue
 		}
 
-		newNodeCount, err = o.GetCappedNewNodeCount(newNodeCount, targetSize)
-		if err != nil {
-			klog.Warningf("ScaleUpToNodeGroupMinSize: failed to get capped node count: %v", err)
+		newNodeCount, cappingResources := limitsLeft.Reserve(delta, ng.MinSize()-targetSize)
+		if len(cappingResources) > 0 {
+			klog.V(1).Infof("ScaleUpToNodeGroupMinSize: capping scale-up of %s to %d nodes due to limits of %v", ng.Id(), newNodeCount, cappingResources)
+		}
+		if newNodeCount == 0 {
 			continue
 		}
 
@@ -421,6 +516,7 @@ func (o *ScaleUpOrchestrator) filterValidScaleUpNodeGroups(
 	nodeGroups []cloudprovider.NodeGroup,
 	nodeInfos map[string]*schedulerframework.NodeInfo,
 	resourcesLeft resource.Limits,
+	costLeft *cost.Budget,
 	currentNodeCount int,
 	now time.Time,
 ) ([]cloudprovider.NodeGroup, map[string]status.Reasons) {
@@ -444,6 +540,11 @@ func (o *ScaleUpOrchestrator) filterValidScaleUpNodeGroups(
 			skippedNodeGroups[nodeGroup.Id()] = MaxLimitReachedReason
 			continue
 		}
+		if headroom, limited := o.provisioningHeadroom(nodeGroup); limited && headroom == 0 {
+			klog.V(4).Infof("Skipping node group %s - max concurrent provisioning reached", nodeGroup.Id())
+			skippedNodeGroups[nodeGroup.Id()] = MaxConcurrentProvisioningReachedReason
+			continue
+		}
 		autoscalingOptions, err := nodeGroup.GetOptions(o.autoscalingContext.NodeGroupDefaults)
 		if err != nil && err != cloudprovider.ErrNotImplemented {
 			klog.Errorf("Couldn't get autoscaling options for ng: %v", nodeGroup.Id())
@@ -468,6 +569,10 @@ func (o *ScaleUpOrchestrator) filterValidScaleUpNodeGroups(
 			skippedNodeGroups[nodeGroup.Id()] = skipReason
 			continue
 		}
+		if skipReason := o.IsNodeGroupCostExceeded(costLeft, nodeGroup, nodeInfo, numNodes, now); skipReason != nil {
+			skippedNodeGroups[nodeGroup.Id()] = skipReason
+			continue
+		}
 
 		validNodeGroups = append(validNodeGroups, nodeGroup)
 	}
@@ -522,9 +627,75 @@ func (o *ScaleUpOrchestrator) ComputeExpansionOption(
 		}
 	}
 
+	if headroom, limited := o.provisioningHeadroom(nodeGroup); limited && option.NodeCount > headroom {
+		if allOrNothing || (autoscalingOptions != nil && autoscalingOptions.ZeroOrMaxNodeScaling) {
+			// Partial scale-up is not an option, the node group has to wait for provisioning nodes.
+			option.Pods = nil
+			option.NodeCount = 0
+		} else {
+			// Estimate again with the cap, so that the option only holds the pods
+			// fitting on the capped nodes. Remaining pods will spill over to other
+			// node groups in the next loops.
+			klog.V(4).Infof("Capping scale-up of %s from %d to %d nodes due to max concurrent provisioning", nodeGroup.Id(), option.NodeCount, headroom)
+			clusterMaxNodeLimit := currentNodeCount + headroom
+			if maxNodesTotal := o.autoscalingContext.MaxNodesTotal; maxNodesTotal > 0 && maxNodesTotal < clusterMaxNodeLimit {
+				clusterMaxNodeLimit = maxNodesTotal
+			}
+			cappedEstimator := o.estimatorBuilder(
+				o.autoscalingContext.PredicateChecker,
+				o.autoscalingContext.ClusterSnapshot,
+				estimator.NewEstimationContext(clusterMaxNodeLimit, option.SimilarNodeGroups, currentNodeCount),
+			)
+			option.NodeCount, option.Pods = cappedEstimator.Estimate(podGroups, nodeInfo, nodeGroup)
+			if option.NodeCount > headroom {
+				// The estimator doesn't limit binpacking by cluster capacity.
+				option.NodeCount = headroom
+			}
+		}
+	}
+
 	return option
 }
 
+// provisioningHeadroom returns how many nodes can be added to the node group
+// without exceeding its MaxConcurrentProvisioning limit. The second return
+// value is false if the node group doesn't have such limit.
+func (o *ScaleUpOrchestrator) provisioningHeadroom(nodeGroup cloudprovider.NodeGroup) (int, bool) {
+	if o.processors == nil || o.processors.NodeGroupConfigProcessor == nil {
+		return 0, false
+	}
+	maxConcurrentProvisioning, err := o.processors.NodeGroupConfigProcessor.GetMaxConcurrentProvisioning(nodeGroup)
+	if err != nil {
+		klog.Errorf("Failed to get max concurrent provisioning for node group %s: %v", nodeGroup.Id(), err)
+		return 0, false
+	}
+	if maxConcurrentProvisioning <= 0 {
+		return 0, false
+	}
+	upcomingCounts, _ := o.clusterStateRegistry.GetUpcomingNodes()
+	headroom := maxConcurrentProvisioning - upcomingCounts[nodeGroup.Id()]
+	if headroom < 0 {
+		headroom = 0
+	}
+	return headroom, true
+}
+
+// capToProvisioningHeadroom limits scale-ups of balanced node groups so that
+// none of them exceeds its MaxConcurrentProvisioning limit.
+func (o *ScaleUpOrchestrator) capToProvisioningHeadroom(scaleUpInfos []nodegroupset.ScaleUpInfo) []nodegroupset.ScaleUpInfo {
+	var capped []nodegroupset.ScaleUpInfo
+	for _, info := range scaleUpInfos {
+		if headroom, limited := o.provisioningHeadroom(info.Group); limited && info.NewSize-info.CurrentSize > headroom {
+			klog.V(4).Infof("Capping scale-up of %s from %d to %d nodes due to max concurrent provisioning", info.Group.Id(), info.NewSize-info.CurrentSize, headroom)
+			info.NewSize = info.CurrentSize + headroom
+		}
+		if info.NewSize > info.CurrentSize {
+			capped = append(capped, info)
+		}
+	}
+	return capped
+}
+
 // CreateNodeGroup will try to create a new node group based on the initialOption.
 func (o *ScaleUpOrchestrator) CreateNodeGroup(
 	initialOption *expander.Option,
@@ -536,6 +707,11 @@ func (o *ScaleUpOrchestrator) CreateNodeGroup(
 	createNodeGroupResults := make([]nodegroups.CreateNodeGroupResult, 0)
 
 	oldId := initialOption.NodeGroup.Id()
+	if o.autoscalingContext.DryRun {
+		klog.V(0).Infof("Dry run: node group %s would be created", oldId)
+		o.autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeNormal, "CreateNodeGroupDryRun", "Dry run: node group %s would be created", oldId)
+		return createNodeGroupResults, nil, nil
+	}
 	createNodeGroupResult, aErr := o.processors.NodeGroupManager.CreateNodeGroup(o.autoscalingContext, initialOption.NodeGroup)
 	if aErr != nil {
 		status, err := status.UpdateScaleUpError(
@@ -686,6 +862,43 @@ func (o *ScaleUpOrchestrator) IsNodeGroupResourceExceeded(resourcesLeft resource
 	return nil
 }
 
+// applyLimitsToScaleUpInfos caps scale-ups of the node groups, so that together
+// they don't exceed the limits and the hourly cost budget. Each node group
+// reserves its share of the limits and the budget before the next one is
+// considered. Scale-ups capped to no nodes at all are dropped.
+func (o *ScaleUpOrchestrator) applyLimitsToScaleUpInfos(scaleUpInfos []nodegroupset.ScaleUpInfo, nodeInfos map[string]*schedulerframework.NodeInfo, limitsLeft resource.Limits, costLeft *cost.Budget, now time.Time) ([]nodegroupset.ScaleUpInfo, errors.AutoscalerError) {
+	result := make([]nodegroupset.ScaleUpInfo, 0, len(scaleUpInfos))
+	for _, info := range scaleUpInfos {
+		nodeInfo, found := nodeInfos[info.Group.Id()]
+		if !found {
+			return nil, errors.NewAutoscalerError(errors.CloudProviderError, "No node info for: %s", info.Group.Id())
+		}
+		delta, aErr := o.resourceManager.DeltaForNode(o.autoscalingContext, nodeInfo, info.Group)
+		if aErr != nil {
+			return nil, aErr
+		}
+		nodeCost, aErr := o.nodeHourlyCost(costLeft, info.Group, nodeInfo, now)
+		if aErr != nil {
+			return nil, aErr
+		}
+		newNodeCount := costLeft.Fits(nodeCost, info.NewSize-info.CurrentSize)
+		if newNodeCount < info.NewSize-info.CurrentSize {
+			klog.V(1).Infof("Capping scale-up of %s to %d nodes due to max hourly cost", info.Group.Id(), newNodeCount)
+		}
+		newNodeCount, cappingResources := limitsLeft.Reserve(delta, newNodeCount)
+		if len(cappingResources) > 0 {
+			klog.V(1).Infof("Capping scale-up of %s to %d nodes due to limits of %v", info.Group.Id(), newNodeCount, cappingResources)
+		}
+		costLeft.Reserve(nodeCost, newNodeCount)
+		if newNodeCount <= 0 {
+			continue
+		}
+		info.NewSize = info.CurrentSize + newNodeCount
+		result = append(result, info)
+	}
+	return result, nil
+}
+
 // GetCappedNewNodeCount caps resize according to cluster wide node count limit.
 func (o *ScaleUpOrchestrator) GetCappedNewNodeCount(newNodeCount, currentNodeCount int) (int, errors.AutoscalerError) {
 	if o.autoscalingContext.MaxNodesTotal > 0 && newNodeCount+currentNodeCount > o.autoscalingContext.MaxNodesTotal {
@@ -699,6 +912,54 @@ func (o *ScaleUpOrchestrator) GetCappedNewNodeCount(newNodeCount, currentNodeCou
 	return newNodeCount, nil
 }
 
+// GetCostCappedNewNodeCount caps resize according to the hourly cost left in the budget.
+func (o *ScaleUpOrchestrator) GetCostCappedNewNodeCount(costLeft *cost.Budget, newNodeCount int, nodeInfo *schedulerframework.NodeInfo, nodeGroup cloudprovider.NodeGroup, now time.Time) (int, errors.AutoscalerError) {
+	nodeCost, aErr := o.nodeHourlyCost(costLeft, nodeGroup, nodeInfo, now)
+	if aErr != nil {
+		return 0, aErr
+	}
+	if capped := costLeft.Fits(nodeCost, newNodeCount); capped < newNodeCount {
+		klog.V(1).Infof("Capping scale-up of %s from %d to %d nodes due to max hourly cost (%.2f left)", nodeGroup.Id(), newNodeCount, capped, costLeft.Left())
+		o.autoscalingContext.LogRecorder.Eventf(apiv1.EventTypeWarning, "MaxHourlyCostReached", "Max hourly cost of cluster reached: %v, scale-up of %s capped to %d of %d nodes", o.autoscalingContext.MaxHourlyCost, nodeGroup.Id(), capped, newNodeCount)
+		if capped < 1 {
+			return capped, errors.NewAutoscalerError(errors.TransientError, "max hourly cost already reached")
+		}
+		newNodeCount = capped
+	}
+	return newNodeCount, nil
+}
+
+// IsNodeGroupCostExceeded returns nil if adding numNodes nodes to the node group doesn't exceed the hourly cost left in the budget, otherwise a reason is provided.
+func (o *ScaleUpOrchestrator) IsNodeGroupCostExceeded(costLeft *cost.Budget, nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulerframework.NodeInfo, numNodes int, now time.Time) status.Reasons {
+	nodeCost, err := o.nodeHourlyCost(costLeft, nodeGroup, nodeInfo, now)
+	if err != nil {
+		klog.Errorf("Skipping node group %s; error getting node group hourly cost: %v", nodeGroup.Id(), err)
+		return NotReadyReason
+	}
+	if costLeft.Fits(nodeCost, numNodes) < numNodes {
+		klog.V(4).Infof("Skipping node group %s; max hourly cost exceeded, %.2f left, node costs %.2f", nodeGroup.Id(), costLeft.Left(), nodeCost)
+		return MaxHourlyCostReachedReason
+	}
+	return nil
+}
+
+// hourlyCostLeft returns the budget of hourly cost left for scale-ups.
+func (o *ScaleUpOrchestrator) hourlyCostLeft(nodes []*apiv1.Node, upcomingNodes []*schedulerframework.NodeInfo, now time.Time) (*cost.Budget, errors.AutoscalerError) {
+	if o.processors == nil || o.processors.ScaleUpCostProcessor == nil {
+		return cost.UnlimitedBudget(), nil
+	}
+	return o.processors.ScaleUpCostProcessor.HourlyCostLeft(o.autoscalingContext, nodes, upcomingNodes, now)
+}
+
+// nodeHourlyCost returns the hourly cost of a new node of the node group, or 0
+// if the budget doesn't limit scale-ups.
+func (o *ScaleUpOrchestrator) nodeHourlyCost(costLeft *cost.Budget, nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulerframework.NodeInfo, now time.Time) (float64, errors.AutoscalerError) {
+	if !costLeft.Limited() {
+		return 0, nil
+	}
+	return o.processors.ScaleUpCostProcessor.NodeHourlyCost(o.autoscalingContext, nodeGroup, nodeInfo, now)
+}
+
 // ComputeSimilarNodeGroups finds similar node groups which can schedule the same
 // set of pods as the main node group.
 func (o *ScaleUpOrchestrator) ComputeSimilarNodeGroups(
@@ -660,6 +660,84 @@ func TestScaleUpCapToMaxTotalNodesLimit(t *testing.T) {
 	simpleScaleUpTest(t, config, results)
 }
 
+func TestScaleUpCapToMaxConcurrentProvisioning(t *testing.T) {
+	options := defaultOptions
+	options.NodeGroupDefaults.MaxConcurrentProvisioning = 2
+	config := &ScaleUpTestConfig{
+		Nodes: []NodeConfig{
+			{Name: "n1", Cpu: 2000, Memory: 100 * utils.MiB, Gpu: 0, Ready: true, Group: "ng1"},
+			{Name: "n2", Cpu: 4000, Memory: 1000 * utils.MiB, Gpu: 0, Ready: true, Group: "ng2"},
+		},
+		Pods: []PodConfig{
+			{Name: "p1", Cpu: 1000, Memory: 0, Gpu: 0, Node: "n1", ToleratesGpu: false},
+			{Name: "p2", Cpu: 3000, Memory: 0, Gpu: 0, Node: "n2", ToleratesGpu: false},
+		},
+		ExtraPods: []PodConfig{
+			{Name: "p-new-1", Cpu: 4000, Memory: 100 * utils.MiB, Gpu: 0, Node: "", ToleratesGpu: false},
+			{Name: "p-new-2", Cpu: 4000, Memory: 100 * utils.MiB, Gpu: 0, Node: "", ToleratesGpu: false},
+			{Name: "p-new-3", Cpu: 4000, Memory: 100 * utils.MiB, Gpu: 0, Node: "", ToleratesGpu: false},
+		},
+		ExpansionOptionToChoose: &GroupSizeChange{GroupName: "ng2", SizeChange: 2},
+		Options:                 &options,
+		EstimatorThresholds:     []estimator.Threshold{estimator.NewClusterCapacityThreshold()},
+	}
+
+	// The new pods are identical, so which of them fit on the capped nodes
+	// depends on the order of their equivalence groups.
+	results := runSimpleScaleUpTest(t, config)
+	assert.True(t, results.ScaleUpStatus.WasSuccessful())
+	assert.Equal(t, []GroupSizeChange{{GroupName: "ng2", SizeChange: 2}}, results.GroupSizeChanges)
+	assert.ElementsMatch(t, []GroupSizeChange{{GroupName: "ng2", SizeChange: 2}}, results.ExpansionOptions)
+	assert.Len(t, results.ScaleUpStatus.PodsTriggeredScaleUp, 2)
+	assert.Subset(t, []string{"p-new-1", "p-new-2", "p-new-3"}, results.ScaleUpStatus.PodsTriggeredScaleUp)
+}
+
+// corePricingModel prices nodes by their number of cores.
+type corePricingModel struct {
+	pricePerCore float64
+}
+
+func (m *corePricingModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	cpu := node.Status.Capacity[apiv1.ResourceCPU]
+	return m.pricePerCore * float64(cpu.MilliValue()) / 1000 * endTime.Sub(startTime).Hours(), nil
+}
+
+func (m *corePricingModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	return 0, nil
+}
+
+func TestScaleUpCapToMaxHourlyCost(t *testing.T) {
+	options := defaultOptions
+	// Existing nodes cost 6, new nodes of ng2 cost 4 each.
+	options.MaxHourlyCost = 15
+	config := &ScaleUpTestConfig{
+		Nodes: []NodeConfig{
+			{Name: "n1", Cpu: 2000, Memory: 100 * utils.MiB, Gpu: 0, Ready: true, Group: "ng1"},
+			{Name: "n2", Cpu: 4000, Memory: 1000 * utils.MiB, Gpu: 0, Ready: true, Group: "ng2"},
+		},
+		Pods: []PodConfig{
+			{Name: "p1", Cpu: 1000, Memory: 0, Gpu: 0, Node: "n1", ToleratesGpu: false},
+			{Name: "p2", Cpu: 3000, Memory: 0, Gpu: 0, Node: "n2", ToleratesGpu: false},
+		},
+		ExtraPods: []PodConfig{
+			{Name: "p-new-1", Cpu: 4000, Memory: 100 * utils.MiB, Gpu: 0, Node: "", ToleratesGpu: false},
+			{Name: "p-new-2", Cpu: 4000, Memory: 100 * utils.MiB, Gpu: 0, Node: "", ToleratesGpu: false},
+			{Name: "p-new-3", Cpu: 4000, Memory: 100 * utils.MiB, Gpu: 0, Node: "", ToleratesGpu: false},
+		},
+		ExpansionOptionToChoose: &GroupSizeChange{GroupName: "ng2", SizeChange: 3},
+		Options:                 &options,
+		PricingModel:            &corePricingModel{pricePerCore: 1},
+	}
+	results := &ScaleTestResults{
+		FinalOption: GroupSizeChange{GroupName: "ng2", SizeChange: 2},
+		ScaleUpStatus: ScaleUpStatusInfo{
+			PodsTriggeredScaleUp: []string{"p-new-1", "p-new-2", "p-new-3"},
+		},
+	}
+
+	simpleScaleUpTest(t, config, results)
+}
+
 func TestScaleUpCapToMaxTotalNodesLimitWithNotAutoscaledGroup(t *testing.T) {
 	options := defaultOptions
 	options.MaxNodesTotal = 3
@@ -822,6 +900,36 @@ func TestNoScaleUpMaxCoresLimitHit(t *testing.T) {
 	simpleNoScaleUpTest(t, config, results)
 }
 
+func TestNoScaleUpMaxHourlyCostReached(t *testing.T) {
+	options := defaultOptions
+	// Existing nodes cost 6, new nodes cost at least 2 each.
+	options.MaxHourlyCost = 7
+	config := &ScaleUpTestConfig{
+		Nodes: []NodeConfig{
+			{Name: "n1", Cpu: 2000, Memory: 100, Gpu: 0, Ready: true, Group: "ng1"},
+			{Name: "n2", Cpu: 4000, Memory: 1000, Gpu: 0, Ready: true, Group: "ng2"},
+		},
+		Pods: []PodConfig{
+			{Name: "p1", Cpu: 1000, Memory: 0, Gpu: 0, Node: "n1", ToleratesGpu: false},
+			{Name: "p2", Cpu: 3000, Memory: 0, Gpu: 0, Node: "n2", ToleratesGpu: false},
+		},
+		ExtraPods: []PodConfig{
+			{Name: "p-new-1", Cpu: 2000, Memory: 0, Gpu: 0, Node: "", ToleratesGpu: false},
+			{Name: "p-new-2", Cpu: 2000, Memory: 0, Gpu: 0, Node: "", ToleratesGpu: false},
+		},
+		Options:      &options,
+		PricingModel: &corePricingModel{pricePerCore: 1},
+	}
+	results := &ScaleTestResults{
+		NoScaleUpReason: "max cluster hourly cost reached",
+		ScaleUpStatus: ScaleUpStatusInfo{
+			PodsRemainUnschedulable: []string{"p-new-1", "p-new-2"},
+		},
+	}
+
+	simpleNoScaleUpTest(t, config, results)
+}
+
 func TestNoCreateNodeGroupMaxCoresLimitHit(t *testing.T) {
 	options := defaultOptions
 	options.MaxCoresTotal = 7
@@ -1017,6 +1125,9 @@ func runSimpleScaleUpTest(t *testing.T, config *ScaleUpTestConfig) *ScaleUpTestR
 		map[string]int64{cloudprovider.ResourceNameCores: options.MinCoresTotal, cloudprovider.ResourceNameMemory: options.MinMemoryTotal},
 		map[string]int64{cloudprovider.ResourceNameCores: options.MaxCoresTotal, cloudprovider.ResourceNameMemory: options.MaxMemoryTotal})
 	provider.SetResourceLimiter(resourceLimiter)
+	if config.PricingModel != nil {
+		provider.SetPricingModel(config.PricingModel)
+	}
 	groupConfigs := make(map[string]*NodeGroupConfig)
 	for _, group := range config.Groups {
 		groupConfigs[group.Name] = &group
@@ -1058,7 +1169,7 @@ func runSimpleScaleUpTest(t *testing.T, config *ScaleUpTestConfig) *ScaleUpTestR
 		processors.NodeGroupManager = &MockAutoprovisioningNodeGroupManager{T: t, ExtraGroups: 0}
 	}
 	orchestrator := New()
-	orchestrator.Initialize(&context, processors, clusterState, newEstimatorBuilder(), taints.TaintConfig{})
+	orchestrator.Initialize(&context, processors, clusterState, newEstimatorBuilderWithThresholds(config.EstimatorThresholds), taints.TaintConfig{})
 	expander := NewMockRepotingStrategy(t, config.ExpansionOptionToChoose)
 	context.ExpanderStrategy = expander
 
@@ -1664,6 +1775,96 @@ func TestScaleUpToMeetNodeGroupMinSize(t *testing.T) {
 	assert.Equal(t, "ng1", scaleUpStatus.ScaleUpInfos[0].Group.Id())
 }
 
+func TestScaleUpToMeetNodeGroupMinSizeDryRun(t *testing.T) {
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
+	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+		t.Fatalf("Unexpected scale-up of %s by %d in dry-run mode", nodeGroup, increase)
+		return nil
+	}, nil)
+
+	n1 := BuildTestNode("n1", 16000, 32)
+	SetNodeReadyState(n1, true, time.Now())
+	provider.AddNodeGroup("ng1", 3, 10, 1)
+	provider.AddNode("ng1", n1)
+
+	options := config.AutoscalingOptions{
+		EstimatorName:  estimator.BinpackingEstimatorName,
+		MaxCoresTotal:  config.DefaultMaxClusterCores,
+		MaxMemoryTotal: config.DefaultMaxClusterMemory,
+		DryRun:         true,
+	}
+	context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider, nil, nil)
+	assert.NoError(t, err)
+
+	nodes := []*apiv1.Node{n1}
+	nodeInfos, _ := nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nil, false).Process(&context, nodes, []*appsv1.DaemonSet{}, taints.TaintConfig{}, time.Now())
+	processors := NewTestProcessors(&context)
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, NewBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+	suOrchestrator := New()
+	suOrchestrator.Initialize(&context, processors, clusterState, newEstimatorBuilder(), taints.TaintConfig{})
+	scaleUpStatus, err := suOrchestrator.ScaleUpToNodeGroupMinSize(nodes, nodeInfos)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(scaleUpStatus.ScaleUpInfos))
+	assert.Equal(t, 3, scaleUpStatus.ScaleUpInfos[0].NewSize)
+	ng1, err := provider.NodeGroupForNode(n1)
+	assert.NoError(t, err)
+	targetSize, err := ng1.TargetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, targetSize)
+}
+
+func TestScaleUpToMeetNodeGroupMinSizeSharedLimits(t *testing.T) {
+	podLister := kube_util.NewTestPodLister([]*apiv1.Pod{})
+	listers := kube_util.NewListerRegistry(nil, nil, podLister, nil, nil, nil, nil, nil, nil)
+	var scaledUpGroups []string
+	provider := testprovider.NewTestCloudProvider(func(nodeGroup string, increase int) error {
+		scaledUpGroups = append(scaledUpGroups, nodeGroup)
+		assert.Equal(t, 1, increase)
+		return nil
+	}, nil)
+	resourceLimiter := cloudprovider.NewResourceLimiter(
+		map[string]int64{cloudprovider.ResourceNameCores: 0, cloudprovider.ResourceNameMemory: 0},
+		map[string]int64{cloudprovider.ResourceNameCores: 48, cloudprovider.ResourceNameMemory: 1000},
+	)
+	provider.SetResourceLimiter(resourceLimiter)
+
+	// Both node groups are below their min size, but the cores limit only
+	// allows adding a single node in total.
+	n1 := BuildTestNode("n1", 16000, 32)
+	SetNodeReadyState(n1, true, time.Now())
+	n2 := BuildTestNode("n2", 16000, 32)
+	SetNodeReadyState(n2, true, time.Now())
+	provider.AddNodeGroup("ng1", 2, 10, 1)
+	provider.AddNode("ng1", n1)
+	provider.AddNodeGroup("ng2", 2, 10, 1)
+	provider.AddNode("ng2", n2)
+
+	options := config.AutoscalingOptions{
+		EstimatorName:  estimator.BinpackingEstimatorName,
+		MaxCoresTotal:  config.DefaultMaxClusterCores,
+		MaxMemoryTotal: config.DefaultMaxClusterMemory,
+	}
+	context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider, nil, nil)
+	assert.NoError(t, err)
+
+	nodes := []*apiv1.Node{n1, n2}
+	nodeInfos, _ := nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nil, false).Process(&context, nodes, []*appsv1.DaemonSet{}, taints.TaintConfig{}, time.Now())
+	processors := NewTestProcessors(&context)
+	clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{}, context.LogRecorder, NewBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+	clusterState.UpdateNodes(nodes, nodeInfos, time.Now())
+
+	suOrchestrator := New()
+	suOrchestrator.Initialize(&context, processors, clusterState, newEstimatorBuilder(), taints.TaintConfig{})
+	scaleUpStatus, err := suOrchestrator.ScaleUpToNodeGroupMinSize(nodes, nodeInfos)
+	assert.NoError(t, err)
+	assert.True(t, scaleUpStatus.WasSuccessful())
+	assert.Equal(t, 1, len(scaleUpStatus.ScaleUpInfos))
+	assert.Equal(t, 1, len(scaledUpGroups))
+}
+
 func TestCheckDeltaWithinLimits(t *testing.T) {
 	type testcase struct {
 		limits            resource.Limits
@@ -1770,11 +1971,16 @@ func simplifyScaleUpStatus(scaleUpStatus *status.ScaleUpStatus) ScaleUpStatusInf
 }
 
 func newEstimatorBuilder() estimator.EstimatorBuilder {
+	return newEstimatorBuilderWithThresholds(nil)
+}
+
+func newEstimatorBuilderWithThresholds(thresholds []estimator.Threshold) estimator.EstimatorBuilder {
 	estimatorBuilder, _ := estimator.NewEstimatorBuilder(
 		estimator.BinpackingEstimatorName,
-		estimator.NewThresholdBasedEstimationLimiter(nil),
+		estimator.NewThresholdBasedEstimationLimiter(thresholds),
 		estimator.NewDecreasingPodOrderer(),
 		nil,
+		0,
 	)
 
 	return estimatorBuilder
@@ -16,14 +16,17 @@ limitations under the License.
 
 package orchestrator
 
+import "k8s.io/autoscaler/cluster-autoscaler/processors/status"
+
 // RejectedReasons contains information why given node group was rejected as a scale-up option.
 type RejectedReasons struct {
 	messages []string
+	code     string
 }
 
 // NewRejectedReasons creates new RejectedReason object.
 func NewRejectedReasons(m string) *RejectedReasons {
-	return &RejectedReasons{[]string{m}}
+	return &RejectedReasons{messages: []string{m}}
 }
 
 // Reasons returns a slice of reasons why the node group was not considered for scale up.
@@ -31,7 +34,15 @@ func (sr *RejectedReasons) Reasons() []string {
 	return sr.messages
 }
 
+// ReasonCode returns a stable code describing why the node group was rejected.
+func (sr *RejectedReasons) ReasonCode() string {
+	return sr.code
+}
+
 var (
 	// AllOrNothingReason means the node group was rejected because not all pods would fit it when using all-or-nothing strategy.
-	AllOrNothingReason = NewRejectedReasons("not all pods would fit and scale-up is using all-or-nothing strategy")
+	AllOrNothingReason = &RejectedReasons{
+		messages: []string{"not all pods would fit and scale-up is using all-or-nothing strategy"},
+		code:     status.NoScaleUpReasonAllOrNothing,
+	}
 )
@@ -19,16 +19,23 @@ package orchestrator
 import (
 	"fmt"
 	"strings"
+
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
 )
 
 // SkippedReasons contains information why given node group was skipped.
 type SkippedReasons struct {
 	messages []string
+	code     string
 }
 
 // NewSkippedReasons creates new SkippedReason object.
 func NewSkippedReasons(m string) *SkippedReasons {
-	return &SkippedReasons{[]string{m}}
+	return &SkippedReasons{messages: []string{m}}
+}
+
+func newSkippedReasonsWithCode(code, m string) *SkippedReasons {
+	return &SkippedReasons{messages: []string{m}, code: code}
 }
 
 // Reasons returns a slice of reasons why the node group was not considered for scale up.
@@ -36,13 +43,22 @@ func (sr *SkippedReasons) Reasons() []string {
 	return sr.messages
 }
 
+// ReasonCode returns a stable code describing why the node group was not considered for scale up.
+func (sr *SkippedReasons) ReasonCode() string {
+	return sr.code
+}
+
 var (
 	// BackoffReason node group is in backoff.
-	BackoffReason = NewSkippedReasons("in backoff after failed scale-up")
+	BackoffReason = newSkippedReasonsWithCode(status.NoScaleUpReasonNodeGroupBackoff, "in backoff after failed scale-up")
 	// MaxLimitReachedReason node group reached max size limit.
-	MaxLimitReachedReason = NewSkippedReasons("max node group size reached")
+	MaxLimitReachedReason = newSkippedReasonsWithCode(status.NoScaleUpReasonMaxNodeGroupSizeReached, "max node group size reached")
 	// NotReadyReason node group is not ready.
-	NotReadyReason = NewSkippedReasons("not ready for scale-up")
+	NotReadyReason = newSkippedReasonsWithCode(status.NoScaleUpReasonNodeGroupNotReady, "not ready for scale-up")
+	// MaxConcurrentProvisioningReachedReason node group has the maximum number of nodes provisioning.
+	MaxConcurrentProvisioningReachedReason = newSkippedReasonsWithCode(status.NoScaleUpReasonMaxConcurrentProvisioningReached, "max concurrent provisioning reached")
+	// MaxHourlyCostReachedReason adding nodes to the node group would exceed the max hourly cost of the cluster.
+	MaxHourlyCostReachedReason = newSkippedReasonsWithCode(status.NoScaleUpReasonMaxHourlyCostReached, "max cluster hourly cost reached")
 )
 
 // MaxResourceLimitReached contains information why given node group was skipped.
@@ -56,6 +72,11 @@ func (sr *MaxResourceLimitReached) Reasons() []string {
 	return sr.messages
 }
 
+// ReasonCode returns a stable code describing why the node group was not considered for scale up.
+func (sr *MaxResourceLimitReached) ReasonCode() string {
+	return status.NoScaleUpReasonMaxResourceLimitReached
+}
+
 // Resources returns a slice of resources which were missing in the node group.
 func (sr *MaxResourceLimitReached) Resources() []string {
 	return sr.resources
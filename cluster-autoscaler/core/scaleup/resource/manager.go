@@ -34,6 +34,10 @@ import (
 // LimitUnknown is used as a value in ResourcesLimits if actual limit could not be obtained due to errors talking to cloud provider.
 const LimitUnknown = math.MaxInt64
 
+// ResourceNameNodes is the key of the number of nodes in Limits. It allows
+// enforcing the max cluster size together with the other resource limits.
+const ResourceNameNodes = "nodes"
+
 // Manager provides resource checks before scaling up the cluster.
 type Manager struct {
 	crp customresources.CustomResourcesProcessor
@@ -180,6 +184,66 @@ func (m *Manager) ApplyLimits(ctx *context.AutoscalingContext, newCount int, res
 	return newCount, nil
 }
 
+// LimitsWithNodes returns a copy of the limits, additionally limiting the
+// number of nodes which can be added to the cluster if maxNodesTotal is set.
+func LimitsWithNodes(limits Limits, maxNodesTotal, currentNodeCount int) Limits {
+	result := make(Limits, len(limits)+1)
+	for resource, limit := range limits {
+		result[resource] = limit
+	}
+	if maxNodesTotal > 0 {
+		result[ResourceNameNodes] = computeBelowMax(int64(currentNodeCount), int64(maxNodesTotal))
+	}
+	return result
+}
+
+// Reserve caps count to the number of nodes with the given per-node delta
+// fitting within the limits, and decrements the limits by resources of the
+// capped number of nodes. All resources, incl. the number of nodes if it's
+// limited, are checked and decremented together, so that consecutive
+// reservations for different node groups can't exceed any limit in total.
+// Resources limiting the count are returned along with the capped count.
+func (l Limits) Reserve(delta Delta, count int) (int, []string) {
+	nodeDelta := make(Delta, len(delta)+1)
+	for resource, resourceDelta := range delta {
+		nodeDelta[resource] = resourceDelta
+	}
+	nodeDelta[ResourceNameNodes] = 1
+
+	fitting := make(map[string]int)
+	capped := count
+	for resource, resourceDelta := range nodeDelta {
+		limit, found := l[resource]
+		if !found || resourceDelta <= 0 {
+			continue
+		}
+		fitting[resource] = 0
+		if limit != LimitUnknown {
+			fitting[resource] = int(limit / resourceDelta)
+		}
+		if fitting[resource] < capped {
+			capped = fitting[resource]
+		}
+	}
+	cappingResources := sets.NewString()
+	for resource, fittingCount := range fitting {
+		if fittingCount < count && fittingCount == capped {
+			cappingResources.Insert(resource)
+		}
+	}
+	count = capped
+	if count <= 0 {
+		return 0, cappingResources.List()
+	}
+
+	for resource, resourceDelta := range nodeDelta {
+		if limit, found := l[resource]; found {
+			l[resource] = limit - int64(count)*resourceDelta
+		}
+	}
+	return count, cappingResources.List()
+}
+
 // CheckDeltaWithinLimits compares the resource limit and resource delta, and returns the limit check result.
 func CheckDeltaWithinLimits(left Limits, delta Delta) LimitsCheckResult {
 	exceededResources := sets.NewString()
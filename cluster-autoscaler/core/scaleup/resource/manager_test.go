@@ -206,6 +206,40 @@ func TestCheckDeltaWithinLimits(t *testing.T) {
 	}
 }
 
+func TestLimitsWithNodes(t *testing.T) {
+	limits := Limits{cloudprovider.ResourceNameCores: 10}
+	assert.Equal(t, Limits{cloudprovider.ResourceNameCores: 10, ResourceNameNodes: 2}, LimitsWithNodes(limits, 5, 3))
+	assert.Equal(t, Limits{cloudprovider.ResourceNameCores: 10, ResourceNameNodes: 0}, LimitsWithNodes(limits, 5, 7))
+	assert.Equal(t, Limits{cloudprovider.ResourceNameCores: 10}, LimitsWithNodes(limits, 0, 7))
+	assert.Equal(t, Limits{cloudprovider.ResourceNameCores: 10}, limits)
+}
+
+func TestReserve(t *testing.T) {
+	limits := Limits{"cpu": 10, "memory": 20, "gpu": 3, ResourceNameNodes: 4}
+
+	// Capped by cpu.
+	count, capping := limits.Reserve(Delta{"cpu": 4, "memory": 4}, 3)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"cpu"}, capping)
+	assert.Equal(t, Limits{"cpu": 2, "memory": 12, "gpu": 3, ResourceNameNodes: 2}, limits)
+
+	// Capped by gpu and nodes, considering the previous reservation.
+	count, capping = limits.Reserve(Delta{"cpu": 1, "gpu": 1}, 5)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []string{"cpu", ResourceNameNodes}, capping)
+	assert.Equal(t, Limits{"cpu": 0, "memory": 12, "gpu": 1, ResourceNameNodes: 0}, limits)
+
+	// Nothing left.
+	count, _ = limits.Reserve(Delta{"memory": 1}, 1)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, Limits{"cpu": 0, "memory": 12, "gpu": 1, ResourceNameNodes: 0}, limits)
+
+	// Unknown limits don't allow any node.
+	count, capping = Limits{"cpu": LimitUnknown}.Reserve(Delta{"cpu": 1}, 1)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, []string{"cpu"}, capping)
+}
+
 func TestResourceManagerWithGpuResource(t *testing.T) {
 	provider := testprovider.NewTestCloudProvider(nil, nil)
 	resourceLimiter := cloudprovider.NewResourceLimiter(
@@ -59,3 +59,16 @@ type Orchestrator interface {
 		nodeInfos map[string]*schedulerframework.NodeInfo,
 	) (*status.ScaleUpStatus, errors.AutoscalerError)
 }
+
+// Simulator is implemented by orchestrators which can simulate scale-ups without
+// executing them.
+type Simulator interface {
+	// SimulateScaleUp computes the scale-up ScaleUp would execute for the pods,
+	// without creating node groups or increasing their sizes.
+	SimulateScaleUp(
+		unschedulablePods []*apiv1.Pod,
+		nodes []*apiv1.Node,
+		daemonSets []*appsv1.DaemonSet,
+		nodeInfos map[string]*schedulerframework.NodeInfo,
+	) (*status.ScaleUpStatus, errors.AutoscalerError)
+}
@@ -24,6 +24,10 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/cluster-autoscaler/core/healthprobe"
+	"k8s.io/autoscaler/cluster-autoscaler/core/maintenance"
+	"k8s.io/autoscaler/cluster-autoscaler/core/orphanednodes"
+	"k8s.io/autoscaler/cluster-autoscaler/core/rediscovery"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/planner"
 	scaledownstatus "k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
@@ -57,6 +61,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
 	caerrors "k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
 	scheduler_utils "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
 	"k8s.io/utils/integer"
 
@@ -95,6 +100,13 @@ type StaticAutoscaler struct {
 	processorCallbacks      *staticAutoscalerProcessorCallbacks
 	initialized             bool
 	taintConfig             taints.TaintConfig
+	orphanedNodesTracker    *orphanednodes.Tracker
+	maintenanceHandler      *maintenance.Handler
+	nodeGroupRediscovery    *rediscovery.NodeGroupRediscovery
+	healthProber            *healthprobe.Prober
+	// unschedulablePodsCount is the number of unschedulable pods the last iteration
+	// tried to help, not counting virtual pods which are always there.
+	unschedulablePodsCount int
 }
 
 type staticAutoscalerProcessorCallbacks struct {
@@ -152,6 +164,10 @@ func NewStaticAutoscaler(
 	clusterStateConfig := clusterstate.ClusterStateRegistryConfig{
 		MaxTotalUnreadyPercentage: opts.MaxTotalUnreadyPercentage,
 		OkTotalUnreadyCount:       opts.OkTotalUnreadyCount,
+		OutOfScopeNodeLister:      autoscalingKubeClients.OutOfScopeNodeLister(),
+
+		PersistBackoff:               opts.PersistNodeGroupBackoff,
+		ResetBackoffOnScaleUpSuccess: opts.ResetNodeGroupBackoffOnScaleUpSuccess,
 	}
 	clusterStateRegistry := clusterstate.NewClusterStateRegistry(cloudProvider, clusterStateConfig, autoscalingKubeClients.LogRecorder, backoff, processors.NodeGroupConfigProcessor)
 	processorCallbacks := newStaticAutoscalerProcessorCallbacks()
@@ -189,6 +205,9 @@ func NewStaticAutoscaler(
 		scaleDownPlanner = scaleDownWrapper
 		scaleDownActuator = scaleDownWrapper
 	}
+	if opts.DryRun {
+		scaleDownActuator = actuation.NewDryRunActuator(autoscalingContext, scaleDownActuator)
+	}
 	processorCallbacks.scaleDownPlanner = scaleDownPlanner
 
 	if scaleUpOrchestrator == nil {
@@ -196,6 +215,17 @@ func NewStaticAutoscaler(
 	}
 	scaleUpOrchestrator.Initialize(autoscalingContext, processors, clusterStateRegistry, estimatorBuilder, taintConfig)
 
+	healthProbeNamespace := opts.HealthProbeNamespace
+	if healthProbeNamespace == "" {
+		healthProbeNamespace = opts.ConfigNamespace
+	}
+	var healthProber *healthprobe.Prober
+	if simulator, ok := scaleUpOrchestrator.(scaleup.Simulator); ok {
+		healthProber = healthprobe.NewProber(opts.HealthProbeInterval, healthProbeNamespace, simulator)
+	} else if opts.HealthProbeInterval > 0 {
+		klog.Warningf("Scale-up orchestrator can't simulate scale-ups, health probe is disabled")
+	}
+
 	// Set the initial scale times to be less than the start time so as to
 	// not start in cooldown mode.
 	initialScaleTime := time.Now().Add(-time.Hour)
@@ -212,6 +242,9 @@ func NewStaticAutoscaler(
 		processorCallbacks:      processorCallbacks,
 		clusterStateRegistry:    clusterStateRegistry,
 		taintConfig:             taintConfig,
+		orphanedNodesTracker:    orphanednodes.NewTracker(opts.OrphanedNodesPolicy, opts.OrphanedNodesGracePeriod, opts.OrphanedNodesDrainTimeout),
+		maintenanceHandler:      maintenance.NewHandler(cloudProvider, opts.MaintenanceLeadTime),
+		healthProber:            healthProber,
 	}
 }
 
@@ -225,6 +258,19 @@ func (a *StaticAutoscaler) LastScaleDownDeleteTime() time.Time {
 	return a.lastScaleDownDeleteTime
 }
 
+// IsActive returns true if the last iteration found unschedulable pods to help,
+// or scale up or scale down is in flight.
+func (a *StaticAutoscaler) IsActive() bool {
+	if a.unschedulablePodsCount > 0 {
+		return true
+	}
+	if currentSize, targetSize := a.clusterStateRegistry.GetAutoscaledNodesCount(); currentSize != targetSize {
+		return true
+	}
+	empty, drained := a.scaleDownActuator.CheckStatus().DeletionsInProgress()
+	return len(empty)+len(drained) > 0
+}
+
 // Start starts components running in background.
 func (a *StaticAutoscaler) Start() error {
 	a.clusterStateRegistry.Start()
@@ -237,6 +283,11 @@ func (a *StaticAutoscaler) cleanUpIfRequired() {
 	if a.initialized {
 		return
 	}
+	if a.DryRun {
+		klog.V(0).Infof("Dry run: not cleaning up taints left by a previous run")
+		a.initialized = true
+		return
+	}
 
 	// CA can die at any time. Removing taints that might have been left from the previous run.
 	if allNodes, err := a.AllNodeLister().List(); err != nil {
@@ -251,6 +302,9 @@ func (a *StaticAutoscaler) cleanUpIfRequired() {
 			taints.CleanAllDeletionCandidates(allNodes,
 				a.AutoscalingContext.ClientSet, a.Recorder)
 		}
+		// Taints left by other tooling, e.g. aborted drains.
+		taints.CleanAllTaintsWithPrefixes(selectedNodes, a.AutoscalingContext.ClientSet, a.Recorder,
+			a.AutoscalingContext.StartupCleanupTaintPrefixes, a.AutoscalingContext.StartupCleanupTaintsDryRun)
 	}
 	a.initialized = true
 }
@@ -277,6 +331,21 @@ func (a *StaticAutoscaler) initializeClusterSnapshot(nodes []*apiv1.Node, schedu
 	return nil
 }
 
+// addUpcomingNodesToClusterSnapshot injects placeholder nodes of upcoming nodes into the cluster snapshot.
+func (a *StaticAutoscaler) addUpcomingNodesToClusterSnapshot(upcomingNodeInfos []*schedulerframework.NodeInfo) caerrors.AutoscalerError {
+	for _, upcomingNode := range upcomingNodeInfos {
+		var pods []*apiv1.Pod
+		for _, podInfo := range upcomingNode.Pods {
+			pods = append(pods, podInfo.Pod)
+		}
+		if err := a.ClusterSnapshot.AddNodeWithPods(upcomingNode.Node(), pods); err != nil {
+			klog.Errorf("Failed to add upcoming node %s to cluster snapshot: %v", upcomingNode.Node().Name, err)
+			return caerrors.ToAutoscalerError(caerrors.InternalError, err)
+		}
+	}
+	return nil
+}
+
 func (a *StaticAutoscaler) initializeRemainingPdbTracker() caerrors.AutoscalerError {
 	a.RemainingPdbTracker.Clear()
 
@@ -297,6 +366,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	a.cleanUpIfRequired()
 	a.processorCallbacks.reset()
 	a.clusterStateRegistry.PeriodicCleanup()
+	a.unschedulablePodsCount = 0
 	a.DebuggingSnapshotter.StartDataCollection()
 	defer a.DebuggingSnapshotter.Flush()
 
@@ -343,6 +413,12 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	}
 	// Snapshot scale-down actuation status before cache refresh.
 	scaleDownActuationStatus := a.scaleDownActuator.CheckStatus()
+	// Rediscover node groups before the refresh, so that it sees up to date definitions.
+	if a.nodeGroupRediscovery != nil {
+		if err := a.nodeGroupRediscovery.RediscoverIfNeeded(currentTime); err != nil {
+			klog.Errorf("Failed to rediscover node groups: %v", err)
+		}
+	}
 	// Call CloudProvider.Refresh before any other calls to cloud provider.
 	refreshStart := time.Now()
 	err = a.AutoscalingContext.CloudProvider.Refresh()
@@ -389,6 +465,16 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	}
 	metrics.UpdateDurationFromStart(metrics.UpdateState, stateUpdateStart)
 
+	if a.orphanedNodesTracker != nil && !a.DryRun {
+		a.orphanedNodesTracker.Update(autoscalingContext, allNodes, currentTime)
+	}
+	if a.maintenanceHandler != nil && !a.DryRun {
+		a.maintenanceHandler.Update(autoscalingContext, allNodes, currentTime)
+	}
+	if a.healthProber != nil {
+		a.healthProber.ProbeIfNeeded(autoscalingContext, readyNodes, daemonsets, nodeInfosForGroups, currentTime)
+	}
+
 	scaleUpStatus := &status.ScaleUpStatus{Result: status.ScaleUpNotTried}
 	scaleUpStatusProcessorAlreadyCalled := false
 	scaleDownStatus := &scaledownstatus.ScaleDownStatus{Result: scaledownstatus.ScaleDownNotTried}
@@ -397,6 +483,9 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 		// Update status information when the loop is done (regardless of reason)
 		if autoscalingContext.WriteStatusConfigMap {
 			status := a.clusterStateRegistry.GetStatus(currentTime)
+			if a.orphanedNodesTracker != nil {
+				status.ClusterWide.OrphanedNodes = a.orphanedNodesTracker.Status()
+			}
 			utils.WriteStatusConfigMap(autoscalingContext.ClientSet, autoscalingContext.ConfigNamespace,
 				*status, a.AutoscalingContext.LogRecorder, a.AutoscalingContext.StatusConfigMapName, currentTime)
 		}
@@ -428,7 +517,9 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	// Check if there are any nodes that failed to register in Kubernetes
 	// master.
 	unregisteredNodes := a.clusterStateRegistry.GetUnregisteredNodes()
-	if len(unregisteredNodes) > 0 {
+	if len(unregisteredNodes) > 0 && a.DryRun {
+		klog.V(0).Infof("Dry run: not removing %d unregistered nodes", len(unregisteredNodes))
+	} else if len(unregisteredNodes) > 0 {
 		klog.V(1).Infof("%d unregistered nodes present", len(unregisteredNodes))
 		removedAny, err := a.removeOldUnregisteredNodes(unregisteredNodes, autoscalingContext,
 			a.clusterStateRegistry, currentTime, autoscalingContext.LogRecorder)
@@ -448,27 +539,30 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 		return nil
 	}
 
-	danglingNodes, err := a.deleteCreatedNodesWithErrors()
-	if err != nil {
-		klog.Warningf("Failed to remove nodes that were created with errors, skipping iteration: %v", err)
-		return nil
-	}
-	if danglingNodes {
-		klog.V(0).Infof("Some nodes that failed to create were removed, skipping iteration")
-		return nil
-	}
+	// In dry-run mode, nodes created with errors and node group sizes are left as they are.
+	if !a.DryRun {
+		danglingNodes, err := a.deleteCreatedNodesWithErrors()
+		if err != nil {
+			klog.Warningf("Failed to remove nodes that were created with errors, skipping iteration: %v", err)
+			return nil
+		}
+		if danglingNodes {
+			klog.V(0).Infof("Some nodes that failed to create were removed, skipping iteration")
+			return nil
+		}
 
-	// Check if there has been a constant difference between the number of nodes in k8s and
-	// the number of nodes on the cloud provider side.
-	// TODO: andrewskim - add protection for ready AWS nodes.
-	fixedSomething, err := fixNodeGroupSize(autoscalingContext, a.clusterStateRegistry, currentTime)
-	if err != nil {
-		klog.Errorf("Failed to fix node group sizes: %v", err)
-		return caerrors.ToAutoscalerError(caerrors.CloudProviderError, err)
-	}
-	if fixedSomething {
-		klog.V(0).Infof("Some node group target size was fixed, skipping the iteration")
-		return nil
+		// Check if there has been a constant difference between the number of nodes in k8s and
+		// the number of nodes on the cloud provider side.
+		// TODO: andrewskim - add protection for ready AWS nodes.
+		fixedSomething, err := fixNodeGroupSize(autoscalingContext, a.clusterStateRegistry, currentTime)
+		if err != nil {
+			klog.Errorf("Failed to fix node group sizes: %v", err)
+			return caerrors.ToAutoscalerError(caerrors.CloudProviderError, err)
+		}
+		if fixedSomething {
+			klog.V(0).Infof("Some node group target size was fixed, skipping the iteration")
+			return nil
+		}
 	}
 
 	metrics.UpdateLastTime(metrics.Autoscaling, time.Now())
@@ -485,16 +579,9 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	// them and not trigger another scale-up.
 	// The fake nodes are intentionally not added to the all nodes list, so that they are not considered as candidates for scale-down (which
 	// doesn't make sense as they're not real).
-	for _, upcomingNode := range getUpcomingNodeInfos(upcomingCounts, nodeInfosForGroups) {
-		var pods []*apiv1.Pod
-		for _, podInfo := range upcomingNode.Pods {
-			pods = append(pods, podInfo.Pod)
-		}
-		err = a.ClusterSnapshot.AddNodeWithPods(upcomingNode.Node(), pods)
-		if err != nil {
-			klog.Errorf("Failed to add upcoming node %s to cluster snapshot: %v", upcomingNode.Node().Name, err)
-			return caerrors.ToAutoscalerError(caerrors.InternalError, err)
-		}
+	upcomingNodeInfos := getUpcomingNodeInfos(upcomingCounts, nodeInfosForGroups)
+	if typedErr := a.addUpcomingNodesToClusterSnapshot(upcomingNodeInfos); typedErr != nil {
+		return typedErr
 	}
 	// Some upcoming nodes can already be registered in the cluster, but not yet ready - we still inject replacements for them above. The actual registered nodes
 	// have to be filtered out of the all nodes list so that scale-down can't consider them as candidates. Otherwise, with aggressive scale-down settings, we
@@ -524,12 +611,46 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 		a.AutoscalingContext.DebuggingSnapshotter.SetClusterNodes(l)
 	}
 
+	var snapshotCheckpoint *clustersnapshot.RevisionCheckpoint
+	if a.VerifyClusterSnapshotRevisions {
+		snapshotCheckpoint, err = clustersnapshot.NewRevisionCheckpoint(a.ClusterSnapshot)
+		if err != nil {
+			klog.Errorf("Failed to record cluster snapshot revision: %v", err)
+		}
+	}
+
 	unschedulablePodsToHelp, err := a.processors.PodListProcessor.Process(a.AutoscalingContext, unschedulablePods)
 
 	if err != nil {
 		klog.Warningf("Failed to process unschedulable pods: %v", err)
 	}
 
+	if snapshotCheckpoint != nil {
+		if err := snapshotCheckpoint.Verify(a.ClusterSnapshot); err != nil {
+			// Pod list processors modified nodes or pods in the snapshot without going through its API, so data cached
+			// by the snapshot, e.g. requests of pods, may be stale. Rebuild it from scratch and process the pods again.
+			klog.Errorf("Cluster snapshot was modified outside of its API by pod list processors, rebuilding it: %v", err)
+			metrics.RegisterClusterSnapshotRebuild()
+			if typedErr := a.initializeClusterSnapshot(allNodes, nonExpendableScheduledPods); typedErr != nil {
+				return typedErr.AddPrefix("failed to rebuild ClusterSnapshot: ")
+			}
+			if typedErr := a.addUpcomingNodesToClusterSnapshot(upcomingNodeInfos); typedErr != nil {
+				return typedErr
+			}
+			unschedulablePodsToHelp, err = a.processors.PodListProcessor.Process(a.AutoscalingContext, unschedulablePods)
+			if err != nil {
+				klog.Warningf("Failed to process unschedulable pods: %v", err)
+			}
+		}
+	}
+
+	a.unschedulablePodsCount = 0
+	for _, pod := range unschedulablePodsToHelp {
+		if !pod_util.IsVirtualPod(pod) {
+			a.unschedulablePodsCount++
+		}
+	}
+
 	// finally, filter out pods that are too "young" to safely be considered for a scale-up (delay is configurable)
 	unschedulablePodsToHelp = a.filterOutYoungPods(unschedulablePodsToHelp, currentTime)
 
@@ -637,7 +758,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 		// in progress.
 		_, drained := scaleDownActuationStatus.DeletionsInProgress()
 		var removedNodeGroups []cloudprovider.NodeGroup
-		if len(drained) == 0 {
+		if len(drained) == 0 && !a.DryRun {
 			var err error
 			removedNodeGroups, err = a.processors.NodeGroupManager.RemoveUnneededNodeGroups(autoscalingContext)
 			if err != nil {
@@ -648,6 +769,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 
 		if scaleDownInCooldown {
 			scaleDownStatus.Result = scaledownstatus.ScaleDownInCooldown
+			a.updateScaleDownBlockedNodes(unneededNodes, currentTime)
 		} else {
 			klog.V(4).Infof("Starting scale down")
 
@@ -659,6 +781,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 			scaleDownStatus.ScaledDownNodes = scaledDownNodes
 			metrics.UpdateDurationFromStart(metrics.ScaleDown, scaleDownStart)
 			metrics.UpdateUnremovableNodesCount(countsByReason(a.scaleDownPlanner.UnremovableNodes()))
+			a.updateScaleDownBlockedNodes(nil, currentTime)
 
 			scaleDownStatus.RemovedNodeGroups = removedNodeGroups
 
@@ -669,7 +792,7 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 
 			if (scaleDownStatus.Result == scaledownstatus.ScaleDownNoNodeDeleted ||
 				scaleDownStatus.Result == scaledownstatus.ScaleDownNoUnneeded) &&
-				a.AutoscalingContext.AutoscalingOptions.MaxBulkSoftTaintCount != 0 {
+				a.AutoscalingContext.AutoscalingOptions.MaxBulkSoftTaintCount != 0 && !a.DryRun {
 				taintableNodes := a.scaleDownPlanner.UnneededNodes()
 
 				// Make sure we are only cleaning taints from selected node groups.
@@ -713,6 +836,43 @@ func (a *StaticAutoscaler) isScaleDownInCooldown(currentTime time.Time, scaleDow
 		a.lastScaleDownDeleteTime.Add(a.ScaleDownDelayAfterDelete).After(currentTime)
 }
 
+// updateScaleDownBlockedNodes reports nodes which can't be scaled down, along
+// with the reasons why, in the status and the debugging snapshot. Unneeded nodes
+// are reported as blocked if scale down is in cooldown after a recent scale up.
+func (a *StaticAutoscaler) updateScaleDownBlockedNodes(unneededNodes []*apiv1.Node, currentTime time.Time) {
+	blockedNodes := a.scaleDownPlanner.UnremovableNodes()
+	if !a.ScaleDownDelayTypeLocal && a.lastScaleUpTime.Add(a.ScaleDownDelayAfterAdd).After(currentTime) {
+		for _, node := range unneededNodes {
+			blockedNodes = append(blockedNodes, &simulator.UnremovableNode{Node: node, Reason: simulator.RecentScaleUp})
+		}
+	}
+	a.clusterStateRegistry.UpdateScaleDownBlockedNodes(blockedNodes)
+
+	if !a.DebuggingSnapshotter.IsDataCollectionAllowed() {
+		return
+	}
+	nodeUtilizationMap := a.scaleDownPlanner.NodeUtilizationMap()
+	unremovableNodes := make([]*debuggingsnapshot.UnremovableNode, 0, len(blockedNodes))
+	for _, blockedNode := range blockedNodes {
+		unremovableNode := &debuggingsnapshot.UnremovableNode{
+			Name:   blockedNode.Node.Name,
+			Reason: blockedNode.Reason.String(),
+		}
+		if nodeGroup, err := a.CloudProvider.NodeGroupForNode(blockedNode.Node); err == nil && nodeGroup != nil && !reflect.ValueOf(nodeGroup).IsNil() {
+			unremovableNode.NodeGroup = nodeGroup.Id()
+		}
+		if blockedNode.BlockingPod != nil {
+			unremovableNode.BlockingPod = blockedNode.BlockingPod.Pod.Namespace + "/" + blockedNode.BlockingPod.Pod.Name
+			unremovableNode.BlockingPodReason = blockedNode.BlockingPod.Reason.String()
+		}
+		if utilInfo, found := nodeUtilizationMap[blockedNode.Node.Name]; found {
+			unremovableNode.Utilization = utilInfo.Utilization
+		}
+		unremovableNodes = append(unremovableNodes, unremovableNode)
+	}
+	a.DebuggingSnapshotter.SetUnremovableNodes(unremovableNodes)
+}
+
 // Sets the target size of node groups to the current number of nodes in them
 // if the difference was constant for a prolonged time. Returns true if managed
 // to fix something.
@@ -959,7 +1119,11 @@ func (a *StaticAutoscaler) ExitCleanUp() {
 	if !a.AutoscalingContext.WriteStatusConfigMap {
 		return
 	}
-	utils.DeleteStatusConfigMap(a.AutoscalingContext.ClientSet, a.AutoscalingContext.ConfigNamespace, a.AutoscalingContext.StatusConfigMapName)
+	// The status ConfigMap is kept, so that the persisted backoff can be
+	// restored after a restart.
+	if !a.AutoscalingContext.PersistNodeGroupBackoff {
+		utils.DeleteStatusConfigMap(a.AutoscalingContext.ClientSet, a.AutoscalingContext.ConfigNamespace, a.AutoscalingContext.StatusConfigMapName)
+	}
 
 	a.CloudProvider.Cleanup()
 
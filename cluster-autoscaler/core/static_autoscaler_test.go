@@ -65,6 +65,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	apiv1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes/fake"
@@ -2619,7 +2620,106 @@ func newEstimatorBuilder() estimator.EstimatorBuilder {
 		estimator.NewThresholdBasedEstimationLimiter(nil),
 		estimator.NewDecreasingPodOrderer(),
 		nil,
+		0,
 	)
 
 	return estimatorBuilder
 }
+
+// inPlaceMutatingPodListProcessor mutates requests of a scheduled pod in place the first time it's called.
+type inPlaceMutatingPodListProcessor struct {
+	pod   *apiv1.Pod
+	calls int
+}
+
+func (p *inPlaceMutatingPodListProcessor) Process(_ *context.AutoscalingContext, unschedulablePods []*apiv1.Pod) ([]*apiv1.Pod, error) {
+	if p.calls == 0 {
+		p.pod.Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU] = *resource.NewMilliQuantity(200, resource.DecimalSI)
+	}
+	p.calls++
+	return unschedulablePods, nil
+}
+
+func (p *inPlaceMutatingPodListProcessor) CleanUp() {}
+
+func TestStaticAutoscalerRunOnceVerifiesClusterSnapshotRevisions(t *testing.T) {
+	for _, verify := range []bool{false, true} {
+		t.Run(fmt.Sprintf("verify=%v", verify), func(t *testing.T) {
+			readyNodeLister := kubernetes.NewTestNodeLister(nil)
+			allNodeLister := kubernetes.NewTestNodeLister(nil)
+			allPodListerMock := &podListerMock{}
+			podDisruptionBudgetListerMock := &podDisruptionBudgetListerMock{}
+			daemonSetListerMock := &daemonSetListerMock{}
+
+			n1 := BuildTestNode("n1", 1000, 1000)
+			SetNodeReadyState(n1, true, time.Now())
+			p1 := BuildTestPod("p1", 100, 0)
+			p1.Spec.NodeName = "n1"
+
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			provider.AddNodeGroup("ng1", 1, 10, 1)
+			provider.AddNode("ng1", n1)
+
+			options := config.AutoscalingOptions{
+				NodeGroupDefaults: config.NodeGroupAutoscalingOptions{
+					ScaleDownUtilizationThreshold: 0.5,
+					MaxNodeProvisionTime:          10 * time.Second,
+				},
+				EstimatorName:                  estimator.BinpackingEstimatorName,
+				ScaleDownEnabled:               false,
+				MaxNodesTotal:                  10,
+				MaxCoresTotal:                  10,
+				MaxMemoryTotal:                 100000,
+				VerifyClusterSnapshotRevisions: verify,
+			}
+			processorCallbacks := newStaticAutoscalerProcessorCallbacks()
+
+			context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider, processorCallbacks, nil)
+			assert.NoError(t, err)
+
+			setUpScaleDownActuator(&context, options)
+
+			context.ListerRegistry = kube_util.NewListerRegistry(allNodeLister, readyNodeLister, allPodListerMock,
+				podDisruptionBudgetListerMock, daemonSetListerMock,
+				nil, nil, nil, nil)
+
+			processors := NewTestProcessors(&context)
+			podListProcessor := &inPlaceMutatingPodListProcessor{pod: p1}
+			processors.PodListProcessor = podListProcessor
+			clusterState := clusterstate.NewClusterStateRegistry(provider, clusterstate.ClusterStateRegistryConfig{OkTotalUnreadyCount: 1}, context.LogRecorder, NewBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(options.NodeGroupDefaults))
+			sdPlanner, sdActuator := newScaleDownPlannerAndActuator(&context, processors, clusterState, nil)
+
+			autoscaler := &StaticAutoscaler{
+				AutoscalingContext:    &context,
+				clusterStateRegistry:  clusterState,
+				lastScaleUpTime:       time.Now(),
+				lastScaleDownFailTime: time.Now(),
+				scaleDownPlanner:      sdPlanner,
+				scaleDownActuator:     sdActuator,
+				processors:            processors,
+				loopStartNotifier:     loopstart.NewObserversList(nil),
+				processorCallbacks:    processorCallbacks,
+			}
+
+			readyNodeLister.SetNodes([]*apiv1.Node{n1})
+			allNodeLister.SetNodes([]*apiv1.Node{n1})
+			allPodListerMock.On("List").Return([]*apiv1.Pod{p1}, nil).Twice()
+			daemonSetListerMock.On("List", labels.Everything()).Return([]*appsv1.DaemonSet{}, nil).Once()
+			podDisruptionBudgetListerMock.On("List").Return([]*policyv1.PodDisruptionBudget{}, nil).Once()
+
+			err = autoscaler.RunOnce(time.Now())
+			assert.NoError(t, err)
+
+			nodeInfo, err := autoscaler.ClusterSnapshot.NodeInfos().Get("n1")
+			assert.NoError(t, err)
+			if verify {
+				// The snapshot was rebuilt, so requests cached by it match the mutated pod.
+				assert.Equal(t, 2, podListProcessor.calls)
+				assert.Equal(t, int64(200), nodeInfo.Requested.MilliCPU)
+			} else {
+				assert.Equal(t, 1, podListProcessor.calls)
+				assert.Equal(t, int64(100), nodeInfo.Requested.MilliCPU)
+			}
+		})
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/deletiontracker"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
 	"k8s.io/autoscaler/cluster-autoscaler/debuggingsnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/estimator"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/random"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
@@ -39,6 +40,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/processors/actionablecluster"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/binpacking"
 	processor_callbacks "k8s.io/autoscaler/cluster-autoscaler/processors/callbacks"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/cost"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/customresources"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroups"
@@ -128,6 +130,9 @@ type ScaleUpTestConfig struct {
 	NodeTemplateConfigs     map[string]*NodeTemplateConfig
 	EnableAutoprovisioning  bool
 	AllOrNothing            bool
+	PricingModel            cloudprovider.PricingModel
+	// EstimatorThresholds limit binpacking of scale-up estimations, none if empty.
+	EstimatorThresholds []estimator.Threshold
 }
 
 // ScaleUpTestResult represents a node groups scale up result
@@ -184,6 +189,7 @@ func NewTestProcessors(context *context.AutoscalingContext) *processors.Autoscal
 			nodes.NewMaxNodesProcessor(),
 			nodes.NewAtomicResizeFilteringProcessor(),
 		}),
+		ScaleUpCostProcessor: cost.NewScaleUpCostProcessor(context.MaxHourlyCost),
 		// TODO(bskiba): change scale up test so that this can be a NoOpProcessor
 		ScaleUpStatusProcessor:      &status.EventingScaleUpStatusProcessor{},
 		ScaleDownStatusProcessor:    &status.NoOpScaleDownStatusProcessor{},
@@ -217,7 +223,7 @@ func NewScaleTestAutoscalingContext(
 	}
 	remainingPdbTracker := pdb.NewBasicRemainingPdbTracker()
 	if debuggingSnapshotter == nil {
-		debuggingSnapshotter = debuggingsnapshot.NewDebuggingSnapshotter(false)
+		debuggingSnapshotter = debuggingsnapshot.NewDebuggingSnapshotter(false, debuggingsnapshot.OutputOptions{})
 	}
 	clusterSnapshot := clustersnapshot.NewBasicClusterSnapshot()
 	return context.AutoscalingContext{
@@ -31,6 +31,16 @@ type ClusterNode struct {
 	Pods []*v1.Pod `json:"Pods"`
 }
 
+// UnremovableNode captures why a single node can't be scaled down.
+type UnremovableNode struct {
+	Name              string  `json:"Name"`
+	NodeGroup         string  `json:"NodeGroup,omitempty"`
+	Reason            string  `json:"Reason"`
+	BlockingPod       string  `json:"BlockingPod,omitempty"`
+	BlockingPodReason string  `json:"BlockingPodReason,omitempty"`
+	Utilization       float64 `json:"Utilization,omitempty"`
+}
+
 // DebuggingSnapshot is the interface used to define any debugging snapshot
 // implementation, incl. any custom impl. to be used by DebuggingSnapshotter
 type DebuggingSnapshot interface {
@@ -42,6 +52,9 @@ type DebuggingSnapshot interface {
 	// SetTemplateNodes is a setter for all the TemplateNodes present in the cluster
 	// incl. templates for which there are no nodes
 	SetTemplateNodes(map[string]*framework.NodeInfo)
+	// SetUnremovableNodes is a setter for all the nodes which can't be scaled down,
+	// along with the reasons why
+	SetUnremovableNodes([]*UnremovableNode)
 	// SetErrorMessage sets the error message in the snapshot
 	SetErrorMessage(string)
 	// SetEndTimestamp sets the timestamp in the snapshot,
@@ -67,6 +80,7 @@ type DebuggingSnapshotImpl struct {
 	StartTimestamp                time.Time               `json:"StartTimestamp"`
 	EndTimestamp                  time.Time               `json:"EndTimestamp"`
 	TemplateNodes                 map[string]*ClusterNode `json:"TemplateNodes"`
+	UnremovableNodes              []*UnremovableNode      `json:"UnremovableNodes"`
 }
 
 // SetUnscheduledPodsCanBeScheduled is the setter for UnscheduledPodsCanBeScheduled
@@ -93,6 +107,19 @@ func (s *DebuggingSnapshotImpl) SetTemplateNodes(templates map[string]*framework
 	}
 }
 
+// SetUnremovableNodes is the setter for UnremovableNodes
+func (s *DebuggingSnapshotImpl) SetUnremovableNodes(nodes []*UnremovableNode) {
+	if nodes == nil {
+		return
+	}
+
+	s.UnremovableNodes = nil
+	for _, node := range nodes {
+		nodeCopy := *node
+		s.UnremovableNodes = append(s.UnremovableNodes, &nodeCopy)
+	}
+}
+
 // GetClusterNodeCopy is an util func to copy template node and filter values
 func GetClusterNodeCopy(template *framework.NodeInfo) *ClusterNode {
 	cNode := &ClusterNode{}
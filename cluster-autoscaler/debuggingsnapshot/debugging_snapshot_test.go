@@ -102,3 +102,26 @@ func TestEmptyDataNoError(t *testing.T) {
 	assert.False(t, err)
 	assert.NotNil(t, op)
 }
+
+func TestSetUnremovableNodes(t *testing.T) {
+	snapshot := &DebuggingSnapshotImpl{}
+	node := &UnremovableNode{
+		Name:              "testNode",
+		NodeGroup:         "ng1",
+		Reason:            "BlockedByPod",
+		BlockingPod:       "default/Pod1",
+		BlockingPodReason: "NotEnoughPdb",
+		Utilization:       0.3,
+	}
+	snapshot.SetUnremovableNodes([]*UnremovableNode{node})
+	node.Reason = "NotUnderutilized"
+	op, err := snapshot.GetOutputBytes()
+	assert.False(t, err)
+
+	var parsed DebuggingSnapshotImpl
+	assert.NoError(t, json.Unmarshal(op, &parsed))
+	assert.Len(t, parsed.UnremovableNodes, 1)
+	assert.Equal(t, "BlockedByPod", parsed.UnremovableNodes[0].Reason)
+	assert.Equal(t, "default/Pod1", parsed.UnremovableNodes[0].BlockingPod)
+	assert.Equal(t, "NotEnoughPdb", parsed.UnremovableNodes[0].BlockingPodReason)
+}
@@ -66,6 +66,8 @@ type DebuggingSnapshotterImpl struct {
 	// CancelRequest is the cancel function for the snapshot request. It is used to
 	// terminate any ongoing request when CA is shutting down
 	CancelRequest context.CancelFunc
+	// OutputOptions configure redaction and encryption of the snapshot output
+	OutputOptions OutputOptions
 }
 
 // DebuggingSnapshotter is the interface for debugging snapshot
@@ -82,6 +84,9 @@ type DebuggingSnapshotter interface {
 	// SetTemplateNodes is a setter for all the TemplateNodes present in the cluster
 	// incl. templates for which there are no nodes
 	SetTemplateNodes(map[string]*framework.NodeInfo)
+	// SetUnremovableNodes is a setter for all the nodes which can't be scaled down,
+	// along with the reasons why
+	SetUnremovableNodes([]*UnremovableNode)
 	// ResponseHandler is the http response handler to manage incoming requests
 	ResponseHandler(http.ResponseWriter, *http.Request)
 	// IsDataCollectionAllowed checks the internal State of the snapshotter
@@ -95,7 +100,7 @@ type DebuggingSnapshotter interface {
 }
 
 // NewDebuggingSnapshotter returns a new instance of DebuggingSnapshotter
-func NewDebuggingSnapshotter(isDebuggerEnabled bool) DebuggingSnapshotter {
+func NewDebuggingSnapshotter(isDebuggerEnabled bool, outputOptions OutputOptions) DebuggingSnapshotter {
 	state := SNAPSHOTTER_DISABLED
 	if isDebuggerEnabled {
 		klog.Infof("Debugging Snapshot is enabled")
@@ -106,6 +111,7 @@ func NewDebuggingSnapshotter(isDebuggerEnabled bool) DebuggingSnapshotter {
 		Mutex:             &sync.Mutex{},
 		DebuggingSnapshot: &DebuggingSnapshotImpl{},
 		Trigger:           make(chan struct{}, 1),
+		OutputOptions:     outputOptions,
 	}
 }
 
@@ -135,6 +141,15 @@ func (d *DebuggingSnapshotterImpl) ResponseHandler(w http.ResponseWriter, r *htt
 		d.Mutex.Lock()
 		d.DebuggingSnapshot.SetEndTimestamp(time.Now().In(time.UTC))
 		body, isErrorMessage := d.DebuggingSnapshot.GetOutputBytes()
+		body, err := d.OutputOptions.process(body)
+		if err != nil {
+			// never fall back to the unprocessed output, it may contain sensitive data
+			klog.Errorf("Unable to process the debugging snapshot output: %v", err)
+			body, isErrorMessage = []byte(err.Error()), true
+		}
+		if len(d.OutputOptions.EncryptionKey) > 0 && err == nil {
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
 		if isErrorMessage {
 			w.WriteHeader(http.StatusInternalServerError)
 		} else {
@@ -251,6 +266,17 @@ func (d *DebuggingSnapshotterImpl) SetTemplateNodes(templates map[string]*framew
 	d.DebuggingSnapshot.SetTemplateNodes(templates)
 }
 
+// SetUnremovableNodes is the setter for UnremovableNodes
+func (d *DebuggingSnapshotterImpl) SetUnremovableNodes(nodes []*UnremovableNode) {
+	d.Mutex.Lock()
+	defer d.Mutex.Unlock()
+	if !d.IsDataCollectionAllowedNoLock() {
+		return
+	}
+	klog.V(4).Infof("UnremovableNodes is being set for the debugging snapshot")
+	d.DebuggingSnapshot.SetUnremovableNodes(nodes)
+}
+
 // Cleanup clears the internal data sets of the cluster
 func (d *DebuggingSnapshotterImpl) Cleanup() {
 	if d.CancelRequest != nil {
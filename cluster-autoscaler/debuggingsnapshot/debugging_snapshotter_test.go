@@ -31,7 +31,7 @@ import (
 func TestBasicSnapshotRequest(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
-	snapshotter := NewDebuggingSnapshotter(true)
+	snapshotter := NewDebuggingSnapshotter(true, OutputOptions{})
 
 	pod := []*framework.PodInfo{
 		{
@@ -86,7 +86,7 @@ func TestBasicSnapshotRequest(t *testing.T) {
 func TestFlushWithoutData(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
-	snapshotter := NewDebuggingSnapshotter(true)
+	snapshotter := NewDebuggingSnapshotter(true, OutputOptions{})
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	w := httptest.NewRecorder()
@@ -110,7 +110,7 @@ func TestFlushWithoutData(t *testing.T) {
 func TestRequestTerminationOnShutdown(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
-	snapshotter := NewDebuggingSnapshotter(true)
+	snapshotter := NewDebuggingSnapshotter(true, OutputOptions{})
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	w := httptest.NewRecorder()
@@ -133,7 +133,7 @@ func TestRequestTerminationOnShutdown(t *testing.T) {
 func TestRejectParallelRequest(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
-	snapshotter := NewDebuggingSnapshotter(true)
+	snapshotter := NewDebuggingSnapshotter(true, OutputOptions{})
 
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
 	w := httptest.NewRecorder()
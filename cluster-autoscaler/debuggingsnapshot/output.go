@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debuggingsnapshot
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces redacted data in the snapshot output.
+const RedactedValue = "REDACTED"
+
+// OutputOptions configure how the snapshot output is processed before it's
+// returned, so that it can be enabled in environments where sensitive data
+// must not leave the cluster in plain text.
+type OutputOptions struct {
+	// RedactedFields are names of JSON fields whose values are replaced at
+	// any depth of the snapshot, e.g. "env" or "providerID".
+	RedactedFields []string
+	// RedactedPatterns are matched against all string values of the
+	// snapshot, matching parts are replaced.
+	RedactedPatterns []*regexp.Regexp
+	// EncryptionKey is an AES key (16, 24 or 32 bytes long). If set, the
+	// output is encrypted with AES-GCM and the random nonce is prepended to it.
+	EncryptionKey []byte
+}
+
+// LoadEncryptionKey reads a base64 encoded AES key from the file.
+func LoadEncryptionKey(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key in %s isn't base64 encoded: %v", path, err)
+	}
+	if _, err := aes.NewCipher(key); err != nil {
+		return nil, fmt.Errorf("invalid encryption key in %s: %v", path, err)
+	}
+	return key, nil
+}
+
+// ParseRedactedPatterns compiles the regular expressions.
+func ParseRedactedPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var result []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
+// process redacts and encrypts the marshalled snapshot according to the options.
+func (o OutputOptions) process(output []byte) ([]byte, error) {
+	if len(o.RedactedFields) > 0 || len(o.RedactedPatterns) > 0 {
+		redacted, err := o.redact(output)
+		if err != nil {
+			return nil, fmt.Errorf("unable to redact the snapshot: %v", err)
+		}
+		output = redacted
+	}
+	if len(o.EncryptionKey) > 0 {
+		encrypted, err := encrypt(output, o.EncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encrypt the snapshot: %v", err)
+		}
+		output = encrypted
+	}
+	return output, nil
+}
+
+func (o OutputOptions) redact(output []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	// Keep numbers as they are instead of converting them to floats.
+	decoder.UseNumber()
+	var snapshot interface{}
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]bool, len(o.RedactedFields))
+	for _, field := range o.RedactedFields {
+		fields[field] = true
+	}
+	return json.Marshal(o.redactValue(snapshot, fields))
+}
+
+func (o OutputOptions) redactValue(value interface{}, fields map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, fieldValue := range v {
+			if fields[key] {
+				v[key] = RedactedValue
+			} else {
+				v[key] = o.redactValue(fieldValue, fields)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = o.redactValue(v[i], fields)
+		}
+	case string:
+		for _, pattern := range o.RedactedPatterns {
+			v = pattern.ReplaceAllLiteralString(v, RedactedValue)
+		}
+		return v
+	}
+	return value
+}
+
+func encrypt(output, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, output, nil), nil
+}
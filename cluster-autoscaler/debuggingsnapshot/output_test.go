@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package debuggingsnapshot
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testSnapshotOutput(t *testing.T) []byte {
+	snapshot := &DebuggingSnapshotImpl{}
+	snapshot.SetUnscheduledPodsCanBeScheduled([]*v1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "secret-team"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:  "container",
+				Env:   []v1.EnvVar{{Name: "PASSWORD", Value: "hunter2"}},
+				Ports: []v1.ContainerPort{{ContainerPort: 8080}},
+			}},
+		},
+	}})
+	output, isError := snapshot.GetOutputBytes()
+	assert.False(t, isError)
+	return output
+}
+
+func TestRedact(t *testing.T) {
+	patterns, err := ParseRedactedPatterns([]string{"secret-[a-z]+"})
+	assert.NoError(t, err)
+	options := OutputOptions{RedactedFields: []string{"env"}, RedactedPatterns: patterns}
+
+	output, err := options.process(testSnapshotOutput(t))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(output), "hunter2")
+
+	var snapshot struct {
+		UnscheduledPodsCanBeScheduled []struct {
+			Metadata metav1.ObjectMeta `json:"metadata"`
+		}
+	}
+	assert.NoError(t, json.Unmarshal(output, &snapshot))
+	assert.Equal(t, RedactedValue, snapshot.UnscheduledPodsCanBeScheduled[0].Metadata.Namespace)
+	assert.Equal(t, "pod", snapshot.UnscheduledPodsCanBeScheduled[0].Metadata.Name)
+	assert.Contains(t, string(output), `"env":"REDACTED"`)
+	assert.Contains(t, string(output), `"containerPort":8080`)
+}
+
+func TestParseRedactedPatterns(t *testing.T) {
+	_, err := ParseRedactedPatterns([]string{"("})
+	assert.Error(t, err)
+}
+
+func TestEncrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	keyFile := filepath.Join(t.TempDir(), "key")
+	assert.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+	loadedKey, err := LoadEncryptionKey(keyFile)
+	assert.NoError(t, err)
+	assert.Equal(t, key, loadedKey)
+
+	plaintext := testSnapshotOutput(t)
+	output, err := OutputOptions{EncryptionKey: loadedKey}.process(plaintext)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(output), "hunter2")
+
+	block, err := aes.NewCipher(key)
+	assert.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	decrypted, err := gcm.Open(nil, output[:gcm.NonceSize()], output[gcm.NonceSize():], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestLoadEncryptionKeyInvalid(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	assert.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString([]byte("short"))), 0600))
+	_, err := LoadEncryptionKey(keyFile)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(keyFile, []byte("not base64!"), 0600))
+	_, err = LoadEncryptionKey(keyFile)
+	assert.Error(t, err)
+}
@@ -36,7 +36,9 @@ type BinpackingNodeEstimator struct {
 	podOrderer             EstimationPodOrderer
 	context                EstimationContext
 	estimationAnalyserFunc EstimationAnalyserFunc // optional
-
+	// numaCells is the number of NUMA cells new nodes are split into, pods with
+	// the Guaranteed QoS class have to fit in a single one. Disabled if lower than 2.
+	numaCells int
 }
 
 // estimationState contains helper variables to avoid coping them independently in each function.
@@ -46,6 +48,7 @@ type estimationState struct {
 	lastNodeName     string
 	newNodeNames     map[string]bool
 	newNodesWithPods map[string]bool
+	numaCells        map[string][]numaCell
 }
 
 // NewBinpackingNodeEstimator builds a new BinpackingNodeEstimator.
@@ -56,6 +59,7 @@ func NewBinpackingNodeEstimator(
 	podOrderer EstimationPodOrderer,
 	context EstimationContext,
 	estimationAnalyserFunc EstimationAnalyserFunc,
+	numaCells int,
 ) *BinpackingNodeEstimator {
 	return &BinpackingNodeEstimator{
 		predicateChecker:       predicateChecker,
@@ -64,6 +68,7 @@ func NewBinpackingNodeEstimator(
 		podOrderer:             podOrderer,
 		context:                context,
 		estimationAnalyserFunc: estimationAnalyserFunc,
+		numaCells:              numaCells,
 	}
 }
 
@@ -74,6 +79,7 @@ func newEstimationState() *estimationState {
 		lastNodeName:     "",
 		newNodeNames:     map[string]bool{},
 		newNodesWithPods: map[string]bool{},
+		numaCells:        map[string][]numaCell{},
 	}
 }
 
@@ -137,7 +143,7 @@ func (e *BinpackingNodeEstimator) tryToScheduleOnExistingNodes(
 
 		// Check schedulability on all nodes created during simulation
 		nodeName, err := e.predicateChecker.FitsAnyNodeMatching(e.clusterSnapshot, pod, func(nodeInfo *schedulerframework.NodeInfo) bool {
-			return estimationState.newNodeNames[nodeInfo.Node().Name]
+			return estimationState.newNodeNames[nodeInfo.Node().Name] && e.fitsNumaCell(estimationState, pod, nodeInfo.Node().Name)
 		})
 		if err != nil {
 			break
@@ -160,7 +166,7 @@ func (e *BinpackingNodeEstimator) tryToScheduleOnNewNodes(
 
 		if estimationState.lastNodeName != "" {
 			// Check schedulability on only newly created node
-			if err := e.predicateChecker.CheckPredicates(e.clusterSnapshot, pod, estimationState.lastNodeName); err == nil {
+			if err := e.predicateChecker.CheckPredicates(e.clusterSnapshot, pod, estimationState.lastNodeName); err == nil && e.fitsNumaCell(estimationState, pod, estimationState.lastNodeName) {
 				found = true
 				if err := e.tryToAddNode(estimationState, pod, estimationState.lastNodeName); err != nil {
 					return err
@@ -198,6 +204,9 @@ func (e *BinpackingNodeEstimator) tryToScheduleOnNewNodes(
 			if err := e.predicateChecker.CheckPredicates(e.clusterSnapshot, pod, estimationState.lastNodeName); err != nil {
 				break
 			}
+			if !e.fitsNumaCell(estimationState, pod, estimationState.lastNodeName) {
+				break
+			}
 			if err := e.tryToAddNode(estimationState, pod, estimationState.lastNodeName); err != nil {
 				return err
 			}
@@ -221,6 +230,9 @@ func (e *BinpackingNodeEstimator) addNewNodeToSnapshot(
 	estimationState.newNodeNameIndex++
 	estimationState.lastNodeName = newNodeInfo.Node().Name
 	estimationState.newNodeNames[estimationState.lastNodeName] = true
+	if e.numaCells > 1 {
+		estimationState.numaCells[estimationState.lastNodeName] = numaCells(newNodeInfo, e.numaCells)
+	}
 	return nil
 }
 
@@ -232,7 +244,19 @@ func (e *BinpackingNodeEstimator) tryToAddNode(
 	if err := e.clusterSnapshot.AddPod(pod, nodeName); err != nil {
 		return fmt.Errorf("Error adding pod %v.%v to node %v in ClusterSnapshot; %v", pod.Namespace, pod.Name, nodeName, err)
 	}
+	if cells, found := estimationState.numaCells[nodeName]; found {
+		reserveNumaCell(cells, pod)
+	}
 	estimationState.newNodesWithPods[nodeName] = true
 	estimationState.scheduledPods = append(estimationState.scheduledPods, pod)
 	return nil
 }
+
+// fitsNumaCell checks whether the pod fits in a single NUMA cell of a node added during estimation.
+func (e *BinpackingNodeEstimator) fitsNumaCell(estimationState *estimationState, pod *apiv1.Pod, nodeName string) bool {
+	cells, found := estimationState.numaCells[nodeName]
+	if !found {
+		return true
+	}
+	return fitsNumaCell(cells, pod)
+}
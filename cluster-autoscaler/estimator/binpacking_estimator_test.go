@@ -217,7 +217,7 @@ func TestBinpackingEstimate(t *testing.T) {
 			assert.NoError(t, err)
 			limiter := NewThresholdBasedEstimationLimiter([]Threshold{NewStaticThreshold(tc.maxNodes, time.Duration(0))})
 			processor := NewDecreasingPodOrderer()
-			estimator := NewBinpackingNodeEstimator(predicateChecker, clusterSnapshot, limiter, processor, nil /* EstimationContext */, nil /* EstimationAnalyserFunc */)
+			estimator := NewBinpackingNodeEstimator(predicateChecker, clusterSnapshot, limiter, processor, nil /* EstimationContext */, nil /* EstimationAnalyserFunc */, 0 /* numaCells */)
 			node := makeNode(tc.millicores, tc.memory, 10, "template", "zone-mars")
 			nodeInfo := schedulerframework.NewNodeInfo()
 			nodeInfo.SetNode(node)
@@ -232,6 +232,70 @@ func TestBinpackingEstimate(t *testing.T) {
 	}
 }
 
+func withGuaranteedQoS(pod *apiv1.Pod) {
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].Resources.Limits = pod.Spec.Containers[i].Resources.Requests.DeepCopy()
+	}
+}
+
+func TestBinpackingEstimateNumaCells(t *testing.T) {
+	testCases := []struct {
+		name            string
+		numaCells       int
+		pod             *apiv1.Pod
+		podCount        int
+		expectNodeCount int
+		expectPodCount  int
+	}{
+		{
+			name:            "guaranteed pods without numa cells",
+			pod:             BuildTestPod("estimatee", 1200, 100, withGuaranteedQoS),
+			podCount:        6,
+			expectNodeCount: 2,
+			expectPodCount:  6,
+		},
+		{
+			name:            "guaranteed pods fit in a single numa cell",
+			numaCells:       2,
+			pod:             BuildTestPod("estimatee", 1200, 100, withGuaranteedQoS),
+			podCount:        6,
+			expectNodeCount: 3,
+			expectPodCount:  6,
+		},
+		{
+			name:            "guaranteed pods larger than a numa cell",
+			numaCells:       2,
+			pod:             BuildTestPod("estimatee", 2500, 100, withGuaranteedQoS),
+			podCount:        2,
+			expectNodeCount: 0,
+			expectPodCount:  0,
+		},
+		{
+			name:            "burstable pods ignore numa cells",
+			numaCells:       2,
+			pod:             BuildTestPod("estimatee", 1200, 100),
+			podCount:        6,
+			expectNodeCount: 2,
+			expectPodCount:  6,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			clusterSnapshot := clustersnapshot.NewBasicClusterSnapshot()
+			predicateChecker, err := predicatechecker.NewTestPredicateChecker()
+			assert.NoError(t, err)
+			limiter := NewThresholdBasedEstimationLimiter([]Threshold{NewStaticThreshold(10, time.Duration(0))})
+			estimator := NewBinpackingNodeEstimator(predicateChecker, clusterSnapshot, limiter, NewDecreasingPodOrderer(), nil /* EstimationContext */, nil /* EstimationAnalyserFunc */, tc.numaCells)
+			nodeInfo := schedulerframework.NewNodeInfo()
+			nodeInfo.SetNode(makeNode(4000, 4000, 10, "template", "zone-mars"))
+
+			estimatedNodes, estimatedPods := estimator.Estimate([]PodEquivalenceGroup{makePodEquivalenceGroup(tc.pod, tc.podCount)}, nodeInfo, nil)
+			assert.Equal(t, tc.expectNodeCount, estimatedNodes)
+			assert.Equal(t, tc.expectPodCount, len(estimatedPods))
+		})
+	}
+}
+
 func BenchmarkBinpackingEstimate(b *testing.B) {
 	millicores := int64(1000)
 	memory := int64(5000)
@@ -272,7 +336,7 @@ func BenchmarkBinpackingEstimate(b *testing.B) {
 		assert.NoError(b, err)
 		limiter := NewThresholdBasedEstimationLimiter([]Threshold{NewStaticThreshold(maxNodes, time.Duration(0))})
 		processor := NewDecreasingPodOrderer()
-		estimator := NewBinpackingNodeEstimator(predicateChecker, clusterSnapshot, limiter, processor, nil /* EstimationContext */, nil /* EstimationAnalyserFunc */)
+		estimator := NewBinpackingNodeEstimator(predicateChecker, clusterSnapshot, limiter, processor, nil /* EstimationContext */, nil /* EstimationAnalyserFunc */, 0 /* numaCells */)
 		node := makeNode(millicores, memory, podsPerNode, "template", "zone-mars")
 		nodeInfo := schedulerframework.NewNodeInfo()
 		nodeInfo.SetNode(node)
@@ -79,6 +79,13 @@ func (d *DecreasingPodOrderer) calculatePodScore(podsEquivalentGroup PodEquivale
 			memorySum.Add(request)
 		}
 	}
+	// Pod overhead, e.g. of sandboxed RuntimeClasses, takes up node capacity as well.
+	if overhead, ok := samplePod.Spec.Overhead[apiv1.ResourceCPU]; ok {
+		cpuSum.Add(overhead)
+	}
+	if overhead, ok := samplePod.Spec.Overhead[apiv1.ResourceMemory]; ok {
+		memorySum.Add(overhead)
+	}
 	score := float64(0)
 	if cpuAllocatable, ok := nodeTemplate.Node().Status.Allocatable[apiv1.ResourceCPU]; ok && cpuAllocatable.MilliValue() > 0 {
 		score += float64(cpuSum.MilliValue()) / float64(cpuAllocatable.MilliValue())
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package estimator
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	"k8s.io/kubernetes/pkg/apis/core/v1/helper/qos"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// numaCell holds the free resources of a NUMA cell of a node added during estimation.
+type numaCell struct {
+	milliCPU int64
+	memory   int64
+}
+
+// numaCells splits the allocatable resources of the node into count equal NUMA
+// cells and reserves the resources of the pods already running on the node.
+func numaCells(nodeInfo *schedulerframework.NodeInfo, count int) []numaCell {
+	allocatable := nodeInfo.Node().Status.Allocatable
+	cells := make([]numaCell, count)
+	for i := range cells {
+		cells[i] = numaCell{
+			milliCPU: allocatable.Cpu().MilliValue() / int64(count),
+			memory:   allocatable.Memory().Value() / int64(count),
+		}
+	}
+	for _, podInfo := range nodeInfo.Pods {
+		reserveNumaCell(cells, podInfo.Pod)
+	}
+	return cells
+}
+
+// requiresSingleNumaCell returns true for pods which are aligned to a single
+// NUMA cell by the single-numa-node topology manager policy, i.e. pods with
+// the Guaranteed QoS class.
+func requiresSingleNumaCell(pod *apiv1.Pod) bool {
+	return qos.GetPodQOS(pod) == apiv1.PodQOSGuaranteed
+}
+
+// fitsNumaCell checks whether the pod fits in any of the cells.
+func fitsNumaCell(cells []numaCell, pod *apiv1.Pod) bool {
+	if !requiresSingleNumaCell(pod) {
+		return true
+	}
+	return findNumaCell(cells, pod) >= 0
+}
+
+// reserveNumaCell reserves the resources of the pod in the first cell it fits in.
+func reserveNumaCell(cells []numaCell, pod *apiv1.Pod) {
+	if !requiresSingleNumaCell(pod) {
+		return
+	}
+	if i := findNumaCell(cells, pod); i >= 0 {
+		requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+		cells[i].milliCPU -= requests.Cpu().MilliValue()
+		cells[i].memory -= requests.Memory().Value()
+	}
+}
+
+func findNumaCell(cells []numaCell, pod *apiv1.Pod) int {
+	requests := resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{})
+	for i, cell := range cells {
+		if requests.Cpu().MilliValue() <= cell.milliCPU && requests.Memory().Value() <= cell.memory {
+			return i
+		}
+	}
+	return -1
+}
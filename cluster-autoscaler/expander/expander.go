@@ -17,6 +17,11 @@ limitations under the License.
 package expander
 
 import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
@@ -24,7 +29,7 @@ import (
 
 var (
 	// AvailableExpanders is a list of available expander options
-	AvailableExpanders = []string{RandomExpanderName, MostPodsExpanderName, LeastWasteExpanderName, PriceBasedExpanderName, PriorityBasedExpanderName, GRPCExpanderName}
+	AvailableExpanders = []string{RandomExpanderName, MostPodsExpanderName, LeastWasteExpanderName, PriceBasedExpanderName, PriorityBasedExpanderName, GRPCExpanderName, FlapDampingExpanderName}
 	// RandomExpanderName selects a node group at random
 	RandomExpanderName = "random"
 	// MostPodsExpanderName selects a node group that fits the most pods
@@ -40,6 +45,8 @@ var (
 	PriorityBasedExpanderName = "priority"
 	// GRPCExpanderName uses the gRPC client expander to call to an external gRPC server to select a node group for scale up
 	GRPCExpanderName = "grpc"
+	// FlapDampingExpanderName filters out node groups of the same shape as nodes removed recently
+	FlapDampingExpanderName = "flap-damping"
 )
 
 // Option describes an option to expand the cluster.
@@ -60,3 +67,15 @@ type Strategy interface {
 type Filter interface {
 	BestOptions(options []Option, nodeInfo map[string]*schedulerframework.NodeInfo) []Option
 }
+
+// OptionHash returns a hash identifying the option by its node groups and node count, stable across loops.
+func OptionHash(option Option) string {
+	similarNodeGroups := make([]string, 0, len(option.SimilarNodeGroups))
+	for _, nodeGroup := range option.SimilarNodeGroups {
+		similarNodeGroups = append(similarNodeGroups, nodeGroup.Id())
+	}
+	sort.Strings(similarNodeGroups)
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%s/%d/%s", option.NodeGroup.Id(), option.NodeCount, strings.Join(similarNodeGroups, ","))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
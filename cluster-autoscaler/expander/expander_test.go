@@ -0,0 +1,40 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expander
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+)
+
+func TestOptionHash(t *testing.T) {
+	ng1 := testprovider.NewTestNodeGroup("ng1", 10, 0, 1, true, false, "", nil, nil)
+	ng2 := testprovider.NewTestNodeGroup("ng2", 10, 0, 1, true, false, "", nil, nil)
+	ng3 := testprovider.NewTestNodeGroup("ng3", 10, 0, 1, true, false, "", nil, nil)
+
+	option := Option{NodeGroup: ng1, NodeCount: 2, SimilarNodeGroups: []cloudprovider.NodeGroup{ng2, ng3}, Debug: "a"}
+	same := Option{NodeGroup: ng1, NodeCount: 2, SimilarNodeGroups: []cloudprovider.NodeGroup{ng3, ng2}, Debug: "b"}
+	otherCount := Option{NodeGroup: ng1, NodeCount: 3, SimilarNodeGroups: []cloudprovider.NodeGroup{ng2, ng3}}
+	otherNodeGroup := Option{NodeGroup: ng2, NodeCount: 2, SimilarNodeGroups: []cloudprovider.NodeGroup{ng1, ng3}}
+
+	assert.Equal(t, OptionHash(option), OptionHash(same))
+	assert.NotEqual(t, OptionHash(option), OptionHash(otherCount))
+	assert.NotEqual(t, OptionHash(option), OptionHash(otherNodeGroup))
+}
@@ -17,30 +17,72 @@ limitations under the License.
 package factory
 
 import (
+	"fmt"
+	"strings"
+
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 
+	klog "k8s.io/klog/v2"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 )
 
+// fallbackName is the name the fallback strategy is reported as.
+const fallbackName = "fallback"
+
 type chainStrategy struct {
 	filters  []expander.Filter
+	names    []string
 	fallback expander.Strategy
 }
 
-func newChainStrategy(filters []expander.Filter, fallback expander.Strategy) expander.Strategy {
+func newChainStrategy(filters []expander.Filter, names []string, fallback expander.Strategy) expander.Strategy {
 	return &chainStrategy{
 		filters:  filters,
+		names:    names,
 		fallback: fallback,
 	}
 }
 
 func (c *chainStrategy) BestOption(options []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) *expander.Option {
 	filteredOptions := options
-	for _, filter := range c.filters {
+	for i, filter := range c.filters {
+		consideredOptions := filteredOptions
 		filteredOptions = filter.BestOptions(filteredOptions, nodeInfo)
+		observeExpander(c.name(i), consideredOptions, filteredOptions)
 		if len(filteredOptions) == 1 {
 			return &filteredOptions[0]
 		}
 	}
-	return c.fallback.BestOption(filteredOptions, nodeInfo)
+	best := c.fallback.BestOption(filteredOptions, nodeInfo)
+	if best != nil {
+		observeExpander(fallbackName, filteredOptions, []expander.Option{*best})
+	} else {
+		observeExpander(fallbackName, filteredOptions, nil)
+	}
+	return best
+}
+
+func (c *chainStrategy) name(i int) string {
+	if i < len(c.names) {
+		return c.names[i]
+	}
+	return fmt.Sprintf("expander-%d", i)
+}
+
+// observeExpander records the options an expander of the chain considered and selected.
+func observeExpander(name string, considered, selected []expander.Option) {
+	metrics.RegisterExpanderOptions(name, len(considered), len(selected))
+	if klogV := klog.V(4); klogV.Enabled() {
+		klogV.Infof("Expander %s: considered %d options [%s], filtered out %d, selected [%s]",
+			name, len(considered), describeOptions(considered), len(considered)-len(selected), describeOptions(selected))
+	}
+}
+
+func describeOptions(options []expander.Option) string {
+	descriptions := make([]string, 0, len(options))
+	for _, option := range options {
+		descriptions = append(descriptions, fmt.Sprintf("%s:%s/%d", expander.OptionHash(option), option.NodeGroup.Id(), option.NodeCount))
+	}
+	return strings.Join(descriptions, " ")
 }
@@ -119,7 +119,7 @@ func TestChainStrategy_BestOption(t *testing.T) {
 		},
 	} {
 		t.Run(name, func(t *testing.T) {
-			subject := newChainStrategy(tc.filters, tc.fallback)
+			subject := newChainStrategy(tc.filters, nil, tc.fallback)
 			actual := subject.BestOption(tc.options, nil)
 			assert.Equal(t, tc.expected, actual)
 		})
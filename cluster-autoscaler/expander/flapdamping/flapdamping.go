@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flapdamping
+
+import (
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// Filter is a scale up filter that penalizes options creating nodes of the same
+// shape as nodes removed within the window, damping scale-up/scale-down flapping
+// of cyclic workloads. Nodes have the same shape if their node groups' templates
+// have the same instance type, or if they belong to the same node group if the
+// templates have no instance type. It observes scale-downs to track removals.
+type Filter struct {
+	window time.Duration
+	now    func() time.Time
+
+	mutex sync.Mutex
+	// removals holds the time of the last removal of a node by node group.
+	removals map[string]time.Time
+}
+
+// NewFilter returns a flap damping scale up filter penalizing options within the window.
+func NewFilter(window time.Duration) *Filter {
+	return &Filter{
+		window:   window,
+		now:      time.Now,
+		removals: make(map[string]time.Time),
+	}
+}
+
+// BestOptions filters out options of node groups whose shape was removed within the
+// window. All options are returned if none of them is left.
+func (f *Filter) BestOptions(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	penalizedShapes := f.recentlyRemovedShapes(nodeInfo)
+	if len(penalizedShapes) == 0 {
+		return expansionOptions
+	}
+
+	var bestOptions []expander.Option
+	for _, option := range expansionOptions {
+		shape := nodeShape(option.NodeGroup.Id(), nodeInfo)
+		if penalizedShapes[shape] {
+			klog.V(4).Infof("Penalizing expansion option of node group %s, nodes of shape %s were removed within %v", option.NodeGroup.Id(), shape, f.window)
+			continue
+		}
+		bestOptions = append(bestOptions, option)
+	}
+	if len(bestOptions) == 0 {
+		return expansionOptions
+	}
+	return bestOptions
+}
+
+func (f *Filter) recentlyRemovedShapes(nodeInfo map[string]*schedulerframework.NodeInfo) map[string]bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.now()
+	shapes := make(map[string]bool)
+	for nodeGroupId, removal := range f.removals {
+		if now.Sub(removal) > f.window {
+			delete(f.removals, nodeGroupId)
+			continue
+		}
+		shapes[nodeShape(nodeGroupId, nodeInfo)] = true
+	}
+	return shapes
+}
+
+// nodeShape returns the instance type of the node group's template, or the node
+// group's id if it's unknown.
+func nodeShape(nodeGroupId string, nodeInfo map[string]*schedulerframework.NodeInfo) string {
+	if template, found := nodeInfo[nodeGroupId]; found && template.Node() != nil {
+		if instanceType := template.Node().Labels[apiv1.LabelInstanceTypeStable]; instanceType != "" {
+			return instanceType
+		}
+	}
+	return nodeGroupId
+}
+
+// RegisterScaleUp is a no-op, only removals are tracked.
+func (f *Filter) RegisterScaleUp(_ cloudprovider.NodeGroup, _ int, _ time.Time) {
+}
+
+// RegisterScaleDown records the removal of a node of the node group.
+func (f *Filter) RegisterScaleDown(nodeGroup cloudprovider.NodeGroup, _ string, currentTime time.Time, _ time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.removals[nodeGroup.Id()] = currentTime
+}
+
+// RegisterFailedScaleUp is a no-op, only removals are tracked.
+func (f *Filter) RegisterFailedScaleUp(_ cloudprovider.NodeGroup, _ string, _ string, _ string, _ string, _ time.Time) {
+}
+
+// RegisterFailedScaleDown is a no-op, only removals are tracked.
+func (f *Filter) RegisterFailedScaleDown(_ cloudprovider.NodeGroup, _ string, _ time.Time) {
+}
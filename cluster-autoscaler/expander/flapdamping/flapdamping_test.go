@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flapdamping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+func TestFlapDamping(t *testing.T) {
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng-small-a", 0, 10, 1)
+	provider.AddNodeGroup("ng-small-b", 0, 10, 1)
+	provider.AddNodeGroup("ng-large", 0, 10, 1)
+	provider.AddNodeGroup("ng-untyped", 0, 10, 1)
+
+	nodeInfos := make(map[string]*schedulerframework.NodeInfo)
+	for nodeGroupId, instanceType := range map[string]string{"ng-small-a": "small", "ng-small-b": "small", "ng-large": "large", "ng-untyped": ""} {
+		node := BuildTestNode(nodeGroupId+"-template", 1000, 1000)
+		if instanceType != "" {
+			node.Labels[apiv1.LabelInstanceTypeStable] = instanceType
+		}
+		nodeInfo := schedulerframework.NewNodeInfo()
+		nodeInfo.SetNode(node)
+		nodeInfos[nodeGroupId] = nodeInfo
+	}
+
+	optionOf := func(nodeGroupId string) expander.Option {
+		return expander.Option{NodeGroup: provider.GetNodeGroup(nodeGroupId), NodeCount: 1, Debug: nodeGroupId}
+	}
+	smallA, smallB, large, untyped := optionOf("ng-small-a"), optionOf("ng-small-b"), optionOf("ng-large"), optionOf("ng-untyped")
+	options := []expander.Option{smallA, smallB, large, untyped}
+
+	now := time.Now()
+	for _, tc := range []struct {
+		name     string
+		removals map[string]time.Time
+		options  []expander.Option
+		expected []expander.Option
+	}{
+		{
+			name:     "no removals",
+			options:  options,
+			expected: options,
+		},
+		{
+			name:     "same instance type removed recently",
+			removals: map[string]time.Time{"ng-small-a": now.Add(-5 * time.Minute)},
+			options:  options,
+			expected: []expander.Option{large, untyped},
+		},
+		{
+			name:     "node group without instance type removed recently",
+			removals: map[string]time.Time{"ng-untyped": now.Add(-5 * time.Minute)},
+			options:  options,
+			expected: []expander.Option{smallA, smallB, large},
+		},
+		{
+			name:     "removed outside of the window",
+			removals: map[string]time.Time{"ng-small-a": now.Add(-time.Hour)},
+			options:  options,
+			expected: options,
+		},
+		{
+			name:     "all options penalized",
+			removals: map[string]time.Time{"ng-small-b": now.Add(-5 * time.Minute)},
+			options:  []expander.Option{smallA, smallB},
+			expected: []expander.Option{smallA, smallB},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := NewFilter(30 * time.Minute)
+			filter.now = func() time.Time { return now }
+			for nodeGroupId, removal := range tc.removals {
+				filter.RegisterScaleDown(provider.GetNodeGroup(nodeGroupId), "node", removal, removal)
+			}
+			assert.Equal(t, tc.expected, filter.BestOptions(tc.options, nodeInfos))
+		})
+	}
+}
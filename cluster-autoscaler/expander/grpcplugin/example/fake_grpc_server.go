@@ -19,6 +19,7 @@ package example
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
 
@@ -101,3 +102,24 @@ func (ServerImpl *ExpanderServerImpl) BestOptions(ctx context.Context, req *prot
 		Options: []*protos.Option{choice},
 	}, nil
 }
+
+// ScoreOptions method scores the options streamed from the gRPC Client in CA one by one, according to the defined strategy.
+// Options with the highest score are selected by the client.
+func (ServerImpl *ExpanderServerImpl) ScoreOptions(stream protos.Expander_ScoreOptionsServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		opt := req.GetOption()
+		log.Printf("Received ScoreOptions Request with option: %v", opt.GetNodeGroupId())
+
+		// This strategy simply prefers Options with longer NodeGroupID names, but can be replaced with any arbitrary logic
+		if err := stream.Send(&protos.OptionScore{NodeGroupId: opt.GetNodeGroupId(), Score: float64(len(opt.GetNodeGroupId()))}); err != nil {
+			return err
+		}
+	}
+}
@@ -18,6 +18,7 @@ package grpcplugin
 
 import (
 	"context"
+	"io"
 	"log"
 	"time"
 
@@ -38,15 +39,18 @@ const (
 
 type grpcclientstrategy struct {
 	grpcClient protos.ExpanderClient
+	// streaming tells if options are streamed to the gRPC server to be scored one by one,
+	// instead of sending all of them to the server at once to select the best ones.
+	streaming bool
 }
 
 // NewFilter returns an expansion filter that creates a gRPC client, and calls out to a gRPC server
-func NewFilter(expanderCert string, expanderUrl string) expander.Filter {
+func NewFilter(expanderCert string, expanderUrl string, streaming bool) expander.Filter {
 	client := createGRPCClient(expanderCert, expanderUrl)
 	if client == nil {
-		return &grpcclientstrategy{grpcClient: nil}
+		return &grpcclientstrategy{grpcClient: nil, streaming: streaming}
 	}
-	return &grpcclientstrategy{grpcClient: client}
+	return &grpcclientstrategy{grpcClient: client, streaming: streaming}
 }
 
 func createGRPCClient(expanderCert string, expanderUrl string) protos.ExpanderClient {
@@ -77,6 +81,9 @@ func (g *grpcclientstrategy) BestOptions(expansionOptions []expander.Option, nod
 		klog.Errorf("Incorrect gRPC client config, filtering no options")
 		return expansionOptions
 	}
+	if g.streaming {
+		return g.bestOptionsByScore(expansionOptions, nodeInfo)
+	}
 
 	// Transform inputs to gRPC inputs
 	grpcOptionsSlice, nodeGroupIDOptionMap := populateOptionsForGRPC(expansionOptions)
@@ -105,6 +112,93 @@ func (g *grpcclientstrategy) BestOptions(expansionOptions []expander.Option, nod
 	return options
 }
 
+// bestOptionsByScore streams the options, together with the template nodes of their node groups,
+// to the gRPC server one by one and returns the options with the highest score streamed back.
+// Scores are received while options are still being sent, so the server can score each option
+// as soon as it receives it.
+func (g *grpcclientstrategy) bestOptionsByScore(expansionOptions []expander.Option, nodeInfo map[string]*schedulerframework.NodeInfo) []expander.Option {
+	grpcOptionsSlice, nodeGroupIDOptionMap := populateOptionsForGRPC(expansionOptions)
+
+	klog.V(2).Infof("GPRC stream of %v options to server for scoring", len(nodeGroupIDOptionMap))
+	ctx, cancel := context.WithTimeout(context.Background(), gRPCTimeout)
+	defer cancel()
+	stream, err := g.grpcClient.ScoreOptions(ctx)
+	if err != nil {
+		klog.V(4).Infof("GRPC call failed, no options filtered: %v", err)
+		return expansionOptions
+	}
+
+	scoresCh := make(chan map[string]float64, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		scores := make(map[string]float64)
+		for {
+			score, err := stream.Recv()
+			if err == io.EOF {
+				scoresCh <- scores
+				return
+			}
+			if err != nil {
+				errCh <- err
+				return
+			}
+			scores[score.NodeGroupId] = score.Score
+		}
+	}()
+
+	for _, grpcOption := range grpcOptionsSlice {
+		request := &protos.ScoreOptionsRequest{Option: grpcOption}
+		if info, found := nodeInfo[grpcOption.NodeGroupId]; found {
+			request.Node = info.Node()
+		}
+		// If sending fails, the stream is aborted and the error is returned by Recv.
+		if err := stream.Send(request); err != nil {
+			break
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		klog.V(4).Infof("Failed to close GRPC stream: %v", err)
+	}
+
+	select {
+	case scores := <-scoresCh:
+		options := selectHighestScoredOptions(scores, nodeGroupIDOptionMap, expansionOptions)
+		if options == nil {
+			klog.V(4).Info("GRPC returned no valid scores, no options filtered")
+			return expansionOptions
+		}
+		return options
+	case err := <-errCh:
+		klog.V(4).Infof("GRPC stream failed, no options filtered: %v", err)
+		return expansionOptions
+	}
+}
+
+// selectHighestScoredOptions returns the options with the highest score, in the order they were passed in.
+func selectHighestScoredOptions(scores map[string]float64, nodeGroupIDOptionMap map[string]expander.Option, expansionOptions []expander.Option) []expander.Option {
+	for nodeGroupId := range scores {
+		if _, found := nodeGroupIDOptionMap[nodeGroupId]; !found {
+			klog.Errorf("GRPC server returned score of invalid nodeGroup ID: %s", nodeGroupId)
+			delete(scores, nodeGroupId)
+		}
+	}
+	var options []expander.Option
+	var highestScore float64
+	for _, option := range expansionOptions {
+		score, found := scores[option.NodeGroup.Id()]
+		if !found {
+			continue
+		}
+		if options == nil || score > highestScore {
+			options = []expander.Option{option}
+			highestScore = score
+		} else if score == highestScore {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
 // populateOptionsForGRPC creates a map of nodegroup ID and options, as well as a slice of Options objects for the gRPC call
 func populateOptionsForGRPC(expansionOptions []expander.Option) ([]*protos.Option, map[string]expander.Option) {
 	grpcOptionsSlice := []*protos.Option{}
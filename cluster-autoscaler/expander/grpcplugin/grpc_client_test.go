@@ -18,6 +18,7 @@ package grpcplugin
 
 import (
 	"errors"
+	"io"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -176,7 +177,7 @@ func TestBestOptionsValid(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockClient := mocks.NewMockExpanderClient(ctrl)
-	g := &grpcclientstrategy{mockClient}
+	g := &grpcclientstrategy{grpcClient: mockClient}
 
 	nodeInfos := makeFakeNodeInfos()
 	grpcNodeInfoMap := make(map[string]*v1.Node)
@@ -202,7 +203,7 @@ func TestBestOptionsErrors(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockClient := mocks.NewMockExpanderClient(ctrl)
-	g := grpcclientstrategy{mockClient}
+	g := grpcclientstrategy{grpcClient: mockClient}
 
 	badProtosOption := protos.Option{
 		NodeGroupId: "badID",
@@ -220,7 +221,7 @@ func TestBestOptionsErrors(t *testing.T) {
 	}{
 		{
 			desc:         "Bad gRPC client config",
-			client:       grpcclientstrategy{nil},
+			client:       grpcclientstrategy{grpcClient: nil},
 			nodeInfo:     makeFakeNodeInfos(),
 			mockResponse: protos.BestOptionsResponse{},
 			errResponse:  nil,
@@ -274,3 +275,84 @@ func TestBestOptionsErrors(t *testing.T) {
 		assert.Equal(t, resp, options)
 	}
 }
+
+func TestBestOptionsByScoreValid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := mocks.NewMockExpanderClient(ctrl)
+	mockStream := mocks.NewMockExpander_ScoreOptionsClient(ctrl)
+	g := &grpcclientstrategy{grpcClient: mockClient, streaming: true}
+
+	mockClient.EXPECT().ScoreOptions(gomock.Any()).Return(mockStream, nil)
+	for i, grpcOption := range []*protos.Option{&grpcEoT2Micro, &grpcEoT2Large, &grpcEoT3Large, &grpcEoM44XLarge} {
+		mockStream.EXPECT().Send(gomock.Eq(&protos.ScoreOptionsRequest{Option: grpcOption, Node: nodes[i]})).Return(nil)
+	}
+	mockStream.EXPECT().CloseSend().Return(nil)
+	gomock.InOrder(
+		mockStream.EXPECT().Recv().Return(&protos.OptionScore{NodeGroupId: eoT2Micro.NodeGroup.Id(), Score: 1}, nil),
+		mockStream.EXPECT().Recv().Return(&protos.OptionScore{NodeGroupId: eoT3Large.NodeGroup.Id(), Score: 3}, nil),
+		mockStream.EXPECT().Recv().Return(&protos.OptionScore{NodeGroupId: "badID", Score: 5}, nil),
+		mockStream.EXPECT().Recv().Return(&protos.OptionScore{NodeGroupId: eoM44XLarge.NodeGroup.Id(), Score: 3}, nil),
+		mockStream.EXPECT().Recv().Return(nil, io.EOF),
+	)
+
+	resp := g.BestOptions(options, makeFakeNodeInfos())
+
+	assert.Equal(t, []expander.Option{eoT3Large, eoM44XLarge}, resp)
+}
+
+// All test cases should error, and no options should be filtered
+func TestBestOptionsByScoreErrors(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		streamErr error
+		scores    []*protos.OptionScore
+		recvErr   error
+	}{
+		{
+			desc:      "gRPC error opening stream",
+			streamErr: errors.New("connection error"),
+		},
+		{
+			desc:    "gRPC error receiving scores",
+			scores:  []*protos.OptionScore{{NodeGroupId: eoT2Micro.NodeGroup.Id(), Score: 1}},
+			recvErr: errors.New("timeout error"),
+		},
+		{
+			desc:    "no scores",
+			recvErr: io.EOF,
+		},
+		{
+			desc:    "scores of nonExistent nodeIDs only",
+			scores:  []*protos.OptionScore{{NodeGroupId: "badID", Score: 1}},
+			recvErr: io.EOF,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockClient := mocks.NewMockExpanderClient(ctrl)
+			g := &grpcclientstrategy{grpcClient: mockClient, streaming: true}
+
+			if tc.streamErr != nil {
+				mockClient.EXPECT().ScoreOptions(gomock.Any()).Return(nil, tc.streamErr)
+			} else {
+				mockStream := mocks.NewMockExpander_ScoreOptionsClient(ctrl)
+				mockClient.EXPECT().ScoreOptions(gomock.Any()).Return(mockStream, nil)
+				mockStream.EXPECT().Send(gomock.Any()).Return(nil).AnyTimes()
+				mockStream.EXPECT().CloseSend().Return(nil)
+				var calls []*gomock.Call
+				for _, score := range tc.scores {
+					calls = append(calls, mockStream.EXPECT().Recv().Return(score, nil))
+				}
+				calls = append(calls, mockStream.EXPECT().Recv().Return(nil, tc.recvErr))
+				gomock.InOrder(calls...)
+			}
+
+			resp := g.BestOptions(options, makeFakeNodeInfos())
+
+			assert.Equal(t, options, resp)
+		})
+	}
+}
@@ -211,6 +211,118 @@ func (x *Option) GetPod() []*v1.Pod {
 	return nil
 }
 
+type ScoreOptionsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Option *Option `protobuf:"bytes,1,opt,name=option,proto3" json:"option,omitempty"`
+	// node is the node template of the option's node group.
+	Node *v1.Node `protobuf:"bytes,2,opt,name=node,proto3" json:"node,omitempty"`
+}
+
+func (x *ScoreOptionsRequest) Reset() {
+	*x = ScoreOptionsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScoreOptionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreOptionsRequest) ProtoMessage() {}
+
+func (x *ScoreOptionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreOptionsRequest.ProtoReflect.Descriptor instead.
+func (*ScoreOptionsRequest) Descriptor() ([]byte, []int) {
+	return file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScoreOptionsRequest) GetOption() *Option {
+	if x != nil {
+		return x.Option
+	}
+	return nil
+}
+
+func (x *ScoreOptionsRequest) GetNode() *v1.Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+type OptionScore struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeGroupId string `protobuf:"bytes,1,opt,name=nodeGroupId,proto3" json:"nodeGroupId,omitempty"`
+	// options with the highest score are selected.
+	Score float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+}
+
+func (x *OptionScore) Reset() {
+	*x = OptionScore{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OptionScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OptionScore) ProtoMessage() {}
+
+func (x *OptionScore) ProtoReflect() protoreflect.Message {
+	mi := &file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OptionScore.ProtoReflect.Descriptor instead.
+func (*OptionScore) Descriptor() ([]byte, []int) {
+	return file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OptionScore) GetNodeGroupId() string {
+	if x != nil {
+		return x.NodeGroupId
+	}
+	return ""
+}
+
+func (x *OptionScore) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
 var File_cluster_autoscaler_expander_grpcplugin_protos_expander_proto protoreflect.FileDescriptor
 
 var file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_rawDesc = []byte{
@@ -248,16 +360,33 @@ var file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_rawDesc =
 	0x05, 0x64, 0x65, 0x62, 0x75, 0x67, 0x12, 0x29, 0x0a, 0x03, 0x70, 0x6f, 0x64, 0x18, 0x04, 0x20,
 	0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69,
 	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x03, 0x70, 0x6f,
-	0x64, 0x32, 0x5c, 0x0a, 0x08, 0x45, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x50, 0x0a,
-	0x0b, 0x42, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x2e, 0x67,
-	0x72, 0x70, 0x63, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x42, 0x65, 0x73, 0x74, 0x4f, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67,
-	0x72, 0x70, 0x63, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x42, 0x65, 0x73, 0x74, 0x4f, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42,
-	0x2f, 0x5a, 0x2d, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2d, 0x61, 0x75, 0x74, 0x6f, 0x73,
-	0x63, 0x61, 0x6c, 0x65, 0x72, 0x2f, 0x65, 0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x72, 0x2f, 0x67,
-	0x72, 0x70, 0x63, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x64, 0x22, 0x6f, 0x0a, 0x13, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x06, 0x6f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x70,
+	0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f,
+	0x64, 0x65, 0x22, 0x45, 0x0a, 0x0b, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x63, 0x6f, 0x72,
+	0x65, 0x12, 0x20, 0x0a, 0x0b, 0x6e, 0x6f, 0x64, 0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6e, 0x6f, 0x64, 0x65, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x32, 0xac, 0x01, 0x0a, 0x08, 0x45, 0x78,
+	0x70, 0x61, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x50, 0x0a, 0x0b, 0x42, 0x65, 0x73, 0x74, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x70, 0x6c, 0x75, 0x67,
+	0x69, 0x6e, 0x2e, 0x42, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x70, 0x6c, 0x75, 0x67,
+	0x69, 0x6e, 0x2e, 0x42, 0x65, 0x73, 0x74, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0c, 0x53, 0x63, 0x6f, 0x72,
+	0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1f, 0x2e, 0x67, 0x72, 0x70, 0x63, 0x70,
+	0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x72, 0x70, 0x63,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x63, 0x6f,
+	0x72, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x2f, 0x5a, 0x2d, 0x63, 0x6c, 0x75, 0x73,
+	0x74, 0x65, 0x72, 0x2d, 0x61, 0x75, 0x74, 0x6f, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x72, 0x2f, 0x65,
+	0x78, 0x70, 0x61, 0x6e, 0x64, 0x65, 0x72, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x70, 0x6c, 0x75, 0x67,
+	0x69, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 }
 
 var (
@@ -272,28 +401,34 @@ func file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_rawDescGZ
 	return file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_rawDescData
 }
 
-var file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_goTypes = []interface{}{
 	(*BestOptionsRequest)(nil),  // 0: grpcplugin.BestOptionsRequest
 	(*BestOptionsResponse)(nil), // 1: grpcplugin.BestOptionsResponse
 	(*Option)(nil),              // 2: grpcplugin.Option
-	nil,                         // 3: grpcplugin.BestOptionsRequest.NodeMapEntry
-	(*v1.Pod)(nil),              // 4: k8s.io.api.core.v1.Pod
-	(*v1.Node)(nil),             // 5: k8s.io.api.core.v1.Node
+	(*ScoreOptionsRequest)(nil), // 3: grpcplugin.ScoreOptionsRequest
+	(*OptionScore)(nil),         // 4: grpcplugin.OptionScore
+	nil,                         // 5: grpcplugin.BestOptionsRequest.NodeMapEntry
+	(*v1.Pod)(nil),              // 6: k8s.io.api.core.v1.Pod
+	(*v1.Node)(nil),             // 7: k8s.io.api.core.v1.Node
 }
 var file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_depIdxs = []int32{
 	2, // 0: grpcplugin.BestOptionsRequest.options:type_name -> grpcplugin.Option
-	3, // 1: grpcplugin.BestOptionsRequest.nodeMap:type_name -> grpcplugin.BestOptionsRequest.NodeMapEntry
+	5, // 1: grpcplugin.BestOptionsRequest.nodeMap:type_name -> grpcplugin.BestOptionsRequest.NodeMapEntry
 	2, // 2: grpcplugin.BestOptionsResponse.options:type_name -> grpcplugin.Option
-	4, // 3: grpcplugin.Option.pod:type_name -> k8s.io.api.core.v1.Pod
-	5, // 4: grpcplugin.BestOptionsRequest.NodeMapEntry.value:type_name -> k8s.io.api.core.v1.Node
-	0, // 5: grpcplugin.Expander.BestOptions:input_type -> grpcplugin.BestOptionsRequest
-	1, // 6: grpcplugin.Expander.BestOptions:output_type -> grpcplugin.BestOptionsResponse
-	6, // [6:7] is the sub-list for method output_type
-	5, // [5:6] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+	6, // 3: grpcplugin.Option.pod:type_name -> k8s.io.api.core.v1.Pod
+	2, // 4: grpcplugin.ScoreOptionsRequest.option:type_name -> grpcplugin.Option
+	7, // 5: grpcplugin.ScoreOptionsRequest.node:type_name -> k8s.io.api.core.v1.Node
+	7, // 6: grpcplugin.BestOptionsRequest.NodeMapEntry.value:type_name -> k8s.io.api.core.v1.Node
+	0, // 7: grpcplugin.Expander.BestOptions:input_type -> grpcplugin.BestOptionsRequest
+	3, // 8: grpcplugin.Expander.ScoreOptions:input_type -> grpcplugin.ScoreOptionsRequest
+	1, // 9: grpcplugin.Expander.BestOptions:output_type -> grpcplugin.BestOptionsResponse
+	4, // 10: grpcplugin.Expander.ScoreOptions:output_type -> grpcplugin.OptionScore
+	9, // [9:11] is the sub-list for method output_type
+	7, // [7:9] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_init() }
@@ -338,6 +473,30 @@ func file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_init() {
 				return nil
 			}
 		}
+		file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScoreOptionsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OptionScore); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -345,7 +504,7 @@ func file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_cluster_autoscaler_expander_grpcplugin_protos_expander_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -372,6 +531,7 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type ExpanderClient interface {
 	BestOptions(ctx context.Context, in *BestOptionsRequest, opts ...grpc.CallOption) (*BestOptionsResponse, error)
+	ScoreOptions(ctx context.Context, opts ...grpc.CallOption) (Expander_ScoreOptionsClient, error)
 }
 
 type expanderClient struct {
@@ -391,9 +551,41 @@ func (c *expanderClient) BestOptions(ctx context.Context, in *BestOptionsRequest
 	return out, nil
 }
 
+func (c *expanderClient) ScoreOptions(ctx context.Context, opts ...grpc.CallOption) (Expander_ScoreOptionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Expander_serviceDesc.Streams[0], "/grpcplugin.Expander/ScoreOptions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &expanderScoreOptionsClient{stream}
+	return x, nil
+}
+
+type Expander_ScoreOptionsClient interface {
+	Send(*ScoreOptionsRequest) error
+	Recv() (*OptionScore, error)
+	grpc.ClientStream
+}
+
+type expanderScoreOptionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *expanderScoreOptionsClient) Send(m *ScoreOptionsRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *expanderScoreOptionsClient) Recv() (*OptionScore, error) {
+	m := new(OptionScore)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ExpanderServer is the server API for Expander service.
 type ExpanderServer interface {
 	BestOptions(context.Context, *BestOptionsRequest) (*BestOptionsResponse, error)
+	ScoreOptions(Expander_ScoreOptionsServer) error
 }
 
 // UnimplementedExpanderServer can be embedded to have forward compatible implementations.
@@ -403,6 +595,9 @@ type UnimplementedExpanderServer struct {
 func (*UnimplementedExpanderServer) BestOptions(context.Context, *BestOptionsRequest) (*BestOptionsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BestOptions not implemented")
 }
+func (*UnimplementedExpanderServer) ScoreOptions(Expander_ScoreOptionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ScoreOptions not implemented")
+}
 
 func RegisterExpanderServer(s *grpc.Server, srv ExpanderServer) {
 	s.RegisterService(&_Expander_serviceDesc, srv)
@@ -426,6 +621,32 @@ func _Expander_BestOptions_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Expander_ScoreOptions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ExpanderServer).ScoreOptions(&expanderScoreOptionsServer{stream})
+}
+
+type Expander_ScoreOptionsServer interface {
+	Send(*OptionScore) error
+	Recv() (*ScoreOptionsRequest, error)
+	grpc.ServerStream
+}
+
+type expanderScoreOptionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *expanderScoreOptionsServer) Send(m *OptionScore) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *expanderScoreOptionsServer) Recv() (*ScoreOptionsRequest, error) {
+	m := new(ScoreOptionsRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _Expander_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "grpcplugin.Expander",
 	HandlerType: (*ExpanderServer)(nil),
@@ -435,6 +656,13 @@ var _Expander_serviceDesc = grpc.ServiceDesc{
 			Handler:    _Expander_BestOptions_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ScoreOptions",
+			Handler:       _Expander_ScoreOptions_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
 	Metadata: "cluster-autoscaler/expander/grpcplugin/protos/expander.proto",
 }
@@ -22,6 +22,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	grpc "google.golang.org/grpc"
+	metadata "google.golang.org/grpc/metadata"
 	"k8s.io/autoscaler/cluster-autoscaler/expander/grpcplugin/protos"
 )
 
@@ -68,6 +69,163 @@ func (mr *MockExpanderClientMockRecorder) BestOptions(ctx, in interface{}, opts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BestOptions", reflect.TypeOf((*MockExpanderClient)(nil).BestOptions), varargs...)
 }
 
+// ScoreOptions mocks base method.
+func (m *MockExpanderClient) ScoreOptions(ctx context.Context, opts ...grpc.CallOption) (protos.Expander_ScoreOptionsClient, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ScoreOptions", varargs...)
+	ret0, _ := ret[0].(protos.Expander_ScoreOptionsClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScoreOptions indicates an expected call of ScoreOptions.
+func (mr *MockExpanderClientMockRecorder) ScoreOptions(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScoreOptions", reflect.TypeOf((*MockExpanderClient)(nil).ScoreOptions), varargs...)
+}
+
+// MockExpander_ScoreOptionsClient is a mock of Expander_ScoreOptionsClient interface.
+type MockExpander_ScoreOptionsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpander_ScoreOptionsClientMockRecorder
+}
+
+// MockExpander_ScoreOptionsClientMockRecorder is the mock recorder for MockExpander_ScoreOptionsClient.
+type MockExpander_ScoreOptionsClientMockRecorder struct {
+	mock *MockExpander_ScoreOptionsClient
+}
+
+// NewMockExpander_ScoreOptionsClient creates a new mock instance.
+func NewMockExpander_ScoreOptionsClient(ctrl *gomock.Controller) *MockExpander_ScoreOptionsClient {
+	mock := &MockExpander_ScoreOptionsClient{ctrl: ctrl}
+	mock.recorder = &MockExpander_ScoreOptionsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpander_ScoreOptionsClient) EXPECT() *MockExpander_ScoreOptionsClientMockRecorder {
+	return m.recorder
+}
+
+// CloseSend mocks base method.
+func (m *MockExpander_ScoreOptionsClient) CloseSend() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseSend")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CloseSend indicates an expected call of CloseSend.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) CloseSend() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseSend", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).CloseSend))
+}
+
+// Context mocks base method.
+func (m *MockExpander_ScoreOptionsClient) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).Context))
+}
+
+// Header mocks base method.
+func (m *MockExpander_ScoreOptionsClient) Header() (metadata.MD, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Header")
+	ret0, _ := ret[0].(metadata.MD)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Header indicates an expected call of Header.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) Header() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Header", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).Header))
+}
+
+// Recv mocks base method.
+func (m *MockExpander_ScoreOptionsClient) Recv() (*protos.OptionScore, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*protos.OptionScore)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockExpander_ScoreOptionsClient) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockExpander_ScoreOptionsClient) Send(arg0 *protos.ScoreOptionsRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).Send), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockExpander_ScoreOptionsClient) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).SendMsg), m)
+}
+
+// Trailer mocks base method.
+func (m *MockExpander_ScoreOptionsClient) Trailer() metadata.MD {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trailer")
+	ret0, _ := ret[0].(metadata.MD)
+	return ret0
+}
+
+// Trailer indicates an expected call of Trailer.
+func (mr *MockExpander_ScoreOptionsClientMockRecorder) Trailer() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trailer", reflect.TypeOf((*MockExpander_ScoreOptionsClient)(nil).Trailer))
+}
+
 // MockExpanderServer is a mock of ExpanderServer interface.
 type MockExpanderServer struct {
 	ctrl     *gomock.Controller
@@ -105,3 +263,151 @@ func (mr *MockExpanderServerMockRecorder) BestOptions(arg0, arg1 interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BestOptions", reflect.TypeOf((*MockExpanderServer)(nil).BestOptions), arg0, arg1)
 }
+
+// ScoreOptions mocks base method.
+func (m *MockExpanderServer) ScoreOptions(arg0 protos.Expander_ScoreOptionsServer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScoreOptions", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScoreOptions indicates an expected call of ScoreOptions.
+func (mr *MockExpanderServerMockRecorder) ScoreOptions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScoreOptions", reflect.TypeOf((*MockExpanderServer)(nil).ScoreOptions), arg0)
+}
+
+// MockExpander_ScoreOptionsServer is a mock of Expander_ScoreOptionsServer interface.
+type MockExpander_ScoreOptionsServer struct {
+	ctrl     *gomock.Controller
+	recorder *MockExpander_ScoreOptionsServerMockRecorder
+}
+
+// MockExpander_ScoreOptionsServerMockRecorder is the mock recorder for MockExpander_ScoreOptionsServer.
+type MockExpander_ScoreOptionsServerMockRecorder struct {
+	mock *MockExpander_ScoreOptionsServer
+}
+
+// NewMockExpander_ScoreOptionsServer creates a new mock instance.
+func NewMockExpander_ScoreOptionsServer(ctrl *gomock.Controller) *MockExpander_ScoreOptionsServer {
+	mock := &MockExpander_ScoreOptionsServer{ctrl: ctrl}
+	mock.recorder = &MockExpander_ScoreOptionsServerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExpander_ScoreOptionsServer) EXPECT() *MockExpander_ScoreOptionsServerMockRecorder {
+	return m.recorder
+}
+
+// Context mocks base method.
+func (m *MockExpander_ScoreOptionsServer) Context() context.Context {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Context")
+	ret0, _ := ret[0].(context.Context)
+	return ret0
+}
+
+// Context indicates an expected call of Context.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) Context() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Context", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).Context))
+}
+
+// Recv mocks base method.
+func (m *MockExpander_ScoreOptionsServer) Recv() (*protos.ScoreOptionsRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(*protos.ScoreOptionsRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).Recv))
+}
+
+// RecvMsg mocks base method.
+func (m_2 *MockExpander_ScoreOptionsServer) RecvMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "RecvMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecvMsg indicates an expected call of RecvMsg.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) RecvMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecvMsg", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).RecvMsg), m)
+}
+
+// Send mocks base method.
+func (m *MockExpander_ScoreOptionsServer) Send(arg0 *protos.OptionScore) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) Send(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).Send), arg0)
+}
+
+// SendHeader mocks base method.
+func (m *MockExpander_ScoreOptionsServer) SendHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendHeader indicates an expected call of SendHeader.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) SendHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHeader", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).SendHeader), arg0)
+}
+
+// SendMsg mocks base method.
+func (m_2 *MockExpander_ScoreOptionsServer) SendMsg(m any) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "SendMsg", m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendMsg indicates an expected call of SendMsg.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) SendMsg(m interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMsg", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).SendMsg), m)
+}
+
+// SetHeader mocks base method.
+func (m *MockExpander_ScoreOptionsServer) SetHeader(arg0 metadata.MD) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetHeader", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetHeader indicates an expected call of SetHeader.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) SetHeader(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetHeader", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).SetHeader), arg0)
+}
+
+// SetTrailer mocks base method.
+func (m *MockExpander_ScoreOptionsServer) SetTrailer(arg0 metadata.MD) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetTrailer", arg0)
+}
+
+// SetTrailer indicates an expected call of SetTrailer.
+func (mr *MockExpander_ScoreOptionsServerMockRecorder) SetTrailer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrailer", reflect.TypeOf((*MockExpander_ScoreOptionsServer)(nil).SetTrailer), arg0)
+}
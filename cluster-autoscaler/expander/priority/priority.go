@@ -24,6 +24,7 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 
 	apiv1 "k8s.io/api/core/v1"
 	v1lister "k8s.io/client-go/listers/core/v1"
@@ -39,6 +40,31 @@ const (
 	ConfigMapKey = "priorities"
 )
 
+// FallbackStrategy defines how expansion options not matching any priority are handled.
+type FallbackStrategy string
+
+const (
+	// LowestPriorityFallback treats unmatched options as having a lower
+	// priority than any configured one, i.e. they are only used if no option
+	// matches a priority.
+	LowestPriorityFallback FallbackStrategy = "lowest"
+	// ExcludeFallback never uses unmatched options. If no option matches a
+	// priority, none is returned.
+	ExcludeFallback FallbackStrategy = "exclude"
+	// ErrorFallback treats unmatched options as a configuration error and
+	// returns no options at all until the configuration covers them.
+	ErrorFallback FallbackStrategy = "error"
+)
+
+// ParseFallbackStrategy returns the FallbackStrategy of the given name.
+func ParseFallbackStrategy(name string) (FallbackStrategy, error) {
+	switch strategy := FallbackStrategy(name); strategy {
+	case LowestPriorityFallback, ExcludeFallback, ErrorFallback:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown priority expander fallback strategy %q, expected one of %s, %s, %s", name, LowestPriorityFallback, ExcludeFallback, ErrorFallback)
+}
+
 type priorities map[int][]*regexp.Regexp
 
 type priority struct {
@@ -46,14 +72,16 @@ type priority struct {
 	okConfigUpdates  int
 	badConfigUpdates int
 	configMapLister  v1lister.ConfigMapNamespaceLister
+	fallback         FallbackStrategy
 }
 
 // NewFilter returns an expansion filter that picks node groups based on user-defined priorities
 func NewFilter(configMapLister v1lister.ConfigMapNamespaceLister,
-	logRecorder record.EventRecorder) expander.Filter {
+	logRecorder record.EventRecorder, fallback FallbackStrategy) expander.Filter {
 	res := &priority{
 		logRecorder:     logRecorder,
 		configMapLister: configMapLister,
+		fallback:        fallback,
 	}
 	return res
 }
@@ -128,6 +156,7 @@ func (p *priority) BestOptions(expansionOptions []expander.Option, nodeInfo map[
 
 	maxPrio := -1
 	best := []expander.Option{}
+	unmatched := 0
 	for _, option := range expansionOptions {
 		id := option.NodeGroup.Id()
 		found := false
@@ -147,13 +176,27 @@ func (p *priority) BestOptions(expansionOptions []expander.Option, nodeInfo map[
 
 		}
 		if !found {
+			unmatched++
 			msg := fmt.Sprintf("Priority expander: node group %s not found in priority expander configuration. "+
 				"The group won't be used.", id)
 			p.logConfigWarning(cm, "PriorityConfigMapNotMatchedGroup", msg)
 		}
 	}
+	metrics.RegisterPriorityExpanderUnmatchedOptions(unmatched)
+
+	if unmatched > 0 && p.fallback == ErrorFallback {
+		msg := fmt.Sprintf("Priority expander: %d expansion options not found in priority expander configuration. "+
+			"No options will be used.", unmatched)
+		p.logConfigWarning(cm, "PriorityConfigMapNotMatchedGroup", msg)
+		return nil
+	}
 
 	if len(best) == 0 {
+		if p.fallback == ExcludeFallback {
+			msg := "Priority expander: no priorities info found for any of the expansion options. No options will be used."
+			p.logConfigWarning(cm, "PriorityConfigMapNoGroupMatched", msg)
+			return nil
+		}
 		msg := "Priority expander: no priorities info found for any of the expansion options. No options filtered."
 		p.logConfigWarning(cm, "PriorityConfigMapNoGroupMatched", msg)
 		return expansionOptions
@@ -88,6 +88,10 @@ var (
 )
 
 func getFilterInstance(t *testing.T, config string) (expander.Filter, *record.FakeRecorder, *apiv1.ConfigMap) {
+	return getFilterInstanceWithFallback(t, config, LowestPriorityFallback)
+}
+
+func getFilterInstanceWithFallback(t *testing.T, config string, fallback FallbackStrategy) (expander.Filter, *record.FakeRecorder, *apiv1.ConfigMap) {
 	cm := &apiv1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: testNamespace,
@@ -100,7 +104,7 @@ func getFilterInstance(t *testing.T, config string) (expander.Filter, *record.Fa
 	lister, err := kubernetes.NewTestConfigMapLister([]*apiv1.ConfigMap{cm})
 	assert.Nil(t, err)
 	r := record.NewFakeRecorder(100)
-	s := NewFilter(lister.ConfigMaps(testNamespace), r)
+	s := NewFilter(lister.ConfigMaps(testNamespace), r, fallback)
 	return s, r, cm
 }
 
@@ -167,3 +171,65 @@ func TestPriorityExpanderCorrecltySkipsBadChangeConfig(t *testing.T) {
 	assert.EqualValues(t, configWarnConfigMapEmpty, event)
 	assert.Equal(t, ret, []expander.Option{eoT2Large, eoT3Large, eoM44XLarge})
 }
+
+func TestPriorityExpanderFallbackStrategies(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fallback FallbackStrategy
+		options  []expander.Option
+		want     []expander.Option
+	}{
+		{
+			name:     "lowest, some options matched",
+			fallback: LowestPriorityFallback,
+			options:  []expander.Option{eoT2Large, eoT3Large, eoM44XLarge},
+			want:     []expander.Option{eoT2Large},
+		},
+		{
+			name:     "lowest, no options matched",
+			fallback: LowestPriorityFallback,
+			options:  []expander.Option{eoT3Large, eoM44XLarge},
+			want:     []expander.Option{eoT3Large, eoM44XLarge},
+		},
+		{
+			name:     "exclude, some options matched",
+			fallback: ExcludeFallback,
+			options:  []expander.Option{eoT2Large, eoT3Large, eoM44XLarge},
+			want:     []expander.Option{eoT2Large},
+		},
+		{
+			name:     "exclude, no options matched",
+			fallback: ExcludeFallback,
+			options:  []expander.Option{eoT3Large, eoM44XLarge},
+			want:     nil,
+		},
+		{
+			name:     "error, all options matched",
+			fallback: ErrorFallback,
+			options:  []expander.Option{eoT2Large},
+			want:     []expander.Option{eoT2Large},
+		},
+		{
+			name:     "error, some options matched",
+			fallback: ErrorFallback,
+			options:  []expander.Option{eoT2Large, eoT3Large},
+			want:     nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, _, _ := getFilterInstanceWithFallback(t, oneEntryConfig, tc.fallback)
+			assert.Equal(t, tc.want, s.BestOptions(tc.options, nil))
+		})
+	}
+}
+
+func TestParseFallbackStrategy(t *testing.T) {
+	for _, name := range []string{"lowest", "exclude", "error"} {
+		strategy, err := ParseFallbackStrategy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, FallbackStrategy(name), strategy)
+	}
+	_, err := ParseFallbackStrategy("highest")
+	assert.Error(t, err)
+}
@@ -81,6 +81,12 @@ func resourcesForPods(pods []*apiv1.Pod) (cpu resource.Quantity, memory resource
 				memory.Add(request)
 			}
 		}
+		if overhead, ok := pod.Spec.Overhead[apiv1.ResourceCPU]; ok {
+			cpu.Add(overhead)
+		}
+		if overhead, ok := pod.Spec.Overhead[apiv1.ResourceMemory]; ok {
+			memory.Add(overhead)
+		}
 	}
 
 	return cpu, memory
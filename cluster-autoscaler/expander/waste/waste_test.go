@@ -125,3 +125,16 @@ func TestLeastWaste(t *testing.T) {
 	ret = e.BestOptions([]expander.Option{balancedOption, highmemOption, lowcpuOption}, nodeMap)
 	assert.Equal(t, ret, []expander.Option{lowcpuOption})
 }
+
+func TestResourcesForPodsWithOverhead(t *testing.T) {
+	pod := BuildTestPod("p1", 500, 1000)
+	podWithOverhead := BuildTestPod("p2", 500, 1000)
+	podWithOverhead.Spec.Overhead = apiv1.ResourceList{
+		apiv1.ResourceCPU:    *resource.NewMilliQuantity(250, resource.DecimalSI),
+		apiv1.ResourceMemory: *resource.NewQuantity(500, resource.DecimalSI),
+	}
+
+	cpu, memory := resourcesForPods([]*apiv1.Pod{pod, podWithOverhead})
+	assert.Equal(t, int64(1250), cpu.MilliValue())
+	assert.Equal(t, int64(2500), memory.Value())
+}
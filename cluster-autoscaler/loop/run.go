@@ -28,8 +28,8 @@ type autoscaler interface {
 	RunOnce(currentTime time.Time) errors.AutoscalerError
 }
 
-// RunAutoscalerOnce triggers a single autoscaling iteration.
-func RunAutoscalerOnce(autoscaler autoscaler, healthCheck *metrics.HealthCheck, loopStart time.Time) {
+// RunAutoscalerOnce triggers a single autoscaling iteration and returns its error, if any.
+func RunAutoscalerOnce(autoscaler autoscaler, healthCheck *metrics.HealthCheck, loopStart time.Time) errors.AutoscalerError {
 	metrics.UpdateLastTime(metrics.Main, loopStart)
 	healthCheck.UpdateLastActivity(loopStart)
 
@@ -41,4 +41,5 @@ func RunAutoscalerOnce(autoscaler autoscaler, healthCheck *metrics.HealthCheck,
 	}
 
 	metrics.UpdateDurationFromStart(metrics.Main, loopStart)
+	return err
 }
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loop
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// activityGetter exposes whether the autoscaler has work in flight
+type activityGetter interface {
+	IsActive() bool
+}
+
+// ScanInterval provides the interval between autoscaling iterations.
+type ScanInterval interface {
+	// Next returns the interval to wait before the next iteration.
+	Next() time.Duration
+}
+
+// FixedScanInterval is a ScanInterval which never changes.
+type FixedScanInterval time.Duration
+
+// Next returns the fixed interval.
+func (i FixedScanInterval) Next() time.Duration {
+	return time.Duration(i)
+}
+
+// AdaptiveScanInterval is a ScanInterval which shortens to its minimum while
+// the autoscaler is active, i.e. there are unschedulable pods or scaling is in
+// flight, and doubles after each idle iteration up to its maximum, reducing
+// CPU and API load of quiet clusters.
+type AdaptiveScanInterval struct {
+	activityGetter activityGetter
+	min            time.Duration
+	max            time.Duration
+	current        time.Duration
+}
+
+// NewAdaptiveScanInterval creates an AdaptiveScanInterval between min and max.
+func NewAdaptiveScanInterval(activityGetter activityGetter, min, max time.Duration) *AdaptiveScanInterval {
+	if max < min {
+		max = min
+	}
+	return &AdaptiveScanInterval{
+		activityGetter: activityGetter,
+		min:            min,
+		max:            max,
+		current:        min,
+	}
+}
+
+// Next returns the minimum interval if the autoscaler is active, and the
+// previous interval doubled, capped at the maximum, otherwise.
+func (i *AdaptiveScanInterval) Next() time.Duration {
+	previous := i.current
+	if i.activityGetter.IsActive() {
+		i.current = i.min
+	} else {
+		i.current = min(2*i.current, i.max)
+	}
+	if i.current != previous {
+		klog.V(4).Infof("Scan interval changed from %v to %v", previous, i.current)
+	}
+	return i.current
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeActivityGetter struct {
+	active bool
+}
+
+func (f *fakeActivityGetter) IsActive() bool {
+	return f.active
+}
+
+func TestAdaptiveScanInterval(t *testing.T) {
+	activity := &fakeActivityGetter{}
+	interval := NewAdaptiveScanInterval(activity, 10*time.Second, time.Minute)
+
+	assert.Equal(t, 20*time.Second, interval.Next())
+	assert.Equal(t, 40*time.Second, interval.Next())
+	assert.Equal(t, time.Minute, interval.Next())
+	assert.Equal(t, time.Minute, interval.Next())
+
+	activity.active = true
+	assert.Equal(t, 10*time.Second, interval.Next())
+	assert.Equal(t, 10*time.Second, interval.Next())
+
+	activity.active = false
+	assert.Equal(t, 20*time.Second, interval.Next())
+}
+
+func TestFixedScanInterval(t *testing.T) {
+	assert.Equal(t, 10*time.Second, FixedScanInterval(10*time.Second).Next())
+}
@@ -46,12 +46,12 @@ type scalingTimesGetter interface {
 // LoopTrigger object implements criteria used to start new autoscaling iteration
 type LoopTrigger struct {
 	podObserver        *UnschedulablePodObserver
-	scanInterval       time.Duration
+	scanInterval       ScanInterval
 	scalingTimesGetter scalingTimesGetter
 }
 
 // NewLoopTrigger creates a LoopTrigger object
-func NewLoopTrigger(podObserver *UnschedulablePodObserver, scalingTimesGetter scalingTimesGetter, scanInterval time.Duration) *LoopTrigger {
+func NewLoopTrigger(podObserver *UnschedulablePodObserver, scalingTimesGetter scalingTimesGetter, scanInterval ScanInterval) *LoopTrigger {
 	return &LoopTrigger{
 		podObserver:        podObserver,
 		scanInterval:       scanInterval,
@@ -78,9 +78,10 @@ func (t *LoopTrigger) Wait(lastRun time.Time) {
 	}
 
 	// Unschedulable pod triggers autoscaling immediately.
+	scanInterval := t.scanInterval.Next()
 	select {
-	case <-time.After(t.scanInterval):
-		klog.Infof("Autoscaler loop triggered by a %v timer", t.scanInterval)
+	case <-time.After(scanInterval):
+		klog.Infof("Autoscaler loop triggered by a %v timer", scanInterval)
 	case <-t.podObserver.unschedulablePodChan:
 		klog.Info("Autoscaler loop triggered by unschedulable pod appearing")
 	}
@@ -28,6 +28,7 @@ import (
 	"syscall"
 	"time"
 
+	"k8s.io/autoscaler/cluster-autoscaler/core/rediscovery"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/actuation"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/orchestrator"
 	"k8s.io/autoscaler/cluster-autoscaler/debuggingsnapshot"
@@ -42,6 +43,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apiserver/pkg/server/mux"
 	"k8s.io/apiserver/pkg/server/routes"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
@@ -50,26 +52,39 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider/gce/localssdsize"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/core"
+	"k8s.io/autoscaler/cluster-autoscaler/core/healthprobe"
+	"k8s.io/autoscaler/cluster-autoscaler/core/orphanednodes"
 	"k8s.io/autoscaler/cluster-autoscaler/core/podlistprocessor"
 	"k8s.io/autoscaler/cluster-autoscaler/estimator"
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
+	"k8s.io/autoscaler/cluster-autoscaler/expander/priority"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	"k8s.io/autoscaler/cluster-autoscaler/observers/loopstart"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/capacitybuffer"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodeinfosprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodes"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pipeline"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/provreq"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates/emptycandidates"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates/previouscandidates"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates/pricecandidates"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/scaleuphints"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
 	provreqorchestrator "k8s.io/autoscaler/cluster-autoscaler/provisioningrequest/orchestrator"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/admissionpolicy"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/runtimeclass"
 	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
 	scheduler_util "k8s.io/autoscaler/cluster-autoscaler/utils/scheduler"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/units"
 	"k8s.io/autoscaler/cluster-autoscaler/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/leaderelection"
@@ -107,7 +122,10 @@ func multiStringFlag(name string, usage string) *MultiStringFlag {
 
 var (
 	clusterName             = flag.String("cluster-name", "", "Autoscaled cluster name, if available")
-	address                 = flag.String("address", ":8085", "The address to expose prometheus metrics.")
+	address                 = flag.String("address", ":8085", "The address to expose prometheus metrics. Can be a comma-separated list of addresses to listen on several interfaces, e.g. \"0.0.0.0:8085,[::]:8085\".")
+	healthCheckAddress      = flag.String("health-check-address", "", "The address to expose the health check endpoint on, if it should be served separately from metrics. Can be a comma-separated list of addresses. If empty, the health check is served on the metrics address.")
+	serverTLSCertFile       = flag.String("server-tls-cert-file", "", "Path to the certificate used to serve metrics and health check endpoints over TLS. The file is reloaded when it changes.")
+	serverTLSKeyFile        = flag.String("server-tls-private-key-file", "", "Path to the private key matching server-tls-cert-file.")
 	kubernetes              = flag.String("kubernetes", "", "Kubernetes master location. Leave blank for default")
 	kubeConfigFile          = flag.String("kubeconfig", "", "Path to kubeconfig file with authorization and master location information.")
 	kubeAPIContentType      = flag.String("kube-api-content-type", "application/vnd.kubernetes.protobuf", "Content type of requests sent to apiserver.")
@@ -156,6 +174,8 @@ var (
 	nodeDeletionBatcherInterval = flag.Duration("node-deletion-batcher-interval", 0*time.Second, "How long CA ScaleDown gather nodes to delete them in batch.")
 	scanInterval                = flag.Duration("scan-interval", config.DefaultScanInterval, "How often cluster is reevaluated for scale up or down")
 	maxNodesTotal               = flag.Int("max-nodes-total", 0, "Maximum number of nodes in all node groups. Cluster autoscaler will not grow the cluster beyond this number.")
+	nodeSelector                = flag.String("node-selector", "", "Label selector of nodes managed by cluster autoscaler, e.g. when another autoscaler manages the remaining nodes. Readiness tracking, scale-down and unregistered node handling ignore nodes not matching it. Empty selects all nodes.")
+	maxHourlyCost               = flag.Float64("max-hourly-cost", 0, "Maximum hourly cost of all nodes in the cluster, as computed by the pricing model of the cloud provider. Cluster autoscaler will not grow the cluster beyond this cost. 0 means no limit.")
 	coresTotal                  = flag.String("cores-total", minMaxFlagString(0, config.DefaultMaxClusterCores), "Minimum and maximum number of cores in cluster, in the format <min>:<max>. Cluster autoscaler will not scale the cluster beyond these numbers.")
 	memoryTotal                 = flag.String("memory-total", minMaxFlagString(0, config.DefaultMaxClusterMemory), "Minimum and maximum number of gigabytes of memory in cluster, in the format <min>:<max>. Cluster autoscaler will not scale the cluster beyond these numbers.")
 	gpuTotal                    = multiStringFlag("gpu-total", "Minimum and maximum number of different GPUs in cluster, in the format <gpu_type>:<min>:<max>. Cluster autoscaler will not scale the cluster beyond these numbers. Can be passed multiple times. CURRENTLY THIS FLAG ONLY WORKS ON GKE.")
@@ -189,13 +209,25 @@ var (
 
 	expanderFlag = flag.String("expander", expander.RandomExpanderName, "Type of node group expander to be used in scale up. Available values: ["+strings.Join(expander.AvailableExpanders, ",")+"]. Specifying multiple values separated by commas will call the expanders in succession until there is only one option remaining. Ties still existing after this process are broken randomly.")
 
-	grpcExpanderCert = flag.String("grpc-expander-cert", "", "Path to cert used by gRPC server over TLS")
-	grpcExpanderURL  = flag.String("grpc-expander-url", "", "URL to reach gRPC expander server.")
+	grpcExpanderCert      = flag.String("grpc-expander-cert", "", "Path to cert used by gRPC server over TLS")
+	grpcExpanderURL       = flag.String("grpc-expander-url", "", "URL to reach gRPC expander server.")
+	grpcExpanderStreaming = flag.Bool("grpc-expander-streaming", false, "Should the gRPC expander stream options to the gRPC server to be scored one by one, instead of sending all of them at once to select the best ones.")
+
+	flapDampingWindow        = flag.Duration("flap-damping-window", 30*time.Minute, "How long after removing nodes of a shape the flap-damping expander penalizes options re-creating nodes of the same shape.")
+	priorityExpanderFallback = flag.String("priority-expander-fallback", string(priority.LowestPriorityFallback), "How the priority expander handles expansion options not matching any priority: lowest (use them only if no option matches), exclude (never use them) or error (use no option at all while any option is unmatched).")
 
 	ignoreDaemonSetsUtilization = flag.Bool("ignore-daemonsets-utilization", false,
 		"Should CA ignore DaemonSet pods when calculating resource utilization for scaling down")
 	ignoreMirrorPodsUtilization = flag.Bool("ignore-mirror-pods-utilization", false,
 		"Should CA ignore Mirror pods when calculating resource utilization for scaling down")
+	maxConcurrentProvisioning = flag.Int("max-concurrent-provisioning", 0,
+		"Maximum number of nodes that can be provisioning in a single node group at the same time. Capacity needed above this limit is requested from other node groups. Can be overridden per node group. 0 means no limit.")
+	nodeGroupMaxScaleDownParallelism = flag.Int("node-group-max-scale-down-parallelism", 0,
+		"Maximum number of nodes of a single node group (both empty and needing drain) that can be deleted in parallel. Can be overridden per node group. 0 means no limit apart from max-scale-down-parallelism and max-drain-parallelism.")
+	maxNodeDrainTime = flag.Duration("max-node-drain-time", 0,
+		"Maximum time CA spends draining a node before scaling it down. Can be overridden per node group. 0 means no limit.")
+	nodeDrainTimeoutPolicy = flag.String("node-drain-timeout-policy", config.DrainTimeoutPolicyAbort,
+		"What happens to nodes whose drain didn't complete within --max-node-drain-time: abort (the node deletion is aborted) or force-delete (pods remaining on the node are force deleted and the node is deleted). Can be overridden per node group.")
 
 	writeStatusConfigMapFlag         = flag.Bool("write-status-configmap", true, "Should CA write status information to a configmap")
 	statusConfigMapName              = flag.String("status-config-map-name", "cluster-autoscaler-status", "Status configmap name")
@@ -203,6 +235,7 @@ var (
 	maxBinpackingTimeFlag            = flag.Duration("max-binpacking-time", 5*time.Minute, "Maximum time spend on binpacking for a single scale-up. If binpacking is limited by this, scale-up will continue with the already calculated scale-up options.")
 	maxFailingTimeFlag               = flag.Duration("max-failing-time", 15*time.Minute, "Maximum time from last recorded successful autoscaler run before automatic restart")
 	balanceSimilarNodeGroupsFlag     = flag.Bool("balance-similar-node-groups", false, "Detect similar node groups and balance the number of nodes between them")
+	balanceScaleDownMaxSkew          = flag.Int("balance-similar-node-groups-scale-down-max-skew", 0, "If positive and --balance-similar-node-groups is set, scale-down doesn't remove nodes if it would make the sizes of similar node groups differ by more than this number of nodes. 0 disables balancing on scale-down.")
 	nodeAutoprovisioningEnabled      = flag.Bool("node-autoprovisioning-enabled", false, "Should CA autoprovision node groups when needed.This flag is deprecated and will be removed in future releases.")
 	maxAutoprovisionedNodeGroupCount = flag.Int("max-autoprovisioned-node-group-count", 15, "The maximum number of autoprovisioned groups in the cluster.This flag is deprecated and will be removed in future releases.")
 
@@ -211,16 +244,20 @@ var (
 	regional                      = flag.Bool("regional", false, "Cluster is regional.")
 	newPodScaleUpDelay            = flag.Duration("new-pod-scale-up-delay", 0*time.Second, "Pods less than this old will not be considered for scale-up. Can be increased for individual pods through annotation 'cluster-autoscaler.kubernetes.io/pod-scale-up-delay'.")
 
-	ignoreTaintsFlag          = multiStringFlag("ignore-taint", "Specifies a taint to ignore in node templates when considering to scale a node group (Deprecated, use startup-taints instead)")
-	startupTaintsFlag         = multiStringFlag("startup-taint", "Specifies a taint to ignore in node templates when considering to scale a node group (Equivalent to ignore-taint)")
-	statusTaintsFlag          = multiStringFlag("status-taint", "Specifies a taint to ignore in node templates when considering to scale a node group but nodes will not be treated as unready")
-	balancingIgnoreLabelsFlag = multiStringFlag("balancing-ignore-label", "Specifies a label to ignore in addition to the basic and cloud-provider set of labels when comparing if two node groups are similar")
-	balancingLabelsFlag       = multiStringFlag("balancing-label", "Specifies a label to use for comparing if two node groups are similar, rather than the built in heuristics. Setting this flag disables all other comparison logic, and cannot be combined with --balancing-ignore-label.")
-	awsUseStaticInstanceList  = flag.Bool("aws-use-static-instance-list", false, "Should CA fetch instance types in runtime or use a static list. AWS only")
+	ignoreTaintsFlag                = multiStringFlag("ignore-taint", "Specifies a taint to ignore in node templates when considering to scale a node group (Deprecated, use startup-taints instead)")
+	startupTaintsFlag               = multiStringFlag("startup-taint", "Specifies a taint to ignore in node templates when considering to scale a node group (Equivalent to ignore-taint)")
+	statusTaintsFlag                = multiStringFlag("status-taint", "Specifies a taint to ignore in node templates when considering to scale a node group but nodes will not be treated as unready")
+	balancingIgnoreLabelsFlag       = multiStringFlag("balancing-ignore-label", "Specifies a label to ignore in addition to the basic and cloud-provider set of labels when comparing if two node groups are similar")
+	balancingLabelsFlag             = multiStringFlag("balancing-label", "Specifies a label to use for comparing if two node groups are similar, rather than the built in heuristics. Setting this flag disables all other comparison logic, and cannot be combined with --balancing-ignore-label.")
+	awsUseStaticInstanceList        = flag.Bool("aws-use-static-instance-list", false, "Should CA fetch instance types in runtime or use a static list. AWS only")
+	awsDrainAZRebalanceTerminations = flag.Bool("aws-drain-az-rebalance-terminations", false, "Should CA cordon and drain nodes of instances terminated by ASG AZRebalance, provisioning replacement capacity for their pods, like nodes affected by other maintenance. AWS only")
+	awsSuspendAZRebalance           = flag.Bool("aws-suspend-az-rebalance", false, "Should CA suspend the AZRebalance process of the ASGs it manages. AWS only")
 
 	// GCE specific flags
 	concurrentGceRefreshes            = flag.Int("gce-concurrent-refreshes", 1, "Maximum number of concurrent refreshes per cloud object type.")
 	gceMigInstancesMinRefreshWaitTime = flag.Duration("gce-mig-instances-min-refresh-wait-time", 5*time.Second, "The minimum time which needs to pass before GCE MIG instances from a given MIG can be refreshed.")
+	gceKubeEnvOverridesConfigMap      = flag.String("gce-kube-env-overrides-config-map", "", "Name of the ConfigMap in the CA namespace with per-MIG overrides of the labels, taints and kube-reserved extracted from kube-env for GCE template nodes. Empty disables the overrides.")
+	gceReservedMemoryModels           = multiStringFlag("gce-reserved-memory-model", "Reserved memory model of a GCE machine family used for template nodes if kube-env doesn't specify memory kube-reserved, in the format <machine-family>:<kernel-reserved-percent>:<system-reserved>, e.g. n2d:2%:256Mi. Machine families without a model use the generic kernel reserved memory estimate. Can be passed multiple times.")
 	_                                 = flag.Bool("gce-expander-ephemeral-storage-support", true, "Whether scale-up takes ephemeral storage resources into account for GCE cloud provider (Deprecated, to be removed in 1.30+)")
 
 	enableProfiling                    = flag.Bool("profiling", false, "Is debug/pprof endpoint enabled")
@@ -231,6 +268,9 @@ var (
 	userAgent                          = flag.String("user-agent", "cluster-autoscaler", "User agent used for HTTP calls.")
 	emitPerNodeGroupMetrics            = flag.Bool("emit-per-nodegroup-metrics", false, "If true, emit per node group metrics.")
 	debuggingSnapshotEnabled           = flag.Bool("debugging-snapshot-enabled", false, "Whether the debugging snapshot of cluster autoscaler feature is enabled")
+	debuggingSnapshotRedactedFields    = multiStringFlag("debugging-snapshot-redacted-field", "Name of a JSON field, e.g. env or providerID, whose values are redacted at any depth of the debugging snapshot. Can be passed multiple times.")
+	debuggingSnapshotRedactedPatterns  = multiStringFlag("debugging-snapshot-redacted-pattern", "Regular expression whose matches are redacted in all string values of the debugging snapshot. Can be passed multiple times.")
+	debuggingSnapshotEncryptionKeyFile = flag.String("debugging-snapshot-encryption-key-file", "", "Path to a file with a base64 encoded AES key. If set, the debugging snapshot is encrypted with AES-GCM, with the nonce prepended to the output.")
 	nodeInfoCacheExpireTime            = flag.Duration("node-info-cache-expire-time", 87600*time.Hour, "Node Info cache expire time for each item. Default value is 10 years.")
 
 	initialNodeGroupBackoffDuration = flag.Duration("initial-node-group-backoff-duration", 5*time.Minute,
@@ -239,17 +279,28 @@ var (
 		"maxNodeGroupBackoffDuration is the maximum backoff duration for a NodeGroup after new nodes failed to start.")
 	nodeGroupBackoffResetTimeout = flag.Duration("node-group-backoff-reset-timeout", 3*time.Hour,
 		"nodeGroupBackoffResetTimeout is the time after last failed scale-up when the backoff duration is reset.")
+	resetNodeGroupBackoffOnScaleUpSuccess = flag.Bool("reset-node-group-backoff-on-scale-up-success", false,
+		"Should CA reset the backoff of a NodeGroup once all nodes of its scale-up register, instead of waiting for node-group-backoff-reset-timeout.")
+	persistNodeGroupBackoff = flag.Bool("persist-node-group-backoff", false,
+		"Should CA persist the backoff state of NodeGroups in the status ConfigMap, so that restarts don't reset it. Requires write-status-configmap.")
 	maxScaleDownParallelismFlag             = flag.Int("max-scale-down-parallelism", 10, "Maximum number of nodes (both empty and needing drain) that can be deleted in parallel.")
 	maxDrainParallelismFlag                 = flag.Int("max-drain-parallelism", 1, "Maximum number of nodes needing drain, that can be drained and deleted in parallel.")
 	recordDuplicatedEvents                  = flag.Bool("record-duplicated-events", false, "enable duplication of similar events within a 5 minute window.")
+	recordNoScaleUpPodConditions            = flag.Bool("record-no-scale-up-pod-conditions", false, "If true, unschedulable pods that didn't trigger scale-up get a NotTriggerScaleUp condition with a stable reason code, in addition to events.")
 	maxNodesPerScaleUp                      = flag.Int("max-nodes-per-scaleup", 1000, "Max nodes added in a single scale-up. This is intended strictly for optimizing CA algorithm latency and not a tool to rate-limit scale-up throughput.")
 	maxNodeGroupBinpackingDuration          = flag.Duration("max-nodegroup-binpacking-duration", 10*time.Second, "Maximum time that will be spent in binpacking simulation for each NodeGroup.")
 	skipNodesWithSystemPods                 = flag.Bool("skip-nodes-with-system-pods", true, "If true cluster autoscaler will never delete nodes with pods from kube-system (except for DaemonSet or mirror pods)")
 	skipNodesWithLocalStorage               = flag.Bool("skip-nodes-with-local-storage", true, "If true cluster autoscaler will never delete nodes with pods with local storage, e.g. EmptyDir or HostPath")
 	skipNodesWithCustomControllerPods       = flag.Bool("skip-nodes-with-custom-controller-pods", true, "If true cluster autoscaler will never delete nodes with pods owned by custom controllers")
+	skipNodesWithVpaEvictions               = flag.Bool("skip-nodes-with-vpa-evictions", false, "If true cluster autoscaler will not delete nodes with pods whose controller has other pods being evicted by the Vertical Pod Autoscaler updater. Requires the updater to run with --cluster-autoscaler-drain-coordination.")
 	minReplicaCount                         = flag.Int("min-replica-count", 0, "Minimum number or replicas that a replica set or replication controller should have to allow their pods deletion in scale down")
+	orphanedNodesPolicy                     = flag.String("orphaned-nodes-policy", orphanednodes.AdoptPolicy, "What to do with nodes whose node group was removed from node group discovery while the nodes still exist. One of: adopt (keep the nodes as unmanaged, never scale them down), drain (taint and cordon the nodes, then evict their pods after --orphaned-nodes-drain-timeout; their instances have to be deleted manually).")
+	orphanedNodesGracePeriod                = flag.Duration("orphaned-nodes-grace-period", 10*time.Minute, "How long nodes have to be missing from all node groups before --orphaned-nodes-policy is applied to them.")
+	orphanedNodesDrainTimeout               = flag.Duration("orphaned-nodes-drain-timeout", time.Hour, "How long nodes orphaned with --orphaned-nodes-policy=drain stay cordoned before their pods are evicted.")
+	maintenanceLeadTime                     = flag.Duration("maintenance-lead-time", 30*time.Minute, "How long before maintenance reported by the cloud provider the affected nodes are cordoned and replacement capacity is provisioned for their pods. Only used with cloud providers reporting maintenance events.")
 	nodeDeleteDelayAfterTaint               = flag.Duration("node-delete-delay-after-taint", 5*time.Second, "How long to wait before deleting a node after tainting it")
 	scaleDownSimulationTimeout              = flag.Duration("scale-down-simulation-timeout", 30*time.Second, "How long should we run scale down simulation.")
+	scaleDownAntiAffinityGroups             = flag.Bool("scale-down-anti-affinity-groups", false, "Should CA evaluate nodes hosting replicas spread by required pod anti-affinity on hostname for scale down as groups, repacking all the replicas jointly, before evaluating them one by one.")
 	parallelDrain                           = flag.Bool("parallel-drain", true, "Whether to allow parallel drain of nodes. This flag is deprecated and will be removed in future releases.")
 	maxCapacityMemoryDifferenceRatio        = flag.Float64("memory-difference-ratio", config.DefaultMaxCapacityMemoryDifferenceRatio, "Maximum difference in memory capacity between two similar node groups to be considered for balancing. Value is a ratio of the smaller node group's memory capacity.")
 	maxFreeDifferenceRatio                  = flag.Float64("max-free-difference-ratio", config.DefaultMaxFreeDifferenceRatio, "Maximum difference in free resources between two similar node groups to be considered for balancing. Value is a ratio of the smaller node group's free resource.")
@@ -262,8 +313,30 @@ var (
 			"--max-graceful-termination-sec flag should not be set when this flag is set. Not setting this flag will use unordered evictor by default."+
 			"Priority evictor reuses the concepts of drain logic in kubelet(https://github.com/kubernetes/enhancements/tree/master/keps/sig-node/2712-pod-priority-based-graceful-node-shutdown#migration-from-the-node-graceful-shutdown-feature)."+
 			"Eg. flag usage:  '10000:20,1000:100,0:60'")
-	provisioningRequestsEnabled = flag.Bool("enable-provisioning-requests", false, "Whether the clusterautoscaler will be handling the ProvisioningRequest CRs.")
-	frequentLoopsEnabled        = flag.Bool("frequent-loops-enabled", false, "Whether clusterautoscaler triggers new iterations more frequently when it's needed")
+	provisioningRequestsEnabled        = flag.Bool("enable-provisioning-requests", false, "Whether the clusterautoscaler will be handling the ProvisioningRequest CRs.")
+	provisioningRequestReservationTime = flag.Duration("provisioning-request-reservation-time", provreq.DefaultReservationTime, "How long capacity is booked for Provisioned ProvisioningRequests, so that it isn't used by other ProvisioningRequests or scaled down, before their BookingExpired condition is set.")
+	provisioningRequestExpirationTime  = flag.Duration("provisioning-request-expiration-time", provreq.DefaultExpirationTime, "How long since their creation CA tries to provision capacity for ProvisioningRequests without the ValidUntilSeconds parameter, before their Failed condition is set.")
+	frequentLoopsEnabled               = flag.Bool("frequent-loops-enabled", false, "Whether clusterautoscaler triggers new iterations more frequently when it's needed")
+	startupCleanupTaintPrefixes        = multiStringFlag("startup-cleanup-taint-prefix", "Specifies a taint key prefix. Taints with matching keys are removed from nodes in autoscaled node groups on startup, e.g. taints left by aborted drains of other tooling. Can be passed multiple times.")
+	dryRun                             = flag.Bool("dry-run", false, "If true, CA runs the full scale-up and scale-down logic and records what it would have done through events, the status ConfigMap and metrics, without resizing node groups, tainting, draining and deleting nodes or mutating cloud resources.")
+	startupCleanupTaintsDryRun         = flag.Bool("startup-cleanup-taints-dry-run", false, "If true, taints matching --startup-cleanup-taint-prefix are only logged on startup instead of being removed.")
+	runtimeClassSimulationEnabled      = flag.Bool("enable-runtime-class-simulation", false, "Whether the overhead of RuntimeClasses is set on DaemonSet pods of template nodes, and labels required by RuntimeClasses of handlers listed in the "+runtimeclass.RuntimeHandlersLabel+" label of template nodes are added to them.")
+	admissionPolicySimulationEnabled   = flag.Bool("enable-admission-policy-simulation", false, "Whether simulated pod placements are checked against ValidatingAdmissionPolicies bound to pods/binding, so that nodes which pods wouldn't be allowed to bind to are not scaled up.")
+	processorsPipelineFile             = flag.String("processors-pipeline-file", "", "Path to a YAML file defining which pod list and scale-down set processors run and in which order, and whether similar node groups are balanced. Processors which aren't customized in the file keep the default behavior.")
+	scaleDownPreferExpensiveNodeGroups = flag.Bool("scale-down-prefer-expensive-node-groups", false, "Should CA prefer scaling down nodes from more expensive node groups among equally removable nodes. Requires the cloud provider to implement pricing.")
+	estimatorNumaCells                 = flag.Int("estimator-numa-cells", 0, "Number of NUMA cells new nodes are treated as when estimating scale-ups. Pods with the Guaranteed QoS class have to fit in a single cell, as with the single-numa-node topology manager policy. Values lower than 2 disable it.")
+	verifyClusterSnapshotRevisions     = flag.Bool("verify-cluster-snapshot-revisions", false, "Whether CA checks that pod list processors modify the cluster snapshot only through its API, e.g. don't mutate pods in place, and rebuilds the snapshot if they don't. Adds the cost of hashing the snapshot to each loop.")
+	capacityBuffersEnabled             = flag.Bool("enable-capacity-buffers", false, "Whether CA keeps spare capacity for the pods declared by CapacityBuffer CRs, as if they were pending.")
+	scaleUpHintsEnabled                = flag.Bool("enable-scale-up-hints", false, fmt.Sprintf("Whether CA provisions capacity for the pods of imminent workloads declared by the %s annotation of ConfigMaps and custom resources, as if they were pending.", scaleuphints.ProvisionForAnnotationKey))
+	scaleUpHintsResources              = multiStringFlag("scale-up-hints-resource", "Custom resource, in the resource.version.group format, e.g. jobqueues.v1.example.com, whose objects are checked for scale-up hints in addition to ConfigMaps. Can be passed multiple times.")
+	scaleUpFingerprintsEnabled         = flag.Bool("persist-scale-up-fingerprints", false, fmt.Sprintf("Whether CA persists recent scale-ups in the %s ConfigMap, so that pods which triggered a scale-up don't trigger another one after a restart, before the nodes register.", podlistprocessor.ScaleUpFingerprintsConfigMapName))
+	namespaceScaleUpQuotasEnabled      = flag.Bool("enable-namespace-scale-up-quotas", false, fmt.Sprintf("Whether CA limits the number of nodes pending pods of a namespace may trigger, as configured in the %s ConfigMap.", podlistprocessor.NamespaceQuotasConfigMapName))
+	adaptiveScanIntervalEnabled        = flag.Bool("adaptive-scan-interval", false, "Whether the scan interval adapts to cluster activity. It's --scan-interval while there are unschedulable pods or scaling is in flight, and doubles after each idle iteration up to --max-scan-interval.")
+	maxScanInterval                    = flag.Duration("max-scan-interval", time.Minute, "Maximum interval between iterations of idle clusters with --adaptive-scan-interval.")
+	nodeGroupRediscoveryInterval       = flag.Duration("node-group-rediscovery-interval", 0, "How often node group definitions, e.g. min and max sizes, are rebuilt from the cloud provider, bypassing its caches, to pick up out-of-band changes. 0 disables periodic rediscovery. Only used with cloud providers supporting rediscovery.")
+	nodeGroupRediscoveryTriggerEnabled = flag.Bool("enable-node-group-rediscovery-trigger", false, "Whether node group rediscovery can be requested on demand by sending SIGUSR1 to CA or a POST request to the /rediscover-node-groups endpoint.")
+	healthProbeInterval                = flag.Duration("health-probe-interval", 0, "How often CA checks that a synthetic unschedulable pod would trigger scale-up of at least one node group, going through the scale-up logic including limits and the expander but without scaling up, and exports the result as metrics. The pod is built from the "+healthprobe.ProbePodTemplateName+" PodTemplate in --health-probe-namespace if it exists. 0 disables the health probe.")
+	healthProbeNamespace               = flag.String("health-probe-namespace", "", "Namespace of the health probe pod and its PodTemplate. Defaults to --namespace.")
 )
 
 func isFlagPassed(name string) bool {
@@ -296,6 +369,16 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 	if *maxDrainParallelismFlag > 1 && !*parallelDrain {
 		klog.Fatalf("Invalid configuration, could not use --max-drain-parallelism > 1 if --parallel-drain is false")
 	}
+	if _, err := priority.ParseFallbackStrategy(*priorityExpanderFallback); err != nil {
+		klog.Fatalf("Invalid configuration, --priority-expander-fallback: %v", err)
+	}
+	parsedNodeSelector, err := labels.Parse(*nodeSelector)
+	if err != nil {
+		klog.Fatalf("Invalid configuration, --node-selector: %v", err)
+	}
+	if *orphanedNodesPolicy != orphanednodes.AdoptPolicy && *orphanedNodesPolicy != orphanednodes.DrainPolicy {
+		klog.Fatalf("Invalid configuration, --orphaned-nodes-policy must be one of %v, got %q", orphanednodes.Policies, *orphanedNodesPolicy)
+	}
 
 	// in order to avoid inconsistent deletion thresholds for the legacy planner and the new actuator, the max-empty-bulk-delete,
 	// and max-scale-down-parallelism flags must be set to the same value.
@@ -336,6 +419,10 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		}
 	}
 
+	if *nodeDrainTimeoutPolicy != config.DrainTimeoutPolicyAbort && *nodeDrainTimeoutPolicy != config.DrainTimeoutPolicyForceDelete {
+		klog.Fatalf("Invalid configuration, --node-drain-timeout-policy must be %s or %s", config.DrainTimeoutPolicyAbort, config.DrainTimeoutPolicyForceDelete)
+	}
+
 	return config.AutoscalingOptions{
 		NodeGroupDefaults: config.NodeGroupAutoscalingOptions{
 			ScaleDownUtilizationThreshold:    *scaleDownUtilizationThreshold,
@@ -344,6 +431,10 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 			ScaleDownUnreadyTime:             *scaleDownUnreadyTime,
 			IgnoreDaemonSetsUtilization:      *ignoreDaemonSetsUtilization,
 			MaxNodeProvisionTime:             *maxNodeProvisionTime,
+			MaxConcurrentProvisioning:        *maxConcurrentProvisioning,
+			MaxScaleDownParallelism:          *nodeGroupMaxScaleDownParallelism,
+			MaxNodeDrainTime:                 *maxNodeDrainTime,
+			NodeDrainTimeoutPolicy:           *nodeDrainTimeoutPolicy,
 		},
 		CloudConfig:                      *cloudConfig,
 		CloudProviderName:                *cloudProviderFlag,
@@ -356,6 +447,7 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		ExpanderNames:                    *expanderFlag,
 		GRPCExpanderCert:                 *grpcExpanderCert,
 		GRPCExpanderURL:                  *grpcExpanderURL,
+		GRPCExpanderStreaming:            *grpcExpanderStreaming,
 		IgnoreMirrorPodsUtilization:      *ignoreMirrorPodsUtilization,
 		MaxBulkSoftTaintCount:            *maxBulkSoftTaintCount,
 		MaxBulkSoftTaintTime:             *maxBulkSoftTaintTime,
@@ -363,6 +455,8 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		MaxGracefulTerminationSec:        *maxGracefulTerminationFlag,
 		MaxPodEvictionTime:               *maxPodEvictionTime,
 		MaxNodesTotal:                    *maxNodesTotal,
+		MaxHourlyCost:                    *maxHourlyCost,
+		NodeSelector:                     parsedNodeSelector,
 		MaxCoresTotal:                    maxCoresTotal,
 		MinCoresTotal:                    minCoresTotal,
 		MaxMemoryTotal:                   maxMemoryTotal,
@@ -384,6 +478,7 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		WriteStatusConfigMap:             *writeStatusConfigMapFlag,
 		StatusConfigMapName:              *statusConfigMapName,
 		BalanceSimilarNodeGroups:         *balanceSimilarNodeGroupsFlag,
+		BalanceScaleDownMaxSkew:          *balanceScaleDownMaxSkew,
 		ConfigNamespace:                  *namespace,
 		ClusterName:                      *clusterName,
 		NodeAutoprovisioningEnabled:      *nodeAutoprovisioningEnabled,
@@ -401,12 +496,16 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 			KubeConfigPath: *kubeConfigFile,
 			APIContentType: *kubeAPIContentType,
 		},
-		NodeDeletionDelayTimeout: *nodeDeletionDelayTimeout,
-		AWSUseStaticInstanceList: *awsUseStaticInstanceList,
+		NodeDeletionDelayTimeout:        *nodeDeletionDelayTimeout,
+		AWSUseStaticInstanceList:        *awsUseStaticInstanceList,
+		AWSDrainAZRebalanceTerminations: *awsDrainAZRebalanceTerminations,
+		AWSSuspendAZRebalance:           *awsSuspendAZRebalance,
 		GCEOptions: config.GCEOptions{
 			ConcurrentRefreshes:            *concurrentGceRefreshes,
 			MigInstancesMinRefreshWaitTime: *gceMigInstancesMinRefreshWaitTime,
 			LocalSSDDiskSizeProvider:       localssdsize.NewSimpleLocalSSDProvider(),
+			KubeEnvOverridesConfigMap:      *gceKubeEnvOverridesConfigMap,
+			ReservedMemoryModels:           *gceReservedMemoryModels,
 		},
 		ClusterAPICloudConfigAuthoritative: *clusterAPICloudConfigAuthoritative,
 		CordonNodeBeforeTerminate:          *cordonNodeBeforeTerminate,
@@ -419,6 +518,11 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		MaxScaleDownParallelism:            *maxScaleDownParallelismFlag,
 		MaxDrainParallelism:                *maxDrainParallelismFlag,
 		RecordDuplicatedEvents:             *recordDuplicatedEvents,
+		RecordNoScaleUpPodConditions:       *recordNoScaleUpPodConditions,
+		OrphanedNodesPolicy:                *orphanedNodesPolicy,
+		OrphanedNodesGracePeriod:           *orphanedNodesGracePeriod,
+		OrphanedNodesDrainTimeout:          *orphanedNodesDrainTimeout,
+		MaintenanceLeadTime:                *maintenanceLeadTime,
 		MaxNodesPerScaleUp:                 *maxNodesPerScaleUp,
 		MaxNodeGroupBinpackingDuration:     *maxNodeGroupBinpackingDuration,
 		MaxBinpackingTime:                  *maxBinpackingTimeFlag,
@@ -426,8 +530,10 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		SkipNodesWithSystemPods:            *skipNodesWithSystemPods,
 		SkipNodesWithLocalStorage:          *skipNodesWithLocalStorage,
 		MinReplicaCount:                    *minReplicaCount,
+		SkipNodesWithVpaEvictions:          *skipNodesWithVpaEvictions,
 		NodeDeleteDelayAfterTaint:          *nodeDeleteDelayAfterTaint,
 		ScaleDownSimulationTimeout:         *scaleDownSimulationTimeout,
+		ScaleDownAntiAffinityGroupsEnabled: *scaleDownAntiAffinityGroups,
 		ParallelDrain:                      *parallelDrain,
 		SkipNodesWithCustomControllerPods:  *skipNodesWithCustomControllerPods,
 		NodeGroupSetRatios: config.NodeGroupDifferenceRatios{
@@ -438,6 +544,28 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		DynamicNodeDeleteDelayAfterTaintEnabled: *dynamicNodeDeleteDelayAfterTaintEnabled,
 		BypassedSchedulers:                      scheduler_util.GetBypassedSchedulersMap(*bypassedSchedulers),
 		ProvisioningRequestEnabled:              *provisioningRequestsEnabled,
+		ProvisioningRequestReservationTime:      *provisioningRequestReservationTime,
+		ProvisioningRequestExpirationTime:       *provisioningRequestExpirationTime,
+		AdmissionPolicySimulationEnabled:        *admissionPolicySimulationEnabled,
+		RuntimeClassSimulationEnabled:           *runtimeClassSimulationEnabled,
+		StartupCleanupTaintPrefixes:             *startupCleanupTaintPrefixes,
+		DryRun:                                  *dryRun,
+		StartupCleanupTaintsDryRun:              *startupCleanupTaintsDryRun,
+		PriorityExpanderFallback:                *priorityExpanderFallback,
+		FlapDampingWindow:                       *flapDampingWindow,
+		ScaleDownPreferExpensiveNodeGroups:      *scaleDownPreferExpensiveNodeGroups,
+		EstimatorNumaCells:                      *estimatorNumaCells,
+		VerifyClusterSnapshotRevisions:          *verifyClusterSnapshotRevisions,
+		CapacityBuffersEnabled:                  *capacityBuffersEnabled,
+		ScaleUpHintsEnabled:                     *scaleUpHintsEnabled,
+		ScaleUpHintsResources:                   *scaleUpHintsResources,
+		NamespaceScaleUpQuotasEnabled:           *namespaceScaleUpQuotasEnabled,
+		ScaleUpFingerprintsEnabled:              *scaleUpFingerprintsEnabled,
+		NodeGroupRediscoveryInterval:            *nodeGroupRediscoveryInterval,
+		HealthProbeInterval:                     *healthProbeInterval,
+		HealthProbeNamespace:                    *healthProbeNamespace,
+		ResetNodeGroupBackoffOnScaleUpSuccess:   *resetNodeGroupBackoffOnScaleUpSuccess,
+		PersistNodeGroupBackoff:                 *persistNodeGroupBackoff,
 	}
 }
 
@@ -456,7 +584,21 @@ func registerSignalHandlers(autoscaler core.Autoscaler) {
 	}()
 }
 
-func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter) (core.Autoscaler, error) {
+// registerRediscoverySignalHandler requests node group rediscovery on SIGUSR1.
+func registerRediscoverySignalHandler(trigger *rediscovery.Trigger) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1)
+	klog.V(1).Info("Registered node group rediscovery signal handler")
+
+	go func() {
+		for range sigs {
+			klog.V(1).Info("Received SIGUSR1, requesting node group rediscovery")
+			trigger.Request()
+		}
+	}()
+}
+
+func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter, rediscoveryTrigger *rediscovery.Trigger) (core.Autoscaler, error) {
 	// Create basic config from flags.
 	autoscalingOptions := createAutoscalingOptions()
 
@@ -464,6 +606,18 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	autoscalingOptions.KubeClientOpts.KubeClientQPS = float32(*kubeClientQPS)
 	kubeClient := kube_util.CreateKubeClient(autoscalingOptions.KubeClientOpts)
 
+	pipelineConfig := &pipeline.Config{}
+	if *processorsPipelineFile != "" {
+		var err error
+		pipelineConfig, err = pipeline.Load(*processorsPipelineFile)
+		if err != nil {
+			return nil, err
+		}
+		if pipelineConfig.BalanceSimilarNodeGroups != nil {
+			autoscalingOptions.BalanceSimilarNodeGroups = *pipelineConfig.BalanceSimilarNodeGroups
+		}
+	}
+
 	// Informer transform to trim ManagedFields for memory efficiency.
 	trim := func(obj interface{}) (interface{}, error) {
 		if accessor, err := meta.Accessor(obj); err == nil {
@@ -473,10 +627,18 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	}
 	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0, informers.WithTransform(trim))
 
-	predicateChecker, err := predicatechecker.NewSchedulerBasedPredicateChecker(informerFactory, autoscalingOptions.SchedulerConfig)
+	schedulerPredicateChecker, err := predicatechecker.NewSchedulerBasedPredicateChecker(informerFactory, autoscalingOptions.SchedulerConfig)
 	if err != nil {
 		return nil, err
 	}
+	var predicateChecker predicatechecker.PredicateChecker = schedulerPredicateChecker
+	if autoscalingOptions.AdmissionPolicySimulationEnabled {
+		admissionPolicyChecker, err := admissionpolicy.NewChecker(informerFactory, kubeClient)
+		if err != nil {
+			return nil, err
+		}
+		predicateChecker = admissionpolicy.NewPredicateChecker(predicateChecker, admissionPolicyChecker)
+	}
 	deleteOptions := options.NewNodeDeleteOptions(autoscalingOptions)
 	drainabilityRules := rules.Default(deleteOptions)
 
@@ -490,11 +652,62 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 		DeleteOptions:        deleteOptions,
 		DrainabilityRules:    drainabilityRules,
 		ScaleUpOrchestrator:  orchestrator.New(),
+		RediscoveryTrigger:   rediscoveryTrigger,
 	}
 
 	opts.Processors = ca_processors.DefaultProcessors(autoscalingOptions)
 	opts.Processors.TemplateNodeInfoProvider = nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nodeInfoCacheExpireTime, *forceDaemonSets)
-	podListProcessor := podlistprocessor.NewDefaultPodListProcessor(opts.PredicateChecker)
+	podListProcessorStages := podlistprocessor.DefaultPodListProcessorStages(opts.PredicateChecker)
+	if autoscalingOptions.CapacityBuffersEnabled {
+		injector, err := capacitybuffer.NewPodsInjector(kube_util.GetKubeConfig(autoscalingOptions.KubeClientOpts))
+		if err != nil {
+			return nil, err
+		}
+		// Buffer pods have to be injected before schedulable pods are filtered out, so that the ones fitting
+		// on existing nodes are added to the cluster snapshot.
+		podListProcessorStages = pipeline.InsertBefore(podListProcessorStages, "filter-out-schedulable", pipeline.Stage[pods.PodListProcessor]{Name: "capacity-buffers", Processor: injector})
+	}
+	if autoscalingOptions.ScaleUpHintsEnabled {
+		resources, err := scaleuphints.ParseResources(autoscalingOptions.ScaleUpHintsResources)
+		if err != nil {
+			return nil, err
+		}
+		dynamicClient, err := dynamic.NewForConfig(kube_util.GetKubeConfig(autoscalingOptions.KubeClientOpts))
+		if err != nil {
+			return nil, err
+		}
+		stopChannel := make(chan struct{})
+		configMaps := kube_util.NewConfigMapListerForNamespace(kubeClient, stopChannel, metav1.NamespaceAll)
+		lister, err := scaleuphints.NewLister(configMaps, dynamicClient, resources, stopChannel)
+		if err != nil {
+			return nil, err
+		}
+		// As buffer pods, hint pods have to be injected before schedulable pods are filtered out.
+		podListProcessorStages = pipeline.InsertBefore(podListProcessorStages, "filter-out-schedulable", pipeline.Stage[pods.PodListProcessor]{Name: "scale-up-hints", Processor: scaleuphints.NewPodsInjector(lister)})
+	}
+	if autoscalingOptions.NamespaceScaleUpQuotasEnabled {
+		// Quotas are checked after pods fitting on existing and upcoming nodes are filtered out, so that
+		// these nodes are attributed to namespaces and only pods which would trigger scale-up are limited.
+		stopChannel := make(chan struct{})
+		lister := kube_util.NewConfigMapListerForNamespace(kubeClient, stopChannel, autoscalingOptions.ConfigNamespace)
+		quotas := podlistprocessor.NewNamespaceQuotasPodListProcessor(lister.ConfigMaps(autoscalingOptions.ConfigNamespace), stopChannel)
+		podListProcessorStages = pipeline.InsertBefore(podListProcessorStages, "filter-out-daemon-sets", pipeline.Stage[pods.PodListProcessor]{Name: "namespace-quotas", Processor: quotas})
+	}
+	if autoscalingOptions.ScaleUpFingerprintsEnabled {
+		// Pods which triggered scale-up before a restart are filtered out once pods fitting on
+		// upcoming nodes are, so that only the ones which would trigger another scale-up are.
+		fingerprints := podlistprocessor.NewScaleUpFingerprints()
+		podListProcessorStages = pipeline.InsertBefore(podListProcessorStages, "filter-out-daemon-sets", pipeline.Stage[pods.PodListProcessor]{Name: "scale-up-fingerprints", Processor: fingerprints.PodListProcessor()})
+		opts.Processors.ScaleUpStatusProcessor = status.NewCombinedScaleUpStatusProcessor([]status.ScaleUpStatusProcessor{
+			opts.Processors.ScaleUpStatusProcessor,
+			fingerprints.ScaleUpStatusProcessor(),
+		})
+	}
+	podListProcessors, err := pipeline.Build(pipelineConfig.PodListProcessors, podListProcessorStages)
+	if err != nil {
+		return nil, err
+	}
+	podListProcessor := pods.NewCombinedPodListProcessor(podListProcessors)
 
 	if autoscalingOptions.ProvisioningRequestEnabled {
 		podListProcessor.AddProcessor(provreq.NewProvisioningRequestPodsFilter(provreq.NewDefautlEventManager()))
@@ -511,7 +724,7 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 		scaleUpOrchestrator := provreqorchestrator.NewWrapperOrchestrator(provreqOrchestrator)
 
 		opts.ScaleUpOrchestrator = scaleUpOrchestrator
-		provreqProcesor := provreq.NewProvReqProcessor(client)
+		provreqProcesor := provreq.NewProvReqProcessor(client, autoscalingOptions.ProvisioningRequestReservationTime, autoscalingOptions.ProvisioningRequestExpirationTime)
 		if err != nil {
 			return nil, err
 		}
@@ -532,6 +745,9 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 		}
 		opts.Processors.ScaleDownCandidatesNotifier.Register(sdCandidatesSorting)
 	}
+	if autoscalingOptions.ScaleDownPreferExpensiveNodeGroups {
+		scaleDownCandidatesComparers = append(scaleDownCandidatesComparers, pricecandidates.NewPriceSorting())
+	}
 
 	cp := scaledowncandidates.NewCombinedScaleDownCandidatesProcessor()
 	cp.Register(scaledowncandidates.NewScaleDownCandidatesSortingProcessor(scaleDownCandidatesComparers))
@@ -561,10 +777,28 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 		nodeInfoComparator = nodeInfoComparatorBuilder(autoscalingOptions.BalancingExtraIgnoredLabels, autoscalingOptions.NodeGroupSetRatios)
 	}
 
+	if autoscalingOptions.RuntimeClassSimulationEnabled {
+		runtimeClassProvider := runtimeclass.NewProvider(informerFactory.Node().V1().RuntimeClasses().Lister())
+		opts.Processors.TemplateNodeInfoProvider = nodeinfosprovider.NewRuntimeClassNodeInfoProvider(opts.Processors.TemplateNodeInfoProvider, runtimeClassProvider)
+	}
+
 	opts.Processors.NodeGroupSetProcessor = &nodegroupset.BalancingNodeGroupSetProcessor{
 		Comparator: nodeInfoComparator,
 	}
 
+	scaleDownSetProcessorStages := nodes.DefaultScaleDownSetProcessorStages()
+	if autoscalingOptions.BalanceSimilarNodeGroups && autoscalingOptions.BalanceScaleDownMaxSkew > 0 {
+		// Candidates are filtered before the number of nodes to remove is capped, so that balanced
+		// candidates aren't cropped in favor of ones which would be dropped.
+		balancing := nodes.NewBalancingScaleDownSetProcessor(opts.Processors.NodeGroupSetProcessor, autoscalingOptions.BalanceScaleDownMaxSkew)
+		scaleDownSetProcessorStages = pipeline.InsertBefore(scaleDownSetProcessorStages, "max-nodes", pipeline.Stage[nodes.ScaleDownSetProcessor]{Name: "balance-similar-node-groups", Processor: balancing})
+	}
+	scaleDownSetProcessors, err := pipeline.Build(pipelineConfig.ScaleDownSetProcessors, scaleDownSetProcessorStages)
+	if err != nil {
+		return nil, err
+	}
+	opts.Processors.ScaleDownSetProcessor = nodes.NewCompositeScaleDownSetProcessor(scaleDownSetProcessors)
+
 	// These metrics should be published only once.
 	metrics.UpdateNapEnabled(autoscalingOptions.NodeAutoprovisioningEnabled)
 	metrics.UpdateCPULimitsCores(autoscalingOptions.MinCoresTotal, autoscalingOptions.MaxCoresTotal)
@@ -584,10 +818,10 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	return autoscaler, nil
 }
 
-func run(healthCheck *metrics.HealthCheck, debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter) {
+func run(healthCheck *metrics.HealthCheck, debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter, rediscoveryTrigger *rediscovery.Trigger) {
 	metrics.RegisterAll(*emitPerNodeGroupMetrics)
 
-	autoscaler, err := buildAutoscaler(debuggingSnapshotter)
+	autoscaler, err := buildAutoscaler(debuggingSnapshotter, rediscoveryTrigger)
 	if err != nil {
 		klog.Fatalf("Failed to create autoscaler: %v", err)
 	}
@@ -606,9 +840,13 @@ func run(healthCheck *metrics.HealthCheck, debuggingSnapshotter debuggingsnapsho
 	// Autoscale ad infinitum.
 	context, cancel := ctx.WithCancel(ctx.Background())
 	defer cancel()
+	var interval loop.ScanInterval = loop.FixedScanInterval(*scanInterval)
+	if *adaptiveScanIntervalEnabled {
+		interval = loop.NewAdaptiveScanInterval(autoscaler, *scanInterval, *maxScanInterval)
+	}
 	if *frequentLoopsEnabled {
 		podObserver := loop.StartPodObserver(context, kube_util.CreateKubeClient(createAutoscalingOptions().KubeClientOpts))
-		trigger := loop.NewLoopTrigger(podObserver, autoscaler, *scanInterval)
+		trigger := loop.NewLoopTrigger(podObserver, autoscaler, interval)
 		lastRun := time.Now()
 		for {
 			trigger.Wait(lastRun)
@@ -617,7 +855,7 @@ func run(healthCheck *metrics.HealthCheck, debuggingSnapshotter debuggingsnapsho
 		}
 	} else {
 		for {
-			time.Sleep(*scanInterval)
+			time.Sleep(interval.Next())
 			loop.RunAutoscalerOnce(autoscaler, healthCheck, time.Now())
 		}
 	}
@@ -650,7 +888,39 @@ func main() {
 
 	klog.V(1).Infof("Cluster Autoscaler %s", version.ClusterAutoscalerVersion)
 
-	debuggingSnapshotter := debuggingsnapshot.NewDebuggingSnapshotter(*debuggingSnapshotEnabled)
+	redactedPatterns, err := debuggingsnapshot.ParseRedactedPatterns(*debuggingSnapshotRedactedPatterns)
+	if err != nil {
+		klog.Fatalf("Failed to parse debugging snapshot redaction patterns: %v", err)
+	}
+	debuggingSnapshotOutputOptions := debuggingsnapshot.OutputOptions{
+		RedactedFields:   *debuggingSnapshotRedactedFields,
+		RedactedPatterns: redactedPatterns,
+	}
+	if *debuggingSnapshotEncryptionKeyFile != "" {
+		debuggingSnapshotOutputOptions.EncryptionKey, err = debuggingsnapshot.LoadEncryptionKey(*debuggingSnapshotEncryptionKeyFile)
+		if err != nil {
+			klog.Fatalf("Failed to load debugging snapshot encryption key: %v", err)
+		}
+	}
+	debuggingSnapshotter := debuggingsnapshot.NewDebuggingSnapshotter(*debuggingSnapshotEnabled, debuggingSnapshotOutputOptions)
+
+	metricsAddresses, err := metrics.ParseAddresses(*address)
+	if err != nil {
+		klog.Fatalf("Failed to parse address: %v", err)
+	}
+	var healthCheckAddresses []string
+	if *healthCheckAddress != "" {
+		healthCheckAddresses, err = metrics.ParseAddresses(*healthCheckAddress)
+		if err != nil {
+			klog.Fatalf("Failed to parse health check address: %v", err)
+		}
+	}
+
+	var rediscoveryTrigger *rediscovery.Trigger
+	if *nodeGroupRediscoveryTriggerEnabled {
+		rediscoveryTrigger = rediscovery.NewTrigger()
+		registerRediscoverySignalHandler(rediscoveryTrigger)
+	}
 
 	go func() {
 		pathRecorderMux := mux.NewPathRecorderMux("cluster-autoscaler")
@@ -661,16 +931,38 @@ func main() {
 		if *debuggingSnapshotEnabled {
 			pathRecorderMux.HandleFunc("/snapshotz", debuggingSnapshotter.ResponseHandler)
 		}
-		pathRecorderMux.HandleFunc("/health-check", healthCheck.ServeHTTP)
+		if len(healthCheckAddresses) == 0 {
+			pathRecorderMux.HandleFunc("/health-check", healthCheck.ServeHTTP)
+		}
+		if rediscoveryTrigger != nil {
+			pathRecorderMux.HandleFunc("/rediscover-node-groups", rediscoveryTrigger.ServeHTTP)
+		}
 		if *enableProfiling {
 			routes.Profiling{}.Install(pathRecorderMux)
 		}
-		err := http.ListenAndServe(*address, pathRecorderMux)
+		err := metrics.ListenAndServe(ctx.TODO(), metrics.ServerConfig{
+			Addresses:   metricsAddresses,
+			TLSCertFile: *serverTLSCertFile,
+			TLSKeyFile:  *serverTLSKeyFile,
+		}, pathRecorderMux)
 		klog.Fatalf("Failed to start metrics: %v", err)
 	}()
 
+	if len(healthCheckAddresses) > 0 {
+		go func() {
+			pathRecorderMux := mux.NewPathRecorderMux("cluster-autoscaler-health-check")
+			pathRecorderMux.HandleFunc("/health-check", healthCheck.ServeHTTP)
+			err := metrics.ListenAndServe(ctx.TODO(), metrics.ServerConfig{
+				Addresses:   healthCheckAddresses,
+				TLSCertFile: *serverTLSCertFile,
+				TLSKeyFile:  *serverTLSKeyFile,
+			}, pathRecorderMux)
+			klog.Fatalf("Failed to start health check: %v", err)
+		}()
+	}
+
 	if !leaderElection.LeaderElect {
-		run(healthCheck, debuggingSnapshotter)
+		run(healthCheck, debuggingSnapshotter, rediscoveryTrigger)
 	} else {
 		id, err := os.Hostname()
 		if err != nil {
@@ -710,7 +1002,7 @@ func main() {
 				OnStartedLeading: func(_ ctx.Context) {
 					// Since we are committing a suicide after losing
 					// mastership, we can safely ignore the argument.
-					run(healthCheck, debuggingSnapshotter)
+					run(healthCheck, debuggingSnapshotter, rediscoveryTrigger)
 				},
 				OnStoppedLeading: func() {
 					klog.Fatalf("lost master")
@@ -47,6 +47,9 @@ type NodeGroupType string
 // PodEvictionResult describes result of the pod eviction attempt
 type PodEvictionResult string
 
+// HealthProbeResult describes result of the health probe
+type HealthProbeResult string
+
 const (
 	caNamespace           = "cluster_autoscaler"
 	readyLabel            = "ready"
@@ -94,6 +97,12 @@ const (
 	PodEvictionSucceed PodEvictionResult = "succeeded"
 	// PodEvictionFailed means creation of the pod eviction object failed
 	PodEvictionFailed PodEvictionResult = "failed"
+	// HealthProbeHealthy means the health probe pod would trigger scale-up
+	HealthProbeHealthy HealthProbeResult = "healthy"
+	// HealthProbeUnhealthy means the health probe pod wouldn't trigger scale-up of any node group
+	HealthProbeUnhealthy HealthProbeResult = "unhealthy"
+	// HealthProbeError means the health probe pod couldn't be built
+	HealthProbeError HealthProbeResult = "error"
 )
 
 // Names of Cluster Autoscaler operations
@@ -216,6 +225,14 @@ var (
 		}, []string{"node_group"},
 	)
 
+	nodesGroupUpcomingNodes = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "node_group_upcoming_count",
+			Help:      "Number of requested nodes in the node group which aren't ready yet.",
+		}, []string{"node_group"},
+	)
+
 	nodesGroupHealthiness = k8smetrics.NewGaugeVec(
 		&k8smetrics.GaugeOpts{
 			Namespace: caNamespace,
@@ -357,6 +374,22 @@ var (
 		},
 	)
 
+	priorityExpanderUnmatchedOptionsCount = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "priority_expander_unmatched_options_total",
+			Help:      "Number of expansion options not matching any priority of the priority expander.",
+		},
+	)
+
+	expanderOptionsCount = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "expander_options_total",
+			Help:      "Number of expansion options considered and selected by each expander of the expander chain.",
+		}, []string{"expander", "result"},
+	)
+
 	oldUnregisteredNodesRemovedCount = k8smetrics.NewCounter(
 		&k8smetrics.CounterOpts{
 			Namespace: caNamespace,
@@ -373,6 +406,30 @@ var (
 		},
 	)
 
+	upcomingNodesCount = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "upcoming_nodes_count",
+			Help:      "Number of requested nodes which aren't ready yet. They're accounted for as if they were ready when computing further scale-ups.",
+		},
+	)
+
+	orphanedNodesCount = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "orphaned_nodes_count",
+			Help:      "Number of nodes whose node group was removed from discovery while the nodes still exist.",
+		},
+	)
+
+	nodesWithScheduledMaintenanceCount = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "nodes_with_scheduled_maintenance_count",
+			Help:      "Number of nodes affected by upcoming maintenance reported by the cloud provider.",
+		},
+	)
+
 	skippedScaleEventsCount = k8smetrics.NewCounterVec(
 		&k8smetrics.CounterOpts{
 			Namespace: caNamespace,
@@ -382,6 +439,23 @@ var (
 		[]string{"direction", "reason"},
 	)
 
+	healthProbeHealthy = k8smetrics.NewGauge(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "health_probe_healthy",
+			Help:      "Whether the last health probe pod would trigger scale-up. 1 if it would, 0 otherwise.",
+		},
+	)
+
+	healthProbesCount = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "health_probes_total",
+			Help:      "Number of health probes, by result.",
+		},
+		[]string{"result"},
+	)
+
 	/**** Metrics related to NodeAutoprovisioning ****/
 	napEnabled = k8smetrics.NewGauge(
 		&k8smetrics.GaugeOpts{
@@ -417,6 +491,23 @@ var (
 		},
 		[]string{"type"},
 	)
+
+	dryRunNodesCount = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "dry_run_nodes_total",
+			Help:      "Number of nodes CA would have added or removed if it wasn't running in dry-run mode.",
+		},
+		[]string{"direction"},
+	)
+
+	clusterSnapshotRebuildsCount = k8smetrics.NewCounter(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "cluster_snapshot_rebuilds_total",
+			Help:      "Number of times the cluster snapshot was rebuilt because processors modified it outside of its API.",
+		},
+	)
 )
 
 // RegisterAll registers all metrics.
@@ -445,18 +536,28 @@ func RegisterAll(emitPerNodeGroupMetrics bool) {
 	legacyregistry.MustRegister(unremovableNodesCount)
 	legacyregistry.MustRegister(scaleDownInCooldown)
 	legacyregistry.MustRegister(oldUnregisteredNodesRemovedCount)
+	legacyregistry.MustRegister(priorityExpanderUnmatchedOptionsCount)
+	legacyregistry.MustRegister(expanderOptionsCount)
 	legacyregistry.MustRegister(overflowingControllersCount)
+	legacyregistry.MustRegister(upcomingNodesCount)
+	legacyregistry.MustRegister(orphanedNodesCount)
+	legacyregistry.MustRegister(nodesWithScheduledMaintenanceCount)
 	legacyregistry.MustRegister(skippedScaleEventsCount)
+	legacyregistry.MustRegister(healthProbeHealthy)
+	legacyregistry.MustRegister(healthProbesCount)
 	legacyregistry.MustRegister(napEnabled)
 	legacyregistry.MustRegister(nodeGroupCreationCount)
 	legacyregistry.MustRegister(nodeGroupDeletionCount)
 	legacyregistry.MustRegister(pendingNodeDeletions)
 	legacyregistry.MustRegister(nodeTaintsCount)
+	legacyregistry.MustRegister(clusterSnapshotRebuildsCount)
+	legacyregistry.MustRegister(dryRunNodesCount)
 
 	if emitPerNodeGroupMetrics {
 		legacyregistry.MustRegister(nodesGroupMinNodes)
 		legacyregistry.MustRegister(nodesGroupMaxNodes)
 		legacyregistry.MustRegister(nodesGroupTargetSize)
+		legacyregistry.MustRegister(nodesGroupUpcomingNodes)
 		legacyregistry.MustRegister(nodesGroupHealthiness)
 		legacyregistry.MustRegister(nodeGroupBackOffStatus)
 	}
@@ -564,6 +665,16 @@ func UpdateNodeGroupTargetSize(targetSizes map[string]int) {
 	}
 }
 
+// UpdateUpcomingNodes records the number of upcoming nodes, in total and by node group
+func UpdateUpcomingNodes(upcomingCounts map[string]int) {
+	total := 0
+	for nodeGroup, upcoming := range upcomingCounts {
+		nodesGroupUpcomingNodes.WithLabelValues(nodeGroup).Set(float64(upcoming))
+		total += upcoming
+	}
+	upcomingNodesCount.Set(float64(total))
+}
+
 // UpdateNodeGroupHealthStatus records if node group is healthy to autoscaling
 func UpdateNodeGroupHealthStatus(nodeGroup string, healthy bool) {
 	if healthy {
@@ -631,7 +742,7 @@ func UpdateUnneededNodesCount(nodesCount int) {
 // UpdateUnremovableNodesCount records number of currently unremovable nodes
 func UpdateUnremovableNodesCount(unremovableReasonCounts map[simulator.UnremovableReason]int) {
 	for reason, count := range unremovableReasonCounts {
-		unremovableNodesCount.WithLabelValues(fmt.Sprintf("%v", reason)).Set(float64(count))
+		unremovableNodesCount.WithLabelValues(fmt.Sprintf("%d", reason)).Set(float64(count))
 	}
 }
 
@@ -674,6 +785,18 @@ func UpdateScaleDownInCooldown(inCooldown bool) {
 	}
 }
 
+// RegisterPriorityExpanderUnmatchedOptions records number of expansion options
+// not matching any priority of the priority expander
+func RegisterPriorityExpanderUnmatchedOptions(optionsCount int) {
+	priorityExpanderUnmatchedOptionsCount.Add(float64(optionsCount))
+}
+
+// RegisterExpanderOptions records the number of expansion options the expander considered and selected.
+func RegisterExpanderOptions(expanderName string, considered, selected int) {
+	expanderOptionsCount.WithLabelValues(expanderName, "considered").Add(float64(considered))
+	expanderOptionsCount.WithLabelValues(expanderName, "selected").Add(float64(selected))
+}
+
 // RegisterOldUnregisteredNodesRemoved records number of old unregistered
 // nodes that have been removed by the cluster autoscaler
 func RegisterOldUnregisteredNodesRemoved(nodesCount int) {
@@ -686,6 +809,26 @@ func UpdateOverflowingControllers(count int) {
 	overflowingControllersCount.Set(float64(count))
 }
 
+// UpdateOrphanedNodesCount records number of nodes whose node group was removed from discovery
+func UpdateOrphanedNodesCount(nodesCount int) {
+	orphanedNodesCount.Set(float64(nodesCount))
+}
+
+// UpdateNodesWithScheduledMaintenanceCount records number of nodes affected by upcoming maintenance
+func UpdateNodesWithScheduledMaintenanceCount(nodesCount int) {
+	nodesWithScheduledMaintenanceCount.Set(float64(nodesCount))
+}
+
+// RegisterHealthProbe records the result of a health probe
+func RegisterHealthProbe(result HealthProbeResult) {
+	healthProbesCount.WithLabelValues(string(result)).Inc()
+	if result == HealthProbeHealthy {
+		healthProbeHealthy.Set(1)
+	} else {
+		healthProbeHealthy.Set(0)
+	}
+}
+
 // RegisterSkippedScaleDownCPU increases the count of skipped scale outs because of CPU resource limits
 func RegisterSkippedScaleDownCPU() {
 	skippedScaleEventsCount.WithLabelValues(DirectionScaleDown, CpuResourceLimit).Add(1.0)
@@ -715,3 +858,18 @@ func ObservePendingNodeDeletions(value int) {
 func ObserveNodeTaintsCount(taintType string, count float64) {
 	nodeTaintsCount.WithLabelValues(taintType).Set(count)
 }
+
+// RegisterDryRunScaleUp records number of nodes CA would have added in dry-run mode
+func RegisterDryRunScaleUp(nodesCount int) {
+	dryRunNodesCount.WithLabelValues(DirectionScaleUp).Add(float64(nodesCount))
+}
+
+// RegisterDryRunScaleDown records number of nodes CA would have removed in dry-run mode
+func RegisterDryRunScaleDown(nodesCount int) {
+	dryRunNodesCount.WithLabelValues(DirectionScaleDown).Add(float64(nodesCount))
+}
+
+// RegisterClusterSnapshotRebuild records a rebuild of the cluster snapshot modified outside of its API.
+func RegisterClusterSnapshotRebuild() {
+	clusterSnapshotRebuildsCount.Inc()
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"k8s.io/apiserver/pkg/server/dynamiccertificates"
+)
+
+// ServerConfig configures an HTTP server exposing metrics or health check endpoints.
+type ServerConfig struct {
+	// Addresses to listen on. Passing several addresses allows listening on
+	// separate interfaces, e.g. on both an IPv4 and an IPv6 address.
+	Addresses []string
+	// TLSCertFile and TLSKeyFile enable serving over TLS if set. The files
+	// are watched, so that rotated certificates are used without a restart.
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// ParseAddresses parses a comma-separated list of host:port addresses.
+// IPv6 hosts have to be enclosed in square brackets, e.g. "[::1]:8085".
+func ParseAddresses(addresses string) ([]string, error) {
+	var result []string
+	for _, address := range strings.Split(addresses, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil, fmt.Errorf("invalid address %q: %v", address, err)
+		}
+		result = append(result, address)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no address specified")
+	}
+	return result, nil
+}
+
+// ListenAndServe serves the handler on all configured addresses until the
+// first of the servers fails. Certificates are reloaded until ctx is done.
+func ListenAndServe(ctx context.Context, config ServerConfig, handler http.Handler) error {
+	tlsConfig, err := serverTLSConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+	listeners, err := listen(config.Addresses)
+	if err != nil {
+		return err
+	}
+	return serve(listeners, handler, tlsConfig)
+}
+
+func listen(addresses []string) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addresses))
+	for _, address := range addresses {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("failed to listen on %s: %v", address, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+func serve(listeners []net.Listener, handler http.Handler, tlsConfig *tls.Config) error {
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		server := &http.Server{Handler: handler, TLSConfig: tlsConfig}
+		go func(listener net.Listener) {
+			if tlsConfig != nil {
+				errs <- server.ServeTLS(listener, "", "")
+			} else {
+				errs <- server.Serve(listener)
+			}
+		}(listener)
+	}
+	return <-errs
+}
+
+// serverTLSConfig returns a TLS config serving the current content of the
+// configured certificate files, or nil if TLS isn't configured.
+func serverTLSConfig(ctx context.Context, config ServerConfig) (*tls.Config, error) {
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		return nil, nil
+	}
+	certKeyContent, err := dynamiccertificates.NewDynamicServingContentFromFiles("serving-cert", config.TLSCertFile, config.TLSKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	go certKeyContent.Run(ctx, 1)
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, err := tls.X509KeyPair(certKeyContent.CurrentCertKeyContent())
+			if err != nil {
+				return nil, err
+			}
+			return &cert, nil
+		},
+	}, nil
+}
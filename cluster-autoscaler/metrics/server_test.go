@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+func TestParseAddresses(t *testing.T) {
+	testCases := []struct {
+		addresses string
+		want      []string
+		wantError bool
+	}{
+		{addresses: ":8085", want: []string{":8085"}},
+		{addresses: "0.0.0.0:8085, [::]:8085", want: []string{"0.0.0.0:8085", "[::]:8085"}},
+		{addresses: "[fd00::1]:8085", want: []string{"[fd00::1]:8085"}},
+		{addresses: "fd00::1:8085", wantError: true},
+		{addresses: "localhost", wantError: true},
+		{addresses: "", wantError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.addresses, func(t *testing.T) {
+			got, err := ParseAddresses(tc.addresses)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	listeners, err := listen([]string{"127.0.0.1:0", "127.0.0.1:0"})
+	require.NoError(t, err)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	go func() { _ = serve(listeners, handler, nil) }()
+
+	for _, listener := range listeners {
+		resp, err := http.Get("http://" + listener.Addr().String())
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "ok", string(body))
+	}
+}
+
+func TestServerTLSConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config, err := serverTLSConfig(ctx, ServerConfig{})
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	_, err = serverTLSConfig(ctx, ServerConfig{TLSCertFile: "cert.pem"})
+	assert.Error(t, err)
+
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey("localhost", []net.IP{net.ParseIP("127.0.0.1")}, nil)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+
+	config, err = serverTLSConfig(ctx, ServerConfig{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	require.NoError(t, err)
+	cert, err := config.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NoError(t, err)
+	assert.NotNil(t, cert)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	bufferapi "k8s.io/autoscaler/cluster-autoscaler/capacitybuffer"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+)
+
+type capacityBufferLister interface {
+	CapacityBuffers() ([]*bufferapi.CapacityBuffer, error)
+}
+
+// podSource provides virtual pods of all CapacityBuffers.
+type podSource struct {
+	client capacityBufferLister
+}
+
+// VirtualPods returns pods of all valid CapacityBuffers.
+func (s *podSource) VirtualPods() ([]*apiv1.Pod, error) {
+	buffers, err := s.client.CapacityBuffers()
+	if err != nil {
+		return nil, err
+	}
+	var result []*apiv1.Pod
+	for _, buffer := range buffers {
+		bufferPods, err := bufferapi.PodsForCapacityBuffer(buffer)
+		if err != nil {
+			klog.Warningf("Failed to get pods for Capacity Buffer %s/%s: %v", buffer.Namespace, buffer.Name, err)
+			continue
+		}
+		klog.V(5).Infof("Capacity Buffer %s/%s has %d pods", buffer.Namespace, buffer.Name, len(bufferPods))
+		result = append(result, bufferPods...)
+	}
+	return result, nil
+}
+
+// NewPodsInjector creates a processor injecting virtual pods of CapacityBuffers
+// into the unschedulable pods list. Buffer pods fitting on existing nodes are
+// added to the cluster snapshot, which also keeps the nodes from being scaled
+// down.
+func NewPodsInjector(kubeConfig *rest.Config) (pods.PodListProcessor, error) {
+	client, err := bufferapi.NewClient(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return pods.NewVirtualPodsInjector("Capacity Buffers", &podSource{client: client}), nil
+}
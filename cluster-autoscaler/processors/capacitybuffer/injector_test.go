@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package capacitybuffer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	bufferapi "k8s.io/autoscaler/cluster-autoscaler/capacitybuffer"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+type fakeCapacityBufferLister struct {
+	buffers []*bufferapi.CapacityBuffer
+	err     error
+}
+
+func (f *fakeCapacityBufferLister) CapacityBuffers() ([]*bufferapi.CapacityBuffer, error) {
+	return f.buffers, f.err
+}
+
+func TestPodSource(t *testing.T) {
+	newBuffer := func(name string, count int32) *bufferapi.CapacityBuffer {
+		return &bufferapi.CapacityBuffer{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: bufferapi.CapacityBufferSpec{PodSets: []bufferapi.PodSet{{
+				Count:    count,
+				Template: apiv1.PodTemplateSpec{Spec: BuildTestPod("template", 100, 100).Spec},
+			}}},
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		lister       *fakeCapacityBufferLister
+		expectedPods int
+		expectedErr  bool
+	}{
+		{
+			name:         "no buffers",
+			lister:       &fakeCapacityBufferLister{},
+			expectedPods: 0,
+		},
+		{
+			name:         "buffers",
+			lister:       &fakeCapacityBufferLister{buffers: []*bufferapi.CapacityBuffer{newBuffer("a", 2), newBuffer("b", 1)}},
+			expectedPods: 3,
+		},
+		{
+			name:         "invalid buffer is skipped",
+			lister:       &fakeCapacityBufferLister{buffers: []*bufferapi.CapacityBuffer{newBuffer("a", 2), newBuffer("b", -1)}},
+			expectedPods: 2,
+		},
+		{
+			name:        "listing fails",
+			lister:      &fakeCapacityBufferLister{err: fmt.Errorf("no CRD")},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := &podSource{client: tc.lister}
+			pods, err := source.VirtualPods()
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Len(t, pods, tc.expectedPods)
+		})
+	}
+}
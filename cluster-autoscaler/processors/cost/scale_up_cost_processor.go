@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// ScaleUpCostProcessor limits scale-ups to a budget of hourly cost of the cluster.
+type ScaleUpCostProcessor interface {
+	// HourlyCostLeft returns the budget of hourly cost left for new nodes, given
+	// the nodes of the cluster and the upcoming nodes.
+	HourlyCostLeft(context *context.AutoscalingContext, nodes []*apiv1.Node, upcomingNodes []*schedulerframework.NodeInfo, now time.Time) (*Budget, errors.AutoscalerError)
+	// NodeHourlyCost returns the hourly cost of a new node of the node group.
+	NodeHourlyCost(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulerframework.NodeInfo, now time.Time) (float64, errors.AutoscalerError)
+	// CleanUp cleans up the processor's internal structures.
+	CleanUp()
+}
+
+// Budget is the hourly cost which can still be spent on new nodes.
+type Budget struct {
+	limited bool
+	left    float64
+}
+
+// UnlimitedBudget returns a budget not limiting scale-ups.
+func UnlimitedBudget() *Budget {
+	return &Budget{}
+}
+
+// NewBudget returns a budget of the given hourly cost.
+func NewBudget(left float64) *Budget {
+	return &Budget{limited: true, left: left}
+}
+
+// Limited returns true if the budget limits scale-ups.
+func (b *Budget) Limited() bool {
+	return b.limited
+}
+
+// Left returns the hourly cost left in the budget.
+func (b *Budget) Left() float64 {
+	return b.left
+}
+
+// Fits returns the number of nodes of the given hourly cost, up to count,
+// fitting within the budget.
+func (b *Budget) Fits(nodeCost float64, count int) int {
+	if !b.limited || nodeCost <= 0 {
+		return count
+	}
+	if b.left < nodeCost {
+		return 0
+	}
+	if fitting := int(b.left / nodeCost); fitting < count {
+		return fitting
+	}
+	return count
+}
+
+// Reserve caps count to the number of nodes of the given hourly cost fitting
+// within the budget, and decrements the budget by the cost of the capped
+// number of nodes.
+func (b *Budget) Reserve(nodeCost float64, count int) int {
+	count = b.Fits(nodeCost, count)
+	if b.limited && count > 0 {
+		b.left -= nodeCost * float64(count)
+	}
+	return count
+}
+
+// pricingScaleUpCostProcessor computes hourly costs of nodes using the
+// pricing model of the cloud provider.
+type pricingScaleUpCostProcessor struct {
+	maxHourlyCost float64
+}
+
+// NewScaleUpCostProcessor returns a ScaleUpCostProcessor limiting the hourly cost
+// of all nodes in the cluster to maxHourlyCost, as computed by the pricing model
+// of the cloud provider. Scale-ups aren't limited if maxHourlyCost is 0.
+func NewScaleUpCostProcessor(maxHourlyCost float64) ScaleUpCostProcessor {
+	return &pricingScaleUpCostProcessor{maxHourlyCost: maxHourlyCost}
+}
+
+// HourlyCostLeft returns the hourly cost left after paying for the nodes and
+// the upcoming nodes. Nodes which can't be priced, e.g. of unknown instance
+// types or outside of node groups, are logged and not counted.
+func (p *pricingScaleUpCostProcessor) HourlyCostLeft(context *context.AutoscalingContext, nodes []*apiv1.Node, upcomingNodes []*schedulerframework.NodeInfo, now time.Time) (*Budget, errors.AutoscalerError) {
+	if p.maxHourlyCost <= 0 {
+		return UnlimitedBudget(), nil
+	}
+	pricingModel, aErr := p.pricingModel(context)
+	if aErr != nil {
+		return nil, aErr
+	}
+	left := p.maxHourlyCost
+	for _, node := range nodes {
+		price, err := pricingModel.NodePrice(node, now, now.Add(time.Hour))
+		if err != nil {
+			klog.Warningf("Failed to get price of node %s, not counting it in the hourly cost: %v", node.Name, err)
+			continue
+		}
+		left -= price
+	}
+	for _, nodeInfo := range upcomingNodes {
+		price, err := pricingModel.NodePrice(nodeInfo.Node(), now, now.Add(time.Hour))
+		if err != nil {
+			klog.Warningf("Failed to get price of upcoming node %s, not counting it in the hourly cost: %v", nodeInfo.Node().Name, err)
+			continue
+		}
+		left -= price
+	}
+	if left < 0 {
+		left = 0
+	}
+	return NewBudget(left), nil
+}
+
+// NodeHourlyCost returns the price of running the template node of the node
+// group for an hour. It's 0 if scale-ups aren't limited.
+func (p *pricingScaleUpCostProcessor) NodeHourlyCost(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup, nodeInfo *schedulerframework.NodeInfo, now time.Time) (float64, errors.AutoscalerError) {
+	if p.maxHourlyCost <= 0 {
+		return 0, nil
+	}
+	pricingModel, aErr := p.pricingModel(context)
+	if aErr != nil {
+		return 0, aErr
+	}
+	price, err := pricingModel.NodePrice(nodeInfo.Node(), now, now.Add(time.Hour))
+	if err != nil {
+		return 0, errors.NewAutoscalerError(errors.CloudProviderError, "failed to get price of node group %s: %v", nodeGroup.Id(), err)
+	}
+	return price, nil
+}
+
+func (p *pricingScaleUpCostProcessor) pricingModel(context *context.AutoscalingContext) (cloudprovider.PricingModel, errors.AutoscalerError) {
+	pricingModel, err := context.CloudProvider.Pricing()
+	if err != nil {
+		return nil, errors.NewAutoscalerError(errors.CloudProviderError, "max hourly cost is set, but pricing model is not available: %v", err)
+	}
+	return pricingModel, nil
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *pricingScaleUpCostProcessor) CleanUp() {
+}
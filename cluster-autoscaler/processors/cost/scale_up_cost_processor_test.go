@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cost
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+type testPricingModel struct {
+	nodePrice map[string]float64
+}
+
+func (tpm *testPricingModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	if price, found := tpm.nodePrice[node.Name]; found {
+		return price * endTime.Sub(startTime).Hours(), nil
+	}
+	return 0.0, fmt.Errorf("price for node %v not found", node.Name)
+}
+
+func (tpm *testPricingModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	return 0.0, fmt.Errorf("price for pod %v not found", pod.Name)
+}
+
+func TestBudget(t *testing.T) {
+	unlimited := UnlimitedBudget()
+	assert.False(t, unlimited.Limited())
+	assert.Equal(t, 10, unlimited.Reserve(5, 10))
+
+	budget := NewBudget(10)
+	assert.True(t, budget.Limited())
+	assert.Equal(t, 3, budget.Fits(3, 5))
+	assert.Equal(t, 2, budget.Fits(3, 2))
+	assert.Equal(t, 0, budget.Fits(11, 1))
+	assert.Equal(t, 5, budget.Fits(0, 5))
+
+	assert.Equal(t, 2, budget.Reserve(4, 5))
+	assert.Equal(t, 2.0, budget.Left())
+	assert.Equal(t, 0, budget.Reserve(4, 1))
+	assert.Equal(t, 2.0, budget.Left())
+}
+
+func TestHourlyCostLeft(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	template := schedulerframework.NewNodeInfo()
+	template.SetNode(BuildTestNode("template", 1000, 1000))
+	now := time.Now()
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng", 0, 10, 1)
+	ctx := &context.AutoscalingContext{CloudProvider: provider}
+
+	// Scale-ups aren't limited, even without a pricing model.
+	budget, err := NewScaleUpCostProcessor(0).HourlyCostLeft(ctx, []*apiv1.Node{n1, n2}, nil, now)
+	require.Nil(t, err)
+	assert.False(t, budget.Limited())
+	cost, err := NewScaleUpCostProcessor(0).NodeHourlyCost(ctx, provider.GetNodeGroup("ng"), template, now)
+	require.Nil(t, err)
+	assert.Equal(t, 0.0, cost)
+
+	processor := NewScaleUpCostProcessor(10)
+	_, err = processor.HourlyCostLeft(ctx, []*apiv1.Node{n1, n2}, nil, now)
+	assert.NotNil(t, err)
+
+	provider.SetPricingModel(&testPricingModel{nodePrice: map[string]float64{"n1": 1, "n2": 2, "template": 3}})
+	budget, err = processor.HourlyCostLeft(ctx, []*apiv1.Node{n1, n2}, []*schedulerframework.NodeInfo{template}, now)
+	require.Nil(t, err)
+	assert.True(t, budget.Limited())
+	assert.Equal(t, 4.0, budget.Left())
+	cost, err = processor.NodeHourlyCost(ctx, provider.GetNodeGroup("ng"), template, now)
+	require.Nil(t, err)
+	assert.Equal(t, 3.0, cost)
+
+	budget, err = processor.HourlyCostLeft(ctx, []*apiv1.Node{n1, n2}, []*schedulerframework.NodeInfo{template, template, template}, now)
+	require.Nil(t, err)
+	assert.Equal(t, 0.0, budget.Left())
+
+	// Nodes which can't be priced aren't counted.
+	budget, err = processor.HourlyCostLeft(ctx, []*apiv1.Node{n1, BuildTestNode("unknown", 1000, 1000)}, nil, now)
+	require.Nil(t, err)
+	assert.Equal(t, 9.0, budget.Left())
+}
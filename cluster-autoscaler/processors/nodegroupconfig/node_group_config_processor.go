@@ -37,6 +37,12 @@ type NodeGroupConfigProcessor interface {
 	GetMaxNodeProvisionTime(nodeGroup cloudprovider.NodeGroup) (time.Duration, error)
 	// GetIgnoreDaemonSetsUtilization returns IgnoreDaemonSetsUtilization value that should be used for a given NodeGroup.
 	GetIgnoreDaemonSetsUtilization(nodeGroup cloudprovider.NodeGroup) (bool, error)
+	// GetMaxConcurrentProvisioning returns MaxConcurrentProvisioning value that should be used for a given NodeGroup.
+	GetMaxConcurrentProvisioning(nodeGroup cloudprovider.NodeGroup) (int, error)
+	// GetMaxScaleDownParallelism returns MaxScaleDownParallelism value that should be used for a given NodeGroup.
+	GetMaxScaleDownParallelism(nodeGroup cloudprovider.NodeGroup) (int, error)
+	// GetMetadata returns Metadata that should be used for a given NodeGroup.
+	GetMetadata(nodeGroup cloudprovider.NodeGroup) (map[string]string, error)
 	// CleanUp cleans up processor's internal structures.
 	CleanUp()
 }
@@ -120,6 +126,42 @@ func (p *DelegatingNodeGroupConfigProcessor) GetIgnoreDaemonSetsUtilization(node
 	return ngConfig.IgnoreDaemonSetsUtilization, nil
 }
 
+// GetMaxConcurrentProvisioning returns MaxConcurrentProvisioning value that should be used for a given NodeGroup.
+func (p *DelegatingNodeGroupConfigProcessor) GetMaxConcurrentProvisioning(nodeGroup cloudprovider.NodeGroup) (int, error) {
+	ngConfig, err := nodeGroup.GetOptions(p.nodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		return 0, err
+	}
+	if ngConfig == nil || err == cloudprovider.ErrNotImplemented {
+		return p.nodeGroupDefaults.MaxConcurrentProvisioning, nil
+	}
+	return ngConfig.MaxConcurrentProvisioning, nil
+}
+
+// GetMaxScaleDownParallelism returns MaxScaleDownParallelism value that should be used for a given NodeGroup.
+func (p *DelegatingNodeGroupConfigProcessor) GetMaxScaleDownParallelism(nodeGroup cloudprovider.NodeGroup) (int, error) {
+	ngConfig, err := nodeGroup.GetOptions(p.nodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		return 0, err
+	}
+	if ngConfig == nil || err == cloudprovider.ErrNotImplemented {
+		return p.nodeGroupDefaults.MaxScaleDownParallelism, nil
+	}
+	return ngConfig.MaxScaleDownParallelism, nil
+}
+
+// GetMetadata returns Metadata that should be used for a given NodeGroup.
+func (p *DelegatingNodeGroupConfigProcessor) GetMetadata(nodeGroup cloudprovider.NodeGroup) (map[string]string, error) {
+	ngConfig, err := nodeGroup.GetOptions(p.nodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		return nil, err
+	}
+	if ngConfig == nil || err == cloudprovider.ErrNotImplemented {
+		return p.nodeGroupDefaults.Metadata, nil
+	}
+	return ngConfig.Metadata, nil
+}
+
 // CleanUp cleans up processor's internal structures.
 func (p *DelegatingNodeGroupConfigProcessor) CleanUp() {
 }
@@ -48,6 +48,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		ScaleDownUtilizationThreshold:    0.5,
 		MaxNodeProvisionTime:             15 * time.Minute,
 		IgnoreDaemonSetsUtilization:      true,
+		MaxConcurrentProvisioning:        5,
+		MaxScaleDownParallelism:          7,
+		Metadata:                         map[string]string{"costclass": "standard"},
 	}
 	ngOpts := &config.NodeGroupAutoscalingOptions{
 		ScaleDownUnneededTime:            10 * time.Minute,
@@ -56,6 +59,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		ScaleDownUtilizationThreshold:    0.75,
 		MaxNodeProvisionTime:             60 * time.Minute,
 		IgnoreDaemonSetsUtilization:      false,
+		MaxConcurrentProvisioning:        2,
+		MaxScaleDownParallelism:          3,
+		Metadata:                         map[string]string{"costclass": "spot", "priority": "10"},
 	}
 
 	testUnneededTime := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
@@ -121,6 +127,39 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		assert.Equal(t, res, results[w])
 	}
 
+	testMaxConcurrentProvisioning := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
+		res, err := p.GetMaxConcurrentProvisioning(ng)
+		assert.Equal(t, err, we)
+		results := map[Want]int{
+			NIL:    0,
+			GLOBAL: 5,
+			NG:     2,
+		}
+		assert.Equal(t, res, results[w])
+	}
+
+	testMaxScaleDownParallelism := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
+		res, err := p.GetMaxScaleDownParallelism(ng)
+		assert.Equal(t, err, we)
+		results := map[Want]int{
+			NIL:    0,
+			GLOBAL: 7,
+			NG:     3,
+		}
+		assert.Equal(t, res, results[w])
+	}
+
+	testMetadata := func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
+		res, err := p.GetMetadata(ng)
+		assert.Equal(t, err, we)
+		results := map[Want]map[string]string{
+			NIL:    nil,
+			GLOBAL: {"costclass": "standard"},
+			NG:     {"costclass": "spot", "priority": "10"},
+		}
+		assert.Equal(t, res, results[w])
+	}
+
 	funcs := map[string]func(*testing.T, NodeGroupConfigProcessor, cloudprovider.NodeGroup, Want, error){
 		"ScaleDownUnneededTime":            testUnneededTime,
 		"ScaleDownUnreadyTime":             testUnreadyTime,
@@ -128,6 +167,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 		"ScaleDownGpuUtilizationThreshold": testGpuThreshold,
 		"MaxNodeProvisionTime":             testMaxNodeProvisionTime,
 		"IgnoreDaemonSetsUtilization":      testIgnoreDSUtilization,
+		"MaxConcurrentProvisioning":        testMaxConcurrentProvisioning,
+		"MaxScaleDownParallelism":          testMaxScaleDownParallelism,
+		"Metadata":                         testMetadata,
 		"MultipleOptions": func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
 			testUnneededTime(t, p, ng, w, we)
 			testUnreadyTime(t, p, ng, w, we)
@@ -135,6 +177,9 @@ func TestDelegatingNodeGroupConfigProcessor(t *testing.T) {
 			testGpuThreshold(t, p, ng, w, we)
 			testMaxNodeProvisionTime(t, p, ng, w, we)
 			testIgnoreDSUtilization(t, p, ng, w, we)
+			testMaxConcurrentProvisioning(t, p, ng, w, we)
+			testMaxScaleDownParallelism(t, p, ng, w, we)
+			testMetadata(t, p, ng, w, we)
 		},
 		"RepeatingTheSameCallGivesConsistentResults": func(t *testing.T, p NodeGroupConfigProcessor, ng cloudprovider.NodeGroup, w Want, we error) {
 			testUnneededTime(t, p, ng, w, we)
@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeinfosprovider
+
+import (
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/runtimeclass"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// RuntimeClassNodeInfoProvider is a wrapper for TemplateNodeInfoProvider simulating RuntimeClasses on the NodeInfos.
+type RuntimeClassNodeInfoProvider struct {
+	templateNodeInfoProvider TemplateNodeInfoProvider
+	runtimeClassProvider     *runtimeclass.Provider
+}
+
+// NewRuntimeClassNodeInfoProvider returns RuntimeClassNodeInfoProvider wrapping TemplateNodeInfoProvider.
+func NewRuntimeClassNodeInfoProvider(templateNodeInfoProvider TemplateNodeInfoProvider, runtimeClassProvider *runtimeclass.Provider) *RuntimeClassNodeInfoProvider {
+	return &RuntimeClassNodeInfoProvider{
+		templateNodeInfoProvider: templateNodeInfoProvider,
+		runtimeClassProvider:     runtimeClassProvider,
+	}
+}
+
+// Process returns the nodeInfos set for this cluster. The overhead of their RuntimeClasses is set
+// on pods, and labels required by RuntimeClasses of handlers listed on nodes are added to them.
+func (p *RuntimeClassNodeInfoProvider) Process(ctx *context.AutoscalingContext, nodes []*apiv1.Node, daemonsets []*appsv1.DaemonSet, taintConfig taints.TaintConfig, currentTime time.Time) (map[string]*schedulerframework.NodeInfo, errors.AutoscalerError) {
+	nodeInfos, err := p.templateNodeInfoProvider.Process(ctx, nodes, daemonsets, taintConfig, currentTime)
+	if err != nil {
+		return nil, err
+	}
+	for id, nodeInfo := range nodeInfos {
+		nodeInfos[id] = p.withRuntimeClasses(nodeInfo)
+	}
+	return nodeInfos, nil
+}
+
+func (p *RuntimeClassNodeInfoProvider) withRuntimeClasses(nodeInfo *schedulerframework.NodeInfo) *schedulerframework.NodeInfo {
+	node := nodeInfo.Node()
+	if nodeLabels := p.runtimeClassProvider.NodeLabels(node); len(nodeLabels) > 0 {
+		node = node.DeepCopy()
+		for key, value := range nodeLabels {
+			if _, found := node.Labels[key]; !found {
+				node.Labels[key] = value
+			}
+		}
+	}
+	pods := make([]*apiv1.Pod, 0, len(nodeInfo.Pods))
+	for _, podInfo := range nodeInfo.Pods {
+		pods = append(pods, p.runtimeClassProvider.PodWithOverhead(podInfo.Pod))
+	}
+	result := schedulerframework.NewNodeInfo(pods...)
+	result.SetNode(node)
+	return result
+}
+
+// CleanUp cleans up processor's internal structures.
+func (p *RuntimeClassNodeInfoProvider) CleanUp() {
+	p.templateNodeInfoProvider.CleanUp()
+}
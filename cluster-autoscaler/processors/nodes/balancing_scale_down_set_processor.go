@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"reflect"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// BalancingScaleDownSetProcessor keeps similar node groups balanced on scale-down,
+// e.g. node groups of the same shape in different zones. A candidate is dropped if
+// removing it would make the sizes of its node group and the similar ones differ by
+// more than maxSkew nodes, unless the removal doesn't increase the difference.
+type BalancingScaleDownSetProcessor struct {
+	nodeGroupSetProcessor nodegroupset.NodeGroupSetProcessor
+	maxSkew               int
+}
+
+// NewBalancingScaleDownSetProcessor returns a new BalancingScaleDownSetProcessor finding
+// similar node groups with the given NodeGroupSetProcessor.
+func NewBalancingScaleDownSetProcessor(nodeGroupSetProcessor nodegroupset.NodeGroupSetProcessor, maxSkew int) *BalancingScaleDownSetProcessor {
+	return &BalancingScaleDownSetProcessor{
+		nodeGroupSetProcessor: nodeGroupSetProcessor,
+		maxSkew:               maxSkew,
+	}
+}
+
+// GetNodesToRemove selects the candidates whose removal keeps similar node groups balanced.
+func (p *BalancingScaleDownSetProcessor) GetNodesToRemove(ctx *context.AutoscalingContext, candidates []simulator.NodeToBeRemoved, maxCount int) []simulator.NodeToBeRemoved {
+	if len(candidates) == 0 {
+		return candidates
+	}
+	nodeInfosForGroups := groupNodeInfos(ctx)
+	similarGroups := map[string][]cloudprovider.NodeGroup{}
+	sizes := map[string]int{}
+	result := []simulator.NodeToBeRemoved{}
+	for _, node := range candidates {
+		nodeGroup, err := ctx.CloudProvider.NodeGroupForNode(node.Node)
+		if err != nil {
+			klog.Errorf("Node %v will not scale down, failed to get node group: %s", node.Node.Name, err)
+			continue
+		}
+		if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+			result = append(result, node)
+			continue
+		}
+		group, found := similarGroups[nodeGroup.Id()]
+		if !found {
+			group = []cloudprovider.NodeGroup{nodeGroup}
+			if _, found := nodeInfosForGroups[nodeGroup.Id()]; found {
+				similar, err := p.nodeGroupSetProcessor.FindSimilarNodeGroups(ctx, nodeGroup, nodeInfosForGroups)
+				if err != nil {
+					klog.Warningf("Failed to find node groups similar to %s: %v", nodeGroup.Id(), err)
+				}
+				group = append(group, similar...)
+			}
+			similarGroups[nodeGroup.Id()] = group
+		}
+		if len(group) == 1 {
+			result = append(result, node)
+			continue
+		}
+
+		before, err := sizeSkew(group, sizes, "")
+		if err != nil {
+			klog.Errorf("Node %v will not scale down, failed to get target sizes of similar node groups: %v", node.Node.Name, err)
+			continue
+		}
+		after, _ := sizeSkew(group, sizes, nodeGroup.Id())
+		if after > p.maxSkew && after > before {
+			klog.V(2).Infof("Skipping scale down of node %s, it would increase the skew of node group %s and %d similar node groups to %d (max: %d)",
+				node.Node.Name, nodeGroup.Id(), len(group)-1, after, p.maxSkew)
+			continue
+		}
+		sizes[nodeGroup.Id()]--
+		result = append(result, node)
+	}
+	return result
+}
+
+// CleanUp is called at CA termination
+func (p *BalancingScaleDownSetProcessor) CleanUp() {
+}
+
+// sizeSkew returns the difference between the largest and the smallest size of the given
+// node groups, with one node removed from the node group removedFrom. Sizes of node
+// groups are cached in sizes, with the removals already planned applied.
+func sizeSkew(group []cloudprovider.NodeGroup, sizes map[string]int, removedFrom string) (int, error) {
+	smallest, largest := 0, 0
+	for i, nodeGroup := range group {
+		size, found := sizes[nodeGroup.Id()]
+		if !found {
+			targetSize, err := nodeGroup.TargetSize()
+			if err != nil {
+				return 0, err
+			}
+			size = targetSize
+			sizes[nodeGroup.Id()] = size
+		}
+		if nodeGroup.Id() == removedFrom {
+			size--
+		}
+		if i == 0 || size < smallest {
+			smallest = size
+		}
+		if i == 0 || size > largest {
+			largest = size
+		}
+	}
+	return largest - smallest, nil
+}
+
+// groupNodeInfos returns a node info of an existing node of each node group,
+// without pods, so that node groups are compared by the shape of their nodes only.
+// Node groups without nodes are left out.
+func groupNodeInfos(ctx *context.AutoscalingContext) map[string]*schedulerframework.NodeInfo {
+	result := map[string]*schedulerframework.NodeInfo{}
+	nodeInfos, err := ctx.ClusterSnapshot.NodeInfos().List()
+	if err != nil {
+		klog.Errorf("Failed to list nodes from cluster snapshot: %v", err)
+		return result
+	}
+	for _, nodeInfo := range nodeInfos {
+		node := nodeInfo.Node()
+		nodeGroup, err := ctx.CloudProvider.NodeGroupForNode(node)
+		if err != nil || nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+			continue
+		}
+		if existing, found := result[nodeGroup.Id()]; found && existing.Node().Name < node.Name {
+			continue
+		}
+		groupNodeInfo := schedulerframework.NewNodeInfo()
+		groupNodeInfo.SetNode(node)
+		result[nodeGroup.Id()] = groupNodeInfo
+	}
+	return result
+}
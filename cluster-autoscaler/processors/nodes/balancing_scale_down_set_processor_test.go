@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/config"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestBalancingScaleDownSetProcessor(t *testing.T) {
+	testCases := []struct {
+		name       string
+		groupSizes map[string]int
+		candidates []string
+		want       []string
+	}{
+		{
+			name:       "removals keeping similar groups within max skew",
+			groupSizes: map[string]int{"ng1": 3, "ng2": 3, "ng3": 2},
+			candidates: []string{"ng3-0", "ng1-0", "ng2-0", "ng1-1", "ng1-2"},
+			want:       []string{"ng1-0", "ng2-0", "ng1-1"},
+		},
+		{
+			name:       "removals reducing skew above max skew",
+			groupSizes: map[string]int{"ng1": 5, "ng2": 1, "ng3": 1},
+			candidates: []string{"ng2-0", "ng1-0", "ng1-1"},
+			want:       []string{"ng1-0", "ng1-1"},
+		},
+		{
+			name:       "group without similar groups",
+			groupSizes: map[string]int{"big": 2, "ng1": 1},
+			candidates: []string{"big-0", "big-1", "ng1-0"},
+			want:       []string{"big-0", "big-1", "ng1-0"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			nodesByName := map[string]*apiv1.Node{}
+			var nodes []*apiv1.Node
+			for group, size := range tc.groupSizes {
+				provider.AddNodeGroup(group, 0, 10, size)
+				for i := 0; i < size; i++ {
+					var node *apiv1.Node
+					if group == "big" {
+						node = BuildTestNode(fmt.Sprintf("%s-%d", group, i), 4000, 4000)
+					} else {
+						node = BuildTestNode(fmt.Sprintf("%s-%d", group, i), 1000, 1000)
+					}
+					provider.AddNode(group, node)
+					nodesByName[node.Name] = node
+					nodes = append(nodes, node)
+				}
+			}
+			snapshot := clustersnapshot.NewBasicClusterSnapshot()
+			clustersnapshot.InitializeClusterSnapshotOrDie(t, snapshot, nodes, nil)
+			ctx := &context.AutoscalingContext{
+				CloudProvider:   provider,
+				ClusterSnapshot: snapshot,
+			}
+			processor := NewBalancingScaleDownSetProcessor(nodegroupset.NewDefaultNodeGroupSetProcessor([]string{}, config.NodeGroupDifferenceRatios{
+				MaxAllocatableDifferenceRatio:    config.DefaultMaxAllocatableDifferenceRatio,
+				MaxCapacityMemoryDifferenceRatio: config.DefaultMaxCapacityMemoryDifferenceRatio,
+				MaxFreeDifferenceRatio:           config.DefaultMaxFreeDifferenceRatio,
+			}), 1)
+
+			var candidates []simulator.NodeToBeRemoved
+			for _, name := range tc.candidates {
+				candidates = append(candidates, simulator.NodeToBeRemoved{Node: nodesByName[name]})
+			}
+			var got []string
+			for _, node := range processor.GetNodesToRemove(ctx, candidates, len(candidates)) {
+				got = append(got, node.Node.Name)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
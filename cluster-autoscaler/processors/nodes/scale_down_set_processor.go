@@ -19,6 +19,7 @@ package nodes
 import (
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pipeline"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/klogx"
 	klog "k8s.io/klog/v2"
@@ -37,6 +38,16 @@ func NewCompositeScaleDownSetProcessor(orderedProcessorList []ScaleDownSetProces
 	}
 }
 
+// DefaultScaleDownSetProcessorStages returns the default sub-processors of
+// CompositeScaleDownSetProcessor in the order they're invoked, named for the
+// processors pipeline file.
+func DefaultScaleDownSetProcessorStages() []pipeline.Stage[ScaleDownSetProcessor] {
+	return []pipeline.Stage[ScaleDownSetProcessor]{
+		{Name: "max-nodes", Processor: NewMaxNodesProcessor()},
+		{Name: "atomic-resize-filtering", Processor: NewAtomicResizeFilteringProcessor()},
+	}
+}
+
 // GetNodesToRemove selects nodes to remove.
 func (p *CompositeScaleDownSetProcessor) GetNodesToRemove(ctx *context.AutoscalingContext, candidates []simulator.NodeToBeRemoved, maxCount int) []simulator.NodeToBeRemoved {
 	for _, p := range p.orderedProcessorList {
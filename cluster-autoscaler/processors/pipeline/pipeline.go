@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is a declarative definition of the processors pipeline, loaded from
+// a YAML file at startup. Empty fields keep the default behavior, so a file
+// only has to mention the parts it customizes, e.g.:
+//
+//	podListProcessors:
+//	- filter-out-expendable
+//	- filter-out-schedulable
+//	balanceSimilarNodeGroups: false
+type Config struct {
+	// PodListProcessors are names of pod list processors run in the given
+	// order. Processors which aren't listed don't run.
+	PodListProcessors []string `json:"podListProcessors,omitempty"`
+	// ScaleDownSetProcessors are names of processors filtering the set of
+	// nodes to scale down, run in the given order.
+	ScaleDownSetProcessors []string `json:"scaleDownSetProcessors,omitempty"`
+	// BalanceSimilarNodeGroups overrides the --balance-similar-node-groups flag.
+	BalanceSimilarNodeGroups *bool `json:"balanceSimilarNodeGroups,omitempty"`
+}
+
+// Stage is a processor which can be referenced by name in the pipeline file.
+type Stage[T any] struct {
+	Name      string
+	Processor T
+}
+
+// Load reads the pipeline file. Unknown fields are rejected, so that typos
+// don't silently fall back to the default behavior.
+func Load(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(content, config); err != nil {
+		return nil, fmt.Errorf("failed to parse processors pipeline %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// Build returns processors of the named stages in the order of names. If
+// names is empty, processors of all stages are returned in the default order.
+func Build[T any](names []string, stages []Stage[T]) ([]T, error) {
+	if len(names) == 0 {
+		processors := make([]T, 0, len(stages))
+		for _, stage := range stages {
+			processors = append(processors, stage.Processor)
+		}
+		return processors, nil
+	}
+	byName := make(map[string]T, len(stages))
+	for _, stage := range stages {
+		byName[stage.Name] = stage.Processor
+	}
+	processors := make([]T, 0, len(names))
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		processor, found := byName[name]
+		if !found {
+			return nil, fmt.Errorf("unknown processor %q, available processors: %v", name, stageNames(stages))
+		}
+		if used[name] {
+			return nil, fmt.Errorf("processor %q is listed more than once", name)
+		}
+		used[name] = true
+		processors = append(processors, processor)
+	}
+	return processors, nil
+}
+
+// InsertBefore returns the stages with the stage inserted before the stage
+// named next, or appended if there's no such stage.
+func InsertBefore[T any](stages []Stage[T], next string, stage Stage[T]) []Stage[T] {
+	for i := range stages {
+		if stages[i].Name == next {
+			inserted := make([]Stage[T], 0, len(stages)+1)
+			inserted = append(inserted, stages[:i]...)
+			inserted = append(inserted, stage)
+			return append(inserted, stages[i:]...)
+		}
+	}
+	return append(stages, stage)
+}
+
+func stageNames[T any](stages []Stage[T]) []string {
+	names := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		names = append(names, stage.Name)
+	}
+	return names
+}
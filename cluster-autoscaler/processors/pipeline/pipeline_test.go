@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name      string
+		content   string
+		want      *Config
+		wantError bool
+	}{
+		{
+			name:    "empty",
+			content: "",
+			want:    &Config{},
+		},
+		{
+			name: "full",
+			content: `
+podListProcessors:
+- filter-out-schedulable
+- filter-out-expendable
+scaleDownSetProcessors:
+- max-nodes
+balanceSimilarNodeGroups: false
+`,
+			want: &Config{
+				PodListProcessors:        []string{"filter-out-schedulable", "filter-out-expendable"},
+				ScaleDownSetProcessors:   []string{"max-nodes"},
+				BalanceSimilarNodeGroups: new(bool),
+			},
+		},
+		{
+			name:      "unknown field",
+			content:   "podListProcesors: [filter-out-schedulable]",
+			wantError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pipeline.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(tc.content), 0600))
+			got, err := Load(path)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	stages := []Stage[int]{{Name: "a", Processor: 1}, {Name: "b", Processor: 2}, {Name: "c", Processor: 3}}
+	testCases := []struct {
+		name      string
+		names     []string
+		want      []int
+		wantError bool
+	}{
+		{name: "default", want: []int{1, 2, 3}},
+		{name: "reordered subset", names: []string{"c", "a"}, want: []int{3, 1}},
+		{name: "unknown", names: []string{"a", "d"}, wantError: true},
+		{name: "duplicate", names: []string{"a", "a"}, wantError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Build(tc.names, stages)
+			if tc.wantError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestInsertBefore(t *testing.T) {
+	stages := []Stage[int]{{Name: "a", Processor: 1}, {Name: "b", Processor: 2}}
+	assert.Equal(t, []Stage[int]{{Name: "a", Processor: 1}, {Name: "x", Processor: 9}, {Name: "b", Processor: 2}}, InsertBefore(stages, "b", Stage[int]{Name: "x", Processor: 9}))
+	assert.Equal(t, []Stage[int]{{Name: "a", Processor: 1}, {Name: "b", Processor: 2}, {Name: "x", Processor: 9}}, InsertBefore(stages, "c", Stage[int]{Name: "x", Processor: 9}))
+	assert.Equal(t, []Stage[int]{{Name: "a", Processor: 1}, {Name: "b", Processor: 2}}, stages)
+}
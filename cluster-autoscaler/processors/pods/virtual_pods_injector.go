@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pods
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	klog "k8s.io/klog/v2"
+)
+
+// VirtualPodSource provides virtual pods, i.e. pods which don't exist in the
+// cluster, but capacity should be provisioned for.
+type VirtualPodSource interface {
+	// VirtualPods returns the virtual pods to be injected. Sources skip
+	// invalid objects themselves, an error means no pods can be provided.
+	VirtualPods() ([]*apiv1.Pod, error)
+}
+
+// VirtualPodsInjector injects virtual pods of a source into the unschedulable
+// pods list. It has to run before unschedulable pods which fit are filtered
+// out, so that virtual pods fitting on existing nodes are added to the cluster
+// snapshot and keep the capacity from being used by other pods in the
+// simulations, while the remaining ones trigger scale-up.
+type VirtualPodsInjector struct {
+	name   string
+	source VirtualPodSource
+}
+
+// NewVirtualPodsInjector creates a processor injecting virtual pods of the
+// source, which is referred to by name in logs.
+func NewVirtualPodsInjector(name string, source VirtualPodSource) *VirtualPodsInjector {
+	return &VirtualPodsInjector{name: name, source: source}
+}
+
+// Process injects virtual pods of the source into the unschedulable pods list.
+func (p *VirtualPodsInjector) Process(
+	_ *context.AutoscalingContext,
+	unschedulablePods []*apiv1.Pod,
+) ([]*apiv1.Pod, error) {
+	virtualPods, err := p.source.VirtualPods()
+	if err != nil {
+		// Virtual pods are best effort, they shouldn't block scaling for pending pods.
+		klog.Errorf("Failed to get virtual pods of %s: %v", p.name, err)
+		return unschedulablePods, nil
+	}
+	if len(virtualPods) > 0 {
+		klog.V(4).Infof("Injecting %d virtual pods of %s", len(virtualPods), p.name)
+	}
+	return append(unschedulablePods, virtualPods...), nil
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *VirtualPodsInjector) CleanUp() {}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pods
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+type fakeVirtualPodSource struct {
+	pods []*apiv1.Pod
+	err  error
+}
+
+func (f *fakeVirtualPodSource) VirtualPods() ([]*apiv1.Pod, error) {
+	return f.pods, f.err
+}
+
+func TestVirtualPodsInjector(t *testing.T) {
+	pendingPod := BuildTestPod("pending", 100, 100)
+	virtualPods := []*apiv1.Pod{BuildTestPod("virtual-1", 100, 100), BuildTestPod("virtual-2", 100, 100)}
+
+	testCases := []struct {
+		name         string
+		source       *fakeVirtualPodSource
+		expectedPods []*apiv1.Pod
+	}{
+		{
+			name:         "no virtual pods",
+			source:       &fakeVirtualPodSource{},
+			expectedPods: []*apiv1.Pod{pendingPod},
+		},
+		{
+			name:         "virtual pods",
+			source:       &fakeVirtualPodSource{pods: virtualPods},
+			expectedPods: append([]*apiv1.Pod{pendingPod}, virtualPods...),
+		},
+		{
+			name:         "source fails",
+			source:       &fakeVirtualPodSource{pods: virtualPods, err: fmt.Errorf("no CRD")},
+			expectedPods: []*apiv1.Pod{pendingPod},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			injector := NewVirtualPodsInjector("test", tc.source)
+			pods, err := injector.Process(nil, []*apiv1.Pod{pendingPod})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPods, pods)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/observers/nodegroupchange"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/actionablecluster"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/binpacking"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/cost"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/customresources"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroups"
@@ -43,6 +44,8 @@ type AutoscalingProcessors struct {
 	BinpackingLimiter binpacking.BinpackingLimiter
 	// NodeGroupSetProcessor is used to divide scale-up between similar NodeGroups.
 	NodeGroupSetProcessor nodegroupset.NodeGroupSetProcessor
+	// ScaleUpCostProcessor is used to limit scale-ups to a budget of hourly cost of the cluster.
+	ScaleUpCostProcessor cost.ScaleUpCostProcessor
 	// ScaleUpStatusProcessor is used to process the state of the cluster after a scale-up.
 	ScaleUpStatusProcessor status.ScaleUpStatusProcessor
 	// ScaleDownNodeProcessor is used to process the nodes of the cluster before scale-down.
@@ -84,7 +87,8 @@ func DefaultProcessors(options config.AutoscalingOptions) *AutoscalingProcessors
 			MaxCapacityMemoryDifferenceRatio: config.DefaultMaxCapacityMemoryDifferenceRatio,
 			MaxFreeDifferenceRatio:           config.DefaultMaxFreeDifferenceRatio,
 		}),
-		ScaleUpStatusProcessor: status.NewDefaultScaleUpStatusProcessor(),
+		ScaleUpCostProcessor:   cost.NewScaleUpCostProcessor(options.MaxHourlyCost),
+		ScaleUpStatusProcessor: &status.EventingScaleUpStatusProcessor{RecordPodConditions: options.RecordNoScaleUpPodConditions},
 		ScaleDownNodeProcessor: nodes.NewPreFilteringScaleDownNodeProcessor(),
 		ScaleDownSetProcessor: nodes.NewCompositeScaleDownSetProcessor(
 			[]nodes.ScaleDownSetProcessor{
@@ -109,6 +113,7 @@ func (ap *AutoscalingProcessors) CleanUp() {
 	ap.PodListProcessor.CleanUp()
 	ap.NodeGroupListProcessor.CleanUp()
 	ap.NodeGroupSetProcessor.CleanUp()
+	ap.ScaleUpCostProcessor.CleanUp()
 	ap.ScaleUpStatusProcessor.CleanUp()
 	ap.ScaleDownSetProcessor.CleanUp()
 	ap.ScaleDownStatusProcessor.CleanUp()
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/autoscaler/cluster-autoscaler/apis/provisioningrequest/autoscaling.x-k8s.io/v1beta1"
@@ -32,7 +33,7 @@ import (
 func TestProcess(t *testing.T) {
 	now := time.Now()
 	dayAgo := now.Add(-1 * 24 * time.Hour)
-	weekAgo := now.Add(-1 * defaultExpirationTime).Add(-1 * 5 * time.Minute)
+	weekAgo := now.Add(-1 * DefaultExpirationTime).Add(-1 * 5 * time.Minute)
 
 	testCases := []struct {
 		name           string
@@ -146,7 +147,7 @@ func TestProcess(t *testing.T) {
 		additionalPr := provreqclient.ProvisioningRequestWrapperForTesting("namespace", "additional")
 		additionalPr.CreationTimestamp = metav1.NewTime(weekAgo)
 		additionalPr.Spec.ProvisioningClassName = v1beta1.ProvisioningClassCheckCapacity
-		processor := provReqProcessor{func() time.Time { return now }, 1, provreqclient.NewFakeProvisioningRequestClient(nil, t, pr, additionalPr)}
+		processor := provReqProcessor{func() time.Time { return now }, 1, provreqclient.NewFakeProvisioningRequestClient(nil, t, pr, additionalPr), DefaultReservationTime, DefaultExpirationTime}
 		processor.Process([]*provreqwrapper.ProvisioningRequest{pr, additionalPr})
 		assert.ElementsMatch(t, test.wantConditions, pr.Status.Conditions)
 		if len(test.conditions) == len(test.wantConditions) {
@@ -164,3 +165,50 @@ func TestProcess(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessValidUntil(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name         string
+		creationTime time.Time
+		validUntil   v1beta1.Parameter
+		wantReason   string
+	}{
+		{
+			name:         "valid",
+			creationTime: now.Add(-30 * time.Minute),
+			validUntil:   "3600",
+		},
+		{
+			name:         "expired before default expiration time",
+			creationTime: now.Add(-2 * time.Hour),
+			validUntil:   "3600",
+			wantReason:   conditions.ExpiredReason,
+		},
+		{
+			name:         "invalid parameter",
+			creationTime: now,
+			validUntil:   "1h",
+			wantReason:   conditions.InvalidParametersReason,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			pr := provreqclient.ProvisioningRequestWrapperForTesting("namespace", "name-1")
+			pr.CreationTimestamp = metav1.NewTime(test.creationTime)
+			pr.Spec.ProvisioningClassName = v1beta1.ProvisioningClassBestEffortAtomicScaleUp
+			pr.Spec.Parameters = map[string]v1beta1.Parameter{provreqwrapper.ValidUntilSecondsKey: test.validUntil}
+			processor := NewProvReqProcessor(provreqclient.NewFakeProvisioningRequestClient(nil, t, pr), DefaultReservationTime, DefaultExpirationTime)
+			processor.now = func() time.Time { return now }
+			processor.Process([]*provreqwrapper.ProvisioningRequest{pr})
+			failed := apimeta.FindStatusCondition(pr.Status.Conditions, v1beta1.Failed)
+			if test.wantReason == "" {
+				assert.Nil(t, failed)
+				return
+			}
+			if assert.NotNil(t, failed) {
+				assert.Equal(t, test.wantReason, failed.Reason)
+			}
+		})
+	}
+}
@@ -30,21 +30,28 @@ import (
 )
 
 const (
-	defaultReservationTime = 10 * time.Minute
-	defaultExpirationTime  = 7 * 24 * time.Hour // 7 days
+	// DefaultReservationTime is how long capacity is booked for Provisioned ProvisioningRequests by default.
+	DefaultReservationTime = 10 * time.Minute
+	// DefaultExpirationTime is how long CA tries to provision capacity for ProvisioningRequests
+	// without the ValidUntilSeconds parameter by default.
+	DefaultExpirationTime = 7 * 24 * time.Hour // 7 days
 	// defaultMaxUpdated is a limit for ProvisioningRequest to update conditions in one ClusterAutoscaler loop.
 	defaultMaxUpdated = 20
 )
 
 type provReqProcessor struct {
-	now        func() time.Time
-	maxUpdated int
-	client     *provreqclient.ProvisioningRequestClient
+	now             func() time.Time
+	maxUpdated      int
+	client          *provreqclient.ProvisioningRequestClient
+	reservationTime time.Duration
+	expirationTime  time.Duration
 }
 
-// NewProvReqProcessor return ProvisioningRequestProcessor.
-func NewProvReqProcessor(client *provreqclient.ProvisioningRequestClient) *provReqProcessor {
-	return &provReqProcessor{now: time.Now, maxUpdated: defaultMaxUpdated, client: client}
+// NewProvReqProcessor return ProvisioningRequestProcessor. Capacity is booked for Provisioned
+// ProvisioningRequests for reservationTime, and ProvisioningRequests without the ValidUntilSeconds
+// parameter fail if they weren't provisioned within expirationTime since their creation.
+func NewProvReqProcessor(client *provreqclient.ProvisioningRequestClient, reservationTime, expirationTime time.Duration) *provReqProcessor {
+	return &provReqProcessor{now: time.Now, maxUpdated: defaultMaxUpdated, client: client, reservationTime: reservationTime, expirationTime: expirationTime}
 }
 
 // Refresh implements loop.Observer interface and will be run at the start
@@ -60,13 +67,19 @@ func (p *provReqProcessor) Refresh() {
 	p.Process(provReqs)
 }
 
+type failedProvReq struct {
+	provReq *provreqwrapper.ProvisioningRequest
+	reason  string
+	message string
+}
+
 // Process iterates over ProvisioningRequests and apply:
 // -BookingExpired condition for Provisioned ProvisioningRequest if capacity reservation time is expired.
-// -Failed condition for ProvisioningRequest that were not provisioned during defaultExpirationTime.
-// TODO(yaroslava): fetch reservation and expiration time from ProvisioningRequest
+// -Failed condition for ProvisioningRequest that were not provisioned before the time set by their
+// ValidUntilSeconds parameter, or during expirationTime if it isn't set.
 func (p *provReqProcessor) Process(provReqs []*provreqwrapper.ProvisioningRequest) {
 	expiredProvReq := []*provreqwrapper.ProvisioningRequest{}
-	failedProvReq := []*provreqwrapper.ProvisioningRequest{}
+	failedProvReqs := []failedProvReq{}
 	for _, provReq := range provReqs {
 		if len(expiredProvReq) >= p.maxUpdated {
 			break
@@ -74,19 +87,26 @@ func (p *provReqProcessor) Process(provReqs []*provreqwrapper.ProvisioningReques
 		if ok, found := provisioningrequest.SupportedProvisioningClasses[provReq.Spec.ProvisioningClassName]; !ok || !found {
 			continue
 		}
-		conditions := provReq.Status.Conditions
-		if apimeta.IsStatusConditionTrue(conditions, v1beta1.BookingExpired) || apimeta.IsStatusConditionTrue(conditions, v1beta1.Failed) {
+		provReqConditions := provReq.Status.Conditions
+		if apimeta.IsStatusConditionTrue(provReqConditions, v1beta1.BookingExpired) || apimeta.IsStatusConditionTrue(provReqConditions, v1beta1.Failed) {
 			continue
 		}
-		provisioned := apimeta.FindStatusCondition(conditions, v1beta1.Provisioned)
+		provisioned := apimeta.FindStatusCondition(provReqConditions, v1beta1.Provisioned)
 		if provisioned != nil && provisioned.Status == metav1.ConditionTrue {
-			if provisioned.LastTransitionTime.Add(defaultReservationTime).Before(p.now()) {
+			if provisioned.LastTransitionTime.Add(p.reservationTime).Before(p.now()) {
 				expiredProvReq = append(expiredProvReq, provReq)
 			}
-		} else if len(failedProvReq) < p.maxUpdated-len(expiredProvReq) {
-			created := provReq.CreationTimestamp
-			if created.Add(defaultExpirationTime).Before(p.now()) {
-				failedProvReq = append(failedProvReq, provReq)
+		} else if len(failedProvReqs) < p.maxUpdated-len(expiredProvReq) {
+			validUntil, found, err := provReq.ValidUntil()
+			if err != nil {
+				failedProvReqs = append(failedProvReqs, failedProvReq{provReq, conditions.InvalidParametersReason, err.Error()})
+				continue
+			}
+			if !found {
+				validUntil = provReq.CreationTimestamp.Add(p.expirationTime)
+			}
+			if validUntil.Before(p.now()) {
+				failedProvReqs = append(failedProvReqs, failedProvReq{provReq, conditions.ExpiredReason, conditions.ExpiredMsg})
 			}
 		}
 	}
@@ -98,8 +118,9 @@ func (p *provReqProcessor) Process(provReqs []*provreqwrapper.ProvisioningReques
 			continue
 		}
 	}
-	for _, provReq := range failedProvReq {
-		conditions.AddOrUpdateCondition(provReq, v1beta1.Failed, metav1.ConditionTrue, conditions.ExpiredReason, conditions.ExpiredMsg, metav1.NewTime(p.now()))
+	for _, failed := range failedProvReqs {
+		provReq := failed.provReq
+		conditions.AddOrUpdateCondition(provReq, v1beta1.Failed, metav1.ConditionTrue, failed.reason, failed.message, metav1.NewTime(p.now()))
 		_, updErr := p.client.UpdateProvisioningRequest(provReq.ProvisioningRequest)
 		if updErr != nil {
 			klog.Errorf("failed to add Failed condition to ProvReq %s/%s, err: %v", provReq.Namespace, provReq.Name, updErr)
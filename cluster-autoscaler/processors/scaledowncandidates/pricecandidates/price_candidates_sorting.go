@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricecandidates
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/klog/v2"
+)
+
+// PriceSorting is sorting scale down candidates so that nodes from more expensive
+// node groups appear first. Over time, this makes the cluster converge to cheaper
+// node groups.
+type PriceSorting struct {
+	// prices are prices of node groups of candidates, by node name.
+	prices map[string]float64
+}
+
+// NewPriceSorting returns PriceSorting struct.
+func NewPriceSorting() *PriceSorting {
+	return &PriceSorting{}
+}
+
+// Refresh computes prices of node groups of the candidates. Nodes in a node group are
+// assumed to have the same price, so the pricing model is queried once per node group.
+func (p *PriceSorting) Refresh(ctx *context.AutoscalingContext, candidates []*apiv1.Node) {
+	p.prices = make(map[string]float64)
+	pricingModel, err := ctx.CloudProvider.Pricing()
+	if err != nil {
+		klog.V(4).Infof("Pricing model not available, scale down candidates won't be sorted by price: %v", err)
+		return
+	}
+	now := time.Now()
+	then := now.Add(time.Hour)
+	nodeGroupPrices := make(map[string]float64)
+	for _, node := range candidates {
+		nodeGroup, err := ctx.CloudProvider.NodeGroupForNode(node)
+		if err != nil || nodeGroup == nil {
+			continue
+		}
+		price, found := nodeGroupPrices[nodeGroup.Id()]
+		if !found {
+			price, err = pricingModel.NodePrice(node, now, then)
+			if err != nil {
+				klog.Warningf("Failed to get price of node %s: %v", node.Name, err)
+				continue
+			}
+			nodeGroupPrices[nodeGroup.Id()] = price
+		}
+		p.prices[node.Name] = price
+	}
+}
+
+// ScaleDownEarlierThan return true if node1 belongs to a more expensive node group than node2.
+func (p *PriceSorting) ScaleDownEarlierThan(node1, node2 *apiv1.Node) bool {
+	price1, found1 := p.prices[node1.Name]
+	price2, found2 := p.prices[node2.Name]
+	return found1 && found2 && price1 > price2
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pricecandidates
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+type testPricingModel struct {
+	nodePrice map[string]float64
+}
+
+func (tpm *testPricingModel) NodePrice(node *apiv1.Node, startTime time.Time, endTime time.Time) (float64, error) {
+	if price, found := tpm.nodePrice[node.Name]; found {
+		return price, nil
+	}
+	return 0.0, fmt.Errorf("price for node %v not found", node.Name)
+}
+
+func (tpm *testPricingModel) PodPrice(pod *apiv1.Pod, startTime time.Time, endTime time.Time) (float64, error) {
+	return 0.0, fmt.Errorf("price for pod %v not found", pod.Name)
+}
+
+func TestScaleDownEarlierThan(t *testing.T) {
+	cheap1 := BuildTestNode("cheap1", 1000, 1000)
+	cheap2 := BuildTestNode("cheap2", 1000, 1000)
+	expensive := BuildTestNode("expensive", 1000, 1000)
+	unknown := BuildTestNode("unknown", 1000, 1000)
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("cheap", 0, 10, 2)
+	provider.AddNodeGroup("expensive", 0, 10, 1)
+	provider.AddNode("cheap", cheap1)
+	provider.AddNode("cheap", cheap2)
+	provider.AddNode("expensive", expensive)
+	// Only the first node of a node group is priced.
+	provider.SetPricingModel(&testPricingModel{nodePrice: map[string]float64{"cheap1": 1, "expensive": 3, "unknown": 5}})
+	ctx := &context.AutoscalingContext{CloudProvider: provider}
+
+	p := NewPriceSorting()
+	p.Refresh(ctx, []*apiv1.Node{cheap1, cheap2, expensive, unknown})
+	testCases := []struct {
+		name  string
+		node1 *apiv1.Node
+		node2 *apiv1.Node
+		want  bool
+	}{
+		{
+			name:  "Compare nodes from the same node group",
+			node1: cheap1,
+			node2: cheap2,
+			want:  false,
+		},
+		{
+			name:  "Compare expensive and cheap node",
+			node1: expensive,
+			node2: cheap2,
+			want:  true,
+		},
+		{
+			name:  "Compare cheap and expensive node",
+			node1: cheap1,
+			node2: expensive,
+			want:  false,
+		},
+		{
+			name:  "Compare node without node group and cheap node",
+			node1: unknown,
+			node2: cheap1,
+			want:  false,
+		},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			got := p.ScaleDownEarlierThan(test.node1, test.node2)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRefreshWithoutPricing(t *testing.T) {
+	cheap := BuildTestNode("cheap", 1000, 1000)
+	expensive := BuildTestNode("expensive", 1000, 1000)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng", 0, 10, 2)
+	provider.AddNode("ng", cheap)
+	provider.AddNode("ng", expensive)
+
+	p := NewPriceSorting()
+	p.Refresh(&context.AutoscalingContext{CloudProvider: provider}, []*apiv1.Node{cheap, expensive})
+	assert.False(t, p.ScaleDownEarlierThan(expensive, cheap))
+	assert.False(t, p.ScaleDownEarlierThan(cheap, expensive))
+}
@@ -20,6 +20,8 @@ import (
 	"sort"
 
 	apiv1 "k8s.io/api/core/v1"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
 )
 
 // CandidatesComparer is an  used for sorting scale down candidates.
@@ -28,6 +30,13 @@ type CandidatesComparer interface {
 	ScaleDownEarlierThan(node1, node2 *apiv1.Node) bool
 }
 
+// RefreshableCandidatesComparer is a CandidatesComparer which needs to refresh its state before sorting.
+type RefreshableCandidatesComparer interface {
+	CandidatesComparer
+	// Refresh is called with the scale down candidates before they're sorted.
+	Refresh(ctx *context.AutoscalingContext, candidates []*apiv1.Node)
+}
+
 // NodeSorter struct contain the list of nodes and the list of processors that should be applied for sorting.
 type NodeSorter struct {
 	nodes      []*apiv1.Node
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleuphints
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+const (
+	// ProvisionForAnnotationKey is the annotation of ConfigMaps and custom resources
+	// declaring the pods of an imminent workload, as a JSON list of pod sets, for
+	// which CA provisions capacity before they're created.
+	ProvisionForAnnotationKey = "cluster-autoscaler.kubernetes.io/provision-for"
+	// ProvisionUntilAnnotationKey is an optional annotation of hints, with the
+	// RFC 3339 time after which the hint is ignored.
+	ProvisionUntilAnnotationKey = "cluster-autoscaler.kubernetes.io/provision-until"
+	// HintPodAnnotationKey is the annotation of virtual pods injected for a hint,
+	// set to the namespace and name of the annotated object.
+	HintPodAnnotationKey = "cluster-autoscaler.kubernetes.io/scale-up-hint"
+)
+
+// PodSet is a number of pods of the same template an imminent workload will create.
+type PodSet struct {
+	// Count is the number of pods.
+	Count int32 `json:"count"`
+	// Template is the template of the pods.
+	Template apiv1.PodTemplateSpec `json:"template"`
+}
+
+// Hint is an object annotated with ProvisionForAnnotationKey.
+type Hint struct {
+	APIVersion string
+	Kind       string
+	Object     metav1.Object
+}
+
+func (h Hint) String() string {
+	if h.Object.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", h.Kind, h.Object.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", h.Kind, h.Object.GetNamespace(), h.Object.GetName())
+}
+
+// PodsForHint returns the virtual pods of the workload the hint declares, or
+// no pods if the hint expired. The pods are safe to evict, so that they don't
+// block scale-down of nodes whose capacity is available elsewhere in the cluster.
+func PodsForHint(hint Hint, now time.Time) ([]*apiv1.Pod, error) {
+	annotations := hint.Object.GetAnnotations()
+	if until, found := annotations[ProvisionUntilAnnotationKey]; found {
+		deadline, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s annotation of %s: %v", ProvisionUntilAnnotationKey, hint, err)
+		}
+		if now.After(deadline) {
+			return nil, nil
+		}
+	}
+	var podSets []PodSet
+	if err := json.Unmarshal([]byte(annotations[ProvisionForAnnotationKey]), &podSets); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation of %s: %v", ProvisionForAnnotationKey, hint, err)
+	}
+	pods := make([]*apiv1.Pod, 0)
+	for i, podSet := range podSets {
+		if podSet.Count < 0 {
+			return nil, fmt.Errorf("pod set %d of %s has negative count %d", i, hint, podSet.Count)
+		}
+		for j := 0; j < int(podSet.Count); j++ {
+			pods = append(pods, podForPodSet(hint, &podSet.Template, i, j))
+		}
+	}
+	return pods, nil
+}
+
+func podForPodSet(hint Hint, template *apiv1.PodTemplateSpec, i, j int) *apiv1.Pod {
+	pod := &apiv1.Pod{
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       *template.Spec.DeepCopy(),
+	}
+	pod.Name = fmt.Sprintf("scale-up-hint-%s-%d-%d", hint.Object.GetName(), i, j)
+	pod.Namespace = hint.Object.GetNamespace()
+	if pod.Namespace == "" {
+		pod.Namespace = metav1.NamespaceDefault
+	}
+	pod.UID = types.UID(fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+	pod.CreationTimestamp = hint.Object.GetCreationTimestamp()
+	// The owner reference groups the pods as coming from one controller,
+	// which simplifies the scale-up simulation.
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: hint.APIVersion,
+		Kind:       hint.Kind,
+		Name:       hint.Object.GetName(),
+		UID:        hint.Object.GetUID(),
+		Controller: proto.Bool(true),
+	}}
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[HintPodAnnotationKey] = fmt.Sprintf("%s/%s", hint.Object.GetNamespace(), hint.Object.GetName())
+	pod.Annotations[drain.PodSafeToEvictKey] = "true"
+	pod.Annotations[pod_util.VirtualPodAnnotationKey] = "true"
+	return pod
+}
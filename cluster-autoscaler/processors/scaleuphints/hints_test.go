@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleuphints
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
+)
+
+const testPodSets = `[
+  {"count": 2, "template": {"metadata": {"labels": {"app": "job"}}, "spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "1"}}}]}}},
+  {"count": 1, "template": {"spec": {"containers": [{"name": "c", "resources": {"requests": {"cpu": "4"}}}]}}}
+]`
+
+func testHint(annotations map[string]string) Hint {
+	return Hint{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Object: &apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name:        "training",
+			Namespace:   "ml",
+			UID:         "hint-uid",
+			Annotations: annotations,
+		}},
+	}
+}
+
+func TestPodsForHint(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	pods, err := PodsForHint(testHint(map[string]string{ProvisionForAnnotationKey: testPodSets}), now)
+	assert.NoError(t, err)
+	if assert.Len(t, pods, 3) {
+		names := map[string]bool{}
+		for _, pod := range pods {
+			names[pod.Name] = true
+			assert.Equal(t, "ml", pod.Namespace)
+			assert.Equal(t, "ml/training", pod.Annotations[HintPodAnnotationKey])
+			assert.True(t, pod_util.IsVirtualPod(pod))
+			assert.True(t, drain.HasSafeToEvictAnnotation(pod))
+			controller := metav1.GetControllerOf(pod)
+			if assert.NotNil(t, controller) {
+				assert.Equal(t, "ConfigMap", controller.Kind)
+				assert.Equal(t, "training", controller.Name)
+			}
+		}
+		assert.Equal(t, map[string]bool{"scale-up-hint-training-0-0": true, "scale-up-hint-training-0-1": true, "scale-up-hint-training-1-0": true}, names)
+		assert.Equal(t, "job", pods[0].Labels["app"])
+		cpu := pods[2].Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU]
+		assert.Equal(t, int64(4), cpu.Value())
+	}
+
+	pods, err = PodsForHint(testHint(map[string]string{
+		ProvisionForAnnotationKey:   testPodSets,
+		ProvisionUntilAnnotationKey: "2024-05-01T13:00:00Z",
+	}), now)
+	assert.NoError(t, err)
+	assert.Len(t, pods, 3)
+
+	pods, err = PodsForHint(testHint(map[string]string{
+		ProvisionForAnnotationKey:   testPodSets,
+		ProvisionUntilAnnotationKey: "2024-05-01T11:00:00Z",
+	}), now)
+	assert.NoError(t, err)
+	assert.Empty(t, pods)
+
+	_, err = PodsForHint(testHint(map[string]string{
+		ProvisionForAnnotationKey:   testPodSets,
+		ProvisionUntilAnnotationKey: "tomorrow",
+	}), now)
+	assert.Error(t, err)
+
+	_, err = PodsForHint(testHint(map[string]string{ProvisionForAnnotationKey: "10 pods"}), now)
+	assert.Error(t, err)
+
+	_, err = PodsForHint(testHint(map[string]string{ProvisionForAnnotationKey: `[{"count": -1}]`}), now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleuphints
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
+	klog "k8s.io/klog/v2"
+)
+
+type hintLister interface {
+	Hints() ([]Hint, error)
+}
+
+// podSource provides virtual pods of all unexpired scale-up hints.
+type podSource struct {
+	lister hintLister
+	now    func() time.Time
+}
+
+// VirtualPods returns pods of all valid, unexpired hints.
+func (s *podSource) VirtualPods() ([]*apiv1.Pod, error) {
+	hints, err := s.lister.Hints()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var result []*apiv1.Pod
+	for _, hint := range hints {
+		hintPods, err := PodsForHint(hint, now)
+		if err != nil {
+			klog.Warningf("Failed to get pods for scale-up hint: %v", err)
+			continue
+		}
+		if len(hintPods) > 0 {
+			klog.V(5).Infof("Scale-up hint %s has %d pods", hint, len(hintPods))
+		}
+		result = append(result, hintPods...)
+	}
+	return result, nil
+}
+
+// NewPodsInjector creates a processor injecting virtual pods of scale-up hints
+// into the unschedulable pods list, so that capacity for an imminent workload
+// is provisioned before its pods are created.
+func NewPodsInjector(lister *Lister) pods.PodListProcessor {
+	return pods.NewVirtualPodsInjector("scale-up hints", &podSource{lister: lister, now: time.Now})
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleuphints
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeHintLister struct {
+	hints []Hint
+	err   error
+}
+
+func (f *fakeHintLister) Hints() ([]Hint, error) {
+	return f.hints, f.err
+}
+
+func TestPodSource(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	expired := testHint(map[string]string{
+		ProvisionForAnnotationKey:   testPodSets,
+		ProvisionUntilAnnotationKey: "2024-05-01T11:00:00Z",
+	})
+
+	testCases := []struct {
+		name         string
+		lister       *fakeHintLister
+		expectedPods int
+		expectedErr  bool
+	}{
+		{
+			name:         "no hints",
+			lister:       &fakeHintLister{},
+			expectedPods: 0,
+		},
+		{
+			name:         "hints",
+			lister:       &fakeHintLister{hints: []Hint{testHint(map[string]string{ProvisionForAnnotationKey: testPodSets})}},
+			expectedPods: 3,
+		},
+		{
+			name:         "expired hint is skipped",
+			lister:       &fakeHintLister{hints: []Hint{expired}},
+			expectedPods: 0,
+		},
+		{
+			name:         "invalid hint is skipped",
+			lister:       &fakeHintLister{hints: []Hint{testHint(map[string]string{ProvisionForAnnotationKey: "{"}), testHint(map[string]string{ProvisionForAnnotationKey: testPodSets})}},
+			expectedPods: 3,
+		},
+		{
+			name:        "listing fails",
+			lister:      &fakeHintLister{err: fmt.Errorf("forbidden")},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := &podSource{lister: tc.lister, now: func() time.Time { return now }}
+			pods, err := source.VirtualPods()
+			assert.Equal(t, tc.expectedErr, err != nil)
+			assert.Len(t, pods, tc.expectedPods)
+		})
+	}
+}
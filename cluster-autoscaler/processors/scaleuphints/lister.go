@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleuphints
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+)
+
+// Lister lists hints from ConfigMaps of all namespaces and from custom resources
+// of the configured kinds. Custom resources are read through the dynamic client,
+// as their types aren't known to CA.
+type Lister struct {
+	configMaps v1lister.ConfigMapLister
+	resources  map[schema.GroupVersionResource]cache.GenericLister
+}
+
+// ParseResources parses custom resources in the resource.version.group format,
+// e.g. jobqueues.v1.example.com.
+func ParseResources(resources []string) ([]schema.GroupVersionResource, error) {
+	result := make([]schema.GroupVersionResource, 0, len(resources))
+	for _, resource := range resources {
+		gvr, _ := schema.ParseResourceArg(resource)
+		if gvr == nil {
+			return nil, fmt.Errorf("%q isn't in the resource.version.group format", resource)
+		}
+		result = append(result, *gvr)
+	}
+	return result, nil
+}
+
+// NewLister creates a hints lister. The ConfigMap lister has to cover all namespaces.
+func NewLister(configMaps v1lister.ConfigMapLister, dynamicClient dynamic.Interface, resources []schema.GroupVersionResource, stopChannel <-chan struct{}) (*Lister, error) {
+	lister := &Lister{
+		configMaps: configMaps,
+		resources:  make(map[schema.GroupVersionResource]cache.GenericLister),
+	}
+	if len(resources) == 0 {
+		return lister, nil
+	}
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 1*time.Hour)
+	for _, resource := range resources {
+		lister.resources[resource] = factory.ForResource(resource).Lister()
+	}
+	factory.Start(stopChannel)
+	for resource, synced := range factory.WaitForCacheSync(stopChannel) {
+		if !synced {
+			return nil, fmt.Errorf("can't create lister of %s for scale-up hints", resource)
+		}
+	}
+	klog.V(2).Infof("Successful initial sync of %d resources for scale-up hints", len(resources))
+	return lister, nil
+}
+
+// Hints lists all objects annotated with ProvisionForAnnotationKey.
+func (l *Lister) Hints() ([]Hint, error) {
+	var hints []Hint
+	configMaps, err := l.configMaps.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error fetching config maps: %w", err)
+	}
+	for _, cm := range configMaps {
+		if _, found := cm.Annotations[ProvisionForAnnotationKey]; found {
+			hints = append(hints, Hint{APIVersion: "v1", Kind: "ConfigMap", Object: cm})
+		}
+	}
+	for resource, lister := range l.resources {
+		objects, err := lister.List(labels.Everything())
+		if err != nil {
+			return nil, fmt.Errorf("error fetching %s: %w", resource, err)
+		}
+		for _, object := range objects {
+			u, ok := object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			if _, found := u.GetAnnotations()[ProvisionForAnnotationKey]; found {
+				hints = append(hints, Hint{APIVersion: u.GetAPIVersion(), Kind: u.GetKind(), Object: u})
+			}
+		}
+	}
+	return hints, nil
+}
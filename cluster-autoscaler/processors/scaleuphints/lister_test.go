@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleuphints
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestLister(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	require.NoError(t, indexer.Add(&apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:        "hint",
+		Namespace:   "ml",
+		Annotations: map[string]string{ProvisionForAnnotationKey: testPodSets},
+	}}))
+	require.NoError(t, indexer.Add(&apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ml"}}))
+
+	resources, err := ParseResources([]string{"jobqueues.v1.example.com"})
+	require.NoError(t, err)
+	jobQueue := &unstructured.Unstructured{}
+	jobQueue.SetAPIVersion("example.com/v1")
+	jobQueue.SetKind("JobQueue")
+	jobQueue.SetNamespace("batch")
+	jobQueue.SetName("nightly")
+	jobQueue.SetAnnotations(map[string]string{ProvisionForAnnotationKey: testPodSets})
+	dynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{resources[0]: "JobQueueList"}, jobQueue)
+
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+	lister, err := NewLister(v1lister.NewConfigMapLister(indexer), dynamicClient, resources, stopChannel)
+	require.NoError(t, err)
+
+	hints, err := lister.Hints()
+	assert.NoError(t, err)
+	if assert.Len(t, hints, 2) {
+		assert.Equal(t, "ConfigMap ml/hint", hints[0].String())
+		assert.Equal(t, "example.com/v1", hints[1].APIVersion)
+		assert.Equal(t, "JobQueue batch/nightly", hints[1].String())
+	}
+
+	_, err = ParseResources([]string{"jobqueues"})
+	assert.Error(t, err)
+}
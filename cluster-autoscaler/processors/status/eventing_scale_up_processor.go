@@ -17,31 +17,86 @@ limitations under the License.
 package status
 
 import (
+	ctx "context"
 	"fmt"
+	"sort"
 	"strings"
 
 	klog "k8s.io/klog/v2"
 
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	scheduler_util "k8s.io/kubernetes/pkg/scheduler/util"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
 )
 
+const (
+	// NotTriggerScaleUpPodCondition is the type of the pod condition describing why
+	// an unschedulable pod didn't trigger scale-up.
+	NotTriggerScaleUpPodCondition apiv1.PodConditionType = "NotTriggerScaleUp"
+	// TriggeredScaleUpConditionReason is the reason set on NotTriggerScaleUpPodCondition
+	// once the pod triggered a scale-up.
+	TriggeredScaleUpConditionReason = "TriggeredScaleUp"
+)
+
+// Stable, machine readable codes explaining why a pod didn't trigger scale-up.
+const (
+	// NoScaleUpReasonNoNodeGroups - there were no node groups that could be considered for the pod.
+	NoScaleUpReasonNoNodeGroups = "NoNodeGroups"
+	// NoScaleUpReasonNotMatchingNodeGroup - the pod doesn't fit node group template, e.g. because of affinity, taints or resources.
+	NoScaleUpReasonNotMatchingNodeGroup = "NotMatchingNodeGroup"
+	// NoScaleUpReasonMaxNodeGroupSizeReached - node group max size was reached.
+	NoScaleUpReasonMaxNodeGroupSizeReached = "MaxNodeGroupSizeReached"
+	// NoScaleUpReasonNodeGroupBackoff - node group is in backoff, e.g. after a stockout.
+	NoScaleUpReasonNodeGroupBackoff = "NodeGroupBackoff"
+	// NoScaleUpReasonNodeGroupNotReady - node group is not ready for scale-up.
+	NoScaleUpReasonNodeGroupNotReady = "NodeGroupNotReady"
+	// NoScaleUpReasonMaxConcurrentProvisioningReached - node group already has the maximum number of nodes provisioning.
+	NoScaleUpReasonMaxConcurrentProvisioningReached = "MaxConcurrentProvisioningReached"
+	// NoScaleUpReasonMaxResourceLimitReached - cluster wide resource limits were reached.
+	NoScaleUpReasonMaxResourceLimitReached = "MaxResourceLimitReached"
+	// NoScaleUpReasonMaxHourlyCostReached - cluster wide max hourly cost was reached.
+	NoScaleUpReasonMaxHourlyCostReached = "MaxHourlyCostReached"
+	// NoScaleUpReasonAllOrNothing - not all pods would fit and scale-up is using all-or-nothing strategy.
+	NoScaleUpReasonAllOrNothing = "AllOrNothing"
+	// NoScaleUpReasonOther - node groups were skipped for a reason without a dedicated code.
+	NoScaleUpReasonOther = "Other"
+)
+
+// ReasonCoder is implemented by Reasons which can be classified with one of
+// the stable NoScaleUpReason codes.
+type ReasonCoder interface {
+	ReasonCode() string
+}
+
 // EventingScaleUpStatusProcessor processes the state of the cluster after
 // a scale-up by emitting relevant events for pods depending on their post
 // scale-up status.
-type EventingScaleUpStatusProcessor struct{}
+type EventingScaleUpStatusProcessor struct {
+	// RecordPodConditions controls whether NotTriggerScaleUpPodCondition
+	// is set on pods in addition to emitting events.
+	RecordPodConditions bool
+}
 
 // Process processes the state of the cluster after a scale-up by emitting
-// relevant events for pods depending on their post scale-up status.
+// relevant events for pods depending on their post scale-up status. Virtual
+// pods don't exist in the cluster, so they are skipped.
 func (p *EventingScaleUpStatusProcessor) Process(context *context.AutoscalingContext, status *ScaleUpStatus) {
 	consideredNodeGroupsMap := nodeGroupListToMapById(status.ConsideredNodeGroups)
 	if status.Result != ScaleUpSuccessful && status.Result != ScaleUpError {
 		for _, noScaleUpInfo := range status.PodsRemainUnschedulable {
+			if pod_util.IsVirtualPod(noScaleUpInfo.Pod) {
+				continue
+			}
+			message := ReasonsMessage(noScaleUpInfo, consideredNodeGroupsMap)
 			context.Recorder.Event(noScaleUpInfo.Pod, apiv1.EventTypeNormal, "NotTriggerScaleUp",
-				fmt.Sprintf("pod didn't trigger scale-up: %s",
-					ReasonsMessage(noScaleUpInfo, consideredNodeGroupsMap)))
+				fmt.Sprintf("pod didn't trigger scale-up: %s", message))
+			if p.RecordPodConditions {
+				updatePodCondition(context, noScaleUpInfo.Pod, apiv1.ConditionTrue, ReasonCode(noScaleUpInfo, consideredNodeGroupsMap), message)
+			}
 		}
 	} else {
 		klog.V(4).Infof("Skipping event processing for unschedulable pods since there is a" +
@@ -49,8 +104,14 @@ func (p *EventingScaleUpStatusProcessor) Process(context *context.AutoscalingCon
 	}
 	if len(status.ScaleUpInfos) > 0 {
 		for _, pod := range status.PodsTriggeredScaleUp {
+			if pod_util.IsVirtualPod(pod) {
+				continue
+			}
 			context.Recorder.Eventf(pod, apiv1.EventTypeNormal, "TriggeredScaleUp",
 				"pod triggered scale-up: %v", status.ScaleUpInfos)
+			if p.RecordPodConditions && hasPodCondition(pod) {
+				updatePodCondition(context, pod, apiv1.ConditionFalse, TriggeredScaleUpConditionReason, "")
+			}
 		}
 	}
 }
@@ -89,6 +150,85 @@ func ReasonsMessage(noScaleUpInfo NoScaleUpInfo, consideredNodeGroups map[string
 	return strings.Join(messages, ", ")
 }
 
+// ReasonCode returns a stable code summarizing why the pod didn't trigger
+// scale-up. The most common code across considered node groups wins, ties are
+// broken alphabetically so the result doesn't change between loops.
+func ReasonCode(noScaleUpInfo NoScaleUpInfo, consideredNodeGroups map[string]cloudprovider.NodeGroup) string {
+	counts := map[string]int{}
+	countCodes := func(nodeGroupReasons map[string]Reasons, defaultCode string) {
+		for nodeGroupId, reasons := range nodeGroupReasons {
+			if nodeGroup, present := consideredNodeGroups[nodeGroupId]; !present || !nodeGroup.Exist() {
+				continue
+			}
+			code := defaultCode
+			if coder, ok := reasons.(ReasonCoder); ok && coder.ReasonCode() != "" {
+				code = coder.ReasonCode()
+			}
+			counts[code]++
+		}
+	}
+	// Node groups are rejected mostly because of failing scheduling predicates.
+	countCodes(noScaleUpInfo.RejectedNodeGroups, NoScaleUpReasonNotMatchingNodeGroup)
+	countCodes(noScaleUpInfo.SkippedNodeGroups, NoScaleUpReasonOther)
+	if len(counts) == 0 {
+		return NoScaleUpReasonNoNodeGroups
+	}
+
+	codes := make([]string, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if counts[codes[i]] != counts[codes[j]] {
+			return counts[codes[i]] > counts[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	return codes[0]
+}
+
+func hasPodCondition(pod *apiv1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == NotTriggerScaleUpPodCondition && condition.Status == apiv1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// updatePodCondition sets NotTriggerScaleUpPodCondition on the pod. The API
+// is only called if the condition actually changed.
+func updatePodCondition(context *context.AutoscalingContext, pod *apiv1.Pod, conditionStatus apiv1.ConditionStatus, reason, message string) {
+	newStatus := pod.Status.DeepCopy()
+	condition := apiv1.PodCondition{
+		Type:               NotTriggerScaleUpPodCondition,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	found := false
+	for i, existing := range newStatus.Conditions {
+		if existing.Type != NotTriggerScaleUpPodCondition {
+			continue
+		}
+		found = true
+		if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+			return
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		newStatus.Conditions[i] = condition
+	}
+	if !found {
+		newStatus.Conditions = append(newStatus.Conditions, condition)
+	}
+	if err := scheduler_util.PatchPodStatus(ctx.TODO(), context.ClientSet, pod, newStatus); err != nil {
+		klog.Warningf("Failed to update %s condition of pod %s/%s: %v", NotTriggerScaleUpPodCondition, pod.Namespace, pod.Name, err)
+	}
+}
+
 func nodeGroupListToMapById(nodeGroups []cloudprovider.NodeGroup) map[string]cloudprovider.NodeGroup {
 	result := make(map[string]cloudprovider.NodeGroup)
 	for _, nodeGroup := range nodeGroups {
@@ -17,16 +17,20 @@ limitations under the License.
 package status
 
 import (
+	ctx "context"
 	"strings"
 	"testing"
 
 	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 	kube_record "k8s.io/client-go/tools/record"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	cp_test "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
+	pod_util "k8s.io/autoscaler/cluster-autoscaler/utils/pod"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 
 	"github.com/stretchr/testify/assert"
@@ -172,3 +176,159 @@ func TestReasonsMessage(t *testing.T) {
 		assert.Contains(t, result, part)
 	}
 }
+
+type testCodedReason struct {
+	testReason
+	code string
+}
+
+func (tr *testCodedReason) ReasonCode() string {
+	return tr.code
+}
+
+func TestReasonCode(t *testing.T) {
+	considered := map[string]cloudprovider.NodeGroup{
+		"group 1":     cp_test.NewTestNodeGroup("group 1", 1, 1, 1, true, false, "", nil, nil),
+		"group 2":     cp_test.NewTestNodeGroup("group 2", 1, 1, 1, true, false, "", nil, nil),
+		"group 3":     cp_test.NewTestNodeGroup("group 3", 1, 1, 1, true, false, "", nil, nil),
+		"tmp group 1": cp_test.NewTestNodeGroup("tmp group 1", 1, 1, 1, false, false, "", nil, nil),
+	}
+	backoff := &testCodedReason{testReason{"in backoff"}, NoScaleUpReasonNodeGroupBackoff}
+	maxSize := &testCodedReason{testReason{"max size"}, NoScaleUpReasonMaxNodeGroupSizeReached}
+
+	testCases := []struct {
+		name     string
+		rejected map[string]Reasons
+		skipped  map[string]Reasons
+		expected string
+	}{
+		{
+			name:     "no node groups",
+			expected: NoScaleUpReasonNoNodeGroups,
+		},
+		{
+			name:     "only not existing node groups",
+			skipped:  map[string]Reasons{"tmp group 1": backoff},
+			expected: NoScaleUpReasonNoNodeGroups,
+		},
+		{
+			name:     "rejected without code",
+			rejected: map[string]Reasons{"group 1": &testReason{"not schedulable"}},
+			expected: NoScaleUpReasonNotMatchingNodeGroup,
+		},
+		{
+			name:     "skipped without code",
+			skipped:  map[string]Reasons{"group 1": &testReason{"skipped"}},
+			expected: NoScaleUpReasonOther,
+		},
+		{
+			name:     "most common code wins",
+			rejected: map[string]Reasons{"group 1": &testReason{"not schedulable"}},
+			skipped:  map[string]Reasons{"group 2": backoff, "group 3": backoff},
+			expected: NoScaleUpReasonNodeGroupBackoff,
+		},
+		{
+			name:     "ties are broken alphabetically",
+			skipped:  map[string]Reasons{"group 1": backoff, "group 2": maxSize},
+			expected: NoScaleUpReasonMaxNodeGroupSizeReached,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ReasonCode(NoScaleUpInfo{nil, tc.rejected, tc.skipped}, considered))
+		})
+	}
+}
+
+func TestEventingScaleUpStatusProcessorPodConditions(t *testing.T) {
+	p := &EventingScaleUpStatusProcessor{RecordPodConditions: true}
+	ng := cp_test.NewTestNodeGroup("group 1", 1, 1, 1, true, false, "", nil, nil)
+	backoff := &testCodedReason{testReason{"in backoff after failed scale-up"}, NoScaleUpReasonNodeGroupBackoff}
+	p1 := BuildTestPod("p1", 0, 0)
+	p2 := BuildTestPod("p2", 0, 0)
+	p2.Status.Conditions = []apiv1.PodCondition{
+		{Type: NotTriggerScaleUpPodCondition, Status: apiv1.ConditionTrue, Reason: NoScaleUpReasonNodeGroupBackoff},
+	}
+	p3 := BuildTestPod("p3", 0, 0)
+
+	fakeClient := fake.NewSimpleClientset(p1, p2, p3)
+	context := &context.AutoscalingContext{
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			ClientSet: fakeClient,
+			Recorder:  kube_record.NewFakeRecorder(5),
+		},
+	}
+
+	p.Process(context, &ScaleUpStatus{
+		Result:               ScaleUpNoOptionsAvailable,
+		ConsideredNodeGroups: []cloudprovider.NodeGroup{ng},
+		PodsRemainUnschedulable: []NoScaleUpInfo{
+			{p1, nil, map[string]Reasons{"group 1": backoff}},
+		},
+	})
+	p.Process(context, &ScaleUpStatus{
+		Result:               ScaleUpSuccessful,
+		ScaleUpInfos:         []nodegroupset.ScaleUpInfo{{}},
+		PodsTriggeredScaleUp: []*apiv1.Pod{p2, p3},
+	})
+
+	getCondition := func(name string) *apiv1.PodCondition {
+		pod, err := fakeClient.CoreV1().Pods(p1.Namespace).Get(ctx.TODO(), name, metav1.GetOptions{})
+		assert.NoError(t, err)
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == NotTriggerScaleUpPodCondition {
+				return &condition
+			}
+		}
+		return nil
+	}
+	if condition := getCondition("p1"); assert.NotNil(t, condition) {
+		assert.Equal(t, apiv1.ConditionTrue, condition.Status)
+		assert.Equal(t, NoScaleUpReasonNodeGroupBackoff, condition.Reason)
+		assert.Equal(t, "1 in backoff after failed scale-up", condition.Message)
+	}
+	if condition := getCondition("p2"); assert.NotNil(t, condition) {
+		assert.Equal(t, apiv1.ConditionFalse, condition.Status)
+		assert.Equal(t, TriggeredScaleUpConditionReason, condition.Reason)
+	}
+	assert.Nil(t, getCondition("p3"))
+}
+
+func TestEventingScaleUpStatusProcessorSkipsVirtualPods(t *testing.T) {
+	p := &EventingScaleUpStatusProcessor{RecordPodConditions: true}
+	ng := cp_test.NewTestNodeGroup("group 1", 1, 1, 1, true, false, "", nil, nil)
+	backoff := &testCodedReason{testReason{"in backoff after failed scale-up"}, NoScaleUpReasonNodeGroupBackoff}
+	virtualPod := func(name string) *apiv1.Pod {
+		pod := BuildTestPod(name, 0, 0)
+		pod.Annotations = map[string]string{pod_util.VirtualPodAnnotationKey: "true"}
+		pod.Status.Conditions = []apiv1.PodCondition{
+			{Type: NotTriggerScaleUpPodCondition, Status: apiv1.ConditionTrue, Reason: NoScaleUpReasonNodeGroupBackoff},
+		}
+		return pod
+	}
+
+	fakeClient := fake.NewSimpleClientset()
+	fakeRecorder := kube_record.NewFakeRecorder(5)
+	context := &context.AutoscalingContext{
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			ClientSet: fakeClient,
+			Recorder:  fakeRecorder,
+		},
+	}
+
+	p.Process(context, &ScaleUpStatus{
+		Result:               ScaleUpNoOptionsAvailable,
+		ConsideredNodeGroups: []cloudprovider.NodeGroup{ng},
+		PodsRemainUnschedulable: []NoScaleUpInfo{
+			{virtualPod("v1"), nil, map[string]Reasons{"group 1": backoff}},
+		},
+	})
+	p.Process(context, &ScaleUpStatus{
+		Result:               ScaleUpSuccessful,
+		ScaleUpInfos:         []nodegroupset.ScaleUpInfo{{}},
+		PodsTriggeredScaleUp: []*apiv1.Pod{virtualPod("v2")},
+	})
+
+	assert.Empty(t, fakeRecorder.Events)
+	assert.Empty(t, fakeClient.Actions())
+}
@@ -100,6 +100,30 @@ func (p *NoOpScaleUpStatusProcessor) Process(context *context.AutoscalingContext
 func (p *NoOpScaleUpStatusProcessor) CleanUp() {
 }
 
+// CombinedScaleUpStatusProcessor is a list of ScaleUpStatusProcessors
+type CombinedScaleUpStatusProcessor struct {
+	processors []ScaleUpStatusProcessor
+}
+
+// NewCombinedScaleUpStatusProcessor construct CombinedScaleUpStatusProcessor.
+func NewCombinedScaleUpStatusProcessor(processors []ScaleUpStatusProcessor) *CombinedScaleUpStatusProcessor {
+	return &CombinedScaleUpStatusProcessor{processors}
+}
+
+// Process runs sub-processors sequentially
+func (p *CombinedScaleUpStatusProcessor) Process(context *context.AutoscalingContext, status *ScaleUpStatus) {
+	for _, processor := range p.processors {
+		processor.Process(context, status)
+	}
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *CombinedScaleUpStatusProcessor) CleanUp() {
+	for _, processor := range p.processors {
+		processor.CleanUp()
+	}
+}
+
 // UpdateScaleUpError updates ScaleUpStatus.
 func UpdateScaleUpError(s *ScaleUpStatus, err errors.AutoscalerError) (*ScaleUpStatus, errors.AutoscalerError) {
 	s.ScaleUpError = &err
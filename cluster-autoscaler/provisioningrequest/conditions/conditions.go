@@ -56,6 +56,8 @@ const (
 	ExpiredReason = "Expired"
 	// ExpiredMsg is added if ProvisioningRequest is expired.
 	ExpiredMsg = "ProvisioningRequest is expired"
+	// InvalidParametersReason is added if ProvisioningRequest has invalid parameters.
+	InvalidParametersReason = "InvalidParameters"
 )
 
 // ShouldCapacityBeBooked returns whether capacity should be booked.
@@ -106,3 +106,17 @@ func (o *WrapperOrchestrator) ScaleUpToNodeGroupMinSize(
 ) (*status.ScaleUpStatus, errors.AutoscalerError) {
 	return o.podsOrchestrator.ScaleUpToNodeGroupMinSize(nodes, nodeInfos)
 }
+
+// SimulateScaleUp simulates a scale-up for regular pods, without executing it.
+func (o *WrapperOrchestrator) SimulateScaleUp(
+	unschedulablePods []*apiv1.Pod,
+	nodes []*apiv1.Node,
+	daemonSets []*appsv1.DaemonSet,
+	nodeInfos map[string]*schedulerframework.NodeInfo,
+) (*status.ScaleUpStatus, errors.AutoscalerError) {
+	simulator, ok := o.podsOrchestrator.(scaleup.Simulator)
+	if !ok {
+		return status.UpdateScaleUpError(&status.ScaleUpStatus{}, errors.NewAutoscalerError(errors.InternalError, "pods orchestrator can't simulate scale-ups"))
+	}
+	return simulator.SimulateScaleUp(unschedulablePods, nodes, daemonSets, nodeInfos)
+}
@@ -18,13 +18,19 @@ package provreqwrapper
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/apis/provisioningrequest/autoscaling.x-k8s.io/v1beta1"
 )
 
+// ValidUntilSecondsKey is the key of the ProvisioningRequest parameter with the number of
+// seconds since its creation after which CA stops trying to provision capacity for it.
+const ValidUntilSecondsKey = "ValidUntilSeconds"
+
 // ProvisioningRequest wrapper representation of the ProvisioningRequest
 type ProvisioningRequest struct {
 	*v1beta1.ProvisioningRequest
@@ -68,6 +74,20 @@ func (pr *ProvisioningRequest) PodSets() ([]PodSet, error) {
 	return podSets, nil
 }
 
+// ValidUntil returns the time set by the ValidUntilSeconds parameter, after which CA stops
+// trying to provision capacity for the Provisioning Request, and whether the parameter is set.
+func (pr *ProvisioningRequest) ValidUntil() (time.Time, bool, error) {
+	value, found := pr.Spec.Parameters[ValidUntilSecondsKey]
+	if !found {
+		return time.Time{}, false, nil
+	}
+	seconds, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}, true, fmt.Errorf("%s parameter has to be a positive number of seconds, got %q", ValidUntilSecondsKey, value)
+	}
+	return pr.CreationTimestamp.Add(time.Duration(seconds) * time.Second), true, nil
+}
+
 // errMissingPodTemplates creates error that is passed when there are missing pod templates.
 func errMissingPodTemplates(podSets []v1beta1.PodSet, podTemplates []*apiv1.PodTemplate) error {
 	foundPodTemplates := map[string]struct{}{}
@@ -140,3 +140,52 @@ func TestProvisioningRequestWrapper(t *testing.T) {
 	assert.Nil(t, podSets)
 	assert.EqualError(t, err, "missing pod templates, 1 pod templates were referenced, 1 templates were missing: name-pod-template-beta")
 }
+
+func TestValidUntil(t *testing.T) {
+	creationTimestamp := time.Date(2023, 11, 12, 13, 14, 15, 0, time.UTC)
+	testCases := []struct {
+		name           string
+		parameters     map[string]v1beta1.Parameter
+		wantValidUntil time.Time
+		wantFound      bool
+		wantErr        bool
+	}{
+		{
+			name: "no parameters",
+		},
+		{
+			name:           "valid until seconds",
+			parameters:     map[string]v1beta1.Parameter{ValidUntilSecondsKey: "3600"},
+			wantValidUntil: creationTimestamp.Add(time.Hour),
+			wantFound:      true,
+		},
+		{
+			name:       "not a number",
+			parameters: map[string]v1beta1.Parameter{ValidUntilSecondsKey: "1h"},
+			wantFound:  true,
+			wantErr:    true,
+		},
+		{
+			name:       "not positive",
+			parameters: map[string]v1beta1.Parameter{ValidUntilSecondsKey: "0"},
+			wantFound:  true,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := NewProvisioningRequest(&v1beta1.ProvisioningRequest{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(creationTimestamp)},
+				Spec:       v1beta1.ProvisioningRequestSpec{Parameters: tc.parameters},
+			}, nil)
+			validUntil, found, err := pr.ValidUntil()
+			assert.Equal(t, tc.wantFound, found)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantValidUntil, validUntil)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/admission/plugin/cel"
+	"k8s.io/apiserver/pkg/admission/plugin/policy/generic"
+	"k8s.io/apiserver/pkg/admission/plugin/policy/matching"
+	"k8s.io/apiserver/pkg/admission/plugin/policy/validating"
+	"k8s.io/apiserver/pkg/admission/plugin/webhook/matchconditions"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"k8s.io/apiserver/pkg/cel/environment"
+	"k8s.io/client-go/informers"
+	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	admissionregistrationv1listers "k8s.io/client-go/listers/admissionregistration/v1"
+	klog "k8s.io/klog/v2"
+)
+
+var (
+	podsResource = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	bindingKind  = schema.GroupVersionKind{Version: "v1", Kind: "Binding"}
+)
+
+// Checker evaluates ValidatingAdmissionPolicies guarding pod bindings, i.e.
+// CREATE requests for the pods/binding subresource, against simulated pod
+// placements. This allows rejecting placements that the API server would
+// refuse when the scheduler binds the pod to the node.
+//
+// Policies with a v1 Node paramKind are evaluated with the candidate node as
+// params, if the node is selected by the paramRef of the binding. Policies with
+// other param kinds are not evaluated.
+type Checker struct {
+	policyLister     admissionregistrationv1listers.ValidatingAdmissionPolicyLister
+	bindingLister    admissionregistrationv1listers.ValidatingAdmissionPolicyBindingLister
+	matcher          generic.PolicyMatcher
+	objectInterfaces admission.ObjectInterfaces
+	compositionEnv   *cel.CompositionEnv
+
+	mutex      sync.Mutex
+	validators map[types.UID]compiledPolicy
+}
+
+type compiledPolicy struct {
+	resourceVersion string
+	validator       validating.Validator
+}
+
+// NewChecker returns a new Checker using listers from the informer factory.
+func NewChecker(informerFactory informers.SharedInformerFactory, kubeClient kube_client.Interface) (*Checker, error) {
+	compositionEnv, err := cel.NewCompositionEnv(cel.VariablesTypeName, environment.MustBaseEnvSet(environment.DefaultCompatibilityVersion(), false))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create CEL environment: %v", err)
+	}
+	admissionregistration := informerFactory.Admissionregistration().V1()
+	return &Checker{
+		policyLister:     admissionregistration.ValidatingAdmissionPolicies().Lister(),
+		bindingLister:    admissionregistration.ValidatingAdmissionPolicyBindings().Lister(),
+		matcher:          generic.NewPolicyMatcher(matching.NewMatcher(informerFactory.Core().V1().Namespaces().Lister(), kubeClient)),
+		objectInterfaces: admission.NewObjectInterfacesFromScheme(scheme.Scheme),
+		compositionEnv:   compositionEnv,
+		validators:       make(map[types.UID]compiledPolicy),
+	}, nil
+}
+
+// CheckPlacement returns an error if binding the pod to the node would be
+// denied by a ValidatingAdmissionPolicy.
+func (c *Checker) CheckPlacement(pod *apiv1.Pod, node *apiv1.Node) error {
+	bindings, err := c.bindingLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("couldn't list validating admission policy bindings: %v", err)
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	podBinding := &apiv1.Binding{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID},
+		Target:     apiv1.ObjectReference{Kind: "Node", Name: node.Name},
+	}
+	attr := admission.NewAttributesRecord(podBinding, nil, bindingKind, pod.Namespace, pod.Name, podsResource, "binding", admission.Create, &metav1.CreateOptions{}, false, nil)
+	for _, binding := range bindings {
+		if !hasDenyAction(binding) {
+			continue
+		}
+		policy, err := c.policyLister.Get(binding.Spec.PolicyName)
+		if err != nil {
+			klog.V(4).Infof("Couldn't get validating admission policy %s for binding %s: %v", binding.Spec.PolicyName, binding.Name, err)
+			continue
+		}
+		matches, matchResource, matchKind, err := c.matcher.DefinitionMatches(attr, c.objectInterfaces, validating.NewValidatingAdmissionPolicyAccessor(policy))
+		if err != nil || !matches {
+			continue
+		}
+		matches, err = c.matcher.BindingMatches(attr, c.objectInterfaces, validating.NewValidatingAdmissionPolicyBindingAccessor(binding))
+		if err != nil || !matches {
+			continue
+		}
+
+		var params runtime.Object
+		if policy.Spec.ParamKind != nil {
+			if !isNodeParamKind(policy.Spec.ParamKind) {
+				klog.V(4).Infof("Skipping validating admission policy %s with unsupported param kind %s/%s", policy.Name, policy.Spec.ParamKind.APIVersion, policy.Spec.ParamKind.Kind)
+				continue
+			}
+			if !paramRefSelects(binding.Spec.ParamRef, node) {
+				if binding.Spec.ParamRef != nil && binding.Spec.ParamRef.ParameterNotFoundAction != nil && *binding.Spec.ParamRef.ParameterNotFoundAction == admissionregistrationv1.DenyAction {
+					return fmt.Errorf("denied by validating admission policy %s: node %s is not selected as params by binding %s", policy.Name, node.Name, binding.Name)
+				}
+				continue
+			}
+			params = node
+		}
+
+		var namespace *apiv1.Namespace
+		if pod.Namespace != "" {
+			namespace, err = c.matcher.GetNamespace(pod.Namespace)
+			if err != nil {
+				klog.V(4).Infof("Couldn't get namespace %s: %v", pod.Namespace, err)
+			}
+		}
+		versionedAttr := &admission.VersionedAttributes{
+			Attributes:      attr,
+			VersionedKind:   matchKind,
+			VersionedObject: podBinding,
+		}
+		result := c.validator(policy).Validate(context.TODO(), matchResource, versionedAttr, params, namespace, celconfig.RuntimeCELCostBudget, nil)
+		for _, decision := range result.Decisions {
+			if decision.Action == validating.ActionDeny {
+				return fmt.Errorf("denied by validating admission policy %s: %s", policy.Name, decision.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// validator returns a compiled validator for the policy, compiling it if the
+// policy changed since the last call.
+func (c *Checker) validator(policy *admissionregistrationv1.ValidatingAdmissionPolicy) validating.Validator {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if compiled, found := c.validators[policy.UID]; found && compiled.resourceVersion == policy.ResourceVersion {
+		return compiled.validator
+	}
+	validator := c.compile(policy)
+	c.validators[policy.UID] = compiledPolicy{resourceVersion: policy.ResourceVersion, validator: validator}
+	return validator
+}
+
+// compile compiles the policy the same way the API server does. The
+// authorizer variable is not available in the simulation, so policies
+// using it fail according to their failure policy.
+func (c *Checker) compile(policy *admissionregistrationv1.ValidatingAdmissionPolicy) validating.Validator {
+	optionalVars := cel.OptionalVariableDeclarations{HasParams: policy.Spec.ParamKind != nil}
+	compiler := cel.NewCompositedCompilerFromTemplate(c.compositionEnv)
+	for _, variable := range policy.Spec.Variables {
+		compiler.CompileAndStoreVariable(&validating.Variable{Name: variable.Name, Expression: variable.Expression}, optionalVars, environment.StoredExpressions)
+	}
+
+	var matcher matchconditions.Matcher
+	if len(policy.Spec.MatchConditions) > 0 {
+		matchExpressions := make([]cel.ExpressionAccessor, len(policy.Spec.MatchConditions))
+		for i := range policy.Spec.MatchConditions {
+			matchExpressions[i] = (*matchconditions.MatchCondition)(&policy.Spec.MatchConditions[i])
+		}
+		matcher = matchconditions.NewMatcher(compiler.Compile(matchExpressions, optionalVars, environment.StoredExpressions), policy.Spec.FailurePolicy, "policy", "validate", policy.Name)
+	}
+	validations := make([]cel.ExpressionAccessor, len(policy.Spec.Validations))
+	messageExpressions := make([]cel.ExpressionAccessor, len(policy.Spec.Validations))
+	for i, validation := range policy.Spec.Validations {
+		validations[i] = &validating.ValidationCondition{Expression: validation.Expression, Message: validation.Message, Reason: validation.Reason}
+		if validation.MessageExpression != "" {
+			messageExpressions[i] = &validating.MessageExpressionCondition{MessageExpression: validation.MessageExpression}
+		}
+	}
+	return validating.NewValidator(
+		compiler.Compile(validations, optionalVars, environment.StoredExpressions),
+		matcher,
+		compiler.Compile(nil, optionalVars, environment.StoredExpressions),
+		compiler.Compile(messageExpressions, optionalVars, environment.StoredExpressions),
+		policy.Spec.FailurePolicy,
+	)
+}
+
+func hasDenyAction(binding *admissionregistrationv1.ValidatingAdmissionPolicyBinding) bool {
+	for _, action := range binding.Spec.ValidationActions {
+		if action == admissionregistrationv1.Deny {
+			return true
+		}
+	}
+	return false
+}
+
+func isNodeParamKind(paramKind *admissionregistrationv1.ParamKind) bool {
+	return paramKind.APIVersion == "v1" && paramKind.Kind == "Node"
+}
+
+// paramRefSelects checks if the node would be one of the params selected by the paramRef.
+func paramRefSelects(paramRef *admissionregistrationv1.ParamRef, node *apiv1.Node) bool {
+	if paramRef == nil {
+		return false
+	}
+	if paramRef.Name != "" {
+		return paramRef.Name == node.Name
+	}
+	if paramRef.Selector == nil {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(paramRef.Selector)
+	if err != nil {
+		klog.V(4).Infof("Invalid param selector: %v", err)
+		return false
+	}
+	return selector.Matches(labels.Set(node.Labels))
+}
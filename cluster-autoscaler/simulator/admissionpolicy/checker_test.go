@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func bindingPolicy(name string, paramKind *admissionregistrationv1.ParamKind, expression string) *admissionregistrationv1.ValidatingAdmissionPolicy {
+	return &admissionregistrationv1.ValidatingAdmissionPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID("uid-" + name), ResourceVersion: "1"},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicySpec{
+			ParamKind: paramKind,
+			MatchConstraints: &admissionregistrationv1.MatchResources{
+				// Selectors are defaulted by the API server.
+				NamespaceSelector: &metav1.LabelSelector{},
+				ObjectSelector:    &metav1.LabelSelector{},
+				ResourceRules: []admissionregistrationv1.NamedRuleWithOperations{{
+					RuleWithOperations: admissionregistrationv1.RuleWithOperations{
+						Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+						Rule: admissionregistrationv1.Rule{
+							APIGroups:   []string{""},
+							APIVersions: []string{"v1"},
+							Resources:   []string{"pods/binding"},
+						},
+					},
+				}},
+			},
+			Validations: []admissionregistrationv1.Validation{{Expression: expression}},
+		},
+	}
+}
+
+func policyBinding(name, policyName string, paramRef *admissionregistrationv1.ParamRef, actions ...admissionregistrationv1.ValidationAction) *admissionregistrationv1.ValidatingAdmissionPolicyBinding {
+	return &admissionregistrationv1.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: admissionregistrationv1.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        policyName,
+			ParamRef:          paramRef,
+			ValidationActions: actions,
+		},
+	}
+}
+
+func newTestChecker(t *testing.T, policies []*admissionregistrationv1.ValidatingAdmissionPolicy, bindings []*admissionregistrationv1.ValidatingAdmissionPolicyBinding) *Checker {
+	client := fake.NewSimpleClientset()
+	informerFactory := informers.NewSharedInformerFactory(client, 0)
+	checker, err := NewChecker(informerFactory, client)
+	assert.NoError(t, err)
+	admissionregistration := informerFactory.Admissionregistration().V1()
+	for _, policy := range policies {
+		assert.NoError(t, admissionregistration.ValidatingAdmissionPolicies().Informer().GetStore().Add(policy))
+	}
+	for _, binding := range bindings {
+		assert.NoError(t, admissionregistration.ValidatingAdmissionPolicyBindings().Informer().GetStore().Add(binding))
+	}
+	return checker
+}
+
+func TestCheckPlacement(t *testing.T) {
+	nodeParamKind := &admissionregistrationv1.ParamKind{APIVersion: "v1", Kind: "Node"}
+	restrictedNodes := &admissionregistrationv1.ParamRef{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"pool": "restricted"}},
+	}
+	denyNotFound := admissionregistrationv1.DenyAction
+	policies := []*admissionregistrationv1.ValidatingAdmissionPolicy{
+		bindingPolicy("restricted-nodes", nodeParamKind, "object.target.name != params.metadata.name || object.metadata.namespace == 'trusted'"),
+		bindingPolicy("no-forbidden-pods", nil, "object.metadata.name != 'forbidden'"),
+		bindingPolicy("broken", nil, "object.doesNotExist"),
+	}
+
+	regularNode := BuildTestNode("regular", 1000, 1000)
+	restrictedNode := BuildTestNode("restricted", 1000, 1000)
+	restrictedNode.Labels["pool"] = "restricted"
+
+	pod := BuildTestPod("p", 100, 100)
+	trustedPod := BuildTestPod("p", 100, 100)
+	trustedPod.Namespace = "trusted"
+	forbiddenPod := BuildTestPod("forbidden", 100, 100)
+
+	testCases := []struct {
+		name      string
+		bindings  []*admissionregistrationv1.ValidatingAdmissionPolicyBinding
+		pod       *apiv1.Pod
+		node      *apiv1.Node
+		wantError bool
+	}{
+		{
+			name: "no bindings",
+			pod:  pod,
+			node: restrictedNode,
+		},
+		{
+			name:      "denied by node params",
+			bindings:  []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "restricted-nodes", restrictedNodes, admissionregistrationv1.Deny)},
+			pod:       pod,
+			node:      restrictedNode,
+			wantError: true,
+		},
+		{
+			name:     "allowed by node params",
+			bindings: []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "restricted-nodes", restrictedNodes, admissionregistrationv1.Deny)},
+			pod:      trustedPod,
+			node:     restrictedNode,
+		},
+		{
+			name:     "node not selected as params",
+			bindings: []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "restricted-nodes", restrictedNodes, admissionregistrationv1.Deny)},
+			pod:      pod,
+			node:     regularNode,
+		},
+		{
+			name: "node not selected as params, deny when not found",
+			bindings: []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "restricted-nodes", &admissionregistrationv1.ParamRef{
+				Selector:                restrictedNodes.Selector,
+				ParameterNotFoundAction: &denyNotFound,
+			}, admissionregistrationv1.Deny)},
+			pod:       pod,
+			node:      regularNode,
+			wantError: true,
+		},
+		{
+			name:      "denied without params",
+			bindings:  []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "no-forbidden-pods", nil, admissionregistrationv1.Deny)},
+			pod:       forbiddenPod,
+			node:      regularNode,
+			wantError: true,
+		},
+		{
+			name:     "audit only binding",
+			bindings: []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "no-forbidden-pods", nil, admissionregistrationv1.Audit)},
+			pod:      forbiddenPod,
+			node:     regularNode,
+		},
+		{
+			name:     "missing policy",
+			bindings: []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "missing", nil, admissionregistrationv1.Deny)},
+			pod:      forbiddenPod,
+			node:     regularNode,
+		},
+		{
+			name:      "invalid policy fails closed",
+			bindings:  []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{policyBinding("b", "broken", nil, admissionregistrationv1.Deny)},
+			pod:       pod,
+			node:      regularNode,
+			wantError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checker := newTestChecker(t, policies, tc.bindings)
+			err := checker.CheckPlacement(tc.pod, tc.node)
+			if tc.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPredicateChecker(t *testing.T) {
+	regularNode := BuildTestNode("regular", 1000, 1000)
+	restrictedNode := BuildTestNode("restricted", 1000, 1000)
+	restrictedNode.Labels["pool"] = "restricted"
+	policy := bindingPolicy("restricted-nodes", nil, "object.target.name != 'restricted'")
+	binding := policyBinding("b", policy.Name, nil, admissionregistrationv1.Deny)
+
+	schedulerPredicateChecker, err := predicatechecker.NewTestPredicateChecker()
+	assert.NoError(t, err)
+	predicateChecker := NewPredicateChecker(schedulerPredicateChecker, newTestChecker(t, []*admissionregistrationv1.ValidatingAdmissionPolicy{policy}, []*admissionregistrationv1.ValidatingAdmissionPolicyBinding{binding}))
+
+	snapshot := clustersnapshot.NewBasicClusterSnapshot()
+	clustersnapshot.InitializeClusterSnapshotOrDie(t, snapshot, []*apiv1.Node{restrictedNode, regularNode}, nil)
+	pod := BuildTestPod("p", 100, 100)
+
+	predicateError := predicateChecker.CheckPredicates(snapshot, pod, restrictedNode.Name)
+	assert.NotNil(t, predicateError)
+	assert.Equal(t, predicatechecker.NotSchedulablePredicateError, predicateError.ErrorType())
+	assert.Equal(t, predicateName, predicateError.PredicateName())
+	assert.Nil(t, predicateChecker.CheckPredicates(snapshot, pod, regularNode.Name))
+
+	for i := 0; i < 3; i++ {
+		nodeName, err := predicateChecker.FitsAnyNode(snapshot, pod)
+		assert.NoError(t, err)
+		assert.Equal(t, regularNode.Name, nodeName)
+	}
+}
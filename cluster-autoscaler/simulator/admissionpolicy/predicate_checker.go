@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/predicatechecker"
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+const predicateName = "ValidatingAdmissionPolicy"
+
+// PredicateChecker wraps a PredicateChecker, additionally rejecting placements
+// that would be denied by ValidatingAdmissionPolicies.
+type PredicateChecker struct {
+	predicatechecker.PredicateChecker
+	checker *Checker
+}
+
+// NewPredicateChecker returns a PredicateChecker running placements accepted by
+// the given predicate checker through validating admission policies.
+func NewPredicateChecker(predicateChecker predicatechecker.PredicateChecker, checker *Checker) *PredicateChecker {
+	return &PredicateChecker{
+		PredicateChecker: predicateChecker,
+		checker:          checker,
+	}
+}
+
+// FitsAnyNode checks if the given pod can be placed on any of the given nodes.
+func (p *PredicateChecker) FitsAnyNode(clusterSnapshot clustersnapshot.ClusterSnapshot, pod *apiv1.Pod) (string, error) {
+	return p.FitsAnyNodeMatching(clusterSnapshot, pod, func(*schedulerframework.NodeInfo) bool {
+		return true
+	})
+}
+
+// FitsAnyNodeMatching checks if the given pod can be placed on any of the given nodes matching the provided function.
+func (p *PredicateChecker) FitsAnyNodeMatching(clusterSnapshot clustersnapshot.ClusterSnapshot, pod *apiv1.Pod, nodeMatches func(*schedulerframework.NodeInfo) bool) (string, error) {
+	return p.PredicateChecker.FitsAnyNodeMatching(clusterSnapshot, pod, func(nodeInfo *schedulerframework.NodeInfo) bool {
+		if !nodeMatches(nodeInfo) {
+			return false
+		}
+		if err := p.checker.CheckPlacement(pod, nodeInfo.Node()); err != nil {
+			klog.V(5).Infof("Pod %s/%s can't be placed on node %s: %v", pod.Namespace, pod.Name, nodeInfo.Node().Name, err)
+			return false
+		}
+		return true
+	})
+}
+
+// CheckPredicates checks if the given pod can be placed on the given node.
+func (p *PredicateChecker) CheckPredicates(clusterSnapshot clustersnapshot.ClusterSnapshot, pod *apiv1.Pod, nodeName string) *predicatechecker.PredicateError {
+	if predicateError := p.PredicateChecker.CheckPredicates(clusterSnapshot, pod, nodeName); predicateError != nil {
+		return predicateError
+	}
+	nodeInfo, err := clusterSnapshot.NodeInfos().Get(nodeName)
+	if err != nil {
+		errorMessage := fmt.Sprintf("Error obtaining NodeInfo for name %s; %v", nodeName, err)
+		return predicatechecker.NewPredicateError(predicatechecker.InternalPredicateError, "", errorMessage, nil, emptyString)
+	}
+	if err := p.checker.CheckPlacement(pod, nodeInfo.Node()); err != nil {
+		return predicatechecker.NewPredicateError(predicatechecker.NotSchedulablePredicateError, predicateName, err.Error(), []string{err.Error()}, emptyString)
+	}
+	return nil
+}
+
+func emptyString() string {
+	return ""
+}
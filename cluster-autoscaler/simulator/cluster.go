@@ -18,6 +18,7 @@ package simulator
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/pdb"
@@ -89,8 +90,58 @@ const (
 	BlockedByPod
 	// UnexpectedError - node can't be removed because of an unexpected error.
 	UnexpectedError
+	// RecentScaleUp - node is unneeded, but can't be removed because scale down is in cooldown after a recent scale up.
+	RecentScaleUp
 )
 
+func (r UnremovableReason) String() string {
+	switch r {
+	case NoReason:
+		return "NoReason"
+	case ScaleDownDisabledAnnotation:
+		return "ScaleDownDisabledAnnotation"
+	case ScaleDownUnreadyDisabled:
+		return "ScaleDownUnreadyDisabled"
+	case NotAutoscaled:
+		return "NotAutoscaled"
+	case NotUnneededLongEnough:
+		return "NotUnneededLongEnough"
+	case NotUnreadyLongEnough:
+		return "NotUnreadyLongEnough"
+	case NodeGroupMinSizeReached:
+		return "NodeGroupMinSizeReached"
+	case MinimalResourceLimitExceeded:
+		return "MinimalResourceLimitExceeded"
+	case CurrentlyBeingDeleted:
+		return "CurrentlyBeingDeleted"
+	case NotUnderutilized:
+		return "NotUnderutilized"
+	case NotUnneededOtherReason:
+		return "NotUnneededOtherReason"
+	case RecentlyUnremovable:
+		return "RecentlyUnremovable"
+	case NoPlaceToMovePods:
+		return "NoPlaceToMovePods"
+	case BlockedByPod:
+		return "BlockedByPod"
+	case UnexpectedError:
+		return "UnexpectedError"
+	case RecentScaleUp:
+		return "RecentScaleUp"
+	default:
+		return fmt.Sprintf("unrecognized reason: %d", int(r))
+	}
+}
+
+// BlockingReason returns the most specific reason why the node can't be
+// removed, i.e. the blocking pod reason for nodes blocked by a pod.
+func (n *UnremovableNode) BlockingReason() string {
+	if n.Reason == BlockedByPod && n.BlockingPod != nil {
+		return n.BlockingPod.Reason.String()
+	}
+	return n.Reason.String()
+}
+
 // RemovalSimulator is a helper object for simulating node removal scenarios.
 type RemovalSimulator struct {
 	listers             kube_util.ListerRegistry
@@ -165,7 +216,7 @@ func (r *RemovalSimulator) SimulateNodeRemoval(
 	}
 
 	err = r.withForkedSnapshot(func() error {
-		return r.findPlaceFor(nodeName, podsToRemove, destinationMap, timestamp)
+		return r.findPlaceFor(map[string][]*apiv1.Pod{nodeName: podsToRemove}, destinationMap, timestamp)
 	})
 	if err != nil {
 		klog.V(2).Infof("node %s is not suitable for removal: %v", nodeName, err)
@@ -179,6 +230,64 @@ func (r *RemovalSimulator) SimulateNodeRemoval(
 	}, nil
 }
 
+// SimulateNodesRemoval simulates removing a group of nodes at once to check
+// whether it is possible to move all their pods to the remaining nodes. Pods
+// are rescheduled together, so replicas spread over the group by pod
+// anti-affinity are repacked jointly instead of node by node. Either all nodes
+// of the group are returned as removable, or the node blocking the removal of
+// the group is returned as unremovable.
+func (r *RemovalSimulator) SimulateNodesRemoval(
+	nodeNames []string,
+	destinationMap map[string]bool,
+	timestamp time.Time,
+	remainingPdbTracker pdb.RemainingPdbTracker,
+) ([]NodeToBeRemoved, *UnremovableNode) {
+	klog.V(2).Infof("Simulating removal of nodes %v", nodeNames)
+
+	var nodesToRemove []NodeToBeRemoved
+	var allPodsToRemove []*apiv1.Pod
+	podsByNode := make(map[string][]*apiv1.Pod, len(nodeNames))
+	for _, nodeName := range nodeNames {
+		nodeInfo, err := r.clusterSnapshot.NodeInfos().Get(nodeName)
+		if err != nil {
+			klog.Errorf("Can't retrieve node %s from snapshot, err: %v", nodeName, err)
+			return nil, &UnremovableNode{Node: &apiv1.Node{}, Reason: UnexpectedError}
+		}
+		podsToRemove, daemonSetPods, blockingPod, err := GetPodsToMove(nodeInfo, r.deleteOptions, r.drainabilityRules, r.listers, remainingPdbTracker, timestamp)
+		if err != nil {
+			klog.V(2).Infof("node %s cannot be removed: %v", nodeName, err)
+			if blockingPod != nil {
+				return nil, &UnremovableNode{Node: nodeInfo.Node(), Reason: BlockedByPod, BlockingPod: blockingPod}
+			}
+			return nil, &UnremovableNode{Node: nodeInfo.Node(), Reason: UnexpectedError}
+		}
+		podsByNode[nodeName] = podsToRemove
+		allPodsToRemove = append(allPodsToRemove, podsToRemove...)
+		nodesToRemove = append(nodesToRemove, NodeToBeRemoved{
+			Node:             nodeInfo.Node(),
+			PodsToReschedule: podsToRemove,
+			DaemonSetPods:    daemonSetPods,
+		})
+	}
+	// Budgets checked per node above may still be exceeded by the whole group.
+	if remainingPdbTracker != nil {
+		if canRemove, _, blockingPod := remainingPdbTracker.CanRemovePods(allPodsToRemove); !canRemove {
+			klog.V(2).Infof("nodes %v cannot be removed together: pod disruption budget exceeded", nodeNames)
+			return nil, &UnremovableNode{Node: nodesToRemove[0].Node, Reason: BlockedByPod, BlockingPod: blockingPod}
+		}
+	}
+
+	err := r.withForkedSnapshot(func() error {
+		return r.findPlaceFor(podsByNode, destinationMap, timestamp)
+	})
+	if err != nil {
+		klog.V(2).Infof("nodes %v are not suitable for removal: %v", nodeNames, err)
+		return nil, &UnremovableNode{Node: nodesToRemove[0].Node, Reason: NoPlaceToMovePods}
+	}
+	klog.V(2).Infof("nodes %v may be removed", nodeNames)
+	return nodesToRemove, nil
+}
+
 // FindEmptyNodesToRemove finds empty nodes that can be removed.
 func (r *RemovalSimulator) FindEmptyNodesToRemove(candidates []string, timestamp time.Time) []string {
 	result := make([]string, 0)
@@ -213,26 +322,39 @@ func (r *RemovalSimulator) withForkedSnapshot(f func() error) (err error) {
 	return err
 }
 
-func (r *RemovalSimulator) findPlaceFor(removedNode string, pods []*apiv1.Pod, nodes map[string]bool, timestamp time.Time) error {
+// findPlaceFor reschedules pods of the removed nodes, keyed by node name, on the
+// remaining destination nodes.
+func (r *RemovalSimulator) findPlaceFor(podsByNode map[string][]*apiv1.Pod, nodes map[string]bool, timestamp time.Time) error {
 	isCandidateNode := func(nodeInfo *schedulerframework.NodeInfo) bool {
-		return nodeInfo.Node().Name != removedNode && nodes[nodeInfo.Node().Name]
+		_, removed := podsByNode[nodeInfo.Node().Name]
+		return !removed && nodes[nodeInfo.Node().Name]
+	}
+
+	removedNodes := make([]string, 0, len(podsByNode))
+	for removedNode := range podsByNode {
+		removedNodes = append(removedNodes, removedNode)
 	}
+	sort.Strings(removedNodes)
 
-	pods = tpu.ClearTPURequests(pods)
+	var newpods []*apiv1.Pod
+	removedNodeOf := make(map[*apiv1.Pod]string)
+	for _, removedNode := range removedNodes {
+		pods := tpu.ClearTPURequests(podsByNode[removedNode])
 
-	// remove pods from clusterSnapshot first
-	for _, pod := range pods {
-		if err := r.clusterSnapshot.RemovePod(pod.Namespace, pod.Name, removedNode); err != nil {
-			// just log error
-			klog.Errorf("Simulating removal of %s/%s return error; %v", pod.Namespace, pod.Name, err)
+		// remove pods from clusterSnapshot first
+		for _, pod := range pods {
+			if err := r.clusterSnapshot.RemovePod(pod.Namespace, pod.Name, removedNode); err != nil {
+				// just log error
+				klog.Errorf("Simulating removal of %s/%s return error; %v", pod.Namespace, pod.Name, err)
+			}
 		}
-	}
 
-	newpods := make([]*apiv1.Pod, 0, len(pods))
-	for _, podptr := range pods {
-		newpod := *podptr
-		newpod.Spec.NodeName = ""
-		newpods = append(newpods, &newpod)
+		for _, podptr := range pods {
+			newpod := *podptr
+			newpod.Spec.NodeName = ""
+			newpods = append(newpods, &newpod)
+			removedNodeOf[&newpod] = removedNode
+		}
 	}
 
 	statuses, _, err := r.schedulingSimulator.TrySchedulePods(r.clusterSnapshot, newpods, isCandidateNode, true)
@@ -244,7 +366,7 @@ func (r *RemovalSimulator) findPlaceFor(removedNode string, pods []*apiv1.Pod, n
 	}
 
 	for _, status := range statuses {
-		r.usageTracker.RegisterUsage(removedNode, status.NodeName, timestamp)
+		r.usageTracker.RegisterUsage(removedNodeOf[status.Pod], status.NodeName, timestamp)
 	}
 	return nil
 }
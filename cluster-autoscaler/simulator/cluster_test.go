@@ -202,6 +202,101 @@ func TestFindNodesToRemove(t *testing.T) {
 	}
 }
 
+func TestSimulateNodesRemoval(t *testing.T) {
+	var nodes []*apiv1.Node
+	for _, name := range []string{"n1", "n2", "n3", "n4"} {
+		node := BuildTestNode(name, 1000, 2000000)
+		node.Labels[apiv1.LabelHostname] = name
+		SetNodeReadyState(node, true, time.Time{})
+		nodes = append(nodes, node)
+	}
+	n1, n2, n3, n4 := nodes[0], nodes[1], nodes[2], nodes[3]
+
+	replicas := int32(2)
+	replicaSets := []*appsv1.ReplicaSet{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rs",
+				Namespace: "default",
+				SelfLink:  "api/v1/namespaces/default/replicasets/rs",
+			},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: &replicas,
+			},
+		},
+	}
+	rsLister, err := kube_util.NewTestReplicaSetLister(replicaSets)
+	assert.NoError(t, err)
+	registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, rsLister, nil)
+
+	buildReplica := func(name, nodeName string) *apiv1.Pod {
+		pod := BuildTestPod(name, 100, 100000)
+		pod.OwnerReferences = GenerateOwnerReferences("rs", "ReplicaSet", "extensions/v1beta1", "")
+		pod.Labels = map[string]string{"app": "spread"}
+		pod.Spec.NodeName = nodeName
+		pod.Spec.Affinity = &apiv1.Affinity{
+			PodAntiAffinity: &apiv1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []apiv1.PodAffinityTerm{{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "spread"}},
+					TopologyKey:   apiv1.LabelHostname,
+				}},
+			},
+		}
+		return pod
+	}
+	replica1 := buildReplica("r1", "n1")
+	replica2 := buildReplica("r2", "n2")
+	notReplicated := BuildTestPod("p", 100, 100000)
+	notReplicated.Spec.NodeName = "n2"
+
+	tests := []struct {
+		name         string
+		pods         []*apiv1.Pod
+		destinations []string
+		toRemove     []NodeToBeRemoved
+		unremovable  *UnremovableNode
+	}{
+		{
+			name:         "replicas repacked on separate nodes",
+			pods:         []*apiv1.Pod{replica1, replica2},
+			destinations: []string{"n1", "n2", "n3", "n4"},
+			toRemove: []NodeToBeRemoved{
+				{Node: n1, PodsToReschedule: []*apiv1.Pod{replica1}},
+				{Node: n2, PodsToReschedule: []*apiv1.Pod{replica2}},
+			},
+		},
+		{
+			name:         "replicas can't share the only destination",
+			pods:         []*apiv1.Pod{replica1, replica2},
+			destinations: []string{"n1", "n2", "n3"},
+			unremovable:  &UnremovableNode{Node: n1, Reason: NoPlaceToMovePods},
+		},
+		{
+			name:         "group blocked by a pod on one of the nodes",
+			pods:         []*apiv1.Pod{replica1, replica2, notReplicated},
+			destinations: []string{"n1", "n2", "n3", "n4"},
+			unremovable:  &UnremovableNode{Node: n2, Reason: BlockedByPod, BlockingPod: &drain.BlockingPod{Pod: notReplicated, Reason: drain.NotReplicated}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clusterSnapshot := clustersnapshot.NewBasicClusterSnapshot()
+			clustersnapshot.InitializeClusterSnapshotOrDie(t, clusterSnapshot, []*apiv1.Node{n1, n2, n3, n4}, test.pods)
+			predicateChecker, err := predicatechecker.NewTestPredicateChecker()
+			assert.NoError(t, err)
+			destinations := make(map[string]bool)
+			for _, destination := range test.destinations {
+				destinations[destination] = true
+			}
+			r := NewRemovalSimulator(registry, clusterSnapshot, predicateChecker, NewUsageTracker(), testDeleteOptions(), nil, false)
+			toRemove, unremovable := r.SimulateNodesRemoval([]string{"n1", "n2"}, destinations, time.Now(), nil)
+			assert.Equal(t, test.toRemove, toRemove)
+			assert.Equal(t, test.unremovable, unremovable)
+		})
+	}
+}
+
 func testDeleteOptions() options.NodeDeleteOptions {
 	return options.NodeDeleteOptions{
 		SkipNodesWithSystemPods:           true,
@@ -27,7 +27,8 @@ import (
 // BasicClusterSnapshot is simple, reference implementation of ClusterSnapshot.
 // It is inefficient. But hopefully bug-free and good for initial testing.
 type BasicClusterSnapshot struct {
-	data []*internalBasicSnapshotData
+	data     []*internalBasicSnapshotData
+	revision int64
 }
 
 type internalBasicSnapshotData struct {
@@ -214,11 +215,13 @@ func (snapshot *BasicClusterSnapshot) getInternalData() *internalBasicSnapshotDa
 
 // AddNode adds node to the snapshot.
 func (snapshot *BasicClusterSnapshot) AddNode(node *apiv1.Node) error {
+	snapshot.revision++
 	return snapshot.getInternalData().addNode(node)
 }
 
 // AddNodes adds nodes in batch to the snapshot.
 func (snapshot *BasicClusterSnapshot) AddNodes(nodes []*apiv1.Node) error {
+	snapshot.revision++
 	return snapshot.getInternalData().addNodes(nodes)
 }
 
@@ -237,16 +240,19 @@ func (snapshot *BasicClusterSnapshot) AddNodeWithPods(node *apiv1.Node, pods []*
 
 // RemoveNode removes nodes (and pods scheduled to it) from the snapshot.
 func (snapshot *BasicClusterSnapshot) RemoveNode(nodeName string) error {
+	snapshot.revision++
 	return snapshot.getInternalData().removeNode(nodeName)
 }
 
 // AddPod adds pod to the snapshot and schedules it to given node.
 func (snapshot *BasicClusterSnapshot) AddPod(pod *apiv1.Pod, nodeName string) error {
+	snapshot.revision++
 	return snapshot.getInternalData().addPod(pod, nodeName)
 }
 
 // RemovePod removes pod from the snapshot.
 func (snapshot *BasicClusterSnapshot) RemovePod(namespace, podName, nodeName string) error {
+	snapshot.revision++
 	return snapshot.getInternalData().removePod(namespace, podName, nodeName)
 }
 
@@ -263,6 +269,7 @@ func (snapshot *BasicClusterSnapshot) Fork() {
 
 // Revert reverts snapshot state to moment of forking.
 func (snapshot *BasicClusterSnapshot) Revert() {
+	snapshot.revision++
 	if len(snapshot.data) == 1 {
 		return
 	}
@@ -271,6 +278,7 @@ func (snapshot *BasicClusterSnapshot) Revert() {
 
 // Commit commits changes done after forking.
 func (snapshot *BasicClusterSnapshot) Commit() error {
+	snapshot.revision++
 	if len(snapshot.data) <= 1 {
 		// do nothing
 		return nil
@@ -281,10 +289,16 @@ func (snapshot *BasicClusterSnapshot) Commit() error {
 
 // Clear reset cluster snapshot to empty, unforked state
 func (snapshot *BasicClusterSnapshot) Clear() {
+	snapshot.revision++
 	baseData := newInternalBasicSnapshotData()
 	snapshot.data = []*internalBasicSnapshotData{baseData}
 }
 
+// Revision returns the revision of the snapshot, increased by every modification done through the snapshot.
+func (snapshot *BasicClusterSnapshot) Revision() int64 {
+	return snapshot.revision
+}
+
 // implementation of SharedLister interface
 
 type basicClusterSnapshotNodeLister BasicClusterSnapshot
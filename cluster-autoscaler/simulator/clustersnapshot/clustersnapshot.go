@@ -52,6 +52,9 @@ type ClusterSnapshot interface {
 	Commit() error
 	// Clear reset cluster snapshot to empty, unforked state.
 	Clear()
+	// Revision returns the revision of the snapshot, increased by every modification done through the snapshot.
+	// Processors can compare revisions to detect whether the snapshot changed in between.
+	Revision() int64
 }
 
 // ErrNodeNotFound means that a node wasn't found in the snapshot.
@@ -42,7 +42,8 @@ import (
 //	pod affinity - causes scheduler framework to list pods with non-empty selector,
 //		so basic caching doesn't help.
 type DeltaClusterSnapshot struct {
-	data *internalDeltaSnapshotData
+	data     *internalDeltaSnapshotData
+	revision int64
 }
 
 type deltaSnapshotNodeLister DeltaClusterSnapshot
@@ -404,11 +405,13 @@ func NewDeltaClusterSnapshot() *DeltaClusterSnapshot {
 
 // AddNode adds node to the snapshot.
 func (snapshot *DeltaClusterSnapshot) AddNode(node *apiv1.Node) error {
+	snapshot.revision++
 	return snapshot.data.addNode(node)
 }
 
 // AddNodes adds nodes in batch to the snapshot.
 func (snapshot *DeltaClusterSnapshot) AddNodes(nodes []*apiv1.Node) error {
+	snapshot.revision++
 	return snapshot.data.addNodes(nodes)
 }
 
@@ -427,16 +430,19 @@ func (snapshot *DeltaClusterSnapshot) AddNodeWithPods(node *apiv1.Node, pods []*
 
 // RemoveNode removes nodes (and pods scheduled to it) from the snapshot.
 func (snapshot *DeltaClusterSnapshot) RemoveNode(nodeName string) error {
+	snapshot.revision++
 	return snapshot.data.removeNode(nodeName)
 }
 
 // AddPod adds pod to the snapshot and schedules it to given node.
 func (snapshot *DeltaClusterSnapshot) AddPod(pod *apiv1.Pod, nodeName string) error {
+	snapshot.revision++
 	return snapshot.data.addPod(pod, nodeName)
 }
 
 // RemovePod removes pod from the snapshot.
 func (snapshot *DeltaClusterSnapshot) RemovePod(namespace, podName, nodeName string) error {
+	snapshot.revision++
 	return snapshot.data.removePod(namespace, podName, nodeName)
 }
 
@@ -454,6 +460,7 @@ func (snapshot *DeltaClusterSnapshot) Fork() {
 // Revert reverts snapshot state to moment of forking.
 // Time: O(1)
 func (snapshot *DeltaClusterSnapshot) Revert() {
+	snapshot.revision++
 	if snapshot.data.baseData != nil {
 		snapshot.data = snapshot.data.baseData
 	}
@@ -462,6 +469,7 @@ func (snapshot *DeltaClusterSnapshot) Revert() {
 // Commit commits changes done after forking.
 // Time: O(n), where n = size of delta (number of nodes added, modified or deleted since forking)
 func (snapshot *DeltaClusterSnapshot) Commit() error {
+	snapshot.revision++
 	newData, err := snapshot.data.commit()
 	if err != nil {
 		return err
@@ -473,5 +481,12 @@ func (snapshot *DeltaClusterSnapshot) Commit() error {
 // Clear reset cluster snapshot to empty, unforked state
 // Time: O(1)
 func (snapshot *DeltaClusterSnapshot) Clear() {
+	snapshot.revision++
 	snapshot.data = newInternalDeltaSnapshotData()
 }
+
+// Revision returns the revision of the snapshot, increased by every modification done through the snapshot.
+// Time: O(1)
+func (snapshot *DeltaClusterSnapshot) Revision() int64 {
+	return snapshot.revision
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustersnapshot
+
+import (
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"sort"
+
+	apiv1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubernetes/pkg/api/v1/resource"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// RevisionCheckpoint records the revision of a snapshot and the state of its
+// nodes, so that modifications bypassing the snapshot API can be detected later
+// on. Such modifications, e.g. pods mutated in place or pods added to node infos
+// directly, leave data cached by the snapshot stale, like requests of pods.
+type RevisionCheckpoint struct {
+	revision int64
+	nodes    map[string]nodeCheckpoint
+}
+
+type nodeCheckpoint struct {
+	generation  int64
+	fingerprint uint64
+}
+
+// NewRevisionCheckpoint records the current revision and state of the snapshot.
+func NewRevisionCheckpoint(snapshot ClusterSnapshot) (*RevisionCheckpoint, error) {
+	nodeInfos, err := snapshot.NodeInfos().List()
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &RevisionCheckpoint{
+		revision: snapshot.Revision(),
+		nodes:    make(map[string]nodeCheckpoint, len(nodeInfos)),
+	}
+	for _, nodeInfo := range nodeInfos {
+		checkpoint.nodes[nodeInfo.Node().Name] = nodeCheckpoint{
+			generation:  nodeInfo.Generation,
+			fingerprint: fingerprintNodeInfo(nodeInfo),
+		}
+	}
+	return checkpoint, nil
+}
+
+// Verify checks that the snapshot was only modified through its API since the
+// checkpoint was recorded. Node infos modified through the API have a new
+// generation, all other node infos are expected to be unchanged. Node infos
+// which changed generation without the revision of the snapshot changing were
+// modified directly.
+func (c *RevisionCheckpoint) Verify(snapshot ClusterSnapshot) error {
+	nodeInfos, err := snapshot.NodeInfos().List()
+	if err != nil {
+		return err
+	}
+	revisionChanged := snapshot.Revision() != c.revision
+	if !revisionChanged && len(nodeInfos) != len(c.nodes) {
+		return fmt.Errorf("number of nodes changed from %d to %d at revision %d", len(c.nodes), len(nodeInfos), c.revision)
+	}
+	for _, nodeInfo := range nodeInfos {
+		nodeName := nodeInfo.Node().Name
+		node, found := c.nodes[nodeName]
+		if !found {
+			if revisionChanged {
+				continue
+			}
+			return fmt.Errorf("node %s added at revision %d", nodeName, c.revision)
+		}
+		if nodeInfo.Generation != node.generation {
+			if revisionChanged {
+				continue
+			}
+			return fmt.Errorf("node info of node %s changed generation at revision %d", nodeName, c.revision)
+		}
+		if fingerprintNodeInfo(nodeInfo) != node.fingerprint {
+			return fmt.Errorf("node %s or pods scheduled on it were modified in place at generation %d", nodeName, node.generation)
+		}
+	}
+	return nil
+}
+
+// fingerprintNodeInfo hashes the node, the pods scheduled on it and their
+// requests, both recomputed from the pods and as cached by the node info.
+func fingerprintNodeInfo(nodeInfo *schedulerframework.NodeInfo) uint64 {
+	h := fnv.New64a()
+	node := nodeInfo.Node()
+	fmt.Fprintf(h, "%s/%s/%s;", node.Name, node.UID, node.ResourceVersion)
+	writeSortedMap(h, node.Labels)
+	for _, taint := range node.Spec.Taints {
+		fmt.Fprintf(h, "%s=%s:%s;", taint.Key, taint.Value, taint.Effect)
+	}
+	writeResourceList(h, node.Status.Allocatable)
+
+	for _, podInfo := range nodeInfo.Pods {
+		pod := podInfo.Pod
+		fmt.Fprintf(h, "%s/%s/%s;", pod.Namespace, pod.Name, pod.UID)
+		writeSortedMap(h, pod.Spec.NodeSelector)
+		writeResourceList(h, resourcehelper.PodRequests(pod, resourcehelper.PodResourcesOptions{}))
+	}
+	fmt.Fprintf(h, "%d/%d/%d;", nodeInfo.Requested.MilliCPU, nodeInfo.Requested.Memory, nodeInfo.Requested.EphemeralStorage)
+	return h.Sum64()
+}
+
+func writeSortedMap(h hash.Hash64, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s;", k, m[k])
+	}
+}
+
+func writeResourceList(h hash.Hash64, resources apiv1.ResourceList) {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		quantity := resources[apiv1.ResourceName(name)]
+		fmt.Fprintf(h, "%s=%s;", name, quantity.String())
+	}
+}
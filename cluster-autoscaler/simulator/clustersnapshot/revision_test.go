@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clustersnapshot
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestRevision(t *testing.T) {
+	for name, snapshotFactory := range snapshots {
+		t.Run(name, func(t *testing.T) {
+			snapshot := snapshotFactory()
+			node := BuildTestNode("node", 1000, 1000)
+			pod := BuildTestPod("pod", 100, 100)
+
+			revision := snapshot.Revision()
+			require.NoError(t, snapshot.AddNode(node))
+			assert.Greater(t, snapshot.Revision(), revision)
+
+			revision = snapshot.Revision()
+			snapshot.Fork()
+			assert.Equal(t, revision, snapshot.Revision())
+			require.NoError(t, snapshot.AddPod(pod, node.Name))
+			assert.Greater(t, snapshot.Revision(), revision)
+
+			revision = snapshot.Revision()
+			snapshot.Revert()
+			assert.Greater(t, snapshot.Revision(), revision)
+
+			revision = snapshot.Revision()
+			snapshot.Clear()
+			assert.Greater(t, snapshot.Revision(), revision)
+		})
+	}
+}
+
+func TestRevisionCheckpoint(t *testing.T) {
+	testCases := []struct {
+		name      string
+		modify    func(t *testing.T, snapshot ClusterSnapshot)
+		expectErr bool
+	}{
+		{
+			name:   "unmodified",
+			modify: func(t *testing.T, snapshot ClusterSnapshot) {},
+		},
+		{
+			name: "pod added through the snapshot",
+			modify: func(t *testing.T, snapshot ClusterSnapshot) {
+				require.NoError(t, snapshot.AddPod(BuildTestPod("new", 100, 100), "node-1"))
+			},
+		},
+		{
+			name: "node added through the snapshot",
+			modify: func(t *testing.T, snapshot ClusterSnapshot) {
+				require.NoError(t, snapshot.AddNode(BuildTestNode("new", 1000, 1000)))
+			},
+		},
+		{
+			name: "pod requests mutated in place",
+			modify: func(t *testing.T, snapshot ClusterSnapshot) {
+				nodeInfo, err := snapshot.NodeInfos().Get("node-1")
+				require.NoError(t, err)
+				nodeInfo.Pods[0].Pod.Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU] = resource.MustParse("500m")
+			},
+			expectErr: true,
+		},
+		{
+			name: "pod requests mutated in place along with a modification through the snapshot",
+			modify: func(t *testing.T, snapshot ClusterSnapshot) {
+				require.NoError(t, snapshot.AddPod(BuildTestPod("new", 100, 100), "node-0"))
+				nodeInfo, err := snapshot.NodeInfos().Get("node-1")
+				require.NoError(t, err)
+				nodeInfo.Pods[0].Pod.Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU] = resource.MustParse("500m")
+			},
+			expectErr: true,
+		},
+		{
+			name: "node labels mutated in place",
+			modify: func(t *testing.T, snapshot ClusterSnapshot) {
+				nodeInfo, err := snapshot.NodeInfos().Get("node-0")
+				require.NoError(t, err)
+				nodeInfo.Node().Labels["zone"] = "b"
+			},
+			expectErr: true,
+		},
+		{
+			name: "pod added to node info directly",
+			modify: func(t *testing.T, snapshot ClusterSnapshot) {
+				nodeInfo, err := snapshot.NodeInfos().Get("node-0")
+				require.NoError(t, err)
+				nodeInfo.AddPod(BuildTestPod("new", 100, 100))
+			},
+			expectErr: true,
+		},
+	}
+	for name, snapshotFactory := range snapshots {
+		for _, tc := range testCases {
+			t.Run(fmt.Sprintf("%s: %s", name, tc.name), func(t *testing.T) {
+				snapshot := snapshotFactory()
+				for i := 0; i < 2; i++ {
+					node := BuildTestNode(fmt.Sprintf("node-%d", i), 1000, 1000)
+					node.Labels = map[string]string{"zone": "a"}
+					pod := BuildTestPod(fmt.Sprintf("pod-%d", i), 100, 100)
+					require.NoError(t, snapshot.AddNodeWithPods(node, []*apiv1.Pod{pod}))
+				}
+
+				checkpoint, err := NewRevisionCheckpoint(snapshot)
+				require.NoError(t, err)
+				tc.modify(t, snapshot)
+				err = checkpoint.Verify(snapshot)
+				if tc.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		}
+	}
+}
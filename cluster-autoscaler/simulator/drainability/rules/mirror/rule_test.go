@@ -52,6 +52,18 @@ func TestDrainable(t *testing.T) {
 			},
 			want: drainability.NewSkipStatus(),
 		},
+		"mirror pod outside kube-system": {
+			pod: &apiv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "manifestPod-node1",
+					Namespace: "monitoring",
+					Annotations: map[string]string{
+						types.ConfigMirrorAnnotationKey: "something",
+					},
+				},
+			},
+			want: drainability.NewSkipStatus(),
+		},
 	} {
 		t.Run(desc, func(t *testing.T) {
 			got := New().Drainable(nil, tc.pod, nil)
@@ -31,6 +31,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/safetoevict"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/system"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/terminal"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules/vpaeviction"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
@@ -69,6 +70,7 @@ func Default(deleteOptions options.NodeDeleteOptions) Rules {
 		{rule: notsafetoevict.New()},
 		{rule: localstorage.New(), skip: !deleteOptions.SkipNodesWithLocalStorage},
 		{rule: pdbrule.New()},
+		{rule: vpaeviction.New(), skip: !deleteOptions.SkipNodesWithVpaEvictions},
 	} {
 		if !r.skip {
 			rules = append(rules, r.rule)
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpaeviction
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// EvictedAtAnnotationKey is set by the Vertical Pod Autoscaler updater on pods
+// right before evicting them.
+const EvictedAtAnnotationKey = "vpa-updater.k8s.io/evicted-at"
+
+// Rule is a drainability rule on how to handle pods whose controller has
+// other pods being evicted by the Vertical Pod Autoscaler updater.
+type Rule struct {
+	mutex sync.Mutex
+	// timestamp is the drain context timestamp evictingControllers were
+	// computed for.
+	timestamp time.Time
+	// evictingControllers maps UIDs of controllers with pods being evicted by
+	// the VPA updater to the UIDs of these pods.
+	evictingControllers map[types.UID][]types.UID
+}
+
+// New creates a new Rule.
+func New() *Rule {
+	return &Rule{}
+}
+
+// Name returns the name of the rule.
+func (r *Rule) Name() string {
+	return "VpaEviction"
+}
+
+// Drainable blocks draining pods whose controller has other pods being evicted
+// by the VPA updater, so the workload isn't disrupted twice at once.
+func (r *Rule) Drainable(drainCtx *drainability.DrainContext, pod *apiv1.Pod, _ *framework.NodeInfo) drainability.Status {
+	if drainCtx.Listers == nil {
+		return drainability.NewUndefinedStatus()
+	}
+	controllerRef := drain.ControllerRef(pod)
+	if controllerRef == nil {
+		return drainability.NewUndefinedStatus()
+	}
+	for _, uid := range r.evictedPods(drainCtx, controllerRef.UID) {
+		if uid != pod.UID {
+			return drainability.NewBlockedStatus(drain.VpaEvictionInProgress, fmt.Errorf("%s %s of pod %s/%s has pods being evicted by the VPA updater", controllerRef.Kind, controllerRef.Name, pod.Namespace, pod.Name))
+		}
+	}
+	return drainability.NewUndefinedStatus()
+}
+
+// evictedPods returns the UIDs of pods of the controller being evicted by the
+// VPA updater. Pods are listed once per drain context timestamp.
+func (r *Rule) evictedPods(drainCtx *drainability.DrainContext, controllerUID types.UID) []types.UID {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.evictingControllers == nil || !r.timestamp.Equal(drainCtx.Timestamp) {
+		pods, err := drainCtx.Listers.AllPodLister().List()
+		if err != nil {
+			klog.Errorf("Failed to list pods: %v", err)
+			return nil
+		}
+		r.timestamp = drainCtx.Timestamp
+		r.evictingControllers = make(map[types.UID][]types.UID)
+		for _, pod := range pods {
+			if !isBeingEvicted(pod) {
+				continue
+			}
+			if ref := drain.ControllerRef(pod); ref != nil {
+				r.evictingControllers[ref.UID] = append(r.evictingControllers[ref.UID], pod.UID)
+			}
+		}
+	}
+	return r.evictingControllers[controllerUID]
+}
+
+// isBeingEvicted checks if the pod was marked by the VPA updater and is
+// terminating. Marked pods which aren't terminating weren't evicted, e.g.
+// because of a PodDisruptionBudget.
+func isBeingEvicted(pod *apiv1.Pod) bool {
+	if pod.DeletionTimestamp == nil {
+		return false
+	}
+	_, found := pod.Annotations[EvictedAtAnnotationKey]
+	return found
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpaeviction
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	kube_util "k8s.io/autoscaler/cluster-autoscaler/utils/kubernetes"
+)
+
+func TestDrainable(t *testing.T) {
+	testTime := time.Date(2020, time.December, 18, 17, 0, 0, 0, time.UTC)
+	newPod := func(name, owner string, evicted, terminating bool) *apiv1.Pod {
+		pod := &apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				UID:       types.UID(name),
+			},
+		}
+		if owner != "" {
+			isController := true
+			pod.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: "apps/v1",
+				Kind:       "ReplicaSet",
+				Name:       owner,
+				UID:        types.UID(owner),
+				Controller: &isController,
+			}}
+		}
+		if evicted {
+			pod.Annotations = map[string]string{EvictedAtAnnotationKey: testTime.Format(time.RFC3339)}
+		}
+		if terminating {
+			pod.DeletionTimestamp = &metav1.Time{Time: testTime}
+		}
+		return pod
+	}
+
+	for desc, tc := range map[string]struct {
+		pod       *apiv1.Pod
+		otherPods []*apiv1.Pod
+		noListers bool
+		want      drainability.Status
+	}{
+		"pod without controller": {
+			pod:       newPod("pod", "", false, false),
+			otherPods: []*apiv1.Pod{newPod("evicted", "rs", true, true)},
+			want:      drainability.NewUndefinedStatus(),
+		},
+		"no pods of controller evicted": {
+			pod:       newPod("pod", "rs", false, false),
+			otherPods: []*apiv1.Pod{newPod("other", "rs", false, false)},
+			want:      drainability.NewUndefinedStatus(),
+		},
+		"pod of controller evicted": {
+			pod:       newPod("pod", "rs", false, false),
+			otherPods: []*apiv1.Pod{newPod("evicted", "rs", true, true)},
+			want:      drainability.NewBlockedStatus(drain.VpaEvictionInProgress, fmt.Errorf("ReplicaSet rs of pod default/pod has pods being evicted by the VPA updater")),
+		},
+		"pod of another controller evicted": {
+			pod:       newPod("pod", "rs", false, false),
+			otherPods: []*apiv1.Pod{newPod("evicted", "rs2", true, true)},
+			want:      drainability.NewUndefinedStatus(),
+		},
+		"pod of controller marked, but not terminating": {
+			pod:       newPod("pod", "rs", false, false),
+			otherPods: []*apiv1.Pod{newPod("evicted", "rs", true, false)},
+			want:      drainability.NewUndefinedStatus(),
+		},
+		"pod of controller terminating, but not marked": {
+			pod:       newPod("pod", "rs", false, false),
+			otherPods: []*apiv1.Pod{newPod("deleted", "rs", false, true)},
+			want:      drainability.NewUndefinedStatus(),
+		},
+		"pod evicted itself": {
+			pod:  newPod("pod", "rs", true, true),
+			want: drainability.NewUndefinedStatus(),
+		},
+		"no listers": {
+			pod:       newPod("pod", "rs", false, false),
+			otherPods: []*apiv1.Pod{newPod("evicted", "rs", true, true)},
+			noListers: true,
+			want:      drainability.NewUndefinedStatus(),
+		},
+	} {
+		t.Run(desc, func(t *testing.T) {
+			drainCtx := &drainability.DrainContext{
+				Timestamp: testTime,
+			}
+			if !tc.noListers {
+				pods := append([]*apiv1.Pod{tc.pod}, tc.otherPods...)
+				drainCtx.Listers = kube_util.NewListerRegistry(nil, nil, kube_util.NewTestPodLister(pods), nil, nil, nil, nil, nil, nil)
+			}
+			got := New().Drainable(drainCtx, tc.pod, nil)
+			if diff := cmp.Diff(tc.want, got, cmp.Comparer(equalErrors)); diff != "" {
+				t.Errorf("Rule.Drainable(%v): got status diff (-want +got):\n%s", tc.pod.Name, diff)
+			}
+		})
+	}
+}
+
+func equalErrors(x, y error) bool {
+	if x == nil || y == nil {
+		return x == y
+	}
+	return x.Error() == y.Error()
+}
+
+func TestDrainableListsPodsOncePerTimestamp(t *testing.T) {
+	testTime := time.Date(2020, time.December, 18, 17, 0, 0, 0, time.UTC)
+	isController := true
+	ownerRefs := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "rs", Controller: &isController}}
+	pod := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod", OwnerReferences: ownerRefs}}
+	evicted := &apiv1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              "evicted",
+		Namespace:         "default",
+		UID:               "evicted",
+		OwnerReferences:   ownerRefs,
+		Annotations:       map[string]string{EvictedAtAnnotationKey: testTime.Format(time.RFC3339)},
+		DeletionTimestamp: &metav1.Time{Time: testTime},
+	}}
+
+	rule := New()
+	drainCtx := &drainability.DrainContext{
+		Listers:   kube_util.NewListerRegistry(nil, nil, kube_util.NewTestPodLister([]*apiv1.Pod{pod}), nil, nil, nil, nil, nil, nil),
+		Timestamp: testTime,
+	}
+	if got := rule.Drainable(drainCtx, pod, nil); got.Outcome != drainability.UndefinedOutcome {
+		t.Errorf("Rule.Drainable(): got outcome %v, want %v", got.Outcome, drainability.UndefinedOutcome)
+	}
+
+	// Pods are not listed again for the same timestamp.
+	drainCtx.Listers = kube_util.NewListerRegistry(nil, nil, kube_util.NewTestPodLister([]*apiv1.Pod{pod, evicted}), nil, nil, nil, nil, nil, nil)
+	if got := rule.Drainable(drainCtx, pod, nil); got.Outcome != drainability.UndefinedOutcome {
+		t.Errorf("Rule.Drainable(): got outcome %v, want %v", got.Outcome, drainability.UndefinedOutcome)
+	}
+
+	drainCtx.Timestamp = testTime.Add(time.Minute)
+	if got := rule.Drainable(drainCtx, pod, nil); got.Outcome != drainability.BlockDrain {
+		t.Errorf("Rule.Drainable(): got outcome %v, want %v", got.Outcome, drainability.BlockDrain)
+	}
+}
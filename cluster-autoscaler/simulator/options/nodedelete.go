@@ -35,6 +35,9 @@ type NodeDeleteOptions struct {
 	// set or replication controller should have to allow pod deletion during
 	// scale down.
 	MinReplicaCount int
+	// SkipNodesWithVpaEvictions is true if nodes with pods whose controller
+	// has other pods being evicted by the VPA updater should not be deleted.
+	SkipNodesWithVpaEvictions bool
 }
 
 // NewNodeDeleteOptions returns new node delete options extracted from autoscaling options.
@@ -44,5 +47,6 @@ func NewNodeDeleteOptions(opts config.AutoscalingOptions) NodeDeleteOptions {
 		SkipNodesWithLocalStorage:         opts.SkipNodesWithLocalStorage,
 		SkipNodesWithCustomControllerPods: opts.SkipNodesWithCustomControllerPods,
 		MinReplicaCount:                   opts.MinReplicaCount,
+		SkipNodesWithVpaEvictions:         opts.SkipNodesWithVpaEvictions,
 	}
 }
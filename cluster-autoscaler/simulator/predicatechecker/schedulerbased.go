@@ -19,6 +19,7 @@ package predicatechecker
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 
@@ -191,6 +192,18 @@ func (p *SchedulerBasedPredicateChecker) buildDebugInfo(filterName string, nodeI
 		return func() string {
 			return fmt.Sprintf("taints on node: %#v", taints)
 		}
+	case "VolumeBinding":
+		// Unbound WaitForFirstConsumer claims are only provisionable on nodes matching
+		// the StorageClass allowedTopologies, so surface the node's topology labels.
+		topologyLabels := map[string]string{}
+		for key, value := range nodeInfo.Node().Labels {
+			if strings.Contains(key, "topology") {
+				topologyLabels[key] = value
+			}
+		}
+		return func() string {
+			return fmt.Sprintf("topology labels on node: %v", topologyLabels)
+		}
 	default:
 		return emptyString
 	}
@@ -30,6 +30,10 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	apiv1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	clientsetfake "k8s.io/client-go/kubernetes/fake"
 )
 
 func TestCheckPredicate(t *testing.T) {
@@ -316,3 +320,76 @@ func TestDebugInfo(t *testing.T) {
 	predicateErr = customPredicateChecker.CheckPredicates(clusterSnapshot, p1, "n1")
 	assert.Nil(t, predicateErr)
 }
+
+func TestCheckPredicateWaitForFirstConsumerVolume(t *testing.T) {
+	bindingMode := storagev1.VolumeBindingWaitForFirstConsumer
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: "zonal"},
+		Provisioner:       "disk.csi.example.com",
+		VolumeBindingMode: &bindingMode,
+		AllowedTopologies: []apiv1.TopologySelectorTerm{
+			{
+				MatchLabelExpressions: []apiv1.TopologySelectorLabelRequirement{
+					{Key: apiv1.LabelTopologyZone, Values: []string{"zone-a"}},
+				},
+			},
+		},
+	}
+	pvc := &apiv1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "default"},
+		Spec:       apiv1.PersistentVolumeClaimSpec{StorageClassName: &storageClass.Name},
+	}
+	pod := BuildTestPod("p1", 100, 1000)
+	pod.Spec.Volumes = []apiv1.Volume{
+		{
+			Name: "data",
+			VolumeSource: apiv1.VolumeSource{
+				PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+			},
+		},
+	}
+
+	informerFactory := informers.NewSharedInformerFactory(clientsetfake.NewSimpleClientset(storageClass, pvc), 0)
+	predicateChecker, err := NewSchedulerBasedPredicateChecker(informerFactory, nil)
+	assert.NoError(t, err)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	informerFactory.Start(stopCh)
+	informerFactory.WaitForCacheSync(stopCh)
+
+	tests := []struct {
+		name        string
+		zone        string
+		expectError bool
+	}{
+		{
+			name:        "node in allowed zone",
+			zone:        "zone-a",
+			expectError: false,
+		},
+		{
+			name:        "node outside allowed zones",
+			zone:        "zone-b",
+			expectError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := BuildTestNode("n1", 1000, 2000000)
+			node.Labels[apiv1.LabelTopologyZone] = tt.zone
+			SetNodeReadyState(node, true, time.Time{})
+			clusterSnapshot := clustersnapshot.NewBasicClusterSnapshot()
+			assert.NoError(t, clusterSnapshot.AddNode(node))
+
+			predicateError := predicateChecker.CheckPredicates(clusterSnapshot, pod, node.Name)
+			if tt.expectError {
+				assert.NotNil(t, predicateError)
+				assert.Equal(t, NotSchedulablePredicateError, predicateError.ErrorType())
+				assert.Contains(t, predicateError.VerboseMessage(), "predicateName=VolumeBinding")
+				assert.Contains(t, predicateError.VerboseMessage(), tt.zone)
+			} else {
+				assert.Nil(t, predicateError)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	nodev1lister "k8s.io/client-go/listers/node/v1"
+	klog "k8s.io/klog/v2"
+)
+
+// RuntimeHandlersLabel is the label of template nodes listing the RuntimeClass handlers
+// configured on nodes of their node group, separated by dots. Template nodes don't
+// carry the labels set on nodes once a handler is installed, e.g. by a DaemonSet,
+// so they're added for the listed handlers based on RuntimeClass scheduling.
+const RuntimeHandlersLabel = "cluster-autoscaler.kubernetes.io/runtime-handlers"
+
+// Provider simulates the effects of RuntimeClasses on pods and nodes.
+type Provider struct {
+	lister nodev1lister.RuntimeClassLister
+}
+
+// NewProvider returns a Provider using the given RuntimeClass lister.
+func NewProvider(lister nodev1lister.RuntimeClassLister) *Provider {
+	return &Provider{lister: lister}
+}
+
+// PodWithOverhead returns the pod with the overhead of its RuntimeClass set, the same
+// way the RuntimeClass admission controller sets it for pods created in the cluster.
+// Pods created from templates, e.g. DaemonSet pods of template nodes, don't go through
+// admission and would be simulated without overhead otherwise. The pod is copied if
+// the overhead is set.
+func (p *Provider) PodWithOverhead(pod *apiv1.Pod) *apiv1.Pod {
+	if pod.Spec.RuntimeClassName == nil || pod.Spec.Overhead != nil {
+		return pod
+	}
+	runtimeClass, err := p.lister.Get(*pod.Spec.RuntimeClassName)
+	if err != nil {
+		klog.V(4).Infof("Failed to get RuntimeClass %s of pod %s/%s: %v", *pod.Spec.RuntimeClassName, pod.Namespace, pod.Name, err)
+		return pod
+	}
+	if runtimeClass.Overhead == nil || len(runtimeClass.Overhead.PodFixed) == 0 {
+		return pod
+	}
+	podCopy := pod.DeepCopy()
+	podCopy.Spec.Overhead = runtimeClass.Overhead.PodFixed.DeepCopy()
+	return podCopy
+}
+
+// NodeLabels returns the labels required by the scheduling of RuntimeClasses
+// using any of the handlers listed in the RuntimeHandlersLabel of the node.
+func (p *Provider) NodeLabels(node *apiv1.Node) map[string]string {
+	value, found := node.Labels[RuntimeHandlersLabel]
+	if !found || value == "" {
+		return nil
+	}
+	handlers := make(map[string]bool)
+	for _, handler := range strings.Split(value, ".") {
+		handlers[handler] = true
+	}
+	runtimeClasses, err := p.lister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list RuntimeClasses: %v", err)
+		return nil
+	}
+	result := make(map[string]string)
+	for _, runtimeClass := range runtimeClasses {
+		if !handlers[runtimeClass.Handler] || runtimeClass.Scheduling == nil {
+			continue
+		}
+		for key, value := range runtimeClass.Scheduling.NodeSelector {
+			result[key] = value
+		}
+	}
+	return result
+}
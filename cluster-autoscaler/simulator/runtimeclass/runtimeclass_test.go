@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtimeclass
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+	nodev1lister "k8s.io/client-go/listers/node/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var overhead = apiv1.ResourceList{
+	apiv1.ResourceCPU:    resource.MustParse("250m"),
+	apiv1.ResourceMemory: resource.MustParse("160Mi"),
+}
+
+func newTestProvider(t *testing.T) *Provider {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, runtimeClass := range []*nodev1.RuntimeClass{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "kata"},
+			Handler:    "kata-qemu",
+			Overhead:   &nodev1.Overhead{PodFixed: overhead},
+			Scheduling: &nodev1.Scheduling{NodeSelector: map[string]string{"katacontainers.io/kata-runtime": "true"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gvisor"},
+			Handler:    "runsc",
+			Scheduling: &nodev1.Scheduling{NodeSelector: map[string]string{"sandbox.gke.io/runtime": "gvisor"}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "runc"},
+			Handler:    "runc",
+		},
+	} {
+		require.NoError(t, indexer.Add(runtimeClass))
+	}
+	return NewProvider(nodev1lister.NewRuntimeClassLister(indexer))
+}
+
+func TestPodWithOverhead(t *testing.T) {
+	provider := newTestProvider(t)
+	withRuntimeClass := func(name string) *apiv1.Pod {
+		pod := BuildTestPod("p", 100, 1000)
+		pod.Spec.RuntimeClassName = &name
+		return pod
+	}
+	withOverhead := func(pod *apiv1.Pod, overhead apiv1.ResourceList) *apiv1.Pod {
+		pod = pod.DeepCopy()
+		pod.Spec.Overhead = overhead
+		return pod
+	}
+	ownOverhead := apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("1")}
+
+	testCases := []struct {
+		name string
+		pod  *apiv1.Pod
+		want *apiv1.Pod
+	}{
+		{
+			name: "no RuntimeClass",
+			pod:  BuildTestPod("p", 100, 1000),
+			want: BuildTestPod("p", 100, 1000),
+		},
+		{
+			name: "RuntimeClass with overhead",
+			pod:  withRuntimeClass("kata"),
+			want: withOverhead(withRuntimeClass("kata"), overhead),
+		},
+		{
+			name: "RuntimeClass without overhead",
+			pod:  withRuntimeClass("gvisor"),
+			want: withRuntimeClass("gvisor"),
+		},
+		{
+			name: "unknown RuntimeClass",
+			pod:  withRuntimeClass("unknown"),
+			want: withRuntimeClass("unknown"),
+		},
+		{
+			name: "overhead already set",
+			pod:  withOverhead(withRuntimeClass("kata"), ownOverhead),
+			want: withOverhead(withRuntimeClass("kata"), ownOverhead),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := tc.pod.DeepCopy()
+			assert.Equal(t, tc.want, provider.PodWithOverhead(tc.pod))
+			assert.Equal(t, original, tc.pod)
+		})
+	}
+}
+
+func TestNodeLabels(t *testing.T) {
+	provider := newTestProvider(t)
+
+	testCases := []struct {
+		name     string
+		handlers string
+		want     map[string]string
+	}{
+		{
+			name: "no handlers label",
+		},
+		{
+			name:     "single handler",
+			handlers: "kata-qemu",
+			want:     map[string]string{"katacontainers.io/kata-runtime": "true"},
+		},
+		{
+			name:     "multiple handlers",
+			handlers: "kata-qemu.runsc.runc",
+			want:     map[string]string{"katacontainers.io/kata-runtime": "true", "sandbox.gke.io/runtime": "gvisor"},
+		},
+		{
+			name:     "unknown handler",
+			handlers: "crun",
+			want:     map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := BuildTestNode("n", 1000, 1000)
+			if tc.handlers != "" {
+				node.Labels[RuntimeHandlersLabel] = tc.handlers
+			}
+			assert.Equal(t, tc.want, provider.NodeLabels(node))
+		})
+	}
+}
@@ -99,6 +99,19 @@ func TestCalculate(t *testing.T) {
 	assert.InEpsilon(t, 50.25, utilInfo.Utilization, 0.01)
 	assert.Equal(t, 25.125, utilInfo.CpuUtil)
 
+	podWithOverhead := BuildTestPod("p-overhead", 100, 200000)
+	podWithOverhead.Spec.Overhead = apiv1.ResourceList{
+		apiv1.ResourceCPU:    *resource.NewMilliQuantity(100, resource.DecimalSI),
+		apiv1.ResourceMemory: *resource.NewQuantity(200000, resource.DecimalSI),
+	}
+	nodeInfo = newNodeInfo(node, pod, podWithOverhead)
+
+	gpuConfig = GetGpuConfigFromNode(nodeInfo.Node())
+	utilInfo, err = Calculate(nodeInfo, false, false, gpuConfig, testTime)
+	assert.NoError(t, err)
+	assert.InEpsilon(t, 3.0/10, utilInfo.Utilization, 0.01)
+	assert.Equal(t, 0.15, utilInfo.CpuUtil)
+
 	daemonSetPod3 := BuildTestPod("p3", 100, 200000)
 	daemonSetPod3.OwnerReferences = GenerateOwnerReferences("ds", "DaemonSet", "apps/v1", "")
 
@@ -40,3 +40,26 @@ type Backoff interface {
 	// RemoveStaleBackoffData removes stale backoff data.
 	RemoveStaleBackoffData(currentTime time.Time)
 }
+
+// NodeGroupBackoffState is the backoff state of a node group, which can be
+// persisted across restarts.
+type NodeGroupBackoffState struct {
+	// Duration is the duration of the last backoff.
+	Duration time.Duration
+	// BackoffUntil is the time until which the node group is backed off.
+	BackoffUntil time.Time
+	// LastFailedExecution is the time of the last failed scale-up.
+	LastFailedExecution time.Time
+	// ErrorInfo is the error which caused the last backoff.
+	ErrorInfo cloudprovider.InstanceErrorInfo
+}
+
+// PersistentBackoff is a Backoff whose state can be saved and restored, so
+// that restarts don't reset backoff of node groups.
+type PersistentBackoff interface {
+	Backoff
+	// State returns the backoff state of node groups, by backoff key.
+	State() map[string]NodeGroupBackoffState
+	// Restore replaces the backoff state of node groups with the given one.
+	Restore(state map[string]NodeGroupBackoffState)
+}
@@ -45,7 +45,7 @@ func NewExponentialBackoff(
 	initialBackoffDuration time.Duration,
 	maxBackoffDuration time.Duration,
 	backoffResetTimeout time.Duration,
-	nodeGroupKey func(nodeGroup cloudprovider.NodeGroup) string) Backoff {
+	nodeGroupKey func(nodeGroup cloudprovider.NodeGroup) string) PersistentBackoff {
 	return &exponentialBackoff{
 		maxBackoffDuration:     maxBackoffDuration,
 		initialBackoffDuration: initialBackoffDuration,
@@ -56,7 +56,7 @@ func NewExponentialBackoff(
 }
 
 // NewIdBasedExponentialBackoff creates an instance of exponential backoff with node group Id used as a key.
-func NewIdBasedExponentialBackoff(initialBackoffDuration time.Duration, maxBackoffDuration time.Duration, backoffResetTimeout time.Duration) Backoff {
+func NewIdBasedExponentialBackoff(initialBackoffDuration time.Duration, maxBackoffDuration time.Duration, backoffResetTimeout time.Duration) PersistentBackoff {
 	return NewExponentialBackoff(
 		initialBackoffDuration,
 		maxBackoffDuration,
@@ -118,3 +118,40 @@ func (b *exponentialBackoff) RemoveStaleBackoffData(currentTime time.Time) {
 		}
 	}
 }
+
+// State returns the backoff state of node groups, by node group key.
+func (b *exponentialBackoff) State() map[string]NodeGroupBackoffState {
+	state := make(map[string]NodeGroupBackoffState, len(b.backoffInfo))
+	for key, backoffInfo := range b.backoffInfo {
+		state[key] = NodeGroupBackoffState{
+			Duration:            backoffInfo.duration,
+			BackoffUntil:        backoffInfo.backoffUntil,
+			LastFailedExecution: backoffInfo.lastFailedExecution,
+			ErrorInfo:           backoffInfo.errorInfo,
+		}
+	}
+	return state
+}
+
+// Restore replaces the backoff state of node groups with the given one.
+// Durations are capped to the maximum backoff duration, which could have been
+// lowered since the state was saved.
+func (b *exponentialBackoff) Restore(state map[string]NodeGroupBackoffState) {
+	b.backoffInfo = make(map[string]exponentialBackoffInfo, len(state))
+	for key, nodeGroupState := range state {
+		duration := nodeGroupState.Duration
+		if duration > b.maxBackoffDuration {
+			duration = b.maxBackoffDuration
+		}
+		backoffUntil := nodeGroupState.BackoffUntil
+		if maxBackoffUntil := nodeGroupState.LastFailedExecution.Add(duration); backoffUntil.After(maxBackoffUntil) {
+			backoffUntil = maxBackoffUntil
+		}
+		b.backoffInfo[key] = exponentialBackoffInfo{
+			duration:            duration,
+			backoffUntil:        backoffUntil,
+			lastFailedExecution: nodeGroupState.LastFailedExecution,
+			errorInfo:           nodeGroupState.ErrorInfo,
+		}
+	}
+}
@@ -116,3 +116,32 @@ func TestIncreaseExistingBackoff(t *testing.T) {
 	assert.Equal(t, noBackOff, backoff.BackoffStatus(nodeGroup1, nil, currentTime))
 	// Result: existing backoff duration was scaled up beyond initial duration
 }
+
+func TestStateAndRestore(t *testing.T) {
+	backoff := NewIdBasedExponentialBackoff(10*time.Minute, time.Hour, 3*time.Hour)
+	startTime := time.Date(2023, 12, 12, 12, 0, 0, 0, time.UTC)
+	backoff.Backoff(nodeGroup1, nil, quotaError, startTime)
+	backoff.Backoff(nodeGroup1, nil, quotaError, startTime.Add(11*time.Minute))
+	state := backoff.State()
+	assert.Equal(t, map[string]NodeGroupBackoffState{
+		"id1": {
+			Duration:            20 * time.Minute,
+			BackoffUntil:        startTime.Add(31 * time.Minute),
+			LastFailedExecution: startTime.Add(11 * time.Minute),
+			ErrorInfo:           quotaError,
+		},
+	}, state)
+
+	// Backoff restored after a restart keeps increasing exponentially.
+	restored := NewIdBasedExponentialBackoff(10*time.Minute, time.Hour, 3*time.Hour)
+	restored.Restore(state)
+	assert.Equal(t, backoffWithQuotaError, restored.BackoffStatus(nodeGroup1, nil, startTime.Add(30*time.Minute)))
+	assert.Equal(t, noBackOff, restored.BackoffStatus(nodeGroup2, nil, startTime.Add(30*time.Minute)))
+	assert.Equal(t, startTime.Add(72*time.Minute), restored.Backoff(nodeGroup1, nil, quotaError, startTime.Add(32*time.Minute)))
+
+	// Restored durations are capped to the maximum backoff duration.
+	capped := NewIdBasedExponentialBackoff(10*time.Minute, 15*time.Minute, 3*time.Hour)
+	capped.Restore(state)
+	assert.Equal(t, 15*time.Minute, capped.State()["id1"].Duration)
+	assert.Equal(t, noBackOff, capped.BackoffStatus(nodeGroup1, nil, startTime.Add(27*time.Minute)))
+}
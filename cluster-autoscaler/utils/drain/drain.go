@@ -67,6 +67,8 @@ const (
 	NotEnoughPdb
 	// UnexpectedError - pod is blocking scale down because of an unexpected error.
 	UnexpectedError
+	// VpaEvictionInProgress - pod is blocking scale down because other pods of its controller are being evicted by the VPA updater.
+	VpaEvictionInProgress
 )
 
 func (e BlockingPodReason) String() string {
@@ -89,6 +91,8 @@ func (e BlockingPodReason) String() string {
 		return "NotEnoughPdb"
 	case UnexpectedError:
 		return "UnexpectedError"
+	case VpaEvictionInProgress:
+		return "VpaEvictionInProgress"
 	default:
 		return fmt.Sprintf("unrecognized reason: %d", int(e))
 	}
@@ -21,6 +21,7 @@ import (
 
 	apiv1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
@@ -44,6 +45,9 @@ type ListerRegistry interface {
 	JobLister() v1batchlister.JobLister
 	ReplicaSetLister() v1appslister.ReplicaSetLister
 	StatefulSetLister() v1appslister.StatefulSetLister
+	// OutOfScopeNodeLister lists nodes not matching the node selector of the
+	// registry. It's nil if all nodes match.
+	OutOfScopeNodeLister() NodeLister
 }
 
 type listerRegistryImpl struct {
@@ -56,6 +60,7 @@ type listerRegistryImpl struct {
 	jobLister                   v1batchlister.JobLister
 	replicaSetLister            v1appslister.ReplicaSetLister
 	statefulSetLister           v1appslister.StatefulSetLister
+	outOfScopeNodeLister        NodeLister
 }
 
 // NewListerRegistry returns a registry providing various listers to list pods or nodes matching conditions
@@ -76,11 +81,19 @@ func NewListerRegistry(allNode NodeLister, readyNode NodeLister, allPodLister Po
 	}
 }
 
-// NewListerRegistryWithDefaultListers returns a registry filled with listers of the default implementations
-func NewListerRegistryWithDefaultListers(informerFactory informers.SharedInformerFactory) ListerRegistry {
+// NewListerRegistryWithDefaultListers returns a registry filled with listers of the default implementations.
+// Node listers only return nodes matching the node selector, nil selects all nodes.
+func NewListerRegistryWithDefaultListers(informerFactory informers.SharedInformerFactory, nodeSelector labels.Selector) ListerRegistry {
 	allPodLister := NewAllPodLister(informerFactory.Core().V1().Pods().Lister())
-	readyNodeLister := NewReadyNodeLister(informerFactory.Core().V1().Nodes().Lister())
-	allNodeLister := NewAllNodeLister(informerFactory.Core().V1().Nodes().Lister())
+	nodeLister := informerFactory.Core().V1().Nodes().Lister()
+	readyNodeLister := NewSelectedNodeLister(nodeLister, nodeSelector, IsNodeReadyAndSchedulable)
+	allNodeLister := NewSelectedNodeLister(nodeLister, nodeSelector, nil)
+	var outOfScopeNodeLister NodeLister
+	if nodeSelector != nil && !nodeSelector.Empty() {
+		outOfScopeNodeLister = NewNodeLister(nodeLister, func(node *apiv1.Node) bool {
+			return !nodeSelector.Matches(labels.Set(node.Labels))
+		})
+	}
 
 	podDisruptionBudgetLister := NewPodDisruptionBudgetLister(informerFactory.Policy().V1().PodDisruptionBudgets().Lister())
 	daemonSetLister := informerFactory.Apps().V1().DaemonSets().Lister()
@@ -88,9 +101,18 @@ func NewListerRegistryWithDefaultListers(informerFactory informers.SharedInforme
 	jobLister := informerFactory.Batch().V1().Jobs().Lister()
 	replicaSetLister := informerFactory.Apps().V1().ReplicaSets().Lister()
 	statefulSetLister := informerFactory.Apps().V1().StatefulSets().Lister()
-	return NewListerRegistry(allNodeLister, readyNodeLister, allPodLister,
-		podDisruptionBudgetLister, daemonSetLister, replicationControllerLister,
-		jobLister, replicaSetLister, statefulSetLister)
+	return listerRegistryImpl{
+		allNodeLister:               allNodeLister,
+		readyNodeLister:             readyNodeLister,
+		allPodLister:                allPodLister,
+		podDisruptionBudgetLister:   podDisruptionBudgetLister,
+		daemonSetLister:             daemonSetLister,
+		replicationControllerLister: replicationControllerLister,
+		jobLister:                   jobLister,
+		replicaSetLister:            replicaSetLister,
+		statefulSetLister:           statefulSetLister,
+		outOfScopeNodeLister:        outOfScopeNodeLister,
+	}
 }
 
 // AllPodLister returns the AllPodLister registered to this registry
@@ -133,6 +155,11 @@ func (r listerRegistryImpl) ReplicaSetLister() v1appslister.ReplicaSetLister {
 	return r.replicaSetLister
 }
 
+// OutOfScopeNodeLister returns the outOfScopeNodeLister registered to this registry
+func (r listerRegistryImpl) OutOfScopeNodeLister() NodeLister {
+	return r.outOfScopeNodeLister
+}
+
 // StatefulSetLister returns the statefulSetLister registered to this registry
 func (r listerRegistryImpl) StatefulSetLister() v1appslister.StatefulSetLister {
 	return r.statefulSetLister
@@ -253,6 +280,7 @@ type NodeLister interface {
 // nodeLister implementation.
 type nodeListerImpl struct {
 	nodeLister v1lister.NodeLister
+	selector   labels.Selector
 	filter     func(*apiv1.Node) bool
 }
 
@@ -268,8 +296,18 @@ func NewReadyNodeLister(nl v1lister.NodeLister) NodeLister {
 
 // NewNodeLister builds a node lister.
 func NewNodeLister(nl v1lister.NodeLister, filter func(*apiv1.Node) bool) NodeLister {
+	return NewSelectedNodeLister(nl, nil, filter)
+}
+
+// NewSelectedNodeLister builds a node lister that only lists and gets nodes
+// matching the selector. Nil selector selects all nodes.
+func NewSelectedNodeLister(nl v1lister.NodeLister, selector labels.Selector, filter func(*apiv1.Node) bool) NodeLister {
+	if selector == nil {
+		selector = labels.Everything()
+	}
 	return &nodeListerImpl{
 		nodeLister: nl,
+		selector:   selector,
 		filter:     filter,
 	}
 }
@@ -279,7 +317,7 @@ func (l *nodeListerImpl) List() ([]*apiv1.Node, error) {
 	var nodes []*apiv1.Node
 	var err error
 
-	nodes, err = l.nodeLister.List(labels.Everything())
+	nodes, err = l.nodeLister.List(l.selector)
 	if err != nil {
 		return []*apiv1.Node{}, err
 	}
@@ -297,6 +335,9 @@ func (l *nodeListerImpl) Get(name string) (*apiv1.Node, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !l.selector.Matches(labels.Set(node.Labels)) {
+		return nil, kube_errors.NewNotFound(apiv1.Resource("nodes"), name)
+	}
 	return node, nil
 }
 
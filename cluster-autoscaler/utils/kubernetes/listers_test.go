@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kube_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestListerRegistryNodeSelector(t *testing.T) {
+	managed := BuildTestNode("managed", 1000, 1000)
+	managed.Labels["autoscaler"] = "cluster-autoscaler"
+	other := BuildTestNode("other", 1000, 1000)
+	other.Labels["autoscaler"] = "other"
+
+	informerFactory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	indexer := informerFactory.Core().V1().Nodes().Informer().GetIndexer()
+	require.NoError(t, indexer.Add(managed))
+	require.NoError(t, indexer.Add(other))
+
+	selector, err := labels.Parse("autoscaler=cluster-autoscaler")
+	require.NoError(t, err)
+	registry := NewListerRegistryWithDefaultListers(informerFactory, selector)
+
+	nodes, err := registry.AllNodeLister().List()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, nodes, []interface{}{managed})
+	_, err = registry.AllNodeLister().Get("other")
+	assert.True(t, kube_errors.IsNotFound(err))
+	node, err := registry.AllNodeLister().Get("managed")
+	require.NoError(t, err)
+	assert.Equal(t, managed, node)
+
+	nodes, err = registry.OutOfScopeNodeLister().List()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, nodes, []interface{}{other})
+
+	registry = NewListerRegistryWithDefaultListers(informerFactory, nil)
+	nodes, err = registry.AllNodeLister().List()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, nodes, []interface{}{managed, other})
+	assert.Nil(t, registry.OutOfScopeNodeLister())
+}
@@ -26,6 +26,8 @@ import (
 const (
 	// DaemonSetPodAnnotationKey - annotation use to informs the cluster-autoscaler controller when a pod needs to be considered as a Daemonset's Pod.
 	DaemonSetPodAnnotationKey = "cluster-autoscaler.kubernetes.io/daemonset-pod"
+	// VirtualPodAnnotationKey - annotation of pods which don't exist in the cluster, but are injected by cluster-autoscaler to keep capacity for them.
+	VirtualPodAnnotationKey = "cluster-autoscaler.kubernetes.io/virtual-pod"
 )
 
 // IsDaemonSetPod returns true if the Pod should be considered as Pod managed by a DaemonSet
@@ -38,6 +40,11 @@ func IsDaemonSetPod(pod *apiv1.Pod) bool {
 	return pod.Annotations[DaemonSetPodAnnotationKey] == "true"
 }
 
+// IsVirtualPod returns true if the pod was injected by cluster-autoscaler and doesn't exist in the cluster.
+func IsVirtualPod(pod *apiv1.Pod) bool {
+	return pod.Annotations[VirtualPodAnnotationKey] == "true"
+}
+
 // IsMirrorPod checks whether the pod is a mirror pod.
 func IsMirrorPod(pod *apiv1.Pod) bool {
 	if pod.ObjectMeta.Annotations == nil {
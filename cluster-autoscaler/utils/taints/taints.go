@@ -40,6 +40,10 @@ const (
 	ToBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
 	// DeletionCandidateTaint is a taint used to mark unneeded node as preferably unschedulable.
 	DeletionCandidateTaint = "DeletionCandidateOfClusterAutoscaler"
+	// OrphanedNodeTaint is a taint used to cordon nodes whose node group was removed from discovery.
+	OrphanedNodeTaint = "OrphanedFromNodeGroupByClusterAutoscaler"
+	// MaintenanceScheduledTaint is a taint used to cordon nodes with upcoming cloud provider maintenance.
+	MaintenanceScheduledTaint = "MaintenanceScheduledByClusterAutoscaler"
 
 	// IgnoreTaintPrefix any taint starting with it will be filtered out from autoscaler template node.
 	IgnoreTaintPrefix = "ignore-taint.cluster-autoscaler.kubernetes.io/"
@@ -113,8 +117,10 @@ func NewTaintConfig(opts config.AutoscalingOptions) TaintConfig {
 	}
 
 	explicitlyReportedTaints := TaintKeySet{
-		ToBeDeletedTaint:       true,
-		DeletionCandidateTaint: true,
+		ToBeDeletedTaint:          true,
+		DeletionCandidateTaint:    true,
+		OrphanedNodeTaint:         true,
+		MaintenanceScheduledTaint: true,
 	}
 
 	for k, v := range NodeConditionTaints {
@@ -385,6 +391,30 @@ func CleanAllTaints(nodes []*apiv1.Node, client kube_client.Interface, recorder
 	}
 }
 
+// CleanAllTaintsWithPrefixes cleans taints with keys starting with any of the
+// given prefixes from given nodes. In dry run mode, the taints are only logged.
+func CleanAllTaintsWithPrefixes(nodes []*apiv1.Node, client kube_client.Interface, recorder kube_record.EventRecorder, prefixes []string, dryRun bool) {
+	if len(prefixes) == 0 {
+		return
+	}
+	for _, node := range nodes {
+		var taintKeys []string
+		for _, taint := range node.Spec.Taints {
+			if matchesAnyPrefix(prefixes, taint.Key) {
+				taintKeys = append(taintKeys, taint.Key)
+			}
+		}
+		if len(taintKeys) == 0 {
+			continue
+		}
+		if dryRun {
+			klog.Infof("Dry run: would remove %v taints from node %v", strings.Join(taintKeys, ","), node.Name)
+			continue
+		}
+		CleanAllTaints([]*apiv1.Node{node}, client, recorder, taintKeys, false)
+	}
+}
+
 func matchesAnyPrefix(prefixes []string, key string) bool {
 	for _, prefix := range prefixes {
 		if strings.HasPrefix(key, prefix) {
@@ -405,6 +435,12 @@ func SanitizeTaints(taints []apiv1.Taint, taintConfig TaintConfig) []apiv1.Taint
 		case DeletionCandidateTaint:
 			klog.V(4).Infof("Removing autoscaler soft taint when creating template from node")
 			continue
+		case OrphanedNodeTaint:
+			klog.V(4).Infof("Removing autoscaler orphaned node taint when creating template from node")
+			continue
+		case MaintenanceScheduledTaint:
+			klog.V(4).Infof("Removing autoscaler maintenance taint when creating template from node")
+			continue
 		}
 
 		// ignore conditional taints as they represent a transient node state.
@@ -309,6 +309,31 @@ func TestCleanAllDeletionCandidates(t *testing.T) {
 	assert.Equal(t, 0, len(getNode(t, fakeClient, "n2").Spec.Taints))
 }
 
+func TestCleanAllTaintsWithPrefixes(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 10)
+	n1.Spec.Taints = []apiv1.Taint{
+		{Key: "drain.example.com/draining", Effect: apiv1.TaintEffectNoSchedule},
+		{Key: "drain.example.com/started", Effect: apiv1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule},
+	}
+	n1.Spec.Unschedulable = true
+	n2 := BuildTestNode("n2", 1000, 10)
+	n2.Spec.Taints = []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}}
+
+	fakeClient := buildFakeClient(t, n1, n2)
+	fakeRecorder := kube_util.CreateEventRecorder(fakeClient, false)
+	prefixes := []string{"drain.example.com/", "unused/"}
+
+	CleanAllTaintsWithPrefixes([]*apiv1.Node{n1, n2}, fakeClient, fakeRecorder, prefixes, true)
+	assert.Equal(t, 3, len(getNode(t, fakeClient, "n1").Spec.Taints))
+
+	CleanAllTaintsWithPrefixes([]*apiv1.Node{n1, n2}, fakeClient, fakeRecorder, prefixes, false)
+	updated := getNode(t, fakeClient, "n1")
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "gpu", Effect: apiv1.TaintEffectNoSchedule}}, updated.Spec.Taints)
+	assert.True(t, updated.Spec.Unschedulable)
+	assert.Equal(t, 1, len(getNode(t, fakeClient, "n2").Spec.Taints))
+}
+
 func setConflictRetryInterval(interval time.Duration) time.Duration {
 	before := conflictRetryInterval
 	conflictRetryInterval = interval
@@ -587,6 +612,16 @@ func TestSanitizeTaints(t *testing.T) {
 					Value:  "1",
 					Effect: apiv1.TaintEffectNoSchedule,
 				},
+				{
+					Key:    OrphanedNodeTaint,
+					Value:  "1",
+					Effect: apiv1.TaintEffectNoSchedule,
+				},
+				{
+					Key:    MaintenanceScheduledTaint,
+					Value:  "1",
+					Effect: apiv1.TaintEffectNoSchedule,
+				},
 				{
 					Key:    "ignore-me",
 					Value:  "1",
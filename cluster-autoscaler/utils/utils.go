@@ -62,12 +62,14 @@ func FilterOutNodes(nodes []*apiv1.Node, nodesToFilterOut []*apiv1.Node) []*apiv
 // an equivalence group per pod which is undesirable.
 // Projected volumes do not impact scheduling so we should ignore them
 func PodSpecSemanticallyEqual(p1 apiv1.PodSpec, p2 apiv1.PodSpec) bool {
-	p1Spec := sanitizePodSpec(p1)
-	p2Spec := sanitizePodSpec(p2)
+	p1Spec := SanitizePodSpec(p1)
+	p2Spec := SanitizePodSpec(p2)
 	return apiequality.Semantic.DeepEqual(p1Spec, p2Spec)
 }
 
-func sanitizePodSpec(podSpec apiv1.PodSpec) apiv1.PodSpec {
+// SanitizePodSpec returns the pod spec without the fields differing between
+// equivalent pods, i.e. projected volumes and the hostname.
+func SanitizePodSpec(podSpec apiv1.PodSpec) apiv1.PodSpec {
 	dropProjectedVolumesAndMounts(&podSpec)
 	dropHostname(&podSpec)
 	return podSpec
@@ -184,7 +184,7 @@ func TestSanitizePodSpec(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := sanitizePodSpec(tt.inputPodSpec)
+			got := SanitizePodSpec(tt.inputPodSpec)
 			assert.True(t, assert.ObjectsAreEqualValues(tt.outputPodSpec, got), "\ngot: %#v\nwant: %#v", got, tt.outputPodSpec)
 		})
 	}
@@ -44,10 +44,11 @@ func NewAdmissionServer(podPreProcessor pod.PreProcessor,
 	vpaPreProcessor vpa.PreProcessor,
 	limitsChecker limitrange.LimitRangeCalculator,
 	vpaMatcher vpa.Matcher,
-	patchCalculators []patch.Calculator) *AdmissionServer {
+	patchCalculators []patch.Calculator,
+	vpaConflictChecker vpa.ConflictChecker) *AdmissionServer {
 	as := &AdmissionServer{limitsChecker, map[metav1.GroupResource]resource.Handler{}}
 	as.RegisterResourceHandler(pod.NewResourceHandler(podPreProcessor, vpaMatcher, patchCalculators))
-	as.RegisterResourceHandler(vpa.NewResourceHandler(vpaPreProcessor))
+	as.RegisterResourceHandler(vpa.NewResourceHandler(vpaPreProcessor, vpaConflictChecker))
 	return as
 }
 
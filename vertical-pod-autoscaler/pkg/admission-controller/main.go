@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	apiv1 "k8s.io/api/core/v1"
@@ -63,19 +64,23 @@ var (
 	ciphers       = flag.String("tls-ciphers", "", "A comma-separated or colon-separated list of ciphers to accept.  Only works when min-tls-version is set to tls1_2.")
 	minTlsVersion = flag.String("min-tls-version", "tls1_2", "The minimum TLS version to accept.  Must be set to either tls1_2 (default) or tls1_3.")
 
-	port               = flag.Int("port", 8000, "The port to listen on.")
-	address            = flag.String("address", ":8944", "The address to expose Prometheus metrics.")
-	kubeconfig         = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
-	kubeApiQps         = flag.Float64("kube-api-qps", 5.0, `QPS limit when making requests to Kubernetes apiserver`)
-	kubeApiBurst       = flag.Float64("kube-api-burst", 10.0, `QPS burst limit when making requests to Kubernetes apiserver`)
-	namespace          = os.Getenv("NAMESPACE")
-	serviceName        = flag.String("webhook-service", "vpa-webhook", "Kubernetes service under which webhook is registered. Used when registerByURL is set to false.")
-	webhookAddress     = flag.String("webhook-address", "", "Address under which webhook is registered. Used when registerByURL is set to true.")
-	webhookPort        = flag.String("webhook-port", "", "Server Port for Webhook")
-	webhookTimeout     = flag.Int("webhook-timeout-seconds", 30, "Timeout in seconds that the API server should wait for this webhook to respond before failing.")
-	registerWebhook    = flag.Bool("register-webhook", true, "If set to true, admission webhook object will be created on start up to register with the API server.")
-	registerByURL      = flag.Bool("register-by-url", false, "If set to true, admission webhook will be registered by URL (webhookAddress:webhookPort) instead of by service name")
-	vpaObjectNamespace = flag.String("vpa-object-namespace", apiv1.NamespaceAll, "Namespace to search for VPA objects. Empty means all namespaces will be used.")
+	port                     = flag.Int("port", 8000, "The port to listen on.")
+	address                  = flag.String("address", ":8944", "The address to expose Prometheus metrics.")
+	kubeconfig               = flag.String("kubeconfig", "", "Path to a kubeconfig. Only required if out-of-cluster.")
+	kubeApiQps               = flag.Float64("kube-api-qps", 5.0, `QPS limit when making requests to Kubernetes apiserver`)
+	kubeApiBurst             = flag.Float64("kube-api-burst", 10.0, `QPS burst limit when making requests to Kubernetes apiserver`)
+	namespace                = os.Getenv("NAMESPACE")
+	serviceName              = flag.String("webhook-service", "vpa-webhook", "Kubernetes service under which webhook is registered. Used when registerByURL is set to false.")
+	webhookAddress           = flag.String("webhook-address", "", "Address under which webhook is registered. Used when registerByURL is set to true.")
+	webhookPort              = flag.String("webhook-port", "", "Server Port for Webhook")
+	webhookTimeout           = flag.Int("webhook-timeout-seconds", 30, "Timeout in seconds that the API server should wait for this webhook to respond before failing.")
+	registerWebhook          = flag.Bool("register-webhook", true, "If set to true, admission webhook object will be created on start up to register with the API server.")
+	registerByURL            = flag.Bool("register-by-url", false, "If set to true, admission webhook will be registered by URL (webhookAddress:webhookPort) instead of by service name")
+	vpaObjectNamespace       = flag.String("vpa-object-namespace", apiv1.NamespaceAll, "Namespace to search for VPA objects. Empty means all namespaces will be used.")
+	ignoredNamespaceSelector = flag.String("ignored-namespace-selector", "", "Label selector for namespaces whose VPA objects should be ignored. Namespace labels are evaluated dynamically. Empty means no namespace is ignored.")
+	rejectOverlappingVpas    = flag.Bool("reject-overlapping-vpas", false, "If set to true, creating a VPA whose target pods may also be selected by another VPA in the same namespace is rejected. VPAs with update mode Off are ignored.")
+	conflictingAnnotations   = flag.String("conflicting-actuation-annotations", "", "A comma-separated list of annotation keys marking workloads whose resources are managed by other actuation systems. Creating a VPA targeting such a workload is rejected.")
+	enablePodLevelResources  = flag.Bool("enable-pod-level-resources", false, "If set to true, pod-level requests are set for VPAs with pod-level policy mode Auto. Requires Kubernetes 1.32+ with the PodLevelResources feature gate enabled.")
 )
 
 func main() {
@@ -90,20 +95,27 @@ func main() {
 	config := common.CreateKubeConfigOrDie(*kubeconfig, float32(*kubeApiQps), int(*kubeApiBurst))
 
 	vpaClient := vpa_clientset.NewForConfigOrDie(config)
-	vpaLister := vpa_api_util.NewVpasLister(vpaClient, make(chan struct{}), *vpaObjectNamespace)
 	kubeClient := kube_client.NewForConfigOrDie(config)
+	vpaLister, err := vpa_api_util.WithIgnoredNamespaces(vpa_api_util.NewVpasLister(vpaClient, make(chan struct{}), *vpaObjectNamespace), kubeClient, make(chan struct{}), *ignoredNamespaceSelector)
+	if err != nil {
+		klog.Fatalf("Could not use --ignored-namespace-selector: %v", err)
+	}
 	factory := informers.NewSharedInformerFactory(kubeClient, defaultResyncPeriod)
 	targetSelectorFetcher := target.NewVpaTargetSelectorFetcher(config, kubeClient, factory)
 	controllerFetcher := controllerfetcher.NewControllerFetcher(config, kubeClient, factory, scaleCacheEntryFreshnessTime, scaleCacheEntryLifetime, scaleCacheEntryJitterFactor)
 	podPreprocessor := pod.NewDefaultPreProcessor()
 	vpaPreprocessor := vpa.NewDefaultPreProcessor()
 	var limitRangeCalculator limitrange.LimitRangeCalculator
-	limitRangeCalculator, err := limitrange.NewLimitsRangeCalculator(factory)
+	limitRangeCalculator, err = limitrange.NewLimitsRangeCalculator(factory)
 	if err != nil {
 		klog.Errorf("Failed to create limitRangeCalculator, falling back to not checking limits. Error message: %s", err)
 		limitRangeCalculator = limitrange.NewNoopLimitsCalculator()
 	}
-	recommendationProvider := recommendation.NewProvider(limitRangeCalculator, vpa_api_util.NewCappingRecommendationProcessor(limitRangeCalculator))
+	recommendationProcessor := vpa_api_util.NewSequentialProcessor([]vpa_api_util.RecommendationProcessor{
+		vpa_api_util.NewPodLevelSplitRecommendationProcessor(),
+		vpa_api_util.NewCappingRecommendationProcessor(limitRangeCalculator),
+	})
+	recommendationProvider := recommendation.NewProvider(limitRangeCalculator, recommendationProcessor)
 	vpaMatcher := vpa.NewMatcher(vpaLister, targetSelectorFetcher, controllerFetcher)
 
 	hostname, err := os.Hostname()
@@ -125,8 +137,22 @@ func main() {
 	)
 	defer close(stopCh)
 
-	calculators := []patch.Calculator{patch.NewResourceUpdatesCalculator(recommendationProvider), patch.NewObservedContainersCalculator()}
-	as := logic.NewAdmissionServer(podPreprocessor, vpaPreprocessor, limitRangeCalculator, vpaMatcher, calculators)
+	calculators := []patch.Calculator{patch.NewResourceUpdatesCalculator(recommendationProvider, *enablePodLevelResources), patch.NewObservedContainersCalculator()}
+	var vpaConflictChecker vpa.ConflictChecker
+	if *rejectOverlappingVpas || *conflictingAnnotations != "" {
+		annotationsFetcher, err := vpa.NewTargetAnnotationsFetcher(config)
+		if err != nil {
+			klog.Fatalf("Could not create target annotations fetcher: %v", err)
+		}
+		var annotations []string
+		for _, annotation := range strings.Split(*conflictingAnnotations, ",") {
+			if annotation = strings.TrimSpace(annotation); annotation != "" {
+				annotations = append(annotations, annotation)
+			}
+		}
+		vpaConflictChecker = vpa.NewConflictChecker(vpaLister, targetSelectorFetcher, annotationsFetcher, *rejectOverlappingVpas, annotations)
+	}
+	as := logic.NewAdmissionServer(podPreprocessor, vpaPreprocessor, limitRangeCalculator, vpaMatcher, calculators, vpaConflictChecker)
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		as.Serve(w, r)
 		healthCheck.UpdateLastActivity()
@@ -86,13 +86,17 @@ func (h *resourceHandler) GetPatches(ar *admissionv1.AdmissionRequest) ([]resour
 	if err != nil {
 		return nil, err
 	}
+	podLevelResources, err := patch.GetPodLevelResources(raw)
+	if err != nil {
+		return nil, err
+	}
 
 	patches := []resource_admission.PatchRecord{}
 	if pod.Annotations == nil {
 		patches = append(patches, patch.GetAddEmptyAnnotationsPatch())
 	}
 	for _, c := range h.patchCalculators {
-		partialPatches, err := c.CalculatePatches(&pod, controllingVpa)
+		partialPatches, err := c.CalculatePatches(&pod, podLevelResources, controllingVpa)
 		if err != nil {
 			return []resource_admission.PatchRecord{}, err
 		}
@@ -52,7 +52,7 @@ type fakePatchCalculator struct {
 	err     error
 }
 
-func (c *fakePatchCalculator) CalculatePatches(_ *apiv1.Pod, _ *vpa_types.VerticalPodAutoscaler) (
+func (c *fakePatchCalculator) CalculatePatches(_ *apiv1.Pod, _ *apiv1.ResourceRequirements, _ *vpa_types.VerticalPodAutoscaler) (
 	[]resource_admission.PatchRecord, error) {
 	return c.patches, c.err
 }
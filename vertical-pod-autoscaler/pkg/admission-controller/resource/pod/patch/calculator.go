@@ -23,6 +23,8 @@ import (
 )
 
 // Calculator is capable of calculating required patches for pod.
+// Pod-level resources of the pod are passed separately, as they aren't
+// part of the vendored Pod API; they're nil if the pod doesn't set them.
 type Calculator interface {
-	CalculatePatches(pod *core.Pod, vpa *vpa_types.VerticalPodAutoscaler) ([]resource.PatchRecord, error)
+	CalculatePatches(pod *core.Pod, podLevelResources *core.ResourceRequirements, vpa *vpa_types.VerticalPodAutoscaler) ([]resource.PatchRecord, error)
 }
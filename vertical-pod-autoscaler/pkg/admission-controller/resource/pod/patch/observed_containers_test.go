@@ -56,7 +56,7 @@ func TestCalculatePatches_ObservedContainers(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			c := NewObservedContainersCalculator()
-			patches, err := c.CalculatePatches(tc.pod, nil)
+			patches, err := c.CalculatePatches(tc.pod, nil, nil)
 			assert.NoError(t, err)
 			if assert.Len(t, patches, 1, "Unexpected number of patches.") {
 				AssertEqPatch(t, tc.expectedPatch, patches[0])
@@ -18,6 +18,8 @@ package patch
 
 import (
 	"fmt"
+	"math/big"
+	"sort"
 	"strings"
 
 	core "k8s.io/api/core/v1"
@@ -36,17 +38,22 @@ const (
 
 type resourcesUpdatesPatchCalculator struct {
 	recommendationProvider recommendation.Provider
+	// podLevelResourcesEnabled allows patching pod-level resources, which are
+	// only supported by clusters with the PodLevelResources feature gate.
+	podLevelResourcesEnabled bool
 }
 
 // NewResourceUpdatesCalculator returns a calculator for
-// resource update patches.
-func NewResourceUpdatesCalculator(recommendationProvider recommendation.Provider) Calculator {
+// resource update patches. Pod-level resources are only patched
+// if podLevelResourcesEnabled is set.
+func NewResourceUpdatesCalculator(recommendationProvider recommendation.Provider, podLevelResourcesEnabled bool) Calculator {
 	return &resourcesUpdatesPatchCalculator{
-		recommendationProvider: recommendationProvider,
+		recommendationProvider:   recommendationProvider,
+		podLevelResourcesEnabled: podLevelResourcesEnabled,
 	}
 }
 
-func (c *resourcesUpdatesPatchCalculator) CalculatePatches(pod *core.Pod, vpa *vpa_types.VerticalPodAutoscaler) ([]resource_admission.PatchRecord, error) {
+func (c *resourcesUpdatesPatchCalculator) CalculatePatches(pod *core.Pod, podLevelResources *core.ResourceRequirements, vpa *vpa_types.VerticalPodAutoscaler) ([]resource_admission.PatchRecord, error) {
 	result := []resource_admission.PatchRecord{}
 
 	containersResources, annotationsPerContainer, err := c.recommendationProvider.GetContainersResourcesForPod(pod, vpa)
@@ -58,6 +65,10 @@ func (c *resourcesUpdatesPatchCalculator) CalculatePatches(pod *core.Pod, vpa *v
 		annotationsPerContainer = vpa_api_util.ContainerToAnnotationsMap{}
 	}
 
+	if podLevelResources != nil {
+		containersResources = capToPodLevelLimits(pod, podLevelResources.Limits, containersResources, annotationsPerContainer)
+	}
+
 	updatesAnnotation := []string{}
 	for i, containerResources := range containersResources {
 		newPatches, newUpdatesAnnotation := getContainerPatch(pod, i, annotationsPerContainer, containerResources)
@@ -65,6 +76,13 @@ func (c *resourcesUpdatesPatchCalculator) CalculatePatches(pod *core.Pod, vpa *v
 		updatesAnnotation = append(updatesAnnotation, newUpdatesAnnotation)
 	}
 
+	if c.podLevelResourcesEnabled && vpa_api_util.GetPodLevelScalingMode(vpa.Spec.ResourcePolicy) == vpa_types.PodLevelScalingModeAuto {
+		if podLevelPatches, podLevelUpdatesAnnotation, found := getPodLevelResourcesPatches(pod, podLevelResources, containersResources); found {
+			result = append(result, podLevelPatches...)
+			updatesAnnotation = append(updatesAnnotation, podLevelUpdatesAnnotation)
+		}
+	}
+
 	if len(updatesAnnotation) > 0 {
 		vpaAnnotationValue := fmt.Sprintf("Pod resources updated by %s: %s", vpa.Name, strings.Join(updatesAnnotation, "; "))
 		result = append(result, GetAddAnnotationPatch(ResourceUpdatesAnnotation, vpaAnnotationValue))
@@ -92,6 +110,152 @@ func getContainerPatch(pod *core.Pod, i int, annotationsPerContainer vpa_api_uti
 	return patches, updatesAnnotation
 }
 
+// capToPodLevelLimits scales recommended container requests down proportionally, so that the sum
+// of container requests doesn't exceed the pod-level limit, which would make the pod invalid.
+// Requests of containers without a recommendation for the resource are left unchanged.
+func capToPodLevelLimits(pod *core.Pod, limits core.ResourceList, containersResources []vpa_api_util.ContainerResources, annotationsPerContainer vpa_api_util.ContainerToAnnotationsMap) []vpa_api_util.ContainerResources {
+	// Don't modify resources shared with the recommendation provider.
+	capped := make([]vpa_api_util.ContainerResources, len(containersResources))
+	for i, containerResources := range containersResources {
+		capped[i] = vpa_api_util.ContainerResources{
+			Requests: containerResources.Requests.DeepCopy(),
+			Limits:   containerResources.Limits,
+		}
+	}
+	for resourceName, limit := range limits {
+		var recommended, other resource.Quantity
+		for i, container := range pod.Spec.Containers {
+			if i < len(containersResources) {
+				if request, found := containersResources[i].Requests[resourceName]; found {
+					recommended.Add(request)
+					continue
+				}
+			}
+			if request, found := container.Resources.Requests[resourceName]; found {
+				other.Add(request)
+			}
+		}
+		available := limit.DeepCopy()
+		available.Sub(other)
+		if recommended.IsZero() || available.Sign() <= 0 {
+			continue
+		}
+		total := recommended.DeepCopy()
+		total.Add(other)
+		if total.Cmp(limit) <= 0 {
+			continue
+		}
+		for i := range capped {
+			request, found := capped[i].Requests[resourceName]
+			if !found || i >= len(pod.Spec.Containers) {
+				continue
+			}
+			capped[i].Requests[resourceName] = scaleQuantity(resourceName, request, available, recommended)
+			containerName := pod.Spec.Containers[i].Name
+			annotationsPerContainer[containerName] = append(annotationsPerContainer[containerName],
+				fmt.Sprintf("%s request capped proportionally to pod-level limit", resourceName))
+		}
+	}
+	return capped
+}
+
+// scaleQuantity returns quantity * numerator / denominator, rounded down, in milliunits for CPU
+// and in units otherwise.
+func scaleQuantity(resourceName core.ResourceName, quantity, numerator, denominator resource.Quantity) resource.Quantity {
+	value := func(q resource.Quantity) *big.Int { return big.NewInt(q.Value()) }
+	if resourceName == core.ResourceCPU {
+		value = func(q resource.Quantity) *big.Int { return big.NewInt(q.MilliValue()) }
+	}
+	var scaled big.Int
+	scaled.Mul(value(quantity), value(numerator))
+	scaled.Div(&scaled, value(denominator))
+	if resourceName == core.ResourceCPU {
+		return *resource.NewMilliQuantity(scaled.Int64(), quantity.Format)
+	}
+	return *resource.NewQuantity(scaled.Int64(), quantity.Format)
+}
+
+// getPodLevelResourcesPatches returns patches setting pod-level resource requests to the sum of
+// container requests after applying the recommendation. Only resources with a recommendation
+// for at least one container are set. Pod-level limits are preserved. Container requests are
+// capped to fit in them beforehand, so a resource is only skipped if requests of containers
+// without a recommendation already exceed its limit.
+func getPodLevelResourcesPatches(pod *core.Pod, podLevelResources *core.ResourceRequirements, containersResources []vpa_api_util.ContainerResources) ([]resource_admission.PatchRecord, string, bool) {
+	recommended := map[core.ResourceName]bool{}
+	for _, containerResources := range containersResources {
+		for resourceName := range containerResources.Requests {
+			recommended[resourceName] = true
+		}
+	}
+	if len(recommended) == 0 {
+		return nil, "", false
+	}
+
+	requests := core.ResourceList{}
+	for i, container := range pod.Spec.Containers {
+		for resourceName := range recommended {
+			var request resource.Quantity
+			var found bool
+			if i < len(containersResources) {
+				request, found = containersResources[i].Requests[resourceName]
+			}
+			if !found {
+				request, found = container.Resources.Requests[resourceName]
+			}
+			if !found {
+				continue
+			}
+			sum := requests[resourceName]
+			sum.Add(request)
+			requests[resourceName] = sum
+		}
+	}
+
+	if len(requests) == 0 {
+		return nil, "", false
+	}
+
+	var patches []resource_admission.PatchRecord
+	if podLevelResources == nil {
+		podLevelResources = &core.ResourceRequirements{}
+		patches = append(patches, resource_admission.PatchRecord{
+			Op:    "add",
+			Path:  "/spec/resources",
+			Value: core.ResourceRequirements{},
+		})
+	}
+	if podLevelResources.Requests == nil {
+		patches = append(patches, resource_admission.PatchRecord{
+			Op:    "add",
+			Path:  "/spec/resources/requests",
+			Value: core.ResourceList{},
+		})
+	}
+
+	resourceNames := make([]string, 0, len(requests))
+	for resourceName, request := range requests {
+		if limit, found := podLevelResources.Limits[resourceName]; found && request.Cmp(limit) > 0 {
+			continue
+		}
+		resourceNames = append(resourceNames, string(resourceName))
+	}
+	if len(resourceNames) == 0 {
+		return nil, "", false
+	}
+	sort.Strings(resourceNames)
+	annotations := []string{}
+	for _, resourceName := range resourceNames {
+		request := requests[core.ResourceName(resourceName)]
+		patches = append(patches, resource_admission.PatchRecord{
+			Op:    "add",
+			Path:  fmt.Sprintf("/spec/resources/requests/%s", resourceName),
+			Value: request.String(),
+		})
+		annotations = append(annotations, fmt.Sprintf("%s request", resourceName))
+	}
+	return patches, "pod: " + strings.Join(annotations, ", "), true
+}
+
 func appendPatchesAndAnnotations(patches []resource_admission.PatchRecord, annotations []string, current core.ResourceList, containerIndex int, resources core.ResourceList, fieldName, resourceName string) ([]resource_admission.PatchRecord, []string) {
 	// Add empty object if it's missing and we're about to fill it.
 	if current == nil && len(resources) > 0 {
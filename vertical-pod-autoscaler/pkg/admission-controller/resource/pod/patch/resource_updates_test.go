@@ -252,8 +252,8 @@ func TestClalculatePatches_ResourceUpdates(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			frp := fakeRecommendationProvider{tc.recommendResources, tc.recommendAnnotations, tc.recommendError}
-			c := NewResourceUpdatesCalculator(&frp)
-			patches, err := c.CalculatePatches(tc.pod, test.VerticalPodAutoscaler().WithContainer("test").WithName("name").Get())
+			c := NewResourceUpdatesCalculator(&frp, false)
+			patches, err := c.CalculatePatches(tc.pod, nil, test.VerticalPodAutoscaler().WithContainer("test").WithName("name").Get())
 			if tc.expectError == nil {
 				assert.NoError(t, err)
 			} else {
@@ -294,8 +294,8 @@ func TestGetPatches_TwoReplacementResources(t *testing.T) {
 	}
 	recommendAnnotations := vpa_api_util.ContainerToAnnotationsMap{}
 	frp := fakeRecommendationProvider{recommendResources, recommendAnnotations, nil}
-	c := NewResourceUpdatesCalculator(&frp)
-	patches, err := c.CalculatePatches(pod, test.VerticalPodAutoscaler().WithName("name").WithContainer("test").Get())
+	c := NewResourceUpdatesCalculator(&frp, false)
+	patches, err := c.CalculatePatches(pod, nil, test.VerticalPodAutoscaler().WithName("name").WithContainer("test").Get())
 	assert.NoError(t, err)
 	// Order of updates for cpu and unobtanium depends on order of iterating a map, both possible results are valid.
 	if assert.Len(t, patches, 3, "unexpected number of patches") {
@@ -309,3 +309,145 @@ func TestGetPatches_TwoReplacementResources(t *testing.T) {
 		AssertPatchOneOf(t, patches[2], []resource_admission.PatchRecord{cpuFirstUnobtaniumSecond, unobtaniumFirstCpuSecond})
 	}
 }
+
+func TestCalculatePatches_PodLevelResources(t *testing.T) {
+	recommendResources := []vpa_api_util.ContainerResources{
+		{
+			Requests: core.ResourceList{
+				cpu: resource.MustParse("1"),
+			},
+		},
+		{},
+	}
+	pod := &core.Pod{
+		Spec: core.PodSpec{
+			Containers: []core.Container{
+				{
+					Resources: core.ResourceRequirements{
+						Requests: core.ResourceList{
+							cpu:                 resource.MustParse("500m"),
+							core.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+				{
+					Resources: core.ResourceRequirements{
+						Requests: core.ResourceList{
+							cpu: resource.MustParse("200m"),
+						},
+					},
+				},
+			},
+		},
+	}
+	podLevelAuto := vpa_types.PodLevelScalingModeAuto
+	podLevelOff := vpa_types.PodLevelScalingModeOff
+	containerPatch := addResourceRequestPatch(0, cpu, "1")
+	// 1 CPU recommended, 200m requested by the other container, 1 CPU pod-level limit.
+	cappedContainerPatch := addResourceRequestPatch(0, cpu, "800m")
+
+	for _, tc := range []struct {
+		name              string
+		mode              *vpa_types.PodLevelScalingMode
+		disabled          bool
+		podLevelResources *core.ResourceRequirements
+		wantContainer     *resource_admission.PatchRecord
+		wantPodLevel      []resource_admission.PatchRecord
+	}{
+		{
+			name: "pod-level policy not set",
+		},
+		{
+			name: "pod-level policy off",
+			mode: &podLevelOff,
+		},
+		{
+			name:     "pod-level resources disabled",
+			mode:     &podLevelAuto,
+			disabled: true,
+		},
+		{
+			name: "pod-level policy auto",
+			mode: &podLevelAuto,
+			wantPodLevel: []resource_admission.PatchRecord{
+				{Op: "add", Path: "/spec/resources", Value: core.ResourceRequirements{}},
+				{Op: "add", Path: "/spec/resources/requests", Value: core.ResourceList{}},
+				{Op: "add", Path: "/spec/resources/requests/cpu", Value: "1200m"},
+			},
+		},
+		{
+			name: "pod-level limits preserved",
+			mode: &podLevelAuto,
+			podLevelResources: &core.ResourceRequirements{
+				Limits: core.ResourceList{
+					cpu: resource.MustParse("2"),
+				},
+			},
+			wantPodLevel: []resource_admission.PatchRecord{
+				{Op: "add", Path: "/spec/resources/requests", Value: core.ResourceList{}},
+				{Op: "add", Path: "/spec/resources/requests/cpu", Value: "1200m"},
+			},
+		},
+		{
+			name: "container requests capped to fit in pod-level limits",
+			mode: &podLevelAuto,
+			podLevelResources: &core.ResourceRequirements{
+				Requests: core.ResourceList{
+					cpu: resource.MustParse("500m"),
+				},
+				Limits: core.ResourceList{
+					cpu: resource.MustParse("1"),
+				},
+			},
+			wantContainer: &cappedContainerPatch,
+			wantPodLevel: []resource_admission.PatchRecord{
+				{Op: "add", Path: "/spec/resources/requests/cpu", Value: "1"},
+			},
+		},
+		{
+			name:     "container requests capped with pod-level resources disabled",
+			mode:     &podLevelAuto,
+			disabled: true,
+			podLevelResources: &core.ResourceRequirements{
+				Limits: core.ResourceList{
+					cpu: resource.MustParse("1"),
+				},
+			},
+			wantContainer: &cappedContainerPatch,
+		},
+		{
+			name: "pod-level patch skipped if other containers exceed limits",
+			mode: &podLevelAuto,
+			podLevelResources: &core.ResourceRequirements{
+				Limits: core.ResourceList{
+					cpu: resource.MustParse("100m"),
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			frp := fakeRecommendationProvider{recommendResources, vpa_api_util.ContainerToAnnotationsMap{}, nil}
+			c := NewResourceUpdatesCalculator(&frp, !tc.disabled)
+			vpa := test.VerticalPodAutoscaler().WithName("name").WithContainer("test").Get()
+			vpa.Spec.ResourcePolicy = &vpa_types.PodResourcePolicy{
+				PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{Mode: tc.mode},
+			}
+			patches, err := c.CalculatePatches(pod, tc.podLevelResources, vpa)
+			assert.NoError(t, err)
+			// The container patch and the annotation are expected besides pod-level patches.
+			wantContainer := containerPatch
+			if tc.wantContainer != nil {
+				wantContainer = *tc.wantContainer
+			}
+			if assert.Len(t, patches, len(tc.wantPodLevel)+2) {
+				AssertEqPatch(t, patches[0], wantContainer)
+				for i, want := range tc.wantPodLevel {
+					AssertEqPatch(t, patches[i+1], want)
+				}
+				if len(tc.wantPodLevel) > 0 {
+					assert.Contains(t, patches[len(patches)-1].Value, "pod: cpu request")
+				}
+			}
+		})
+	}
+}
@@ -17,8 +17,10 @@ limitations under the License.
 package patch
 
 import (
+	"encoding/json"
 	"fmt"
 
+	core "k8s.io/api/core/v1"
 	resource_admission "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/admission-controller/resource"
 )
 
@@ -39,3 +41,17 @@ func GetAddAnnotationPatch(annotationName, annotationValue string) resource_admi
 		Value: annotationValue,
 	}
 }
+
+// GetPodLevelResources returns the pod-level resources of the raw pod, nil if
+// they're not set. They aren't part of the vendored Pod API yet.
+func GetPodLevelResources(raw []byte) (*core.ResourceRequirements, error) {
+	pod := struct {
+		Spec struct {
+			Resources *core.ResourceRequirements `json:"resources,omitempty"`
+		} `json:"spec"`
+	}{}
+	if err := json.Unmarshal(raw, &pod); err != nil {
+		return nil, err
+	}
+	return pod.Spec.Resources, nil
+}
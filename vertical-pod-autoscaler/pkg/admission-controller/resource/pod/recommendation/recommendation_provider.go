@@ -100,6 +100,9 @@ func (p *recommendationProvider) GetContainersResourcesForPod(pod *core.Pod, vpa
 			klog.V(2).Infof("cannot process recommendation for pod %s", klog.KObj(pod))
 			return nil, annotations, err
 		}
+		// Resources which aren't updated for the pod keep the requests from the pod spec.
+		recommendedPodResources = vpa_api_util.FilterRecommendationByUpdateModes(recommendedPodResources, vpa, pod,
+			vpa_types.UpdateModeInitial, vpa_types.UpdateModeInPlace, vpa_types.UpdateModeRecreate, vpa_types.UpdateModeAuto)
 	}
 	containerLimitRange, err := p.limitsRangeCalculator.GetContainerLimitRangeItem(pod.Namespace)
 	if err != nil {
@@ -107,6 +107,8 @@ func TestUpdateResourceRequests(t *testing.T) {
 	resourceRequestsOnlyVPAHighTarget := vpaBuilder.WithControlledValues(containerName, vpa_types.ContainerControlledValuesRequestsOnly).
 		WithTarget("3", "500Mi").WithMaxAllowed(containerName, "5", "1Gi").Get()
 
+	memoryUpdateOffVPA := vpaBuilder.WithUpdateMode(vpa_types.UpdateModeAuto).WithResourceUpdateMode(apiv1.ResourceMemory, vpa_types.UpdateModeOff).Get()
+
 	vpaWithEmptyRecommendation := vpaBuilder.Get()
 	vpaWithEmptyRecommendation.Status.Recommendation = &vpa_types.RecommendedPodResources{}
 	vpaWithNilRecommendation := vpaBuilder.Get()
@@ -164,6 +166,13 @@ func TestUpdateResourceRequests(t *testing.T) {
 			expectedMem:    resource.MustParse("200Mi"),
 			expectedCPU:    resource.MustParse("2"),
 		},
+		{
+			name:           "memory update mode off",
+			pod:            initialized,
+			vpa:            memoryUpdateOffVPA,
+			expectedAction: true,
+			expectedCPU:    resource.MustParse("2"),
+		},
 		{
 			name:           "high memory",
 			pod:            initialized,
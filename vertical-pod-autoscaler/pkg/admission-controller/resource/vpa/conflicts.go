@@ -0,0 +1,218 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpa
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	cacheddiscovery "k8s.io/client-go/discovery/cached"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/klog/v2"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	vpa_lister "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/listers/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/target"
+	vpa_api_util "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/vpa"
+)
+
+// ConflictChecker detects VPAs which would silently compete for the same pods
+// with other VPAs or with other systems actuating resources of the workload.
+type ConflictChecker interface {
+	// CheckConflicts returns an error describing the conflict, if any.
+	CheckConflicts(vpa *vpa_types.VerticalPodAutoscaler) error
+}
+
+// TargetAnnotationsFetcher returns annotations of the workload targeted by a VPA.
+type TargetAnnotationsFetcher interface {
+	Fetch(vpa *vpa_types.VerticalPodAutoscaler) (map[string]string, error)
+}
+
+type conflictChecker struct {
+	vpaLister              vpa_lister.VerticalPodAutoscalerLister
+	selectorFetcher        target.VpaTargetSelectorFetcher
+	annotationsFetcher     TargetAnnotationsFetcher
+	rejectOverlapping      bool
+	conflictingAnnotations []string
+}
+
+// NewConflictChecker returns a ConflictChecker. If rejectOverlapping is set,
+// VPAs selecting pods which may also be selected by another VPA in the same
+// namespace are rejected. VPAs targeting workloads with any of the
+// conflictingAnnotations are rejected, as other systems use them to mark
+// workloads whose resources they manage.
+func NewConflictChecker(vpaLister vpa_lister.VerticalPodAutoscalerLister,
+	selectorFetcher target.VpaTargetSelectorFetcher,
+	annotationsFetcher TargetAnnotationsFetcher,
+	rejectOverlapping bool,
+	conflictingAnnotations []string) ConflictChecker {
+	return &conflictChecker{
+		vpaLister:              vpaLister,
+		selectorFetcher:        selectorFetcher,
+		annotationsFetcher:     annotationsFetcher,
+		rejectOverlapping:      rejectOverlapping,
+		conflictingAnnotations: conflictingAnnotations,
+	}
+}
+
+func (c *conflictChecker) CheckConflicts(vpa *vpa_types.VerticalPodAutoscaler) error {
+	if vpa.Spec.TargetRef == nil || vpa_api_util.GetMaxUpdateMode(vpa) == vpa_types.UpdateModeOff {
+		// VPAs which don't actuate recommendations can't conflict.
+		return nil
+	}
+	if len(c.conflictingAnnotations) > 0 {
+		annotations, err := c.annotationsFetcher.Fetch(vpa)
+		if err != nil {
+			// The target may not exist yet, don't block creating VPAs before their workloads.
+			klog.V(3).Infof("Cannot fetch annotations of the target of VPA %s, skipping actuation conflicts check: %v", klog.KObj(vpa), err)
+		}
+		for _, annotation := range c.conflictingAnnotations {
+			if _, found := annotations[annotation]; found {
+				return fmt.Errorf("%s %s is managed by another actuation system (annotation %s)", vpa.Spec.TargetRef.Kind, vpa.Spec.TargetRef.Name, annotation)
+			}
+		}
+	}
+	if c.rejectOverlapping {
+		return c.checkOverlapping(vpa)
+	}
+	return nil
+}
+
+func (c *conflictChecker) checkOverlapping(vpa *vpa_types.VerticalPodAutoscaler) error {
+	selector, err := c.selectorFetcher.Fetch(vpa)
+	if err != nil {
+		klog.V(3).Infof("Cannot fetch selector of VPA %s, skipping overlap check: %v", klog.KObj(vpa), err)
+		return nil
+	}
+	others, err := c.vpaLister.VerticalPodAutoscalers(vpa.Namespace).List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("failed to list VPAs: %v", err)
+	}
+	for _, other := range others {
+		if other.Name == vpa.Name || vpa_api_util.GetMaxUpdateMode(other) == vpa_types.UpdateModeOff {
+			continue
+		}
+		otherSelector, err := c.selectorFetcher.Fetch(other)
+		if err != nil {
+			klog.V(3).Infof("Cannot fetch selector of VPA %s, skipping it in overlap check: %v", klog.KObj(other), err)
+			continue
+		}
+		if selectorsOverlap(selector, otherSelector) {
+			return fmt.Errorf("pods selected by the target %s %s may also be selected by VPA %s (selector %s)", vpa.Spec.TargetRef.Kind, vpa.Spec.TargetRef.Name, other.Name, otherSelector)
+		}
+	}
+	return nil
+}
+
+// selectorsOverlap returns false only if no set of labels can match both
+// selectors. Numeric requirements are assumed to be satisfiable.
+func selectorsOverlap(a, b labels.Selector) bool {
+	requirementsA, selectableA := a.Requirements()
+	requirementsB, selectableB := b.Requirements()
+	if !selectableA || !selectableB {
+		return false
+	}
+	type keyConstraints struct {
+		mustExist    bool
+		mustNotExist bool
+		// allowed is nil if any value is allowed.
+		allowed   sets.Set[string]
+		forbidden sets.Set[string]
+	}
+	constraints := map[string]*keyConstraints{}
+	for _, requirement := range append(requirementsA, requirementsB...) {
+		c, found := constraints[requirement.Key()]
+		if !found {
+			c = &keyConstraints{forbidden: sets.New[string]()}
+			constraints[requirement.Key()] = c
+		}
+		values := sets.New(requirement.Values().UnsortedList()...)
+		switch requirement.Operator() {
+		case selection.Equals, selection.DoubleEquals, selection.In:
+			c.mustExist = true
+			if c.allowed == nil {
+				c.allowed = values
+			} else {
+				c.allowed = c.allowed.Intersection(values)
+			}
+		case selection.NotEquals, selection.NotIn:
+			c.forbidden = c.forbidden.Union(values)
+		case selection.Exists, selection.GreaterThan, selection.LessThan:
+			c.mustExist = true
+		case selection.DoesNotExist:
+			c.mustNotExist = true
+		}
+	}
+	for _, c := range constraints {
+		if c.mustExist && c.mustNotExist {
+			return false
+		}
+		if c.allowed != nil && c.allowed.Difference(c.forbidden).Len() == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type targetAnnotationsFetcher struct {
+	dynamicClient dynamic.Interface
+	mapper        *restmapper.DeferredDiscoveryRESTMapper
+}
+
+// NewTargetAnnotationsFetcher returns a TargetAnnotationsFetcher reading
+// targets of any kind with the dynamic client.
+func NewTargetAnnotationsFetcher(config *rest.Config) (TargetAnnotationsFetcher, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &targetAnnotationsFetcher{
+		dynamicClient: dynamicClient,
+		mapper:        restmapper.NewDeferredDiscoveryRESTMapper(cacheddiscovery.NewMemCacheClient(discoveryClient)),
+	}, nil
+}
+
+func (f *targetAnnotationsFetcher) Fetch(vpa *vpa_types.VerticalPodAutoscaler) (map[string]string, error) {
+	targetRef := vpa.Spec.TargetRef
+	groupVersion, err := schema.ParseGroupVersion(targetRef.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := f.mapper.RESTMapping(schema.GroupKind{Group: groupVersion.Group, Kind: targetRef.Kind}, groupVersion.Version)
+	if err != nil {
+		// Kinds may have been added since discovery was cached.
+		f.mapper.Reset()
+		return nil, err
+	}
+	obj, err := f.dynamicClient.Resource(mapping.Resource).Namespace(vpa.Namespace).Get(context.TODO(), targetRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.GetAnnotations(), nil
+}
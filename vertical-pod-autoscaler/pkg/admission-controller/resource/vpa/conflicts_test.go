@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vpa
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/admission/v1"
+	autoscaling "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+)
+
+type fakeSelectorFetcher map[string]string
+
+func (f fakeSelectorFetcher) Fetch(vpa *vpa_types.VerticalPodAutoscaler) (labels.Selector, error) {
+	selector, found := f[vpa.Spec.TargetRef.Name]
+	if !found {
+		return nil, fmt.Errorf("%s not found", vpa.Spec.TargetRef.Name)
+	}
+	return labels.Parse(selector)
+}
+
+type fakeAnnotationsFetcher map[string]map[string]string
+
+func (f fakeAnnotationsFetcher) Fetch(vpa *vpa_types.VerticalPodAutoscaler) (map[string]string, error) {
+	annotations, found := f[vpa.Spec.TargetRef.Name]
+	if !found {
+		return nil, fmt.Errorf("%s not found", vpa.Spec.TargetRef.Name)
+	}
+	return annotations, nil
+}
+
+func TestSelectorsOverlap(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "app=a", b: "app=a", want: true},
+		{a: "app=a", b: "app=b", want: false},
+		{a: "app=a", b: "tier=web", want: true},
+		{a: "app in (a, b)", b: "app in (b, c)", want: true},
+		{a: "app in (a, b)", b: "app notin (a, b)", want: false},
+		{a: "app=a", b: "!app", want: false},
+		{a: "app", b: "app!=a", want: true},
+		{a: "", b: "app=a", want: true},
+		{a: "replicas>1", b: "app=a", want: true},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s and %s", tc.a, tc.b), func(t *testing.T) {
+			a, err := labels.Parse(tc.a)
+			assert.NoError(t, err)
+			b, err := labels.Parse(tc.b)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, selectorsOverlap(a, b))
+			assert.Equal(t, tc.want, selectorsOverlap(b, a))
+		})
+	}
+}
+
+func TestCheckConflicts(t *testing.T) {
+	vpaFor := func(name, target string, mode vpa_types.UpdateMode) *vpa_types.VerticalPodAutoscaler {
+		return test.VerticalPodAutoscaler().WithName(name).WithNamespace("default").WithContainer("c").WithUpdateMode(mode).
+			WithTargetRef(&autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: target, APIVersion: "apps/v1"}).Get()
+	}
+	selectors := fakeSelectorFetcher{"web": "app=web", "web-canary": "app=web,track=canary", "db": "app=db", "db-replica": "app=db"}
+	annotations := fakeAnnotationsFetcher{"web": {}, "db": {"example.com/managed-resources": "true"}}
+	existing := []*vpa_types.VerticalPodAutoscaler{
+		vpaFor("web", "web", vpa_types.UpdateModeAuto),
+		vpaFor("db-recommendations", "db", vpa_types.UpdateModeOff),
+	}
+
+	testCases := []struct {
+		name              string
+		vpa               *vpa_types.VerticalPodAutoscaler
+		rejectOverlapping bool
+		wantError         bool
+	}{
+		{
+			name:              "overlapping VPA",
+			vpa:               vpaFor("web-canary", "web-canary", vpa_types.UpdateModeAuto),
+			rejectOverlapping: true,
+			wantError:         true,
+		},
+		{
+			name: "overlapping VPA without the check",
+			vpa:  vpaFor("web-canary", "web-canary", vpa_types.UpdateModeAuto),
+		},
+		{
+			name:              "overlapping VPA in Off mode",
+			vpa:               vpaFor("web-canary", "web-canary", vpa_types.UpdateModeOff),
+			rejectOverlapping: true,
+		},
+		{
+			name:              "the same VPA",
+			vpa:               vpaFor("web", "web", vpa_types.UpdateModeRecreate),
+			rejectOverlapping: true,
+		},
+		{
+			name:              "overlapping only with VPA in Off mode",
+			vpa:               vpaFor("db-replica", "db-replica", vpa_types.UpdateModeAuto),
+			rejectOverlapping: true,
+		},
+		{
+			name:      "target managed by another system",
+			vpa:       vpaFor("db", "db", vpa_types.UpdateModeAuto),
+			wantError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vpaNamespaceLister := &test.VerticalPodAutoscalerListerMock{}
+			vpaNamespaceLister.On("List").Return(existing, nil)
+			vpaLister := &test.VerticalPodAutoscalerListerMock{}
+			vpaLister.On("VerticalPodAutoscalers", "default").Return(vpaNamespaceLister)
+
+			checker := NewConflictChecker(vpaLister, selectors, annotations, tc.rejectOverlapping, []string{"example.com/managed-resources"})
+			err := checker.CheckConflicts(tc.vpa)
+			if tc.wantError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNeedsConflictCheck(t *testing.T) {
+	targetRef := &autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "web", APIVersion: "apps/v1"}
+	vpa := test.VerticalPodAutoscaler().WithName("web").WithContainer("c").WithTargetRef(targetRef)
+	raw := func(vpa *vpa_types.VerticalPodAutoscaler) runtime.RawExtension {
+		bytes, err := json.Marshal(vpa)
+		assert.NoError(t, err)
+		return runtime.RawExtension{Raw: bytes}
+	}
+	otherTarget := vpa.WithTargetRef(&autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "api", APIVersion: "apps/v1"}).Get()
+
+	testCases := []struct {
+		name string
+		ar   *v1.AdmissionRequest
+		vpa  *vpa_types.VerticalPodAutoscaler
+		want bool
+	}{
+		{
+			name: "create",
+			ar:   &v1.AdmissionRequest{Operation: v1.Create},
+			vpa:  vpa.Get(),
+			want: true,
+		},
+		{
+			name: "unchanged target",
+			ar:   &v1.AdmissionRequest{Operation: v1.Update, OldObject: raw(vpa.WithUpdateMode(vpa_types.UpdateModeAuto).Get())},
+			vpa:  vpa.WithUpdateMode(vpa_types.UpdateModeRecreate).Get(),
+			want: false,
+		},
+		{
+			name: "changed target",
+			ar:   &v1.AdmissionRequest{Operation: v1.Update, OldObject: raw(vpa.Get())},
+			vpa:  otherTarget,
+			want: true,
+		},
+		{
+			name: "starts actuating",
+			ar:   &v1.AdmissionRequest{Operation: v1.Update, OldObject: raw(vpa.WithUpdateMode(vpa_types.UpdateModeOff).Get())},
+			vpa:  vpa.WithUpdateMode(vpa_types.UpdateModeInitial).Get(),
+			want: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := needsConflictCheck(tc.ar, tc.vpa)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -22,12 +22,14 @@ import (
 
 	v1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apires "k8s.io/apimachinery/pkg/api/resource"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/admission-controller/resource"
 	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/metrics/admission"
+	vpa_api_util "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/vpa"
 	"k8s.io/klog/v2"
 )
 
@@ -35,24 +37,42 @@ var (
 	possibleUpdateModes = map[vpa_types.UpdateMode]interface{}{
 		vpa_types.UpdateModeOff:      struct{}{},
 		vpa_types.UpdateModeInitial:  struct{}{},
+		vpa_types.UpdateModeInPlace:  struct{}{},
 		vpa_types.UpdateModeRecreate: struct{}{},
 		vpa_types.UpdateModeAuto:     struct{}{},
 	}
 
+	possibleJobUpdateModes = map[vpa_types.JobUpdateMode]interface{}{
+		vpa_types.JobUpdateModeFrozen: struct{}{},
+		vpa_types.JobUpdateModeOff:    struct{}{},
+	}
+
 	possibleScalingModes = map[vpa_types.ContainerScalingMode]interface{}{
 		vpa_types.ContainerScalingModeAuto: struct{}{},
 		vpa_types.ContainerScalingModeOff:  struct{}{},
 	}
+
+	possiblePodLevelScalingModes = map[vpa_types.PodLevelScalingMode]interface{}{
+		vpa_types.PodLevelScalingModeAuto: struct{}{},
+		vpa_types.PodLevelScalingModeOff:  struct{}{},
+	}
+
+	possibleContainerSplitPolicies = map[vpa_types.ContainerSplitPolicy]interface{}{
+		vpa_types.ContainerSplitPolicyPerContainer:           struct{}{},
+		vpa_types.ContainerSplitPolicyProportionalToRequests: struct{}{},
+	}
 )
 
 // resourceHandler builds patches for VPAs.
 type resourceHandler struct {
-	preProcessor PreProcessor
+	preProcessor    PreProcessor
+	conflictChecker ConflictChecker
 }
 
-// NewResourceHandler creates new instance of resourceHandler.
-func NewResourceHandler(preProcessor PreProcessor) resource.Handler {
-	return &resourceHandler{preProcessor: preProcessor}
+// NewResourceHandler creates new instance of resourceHandler. conflictChecker
+// may be nil, in which case VPAs aren't checked for conflicts.
+func NewResourceHandler(preProcessor PreProcessor, conflictChecker ConflictChecker) resource.Handler {
+	return &resourceHandler{preProcessor: preProcessor, conflictChecker: conflictChecker}
 }
 
 // AdmissionResource returns resource type this handler accepts.
@@ -88,6 +108,18 @@ func (h *resourceHandler) GetPatches(ar *v1.AdmissionRequest) ([]resource.PatchR
 		return nil, err
 	}
 
+	if h.conflictChecker != nil {
+		needsCheck, err := needsConflictCheck(ar, vpa)
+		if err != nil {
+			return nil, err
+		}
+		if needsCheck {
+			if err := h.conflictChecker.CheckConflicts(vpa); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	klog.V(4).Infof("Processing vpa: %v", vpa)
 	patches := []resource.PatchRecord{}
 	if vpa.Spec.UpdatePolicy == nil {
@@ -109,6 +141,22 @@ func parseVPA(raw []byte) (*vpa_types.VerticalPodAutoscaler, error) {
 	return &vpa, nil
 }
 
+// needsConflictCheck returns whether the admitted VPA may have started to
+// conflict with other VPAs or actuation systems. Updates which don't change the
+// target or start actuating recommendations are let in, so that VPAs which
+// conflicted before the check was enabled can still be edited.
+func needsConflictCheck(ar *v1.AdmissionRequest, vpa *vpa_types.VerticalPodAutoscaler) (bool, error) {
+	if ar.Operation == v1.Create || len(ar.OldObject.Raw) == 0 {
+		return true, nil
+	}
+	oldVpa, err := parseVPA(ar.OldObject.Raw)
+	if err != nil {
+		return false, err
+	}
+	startsActuating := vpa_api_util.GetMaxUpdateMode(oldVpa) == vpa_types.UpdateModeOff && vpa_api_util.GetMaxUpdateMode(vpa) != vpa_types.UpdateModeOff
+	return startsActuating || !apiequality.Semantic.DeepEqual(oldVpa.Spec.TargetRef, vpa.Spec.TargetRef), nil
+}
+
 // ValidateVPA checks the correctness of VPA Spec and returns an error if there is a problem.
 func ValidateVPA(vpa *vpa_types.VerticalPodAutoscaler, isCreate bool) error {
 	if vpa.Spec.UpdatePolicy != nil {
@@ -120,6 +168,26 @@ func ValidateVPA(vpa *vpa_types.VerticalPodAutoscaler, isCreate bool) error {
 			return fmt.Errorf("unexpected UpdateMode value %s", *mode)
 		}
 
+		resources := make(map[corev1.ResourceName]bool)
+		for _, resourceMode := range vpa.Spec.UpdatePolicy.ResourceUpdateModes {
+			if resourceMode.Resource != corev1.ResourceCPU && resourceMode.Resource != corev1.ResourceMemory {
+				return fmt.Errorf("unexpected ResourceUpdateModes resource %s", resourceMode.Resource)
+			}
+			if resources[resourceMode.Resource] {
+				return fmt.Errorf("ResourceUpdateModes contain resource %s more than once", resourceMode.Resource)
+			}
+			resources[resourceMode.Resource] = true
+			if _, found := possibleUpdateModes[resourceMode.UpdateMode]; !found {
+				return fmt.Errorf("unexpected ResourceUpdateModes value %s for resource %s", resourceMode.UpdateMode, resourceMode.Resource)
+			}
+		}
+
+		if jobMode := vpa.Spec.UpdatePolicy.JobUpdateMode; jobMode != nil {
+			if _, found := possibleJobUpdateModes[*jobMode]; !found {
+				return fmt.Errorf("unexpected JobUpdateMode value %s", *jobMode)
+			}
+		}
+
 		if minReplicas := vpa.Spec.UpdatePolicy.MinReplicas; minReplicas != nil && *minReplicas <= 0 {
 			return fmt.Errorf("MinReplicas has to be positive, got %v", *minReplicas)
 		}
@@ -151,6 +219,14 @@ func ValidateVPA(vpa *vpa_types.VerticalPodAutoscaler, isCreate bool) error {
 					return fmt.Errorf("MaxAllowed: %v", err)
 				}
 			}
+			if granularity := policy.CPUGranularity; granularity != nil {
+				if granularity.Sign() <= 0 {
+					return fmt.Errorf("CPUGranularity has to be positive, got %v", granularity)
+				}
+				if err := validateCPUResolution(*granularity); err != nil {
+					return fmt.Errorf("CPUGranularity: %v", err)
+				}
+			}
 			ControlledValues := policy.ControlledValues
 			if mode != nil && ControlledValues != nil {
 				if *mode == vpa_types.ContainerScalingModeOff && *ControlledValues == vpa_types.ContainerControlledValuesRequestsAndLimits {
@@ -158,6 +234,18 @@ func ValidateVPA(vpa *vpa_types.VerticalPodAutoscaler, isCreate bool) error {
 				}
 			}
 		}
+		if podLevelPolicy := vpa.Spec.ResourcePolicy.PodLevelPolicy; podLevelPolicy != nil {
+			if mode := podLevelPolicy.Mode; mode != nil {
+				if _, found := possiblePodLevelScalingModes[*mode]; !found {
+					return fmt.Errorf("unexpected PodLevelPolicy.Mode value %s", *mode)
+				}
+			}
+			if splitPolicy := podLevelPolicy.ContainerSplitPolicy; splitPolicy != nil {
+				if _, found := possibleContainerSplitPolicies[*splitPolicy]; !found {
+					return fmt.Errorf("unexpected PodLevelPolicy.ContainerSplitPolicy value %s", *splitPolicy)
+				}
+			}
+		}
 	}
 
 	if isCreate && vpa.Spec.TargetRef == nil {
@@ -41,6 +41,11 @@ func TestValidateVPA(t *testing.T) {
 	validScalingMode := vpa_types.ContainerScalingModeAuto
 	scalingModeOff := vpa_types.ContainerScalingModeOff
 	controlledValuesRequestsAndLimits := vpa_types.ContainerControlledValuesRequestsAndLimits
+	badJobUpdateMode := vpa_types.JobUpdateMode("bad")
+	badPodLevelScalingMode := vpa_types.PodLevelScalingMode("bad")
+	badContainerSplitPolicy := vpa_types.ContainerSplitPolicy("bad")
+	podLevelScalingModeAuto := vpa_types.PodLevelScalingModeAuto
+	containerSplitPolicyProportional := vpa_types.ContainerSplitPolicyProportionalToRequests
 	tests := []struct {
 		name        string
 		vpa         vpa_types.VerticalPodAutoscaler
@@ -57,6 +62,41 @@ func TestValidateVPA(t *testing.T) {
 			isCreate:    true,
 			expectError: fmt.Errorf("TargetRef is required. If you're using v1beta1 version of the API, please migrate to v1"),
 		},
+		{
+			name: "bad pod-level scaling mode",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					ResourcePolicy: &vpa_types.PodResourcePolicy{
+						PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{Mode: &badPodLevelScalingMode},
+					},
+				},
+			},
+			expectError: fmt.Errorf("unexpected PodLevelPolicy.Mode value bad"),
+		},
+		{
+			name: "bad container split policy",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					ResourcePolicy: &vpa_types.PodResourcePolicy{
+						PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{ContainerSplitPolicy: &badContainerSplitPolicy},
+					},
+				},
+			},
+			expectError: fmt.Errorf("unexpected PodLevelPolicy.ContainerSplitPolicy value bad"),
+		},
+		{
+			name: "valid pod-level policy",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					ResourcePolicy: &vpa_types.PodResourcePolicy{
+						PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{
+							Mode:                 &podLevelScalingModeAuto,
+							ContainerSplitPolicy: &containerSplitPolicyProportional,
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "no update mode",
 			vpa: vpa_types.VerticalPodAutoscaler{
@@ -77,6 +117,57 @@ func TestValidateVPA(t *testing.T) {
 			},
 			expectError: fmt.Errorf("unexpected UpdateMode value bad"),
 		},
+		{
+			name: "bad job update mode",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					UpdatePolicy: &vpa_types.PodUpdatePolicy{
+						UpdateMode:    &validUpdateMode,
+						JobUpdateMode: &badJobUpdateMode,
+					},
+				},
+			},
+			expectError: fmt.Errorf("unexpected JobUpdateMode value bad"),
+		},
+		{
+			name: "bad resource update mode",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					UpdatePolicy: &vpa_types.PodUpdatePolicy{
+						UpdateMode:          &validUpdateMode,
+						ResourceUpdateModes: []vpa_types.ResourceUpdateMode{{Resource: apiv1.ResourceMemory, UpdateMode: badUpdateMode}},
+					},
+				},
+			},
+			expectError: fmt.Errorf("unexpected ResourceUpdateModes value bad for resource memory"),
+		},
+		{
+			name: "resource update mode of unsupported resource",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					UpdatePolicy: &vpa_types.PodUpdatePolicy{
+						UpdateMode:          &validUpdateMode,
+						ResourceUpdateModes: []vpa_types.ResourceUpdateMode{{Resource: apiv1.ResourceStorage, UpdateMode: validUpdateMode}},
+					},
+				},
+			},
+			expectError: fmt.Errorf("unexpected ResourceUpdateModes resource storage"),
+		},
+		{
+			name: "duplicate resource update mode",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					UpdatePolicy: &vpa_types.PodUpdatePolicy{
+						UpdateMode: &validUpdateMode,
+						ResourceUpdateModes: []vpa_types.ResourceUpdateMode{
+							{Resource: apiv1.ResourceCPU, UpdateMode: validUpdateMode},
+							{Resource: apiv1.ResourceCPU, UpdateMode: validUpdateMode},
+						},
+					},
+				},
+			},
+			expectError: fmt.Errorf("ResourceUpdateModes contain resource cpu more than once"),
+		},
 		{
 			name: "zero minReplicas",
 			vpa: vpa_types.VerticalPodAutoscaler{
@@ -215,6 +306,38 @@ func TestValidateVPA(t *testing.T) {
 			},
 			expectError: fmt.Errorf("MaxAllowed: CPU [%s] must be a whole number of milli CPUs", badCPUResource.String()),
 		},
+		{
+			name: "non-positive cpuGranularity",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					ResourcePolicy: &vpa_types.PodResourcePolicy{
+						ContainerPolicies: []vpa_types.ContainerResourcePolicy{
+							{
+								ContainerName:  "loot box",
+								CPUGranularity: resource.NewQuantity(0, resource.DecimalSI),
+							},
+						},
+					},
+				},
+			},
+			expectError: fmt.Errorf("CPUGranularity has to be positive, got 0"),
+		},
+		{
+			name: "bad cpuGranularity value",
+			vpa: vpa_types.VerticalPodAutoscaler{
+				Spec: vpa_types.VerticalPodAutoscalerSpec{
+					ResourcePolicy: &vpa_types.PodResourcePolicy{
+						ContainerPolicies: []vpa_types.ContainerResourcePolicy{
+							{
+								ContainerName:  "loot box",
+								CPUGranularity: &badCPUResource,
+							},
+						},
+					},
+				},
+			},
+			expectError: fmt.Errorf("CPUGranularity: CPU [%s] must be a whole number of milli CPUs", badCPUResource.String()),
+		},
 		{
 			name: "bad maxAllowed memory value",
 			vpa: vpa_types.VerticalPodAutoscaler{
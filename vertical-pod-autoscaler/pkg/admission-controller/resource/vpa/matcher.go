@@ -57,7 +57,7 @@ func (m *matcher) GetMatchingVPA(pod *core.Pod) *vpa_types.VerticalPodAutoscaler
 	}
 	onConfigs := make([]*vpa_api_util.VpaWithSelector, 0)
 	for _, vpaConfig := range configs {
-		if vpa_api_util.GetUpdateMode(vpaConfig) == vpa_types.UpdateModeOff {
+		if vpa_api_util.GetPodUpdateMode(vpaConfig, pod) == vpa_types.UpdateModeOff {
 			continue
 		}
 		selector, err := m.selectorFetcher.Fetch(vpaConfig)
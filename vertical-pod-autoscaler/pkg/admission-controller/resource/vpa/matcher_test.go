@@ -60,6 +60,14 @@ func TestGetMatchingVpa(t *testing.T) {
 		AddContainer(test.Container().WithName("i-am-container").Get())
 	podBuilder := podBuilderWithoutCreator.WithCreator(&sts.ObjectMeta, &sts.TypeMeta)
 	vpaBuilder := test.VerticalPodAutoscaler().WithContainer("i-am-container")
+	isController := true
+	jobPod := podBuilderWithoutCreator.Get()
+	jobPod.OwnerReferences = []meta.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "job", Controller: &isController}}
+	jobTargetRef := &v1.CrossVersionObjectReference{
+		Kind:       "Job",
+		Name:       "job",
+		APIVersion: "batch/v1",
+	}
 	testCases := []struct {
 		name            string
 		pod             *core.Pod
@@ -120,6 +128,25 @@ func TestGetMatchingVpa(t *testing.T) {
 			labelSelector:   "app = test",
 			expectedFound:   true,
 			expectedVpaName: "initial-vpa",
+		}, {
+			name: "frozen job pod",
+			pod:  jobPod,
+			vpas: []*vpa_types.VerticalPodAutoscaler{
+				test.VerticalPodAutoscaler().WithContainer("i-am-container").WithUpdateMode(vpa_types.UpdateModeOff).
+					WithJobUpdateMode(vpa_types.JobUpdateModeFrozen).WithName("frozen-vpa").WithTargetRef(jobTargetRef).Get(),
+			},
+			labelSelector:   "app = test",
+			expectedFound:   true,
+			expectedVpaName: "frozen-vpa",
+		}, {
+			name: "job pod with job update mode off",
+			pod:  jobPod,
+			vpas: []*vpa_types.VerticalPodAutoscaler{
+				test.VerticalPodAutoscaler().WithContainer("i-am-container").WithUpdateMode(vpa_types.UpdateModeAuto).
+					WithJobUpdateMode(vpa_types.JobUpdateModeOff).WithName("auto-vpa").WithTargetRef(jobTargetRef).Get(),
+			},
+			labelSelector: "app = test",
+			expectedFound: false,
 		}, {
 			name:          "no vpa objects",
 			pod:           podBuilder.Get(),
@@ -20,6 +20,7 @@ package v1
 import (
 	autoscaling "k8s.io/api/autoscaling/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -148,10 +149,30 @@ type PodUpdatePolicy struct {
 	// EvictionRequirement is specified, all of them need to be fulfilled to allow eviction.
 	// +optional
 	EvictionRequirements []*EvictionRequirement `json:"evictionRequirements,omitempty" protobuf:"bytes,3,opt,name=evictionRequirements"`
+
+	// Controls how autoscaler applies changes to the resources of pods of Jobs,
+	// including Jobs created by CronJobs. If not specified, pods of Jobs are
+	// updated according to updateMode.
+	// +optional
+	JobUpdateMode *JobUpdateMode `json:"jobUpdateMode,omitempty" protobuf:"bytes,4,opt,name=jobUpdateMode"`
+
+	// ResourceUpdateModes override updateMode for individual resources, e.g.
+	// to only assign memory on pod creation while CPU is updated during the
+	// lifetime of the pod. Resources which aren't listed use updateMode.
+	// +optional
+	ResourceUpdateModes []ResourceUpdateMode `json:"resourceUpdateModes,omitempty" protobuf:"bytes,5,rep,name=resourceUpdateModes"`
+}
+
+// ResourceUpdateMode is the update mode of a single resource.
+type ResourceUpdateMode struct {
+	// Resource is the name of the resource, either "cpu" or "memory".
+	Resource v1.ResourceName `json:"resource" protobuf:"bytes,1,name=resource"`
+	// UpdateMode controls when autoscaler applies changes to the resource.
+	UpdateMode UpdateMode `json:"updateMode" protobuf:"bytes,2,name=updateMode"`
 }
 
 // UpdateMode controls when autoscaler applies changes to the pod resources.
-// +kubebuilder:validation:Enum=Off;Initial;Recreate;Auto
+// +kubebuilder:validation:Enum=Off;Initial;InPlace;Recreate;Auto
 type UpdateMode string
 
 const (
@@ -162,6 +183,13 @@ const (
 	// UpdateModeInitial means that autoscaler only assigns resources on pod
 	// creation and does not change them during the lifetime of the pod.
 	UpdateModeInitial UpdateMode = "Initial"
+	// UpdateModeInPlace means that autoscaler assigns resources on pod
+	// creation and additionally can update them during the lifetime of the
+	// pod using in-place pod resize, without restarting containers. Pods
+	// whose containerResizePolicy requires a restart to resize a resource,
+	// or whose resize is infeasible, are updated by deleting and recreating
+	// the pod.
+	UpdateModeInPlace UpdateMode = "InPlace"
 	// UpdateModeRecreate means that autoscaler assigns resources on pod
 	// creation and additionally can update them during the lifetime of the
 	// pod by deleting and recreating the pod.
@@ -173,6 +201,20 @@ const (
 	UpdateModeAuto UpdateMode = "Auto"
 )
 
+// JobUpdateMode controls how autoscaler applies changes to the resources of pods of Jobs.
+// +kubebuilder:validation:Enum=Frozen;Off
+type JobUpdateMode string
+
+const (
+	// JobUpdateModeFrozen means that autoscaler assigns resources to pods of
+	// Jobs on creation and freezes them for the lifetime of the pod, i.e. the
+	// pods are never evicted to apply newer recommendations, even if updateMode
+	// is "Off". This right-sizes batch pods without disrupting their runs.
+	JobUpdateModeFrozen JobUpdateMode = "Frozen"
+	// JobUpdateModeOff means that autoscaler never changes resources of pods of Jobs.
+	JobUpdateModeOff JobUpdateMode = "Off"
+)
+
 // PodResourcePolicy controls how autoscaler computes the recommended resources
 // for containers belonging to the pod. There can be at most one entry for every
 // named container and optionally a single wildcard entry with `containerName` = '*',
@@ -183,8 +225,57 @@ type PodResourcePolicy struct {
 	// +patchMergeKey=containerName
 	// +patchStrategy=merge
 	ContainerPolicies []ContainerResourcePolicy `json:"containerPolicies,omitempty" patchStrategy:"merge" patchMergeKey:"containerName" protobuf:"bytes,1,rep,name=containerPolicies"`
+
+	// Controls the pod-level (workload-level) recommendation, aggregated
+	// over all containers of the pod.
+	// If not specified, no pod-level recommendation is computed.
+	// +optional
+	PodLevelPolicy *PodLevelResourcePolicy `json:"podLevelPolicy,omitempty" protobuf:"bytes,2,opt,name=podLevelPolicy"`
 }
 
+// PodLevelResourcePolicy controls how autoscaler computes and applies the
+// pod-level recommendation, i.e. the sum of recommendations for all
+// containers in the pod. The pod-level recommendation is applied to the
+// pod-level resources (`spec.resources`), supported by new Kubernetes versions.
+type PodLevelResourcePolicy struct {
+	// Whether the pod-level recommendation is computed and applied.
+	// The default is "Off".
+	// +optional
+	Mode *PodLevelScalingMode `json:"mode,omitempty" protobuf:"bytes,1,opt,name=mode"`
+	// Specifies how the pod-level recommendation is split between containers.
+	// The default is "PerContainer".
+	// +optional
+	ContainerSplitPolicy *ContainerSplitPolicy `json:"containerSplitPolicy,omitempty" protobuf:"bytes,2,opt,name=containerSplitPolicy"`
+}
+
+// PodLevelScalingMode controls whether autoscaler computes and applies the
+// pod-level recommendation.
+// +kubebuilder:validation:Enum=Auto;Off
+type PodLevelScalingMode string
+
+const (
+	// PodLevelScalingModeAuto means the pod-level recommendation is computed
+	// and applied to the pod-level resources.
+	PodLevelScalingModeAuto PodLevelScalingMode = "Auto"
+	// PodLevelScalingModeOff means the pod-level recommendation is not computed.
+	PodLevelScalingModeOff PodLevelScalingMode = "Off"
+)
+
+// ContainerSplitPolicy controls how the pod-level recommendation is split
+// between containers.
+// +kubebuilder:validation:Enum=PerContainer;ProportionalToRequests
+type ContainerSplitPolicy string
+
+const (
+	// ContainerSplitPolicyPerContainer means each container gets its own
+	// recommendation.
+	ContainerSplitPolicyPerContainer ContainerSplitPolicy = "PerContainer"
+	// ContainerSplitPolicyProportionalToRequests means the pod-level
+	// recommendation is split between containers proportionally to their
+	// current requests, keeping the ratios set in the pod spec.
+	ContainerSplitPolicyProportionalToRequests ContainerSplitPolicy = "ProportionalToRequests"
+)
+
 // ContainerResourcePolicy controls how autoscaler computes the recommended
 // resources for a specific container.
 type ContainerResourcePolicy struct {
@@ -207,12 +298,21 @@ type ContainerResourcePolicy struct {
 	// Specifies the type of recommendations that will be computed
 	// (and possibly applied) by VPA.
 	// If not specified, the default of [ResourceCPU, ResourceMemory] will be used.
+	// Besides CPU and memory, hugepages and extended resources (e.g. advertised by
+	// device plugins) can be controlled, if their usage is provided to the recommender.
 	ControlledResources *[]v1.ResourceName `json:"controlledResources,omitempty" patchStrategy:"merge" protobuf:"bytes,5,rep,name=controlledResources"`
 
 	// Specifies which resource values should be controlled.
 	// The default is "RequestsAndLimits".
 	// +optional
 	ControlledValues *ContainerControlledValues `json:"controlledValues,omitempty" protobuf:"bytes,6,rep,name=controlledValues"`
+
+	// CPUGranularity rounds CPU recommendations up to a multiple of the given
+	// quantity, e.g. "100m", or "1" for whole cores as needed by containers of
+	// pods using the static CPU manager policy. Recommendations are still
+	// capped to maxAllowed after rounding. The default is no rounding.
+	// +optional
+	CPUGranularity *resource.Quantity `json:"cpuGranularity,omitempty" protobuf:"bytes,7,opt,name=cpuGranularity"`
 }
 
 const (
@@ -267,6 +367,23 @@ type RecommendedPodResources struct {
 	// Resources recommended by the autoscaler for each container.
 	// +optional
 	ContainerRecommendations []RecommendedContainerResources `json:"containerRecommendations,omitempty" protobuf:"bytes,1,rep,name=containerRecommendations"`
+	// Resources recommended by the autoscaler for the whole pod. Only set if
+	// the pod-level policy mode is "Auto".
+	// +optional
+	PodRecommendation *RecommendedPodLevelResources `json:"podRecommendation,omitempty" protobuf:"bytes,2,opt,name=podRecommendation"`
+}
+
+// RecommendedPodLevelResources is the pod-level recommendation of resources
+// computed by autoscaler, aggregated over all containers with a recommendation.
+type RecommendedPodLevelResources struct {
+	// Recommended amount of resources for the pod.
+	Target v1.ResourceList `json:"target" protobuf:"bytes,1,rep,name=target,casttype=ResourceList,castkey=ResourceName"`
+	// Minimum recommended amount of resources for the pod.
+	// +optional
+	LowerBound v1.ResourceList `json:"lowerBound,omitempty" protobuf:"bytes,2,rep,name=lowerBound,casttype=ResourceList,castkey=ResourceName"`
+	// Maximum recommended amount of resources for the pod.
+	// +optional
+	UpperBound v1.ResourceList `json:"upperBound,omitempty" protobuf:"bytes,3,rep,name=upperBound,casttype=ResourceList,castkey=ResourceName"`
 }
 
 // RecommendedContainerResources is the recommendation of resources computed by
@@ -396,6 +513,11 @@ type VerticalPodAutoscalerCheckpointStatus struct {
 	// Checkpoint of histogram for consumption of memory.
 	MemoryHistogram HistogramCheckpoint `json:"memoryHistogram,omitempty" protobuf:"bytes,4,rep,name=memoryHistogram"`
 
+	// Checkpoints of histograms for consumption of hugepages and extended resources,
+	// keyed by resource name.
+	// +optional
+	ExtendedResourceHistograms map[v1.ResourceName]HistogramCheckpoint `json:"extendedResourceHistograms,omitempty" protobuf:"bytes,8,rep,name=extendedResourceHistograms"`
+
 	// Timestamp of the fist sample from the histograms.
 	// +nullable
 	FirstSampleStart metav1.Time `json:"firstSampleStart,omitempty" protobuf:"bytes,5,opt,name=firstSampleStart"`
@@ -63,6 +63,11 @@ func (in *ContainerResourcePolicy) DeepCopyInto(out *ContainerResourcePolicy) {
 		*out = new(ContainerControlledValues)
 		**out = **in
 	}
+	if in.CPUGranularity != nil {
+		in, out := &in.CPUGranularity, &out.CPUGranularity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 	return
 }
 
@@ -100,6 +105,32 @@ func (in *HistogramCheckpoint) DeepCopy() *HistogramCheckpoint {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodLevelResourcePolicy) DeepCopyInto(out *PodLevelResourcePolicy) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(PodLevelScalingMode)
+		**out = **in
+	}
+	if in.ContainerSplitPolicy != nil {
+		in, out := &in.ContainerSplitPolicy, &out.ContainerSplitPolicy
+		*out = new(ContainerSplitPolicy)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodLevelResourcePolicy.
+func (in *PodLevelResourcePolicy) DeepCopy() *PodLevelResourcePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PodLevelResourcePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodResourcePolicy) DeepCopyInto(out *PodResourcePolicy) {
 	*out = *in
@@ -110,6 +141,11 @@ func (in *PodResourcePolicy) DeepCopyInto(out *PodResourcePolicy) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PodLevelPolicy != nil {
+		in, out := &in.PodLevelPolicy, &out.PodLevelPolicy
+		*out = new(PodLevelResourcePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -136,6 +172,16 @@ func (in *PodUpdatePolicy) DeepCopyInto(out *PodUpdatePolicy) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.JobUpdateMode != nil {
+		in, out := &in.JobUpdateMode, &out.JobUpdateMode
+		*out = new(JobUpdateMode)
+		**out = **in
+	}
+	if in.ResourceUpdateModes != nil {
+		in, out := &in.ResourceUpdateModes, &out.ResourceUpdateModes
+		*out = make([]ResourceUpdateMode, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -193,6 +239,43 @@ func (in *RecommendedContainerResources) DeepCopy() *RecommendedContainerResourc
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecommendedPodLevelResources) DeepCopyInto(out *RecommendedPodLevelResources) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.LowerBound != nil {
+		in, out := &in.LowerBound, &out.LowerBound
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.UpperBound != nil {
+		in, out := &in.UpperBound, &out.UpperBound
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecommendedPodLevelResources.
+func (in *RecommendedPodLevelResources) DeepCopy() *RecommendedPodLevelResources {
+	if in == nil {
+		return nil
+	}
+	out := new(RecommendedPodLevelResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RecommendedPodResources) DeepCopyInto(out *RecommendedPodResources) {
 	*out = *in
@@ -203,6 +286,11 @@ func (in *RecommendedPodResources) DeepCopyInto(out *RecommendedPodResources) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PodRecommendation != nil {
+		in, out := &in.PodRecommendation, &out.PodRecommendation
+		*out = new(RecommendedPodLevelResources)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -216,6 +304,22 @@ func (in *RecommendedPodResources) DeepCopy() *RecommendedPodResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceUpdateMode) DeepCopyInto(out *ResourceUpdateMode) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceUpdateMode.
+func (in *ResourceUpdateMode) DeepCopy() *ResourceUpdateMode {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceUpdateMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VerticalPodAutoscaler) DeepCopyInto(out *VerticalPodAutoscaler) {
 	*out = *in
@@ -327,6 +431,13 @@ func (in *VerticalPodAutoscalerCheckpointStatus) DeepCopyInto(out *VerticalPodAu
 	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	in.CPUHistogram.DeepCopyInto(&out.CPUHistogram)
 	in.MemoryHistogram.DeepCopyInto(&out.MemoryHistogram)
+	if in.ExtendedResourceHistograms != nil {
+		in, out := &in.ExtendedResourceHistograms, &out.ExtendedResourceHistograms
+		*out = make(map[corev1.ResourceName]HistogramCheckpoint, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	in.FirstSampleStart.DeepCopyInto(&out.FirstSampleStart)
 	in.LastSampleStart.DeepCopyInto(&out.LastSampleStart)
 	return
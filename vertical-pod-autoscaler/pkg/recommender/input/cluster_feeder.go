@@ -87,22 +87,26 @@ type ClusterStateFeederFactory struct {
 	MemorySaveMode      bool
 	ControllerFetcher   controllerfetcher.ControllerFetcher
 	RecommenderName     string
+	// AdditionalRecommenderNames are names of other recommenders served by
+	// this recommender. VPA objects selecting any of them are loaded too.
+	AdditionalRecommenderNames []string
 }
 
 // Make creates new ClusterStateFeeder with internal data providers, based on kube client.
 func (m ClusterStateFeederFactory) Make() *clusterStateFeeder {
 	return &clusterStateFeeder{
-		coreClient:          m.KubeClient.CoreV1(),
-		metricsClient:       m.MetricsClient,
-		oomChan:             m.OOMObserver.GetObservedOomsChannel(),
-		vpaCheckpointClient: m.VpaCheckpointClient,
-		vpaLister:           m.VpaLister,
-		clusterState:        m.ClusterState,
-		specClient:          spec.NewSpecClient(m.PodLister),
-		selectorFetcher:     m.SelectorFetcher,
-		memorySaveMode:      m.MemorySaveMode,
-		controllerFetcher:   m.ControllerFetcher,
-		recommenderName:     m.RecommenderName,
+		coreClient:                 m.KubeClient.CoreV1(),
+		metricsClient:              m.MetricsClient,
+		oomChan:                    m.OOMObserver.GetObservedOomsChannel(),
+		vpaCheckpointClient:        m.VpaCheckpointClient,
+		vpaLister:                  m.VpaLister,
+		clusterState:               m.ClusterState,
+		specClient:                 spec.NewSpecClient(m.PodLister),
+		selectorFetcher:            m.SelectorFetcher,
+		memorySaveMode:             m.MemorySaveMode,
+		controllerFetcher:          m.ControllerFetcher,
+		recommenderName:            m.RecommenderName,
+		additionalRecommenderNames: m.AdditionalRecommenderNames,
 	}
 }
 
@@ -181,17 +185,18 @@ func NewPodListerAndOOMObserver(kubeClient kube_client.Interface, namespace stri
 }
 
 type clusterStateFeeder struct {
-	coreClient          corev1.CoreV1Interface
-	specClient          spec.SpecClient
-	metricsClient       metrics.MetricsClient
-	oomChan             <-chan oom.OomInfo
-	vpaCheckpointClient vpa_api.VerticalPodAutoscalerCheckpointsGetter
-	vpaLister           vpa_lister.VerticalPodAutoscalerLister
-	clusterState        *model.ClusterState
-	selectorFetcher     target.VpaTargetSelectorFetcher
-	memorySaveMode      bool
-	controllerFetcher   controllerfetcher.ControllerFetcher
-	recommenderName     string
+	coreClient                 corev1.CoreV1Interface
+	specClient                 spec.SpecClient
+	metricsClient              metrics.MetricsClient
+	oomChan                    <-chan oom.OomInfo
+	vpaCheckpointClient        vpa_api.VerticalPodAutoscalerCheckpointsGetter
+	vpaLister                  vpa_lister.VerticalPodAutoscalerLister
+	clusterState               *model.ClusterState
+	selectorFetcher            target.VpaTargetSelectorFetcher
+	memorySaveMode             bool
+	controllerFetcher          controllerfetcher.ControllerFetcher
+	recommenderName            string
+	additionalRecommenderNames []string
 }
 
 func (feeder *clusterStateFeeder) InitFromHistoryProvider(historyProvider history.HistoryProvider) {
@@ -312,11 +317,24 @@ func selectsRecommender(selectors []*vpa_types.VerticalPodAutoscalerRecommenderS
 	return false
 }
 
+func selectsAnyRecommender(selectors []*vpa_types.VerticalPodAutoscalerRecommenderSelector, names []string) bool {
+	for i := range names {
+		if selectsRecommender(selectors, &names[i]) {
+			return true
+		}
+	}
+	return false
+}
+
 // Filter VPA objects whose specified recommender names are not default
 func filterVPAs(feeder *clusterStateFeeder, allVpaCRDs []*vpa_types.VerticalPodAutoscaler) []*vpa_types.VerticalPodAutoscaler {
 	klog.V(3).Infof("Start selecting the vpaCRDs.")
 	var vpaCRDs []*vpa_types.VerticalPodAutoscaler
 	for _, vpaCRD := range allVpaCRDs {
+		if selectsAnyRecommender(vpaCRD.Spec.Recommenders, feeder.additionalRecommenderNames) {
+			vpaCRDs = append(vpaCRDs, vpaCRD)
+			continue
+		}
 		if feeder.recommenderName == DefaultRecommenderName {
 			if !implicitDefaultRecommender(vpaCRD.Spec.Recommenders) && !selectsRecommender(vpaCRD.Spec.Recommenders, &feeder.recommenderName) {
 				klog.V(6).Infof("Ignoring vpaCRD %s as current recommender's name %v doesn't appear among its recommenders", klog.KObj(vpaCRD), feeder.recommenderName)
@@ -568,3 +568,27 @@ func TestFilterVPAs(t *testing.T) {
 
 	assert.ElementsMatch(t, expectedResult, result)
 }
+
+func TestFilterVPAsAdditionalRecommenders(t *testing.T) {
+	vpaSelecting := func(names ...string) *vpa_types.VerticalPodAutoscaler {
+		vpa := &vpa_types.VerticalPodAutoscaler{}
+		for _, name := range names {
+			vpa.Spec.Recommenders = append(vpa.Spec.Recommenders, &vpa_types.VerticalPodAutoscalerRecommenderSelector{Name: name})
+		}
+		return vpa
+	}
+	implicitDefault := vpaSelecting()
+	explicitDefault := vpaSelecting(DefaultRecommenderName)
+	forecast := vpaSelecting("forecast")
+	other := vpaSelecting("other")
+	allVpaCRDs := []*vpa_types.VerticalPodAutoscaler{implicitDefault, explicitDefault, forecast, other}
+
+	feeder := &clusterStateFeeder{
+		recommenderName:            DefaultRecommenderName,
+		additionalRecommenderNames: []string{"forecast"},
+	}
+	assert.ElementsMatch(t, []*vpa_types.VerticalPodAutoscaler{implicitDefault, explicitDefault, forecast}, filterVPAs(feeder, allVpaCRDs))
+
+	feeder.recommenderName = "performance"
+	assert.ElementsMatch(t, []*vpa_types.VerticalPodAutoscaler{forecast}, filterVPAs(feeder, allVpaCRDs))
+}
@@ -111,8 +111,14 @@ func calculateUsage(containerUsage k8sapiv1.ResourceList) model.Resources {
 	memoryQuantity := containerUsage[k8sapiv1.ResourceMemory]
 	memoryBytes := memoryQuantity.Value()
 
-	return model.Resources{
+	usage := model.Resources{
 		model.ResourceCPU:    model.ResourceAmount(cpuMillicores),
 		model.ResourceMemory: model.ResourceAmount(memoryBytes),
 	}
+	for resourceName, quantity := range containerUsage {
+		if model.IsExtendedResource(model.ResourceName(resourceName)) {
+			usage[model.ResourceName(resourceName)] = model.ResourceAmount(quantity.Value())
+		}
+	}
+	return usage
 }
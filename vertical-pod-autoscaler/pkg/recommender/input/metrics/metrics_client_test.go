@@ -20,6 +20,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	k8sapiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
 )
 
 func TestGetContainersMetricsReturnsEmptyList(t *testing.T) {
@@ -44,3 +48,20 @@ func TestGetContainersMetricsReturnsResults(t *testing.T) {
 		assert.Contains(t, tc.getAllSnaps(), snap, "One of returned ContainerMetricsSnapshot is different then expected ")
 	}
 }
+
+func TestCalculateUsageExtendedResources(t *testing.T) {
+	usage := calculateUsage(k8sapiv1.ResourceList{
+		k8sapiv1.ResourceCPU:     resource.MustParse("500m"),
+		k8sapiv1.ResourceMemory:  resource.MustParse("1Gi"),
+		"hugepages-2Mi":          resource.MustParse("4Mi"),
+		"nvidia.com/gpu":         resource.MustParse("2"),
+		k8sapiv1.ResourceStorage: resource.MustParse("1Gi"),
+	})
+
+	assert.Equal(t, model.Resources{
+		model.ResourceCPU:    500,
+		model.ResourceMemory: 1024 * 1024 * 1024,
+		"hugepages-2Mi":      4 * 1024 * 1024,
+		"nvidia.com/gpu":     2,
+	}, usage)
+}
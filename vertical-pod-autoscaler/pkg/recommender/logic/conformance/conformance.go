@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance checks that implementations of
+// logic.PodResourceRecommender behave the way the rest of the recommender
+// expects, so that alternate recommendation algorithms can be validated
+// before being registered.
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/logic"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+)
+
+// RunPodResourceRecommenderTests runs conformance checks against the recommender.
+// A conforming recommender:
+//   - returns no recommendations for a pod without containers,
+//   - returns a recommendation for every container,
+//   - recommends only resources controlled by the VPA, setting the target,
+//     lower and upper bound of each of them,
+//   - recommends non-negative amounts, with lower bound <= target <= upper bound.
+func RunPodResourceRecommenderTests(t *testing.T, recommender logic.PodResourceRecommender) {
+	t.Run("no containers", func(t *testing.T) {
+		assert.Empty(t, recommender.GetRecommendedPodResources(model.ContainerNameToAggregateStateMap{}))
+	})
+	t.Run("all containers recommended", func(t *testing.T) {
+		states := model.ContainerNameToAggregateStateMap{
+			"app":     aggregateStateWithSamples(0.5, 200e6, nil),
+			"sidecar": aggregateStateWithSamples(0.01, 20e6, nil),
+			"idle":    model.NewAggregateContainerState(),
+		}
+		recommendations := recommender.GetRecommendedPodResources(states)
+		assert.Len(t, recommendations, len(states))
+		for name := range states {
+			checkRecommendation(t, name, recommendations, []model.ResourceName{model.ResourceCPU, model.ResourceMemory})
+		}
+	})
+	t.Run("only controlled resources recommended", func(t *testing.T) {
+		for _, resource := range []model.ResourceName{model.ResourceCPU, model.ResourceMemory} {
+			controlled := []model.ResourceName{resource}
+			states := model.ContainerNameToAggregateStateMap{
+				"app": aggregateStateWithSamples(0.5, 200e6, &controlled),
+			}
+			checkRecommendation(t, "app", recommender.GetRecommendedPodResources(states), controlled)
+		}
+	})
+}
+
+func aggregateStateWithSamples(cpuCores, memoryBytes float64, controlledResources *[]model.ResourceName) *model.AggregateContainerState {
+	state := model.NewAggregateContainerState()
+	state.ControlledResources = controlledResources
+	start := time.Now().Add(-24 * time.Hour)
+	for i := 0; i < 24*60; i++ {
+		measureStart := start.Add(time.Duration(i) * time.Minute)
+		// Usage oscillates around the given values.
+		factor := 0.5 + float64(i%10)/10
+		state.AddSample(&model.ContainerUsageSample{
+			MeasureStart: measureStart,
+			Usage:        model.CPUAmountFromCores(cpuCores * factor),
+			Request:      model.CPUAmountFromCores(cpuCores),
+			Resource:     model.ResourceCPU,
+		})
+		state.AddSample(&model.ContainerUsageSample{
+			MeasureStart: measureStart,
+			Usage:        model.MemoryAmountFromBytes(memoryBytes * factor),
+			Request:      model.MemoryAmountFromBytes(memoryBytes),
+			Resource:     model.ResourceMemory,
+		})
+	}
+	return state
+}
+
+func checkRecommendation(t *testing.T, containerName string, recommendations logic.RecommendedPodResources, controlled []model.ResourceName) {
+	recommendation, found := recommendations[containerName]
+	if !assert.True(t, found, "no recommendation for container %s", containerName) {
+		return
+	}
+	for _, bound := range []model.Resources{recommendation.Target, recommendation.LowerBound, recommendation.UpperBound} {
+		assert.Len(t, bound, len(controlled), "container %s has recommendations for uncontrolled resources", containerName)
+	}
+	for _, resource := range controlled {
+		target, found := recommendation.Target[resource]
+		assert.True(t, found, "no %s target for container %s", resource, containerName)
+		lowerBound, found := recommendation.LowerBound[resource]
+		assert.True(t, found, "no %s lower bound for container %s", resource, containerName)
+		upperBound, found := recommendation.UpperBound[resource]
+		assert.True(t, found, "no %s upper bound for container %s", resource, containerName)
+		assert.GreaterOrEqual(t, lowerBound, model.ResourceAmount(0), "negative %s lower bound for container %s", resource, containerName)
+		assert.LessOrEqual(t, lowerBound, target, "%s lower bound above target for container %s", resource, containerName)
+		assert.LessOrEqual(t, target, upperBound, "%s target above upper bound for container %s", resource, containerName)
+	}
+}
@@ -55,6 +55,11 @@ type minResourcesEstimator struct {
 	baseEstimator ResourceEstimator
 }
 
+type memoryPeakOverWindowEstimator struct {
+	window        time.Duration
+	baseEstimator ResourceEstimator
+}
+
 type confidenceMultiplier struct {
 	multiplier    float64
 	exponent      float64
@@ -83,6 +88,14 @@ func WithMinResources(minResources model.Resources, baseEstimator ResourceEstima
 	return &minResourcesEstimator{minResources, baseEstimator}
 }
 
+// WithMemoryPeakOverWindow returns a given ResourceEstimator with the memory
+// estimation replaced by the highest memory peak observed within the window.
+// The base memory estimation is used if no recent peaks are known, e.g. right
+// after the recommender restarted from checkpoints.
+func WithMemoryPeakOverWindow(window time.Duration, baseEstimator ResourceEstimator) ResourceEstimator {
+	return &memoryPeakOverWindowEstimator{window, baseEstimator}
+}
+
 // WithConfidenceMultiplier returns a given ResourceEstimator with confidenceMultiplier applied.
 func WithConfidenceMultiplier(multiplier, exponent float64, baseEstimator ResourceEstimator) ResourceEstimator {
 	return &confidenceMultiplier{multiplier, exponent, baseEstimator}
@@ -94,13 +107,20 @@ func (e *constEstimator) GetResourceEstimation(s *model.AggregateContainerState)
 }
 
 // Returns specific percentiles of CPU and memory peaks distributions.
+// Hugepages and extended resources use the memory percentile, as they can't
+// be throttled either. Their samples are whole devices or pages, so the start of
+// the bucket is taken rather than its end, which can be a unit above the usage.
 func (e *percentileEstimator) GetResourceEstimation(s *model.AggregateContainerState) model.Resources {
-	return model.Resources{
+	resources := model.Resources{
 		model.ResourceCPU: model.CPUAmountFromCores(
 			s.AggregateCPUUsage.Percentile(e.cpuPercentile)),
 		model.ResourceMemory: model.MemoryAmountFromBytes(
 			s.AggregateMemoryPeaks.Percentile(e.memoryPercentile)),
 	}
+	for resource, histogram := range s.AggregateExtendedUsage {
+		resources[resource] = model.ExtendedResourceAmountFromValue(histogram.PercentileBucketStart(e.memoryPercentile))
+	}
+	return resources
 }
 
 // Returns a non-negative real number that heuristically measures how much
@@ -147,6 +167,19 @@ func (e *marginEstimator) GetResourceEstimation(s *model.AggregateContainerState
 	return newResources
 }
 
+func (e *memoryPeakOverWindowEstimator) GetResourceEstimation(s *model.AggregateContainerState) model.Resources {
+	originalResources := e.baseEstimator.GetResourceEstimation(s)
+	if s.RecentMemoryPeaks.IsEmpty() {
+		return originalResources
+	}
+	newResources := make(model.Resources)
+	for resource, resourceAmount := range originalResources {
+		newResources[resource] = resourceAmount
+	}
+	newResources[model.ResourceMemory] = s.RecentMemoryPeaks.MaxOverWindow(e.window)
+	return newResources
+}
+
 func (e *minResourcesEstimator) GetResourceEstimation(s *model.AggregateContainerState) model.Resources {
 	originalResources := e.baseEstimator.GetResourceEstimation(s)
 	newResources := make(model.Resources)
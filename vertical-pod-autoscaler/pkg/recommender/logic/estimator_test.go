@@ -62,6 +62,42 @@ func TestPercentileEstimator(t *testing.T) {
 	assert.InEpsilon(t, 2e9, model.BytesFromMemoryAmount(resourceEstimation[model.ResourceMemory]), maxRelativeError)
 }
 
+// Verifies that the PercentileEstimator returns the memory percentile of
+// distributions of extended resources.
+func TestPercentileEstimatorExtendedResources(t *testing.T) {
+	config := model.GetAggregationsConfig()
+	gpuHistogram := util.NewHistogram(config.ExtendedResourceHistogramOptions)
+	gpuHistogram.AddSample(1.0, 1.0, anyTime)
+	gpuHistogram.AddSample(4.0, 1.0, anyTime)
+	gpuHistogram.AddSample(8.0, 1.0, anyTime)
+	estimator := NewPercentileEstimator(0.2, 0.5)
+
+	resourceEstimation := estimator.GetResourceEstimation(
+		&model.AggregateContainerState{
+			AggregateCPUUsage:      util.NewHistogram(config.CPUHistogramOptions),
+			AggregateMemoryPeaks:   util.NewHistogram(config.MemoryHistogramOptions),
+			AggregateExtendedUsage: map[model.ResourceName]util.Histogram{"nvidia.com/gpu": gpuHistogram},
+		})
+	assert.InEpsilon(t, 4.0, float64(resourceEstimation["nvidia.com/gpu"]), 0.05)
+}
+
+// Verifies that a container using a single device is recommended exactly one,
+// even with the safety margin applied.
+func TestPercentileEstimatorSingleDevice(t *testing.T) {
+	config := model.GetAggregationsConfig()
+	gpuHistogram := util.NewHistogram(config.ExtendedResourceHistogramOptions)
+	gpuHistogram.AddSample(1.0, 1.0, anyTime)
+	estimator := WithMargin(0.15, NewPercentileEstimator(0.9, 0.9))
+
+	resourceEstimation := estimator.GetResourceEstimation(
+		&model.AggregateContainerState{
+			AggregateCPUUsage:      util.NewHistogram(config.CPUHistogramOptions),
+			AggregateMemoryPeaks:   util.NewHistogram(config.MemoryHistogramOptions),
+			AggregateExtendedUsage: map[model.ResourceName]util.Histogram{"nvidia.com/gpu": gpuHistogram},
+		})
+	assert.Equal(t, model.ResourceAmount(1), resourceEstimation["nvidia.com/gpu"])
+}
+
 // Verifies that the confidenceMultiplier calculates the internal
 // confidence based on the amount of historical samples and scales the resources
 // returned by the base estimator according to the formula, using the calculated
@@ -159,3 +195,39 @@ func TestMinResourcesEstimator(t *testing.T) {
 	// Original Memory is below min resources
 	assert.Equal(t, 4e8, model.BytesFromMemoryAmount(resourceEstimation[model.ResourceMemory]))
 }
+
+// Verifies that the memoryPeakOverWindowEstimator returns the highest memory
+// peak within the window, and the base estimation if no peaks are known.
+func TestMemoryPeakOverWindowEstimator(t *testing.T) {
+	baseEstimator := NewConstEstimator(model.Resources{
+		model.ResourceCPU:    model.CPUAmountFromCores(3.14),
+		model.ResourceMemory: model.MemoryAmountFromBytes(3.14e9),
+	})
+	testedEstimator := &memoryPeakOverWindowEstimator{
+		window:        36 * time.Hour,
+		baseEstimator: baseEstimator,
+	}
+
+	s := model.NewAggregateContainerState()
+	resourceEstimation := testedEstimator.GetResourceEstimation(s)
+	assert.Equal(t, 3.14e9, model.BytesFromMemoryAmount(resourceEstimation[model.ResourceMemory]))
+
+	// Add one peak per day, the spike two days ago is outside of the window.
+	timestamp := anyTime
+	for _, peak := range []float64{1e9, 8e9, 2e9, 4e9} {
+		s.AddSample(&model.ContainerUsageSample{
+			MeasureStart: timestamp,
+			Usage:        model.MemoryAmountFromBytes(peak),
+			Request:      testRequest[model.ResourceMemory],
+			Resource:     model.ResourceMemory,
+		})
+		timestamp = timestamp.Add(24 * time.Hour)
+	}
+	resourceEstimation = testedEstimator.GetResourceEstimation(s)
+	assert.Equal(t, 3.14, model.CoresFromCPUAmount(resourceEstimation[model.ResourceCPU]))
+	assert.Equal(t, 4e9, model.BytesFromMemoryAmount(resourceEstimation[model.ResourceMemory]))
+
+	testedEstimator.window = 72 * time.Hour
+	resourceEstimation = testedEstimator.GetResourceEstimation(s)
+	assert.Equal(t, 8e9, model.BytesFromMemoryAmount(resourceEstimation[model.ResourceMemory]))
+}
@@ -19,9 +19,11 @@ package logic
 import (
 	"flag"
 	"sort"
+	"time"
 
 	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -34,10 +36,23 @@ var (
 	targetMemoryPercentile     = flag.Float64("target-memory-percentile", 0.9, "Memory usage percentile that will be used as a base for memory target recommendation. Doesn't affect memory lower bound nor memory upper bound.")
 	lowerBoundMemoryPercentile = flag.Float64("recommendation-lower-bound-memory-percentile", 0.5, `Memory usage percentile that will be used for the lower bound on memory recommendation.`)
 	upperBoundMemoryPercentile = flag.Float64("recommendation-upper-bound-memory-percentile", 0.95, `Memory usage percentile that will be used for the upper bound on memory recommendation.`)
+	memoryRecommendationMode   = flag.String("memory-recommendation-mode", MemoryRecommendationModePercentile, `How memory target and upper bound recommendations are computed. Supported values: percentile (percentile of the memory peaks histogram), peak-over-window (highest memory peak within memory-peak-window, for spiky workloads)`)
+	memoryPeakWindow           = flag.Duration("memory-peak-window", 24*time.Hour, `Length of the window the highest memory peak is taken over in the peak-over-window memory recommendation mode. Peaks are observed once per memory-aggregation-interval and kept for the memory aggregation window at most.`)
+)
+
+const (
+	// MemoryRecommendationModePercentile bases memory recommendations on percentiles of the memory peaks histogram.
+	MemoryRecommendationModePercentile = "percentile"
+	// MemoryRecommendationModePeakOverWindow bases memory target and upper bound recommendations on the
+	// highest memory peak within a window, tolerating bursts of spiky workloads.
+	MemoryRecommendationModePeakOverWindow = "peak-over-window"
 )
 
 // PodResourceRecommender computes resource recommendation for a Vpa object.
+// Alternate implementations can be registered with RegisterPodResourceRecommender.
 type PodResourceRecommender interface {
+	// GetRecommendedPodResources returns recommendations for all containers of
+	// the map, limited to the resources they control.
 	GetRecommendedPodResources(containerNameToAggregateStateMap model.ContainerNameToAggregateStateMap) RecommendedPodResources
 }
 
@@ -111,6 +126,18 @@ func CreatePodResourceRecommender() PodResourceRecommender {
 	lowerBoundEstimator := NewPercentileEstimator(*lowerBoundCPUPercentile, *lowerBoundMemoryPercentile)
 	upperBoundEstimator := NewPercentileEstimator(*upperBoundCPUPercentile, *upperBoundMemoryPercentile)
 
+	switch *memoryRecommendationMode {
+	case MemoryRecommendationModePercentile:
+	case MemoryRecommendationModePeakOverWindow:
+		// The lower bound is left percentile based, so that pods aren't
+		// evicted to get more memory unless their requests are far below
+		// typical usage.
+		targetEstimator = WithMemoryPeakOverWindow(*memoryPeakWindow, targetEstimator)
+		upperBoundEstimator = WithMemoryPeakOverWindow(*memoryPeakWindow, upperBoundEstimator)
+	default:
+		klog.Fatalf("Unsupported memory recommendation mode %q", *memoryRecommendationMode)
+	}
+
 	targetEstimator = WithMargin(*safetyMarginFraction, targetEstimator)
 	lowerBoundEstimator = WithMargin(*safetyMarginFraction, lowerBoundEstimator)
 	upperBoundEstimator = WithMargin(*safetyMarginFraction, upperBoundEstimator)
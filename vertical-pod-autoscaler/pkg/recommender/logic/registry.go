@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// PodResourceRecommenderFactory creates a PodResourceRecommender. It's called
+// after flags are parsed, so it may depend on their values.
+type PodResourceRecommenderFactory func() PodResourceRecommender
+
+var (
+	registryMutex sync.Mutex
+	registry      = make(map[string]PodResourceRecommenderFactory)
+)
+
+// RegisterPodResourceRecommender makes a recommendation algorithm available
+// under the given name, so that it can serve VPA objects selecting the name in
+// their recommenders. Alternate algorithms are expected to be registered from
+// init functions of the packages implementing them.
+func RegisterPodResourceRecommender(name string, factory PodResourceRecommenderFactory) error {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if name == "" {
+		return fmt.Errorf("recommender name can't be empty")
+	}
+	if factory == nil {
+		return fmt.Errorf("factory of recommender %s is nil", name)
+	}
+	if _, found := registry[name]; found {
+		return fmt.Errorf("recommender %s is already registered", name)
+	}
+	registry[name] = factory
+	return nil
+}
+
+// RegisteredPodResourceRecommenders returns the sorted names of all registered
+// recommendation algorithms.
+func RegisteredPodResourceRecommenders() []string {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateRegisteredPodResourceRecommenders creates the registered recommenders
+// of the given names. It fails if any of them isn't registered.
+func CreateRegisteredPodResourceRecommenders(names []string) (map[string]PodResourceRecommender, error) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	recommenders := make(map[string]PodResourceRecommender, len(names))
+	for _, name := range names {
+		factory, found := registry[name]
+		if !found {
+			return nil, fmt.Errorf("recommender %s is not registered", name)
+		}
+		recommenders[name] = factory()
+	}
+	return recommenders, nil
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+)
+
+type fakePodResourceRecommender struct{}
+
+func (fakePodResourceRecommender) GetRecommendedPodResources(model.ContainerNameToAggregateStateMap) RecommendedPodResources {
+	return RecommendedPodResources{}
+}
+
+func TestRegisterPodResourceRecommender(t *testing.T) {
+	factory := func() PodResourceRecommender { return fakePodResourceRecommender{} }
+	require.NoError(t, RegisterPodResourceRecommender("test-registry", factory))
+	assert.Error(t, RegisterPodResourceRecommender("test-registry", factory))
+	assert.Error(t, RegisterPodResourceRecommender("", factory))
+	assert.Error(t, RegisterPodResourceRecommender("test-nil", nil))
+	assert.Contains(t, RegisteredPodResourceRecommenders(), "test-registry")
+	assert.NotContains(t, RegisteredPodResourceRecommenders(), "test-nil")
+
+	recommenders, err := CreateRegisteredPodResourceRecommenders([]string{"test-registry"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PodResourceRecommender{"test-registry": fakePodResourceRecommender{}}, recommenders)
+
+	_, err = CreateRegisteredPodResourceRecommenders([]string{"test-registry", "unknown"})
+	assert.Error(t, err)
+}
@@ -19,6 +19,8 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"strings"
 	"time"
 
 	resourceclient "k8s.io/metrics/pkg/client/clientset/versioned/typed/metrics/v1beta1"
@@ -48,6 +50,7 @@ import (
 
 var (
 	recommenderName        = flag.String("recommender-name", input.DefaultRecommenderName, "Set the recommender name. Recommender will generate recommendations for VPAs that configure the same recommender name. If the recommender name is left as default it will also generate recommendations that don't explicitly specify recommender. You shouldn't run two recommenders with the same name in a cluster.")
+	additionalRecommenders = flag.String("additional-recommenders", "", "Comma-separated list of names of registered recommendation algorithms served by this recommender besides the one named by --recommender-name. Each of them generates recommendations for VPAs that configure its name.")
 	metricsFetcherInterval = flag.Duration("recommender-interval", 1*time.Minute, `How often metrics should be fetched`)
 	checkpointsGCInterval  = flag.Duration("checkpoints-gc-interval", 10*time.Minute, `How often orphaned checkpoints should be garbage collected`)
 	prometheusAddress      = flag.String("prometheus-address", "", `Where to reach for Prometheus metrics`)
@@ -59,24 +62,28 @@ var (
 
 	storage = flag.String("storage", "", `Specifies storage mode. Supported values: prometheus, checkpoint (default)`)
 	// prometheus history provider configs
-	historyLength       = flag.String("history-length", "8d", `How much time back prometheus have to be queried to get historical metrics`)
-	historyResolution   = flag.String("history-resolution", "1h", `Resolution at which Prometheus is queried for historical metrics`)
-	queryTimeout        = flag.String("prometheus-query-timeout", "5m", `How long to wait before killing long queries`)
-	podLabelPrefix      = flag.String("pod-label-prefix", "pod_label_", `Which prefix to look for pod labels in metrics`)
-	podLabelsMetricName = flag.String("metric-for-pod-labels", "up{job=\"kubernetes-pods\"}", `Which metric to look for pod labels in metrics`)
-	podNamespaceLabel   = flag.String("pod-namespace-label", "kubernetes_namespace", `Label name to look for pod namespaces`)
-	podNameLabel        = flag.String("pod-name-label", "kubernetes_pod_name", `Label name to look for pod names`)
-	ctrNamespaceLabel   = flag.String("container-namespace-label", "namespace", `Label name to look for container namespaces`)
-	ctrPodNameLabel     = flag.String("container-pod-name-label", "pod_name", `Label name to look for container pod names`)
-	ctrNameLabel        = flag.String("container-name-label", "name", `Label name to look for container names`)
-	vpaObjectNamespace  = flag.String("vpa-object-namespace", apiv1.NamespaceAll, "Namespace to search for VPA objects and pod stats. Empty means all namespaces will be used.")
-	username            = flag.String("username", "", "The username used in the prometheus server basic auth")
-	password            = flag.String("password", "", "The password used in the prometheus server basic auth")
-	memorySaver         = flag.Bool("memory-saver", false, `If true, only track pods which have an associated VPA`)
+	historyLength            = flag.String("history-length", "8d", `How much time back prometheus have to be queried to get historical metrics`)
+	historyResolution        = flag.String("history-resolution", "1h", `Resolution at which Prometheus is queried for historical metrics`)
+	queryTimeout             = flag.String("prometheus-query-timeout", "5m", `How long to wait before killing long queries`)
+	podLabelPrefix           = flag.String("pod-label-prefix", "pod_label_", `Which prefix to look for pod labels in metrics`)
+	podLabelsMetricName      = flag.String("metric-for-pod-labels", "up{job=\"kubernetes-pods\"}", `Which metric to look for pod labels in metrics`)
+	podNamespaceLabel        = flag.String("pod-namespace-label", "kubernetes_namespace", `Label name to look for pod namespaces`)
+	podNameLabel             = flag.String("pod-name-label", "kubernetes_pod_name", `Label name to look for pod names`)
+	ctrNamespaceLabel        = flag.String("container-namespace-label", "namespace", `Label name to look for container namespaces`)
+	ctrPodNameLabel          = flag.String("container-pod-name-label", "pod_name", `Label name to look for container pod names`)
+	ctrNameLabel             = flag.String("container-name-label", "name", `Label name to look for container names`)
+	vpaObjectNamespace       = flag.String("vpa-object-namespace", apiv1.NamespaceAll, "Namespace to search for VPA objects and pod stats. Empty means all namespaces will be used.")
+	username                 = flag.String("username", "", "The username used in the prometheus server basic auth")
+	password                 = flag.String("password", "", "The password used in the prometheus server basic auth")
+	memorySaver              = flag.Bool("memory-saver", false, `If true, only track pods which have an associated VPA`)
+	ignoredNamespaceSelector = flag.String("ignored-namespace-selector", "", "Label selector for namespaces whose VPA objects should be ignored. Namespace labels are evaluated dynamically. Empty means no namespace is ignored.")
+	aggregateRecommendations = flag.Bool("aggregate-recommendations", false, "If true, export the sums of recommendations of VPA objects, multiplied by the number of pods they match, per namespace as metrics.")
+	aggregationSelectors     = flag.String("aggregate-recommendations-selectors", "", "Semicolon-separated list of name=selector pairs. If --aggregate-recommendations is set, the sums of recommendations of VPA objects whose labels match each selector are exported as metrics too, e.g. team-a=team=a;prod=env in (prod).")
 	// external metrics provider config
-	useExternalMetrics   = flag.Bool("use-external-metrics", false, "ALPHA.  Use an external metrics provider instead of metrics_server.")
-	externalCpuMetric    = flag.String("external-metrics-cpu-metric", "", "ALPHA.  Metric to use with external metrics provider for CPU usage.")
-	externalMemoryMetric = flag.String("external-metrics-memory-metric", "", "ALPHA.  Metric to use with external metrics provider for memory usage.")
+	useExternalMetrics      = flag.Bool("use-external-metrics", false, "ALPHA.  Use an external metrics provider instead of metrics_server.")
+	externalCpuMetric       = flag.String("external-metrics-cpu-metric", "", "ALPHA.  Metric to use with external metrics provider for CPU usage.")
+	externalMemoryMetric    = flag.String("external-metrics-memory-metric", "", "ALPHA.  Metric to use with external metrics provider for memory usage.")
+	externalExtendedMetrics = flag.String("external-metrics-extended-resource-metrics", "", "ALPHA.  Comma-separated list of resource=metric pairs to use with external metrics provider for usage of hugepages and extended resources, e.g. hugepages-2Mi=container_hugepages_2mi_usage.")
 )
 
 // Aggregation configuration flags
@@ -130,9 +137,12 @@ func main() {
 	if *postProcessorCPUasInteger {
 		postProcessors = append(postProcessors, &routines.IntegerCPUPostProcessor{})
 	}
+	postProcessors = append(postProcessors, &routines.CPUGranularityPostProcessor{})
 
 	// CappingPostProcessor, should always come in the last position for post-processing
 	postProcessors = append(postProcessors, &routines.CappingPostProcessor{})
+	// PodLevelPostProcessor aggregates the final container recommendations, so it comes after capping
+	postProcessors = append(postProcessors, &routines.PodLevelPostProcessor{})
 	var source input_metrics.PodMetricsLister
 	if *useExternalMetrics {
 		resourceMetrics := map[apiv1.ResourceName]string{}
@@ -142,6 +152,9 @@ func main() {
 		if externalMemoryMetric != nil && *externalMemoryMetric != "" {
 			resourceMetrics[apiv1.ResourceMemory] = *externalMemoryMetric
 		}
+		if err := parseExtendedResourceMetrics(*externalExtendedMetrics, resourceMetrics); err != nil {
+			klog.Fatalf("Could not use --external-metrics-extended-resource-metrics: %v", err)
+		}
 		externalClientOptions := &input_metrics.ExternalClientOptions{ResourceMetrics: resourceMetrics, ContainerNameLabel: *ctrNameLabel}
 		klog.V(1).Infof("Using External Metrics: %+v", externalClientOptions)
 		source = input_metrics.NewExternalClient(config, clusterState, *externalClientOptions)
@@ -150,18 +163,41 @@ func main() {
 		source = input_metrics.NewPodMetricsesSource(resourceclient.NewForConfigOrDie(config))
 	}
 
+	vpaLister, err := vpa_api_util.WithIgnoredNamespaces(vpa_api_util.NewVpasLister(vpa_clientset.NewForConfigOrDie(config), make(chan struct{}), *vpaObjectNamespace), kubeClient, make(chan struct{}), *ignoredNamespaceSelector)
+	if err != nil {
+		klog.Fatalf("Could not use --ignored-namespace-selector: %v", err)
+	}
+
+	parsedAggregationSelectors, err := metrics_recommender.ParseAggregationSelectors(*aggregationSelectors)
+	if err != nil {
+		klog.Fatalf("Could not use --aggregate-recommendations-selectors: %v", err)
+	}
+
+	var additionalRecommenderNames []string
+	for _, name := range strings.Split(*additionalRecommenders, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			additionalRecommenderNames = append(additionalRecommenderNames, name)
+		}
+	}
+	additionalPodResourceRecommenders, err := logic.CreateRegisteredPodResourceRecommenders(additionalRecommenderNames)
+	if err != nil {
+		klog.Fatalf("Could not use --additional-recommenders: %v. Registered recommenders: %v", err, logic.RegisteredPodResourceRecommenders())
+	}
+
 	clusterStateFeeder := input.ClusterStateFeederFactory{
 		PodLister:           podLister,
 		OOMObserver:         oomObserver,
 		KubeClient:          kubeClient,
 		MetricsClient:       input_metrics.NewMetricsClient(source, *vpaObjectNamespace, "default-metrics-client"),
 		VpaCheckpointClient: vpa_clientset.NewForConfigOrDie(config).AutoscalingV1(),
-		VpaLister:           vpa_api_util.NewVpasLister(vpa_clientset.NewForConfigOrDie(config), make(chan struct{}), *vpaObjectNamespace),
+		VpaLister:           vpaLister,
 		ClusterState:        clusterState,
 		SelectorFetcher:     target.NewVpaTargetSelectorFetcher(config, kubeClient, factory),
 		MemorySaveMode:      *memorySaver,
 		ControllerFetcher:   controllerFetcher,
 		RecommenderName:     *recommenderName,
+
+		AdditionalRecommenderNames: additionalRecommenderNames,
 	}.Make()
 	controllerFetcher.Start(context.Background(), scaleCacheLoopPeriod)
 
@@ -175,6 +211,10 @@ func main() {
 		RecommendationPostProcessors: postProcessors,
 		CheckpointsGCInterval:        *checkpointsGCInterval,
 		UseCheckpoints:               useCheckpoints,
+
+		AdditionalPodResourceRecommenders: additionalPodResourceRecommenders,
+		AggregateRecommendations:          *aggregateRecommendations,
+		AggregationSelectors:              parsedAggregationSelectors,
 	}.Make()
 
 	promQueryTimeout, err := time.ParseDuration(*queryTimeout)
@@ -217,3 +257,23 @@ func main() {
 		healthCheck.UpdateLastActivity()
 	}
 }
+
+// parseExtendedResourceMetrics adds the resource=metric pairs of the
+// comma-separated list to resourceMetrics. Only hugepages and extended
+// resources are accepted.
+func parseExtendedResourceMetrics(pairs string, resourceMetrics map[apiv1.ResourceName]string) error {
+	for _, pair := range strings.Split(pairs, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		resource, metric, found := strings.Cut(pair, "=")
+		if !found || resource == "" || metric == "" {
+			return fmt.Errorf("%q isn't a resource=metric pair", pair)
+		}
+		if !model.IsExtendedResource(model.ResourceName(resource)) {
+			return fmt.Errorf("%s is neither hugepages nor an extended resource", resource)
+		}
+		resourceMetrics[apiv1.ResourceName(resource)] = metric
+	}
+	return nil
+}
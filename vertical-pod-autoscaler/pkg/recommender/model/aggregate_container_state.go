@@ -94,6 +94,12 @@ type AggregateContainerState struct {
 	// AggregateMemoryPeaks is a distribution of memory peaks from all containers:
 	// each container should add one peak per memory aggregation interval (e.g. once every 24h).
 	AggregateMemoryPeaks util.Histogram
+	// RecentMemoryPeaks holds the same memory peaks as AggregateMemoryPeaks
+	// along with their time, without decay. It's not checkpointed.
+	RecentMemoryPeaks MemoryPeaks
+	// AggregateExtendedUsage holds a distribution of usage samples of each
+	// hugepages or extended resource, created on the first sample.
+	AggregateExtendedUsage map[ResourceName]util.Histogram
 	// Note: first/last sample timestamps as well as the sample count are based only on CPU samples.
 	FirstSampleStart  time.Time
 	LastSampleStart   time.Time
@@ -157,6 +163,10 @@ func (a *AggregateContainerState) MarkNotAutoscaled() {
 func (a *AggregateContainerState) MergeContainerState(other *AggregateContainerState) {
 	a.AggregateCPUUsage.Merge(other.AggregateCPUUsage)
 	a.AggregateMemoryPeaks.Merge(other.AggregateMemoryPeaks)
+	a.RecentMemoryPeaks.Merge(&other.RecentMemoryPeaks)
+	for resource, histogram := range other.AggregateExtendedUsage {
+		a.extendedUsage(resource).Merge(histogram)
+	}
 
 	if a.FirstSampleStart.IsZero() ||
 		(!other.FirstSampleStart.IsZero() && other.FirstSampleStart.Before(a.FirstSampleStart)) {
@@ -185,7 +195,12 @@ func (a *AggregateContainerState) AddSample(sample *ContainerUsageSample) {
 		a.addCPUSample(sample)
 	case ResourceMemory:
 		a.AggregateMemoryPeaks.AddSample(BytesFromMemoryAmount(sample.Usage), 1.0, sample.MeasureStart)
+		a.RecentMemoryPeaks.AddSample(sample.Usage, sample.MeasureStart)
 	default:
+		if IsExtendedResource(sample.Resource) {
+			a.extendedUsage(sample.Resource).AddSample(float64(sample.Usage), 1.0, sample.MeasureStart)
+			return
+		}
 		panic(fmt.Sprintf("AddSample doesn't support resource '%s'", sample.Resource))
 	}
 }
@@ -199,11 +214,27 @@ func (a *AggregateContainerState) SubtractSample(sample *ContainerUsageSample) {
 	switch sample.Resource {
 	case ResourceMemory:
 		a.AggregateMemoryPeaks.SubtractSample(BytesFromMemoryAmount(sample.Usage), 1.0, sample.MeasureStart)
+		a.RecentMemoryPeaks.SubtractSample(sample.Usage, sample.MeasureStart)
 	default:
 		panic(fmt.Sprintf("SubtractSample doesn't support resource '%s'", sample.Resource))
 	}
 }
 
+// extendedUsage returns the histogram of usage of the given hugepages or
+// extended resource, creating it if needed.
+func (a *AggregateContainerState) extendedUsage(resource ResourceName) util.Histogram {
+	if a.AggregateExtendedUsage == nil {
+		a.AggregateExtendedUsage = make(map[ResourceName]util.Histogram)
+	}
+	histogram, found := a.AggregateExtendedUsage[resource]
+	if !found {
+		config := GetAggregationsConfig()
+		histogram = util.NewDecayingHistogram(config.ExtendedResourceHistogramOptions, config.MemoryHistogramDecayHalfLife)
+		a.AggregateExtendedUsage[resource] = histogram
+	}
+	return histogram
+}
+
 func (a *AggregateContainerState) addCPUSample(sample *ContainerUsageSample) {
 	cpuUsageCores := CoresFromCPUAmount(sample.Usage)
 	cpuRequestCores := CoresFromCPUAmount(sample.Request)
@@ -232,14 +263,26 @@ func (a *AggregateContainerState) SaveToCheckpoint() (*vpa_types.VerticalPodAuto
 	if err != nil {
 		return nil, err
 	}
+	var extended map[corev1.ResourceName]vpa_types.HistogramCheckpoint
+	for resource, histogram := range a.AggregateExtendedUsage {
+		checkpoint, err := histogram.SaveToChekpoint()
+		if err != nil {
+			return nil, err
+		}
+		if extended == nil {
+			extended = make(map[corev1.ResourceName]vpa_types.HistogramCheckpoint)
+		}
+		extended[corev1.ResourceName(resource)] = *checkpoint
+	}
 	return &vpa_types.VerticalPodAutoscalerCheckpointStatus{
-		LastUpdateTime:    metav1.NewTime(time.Now()),
-		FirstSampleStart:  metav1.NewTime(a.FirstSampleStart),
-		LastSampleStart:   metav1.NewTime(a.LastSampleStart),
-		TotalSamplesCount: a.TotalSamplesCount,
-		MemoryHistogram:   *memory,
-		CPUHistogram:      *cpu,
-		Version:           SupportedCheckpointVersion,
+		LastUpdateTime:             metav1.NewTime(time.Now()),
+		FirstSampleStart:           metav1.NewTime(a.FirstSampleStart),
+		LastSampleStart:            metav1.NewTime(a.LastSampleStart),
+		TotalSamplesCount:          a.TotalSamplesCount,
+		MemoryHistogram:            *memory,
+		CPUHistogram:               *cpu,
+		ExtendedResourceHistograms: extended,
+		Version:                    SupportedCheckpointVersion,
 	}, nil
 }
 
@@ -260,6 +303,16 @@ func (a *AggregateContainerState) LoadFromCheckpoint(checkpoint *vpa_types.Verti
 	if err != nil {
 		return err
 	}
+	for resource, histogram := range checkpoint.ExtendedResourceHistograms {
+		if !IsExtendedResource(ResourceName(resource)) {
+			return fmt.Errorf("unsupported extended resource %s", resource)
+		}
+		histogram := histogram
+		err = a.extendedUsage(ResourceName(resource)).LoadFromCheckpoint(&histogram)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -199,6 +199,32 @@ func TestAggregateContainerStateLoadFromCheckpoint(t *testing.T) {
 	assert.False(t, cs.AggregateMemoryPeaks.IsEmpty())
 }
 
+func TestAggregateContainerStateExtendedResources(t *testing.T) {
+	hugepages := ResourceName(apiv1.ResourceHugePagesPrefix + "2Mi")
+	gpu := ResourceName("nvidia.com/gpu")
+	cs := NewAggregateContainerState()
+	cs.AddSample(&ContainerUsageSample{testTimestamp, 4 * 2 * 1024 * 1024, 0, hugepages})
+	cs.AddSample(&ContainerUsageSample{testTimestamp, 2, 0, gpu})
+	other := NewAggregateContainerState()
+	other.AddSample(&ContainerUsageSample{testTimestamp, 3, 0, gpu})
+	cs.MergeContainerState(other)
+	assert.Len(t, cs.AggregateExtendedUsage, 2)
+	assert.Equal(t, 3.0, cs.AggregateExtendedUsage[gpu].PercentileBucketStart(1.0))
+
+	checkpoint, err := cs.SaveToCheckpoint()
+	assert.NoError(t, err)
+	assert.Len(t, checkpoint.ExtendedResourceHistograms, 2)
+	assert.Len(t, checkpoint.ExtendedResourceHistograms[apiv1.ResourceName(gpu)].BucketWeights, 2)
+
+	restored := NewAggregateContainerState()
+	assert.NoError(t, restored.LoadFromCheckpoint(checkpoint))
+	assert.Len(t, restored.AggregateExtendedUsage, 2)
+	assert.InEpsilon(t, 4*2*1024*1024, restored.AggregateExtendedUsage[hugepages].PercentileBucketStart(1.0), 0.1)
+
+	checkpoint.ExtendedResourceHistograms[apiv1.ResourceStorage] = vpa_types.HistogramCheckpoint{}
+	assert.Error(t, NewAggregateContainerState().LoadFromCheckpoint(checkpoint))
+}
+
 func TestAggregateContainerStateIsExpired(t *testing.T) {
 	cs := NewAggregateContainerState()
 	cs.LastSampleStart = testTimestamp
@@ -276,6 +302,12 @@ func TestUpdateFromPolicyControlledResources(t *testing.T) {
 				ControlledResources: &[]apiv1.ResourceName{apiv1.ResourceMemory},
 			},
 			expected: []ResourceName{ResourceMemory},
+		}, {
+			name: "ControlledResources with hugepages and extended resources",
+			policy: &vpa_types.ContainerResourcePolicy{
+				ControlledResources: &[]apiv1.ResourceName{apiv1.ResourceMemory, "hugepages-1Gi", "nvidia.com/gpu", apiv1.ResourceStorage},
+			},
+			expected: []ResourceName{ResourceMemory, "hugepages-1Gi", "nvidia.com/gpu"},
 		}, {
 			name:     "No ControlledResources specified - used default",
 			policy:   &vpa_types.ContainerResourcePolicy{},
@@ -40,6 +40,9 @@ type AggregationsConfig struct {
 	// MemoryHistogramOptions are options to be used by histograms that
 	// store memory measures expressed in bytes.
 	MemoryHistogramOptions util.HistogramOptions
+	// ExtendedResourceHistogramOptions are options to be used by histograms
+	// that store hugepages (in bytes) and extended resources (in their units).
+	ExtendedResourceHistogramOptions util.HistogramOptions
 	// HistogramBucketSizeGrowth defines the growth rate of the histogram buckets.
 	// Each bucket is wider than the previous one by this fraction.
 	HistogramBucketSizeGrowth float64
@@ -110,6 +113,19 @@ func (a *AggregationsConfig) memoryHistogramOptions() util.HistogramOptions {
 	return options
 }
 
+func (a *AggregationsConfig) extendedResourceHistogramOptions() util.HistogramOptions {
+	// Extended resource histograms use buckets starting at whole numbers, 1 unit
+	// wide for small values, so that whole devices are told apart, growing
+	// exponentially up to max of 1e14 units, covering hugepages expressed in bytes.
+	//
+	// When parameters below are changed SupportedCheckpointVersion has to be bumped.
+	options, err := util.NewIntegerHistogramOptions(1e14, 1.+a.HistogramBucketSizeGrowth, epsilon)
+	if err != nil {
+		panic("Invalid extended resource histogram options") // Should not happen.
+	}
+	return options
+}
+
 // NewAggregationsConfig creates a new AggregationsConfig based on the supplied parameters and default values.
 func NewAggregationsConfig(memoryAggregationInterval time.Duration, memoryAggregationIntervalCount int64, memoryHistogramDecayHalfLife, cpuHistogramDecayHalfLife time.Duration, oomBumpUpRatio float64, oomMinBumpUp float64) *AggregationsConfig {
 	a := &AggregationsConfig{
@@ -123,6 +139,7 @@ func NewAggregationsConfig(memoryAggregationInterval time.Duration, memoryAggreg
 	}
 	a.CPUHistogramOptions = a.cpuHistogramOptions()
 	a.MemoryHistogramOptions = a.memoryHistogramOptions()
+	a.ExtendedResourceHistogramOptions = a.extendedResourceHistogramOptions()
 	return a
 }
 
@@ -57,6 +57,8 @@ type ContainerState struct {
 	WindowEnd time.Time
 	// Start of the latest memory usage sample that was aggregated.
 	lastMemorySampleStart time.Time
+	// Start of the latest usage sample of each extended resource that was aggregated.
+	lastExtendedSampleStart map[ResourceName]time.Time
 	// Aggregation to add usage samples to.
 	aggregator ContainerStateAggregator
 }
@@ -183,6 +185,19 @@ func (container *ContainerState) addMemorySample(sample *ContainerUsageSample, i
 	return true
 }
 
+func (container *ContainerState) addExtendedSample(sample *ContainerUsageSample) bool {
+	if container.lastExtendedSampleStart == nil {
+		container.lastExtendedSampleStart = make(map[ResourceName]time.Time)
+	}
+	// Like for CPU, order should not matter for the histogram, other than deduplication.
+	if !sample.isValid(sample.Resource) || !sample.MeasureStart.After(container.lastExtendedSampleStart[sample.Resource]) {
+		return false // Discard invalid, duplicate or out-of-order samples.
+	}
+	container.aggregator.AddSample(sample)
+	container.lastExtendedSampleStart[sample.Resource] = sample.MeasureStart
+	return true
+}
+
 // RecordOOM adds info regarding OOM event in the model as an artificial memory sample.
 func (container *ContainerState) RecordOOM(timestamp time.Time, requestedMemory ResourceAmount) error {
 	// Discard old OOM
@@ -220,6 +235,9 @@ func (container *ContainerState) AddSample(sample *ContainerUsageSample) bool {
 	case ResourceMemory:
 		return container.addMemorySample(sample, false)
 	default:
+		if IsExtendedResource(sample.Resource) {
+			return container.addExtendedSample(sample)
+		}
 		return false
 	}
 }
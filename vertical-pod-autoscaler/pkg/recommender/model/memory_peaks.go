@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// MemoryPeak is a single memory usage peak of a container over a memory
+// aggregation interval.
+type MemoryPeak struct {
+	// MeasureStart is the end of the memory aggregation interval the peak
+	// was observed in.
+	MeasureStart time.Time
+	// Usage is the peak memory usage.
+	Usage ResourceAmount
+}
+
+// MemoryPeaks holds the memory usage peaks of all containers, ordered by time,
+// without any decay. Unlike the memory peaks histogram, it keeps the time of
+// each peak, which allows computing the peak over a recent window.
+type MemoryPeaks struct {
+	peaks []MemoryPeak
+}
+
+// AddSample adds a memory peak. Peaks older than the memory aggregation window
+// length relative to the newest peak are dropped.
+func (m *MemoryPeaks) AddSample(usage ResourceAmount, measureStart time.Time) {
+	i := sort.Search(len(m.peaks), func(i int) bool { return m.peaks[i].MeasureStart.After(measureStart) })
+	m.peaks = append(m.peaks, MemoryPeak{})
+	copy(m.peaks[i+1:], m.peaks[i:])
+	m.peaks[i] = MemoryPeak{MeasureStart: measureStart, Usage: usage}
+	m.prune()
+}
+
+// SubtractSample removes a memory peak previously added with AddSample().
+func (m *MemoryPeaks) SubtractSample(usage ResourceAmount, measureStart time.Time) {
+	for i, peak := range m.peaks {
+		if peak.MeasureStart.Equal(measureStart) && peak.Usage == usage {
+			m.peaks = append(m.peaks[:i], m.peaks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Merge adds all peaks of the other MemoryPeaks.
+func (m *MemoryPeaks) Merge(other *MemoryPeaks) {
+	for _, peak := range other.peaks {
+		m.AddSample(peak.Usage, peak.MeasureStart)
+	}
+}
+
+// IsEmpty returns true if no peaks are held.
+func (m *MemoryPeaks) IsEmpty() bool {
+	return len(m.peaks) == 0
+}
+
+// MaxOverWindow returns the highest peak observed within the window preceding
+// the newest peak, which is always included, and 0 if no peaks are held.
+func (m *MemoryPeaks) MaxOverWindow(window time.Duration) ResourceAmount {
+	if len(m.peaks) == 0 {
+		return 0
+	}
+	newest := m.peaks[len(m.peaks)-1]
+	windowStart := newest.MeasureStart.Add(-window)
+	max := newest.Usage
+	for i := len(m.peaks) - 2; i >= 0 && m.peaks[i].MeasureStart.After(windowStart); i-- {
+		max = ResourceAmountMax(max, m.peaks[i].Usage)
+	}
+	return max
+}
+
+func (m *MemoryPeaks) prune() {
+	windowStart := m.peaks[len(m.peaks)-1].MeasureStart.Add(-GetAggregationsConfig().GetMemoryAggregationWindowLength())
+	i := sort.Search(len(m.peaks), func(i int) bool { return m.peaks[i].MeasureStart.After(windowStart) })
+	m.peaks = m.peaks[i:]
+}
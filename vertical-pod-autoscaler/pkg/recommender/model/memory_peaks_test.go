@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryPeaks(t *testing.T) {
+	start := time.Unix(0, 0)
+	day := 24 * time.Hour
+	peaks := MemoryPeaks{}
+	assert.True(t, peaks.IsEmpty())
+	assert.Equal(t, ResourceAmount(0), peaks.MaxOverWindow(day))
+
+	// Peaks are added out of order, as containers of an aggregation report them independently.
+	peaks.AddSample(5, start.Add(day))
+	peaks.AddSample(1, start.Add(3*day))
+	peaks.AddSample(3, start.Add(2*day))
+	assert.Equal(t, ResourceAmount(1), peaks.MaxOverWindow(day))
+	assert.Equal(t, ResourceAmount(3), peaks.MaxOverWindow(2*day))
+	assert.Equal(t, ResourceAmount(5), peaks.MaxOverWindow(3*day))
+
+	// A peak is replaced by a higher one within its interval.
+	peaks.SubtractSample(1, start.Add(3*day))
+	peaks.AddSample(4, start.Add(3*day))
+	assert.Equal(t, ResourceAmount(4), peaks.MaxOverWindow(day))
+
+	// Peaks older than the memory aggregation window are dropped.
+	peaks.AddSample(2, start.Add(day).Add(GetAggregationsConfig().GetMemoryAggregationWindowLength()))
+	assert.Equal(t, ResourceAmount(4), peaks.MaxOverWindow(100*day))
+
+	other := MemoryPeaks{}
+	other.AddSample(6, start.Add(4*day))
+	peaks.Merge(&other)
+	assert.Equal(t, ResourceAmount(6), peaks.MaxOverWindow(100*day))
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package model
 
 import (
+	"math"
+	"strings"
+
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
@@ -73,6 +76,59 @@ func QuantityFromMemoryAmount(memoryAmount ResourceAmount) resource.Quantity {
 	return *resource.NewScaledQuantity(int64(memoryAmount), 0)
 }
 
+// IsExtendedResource returns true if the resource is recommended like CPU and
+// memory despite being neither of them, i.e. it's hugepages or an extended
+// resource, e.g. advertised by a device plugin. Amounts of such resources are
+// kept in their base units (bytes for hugepages).
+func IsExtendedResource(resource ResourceName) bool {
+	name := string(resource)
+	if strings.HasPrefix(name, apiv1.ResourceHugePagesPrefix) {
+		return true
+	}
+	// Extended resources are fully qualified outside of the kubernetes.io domain.
+	return strings.Contains(name, "/") && !strings.HasPrefix(name, apiv1.ResourceDefaultNamespacePrefix) &&
+		!strings.HasPrefix(name, apiv1.DefaultResourceRequestsPrefix)
+}
+
+// ExtendedResourceAmountFromValue converts a value of hugepages or an extended
+// resource in its base units to a ResourceAmount, rounded up to a whole unit, as
+// devices can't be shared and rounding down would starve the container.
+func ExtendedResourceAmountFromValue(value float64) ResourceAmount {
+	return resourceAmountFromFloat(math.Ceil(value))
+}
+
+// QuantityFromExtendedResourceAmount converts ResourceAmount of hugepages or an
+// extended resource to a resource.Quantity. Hugepages are rounded up to a
+// multiple of the page size, as the API rejects other values.
+func QuantityFromExtendedResourceAmount(name ResourceName, amount ResourceAmount) resource.Quantity {
+	if strings.HasPrefix(string(name), apiv1.ResourceHugePagesPrefix) {
+		return *resource.NewQuantity(roundToMultiple(int64(amount), hugePageSize(name)), resource.BinarySI)
+	}
+	return *resource.NewQuantity(int64(amount), resource.DecimalSI)
+}
+
+// hugePageSize returns the page size in bytes of the hugepages resource, e.g.
+// 2Mi for hugepages-2Mi, or 1 if the name doesn't carry a valid size.
+func hugePageSize(name ResourceName) int64 {
+	size, err := resource.ParseQuantity(strings.TrimPrefix(string(name), apiv1.ResourceHugePagesPrefix))
+	if err != nil || size.Value() <= 0 {
+		klog.Warningf("Cannot parse page size of %s, not rounding its recommendation: %v", name, err)
+		return 1
+	}
+	return size.Value()
+}
+
+// roundToMultiple rounds value up to a multiple of unit.
+func roundToMultiple(value, unit int64) int64 {
+	if value <= 0 {
+		return 0
+	}
+	if value > math.MaxInt64-unit+1 {
+		return math.MaxInt64 / unit * unit
+	}
+	return (value + unit - 1) / unit * unit
+}
+
 // ScaleResource returns the resource amount multiplied by a given factor.
 func ScaleResource(amount ResourceAmount, factor float64) ResourceAmount {
 	return resourceAmountFromFloat(float64(amount) * factor)
@@ -92,6 +148,10 @@ func ResourcesAsResourceList(resources Resources) apiv1.ResourceList {
 			newKey = apiv1.ResourceMemory
 			quantity = QuantityFromMemoryAmount(resourceAmount)
 		default:
+			if IsExtendedResource(key) {
+				result[apiv1.ResourceName(key)] = QuantityFromExtendedResourceAmount(key, resourceAmount)
+				continue
+			}
 			klog.Errorf("Cannot translate %v resource name", key)
 			continue
 		}
@@ -110,6 +170,10 @@ func ResourceNamesApiToModel(resources []apiv1.ResourceName) *[]ResourceName {
 		case apiv1.ResourceMemory:
 			result = append(result, ResourceMemory)
 		default:
+			if IsExtendedResource(ResourceName(resource)) {
+				result = append(result, ResourceName(resource))
+				continue
+			}
 			klog.Errorf("Cannot translate %v resource name", resource)
 			continue
 		}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestExtendedResourceAmountFromValue(t *testing.T) {
+	assert.Equal(t, ResourceAmount(1), ExtendedResourceAmountFromValue(1))
+	assert.Equal(t, ResourceAmount(2), ExtendedResourceAmountFromValue(1.15))
+	assert.Equal(t, ResourceAmount(2), ExtendedResourceAmountFromValue(1.5))
+	assert.Equal(t, ResourceAmount(0), ExtendedResourceAmountFromValue(-1))
+}
+
+func TestQuantityFromExtendedResourceAmount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		resource ResourceName
+		amount   ResourceAmount
+		expected resource.Quantity
+	}{
+		{
+			name:     "hugepages rounded up to a multiple of the page size",
+			resource: "hugepages-2Mi",
+			amount:   ResourceAmount(4100 * 1024),
+			expected: resource.MustParse("6Mi"),
+		},
+		{
+			name:     "hugepages already a multiple of the page size",
+			resource: "hugepages-2Mi",
+			amount:   ResourceAmount(4 * 1024 * 1024),
+			expected: resource.MustParse("4Mi"),
+		},
+		{
+			name:     "hugepages not rounded below one page",
+			resource: "hugepages-1Gi",
+			amount:   ResourceAmount(100 * 1024 * 1024),
+			expected: resource.MustParse("1Gi"),
+		},
+		{
+			name:     "no hugepages",
+			resource: "hugepages-1Gi",
+			amount:   0,
+			expected: resource.MustParse("0"),
+		},
+		{
+			name:     "devices",
+			resource: "nvidia.com/gpu",
+			amount:   2,
+			expected: resource.MustParse("2"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quantity := QuantityFromExtendedResourceAmount(tc.resource, tc.amount)
+			assert.Equal(t, tc.expected.Value(), quantity.Value())
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routines
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	vpa_utils "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/vpa"
+)
+
+// CPUGranularityPostProcessor rounds CPU recommendations up to a multiple of the
+// CPU granularity of the container policy, so that recommendations align with
+// CPU pinning requirements. It has to come before capping, so that maxAllowed
+// is still respected.
+type CPUGranularityPostProcessor struct{}
+
+var _ RecommendationPostProcessor = &CPUGranularityPostProcessor{}
+
+// Process rounds CPU recommendations of containers with a CPU granularity.
+func (p *CPUGranularityPostProcessor) Process(vpa *vpa_types.VerticalPodAutoscaler, recommendation *vpa_types.RecommendedPodResources) *vpa_types.RecommendedPodResources {
+	if recommendation == nil || vpa.Spec.ResourcePolicy == nil {
+		return recommendation
+	}
+
+	amendedRecommendation := recommendation.DeepCopy()
+	for _, r := range amendedRecommendation.ContainerRecommendations {
+		policy := vpa_utils.GetContainerResourcePolicy(r.ContainerName, vpa.Spec.ResourcePolicy)
+		if policy == nil || policy.CPUGranularity == nil {
+			continue
+		}
+		granularity := policy.CPUGranularity.MilliValue()
+		if granularity <= 0 {
+			continue
+		}
+		roundUpCPURecommendation(r.Target, granularity)
+		roundUpCPURecommendation(r.LowerBound, granularity)
+		roundUpCPURecommendation(r.UpperBound, granularity)
+		roundUpCPURecommendation(r.UncappedTarget, granularity)
+	}
+	return amendedRecommendation
+}
+
+// roundUpCPURecommendation rounds the CPU recommendation up to a multiple of
+// the granularity in millicores.
+func roundUpCPURecommendation(recommendation apiv1.ResourceList, granularity int64) {
+	recommended, found := recommendation[apiv1.ResourceCPU]
+	if !found {
+		return
+	}
+	milliValue := recommended.MilliValue()
+	if remainder := milliValue % granularity; remainder != 0 {
+		milliValue += granularity - remainder
+	}
+	recommendation[apiv1.ResourceCPU] = *resource.NewMilliQuantity(milliValue, recommended.Format)
+}
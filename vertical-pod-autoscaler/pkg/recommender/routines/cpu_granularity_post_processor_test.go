@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+)
+
+func TestCPUGranularityPostProcessor_Process(t *testing.T) {
+	granularity := func(quantity string) *resource.Quantity {
+		q := resource.MustParse(quantity)
+		return &q
+	}
+	recommendation := func(cpu string) *vpa_types.RecommendedPodResources {
+		return &vpa_types.RecommendedPodResources{
+			ContainerRecommendations: []vpa_types.RecommendedContainerResources{
+				test.Recommendation().WithContainer("c1").WithTarget(cpu, "1Gi").WithLowerBound(cpu, "1Gi").WithUpperBound(cpu, "1Gi").GetContainerResources(),
+				test.Recommendation().WithContainer("c2").WithTarget(cpu, "1Gi").GetContainerResources(),
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		policies   []vpa_types.ContainerResourcePolicy
+		cpu        string
+		expectedC1 string
+		expectedC2 string
+	}{
+		{
+			name:       "no granularity",
+			policies:   []vpa_types.ContainerResourcePolicy{{ContainerName: "c1"}},
+			cpu:        "1234m",
+			expectedC1: "1234m",
+			expectedC2: "1234m",
+		},
+		{
+			name:       "millicore granularity",
+			policies:   []vpa_types.ContainerResourcePolicy{{ContainerName: "c1", CPUGranularity: granularity("100m")}},
+			cpu:        "1234m",
+			expectedC1: "1300m",
+			expectedC2: "1234m",
+		},
+		{
+			name:       "whole cores",
+			policies:   []vpa_types.ContainerResourcePolicy{{ContainerName: "c1", CPUGranularity: granularity("1")}},
+			cpu:        "1234m",
+			expectedC1: "2",
+			expectedC2: "1234m",
+		},
+		{
+			name:       "already aligned",
+			policies:   []vpa_types.ContainerResourcePolicy{{ContainerName: "c1", CPUGranularity: granularity("250m")}},
+			cpu:        "1500m",
+			expectedC1: "1500m",
+			expectedC2: "1500m",
+		},
+		{
+			name: "default policy",
+			policies: []vpa_types.ContainerResourcePolicy{
+				{ContainerName: vpa_types.DefaultContainerResourcePolicy, CPUGranularity: granularity("500m")},
+				{ContainerName: "c1", CPUGranularity: granularity("1")},
+			},
+			cpu:        "1234m",
+			expectedC1: "2",
+			expectedC2: "1500m",
+		},
+	}
+	milliValue := func(quantity string) int64 {
+		q := resource.MustParse(quantity)
+		return q.MilliValue()
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vpa := test.VerticalPodAutoscaler().WithContainer("c1").WithContainer("c2").Get()
+			vpa.Spec.ResourcePolicy = &vpa_types.PodResourcePolicy{ContainerPolicies: tt.policies}
+			processor := CPUGranularityPostProcessor{}
+			got := processor.Process(vpa, recommendation(tt.cpu))
+
+			c1 := got.ContainerRecommendations[0]
+			for _, resources := range []v1.ResourceList{c1.Target, c1.LowerBound, c1.UpperBound} {
+				assert.Equal(t, milliValue(tt.expectedC1), resources.Cpu().MilliValue())
+				assert.Equal(t, int64(1024*1024*1024), resources.Memory().Value())
+			}
+			assert.Equal(t, milliValue(tt.expectedC2), got.ContainerRecommendations[1].Target.Cpu().MilliValue())
+		})
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routines
+
+import (
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	vpa_utils "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/vpa"
+)
+
+// PodLevelPostProcessor adds the pod-level recommendation, the sum of container
+// recommendations, for VPAs with the pod-level policy mode set to "Auto".
+type PodLevelPostProcessor struct{}
+
+var _ RecommendationPostProcessor = &PodLevelPostProcessor{}
+
+// Process sets the pod-level recommendation, or clears it if the VPA doesn't use it.
+func (p PodLevelPostProcessor) Process(vpa *vpa_types.VerticalPodAutoscaler, recommendation *vpa_types.RecommendedPodResources) *vpa_types.RecommendedPodResources {
+	if recommendation == nil {
+		return nil
+	}
+	amendedRecommendation := recommendation.DeepCopy()
+	amendedRecommendation.PodRecommendation = nil
+	if vpa_utils.GetPodLevelScalingMode(vpa.Spec.ResourcePolicy) == vpa_types.PodLevelScalingModeAuto {
+		amendedRecommendation.PodRecommendation = vpa_utils.GetPodLevelRecommendation(amendedRecommendation)
+	}
+	return amendedRecommendation
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+)
+
+func TestPodLevelPostProcessor(t *testing.T) {
+	podLevelAuto := vpa_types.PodLevelScalingModeAuto
+	recommendation := &vpa_types.RecommendedPodResources{
+		ContainerRecommendations: []vpa_types.RecommendedContainerResources{
+			{ContainerName: "c1", Target: test.Resources("1", "1Gi")},
+			{ContainerName: "c2", Target: test.Resources("500m", "1Gi")},
+		},
+		PodRecommendation: &vpa_types.RecommendedPodLevelResources{Target: test.Resources("10", "10Gi")},
+	}
+
+	vpa := test.VerticalPodAutoscaler().WithName("vpa").WithContainer("c1").Get()
+	processed := PodLevelPostProcessor{}.Process(vpa, recommendation)
+	assert.Nil(t, processed.PodRecommendation)
+
+	vpa.Spec.ResourcePolicy = &vpa_types.PodResourcePolicy{
+		PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{Mode: &podLevelAuto},
+	}
+	processed = PodLevelPostProcessor{}.Process(vpa, recommendation)
+	if assert.NotNil(t, processed.PodRecommendation) {
+		assert.Equal(t, int64(1500), processed.PodRecommendation.Target.Cpu().MilliValue())
+		assert.Equal(t, int64(2*1024*1024*1024), processed.PodRecommendation.Target.Memory().Value())
+	}
+	assert.Equal(t, recommendation.ContainerRecommendations, processed.ContainerRecommendations)
+
+	assert.Nil(t, PodLevelPostProcessor{}.Process(vpa, nil))
+}
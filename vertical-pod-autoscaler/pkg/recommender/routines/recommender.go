@@ -23,6 +23,7 @@ import (
 
 	"k8s.io/klog/v2"
 
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	vpa_api "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned/typed/autoscaling.k8s.io/v1"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/checkpoint"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/input"
@@ -63,9 +64,12 @@ type recommender struct {
 	lastCheckpointGC              time.Time
 	vpaClient                     vpa_api.VerticalPodAutoscalersGetter
 	podResourceRecommender        logic.PodResourceRecommender
+	additionalRecommenders        map[string]logic.PodResourceRecommender
 	useCheckpoints                bool
 	lastAggregateContainerStateGC time.Time
 	recommendationPostProcessor   []RecommendationPostProcessor
+	aggregateRecommendations      bool
+	aggregationSelectors          []metrics_recommender.AggregationSelector
 }
 
 func (r *recommender) GetClusterState() *model.ClusterState {
@@ -80,6 +84,11 @@ func (r *recommender) GetClusterStateFeeder() input.ClusterStateFeeder {
 func (r *recommender) UpdateVPAs() {
 	cnt := metrics_recommender.NewObjectCounter()
 	defer cnt.Observe()
+	var aggregator *metrics_recommender.RecommendationAggregator
+	if r.aggregateRecommendations {
+		aggregator = metrics_recommender.NewRecommendationAggregator(r.aggregationSelectors)
+		defer aggregator.Observe()
+	}
 
 	for _, observedVpa := range r.clusterState.ObservedVpas {
 		key := model.VpaID{
@@ -91,7 +100,7 @@ func (r *recommender) UpdateVPAs() {
 		if !found {
 			continue
 		}
-		resources := r.podResourceRecommender.GetRecommendedPodResources(GetContainerNameToAggregateStateMap(vpa))
+		resources := r.podResourceRecommenderFor(observedVpa).GetRecommendedPodResources(GetContainerNameToAggregateStateMap(vpa))
 		had := vpa.HasRecommendation()
 
 		listOfResourceRecommendation := logic.MapToListOfRecommendedContainerResources(resources)
@@ -121,6 +130,9 @@ func (r *recommender) UpdateVPAs() {
 			}
 		}
 		cnt.Add(vpa)
+		if aggregator != nil {
+			aggregator.Add(vpa, observedVpa.Labels)
+		}
 
 		_, err := vpa_utils.UpdateVpaStatusIfNeeded(
 			r.vpaClient.VerticalPodAutoscalers(vpa.ID.Namespace), vpa.ID.VpaName, vpa.AsStatus(), &observedVpa.Status)
@@ -131,6 +143,17 @@ func (r *recommender) UpdateVPAs() {
 	}
 }
 
+// podResourceRecommenderFor returns the first of the additional recommenders
+// selected by the VPA, or the primary recommender if it selects none of them.
+func (r *recommender) podResourceRecommenderFor(vpa *vpa_types.VerticalPodAutoscaler) logic.PodResourceRecommender {
+	for _, selector := range vpa.Spec.Recommenders {
+		if recommender, found := r.additionalRecommenders[selector.Name]; found {
+			return recommender
+		}
+	}
+	return r.podResourceRecommender
+}
+
 func (r *recommender) MaintainCheckpoints(ctx context.Context, minCheckpointsPerRun int) {
 	now := time.Now()
 	if r.useCheckpoints {
@@ -185,10 +208,19 @@ type RecommenderFactory struct {
 	PodResourceRecommender logic.PodResourceRecommender
 	VpaClient              vpa_api.VerticalPodAutoscalersGetter
 
+	// AdditionalPodResourceRecommenders serve VPA objects selecting their
+	// names, instead of PodResourceRecommender.
+	AdditionalPodResourceRecommenders map[string]logic.PodResourceRecommender
+
 	RecommendationPostProcessors []RecommendationPostProcessor
 
 	CheckpointsGCInterval time.Duration
 	UseCheckpoints        bool
+
+	// AggregateRecommendations exports the sums of recommendations per
+	// namespace and per AggregationSelectors as metrics.
+	AggregateRecommendations bool
+	AggregationSelectors     []metrics_recommender.AggregationSelector
 }
 
 // Make creates a new recommender instance,
@@ -203,7 +235,10 @@ func (c RecommenderFactory) Make() Recommender {
 		useCheckpoints:                c.UseCheckpoints,
 		vpaClient:                     c.VpaClient,
 		podResourceRecommender:        c.PodResourceRecommender,
+		additionalRecommenders:        c.AdditionalPodResourceRecommenders,
 		recommendationPostProcessor:   c.RecommendationPostProcessors,
+		aggregateRecommendations:      c.AggregateRecommendations,
+		aggregationSelectors:          c.AggregationSelectors,
 		lastAggregateContainerStateGC: time.Now(),
 		lastCheckpointGC:              time.Now(),
 	}
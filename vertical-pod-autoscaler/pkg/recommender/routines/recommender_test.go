@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routines
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/logic"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+)
+
+type namedPodResourceRecommender struct {
+	name string
+}
+
+func (r *namedPodResourceRecommender) GetRecommendedPodResources(model.ContainerNameToAggregateStateMap) logic.RecommendedPodResources {
+	return logic.RecommendedPodResources{}
+}
+
+func TestPodResourceRecommenderFor(t *testing.T) {
+	primary := &namedPodResourceRecommender{name: "default"}
+	forecast := &namedPodResourceRecommender{name: "forecast"}
+	r := &recommender{
+		podResourceRecommender: primary,
+		additionalRecommenders: map[string]logic.PodResourceRecommender{"forecast": forecast},
+	}
+	vpaSelecting := func(names ...string) *vpa_types.VerticalPodAutoscaler {
+		vpa := &vpa_types.VerticalPodAutoscaler{}
+		for _, name := range names {
+			vpa.Spec.Recommenders = append(vpa.Spec.Recommenders, &vpa_types.VerticalPodAutoscalerRecommenderSelector{Name: name})
+		}
+		return vpa
+	}
+
+	assert.Same(t, primary, r.podResourceRecommenderFor(vpaSelecting()))
+	assert.Same(t, primary, r.podResourceRecommenderFor(vpaSelecting("default")))
+	assert.Same(t, forecast, r.podResourceRecommenderFor(vpaSelecting("forecast")))
+	assert.Same(t, forecast, r.podResourceRecommenderFor(vpaSelecting("unknown", "forecast")))
+}
@@ -62,6 +62,10 @@ func (h *decayingHistogram) Percentile(percentile float64) float64 {
 	return h.histogram.Percentile(percentile)
 }
 
+func (h *decayingHistogram) PercentileBucketStart(percentile float64) float64 {
+	return h.histogram.PercentileBucketStart(percentile)
+}
+
 func (h *decayingHistogram) AddSample(value float64, weight float64, time time.Time) {
 	h.histogram.AddSample(value, weight*h.decayFactor(time), time)
 }
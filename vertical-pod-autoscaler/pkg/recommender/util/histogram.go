@@ -39,6 +39,13 @@ type Histogram interface {
 	// If the histogram is empty, Percentile() returns 0.0.
 	Percentile(percentile float64) float64
 
+	// Returns the start of the bucket holding the given percentile of the
+	// distribution, i.e. its lower bound rather than the upper bound returned
+	// by Percentile(). This suits integer-valued samples, e.g. device counts,
+	// whose buckets may be wider than a unit.
+	// If the histogram is empty, PercentileBucketStart() returns 0.0.
+	PercentileBucketStart(percentile float64) float64
+
 	// Add a sample with a given value and weight.
 	AddSample(value float64, weight float64, time time.Time)
 
@@ -160,6 +167,26 @@ func (h *histogram) Percentile(percentile float64) float64 {
 	if h.IsEmpty() {
 		return 0.0
 	}
+	bucket := h.percentileBucket(percentile)
+	if bucket < h.options.NumBuckets()-1 {
+		// Return the end of the bucket.
+		return h.options.GetBucketStart(bucket + 1)
+	}
+	// Return the start of the last bucket (note that the last bucket
+	// doesn't have an upper bound).
+	return h.options.GetBucketStart(bucket)
+}
+
+func (h *histogram) PercentileBucketStart(percentile float64) float64 {
+	if h.IsEmpty() {
+		return 0.0
+	}
+	return h.options.GetBucketStart(h.percentileBucket(percentile))
+}
+
+// percentileBucket returns the index of the bucket holding the given
+// percentile of a non-empty histogram.
+func (h *histogram) percentileBucket(percentile float64) int {
 	partialSum := 0.0
 	threshold := percentile * h.totalWeight
 	bucket := h.minBucket
@@ -169,13 +196,7 @@ func (h *histogram) Percentile(percentile float64) float64 {
 			break
 		}
 	}
-	if bucket < h.options.NumBuckets()-1 {
-		// Return the end of the bucket.
-		return h.options.GetBucketStart(bucket + 1)
-	}
-	// Return the start of the last bucket (note that the last bucket
-	// doesn't have an upper bound).
-	return h.options.GetBucketStart(bucket)
+	return bucket
 }
 
 func (h *histogram) IsEmpty() bool {
@@ -34,6 +34,12 @@ func (m *MockHistogram) Percentile(percentile float64) float64 {
 	return args.Get(0).(float64)
 }
 
+// PercentileBucketStart is a mock implementation of Histogram.PercentileBucketStart.
+func (m *MockHistogram) PercentileBucketStart(percentile float64) float64 {
+	args := m.Called(percentile)
+	return args.Get(0).(float64)
+}
+
 // AddSample is a mock implementation of Histogram.AddSample.
 func (m *MockHistogram) AddSample(value float64, weight float64, time time.Time) {
 	m.Called(value, weight, time)
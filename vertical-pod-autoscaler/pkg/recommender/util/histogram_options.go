@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 )
 
 // HistogramOptions define the number and size of buckets of a histogram.
@@ -70,6 +71,25 @@ func NewExponentialHistogramOptions(
 	return &exponentialHistogramOptions{numBuckets, firstBucketSize, ratio, epsilon}, nil
 }
 
+// NewIntegerHistogramOptions returns HistogramOptions describing a histogram
+// whose buckets start at whole numbers, so that small integer values, e.g.
+// device counts, each fall to their own bucket. The first bucket covers the
+// range [0..1). Each next bucket is ratio times larger than the start of the
+// previous one, rounded down to a whole number, but at least 1 wide.
+// The last bucket start is larger or equal to maxValue.
+// Requires maxValue > 0, ratio > 1, epsilon > 0.
+func NewIntegerHistogramOptions(maxValue float64, ratio float64, epsilon float64) (HistogramOptions, error) {
+	if maxValue <= 0.0 || ratio <= 1.0 || epsilon <= 0.0 {
+		return nil, errors.New("maxValue and epsilon must be > 0.0, ratio must be > 1.0")
+	}
+	bucketStarts := []float64{0.0}
+	for start := 0.0; start < maxValue; {
+		start += math.Max(1.0, math.Floor(start*(ratio-1)))
+		bucketStarts = append(bucketStarts, start)
+	}
+	return &integerHistogramOptions{bucketStarts, epsilon}, nil
+}
+
 type linearHistogramOptions struct {
 	numBuckets int
 	bucketSize float64
@@ -145,6 +165,35 @@ func (o *exponentialHistogramOptions) Epsilon() float64 {
 	return o.epsilon
 }
 
+type integerHistogramOptions struct {
+	bucketStarts []float64
+	epsilon      float64
+}
+
+func (o *integerHistogramOptions) NumBuckets() int {
+	return len(o.bucketStarts)
+}
+
+func (o *integerHistogramOptions) FindBucket(value float64) int {
+	// Index of the first bucket starting after the value, minus one.
+	bucket := sort.Search(len(o.bucketStarts), func(i int) bool { return o.bucketStarts[i] > value }) - 1
+	if bucket < 0 {
+		return 0
+	}
+	return bucket
+}
+
+func (o *integerHistogramOptions) GetBucketStart(bucket int) float64 {
+	if bucket < 0 || bucket >= len(o.bucketStarts) {
+		panic(fmt.Sprintf("index %d out of range [0..%d]", bucket, len(o.bucketStarts)-1))
+	}
+	return o.bucketStarts[bucket]
+}
+
+func (o *integerHistogramOptions) Epsilon() float64 {
+	return o.epsilon
+}
+
 // Returns the logarithm of x to given base, so that: base^log(base, x) == x.
 func log(base, x float64) float64 {
 	return math.Log(x) / math.Log(base)
@@ -62,3 +62,25 @@ func TestExponentialHistogramOptions(t *testing.T) {
 	assert.Equal(t, 2, o.FindBucket(100.0))
 	assert.Equal(t, 5, o.FindBucket(900.0))
 }
+
+// Test all methods of IntegerHistogramOptions using a sample bucketing scheme.
+func TestIntegerHistogramOptions(t *testing.T) {
+	o, err := NewIntegerHistogramOptions(10.0, 1.5, epsilon)
+	assert.Nil(t, err)
+	assert.Equal(t, epsilon, o.Epsilon())
+	assert.Equal(t, 8, o.NumBuckets())
+
+	for bucket, start := range []float64{0, 1, 2, 3, 4, 6, 9, 13} {
+		assert.Equal(t, start, o.GetBucketStart(bucket))
+	}
+
+	assert.Equal(t, 0, o.FindBucket(-1.0))
+	assert.Equal(t, 0, o.FindBucket(0.99))
+	assert.Equal(t, 1, o.FindBucket(1.0))
+	assert.Equal(t, 4, o.FindBucket(5.0))
+	assert.Equal(t, 5, o.FindBucket(6.0))
+	assert.Equal(t, 7, o.FindBucket(100.0))
+
+	_, err = NewIntegerHistogramOptions(10.0, 1.0, epsilon)
+	assert.NotNil(t, err)
+}
@@ -64,6 +64,20 @@ func TestPercentiles(t *testing.T) {
 	assert.InEpsilon(t, 5, h.Percentile(1.0), valueEpsilon)
 }
 
+// Verifies that PercentileBucketStart() returns the start of the bucket holding
+// the percentile on the following histogram: { 1: 1, 2: 2, 3: 3, 4: 4 }.
+func TestPercentileBucketStart(t *testing.T) {
+	h := NewHistogram(testHistogramOptions)
+	assert.Equal(t, 0.0, h.PercentileBucketStart(0.5))
+	for i := 1; i <= 4; i++ {
+		h.AddSample(float64(i), float64(i), anyTime)
+	}
+	assert.InEpsilon(t, 1, h.PercentileBucketStart(0.0), valueEpsilon)
+	assert.InEpsilon(t, 2, h.PercentileBucketStart(0.2), valueEpsilon)
+	assert.InEpsilon(t, 3, h.PercentileBucketStart(0.5), valueEpsilon)
+	assert.InEpsilon(t, 4, h.PercentileBucketStart(1.0), valueEpsilon)
+}
+
 // Verifies that querying percentile < 0.0 returns the minimum value in the
 // histogram, while querying percentile > 1.0 returns the maximum of the
 // histogram.
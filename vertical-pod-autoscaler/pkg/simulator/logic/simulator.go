@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/input/history"
+	recommender_logic "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/logic"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+	"k8s.io/klog/v2"
+)
+
+// SimulatedRecommendation is the recommendation computed offline for a single container.
+type SimulatedRecommendation struct {
+	// Namespace of the workload.
+	Namespace string
+	// Name identifies the workload, i.e. the VPA name for checkpoints or
+	// the pod selector for Prometheus history.
+	Name string
+	// ContainerName is the name of the container.
+	ContainerName string
+	// Recommendation holds the target, lower and upper bound.
+	Recommendation recommender_logic.RecommendedContainerResources
+}
+
+type workloadID struct {
+	namespace string
+	name      string
+}
+
+// LoadCheckpoints reads VerticalPodAutoscalerCheckpoint objects from a YAML or
+// JSON stream. Both single objects and lists (e.g. the output of
+// `kubectl get verticalpodautoscalercheckpoints -o yaml`) are accepted.
+func LoadCheckpoints(r io.Reader) ([]*vpa_types.VerticalPodAutoscalerCheckpoint, error) {
+	var checkpoints []*vpa_types.VerticalPodAutoscalerCheckpoint
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("cannot decode checkpoints: %v", err)
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, fmt.Errorf("cannot decode object kind: %v", err)
+		}
+		switch typeMeta.Kind {
+		case "VerticalPodAutoscalerCheckpoint":
+			checkpoint := &vpa_types.VerticalPodAutoscalerCheckpoint{}
+			if err := json.Unmarshal(raw, checkpoint); err != nil {
+				return nil, fmt.Errorf("cannot decode checkpoint: %v", err)
+			}
+			checkpoints = append(checkpoints, checkpoint)
+		case "List", "VerticalPodAutoscalerCheckpointList":
+			list := &vpa_types.VerticalPodAutoscalerCheckpointList{}
+			if err := json.Unmarshal(raw, list); err != nil {
+				return nil, fmt.Errorf("cannot decode checkpoint list: %v", err)
+			}
+			for i := range list.Items {
+				checkpoints = append(checkpoints, &list.Items[i])
+			}
+		default:
+			return nil, fmt.Errorf("unsupported object kind %q, expected VerticalPodAutoscalerCheckpoint", typeMeta.Kind)
+		}
+	}
+	return checkpoints, nil
+}
+
+// RecommendFromCheckpoints computes recommendations for the given checkpoints.
+// Checkpoints of the same VPA are grouped together, so that per-pod minimums
+// are split between containers the same way the recommender does it.
+func RecommendFromCheckpoints(checkpoints []*vpa_types.VerticalPodAutoscalerCheckpoint, recommender recommender_logic.PodResourceRecommender) ([]SimulatedRecommendation, error) {
+	workloads := make(map[workloadID]model.ContainerNameToAggregateStateMap)
+	for _, checkpoint := range checkpoints {
+		id := workloadID{namespace: checkpoint.Namespace, name: checkpoint.Spec.VPAObjectName}
+		if _, found := workloads[id]; !found {
+			workloads[id] = make(model.ContainerNameToAggregateStateMap)
+		}
+		state := model.NewAggregateContainerState()
+		if err := state.LoadFromCheckpoint(&checkpoint.Status); err != nil {
+			return nil, fmt.Errorf("cannot load checkpoint %s/%s: %v", checkpoint.Namespace, checkpoint.Name, err)
+		}
+		workloads[id][checkpoint.Spec.ContainerName] = state
+	}
+	return recommend(workloads, recommender), nil
+}
+
+// RecommendFromHistory computes recommendations from usage history fetched from
+// Prometheus. All pods in a namespace matching the selector are treated as a
+// single workload and their samples are aggregated by container name.
+func RecommendFromHistory(clusterHistory map[model.PodID]*history.PodHistory, selector labels.Selector, recommender recommender_logic.PodResourceRecommender) []SimulatedRecommendation {
+	workloads := make(map[workloadID]model.ContainerNameToAggregateStateMap)
+	for podID, podHistory := range clusterHistory {
+		if !selector.Matches(labels.Set(podHistory.LastLabels)) {
+			continue
+		}
+		id := workloadID{namespace: podID.Namespace, name: selector.String()}
+		if _, found := workloads[id]; !found {
+			workloads[id] = make(model.ContainerNameToAggregateStateMap)
+		}
+		for containerName, samples := range podHistory.Samples {
+			aggregation, found := workloads[id][containerName]
+			if !found {
+				aggregation = model.NewAggregateContainerState()
+				workloads[id][containerName] = aggregation
+			}
+			container := model.NewContainerState(nil, aggregation)
+			for i := range samples {
+				if !container.AddSample(&samples[i]) {
+					klog.V(4).Infof("Dropped sample for container %s in pod %s/%s", containerName, podID.Namespace, podID.PodName)
+				}
+			}
+		}
+	}
+	return recommend(workloads, recommender)
+}
+
+func recommend(workloads map[workloadID]model.ContainerNameToAggregateStateMap, recommender recommender_logic.PodResourceRecommender) []SimulatedRecommendation {
+	var result []SimulatedRecommendation
+	for id, containers := range workloads {
+		for containerName, recommendation := range recommender.GetRecommendedPodResources(containers) {
+			result = append(result, SimulatedRecommendation{
+				Namespace:      id.namespace,
+				Name:           id.name,
+				ContainerName:  containerName,
+				Recommendation: recommendation,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		return result[i].ContainerName < result[j].ContainerName
+	})
+	return result
+}
+
+// PrintRecommendations writes the recommendations as a table.
+func PrintRecommendations(w io.Writer, recommendations []SimulatedRecommendation) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tNAME\tCONTAINER\tCPU TARGET\tCPU LOWER\tCPU UPPER\tMEMORY TARGET\tMEMORY LOWER\tMEMORY UPPER")
+	for _, r := range recommendations {
+		target := model.ResourcesAsResourceList(r.Recommendation.Target)
+		lower := model.ResourcesAsResourceList(r.Recommendation.LowerBound)
+		upper := model.ResourcesAsResourceList(r.Recommendation.UpperBound)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Namespace, r.Name, r.ContainerName,
+			quantityString(target.Cpu()), quantityString(lower.Cpu()), quantityString(upper.Cpu()),
+			quantityString(target.Memory()), quantityString(lower.Memory()), quantityString(upper.Memory()))
+	}
+	return tw.Flush()
+}
+
+func quantityString(q *resource.Quantity) string {
+	if q.IsZero() {
+		return "-"
+	}
+	return q.String()
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/input/history"
+	recommender_logic "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/logic"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+)
+
+var testTimestamp = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func usageSamples(cpuCores float64, memoryBytes float64) []model.ContainerUsageSample {
+	var samples []model.ContainerUsageSample
+	for i := 0; i < 24; i++ {
+		start := testTimestamp.Add(time.Duration(i) * time.Hour)
+		samples = append(samples,
+			model.ContainerUsageSample{MeasureStart: start, Usage: model.CPUAmountFromCores(cpuCores), Request: model.CPUAmountFromCores(cpuCores), Resource: model.ResourceCPU},
+			model.ContainerUsageSample{MeasureStart: start, Usage: model.MemoryAmountFromBytes(memoryBytes), Resource: model.ResourceMemory})
+	}
+	return samples
+}
+
+func testCheckpoint(t *testing.T, vpaName, containerName string) vpa_types.VerticalPodAutoscalerCheckpoint {
+	state := model.NewAggregateContainerState()
+	for _, sample := range usageSamples(1.0, 1e9) {
+		sample := sample
+		state.AddSample(&sample)
+	}
+	status, err := state.SaveToCheckpoint()
+	assert.NoError(t, err)
+	return vpa_types.VerticalPodAutoscalerCheckpoint{
+		TypeMeta:   metav1.TypeMeta{Kind: "VerticalPodAutoscalerCheckpoint", APIVersion: "autoscaling.k8s.io/v1"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: vpaName + "-" + containerName},
+		Spec:       vpa_types.VerticalPodAutoscalerCheckpointSpec{VPAObjectName: vpaName, ContainerName: containerName},
+		Status:     *status,
+	}
+}
+
+func TestLoadCheckpoints(t *testing.T) {
+	single, err := json.Marshal(testCheckpoint(t, "vpa", "app"))
+	assert.NoError(t, err)
+	list, err := json.Marshal(vpa_types.VerticalPodAutoscalerCheckpointList{
+		TypeMeta: metav1.TypeMeta{Kind: "List", APIVersion: "v1"},
+		Items:    []vpa_types.VerticalPodAutoscalerCheckpoint{testCheckpoint(t, "vpa", "app"), testCheckpoint(t, "vpa", "sidecar")},
+	})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		input         string
+		expectedCount int
+		expectError   bool
+	}{
+		{
+			name:          "single checkpoint",
+			input:         string(single),
+			expectedCount: 1,
+		},
+		{
+			name:          "checkpoint list",
+			input:         string(list),
+			expectedCount: 2,
+		},
+		{
+			name:          "YAML stream with multiple documents",
+			input:         "kind: VerticalPodAutoscalerCheckpoint\nspec:\n  containerName: app\n---\nkind: VerticalPodAutoscalerCheckpoint\nspec:\n  containerName: sidecar\n",
+			expectedCount: 2,
+		},
+		{
+			name:        "unsupported kind",
+			input:       "kind: Pod\n",
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checkpoints, err := LoadCheckpoints(strings.NewReader(tc.input))
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, checkpoints, tc.expectedCount)
+		})
+	}
+}
+
+func TestRecommendFromCheckpoints(t *testing.T) {
+	app := testCheckpoint(t, "vpa", "app")
+	sidecar := testCheckpoint(t, "vpa", "sidecar")
+	recommendations, err := RecommendFromCheckpoints([]*vpa_types.VerticalPodAutoscalerCheckpoint{&sidecar, &app}, recommender_logic.CreatePodResourceRecommender())
+	assert.NoError(t, err)
+	assert.Len(t, recommendations, 2)
+	assert.Equal(t, "app", recommendations[0].ContainerName)
+	assert.Equal(t, "sidecar", recommendations[1].ContainerName)
+	for _, r := range recommendations {
+		assert.Equal(t, "vpa", r.Name)
+		assert.Greater(t, r.Recommendation.Target[model.ResourceCPU], model.CPUAmountFromCores(1.0))
+		assert.Greater(t, r.Recommendation.Target[model.ResourceMemory], model.MemoryAmountFromBytes(1e9))
+	}
+
+	unsupported := testCheckpoint(t, "vpa", "app")
+	unsupported.Status.Version = "v1"
+	_, err = RecommendFromCheckpoints([]*vpa_types.VerticalPodAutoscalerCheckpoint{&unsupported}, recommender_logic.CreatePodResourceRecommender())
+	assert.Error(t, err)
+}
+
+func TestRecommendFromHistory(t *testing.T) {
+	clusterHistory := map[model.PodID]*history.PodHistory{
+		{Namespace: "default", PodName: "app-1"}: {
+			LastLabels: map[string]string{"app": "app"},
+			Samples:    map[string][]model.ContainerUsageSample{"app": usageSamples(1.0, 1e9)},
+		},
+		{Namespace: "default", PodName: "app-2"}: {
+			LastLabels: map[string]string{"app": "app"},
+			Samples:    map[string][]model.ContainerUsageSample{"app": usageSamples(2.0, 2e9)},
+		},
+		{Namespace: "default", PodName: "other"}: {
+			LastLabels: map[string]string{"app": "other"},
+			Samples:    map[string][]model.ContainerUsageSample{"other": usageSamples(1.0, 1e9)},
+		},
+	}
+	selector, err := labels.Parse("app=app")
+	assert.NoError(t, err)
+
+	recommendations := RecommendFromHistory(clusterHistory, selector, recommender_logic.CreatePodResourceRecommender())
+	assert.Len(t, recommendations, 1)
+	assert.Equal(t, "default", recommendations[0].Namespace)
+	assert.Equal(t, "app=app", recommendations[0].Name)
+	assert.Equal(t, "app", recommendations[0].ContainerName)
+	assert.Greater(t, recommendations[0].Recommendation.Target[model.ResourceCPU], model.CPUAmountFromCores(1.0))
+}
+
+func TestPrintRecommendations(t *testing.T) {
+	var out bytes.Buffer
+	err := PrintRecommendations(&out, []SimulatedRecommendation{
+		{
+			Namespace:     "default",
+			Name:          "vpa",
+			ContainerName: "app",
+			Recommendation: recommender_logic.RecommendedContainerResources{
+				Target: model.Resources{model.ResourceCPU: model.CPUAmountFromCores(0.5)},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, []string{"default", "vpa", "app", "500m", "-", "-", "-", "-", "-"}, strings.Fields(lines[1]))
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kube_flag "k8s.io/component-base/cli/flag"
+	"k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/vertical-pod-autoscaler/common"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/input/history"
+	recommender_logic "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/logic"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/simulator/logic"
+)
+
+// Recommendation tuning flags (percentiles, margin and minimums) are shared with
+// the recommender and registered by the recommender logic package.
+var (
+	checkpointFile = flag.String("checkpoint-file", "", `Path to a file with VerticalPodAutoscalerCheckpoint objects in YAML or JSON, e.g. the output of "kubectl get vpacheckpoints -o yaml". Use "-" to read from stdin.`)
+
+	prometheusAddress   = flag.String("prometheus-address", "", `Where to reach for Prometheus metrics. Used when --checkpoint-file is not set.`)
+	prometheusJobName   = flag.String("prometheus-cadvisor-job-name", "kubernetes-cadvisor", `Name of the prometheus job name which scrapes the cAdvisor metrics`)
+	historyLength       = flag.String("history-length", "8d", `How much time back prometheus have to be queried to get historical metrics`)
+	historyResolution   = flag.String("history-resolution", "1h", `Resolution at which Prometheus is queried for historical metrics`)
+	queryTimeout        = flag.Duration("prometheus-query-timeout", 5*time.Minute, `How long to wait before killing long queries`)
+	podLabelPrefix      = flag.String("pod-label-prefix", "pod_label_", `Which prefix to look for pod labels in metrics`)
+	podLabelsMetricName = flag.String("metric-for-pod-labels", "up{job=\"kubernetes-pods\"}", `Which metric to look for pod labels in metrics`)
+	podNamespaceLabel   = flag.String("pod-namespace-label", "kubernetes_namespace", `Label name to look for pod namespaces`)
+	podNameLabel        = flag.String("pod-name-label", "kubernetes_pod_name", `Label name to look for pod names`)
+	ctrNamespaceLabel   = flag.String("container-namespace-label", "namespace", `Label name to look for container namespaces`)
+	ctrPodNameLabel     = flag.String("container-pod-name-label", "pod_name", `Label name to look for container pod names`)
+	ctrNameLabel        = flag.String("container-name-label", "name", `Label name to look for container names`)
+	username            = flag.String("username", "", "The username used in the prometheus server basic auth")
+	password            = flag.String("password", "", "The password used in the prometheus server basic auth")
+	namespace           = flag.String("namespace", apiv1.NamespaceAll, "Namespace to query pod stats from. Empty means all namespaces will be used.")
+	podSelector         = flag.String("pod-selector", "", "Label selector for pods whose usage should be aggregated into one recommendation per namespace and container name. Empty means all pods.")
+)
+
+// Aggregation configuration flags
+var (
+	memoryAggregationInterval      = flag.Duration("memory-aggregation-interval", model.DefaultMemoryAggregationInterval, `The length of a single interval, for which the peak memory usage is computed. Memory usage peaks are aggregated in multiples of this interval. In other words there is one memory usage sample per interval (the maximum usage over that interval)`)
+	memoryAggregationIntervalCount = flag.Int64("memory-aggregation-interval-count", model.DefaultMemoryAggregationIntervalCount, `The number of consecutive memory-aggregation-intervals which make up the MemoryAggregationWindowLength which in turn is the period for memory usage aggregation by VPA. In other words, MemoryAggregationWindowLength = memory-aggregation-interval * memory-aggregation-interval-count.`)
+	memoryHistogramDecayHalfLife   = flag.Duration("memory-histogram-decay-half-life", model.DefaultMemoryHistogramDecayHalfLife, `The amount of time it takes a historical memory usage sample to lose half of its weight. In other words, a fresh usage sample is twice as 'important' as one with age equal to the half life period.`)
+	cpuHistogramDecayHalfLife      = flag.Duration("cpu-histogram-decay-half-life", model.DefaultCPUHistogramDecayHalfLife, `The amount of time it takes a historical CPU usage sample to lose half of its weight.`)
+	oomBumpUpRatio                 = flag.Float64("oom-bump-up-ratio", model.DefaultOOMBumpUpRatio, `The memory bump up ratio when OOM occurred, default is 1.2.`)
+	oomMinBumpUp                   = flag.Float64("oom-min-bump-up-bytes", model.DefaultOOMMinBumpUp, `The minimal increase of memory when OOM occurred in bytes, default is 100 * 1024 * 1024`)
+)
+
+func main() {
+	klog.InitFlags(nil)
+	kube_flag.InitFlags()
+	klog.V(1).Infof("Vertical Pod Autoscaler %s Simulator", common.VerticalPodAutoscalerVersion)
+
+	model.InitializeAggregationsConfig(model.NewAggregationsConfig(*memoryAggregationInterval, *memoryAggregationIntervalCount, *memoryHistogramDecayHalfLife, *cpuHistogramDecayHalfLife, *oomBumpUpRatio, *oomMinBumpUp))
+	recommender := recommender_logic.CreatePodResourceRecommender()
+
+	var recommendations []logic.SimulatedRecommendation
+	switch {
+	case *checkpointFile != "":
+		input := os.Stdin
+		if *checkpointFile != "-" {
+			f, err := os.Open(*checkpointFile)
+			if err != nil {
+				klog.Fatalf("Cannot open checkpoint file: %v", err)
+			}
+			defer f.Close()
+			input = f
+		}
+		checkpoints, err := logic.LoadCheckpoints(input)
+		if err != nil {
+			klog.Fatalf("Cannot load checkpoints: %v", err)
+		}
+		recommendations, err = logic.RecommendFromCheckpoints(checkpoints, recommender)
+		if err != nil {
+			klog.Fatalf("Cannot compute recommendations: %v", err)
+		}
+	case *prometheusAddress != "":
+		selector, err := labels.Parse(*podSelector)
+		if err != nil {
+			klog.Fatalf("Cannot parse --pod-selector: %v", err)
+		}
+		provider, err := history.NewPrometheusHistoryProvider(history.PrometheusHistoryProviderConfig{
+			Address:                *prometheusAddress,
+			QueryTimeout:           *queryTimeout,
+			HistoryLength:          *historyLength,
+			HistoryResolution:      *historyResolution,
+			PodLabelPrefix:         *podLabelPrefix,
+			PodLabelsMetricName:    *podLabelsMetricName,
+			PodNamespaceLabel:      *podNamespaceLabel,
+			PodNameLabel:           *podNameLabel,
+			CtrNamespaceLabel:      *ctrNamespaceLabel,
+			CtrPodNameLabel:        *ctrPodNameLabel,
+			CtrNameLabel:           *ctrNameLabel,
+			CadvisorMetricsJobName: *prometheusJobName,
+			Namespace:              *namespace,
+			PrometheusBasicAuthTransport: history.PrometheusBasicAuthTransport{
+				Username: *username,
+				Password: *password,
+			},
+		})
+		if err != nil {
+			klog.Fatalf("Could not initialize history provider: %v", err)
+		}
+		clusterHistory, err := provider.GetClusterHistory()
+		if err != nil {
+			klog.Fatalf("Cannot get cluster history: %v", err)
+		}
+		recommendations = logic.RecommendFromHistory(clusterHistory, selector, recommender)
+	default:
+		klog.Fatalf("Either --checkpoint-file or --prometheus-address has to be set")
+	}
+
+	if err := logic.PrintRecommendations(os.Stdout, recommendations); err != nil {
+		klog.Fatalf("Cannot print recommendations: %v", err)
+	}
+}
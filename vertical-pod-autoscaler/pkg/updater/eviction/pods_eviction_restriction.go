@@ -25,6 +25,7 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	appsinformer "k8s.io/client-go/informers/apps/v1"
 	coreinformer "k8s.io/client-go/informers/core/v1"
@@ -36,6 +37,10 @@ import (
 
 const (
 	resyncPeriod time.Duration = 1 * time.Minute
+	// EvictedAtAnnotation is set on pods right before they're evicted, if marking evicted
+	// pods is enabled. Its value is the eviction time. Cluster Autoscaler reads it to
+	// avoid draining nodes with other replicas of a workload which is being evicted.
+	EvictedAtAnnotation = "vpa-updater.k8s.io/evicted-at"
 )
 
 // PodsEvictionRestriction controls pods evictions. It ensures that we will not evict too
@@ -51,6 +56,7 @@ type PodsEvictionRestriction interface {
 
 type podsEvictionRestrictionImpl struct {
 	client                       kube_client.Interface
+	markEvictedPods              bool
 	podToReplicaCreatorMap       map[string]podReplicaCreator
 	creatorToSingleGroupStatsMap map[podReplicaCreator]singleGroupStats
 }
@@ -78,6 +84,7 @@ type podsEvictionRestrictionFactoryImpl struct {
 	dsInformer                cache.SharedIndexInformer // informer for Daemon Sets
 	minReplicas               int
 	evictionToleranceFraction float64
+	markEvictedPods           bool
 }
 
 type controllerKind string
@@ -132,6 +139,13 @@ func (e *podsEvictionRestrictionImpl) Evict(podToEvict *apiv1.Pod, eventRecorder
 		return fmt.Errorf("cannot evict pod %s/%s: eviction budget exceeded", podToEvict.Namespace, podToEvict.Name)
 	}
 
+	if e.markEvictedPods {
+		if err := markEvicted(e.client, podToEvict); err != nil {
+			klog.Errorf("failed to mark pod %s/%s as evicted, error: %v", podToEvict.Namespace, podToEvict.Name, err)
+			return err
+		}
+	}
+
 	eviction := &policyv1.Eviction{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: podToEvict.Namespace,
@@ -158,9 +172,17 @@ func (e *podsEvictionRestrictionImpl) Evict(podToEvict *apiv1.Pod, eventRecorder
 	return nil
 }
 
-// NewPodsEvictionRestrictionFactory creates PodsEvictionRestrictionFactory
+// markEvicted sets EvictedAtAnnotation on the pod.
+func markEvicted(client kube_client.Interface, pod *apiv1.Pod) error {
+	patch := fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, EvictedAtAnnotation, time.Now().UTC().Format(time.RFC3339))
+	_, err := client.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+// NewPodsEvictionRestrictionFactory creates PodsEvictionRestrictionFactory. If markEvictedPods
+// is true, pods are annotated with EvictedAtAnnotation before being evicted.
 func NewPodsEvictionRestrictionFactory(client kube_client.Interface, minReplicas int,
-	evictionToleranceFraction float64) (PodsEvictionRestrictionFactory, error) {
+	evictionToleranceFraction float64, markEvictedPods bool) (PodsEvictionRestrictionFactory, error) {
 	rcInformer, err := setUpInformer(client, replicationController)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create rcInformer: %v", err)
@@ -184,7 +206,8 @@ func NewPodsEvictionRestrictionFactory(client kube_client.Interface, minReplicas
 		rsInformer:                rsInformer, // informer for Stateful Sets
 		dsInformer:                dsInformer, // informer for Daemon Sets
 		minReplicas:               minReplicas,
-		evictionToleranceFraction: evictionToleranceFraction}, nil
+		evictionToleranceFraction: evictionToleranceFraction,
+		markEvictedPods:           markEvictedPods}, nil
 }
 
 // NewPodsEvictionRestriction creates PodsEvictionRestriction for a given set of pods,
@@ -256,6 +279,7 @@ func (f *podsEvictionRestrictionFactoryImpl) NewPodsEvictionRestriction(pods []*
 	}
 	return &podsEvictionRestrictionImpl{
 		client:                       f.client,
+		markEvictedPods:              f.markEvictedPods,
 		podToReplicaCreatorMap:       podToReplicaCreatorMap,
 		creatorToSingleGroupStatsMap: creatorToSingleGroupStatsMap}
 }
@@ -31,6 +31,7 @@ import (
 	appsinformer "k8s.io/client-go/informers/apps/v1"
 	coreinformer "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 )
 
@@ -608,3 +609,49 @@ func getEvictionRestrictionFactory(rc *apiv1.ReplicationController, rs *appsv1.R
 func getTestPodName(index int) string {
 	return fmt.Sprintf("test-%v", index)
 }
+
+func TestEvictMarksEvictedPods(t *testing.T) {
+	replicas := int32(5)
+	livePods := 5
+
+	rs := appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rs",
+			Namespace: "default",
+		},
+		TypeMeta: metav1.TypeMeta{
+			Kind: "ReplicaSet",
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: &replicas,
+		},
+	}
+
+	pods := make([]*apiv1.Pod, livePods)
+	for i := range pods {
+		pods[i] = test.Pod().WithName(getTestPodName(i)).WithCreator(&rs.ObjectMeta, &rs.TypeMeta).Get()
+	}
+
+	for _, markEvictedPods := range []bool{false, true} {
+		t.Run(fmt.Sprintf("markEvictedPods=%v", markEvictedPods), func(t *testing.T) {
+			factory, _ := getEvictionRestrictionFactory(nil, &rs, nil, nil, 2, 0.5)
+			factoryImpl := factory.(*podsEvictionRestrictionFactoryImpl)
+			factoryImpl.markEvictedPods = markEvictedPods
+			eviction := factory.NewPodsEvictionRestriction(pods, getBasicVpa())
+
+			err := eviction.Evict(pods[0], test.FakeEventRecorder())
+			assert.NoError(t, err)
+
+			var patched bool
+			for _, action := range factoryImpl.client.(*fake.Clientset).Actions() {
+				if action.GetVerb() != "patch" {
+					continue
+				}
+				patched = true
+				assert.Equal(t, pods[0].Name, action.(clienttesting.PatchAction).GetName())
+				assert.Contains(t, string(action.(clienttesting.PatchAction).GetPatch()), EvictedAtAnnotation)
+			}
+			assert.Equal(t, markEvictedPods, patched)
+		})
+	}
+}
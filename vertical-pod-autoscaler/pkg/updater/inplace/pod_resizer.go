@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inplace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/admission-controller/resource/pod/recommendation"
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	vpa_api_util "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/vpa"
+)
+
+// PodResizer applies recommended resources to pods using in-place pod resize.
+type PodResizer interface {
+	// CanResize returns true if the recommended resources can be applied to
+	// the pod in place, without restarting any of its containers.
+	CanResize(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler) bool
+	// Resize updates resources of the pod containers to the recommended ones.
+	// Only resources in UpdateModeInPlace are updated.
+	Resize(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler, eventRecorder record.EventRecorder) error
+}
+
+type podResizer struct {
+	client                 kube_client.Interface
+	recommendationProvider recommendation.Provider
+}
+
+// NewPodResizer creates a PodResizer computing the recommended resources the
+// same way the admission controller does for new pods.
+func NewPodResizer(client kube_client.Interface, recommendationProvider recommendation.Provider) PodResizer {
+	return &podResizer{
+		client:                 client,
+		recommendationProvider: recommendationProvider,
+	}
+}
+
+// CanResize returns false if the last resize of the pod was infeasible, or if
+// the containerResizePolicy of any resized resource requires a container restart.
+func (r *podResizer) CanResize(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler) bool {
+	if pod.Status.Resize == apiv1.PodResizeStatusInfeasible {
+		klog.V(4).Infof("can't resize pod %s in place, its last resize was infeasible", klog.KObj(pod))
+		return false
+	}
+	resized, err := r.resizedPod(pod, vpa)
+	if err != nil {
+		klog.V(2).Infof("can't resize pod %s in place: %v", klog.KObj(pod), err)
+		return false
+	}
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		for _, resource := range changedResources(container.Resources, resized.Spec.Containers[i].Resources) {
+			if policy := resizeRestartPolicy(container, resource); policy != apiv1.NotRequired {
+				klog.V(4).Infof("can't resize %s of container %s of pod %s in place, its resize restart policy is %s",
+					resource, container.Name, klog.KObj(pod), policy)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Resize patches the pod spec with the recommended resources through the resize
+// subresource, falling back to patching the pod on clusters without it. Kubelet
+// resizes the containers asynchronously, reporting the progress in the pod status.
+func (r *podResizer) Resize(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler, eventRecorder record.EventRecorder) error {
+	resized, err := r.resizedPod(pod, vpa)
+	if err != nil {
+		return err
+	}
+	patch, err := resizePatch(pod, resized)
+	if err != nil {
+		return err
+	}
+	pods := r.client.CoreV1().Pods(pod.Namespace)
+	_, err = pods.Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}, "resize")
+	if errors.IsNotFound(err) {
+		// Clusters older than 1.33 don't serve the resize subresource, resources
+		// are changed by patching the pod there.
+		klog.V(4).Infof("resize subresource not found for pod %s, patching the pod", klog.KObj(pod))
+		_, err = pods.Patch(context.TODO(), pod.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	}
+	if err != nil {
+		klog.Errorf("failed to resize pod %s in place, error: %v", klog.KObj(pod), err)
+		return err
+	}
+	eventRecorder.Event(pod, apiv1.EventTypeNormal, "ResizedByVPA",
+		"Pod was resized in place by VPA Updater to apply resource recommendation.")
+	return nil
+}
+
+// resizePatch returns a strategic merge patch changing the pod into the resized one.
+func resizePatch(pod, resized *apiv1.Pod) ([]byte, error) {
+	current, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := json.Marshal(resized)
+	if err != nil {
+		return nil, err
+	}
+	return strategicpatch.CreateTwoWayMergePatch(current, modified, apiv1.Pod{})
+}
+
+// resizedPod returns a copy of the pod with the recommended resources set for
+// resources in UpdateModeInPlace.
+func (r *podResizer) resizedPod(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler) (*apiv1.Pod, error) {
+	containersResources, _, err := r.recommendationProvider.GetContainersResourcesForPod(pod, vpa)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommended resources: %v", err)
+	}
+	resized := pod.DeepCopy()
+	for i, resources := range containersResources {
+		if i >= len(resized.Spec.Containers) {
+			break
+		}
+		container := &resized.Spec.Containers[i]
+		container.Resources.Requests = updateInPlaceResources(container.Resources.Requests, resources.Requests, vpa, pod)
+		container.Resources.Limits = updateInPlaceResources(container.Resources.Limits, resources.Limits, vpa, pod)
+	}
+	return resized, nil
+}
+
+func updateInPlaceResources(current, recommended apiv1.ResourceList, vpa *vpa_types.VerticalPodAutoscaler, pod *apiv1.Pod) apiv1.ResourceList {
+	for resource, quantity := range recommended {
+		if vpa_api_util.GetPodResourceUpdateMode(vpa, pod, resource) != vpa_types.UpdateModeInPlace {
+			continue
+		}
+		if current == nil {
+			current = apiv1.ResourceList{}
+		}
+		current[resource] = quantity
+	}
+	return current
+}
+
+// changedResources returns names of resources with different requests or limits.
+func changedResources(current, resized apiv1.ResourceRequirements) []apiv1.ResourceName {
+	changed := make(map[apiv1.ResourceName]bool)
+	for _, lists := range [][2]apiv1.ResourceList{{current.Requests, resized.Requests}, {current.Limits, resized.Limits}} {
+		for resource, quantity := range lists[1] {
+			if currentQuantity, found := lists[0][resource]; !found || currentQuantity.Cmp(quantity) != 0 {
+				changed[resource] = true
+			}
+		}
+	}
+	result := make([]apiv1.ResourceName, 0, len(changed))
+	for resource := range changed {
+		result = append(result, resource)
+	}
+	return result
+}
+
+// resizeRestartPolicy returns the restart policy of the container for resizing
+// the resource, NotRequired if not specified.
+func resizeRestartPolicy(container *apiv1.Container, resource apiv1.ResourceName) apiv1.ResourceResizeRestartPolicy {
+	for _, policy := range container.ResizePolicy {
+		if policy.ResourceName == resource && policy.RestartPolicy != "" {
+			return policy.RestartPolicy
+		}
+	}
+	return apiv1.NotRequired
+}
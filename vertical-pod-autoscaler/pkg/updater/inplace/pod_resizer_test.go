@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inplace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+	vpa_api_util "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/vpa"
+)
+
+type fakeRecommendationProvider struct {
+	resources []vpa_api_util.ContainerResources
+}
+
+func (frp *fakeRecommendationProvider) GetContainersResourcesForPod(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler) ([]vpa_api_util.ContainerResources, vpa_api_util.ContainerToAnnotationsMap, error) {
+	return frp.resources, nil, nil
+}
+
+func testPod(resizePolicy ...apiv1.ContainerResizePolicy) *apiv1.Pod {
+	container := test.Container().WithName("container").
+		WithCPURequest(resource.MustParse("1")).WithMemRequest(resource.MustParse("100M")).Get()
+	container.ResizePolicy = resizePolicy
+	pod := test.Pod().WithName("pod").AddContainer(container).Get()
+	pod.Namespace = "default"
+	return pod
+}
+
+func TestCanResize(t *testing.T) {
+	restartMemory := apiv1.ContainerResizePolicy{ResourceName: apiv1.ResourceMemory, RestartPolicy: apiv1.RestartContainer}
+	notRequiredMemory := apiv1.ContainerResizePolicy{ResourceName: apiv1.ResourceMemory, RestartPolicy: apiv1.NotRequired}
+	infeasible := testPod()
+	infeasible.Status.Resize = apiv1.PodResizeStatusInfeasible
+
+	testCases := []struct {
+		name        string
+		pod         *apiv1.Pod
+		recommended apiv1.ResourceList
+		expected    bool
+	}{
+		{
+			name:        "no resize policy",
+			pod:         testPod(),
+			recommended: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("200M")},
+			expected:    true,
+		},
+		{
+			name:        "resize not requiring restart",
+			pod:         testPod(notRequiredMemory),
+			recommended: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("200M")},
+			expected:    true,
+		},
+		{
+			name:        "resize requiring restart",
+			pod:         testPod(restartMemory),
+			recommended: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("200M")},
+			expected:    false,
+		},
+		{
+			name:        "restart policy of unchanged resource",
+			pod:         testPod(restartMemory),
+			recommended: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("100M")},
+			expected:    true,
+		},
+		{
+			name:        "infeasible resize",
+			pod:         infeasible,
+			recommended: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("200M")},
+			expected:    false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vpa := test.VerticalPodAutoscaler().WithContainer("container").WithUpdateMode(vpa_types.UpdateModeInPlace).Get()
+			provider := &fakeRecommendationProvider{resources: []vpa_api_util.ContainerResources{{Requests: tc.recommended}}}
+			resizer := NewPodResizer(fake.NewSimpleClientset(), provider)
+			assert.Equal(t, tc.expected, resizer.CanResize(tc.pod, vpa))
+		})
+	}
+}
+
+func TestResize(t *testing.T) {
+	for _, tc := range []struct {
+		name                     string
+		resizeSubresourceMissing bool
+		expectedSubresources     []string
+	}{
+		{
+			name:                 "resize subresource",
+			expectedSubresources: []string{"resize"},
+		},
+		{
+			name:                     "resize subresource not served",
+			resizeSubresourceMissing: true,
+			expectedSubresources:     []string{"resize", ""},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := testPod()
+			client := fake.NewSimpleClientset(pod)
+			var subresources []string
+			client.PrependReactor("patch", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				subresources = append(subresources, action.GetSubresource())
+				if tc.resizeSubresourceMissing && action.GetSubresource() == "resize" {
+					return true, nil, errors.NewNotFound(apiv1.Resource("pods/resize"), pod.Name)
+				}
+				return false, nil, nil
+			})
+			vpa := test.VerticalPodAutoscaler().WithContainer("container").
+				WithUpdateMode(vpa_types.UpdateModeInPlace).
+				WithResourceUpdateMode(apiv1.ResourceMemory, vpa_types.UpdateModeInitial).Get()
+			provider := &fakeRecommendationProvider{resources: []vpa_api_util.ContainerResources{{
+				Requests: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("2"), apiv1.ResourceMemory: resource.MustParse("200M")},
+			}}}
+			recorder := record.NewFakeRecorder(1)
+
+			err := NewPodResizer(client, provider).Resize(pod, vpa, recorder)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSubresources, subresources)
+
+			updated, err := client.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			require.NoError(t, err)
+			requests := updated.Spec.Containers[0].Resources.Requests
+			assert.Equal(t, resource.MustParse("2"), requests[apiv1.ResourceCPU])
+			// Memory in the initial update mode isn't resized.
+			assert.Equal(t, resource.MustParse("100M"), requests[apiv1.ResourceMemory])
+			assert.Len(t, recorder.Events, 1)
+			// The pod from the lister isn't modified.
+			assert.Equal(t, resource.MustParse("1"), pod.Spec.Containers[0].Resources.Requests[apiv1.ResourceCPU])
+		})
+	}
+}
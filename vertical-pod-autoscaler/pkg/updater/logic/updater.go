@@ -41,6 +41,7 @@ import (
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/target"
 	controllerfetcher "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/target/controller_fetcher"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/updater/eviction"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/updater/inplace"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/updater/priority"
 	metrics_updater "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/metrics/updater"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/status"
@@ -58,6 +59,7 @@ type updater struct {
 	podLister                    v1lister.PodLister
 	eventRecorder                record.EventRecorder
 	evictionFactory              eviction.PodsEvictionRestrictionFactory
+	podResizer                   inplace.PodResizer
 	recommendationProcessor      vpa_api_util.RecommendationProcessor
 	evictionAdmission            priority.PodEvictionAdmission
 	priorityProcessor            priority.PriorityProcessor
@@ -76,6 +78,7 @@ func NewUpdater(
 	evictionRateLimit float64,
 	evictionRateBurst int,
 	evictionToleranceFraction float64,
+	markEvictedPods bool,
 	useAdmissionControllerStatus bool,
 	statusNamespace string,
 	recommendationProcessor vpa_api_util.RecommendationProcessor,
@@ -83,18 +86,25 @@ func NewUpdater(
 	selectorFetcher target.VpaTargetSelectorFetcher,
 	controllerFetcher controllerfetcher.ControllerFetcher,
 	priorityProcessor priority.PriorityProcessor,
+	podResizer inplace.PodResizer,
 	namespace string,
+	ignoredNamespaceSelector string,
 ) (Updater, error) {
 	evictionRateLimiter := getRateLimiter(evictionRateLimit, evictionRateBurst)
-	factory, err := eviction.NewPodsEvictionRestrictionFactory(kubeClient, minReplicasForEvicition, evictionToleranceFraction)
+	factory, err := eviction.NewPodsEvictionRestrictionFactory(kubeClient, minReplicasForEvicition, evictionToleranceFraction, markEvictedPods)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to create eviction restriction factory: %v", err)
 	}
+	vpaLister, err := vpa_api_util.WithIgnoredNamespaces(vpa_api_util.NewVpasLister(vpaClient, make(chan struct{}), namespace), kubeClient, make(chan struct{}), ignoredNamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
 	return &updater{
-		vpaLister:                    vpa_api_util.NewVpasLister(vpaClient, make(chan struct{}), namespace),
+		vpaLister:                    vpaLister,
 		podLister:                    newPodLister(kubeClient, namespace),
 		eventRecorder:                newEventRecorder(kubeClient),
 		evictionFactory:              factory,
+		podResizer:                   podResizer,
 		recommendationProcessor:      recommendationProcessor,
 		evictionRateLimiter:          evictionRateLimiter,
 		evictionAdmission:            evictionAdmission,
@@ -137,9 +147,8 @@ func (u *updater) RunOnce(ctx context.Context) {
 	vpas := make([]*vpa_api_util.VpaWithSelector, 0)
 
 	for _, vpa := range vpaList {
-		if vpa_api_util.GetUpdateMode(vpa) != vpa_types.UpdateModeRecreate &&
-			vpa_api_util.GetUpdateMode(vpa) != vpa_types.UpdateModeAuto {
-			klog.V(3).Infof("skipping VPA object %s because its mode is not \"Recreate\" or \"Auto\"", klog.KObj(vpa))
+		if updateMode := vpa_api_util.GetMaxUpdateMode(vpa); !isUpdatedByUpdater(updateMode) {
+			klog.V(3).Infof("skipping VPA object %s because its mode is not \"InPlace\", \"Recreate\" or \"Auto\"", klog.KObj(vpa))
 			continue
 		}
 		selector, err := u.selectorFetcher.Fetch(vpa)
@@ -173,9 +182,14 @@ func (u *updater) RunOnce(ctx context.Context) {
 	controlledPods := make(map[*vpa_types.VerticalPodAutoscaler][]*apiv1.Pod)
 	for _, pod := range allLivePods {
 		controllingVPA := vpa_api_util.GetControllingVPAForPod(pod, vpas, u.controllerFetcher)
-		if controllingVPA != nil {
-			controlledPods[controllingVPA.Vpa] = append(controlledPods[controllingVPA.Vpa], pod)
+		if controllingVPA == nil {
+			continue
+		}
+		if podUpdateMode := vpa_api_util.GetPodUpdateMode(controllingVPA.Vpa, pod); !isUpdatedByUpdater(podUpdateMode) {
+			klog.V(4).Infof("skipping pod %s because its update mode is %q", klog.KObj(pod), podUpdateMode)
+			continue
 		}
+		controlledPods[controllingVPA.Vpa] = append(controlledPods[controllingVPA.Vpa], pod)
 	}
 	timer.ObserveStep("FilterPods")
 
@@ -197,18 +211,34 @@ func (u *updater) RunOnce(ctx context.Context) {
 	defer vpasWithEvictedPodsCounter.Observe()
 
 	// NOTE: this loop assumes that controlledPods are filtered
-	// to contain only Pods controlled by a VPA in auto or recreate mode
+	// to contain only Pods controlled by a VPA in auto, recreate or in-place
+	// mode and not frozen by the VPA's job update mode
 	for vpa, livePods := range controlledPods {
 		vpaSize := len(livePods)
 		controlledPodsCounter.Add(vpaSize, vpaSize)
 		evictionLimiter := u.evictionFactory.NewPodsEvictionRestriction(livePods, vpa)
-		podsForUpdate := u.getPodsUpdateOrder(filterNonEvictablePods(livePods, evictionLimiter), vpa)
+		inPlacePods := u.getInPlaceResizablePods(livePods, vpa)
+		podsForUpdate := u.getPodsUpdateOrder(filterNonUpdatablePods(livePods, inPlacePods, evictionLimiter), vpa)
 		evictablePodsCounter.Add(vpaSize, len(podsForUpdate))
 
 		withEvictable := false
 		withEvicted := false
 		for _, pod := range podsForUpdate {
 			withEvictable = true
+			if inPlacePods[pod] {
+				err := u.evictionRateLimiter.Wait(ctx)
+				if err != nil {
+					klog.Warningf("resizing pod %s failed: %v", klog.KObj(pod), err)
+					return
+				}
+				klog.V(2).Infof("resizing pod %s in place", klog.KObj(pod))
+				if resizeErr := u.podResizer.Resize(pod, vpa, u.eventRecorder); resizeErr != nil {
+					klog.Warningf("resizing pod %s failed: %v", klog.KObj(pod), resizeErr)
+				} else {
+					metrics_updater.AddInPlaceUpdatedPod(vpaSize)
+				}
+				continue
+			}
 			if !evictionLimiter.CanEvict(pod) {
 				continue
 			}
@@ -265,10 +295,35 @@ func (u *updater) getPodsUpdateOrder(pods []*apiv1.Pod, vpa *vpa_types.VerticalP
 	return priorityCalculator.GetSortedPods(u.evictionAdmission)
 }
 
-func filterNonEvictablePods(pods []*apiv1.Pod, evictionRestriction eviction.PodsEvictionRestriction) []*apiv1.Pod {
+// isUpdatedByUpdater returns true if pods in the update mode are updated by
+// Updater, by eviction or in place.
+func isUpdatedByUpdater(updateMode vpa_types.UpdateMode) bool {
+	return updateMode == vpa_types.UpdateModeInPlace || updateMode == vpa_types.UpdateModeRecreate ||
+		updateMode == vpa_types.UpdateModeAuto
+}
+
+// getInPlaceResizablePods returns pods in the in-place update mode which can
+// be resized without restarting their containers. Other pods fall back to
+// eviction.
+func (u *updater) getInPlaceResizablePods(pods []*apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler) map[*apiv1.Pod]bool {
+	result := make(map[*apiv1.Pod]bool)
+	if u.podResizer == nil {
+		return result
+	}
+	for _, pod := range pods {
+		if vpa_api_util.GetPodUpdateMode(vpa, pod) == vpa_types.UpdateModeInPlace && u.podResizer.CanResize(pod, vpa) {
+			result[pod] = true
+		}
+	}
+	return result
+}
+
+// filterNonUpdatablePods filters out pods which can be neither resized in
+// place nor evicted.
+func filterNonUpdatablePods(pods []*apiv1.Pod, inPlacePods map[*apiv1.Pod]bool, evictionRestriction eviction.PodsEvictionRestriction) []*apiv1.Pod {
 	result := make([]*apiv1.Pod, 0)
 	for _, pod := range pods {
-		if evictionRestriction.CanEvict(pod) {
+		if inPlacePods[pod] || evictionRestriction.CanEvict(pod) {
 			result = append(result, pod)
 		}
 	}
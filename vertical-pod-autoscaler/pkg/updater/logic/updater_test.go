@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
 
 	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	controllerfetcher "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/target/controller_fetcher"
@@ -204,6 +205,112 @@ func testRunOnceBase(
 	eviction.AssertNumberOfCalls(t, "Evict", expectedEvictionCount)
 }
 
+func TestRunOnce_FrozenJobPods(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	isController := true
+	containerName := "container1"
+	pods := make([]*apiv1.Pod, 3)
+	eviction := &test.PodsEvictionRestrictionMock{}
+	for i := range pods {
+		pods[i] = test.Pod().WithName("test_" + strconv.Itoa(i)).
+			AddContainer(test.Container().WithName(containerName).WithCPURequest(resource.MustParse("1")).WithMemRequest(resource.MustParse("100M")).Get()).
+			WithLabels(map[string]string{"app": "testingApp"}).
+			Get()
+		pods[i].OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "job", Controller: &isController}}
+		eviction.On("CanEvict", pods[i]).Return(true)
+		eviction.On("Evict", pods[i], nil).Return(nil)
+	}
+
+	podLister := &test.PodListerMock{}
+	podLister.On("List").Return(pods, nil)
+	vpaObj := test.VerticalPodAutoscaler().
+		WithContainer(containerName).
+		WithTarget("2", "200M").
+		WithUpdateMode(vpa_types.UpdateModeAuto).
+		WithJobUpdateMode(vpa_types.JobUpdateModeFrozen).
+		WithTargetRef(&v1.CrossVersionObjectReference{Kind: "Job", Name: "job", APIVersion: "batch/v1"}).Get()
+	vpaLister := &test.VerticalPodAutoscalerListerMock{}
+	vpaLister.On("List").Return([]*vpa_types.VerticalPodAutoscaler{vpaObj}, nil).Once()
+
+	mockSelectorFetcher := target_mock.NewMockVpaTargetSelectorFetcher(ctrl)
+	mockSelectorFetcher.EXPECT().Fetch(gomock.Eq(vpaObj)).Return(parseLabelSelector("app = testingApp"), nil)
+
+	updater := &updater{
+		vpaLister:               vpaLister,
+		podLister:               podLister,
+		evictionFactory:         &fakeEvictFactory{eviction},
+		evictionRateLimiter:     rate.NewLimiter(rate.Inf, 0),
+		evictionAdmission:       priority.NewDefaultPodEvictionAdmission(),
+		recommendationProcessor: &test.FakeRecommendationProcessor{},
+		selectorFetcher:         mockSelectorFetcher,
+		controllerFetcher:       controllerfetcher.FakeControllerFetcher{},
+		priorityProcessor:       priority.NewProcessor(),
+	}
+	updater.RunOnce(context.Background())
+	eviction.AssertNumberOfCalls(t, "Evict", 0)
+}
+
+func TestRunOnce_InPlace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	containerName := "container1"
+	rc := apiv1.ReplicationController{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "ReplicationController",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rc",
+			Namespace: "default",
+		},
+	}
+	pods := make([]*apiv1.Pod, 3)
+	eviction := &test.PodsEvictionRestrictionMock{}
+	for i := range pods {
+		pods[i] = test.Pod().WithName("test_"+strconv.Itoa(i)).
+			AddContainer(test.Container().WithName(containerName).WithCPURequest(resource.MustParse("1")).WithMemRequest(resource.MustParse("100M")).Get()).
+			WithCreator(&rc.ObjectMeta, &rc.TypeMeta).
+			WithLabels(map[string]string{"app": "testingApp"}).
+			Get()
+		eviction.On("CanEvict", pods[i]).Return(true)
+		eviction.On("Evict", pods[i], nil).Return(nil)
+	}
+	// The last pod can't be resized in place and is evicted instead.
+	resizer := &fakePodResizer{canResize: map[*apiv1.Pod]bool{pods[0]: true, pods[1]: true}}
+
+	podLister := &test.PodListerMock{}
+	podLister.On("List").Return(pods, nil)
+	vpaObj := test.VerticalPodAutoscaler().
+		WithContainer(containerName).
+		WithTarget("2", "200M").
+		WithUpdateMode(vpa_types.UpdateModeInPlace).
+		WithTargetRef(&v1.CrossVersionObjectReference{Kind: rc.Kind, Name: rc.Name, APIVersion: rc.APIVersion}).Get()
+	vpaLister := &test.VerticalPodAutoscalerListerMock{}
+	vpaLister.On("List").Return([]*vpa_types.VerticalPodAutoscaler{vpaObj}, nil).Once()
+
+	mockSelectorFetcher := target_mock.NewMockVpaTargetSelectorFetcher(ctrl)
+	mockSelectorFetcher.EXPECT().Fetch(gomock.Eq(vpaObj)).Return(parseLabelSelector("app = testingApp"), nil)
+
+	updater := &updater{
+		vpaLister:               vpaLister,
+		podLister:               podLister,
+		evictionFactory:         &fakeEvictFactory{eviction},
+		podResizer:              resizer,
+		evictionRateLimiter:     rate.NewLimiter(rate.Inf, 0),
+		evictionAdmission:       priority.NewDefaultPodEvictionAdmission(),
+		recommendationProcessor: &test.FakeRecommendationProcessor{},
+		selectorFetcher:         mockSelectorFetcher,
+		controllerFetcher:       controllerfetcher.FakeControllerFetcher{},
+		priorityProcessor:       priority.NewProcessor(),
+	}
+	updater.RunOnce(context.Background())
+	assert.ElementsMatch(t, []*apiv1.Pod{pods[0], pods[1]}, resizer.resized)
+	eviction.AssertNumberOfCalls(t, "Evict", 1)
+	eviction.AssertCalled(t, "Evict", pods[2], nil)
+}
+
 func TestRunOnceNotingToProcess(t *testing.T) {
 	eviction := &test.PodsEvictionRestrictionMock{}
 	factory := &fakeEvictFactory{eviction}
@@ -249,6 +356,20 @@ func (f fakeEvictFactory) NewPodsEvictionRestriction(pods []*apiv1.Pod, vpa *vpa
 	return f.evict
 }
 
+type fakePodResizer struct {
+	canResize map[*apiv1.Pod]bool
+	resized   []*apiv1.Pod
+}
+
+func (f *fakePodResizer) CanResize(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler) bool {
+	return f.canResize[pod]
+}
+
+func (f *fakePodResizer) Resize(pod *apiv1.Pod, vpa *vpa_types.VerticalPodAutoscaler, eventRecorder record.EventRecorder) error {
+	f.resized = append(f.resized, pod)
+	return nil
+}
+
 type fakeValidator struct {
 	isValid bool
 }
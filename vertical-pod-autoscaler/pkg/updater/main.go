@@ -25,13 +25,16 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	kube_client "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	kube_flag "k8s.io/component-base/cli/flag"
 	"k8s.io/klog/v2"
 
 	"k8s.io/autoscaler/vertical-pod-autoscaler/common"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/admission-controller/resource/pod/recommendation"
 	vpa_clientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/target"
 	controllerfetcher "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/target/controller_fetcher"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/updater/inplace"
 	updater "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/updater/logic"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/updater/priority"
 	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/limitrange"
@@ -65,8 +68,16 @@ var (
 	useAdmissionControllerStatus = flag.Bool("use-admission-controller-status", true,
 		"If true, updater will only evict pods when admission controller status is valid.")
 
-	namespace          = os.Getenv("NAMESPACE")
-	vpaObjectNamespace = flag.String("vpa-object-namespace", apiv1.NamespaceAll, "Namespace to search for VPA objects. Empty means all namespaces will be used.")
+	namespace                = os.Getenv("NAMESPACE")
+	vpaObjectNamespace       = flag.String("vpa-object-namespace", apiv1.NamespaceAll, "Namespace to search for VPA objects. Empty means all namespaces will be used.")
+	ignoredNamespaceSelector = flag.String("ignored-namespace-selector", "", "Label selector for namespaces whose VPA objects should be ignored. Namespace labels are evaluated dynamically. Empty means no namespace is ignored.")
+
+	disruptionFreeWindowDetection = flag.Bool("disruption-free-window-detection", false,
+		"If true, updater will not evict pods while an HPA is scaling their workload or a PDB covering them allows no disruptions.")
+	hpaStabilityWindow = flag.Duration("hpa-stability-window", 5*time.Minute,
+		"How long after an HPA changed the replica count updater should avoid evicting pods of the scaled workload. Only used with --disruption-free-window-detection.")
+	clusterAutoscalerDrainCoordination = flag.Bool("cluster-autoscaler-drain-coordination", false,
+		"If true, updater will not evict pods on nodes Cluster Autoscaler is draining, nor pods whose controller has pods on such nodes. Evicted pods are annotated, so that Cluster Autoscaler can hold back draining nodes with other pods of their controller.")
 )
 
 const (
@@ -101,6 +112,35 @@ func main() {
 	if namespace != "" {
 		admissionControllerStatusNamespace = namespace
 	}
+	recommendationProcessor := vpa_api_util.NewSequentialProcessor([]vpa_api_util.RecommendationProcessor{
+		vpa_api_util.NewPodLevelSplitRecommendationProcessor(),
+		vpa_api_util.NewCappingRecommendationProcessor(limitRangeCalculator),
+	})
+	evictionAdmissions := []priority.PodEvictionAdmission{priority.NewScalingDirectionPodEvictionAdmission()}
+	if *disruptionFreeWindowDetection {
+		hpaInformer := factory.Autoscaling().V2().HorizontalPodAutoscalers()
+		pdbInformer := factory.Policy().V1().PodDisruptionBudgets()
+		// Informers have to be requested from the factory before it's started.
+		hpaInformer.Informer()
+		pdbInformer.Informer()
+		stopCh := make(chan struct{})
+		factory.Start(stopCh)
+		for informerType, synced := range factory.WaitForCacheSync(stopCh) {
+			if !synced {
+				klog.Fatalf("Failed to sync %v informer", informerType)
+			}
+		}
+		evictionAdmissions = append(evictionAdmissions, priority.NewDisruptionWindowPodEvictionAdmission(hpaInformer.Lister(), pdbInformer.Lister(), *hpaStabilityWindow))
+	}
+	if *clusterAutoscalerDrainCoordination {
+		nodeInformer := factory.Core().V1().Nodes()
+		stopCh := make(chan struct{})
+		go nodeInformer.Informer().Run(stopCh)
+		if !cache.WaitForCacheSync(stopCh, nodeInformer.Informer().HasSynced) {
+			klog.Fatalf("Failed to sync node informer")
+		}
+		evictionAdmissions = append(evictionAdmissions, priority.NewNodeDrainPodEvictionAdmission(nodeInformer.Lister()))
+	}
 	// TODO: use SharedInformerFactory in updater
 	updater, err := updater.NewUpdater(
 		kubeClient,
@@ -109,14 +149,17 @@ func main() {
 		*evictionRateLimit,
 		*evictionRateBurst,
 		*evictionToleranceFraction,
+		*clusterAutoscalerDrainCoordination,
 		*useAdmissionControllerStatus,
 		admissionControllerStatusNamespace,
-		vpa_api_util.NewCappingRecommendationProcessor(limitRangeCalculator),
-		priority.NewScalingDirectionPodEvictionAdmission(),
+		recommendationProcessor,
+		priority.NewSequentialPodEvictionAdmission(evictionAdmissions),
 		targetSelectorFetcher,
 		controllerFetcher,
 		priority.NewProcessor(),
+		inplace.NewPodResizer(kubeClient, recommendation.NewProvider(limitRangeCalculator, recommendationProcessor)),
 		*vpaObjectNamespace,
+		*ignoredNamespaceSelector,
 	)
 	if err != nil {
 		klog.Fatalf("Failed to create updater: %v", err)
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	"time"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v2"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/klog/v2"
+)
+
+// NewDisruptionWindowPodEvictionAdmission creates a PodEvictionAdmission object.
+// It holds back evictions while the workload is not in a disruption-free window, i.e. when
+// * an HPA targeting the same workload has not reached its desired number of replicas yet
+// * an HPA targeting the same workload changed the replica count within the last hpaStabilityWindow
+// * a PodDisruptionBudget covering the Pod does not allow any disruptions at the moment
+func NewDisruptionWindowPodEvictionAdmission(hpaLister autoscalinglisters.HorizontalPodAutoscalerLister, pdbLister policylisters.PodDisruptionBudgetLister, hpaStabilityWindow time.Duration) PodEvictionAdmission {
+	return &disruptionWindowPodEvictionAdmission{
+		hpaLister:          hpaLister,
+		pdbLister:          pdbLister,
+		hpaStabilityWindow: hpaStabilityWindow,
+		now:                time.Now,
+	}
+}
+
+type disruptionWindowPodEvictionAdmission struct {
+	hpaLister          autoscalinglisters.HorizontalPodAutoscalerLister
+	pdbLister          policylisters.PodDisruptionBudgetLister
+	hpaStabilityWindow time.Duration
+	now                func() time.Time
+	// unstablePods holds Pods controlled by a VPA whose target is currently being scaled by an HPA.
+	unstablePods map[*apiv1.Pod]bool
+}
+
+// LoopInit finds the VPAs whose target workload is being scaled horizontally, or was scaled recently,
+// and marks all Pods they control as not admitted in this loop.
+func (d *disruptionWindowPodEvictionAdmission) LoopInit(_ []*apiv1.Pod, vpaControlledPods map[*vpa_types.VerticalPodAutoscaler][]*apiv1.Pod) {
+	d.unstablePods = make(map[*apiv1.Pod]bool)
+	for vpa, pods := range vpaControlledPods {
+		if vpa.Spec.TargetRef == nil {
+			continue
+		}
+		hpas, err := d.hpaLister.HorizontalPodAutoscalers(vpa.Namespace).List(labels.Everything())
+		if err != nil {
+			klog.Errorf("Failed to list HPAs in namespace %s: %v", vpa.Namespace, err)
+			continue
+		}
+		for _, hpa := range hpas {
+			if hpa.Spec.ScaleTargetRef.Kind != vpa.Spec.TargetRef.Kind || hpa.Spec.ScaleTargetRef.Name != vpa.Spec.TargetRef.Name {
+				continue
+			}
+			if d.isHpaUnstable(hpa) {
+				klog.V(4).Infof("HPA %s/%s is not stable, holding back evictions for VPA %s/%s", hpa.Namespace, hpa.Name, vpa.Namespace, vpa.Name)
+				for _, pod := range pods {
+					d.unstablePods[pod] = true
+				}
+				break
+			}
+		}
+	}
+}
+
+func (d *disruptionWindowPodEvictionAdmission) isHpaUnstable(hpa *autoscalingv2.HorizontalPodAutoscaler) bool {
+	if hpa.Status.CurrentReplicas != hpa.Status.DesiredReplicas {
+		return true
+	}
+	if hpa.Status.LastScaleTime == nil {
+		return false
+	}
+	return d.now().Sub(hpa.Status.LastScaleTime.Time) < d.hpaStabilityWindow
+}
+
+// Admit admits a Pod for eviction unless its workload is being scaled horizontally
+// or a PodDisruptionBudget covering it has no disruptions left.
+func (d *disruptionWindowPodEvictionAdmission) Admit(pod *apiv1.Pod, _ *vpa_types.RecommendedPodResources) bool {
+	if d.unstablePods[pod] {
+		return false
+	}
+	pdbs, err := d.pdbLister.PodDisruptionBudgets(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list PDBs in namespace %s: %v", pod.Namespace, err)
+		return true
+	}
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		// In policy/v1 an empty selector matches all pods in the namespace, a null one matches no pods.
+		if !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed <= 0 {
+			klog.V(4).Infof("PDB %s/%s allows no disruptions, not evicting pod %s/%s", pdb.Namespace, pdb.Name, pod.Namespace, pod.Name)
+			return false
+		}
+	}
+	return true
+}
+
+func (d *disruptionWindowPodEvictionAdmission) CleanUp() {
+	d.unstablePods = nil
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	autoscaling "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apiv1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+	autoscalinglisters "k8s.io/client-go/listers/autoscaling/v2"
+	policylisters "k8s.io/client-go/listers/policy/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDisruptionWindowPodEvictionAdmission(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	stabilityWindow := 5 * time.Minute
+	podLabels := map[string]string{"app": "test"}
+
+	newHpa := func(current, desired int32, lastScaleTime *time.Time) *autoscalingv2.HorizontalPodAutoscaler {
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-hpa"},
+			Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "test-deployment"},
+			},
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: current, DesiredReplicas: desired},
+		}
+		if lastScaleTime != nil {
+			hpa.Status.LastScaleTime = &metav1.Time{Time: *lastScaleTime}
+		}
+		return hpa
+	}
+	newPdb := func(disruptionsAllowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-pdb"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: podLabels}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: disruptionsAllowed},
+		}
+	}
+	recentScale := now.Add(-time.Minute)
+	oldScale := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name  string
+		hpa   *autoscalingv2.HorizontalPodAutoscaler
+		pdb   *policyv1.PodDisruptionBudget
+		admit bool
+	}{
+		{
+			name:  "no HPA and no PDB",
+			admit: true,
+		},
+		{
+			name:  "stable HPA",
+			hpa:   newHpa(3, 3, &oldScale),
+			admit: true,
+		},
+		{
+			name:  "HPA never scaled",
+			hpa:   newHpa(3, 3, nil),
+			admit: true,
+		},
+		{
+			name:  "HPA scaling in progress",
+			hpa:   newHpa(3, 5, &oldScale),
+			admit: false,
+		},
+		{
+			name:  "HPA scaled within stability window",
+			hpa:   newHpa(5, 5, &recentScale),
+			admit: false,
+		},
+		{
+			name:  "PDB allows disruptions",
+			pdb:   newPdb(1),
+			admit: true,
+		},
+		{
+			name:  "PDB allows no disruptions",
+			pdb:   newPdb(0),
+			admit: false,
+		},
+		{
+			name: "PDB with empty selector allows no disruptions",
+			pdb: func() *policyv1.PodDisruptionBudget {
+				pdb := newPdb(0)
+				pdb.Spec.Selector = &metav1.LabelSelector{}
+				return pdb
+			}(),
+			admit: false,
+		},
+		{
+			name: "PDB with null selector allows no disruptions",
+			pdb: func() *policyv1.PodDisruptionBudget {
+				pdb := newPdb(0)
+				pdb.Spec.Selector = nil
+				return pdb
+			}(),
+			admit: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hpaIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			pdbIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			if tc.hpa != nil {
+				assert.NoError(t, hpaIndexer.Add(tc.hpa))
+			}
+			if tc.pdb != nil {
+				assert.NoError(t, pdbIndexer.Add(tc.pdb))
+			}
+			admission := NewDisruptionWindowPodEvictionAdmission(autoscalinglisters.NewHorizontalPodAutoscalerLister(hpaIndexer), policylisters.NewPodDisruptionBudgetLister(pdbIndexer), stabilityWindow)
+			admission.(*disruptionWindowPodEvictionAdmission).now = func() time.Time { return now }
+
+			pod := test.Pod().WithName("test-pod").WithLabels(podLabels).Get()
+			vpa := test.VerticalPodAutoscaler().WithName("test-vpa").WithContainer("container").
+				WithTargetRef(&autoscaling.CrossVersionObjectReference{Kind: "Deployment", Name: "test-deployment"}).Get()
+
+			admission.LoopInit(nil, map[*vpa_types.VerticalPodAutoscaler][]*apiv1.Pod{vpa: {pod}})
+			assert.Equal(t, tc.admit, admission.Admit(pod, nil))
+			admission.CleanUp()
+		})
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// ClusterAutoscalerToBeDeletedTaint is the taint Cluster Autoscaler puts on a node
+// before draining it for scale-down.
+const ClusterAutoscalerToBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
+
+// NewNodeDrainPodEvictionAdmission creates a PodEvictionAdmission object.
+// It holds back evictions which would disrupt a workload while Cluster Autoscaler
+// is draining a node, i.e. of
+// * Pods running on a node being drained, as they're evicted by the drain anyway
+// * Pods whose controller also has Pods on a node being drained, so that replicas
+// of the same workload aren't disrupted by both at once
+func NewNodeDrainPodEvictionAdmission(nodeLister v1lister.NodeLister) PodEvictionAdmission {
+	return &nodeDrainPodEvictionAdmission{
+		nodeLister: nodeLister,
+	}
+}
+
+type nodeDrainPodEvictionAdmission struct {
+	nodeLister v1lister.NodeLister
+	// drainingNodes holds the names of nodes being drained by Cluster Autoscaler.
+	drainingNodes map[string]bool
+	// drainedControllers holds the UIDs of controllers with Pods on nodes being drained.
+	drainedControllers map[types.UID]bool
+}
+
+// LoopInit finds the nodes being drained by Cluster Autoscaler and the controllers
+// of the live Pods running on them.
+func (n *nodeDrainPodEvictionAdmission) LoopInit(allLivePods []*apiv1.Pod, _ map[*vpa_types.VerticalPodAutoscaler][]*apiv1.Pod) {
+	n.drainingNodes = make(map[string]bool)
+	n.drainedControllers = make(map[types.UID]bool)
+	nodes, err := n.nodeLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("Failed to list nodes: %v", err)
+		return
+	}
+	for _, node := range nodes {
+		if isBeingDrained(node) {
+			n.drainingNodes[node.Name] = true
+		}
+	}
+	if len(n.drainingNodes) == 0 {
+		return
+	}
+	for _, pod := range allLivePods {
+		if !n.drainingNodes[pod.Spec.NodeName] {
+			continue
+		}
+		if controller := metav1.GetControllerOf(pod); controller != nil {
+			n.drainedControllers[controller.UID] = true
+		}
+	}
+}
+
+func isBeingDrained(node *apiv1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == ClusterAutoscalerToBeDeletedTaint {
+			return true
+		}
+	}
+	return false
+}
+
+// Admit admits a Pod for eviction unless it runs on a node being drained by
+// Cluster Autoscaler or its controller has Pods on such a node.
+func (n *nodeDrainPodEvictionAdmission) Admit(pod *apiv1.Pod, _ *vpa_types.RecommendedPodResources) bool {
+	if n.drainingNodes[pod.Spec.NodeName] {
+		klog.V(4).Infof("Node %s is being drained by Cluster Autoscaler, not evicting pod %s/%s", pod.Spec.NodeName, pod.Namespace, pod.Name)
+		return false
+	}
+	if controller := metav1.GetControllerOf(pod); controller != nil && n.drainedControllers[controller.UID] {
+		klog.V(4).Infof("%s %s has pods on a node being drained by Cluster Autoscaler, not evicting pod %s/%s", controller.Kind, controller.Name, pod.Namespace, pod.Name)
+		return false
+	}
+	return true
+}
+
+func (n *nodeDrainPodEvictionAdmission) CleanUp() {
+	n.drainingNodes = nil
+	n.drainedControllers = nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package priority
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNodeDrainPodEvictionAdmission(t *testing.T) {
+	drainingNode := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "draining"},
+		Spec:       apiv1.NodeSpec{Taints: []apiv1.Taint{{Key: ClusterAutoscalerToBeDeletedTaint, Effect: apiv1.TaintEffectNoSchedule}}},
+	}
+	healthyNode := &apiv1.Node{ObjectMeta: metav1.ObjectMeta{Name: "healthy"}}
+	nodeIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.NoError(t, nodeIndexer.Add(drainingNode))
+	assert.NoError(t, nodeIndexer.Add(healthyNode))
+
+	newPod := func(name, nodeName, replicaSet string) *apiv1.Pod {
+		pod := test.Pod().WithName(name).
+			WithCreator(&metav1.ObjectMeta{Name: replicaSet, UID: types.UID(replicaSet)}, &metav1.TypeMeta{Kind: "ReplicaSet"}).Get()
+		pod.Spec.NodeName = nodeName
+		return pod
+	}
+	drainedPod := newPod("drained", drainingNode.Name, "rs1")
+	drainedReplica := newPod("drained-replica", healthyNode.Name, "rs1")
+	otherPod := newPod("other", healthyNode.Name, "rs2")
+	orphanPod := test.Pod().WithName("orphan").Get()
+	orphanPod.Spec.NodeName = healthyNode.Name
+
+	admission := NewNodeDrainPodEvictionAdmission(v1lister.NewNodeLister(nodeIndexer))
+	admission.LoopInit([]*apiv1.Pod{drainedPod, drainedReplica, otherPod, orphanPod}, nil)
+	assert.False(t, admission.Admit(drainedPod, nil))
+	assert.False(t, admission.Admit(drainedReplica, nil))
+	assert.True(t, admission.Admit(otherPod, nil))
+	assert.True(t, admission.Admit(orphanPod, nil))
+	admission.CleanUp()
+
+	assert.NoError(t, nodeIndexer.Delete(drainingNode))
+	admission.LoopInit([]*apiv1.Pod{drainedPod, drainedReplica, otherPod, orphanPod}, nil)
+	assert.True(t, admission.Admit(drainedReplica, nil))
+	admission.CleanUp()
+}
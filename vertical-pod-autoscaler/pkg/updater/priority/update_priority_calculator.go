@@ -87,6 +87,9 @@ func (calc *UpdatePriorityCalculator) AddPod(pod *apiv1.Pod, now time.Time) {
 		klog.V(2).Infof("cannot process recommendation for pod %s: %v", klog.KObj(pod), err)
 		return
 	}
+	// Only resources updated by eviction or in place are taken into account.
+	processedRecommendation = vpa_api_util.FilterRecommendationByUpdateModes(processedRecommendation, calc.vpa, pod,
+		vpa_types.UpdateModeInPlace, vpa_types.UpdateModeRecreate, vpa_types.UpdateModeAuto)
 
 	hasObservedContainers, vpaContainerSet := parseVpaObservedContainers(pod)
 
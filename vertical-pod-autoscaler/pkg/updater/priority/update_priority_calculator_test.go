@@ -107,6 +107,22 @@ func TestUpdateNotRequired(t *testing.T) {
 	assert.Exactly(t, []*apiv1.Pod{}, result, "Pod should not be updated")
 }
 
+func TestUpdateResourceUpdateModes(t *testing.T) {
+	pod := test.Pod().WithName("POD1").AddContainer(test.Container().WithName(containerName).WithCPURequest(resource.MustParse("4")).WithMemRequest(resource.MustParse("10M")).Get()).Get()
+	vpaBuilder := test.VerticalPodAutoscaler().WithContainer(containerName).WithTarget("4", "1G").WithUpdateMode(vpa_types.UpdateModeAuto)
+	timestampNow := pod.Status.StartTime.Time.Add(time.Hour * 24)
+
+	calculator := NewUpdatePriorityCalculator(vpaBuilder.Get(), nil, &test.FakeRecommendationProcessor{}, NewProcessor())
+	calculator.AddPod(pod, timestampNow)
+	assert.Exactly(t, []*apiv1.Pod{pod}, calculator.GetSortedPods(NewDefaultPodEvictionAdmission()), "Pod should be updated")
+
+	// Memory is only assigned on pod creation, so its recommendation doesn't cause evictions.
+	vpa := vpaBuilder.WithResourceUpdateMode(apiv1.ResourceMemory, vpa_types.UpdateModeInitial).Get()
+	calculator = NewUpdatePriorityCalculator(vpa, nil, &test.FakeRecommendationProcessor{}, NewProcessor())
+	calculator.AddPod(pod, timestampNow)
+	assert.Exactly(t, []*apiv1.Pod{}, calculator.GetSortedPods(NewDefaultPodEvictionAdmission()), "Pod should not be updated")
+}
+
 // TODO: add expects to fake processor
 func TestUseProcessor(t *testing.T) {
 	processedRecommendation := test.Recommendation().WithContainer(containerName).WithTarget("4", "10M").Get()
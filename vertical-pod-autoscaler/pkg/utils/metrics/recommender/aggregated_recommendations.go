@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommender
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+)
+
+const (
+	recommendationTarget     = "target"
+	recommendationLowerBound = "lower_bound"
+	recommendationUpperBound = "upper_bound"
+)
+
+var (
+	namespaceRecommendations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "namespace_recommended_resources",
+			Help:      "Sum of the recommendations of all VPA objects in a namespace, multiplied by the number of pods they match. CPU in cores, other resources in their base unit.",
+		}, []string{"namespace", "resource", "recommendation"},
+	)
+
+	selectorRecommendations = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "selector_recommended_resources",
+			Help:      "Sum of the recommendations of all VPA objects whose labels match a configured label selector, multiplied by the number of pods they match. CPU in cores, other resources in their base unit.",
+		}, []string{"selector", "resource", "recommendation"},
+	)
+)
+
+// AggregationSelector is a named label selector of VPA objects whose
+// recommendations are summed up.
+type AggregationSelector struct {
+	Name     string
+	Selector labels.Selector
+}
+
+// ParseAggregationSelectors parses a semicolon-separated list of name=selector
+// pairs, e.g. "team-a=team=a;prod=env in (prod,production)".
+func ParseAggregationSelectors(pairs string) ([]AggregationSelector, error) {
+	var result []AggregationSelector
+	for _, pair := range strings.Split(pairs, ";") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, selector, found := strings.Cut(pair, "=")
+		if !found || name == "" || selector == "" {
+			return nil, fmt.Errorf("%q isn't a name=selector pair", pair)
+		}
+		parsed, err := labels.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q of %s: %v", selector, name, err)
+		}
+		result = append(result, AggregationSelector{Name: name, Selector: parsed})
+	}
+	return result, nil
+}
+
+type aggregationKey struct {
+	group          string
+	resource       apiv1.ResourceName
+	recommendation string
+}
+
+// RecommendationAggregator sums up recommendations of VPA objects per namespace
+// and per label selector, e.g. for quota planning.
+type RecommendationAggregator struct {
+	selectors  []AggregationSelector
+	namespaces map[aggregationKey]float64
+	selected   map[aggregationKey]float64
+}
+
+// NewRecommendationAggregator creates a new helper to sum up recommendations.
+func NewRecommendationAggregator(selectors []AggregationSelector) *RecommendationAggregator {
+	return &RecommendationAggregator{
+		selectors:  selectors,
+		namespaces: make(map[aggregationKey]float64),
+		selected:   make(map[aggregationKey]float64),
+	}
+}
+
+// Add updates the helper state to include the recommendation of the given VPA
+// object, for all of its pods. vpaLabels are the labels of the VPA object.
+func (ra *RecommendationAggregator) Add(vpa *model.Vpa, vpaLabels map[string]string) {
+	if vpa.Recommendation == nil || vpa.PodCount == 0 {
+		return
+	}
+	var groups []string
+	for _, selector := range ra.selectors {
+		if selector.Selector.Matches(labels.Set(vpaLabels)) {
+			groups = append(groups, selector.Name)
+		}
+	}
+	for _, container := range vpa.Recommendation.ContainerRecommendations {
+		for recommendation, resources := range map[string]apiv1.ResourceList{
+			recommendationTarget:     container.Target,
+			recommendationLowerBound: container.LowerBound,
+			recommendationUpperBound: container.UpperBound,
+		} {
+			for resource, quantity := range resources {
+				value := quantity.AsApproximateFloat64() * float64(vpa.PodCount)
+				ra.namespaces[aggregationKey{vpa.ID.Namespace, resource, recommendation}] += value
+				for _, group := range groups {
+					ra.selected[aggregationKey{group, resource, recommendation}] += value
+				}
+			}
+		}
+	}
+}
+
+// Observe passes the sums to metrics, dropping sums of previous runs.
+func (ra *RecommendationAggregator) Observe() {
+	namespaceRecommendations.Reset()
+	for k, v := range ra.namespaces {
+		namespaceRecommendations.WithLabelValues(k.group, string(k.resource), k.recommendation).Set(v)
+	}
+	selectorRecommendations.Reset()
+	for k, v := range ra.selected {
+		selectorRecommendations.WithLabelValues(k.group, string(k.resource), k.recommendation).Set(v)
+	}
+}
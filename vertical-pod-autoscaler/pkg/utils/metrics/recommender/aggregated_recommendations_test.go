@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recommender
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/recommender/model"
+)
+
+func TestParseAggregationSelectors(t *testing.T) {
+	selectors, err := ParseAggregationSelectors(" team-a=team=a ; prod=env in (prod,production);")
+	assert.NoError(t, err)
+	if assert.Len(t, selectors, 2) {
+		assert.Equal(t, "team-a", selectors[0].Name)
+		assert.Equal(t, "team=a", selectors[0].Selector.String())
+		assert.Equal(t, "prod", selectors[1].Name)
+		assert.Equal(t, "env in (prod,production)", selectors[1].Selector.String())
+	}
+
+	selectors, err = ParseAggregationSelectors("")
+	assert.NoError(t, err)
+	assert.Empty(t, selectors)
+
+	_, err = ParseAggregationSelectors("team=")
+	assert.Error(t, err)
+	_, err = ParseAggregationSelectors("team-a=team in a")
+	assert.Error(t, err)
+}
+
+func TestRecommendationAggregator(t *testing.T) {
+	recommendation := func(cpu, memory string) *vpa_types.RecommendedPodResources {
+		resources := apiv1.ResourceList{
+			apiv1.ResourceCPU:    resource.MustParse(cpu),
+			apiv1.ResourceMemory: resource.MustParse(memory),
+		}
+		return &vpa_types.RecommendedPodResources{
+			ContainerRecommendations: []vpa_types.RecommendedContainerResources{
+				{ContainerName: "c", Target: resources},
+			},
+		}
+	}
+	vpa := func(namespace string, podCount int, recommendation *vpa_types.RecommendedPodResources) *model.Vpa {
+		return &model.Vpa{
+			ID:             model.VpaID{Namespace: namespace, VpaName: "vpa"},
+			Recommendation: recommendation,
+			PodCount:       podCount,
+		}
+	}
+	selectors, err := ParseAggregationSelectors("team-a=team=a")
+	assert.NoError(t, err)
+
+	aggregator := NewRecommendationAggregator(selectors)
+	aggregator.Add(vpa("ns1", 2, recommendation("500m", "1Gi")), map[string]string{"team": "a"})
+	aggregator.Add(vpa("ns1", 1, recommendation("1", "1Gi")), map[string]string{"team": "b"})
+	aggregator.Add(vpa("ns2", 3, recommendation("250m", "1Mi")), map[string]string{"team": "a"})
+	aggregator.Add(vpa("ns2", 0, recommendation("1", "1Gi")), map[string]string{"team": "a"})
+	aggregator.Add(vpa("ns3", 1, nil), map[string]string{"team": "a"})
+
+	t.Cleanup(func() {
+		// Reset the metrics after the test to avoid collisions.
+		namespaceRecommendations.Reset()
+		selectorRecommendations.Reset()
+	})
+	aggregator.Observe()
+
+	assert.Equal(t, map[string]float64{
+		"namespace=ns1,recommendation=target,resource=cpu,":    2,
+		"namespace=ns1,recommendation=target,resource=memory,": 3 * 1024 * 1024 * 1024,
+		"namespace=ns2,recommendation=target,resource=cpu,":    0.75,
+		"namespace=ns2,recommendation=target,resource=memory,": 3 * 1024 * 1024,
+	}, collectGauges(t, namespaceRecommendations))
+	assert.Equal(t, map[string]float64{
+		"recommendation=target,resource=cpu,selector=team-a,":    1.75,
+		"recommendation=target,resource=memory,selector=team-a,": 2*1024*1024*1024 + 3*1024*1024,
+	}, collectGauges(t, selectorRecommendations))
+}
+
+func collectGauges(t *testing.T, gauges *prometheus.GaugeVec) map[string]float64 {
+	metrics := make(chan prometheus.Metric)
+	go func() {
+		gauges.Collect(metrics)
+		close(metrics)
+	}()
+	result := make(map[string]float64)
+	for metric := range metrics {
+		var metricProto dto.Metric
+		if err := metric.Write(&metricProto); err != nil {
+			t.Errorf("failed to write metric: %v", err)
+		}
+		result[labelsToKey(metricProto.GetLabel())] = metricProto.GetGauge().GetValue()
+	}
+	return result
+}
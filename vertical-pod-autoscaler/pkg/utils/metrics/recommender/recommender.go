@@ -38,6 +38,7 @@ var (
 	modes = []string{
 		string(vpa_types.UpdateModeOff),
 		string(vpa_types.UpdateModeInitial),
+		string(vpa_types.UpdateModeInPlace),
 		string(vpa_types.UpdateModeRecreate),
 		string(vpa_types.UpdateModeAuto),
 	}
@@ -104,7 +105,7 @@ type ObjectCounter struct {
 
 // Register initializes all metrics for VPA Recommender
 func Register() {
-	prometheus.MustRegister(vpaObjectCount, recommendationLatency, functionLatency, aggregateContainerStatesCount, metricServerResponses)
+	prometheus.MustRegister(vpaObjectCount, recommendationLatency, functionLatency, aggregateContainerStatesCount, metricServerResponses, namespaceRecommendations, selectorRecommendations)
 }
 
 // NewExecutionTimer provides a timer for Recommender's RunOnce execution
@@ -59,6 +59,14 @@ var (
 		}, []string{"vpa_size_log2"},
 	)
 
+	inPlaceUpdatedCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "in_place_updated_pods_total",
+			Help:      "Number of Pods resized in place by Updater to apply a new recommendation.",
+		}, []string{"vpa_size_log2"},
+	)
+
 	vpasWithEvictablePodsCount = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: metricsNamespace,
@@ -81,7 +89,7 @@ var (
 
 // Register initializes all metrics for VPA Updater
 func Register() {
-	prometheus.MustRegister(controlledCount, evictableCount, evictedCount, vpasWithEvictablePodsCount, vpasWithEvictedPodsCount, functionLatency)
+	prometheus.MustRegister(controlledCount, evictableCount, evictedCount, inPlaceUpdatedCount, vpasWithEvictablePodsCount, vpasWithEvictedPodsCount, functionLatency)
 }
 
 // NewExecutionTimer provides a timer for Updater's RunOnce execution
@@ -123,6 +131,12 @@ func AddEvictedPod(vpaSize int) {
 	evictedCount.WithLabelValues(strconv.Itoa(log2)).Inc()
 }
 
+// AddInPlaceUpdatedPod increases the counter of pods resized in place by Updater, by given VPA size
+func AddInPlaceUpdatedPod(vpaSize int) {
+	log2 := metrics.GetVpaSizeLog2(vpaSize)
+	inPlaceUpdatedCount.WithLabelValues(strconv.Itoa(log2)).Inc()
+}
+
 // Add increases the counter for the given VPA size
 func (g *SizeBasedGauge) Add(vpaSize int, value int) {
 	log2 := metrics.GetVpaSizeLog2(vpaSize)
@@ -47,6 +47,8 @@ type VerticalPodAutoscalerBuilder interface {
 	WithGroupVersion(gv meta.GroupVersion) VerticalPodAutoscalerBuilder
 	WithEvictionRequirements([]*vpa_types.EvictionRequirement) VerticalPodAutoscalerBuilder
 	WithMinReplicas(minReplicas *int32) VerticalPodAutoscalerBuilder
+	WithJobUpdateMode(jobUpdateMode vpa_types.JobUpdateMode) VerticalPodAutoscalerBuilder
+	WithResourceUpdateMode(resource core.ResourceName, updateMode vpa_types.UpdateMode) VerticalPodAutoscalerBuilder
 	AppendCondition(conditionType vpa_types.VerticalPodAutoscalerConditionType,
 		status core.ConditionStatus, reason, message string, lastTransitionTime time.Time) VerticalPodAutoscalerBuilder
 	AppendRecommendation(vpa_types.RecommendedContainerResources) VerticalPodAutoscalerBuilder
@@ -214,6 +216,28 @@ func (b *verticalPodAutoscalerBuilder) WithMinReplicas(minReplicas *int32) Verti
 	return &c
 }
 
+func (b *verticalPodAutoscalerBuilder) WithJobUpdateMode(jobUpdateMode vpa_types.JobUpdateMode) VerticalPodAutoscalerBuilder {
+	c := *b
+	if c.updatePolicy == nil {
+		c.updatePolicy = &vpa_types.PodUpdatePolicy{}
+	} else {
+		c.updatePolicy = c.updatePolicy.DeepCopy()
+	}
+	c.updatePolicy.JobUpdateMode = &jobUpdateMode
+	return &c
+}
+
+func (b *verticalPodAutoscalerBuilder) WithResourceUpdateMode(resource core.ResourceName, updateMode vpa_types.UpdateMode) VerticalPodAutoscalerBuilder {
+	c := *b
+	if c.updatePolicy == nil {
+		c.updatePolicy = &vpa_types.PodUpdatePolicy{}
+	} else {
+		c.updatePolicy = c.updatePolicy.DeepCopy()
+	}
+	c.updatePolicy.ResourceUpdateModes = append(c.updatePolicy.ResourceUpdateModes, vpa_types.ResourceUpdateMode{Resource: resource, UpdateMode: updateMode})
+	return &c
+}
+
 func (b *verticalPodAutoscalerBuilder) AppendCondition(conditionType vpa_types.VerticalPodAutoscalerConditionType,
 	status core.ConditionStatus, reason, message string, lastTransitionTime time.Time) VerticalPodAutoscalerBuilder {
 	c := *b
@@ -28,6 +28,7 @@ import (
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -186,6 +187,143 @@ func GetUpdateMode(vpa *vpa_types.VerticalPodAutoscaler) vpa_types.UpdateMode {
 	return *vpa.Spec.UpdatePolicy.UpdateMode
 }
 
+// updateModeOrder orders update modes from the least to the most disruptive.
+var updateModeOrder = map[vpa_types.UpdateMode]int{
+	vpa_types.UpdateModeOff:      0,
+	vpa_types.UpdateModeInitial:  1,
+	vpa_types.UpdateModeInPlace:  2,
+	vpa_types.UpdateModeRecreate: 3,
+	vpa_types.UpdateModeAuto:     4,
+}
+
+// GetResourceUpdateMode returns the update mode of a given VPA for the resource,
+// i.e. the mode in updatePolicy.resourceUpdateModes if the resource is listed
+// there and the updatePolicy.updateMode otherwise.
+func GetResourceUpdateMode(vpa *vpa_types.VerticalPodAutoscaler, resource core.ResourceName) vpa_types.UpdateMode {
+	if mode, found := listedResourceUpdateMode(vpa, resource); found {
+		return mode
+	}
+	return GetUpdateMode(vpa)
+}
+
+func listedResourceUpdateMode(vpa *vpa_types.VerticalPodAutoscaler, resource core.ResourceName) (vpa_types.UpdateMode, bool) {
+	if vpa.Spec.UpdatePolicy != nil {
+		for _, resourceMode := range vpa.Spec.UpdatePolicy.ResourceUpdateModes {
+			if resourceMode.Resource == resource && resourceMode.UpdateMode != "" {
+				return resourceMode.UpdateMode, true
+			}
+		}
+	}
+	return "", false
+}
+
+// GetMaxUpdateMode returns the most disruptive update mode of a given VPA over
+// all resources.
+func GetMaxUpdateMode(vpa *vpa_types.VerticalPodAutoscaler) vpa_types.UpdateMode {
+	return maxUpdateMode(vpa, func(resource core.ResourceName) vpa_types.UpdateMode {
+		return GetResourceUpdateMode(vpa, resource)
+	})
+}
+
+// GetPodResourceUpdateMode returns the update mode of a given VPA for the
+// resource of the pod. Pods of Jobs follow the updatePolicy.jobUpdateMode if
+// it's specified: resources of frozen pods are updated like in UpdateModeInitial,
+// unless resourceUpdateModes turn them off, and resources of pods with the mode
+// "Off" aren't updated.
+func GetPodResourceUpdateMode(vpa *vpa_types.VerticalPodAutoscaler, pod *core.Pod, resource core.ResourceName) vpa_types.UpdateMode {
+	if vpa.Spec.UpdatePolicy == nil || vpa.Spec.UpdatePolicy.JobUpdateMode == nil || !isJobPod(pod) {
+		return GetResourceUpdateMode(vpa, resource)
+	}
+	switch *vpa.Spec.UpdatePolicy.JobUpdateMode {
+	case vpa_types.JobUpdateModeFrozen:
+		if mode, found := listedResourceUpdateMode(vpa, resource); found && mode == vpa_types.UpdateModeOff {
+			return mode
+		}
+		return vpa_types.UpdateModeInitial
+	case vpa_types.JobUpdateModeOff:
+		return vpa_types.UpdateModeOff
+	}
+	return GetResourceUpdateMode(vpa, resource)
+}
+
+// GetPodUpdateMode returns the most disruptive update mode of a given VPA over
+// all resources of the pod.
+func GetPodUpdateMode(vpa *vpa_types.VerticalPodAutoscaler, pod *core.Pod) vpa_types.UpdateMode {
+	return maxUpdateMode(vpa, func(resource core.ResourceName) vpa_types.UpdateMode {
+		return GetPodResourceUpdateMode(vpa, pod, resource)
+	})
+}
+
+func maxUpdateMode(vpa *vpa_types.VerticalPodAutoscaler, resourceMode func(core.ResourceName) vpa_types.UpdateMode) vpa_types.UpdateMode {
+	resources := []core.ResourceName{core.ResourceCPU, core.ResourceMemory}
+	if vpa.Spec.UpdatePolicy != nil {
+		for _, mode := range vpa.Spec.UpdatePolicy.ResourceUpdateModes {
+			resources = append(resources, mode.Resource)
+		}
+	}
+	result := vpa_types.UpdateModeOff
+	for _, resource := range resources {
+		if mode := resourceMode(resource); updateModeOrder[mode] > updateModeOrder[result] {
+			result = mode
+		}
+	}
+	return result
+}
+
+// FilterRecommendationByUpdateModes returns a copy of the recommendation only
+// with resources for which the update mode of a given VPA for the pod is one of
+// the given modes.
+func FilterRecommendationByUpdateModes(recommendation *vpa_types.RecommendedPodResources, vpa *vpa_types.VerticalPodAutoscaler, pod *core.Pod, modes ...vpa_types.UpdateMode) *vpa_types.RecommendedPodResources {
+	if recommendation == nil {
+		return nil
+	}
+	allowed := make(map[core.ResourceName]bool)
+	filter := func(resources core.ResourceList) core.ResourceList {
+		if resources == nil {
+			return nil
+		}
+		result := core.ResourceList{}
+		for resource, quantity := range resources {
+			isAllowed, found := allowed[resource]
+			if !found {
+				mode := GetPodResourceUpdateMode(vpa, pod, resource)
+				for _, allowedMode := range modes {
+					isAllowed = isAllowed || mode == allowedMode
+				}
+				allowed[resource] = isAllowed
+			}
+			if isAllowed {
+				result[resource] = quantity
+			}
+		}
+		return result
+	}
+	result := recommendation.DeepCopy()
+	for i := range result.ContainerRecommendations {
+		containerRecommendation := &result.ContainerRecommendations[i]
+		containerRecommendation.Target = filter(containerRecommendation.Target)
+		containerRecommendation.LowerBound = filter(containerRecommendation.LowerBound)
+		containerRecommendation.UpperBound = filter(containerRecommendation.UpperBound)
+		containerRecommendation.UncappedTarget = filter(containerRecommendation.UncappedTarget)
+	}
+	if podRecommendation := result.PodRecommendation; podRecommendation != nil {
+		podRecommendation.Target = filter(podRecommendation.Target)
+		podRecommendation.LowerBound = filter(podRecommendation.LowerBound)
+		podRecommendation.UpperBound = filter(podRecommendation.UpperBound)
+	}
+	return result
+}
+
+// isJobPod returns true iff the pod is controlled by a Job.
+func isJobPod(pod *core.Pod) bool {
+	owner := meta.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "Job" {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(owner.APIVersion)
+	return err == nil && gv.Group == "batch"
+}
+
 // GetContainerResourcePolicy returns the ContainerResourcePolicy for a given policy
 // and container name. It returns nil if there is no policy specified for the container.
 func GetContainerResourcePolicy(containerName string, policy *vpa_types.PodResourcePolicy) *vpa_types.ContainerResourcePolicy {
@@ -188,6 +188,129 @@ func TestGetControllingVPAForPod(t *testing.T) {
 	assert.Nil(t, chosen)
 }
 
+func TestGetPodUpdateMode(t *testing.T) {
+	isController := true
+	ownedPod := func(apiVersion, kind string) *core.Pod {
+		pod := test.Pod().WithName("test-pod").Get()
+		pod.OwnerReferences = []meta.OwnerReference{{APIVersion: apiVersion, Kind: kind, Name: "owner", Controller: &isController}}
+		return pod
+	}
+	jobPod := ownedPod("batch/v1", "Job")
+	stsPod := ownedPod("apps/v1", "StatefulSet")
+	otherJobPod := ownedPod("example.com/v1", "Job")
+
+	vpaBuilder := test.VerticalPodAutoscaler().WithContainer(containerName).WithUpdateMode(vpa_types.UpdateModeAuto)
+	testCases := []struct {
+		name     string
+		vpa      *vpa_types.VerticalPodAutoscaler
+		pod      *core.Pod
+		expected vpa_types.UpdateMode
+	}{
+		{
+			name:     "job pod without job update mode",
+			vpa:      vpaBuilder.Get(),
+			pod:      jobPod,
+			expected: vpa_types.UpdateModeAuto,
+		},
+		{
+			name:     "frozen job pod",
+			vpa:      vpaBuilder.WithJobUpdateMode(vpa_types.JobUpdateModeFrozen).Get(),
+			pod:      jobPod,
+			expected: vpa_types.UpdateModeInitial,
+		},
+		{
+			name:     "frozen job pod with update mode off",
+			vpa:      test.VerticalPodAutoscaler().WithContainer(containerName).WithUpdateMode(vpa_types.UpdateModeOff).WithJobUpdateMode(vpa_types.JobUpdateModeFrozen).Get(),
+			pod:      jobPod,
+			expected: vpa_types.UpdateModeInitial,
+		},
+		{
+			name:     "job pod with job update mode off",
+			vpa:      vpaBuilder.WithJobUpdateMode(vpa_types.JobUpdateModeOff).Get(),
+			pod:      jobPod,
+			expected: vpa_types.UpdateModeOff,
+		},
+		{
+			name:     "non-job pod",
+			vpa:      vpaBuilder.WithJobUpdateMode(vpa_types.JobUpdateModeFrozen).Get(),
+			pod:      stsPod,
+			expected: vpa_types.UpdateModeAuto,
+		},
+		{
+			name:     "pod of job from other group",
+			vpa:      vpaBuilder.WithJobUpdateMode(vpa_types.JobUpdateModeFrozen).Get(),
+			pod:      otherJobPod,
+			expected: vpa_types.UpdateModeAuto,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, GetPodUpdateMode(tc.vpa, tc.pod))
+		})
+	}
+}
+
+func TestResourceUpdateModes(t *testing.T) {
+	isController := true
+	jobPod := test.Pod().WithName("test-pod").Get()
+	jobPod.OwnerReferences = []meta.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "job", Controller: &isController}}
+	pod := test.Pod().WithName("test-pod").Get()
+
+	vpa := test.VerticalPodAutoscaler().WithContainer(containerName).
+		WithUpdateMode(vpa_types.UpdateModeInitial).
+		WithResourceUpdateMode(core.ResourceCPU, vpa_types.UpdateModeAuto).Get()
+	assert.Equal(t, vpa_types.UpdateModeAuto, GetResourceUpdateMode(vpa, core.ResourceCPU))
+	assert.Equal(t, vpa_types.UpdateModeInitial, GetResourceUpdateMode(vpa, core.ResourceMemory))
+	assert.Equal(t, vpa_types.UpdateModeAuto, GetMaxUpdateMode(vpa))
+	assert.Equal(t, vpa_types.UpdateModeAuto, GetPodUpdateMode(vpa, pod))
+
+	vpa = test.VerticalPodAutoscaler().WithContainer(containerName).
+		WithUpdateMode(vpa_types.UpdateModeOff).
+		WithResourceUpdateMode(core.ResourceMemory, vpa_types.UpdateModeInitial).Get()
+	assert.Equal(t, vpa_types.UpdateModeInitial, GetMaxUpdateMode(vpa))
+
+	vpa = test.VerticalPodAutoscaler().WithContainer(containerName).
+		WithUpdateMode(vpa_types.UpdateModeAuto).
+		WithResourceUpdateMode(core.ResourceCPU, vpa_types.UpdateModeOff).
+		WithResourceUpdateMode(core.ResourceMemory, vpa_types.UpdateModeOff).Get()
+	assert.Equal(t, vpa_types.UpdateModeOff, GetMaxUpdateMode(vpa))
+
+	// Resizing in place is less disruptive than recreating pods.
+	vpa = test.VerticalPodAutoscaler().WithContainer(containerName).
+		WithUpdateMode(vpa_types.UpdateModeInPlace).
+		WithResourceUpdateMode(core.ResourceMemory, vpa_types.UpdateModeInitial).Get()
+	assert.Equal(t, vpa_types.UpdateModeInPlace, GetPodUpdateMode(vpa, pod))
+	vpa = test.VerticalPodAutoscaler().WithContainer(containerName).
+		WithUpdateMode(vpa_types.UpdateModeInPlace).
+		WithResourceUpdateMode(core.ResourceMemory, vpa_types.UpdateModeRecreate).Get()
+	assert.Equal(t, vpa_types.UpdateModeRecreate, GetPodUpdateMode(vpa, pod))
+
+	// Frozen Job pods keep resources which are turned off.
+	vpa = test.VerticalPodAutoscaler().WithContainer(containerName).
+		WithUpdateMode(vpa_types.UpdateModeAuto).
+		WithResourceUpdateMode(core.ResourceMemory, vpa_types.UpdateModeOff).
+		WithJobUpdateMode(vpa_types.JobUpdateModeFrozen).Get()
+	assert.Equal(t, vpa_types.UpdateModeInitial, GetPodResourceUpdateMode(vpa, jobPod, core.ResourceCPU))
+	assert.Equal(t, vpa_types.UpdateModeOff, GetPodResourceUpdateMode(vpa, jobPod, core.ResourceMemory))
+	assert.Equal(t, vpa_types.UpdateModeAuto, GetPodResourceUpdateMode(vpa, pod, core.ResourceCPU))
+}
+
+func TestFilterRecommendationByUpdateModes(t *testing.T) {
+	pod := test.Pod().WithName("test-pod").Get()
+	recommendation := test.Recommendation().WithContainer(containerName).WithTarget("2", "200M").
+		WithLowerBound("1", "100M").WithUpperBound("3", "300M").Get()
+	vpa := test.VerticalPodAutoscaler().WithContainer(containerName).
+		WithUpdateMode(vpa_types.UpdateModeAuto).
+		WithResourceUpdateMode(core.ResourceMemory, vpa_types.UpdateModeInitial).Get()
+
+	filtered := FilterRecommendationByUpdateModes(recommendation, vpa, pod, vpa_types.UpdateModeRecreate, vpa_types.UpdateModeAuto)
+	assert.Equal(t, test.Recommendation().WithContainer(containerName).WithTarget("2", "").
+		WithLowerBound("1", "").WithUpperBound("3", "").Get(), filtered)
+	assert.Equal(t, recommendation, FilterRecommendationByUpdateModes(recommendation, vpa, pod,
+		vpa_types.UpdateModeInitial, vpa_types.UpdateModeRecreate, vpa_types.UpdateModeAuto))
+	assert.Nil(t, FilterRecommendationByUpdateModes(nil, vpa, pod, vpa_types.UpdateModeAuto))
+}
+
 func TestGetContainerResourcePolicy(t *testing.T) {
 	containerPolicy1 := vpa_types.ContainerResourcePolicy{
 		ContainerName: "container1",
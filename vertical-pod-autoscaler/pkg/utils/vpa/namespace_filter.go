@@ -0,0 +1,144 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	kube_client "k8s.io/client-go/kubernetes"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	vpa_lister "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/listers/autoscaling.k8s.io/v1"
+)
+
+// NewNamespacesLister returns NamespaceLister configured to fetch all Namespace objects.
+// The method blocks until namespaceLister is initially populated.
+func NewNamespacesLister(kubeClient kube_client.Interface, stopChannel <-chan struct{}) v1lister.NamespaceLister {
+	factory := informers.NewSharedInformerFactory(kubeClient, 1*time.Hour)
+	namespaceLister := factory.Core().V1().Namespaces().Lister()
+	factory.Start(stopChannel)
+	for informerType, synced := range factory.WaitForCacheSync(stopChannel) {
+		if !synced {
+			klog.Fatalf("Failed to sync %v cache during initialization", informerType)
+		}
+	}
+	klog.Info("Initial Namespaces synced successfully")
+	return namespaceLister
+}
+
+// WithIgnoredNamespaces wraps vpaLister so that VPA objects from namespaces with labels matching
+// ignoredNamespaceSelector are not visible. Returns vpaLister unchanged if the selector is empty.
+func WithIgnoredNamespaces(vpaLister vpa_lister.VerticalPodAutoscalerLister, kubeClient kube_client.Interface, stopChannel <-chan struct{}, ignoredNamespaceSelector string) (vpa_lister.VerticalPodAutoscalerLister, error) {
+	if ignoredNamespaceSelector == "" {
+		return vpaLister, nil
+	}
+	selector, err := labels.Parse(ignoredNamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ignored namespace selector %q: %v", ignoredNamespaceSelector, err)
+	}
+	return NewNamespaceFilteringVpasLister(vpaLister, NewNamespacesLister(kubeClient, stopChannel), selector), nil
+}
+
+// NewNamespaceFilteringVpasLister returns VerticalPodAutoscalerLister which hides VPA objects from namespaces
+// with labels matching ignoredNamespaceSelector. Namespace labels are evaluated on every call, so relabeling
+// a namespace takes effect without restarting the component.
+func NewNamespaceFilteringVpasLister(vpaLister vpa_lister.VerticalPodAutoscalerLister, namespaceLister v1lister.NamespaceLister, ignoredNamespaceSelector labels.Selector) vpa_lister.VerticalPodAutoscalerLister {
+	return &namespaceFilteringVpasLister{
+		vpaLister:                vpaLister,
+		namespaceLister:          namespaceLister,
+		ignoredNamespaceSelector: ignoredNamespaceSelector,
+	}
+}
+
+type namespaceFilteringVpasLister struct {
+	vpaLister                vpa_lister.VerticalPodAutoscalerLister
+	namespaceLister          v1lister.NamespaceLister
+	ignoredNamespaceSelector labels.Selector
+}
+
+// List lists all VerticalPodAutoscalers outside of ignored namespaces.
+func (l *namespaceFilteringVpasLister) List(selector labels.Selector) ([]*vpa_types.VerticalPodAutoscaler, error) {
+	vpas, err := l.vpaLister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return l.filter(vpas), nil
+}
+
+// VerticalPodAutoscalers returns an object that can list and get VerticalPodAutoscalers from a namespace.
+func (l *namespaceFilteringVpasLister) VerticalPodAutoscalers(namespace string) vpa_lister.VerticalPodAutoscalerNamespaceLister {
+	return &namespaceFilteringVpasNamespaceLister{
+		parent:      l,
+		namespace:   namespace,
+		nsVpaLister: l.vpaLister.VerticalPodAutoscalers(namespace),
+	}
+}
+
+func (l *namespaceFilteringVpasLister) filter(vpas []*vpa_types.VerticalPodAutoscaler) []*vpa_types.VerticalPodAutoscaler {
+	ignored := make(map[string]bool)
+	result := make([]*vpa_types.VerticalPodAutoscaler, 0, len(vpas))
+	for _, vpa := range vpas {
+		isIgnored, found := ignored[vpa.Namespace]
+		if !found {
+			isIgnored = l.isIgnored(vpa.Namespace)
+			ignored[vpa.Namespace] = isIgnored
+		}
+		if isIgnored {
+			klog.V(6).Infof("Ignoring VPA %s as its namespace matches the ignored namespace selector", klog.KObj(vpa))
+			continue
+		}
+		result = append(result, vpa)
+	}
+	return result
+}
+
+func (l *namespaceFilteringVpasLister) isIgnored(namespace string) bool {
+	ns, err := l.namespaceLister.Get(namespace)
+	if err != nil {
+		klog.V(4).Infof("Cannot get namespace %s, assuming it isn't ignored: %v", namespace, err)
+		return false
+	}
+	return l.ignoredNamespaceSelector.Matches(labels.Set(ns.Labels))
+}
+
+type namespaceFilteringVpasNamespaceLister struct {
+	parent      *namespaceFilteringVpasLister
+	namespace   string
+	nsVpaLister vpa_lister.VerticalPodAutoscalerNamespaceLister
+}
+
+// List lists all VerticalPodAutoscalers in the namespace, unless the namespace is ignored.
+func (l *namespaceFilteringVpasNamespaceLister) List(selector labels.Selector) ([]*vpa_types.VerticalPodAutoscaler, error) {
+	if l.parent.isIgnored(l.namespace) {
+		return []*vpa_types.VerticalPodAutoscaler{}, nil
+	}
+	return l.nsVpaLister.List(selector)
+}
+
+// Get retrieves the VerticalPodAutoscaler from the namespace, unless the namespace is ignored.
+func (l *namespaceFilteringVpasNamespaceLister) Get(name string) (*vpa_types.VerticalPodAutoscaler, error) {
+	if l.parent.isIgnored(l.namespace) {
+		return nil, apierrors.NewNotFound(vpa_types.Resource("verticalpodautoscaler"), name)
+	}
+	return l.nsVpaLister.Get(name)
+}
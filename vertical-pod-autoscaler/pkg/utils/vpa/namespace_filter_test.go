@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1lister "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	vpa_lister "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/listers/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+)
+
+func TestNamespaceFilteringVpasLister(t *testing.T) {
+	vpaIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+
+	platformNamespace := &core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "platform", Labels: map[string]string{"team": "platform"}}}
+	appNamespace := &core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "app", Labels: map[string]string{"team": "app"}}}
+	assert.NoError(t, namespaceIndexer.Add(platformNamespace))
+	assert.NoError(t, namespaceIndexer.Add(appNamespace))
+
+	platformVpa := test.VerticalPodAutoscaler().WithName("platform-vpa").WithNamespace("platform").WithContainer(containerName).Get()
+	appVpa := test.VerticalPodAutoscaler().WithName("app-vpa").WithNamespace("app").WithContainer(containerName).Get()
+	unknownNamespaceVpa := test.VerticalPodAutoscaler().WithName("other-vpa").WithNamespace("unknown").WithContainer(containerName).Get()
+	for _, vpa := range []*vpa_types.VerticalPodAutoscaler{platformVpa, appVpa, unknownNamespaceVpa} {
+		assert.NoError(t, vpaIndexer.Add(vpa))
+	}
+
+	selector, err := labels.Parse("team=platform")
+	assert.NoError(t, err)
+	lister := NewNamespaceFilteringVpasLister(vpa_lister.NewVerticalPodAutoscalerLister(vpaIndexer), v1lister.NewNamespaceLister(namespaceIndexer), selector)
+
+	vpas, err := lister.List(labels.Everything())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*vpa_types.VerticalPodAutoscaler{appVpa, unknownNamespaceVpa}, vpas)
+
+	vpas, err = lister.VerticalPodAutoscalers("platform").List(labels.Everything())
+	assert.NoError(t, err)
+	assert.Empty(t, vpas)
+	_, err = lister.VerticalPodAutoscalers("platform").Get("platform-vpa")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	vpa, err := lister.VerticalPodAutoscalers("app").Get("app-vpa")
+	assert.NoError(t, err)
+	assert.Equal(t, appVpa, vpa)
+
+	// Namespace labels are evaluated dynamically.
+	relabeledAppNamespace := appNamespace.DeepCopy()
+	relabeledAppNamespace.Labels["team"] = "platform"
+	assert.NoError(t, namespaceIndexer.Update(relabeledAppNamespace))
+	vpas, err = lister.List(labels.Everything())
+	assert.NoError(t, err)
+	assert.Equal(t, []*vpa_types.VerticalPodAutoscaler{unknownNamespaceVpa}, vpas)
+	vpas, err = lister.VerticalPodAutoscalers("app").List(labels.Everything())
+	assert.NoError(t, err)
+	assert.Empty(t, vpas)
+}
+
+func TestWithIgnoredNamespaces(t *testing.T) {
+	vpaLister := vpa_lister.NewVerticalPodAutoscalerLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+
+	lister, err := WithIgnoredNamespaces(vpaLister, nil, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, vpaLister, lister)
+
+	_, err = WithIgnoredNamespaces(vpaLister, nil, nil, "team in (platform")
+	assert.Error(t, err)
+}
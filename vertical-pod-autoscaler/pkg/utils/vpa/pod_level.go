@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+)
+
+// GetPodLevelScalingMode returns the pod-level scaling mode from the policy, "Off" if not set.
+func GetPodLevelScalingMode(policy *vpa_types.PodResourcePolicy) vpa_types.PodLevelScalingMode {
+	if policy == nil || policy.PodLevelPolicy == nil || policy.PodLevelPolicy.Mode == nil {
+		return vpa_types.PodLevelScalingModeOff
+	}
+	return *policy.PodLevelPolicy.Mode
+}
+
+// GetContainerSplitPolicy returns the container split policy from the policy, "PerContainer" if not set.
+func GetContainerSplitPolicy(policy *vpa_types.PodResourcePolicy) vpa_types.ContainerSplitPolicy {
+	if policy == nil || policy.PodLevelPolicy == nil || policy.PodLevelPolicy.ContainerSplitPolicy == nil {
+		return vpa_types.ContainerSplitPolicyPerContainer
+	}
+	return *policy.PodLevelPolicy.ContainerSplitPolicy
+}
+
+// GetPodLevelRecommendation sums container recommendations into a pod-level recommendation.
+// Returns nil if there are no container recommendations.
+func GetPodLevelRecommendation(recommendation *vpa_types.RecommendedPodResources) *vpa_types.RecommendedPodLevelResources {
+	if recommendation == nil || len(recommendation.ContainerRecommendations) == 0 {
+		return nil
+	}
+	result := &vpa_types.RecommendedPodLevelResources{
+		Target:     apiv1.ResourceList{},
+		LowerBound: apiv1.ResourceList{},
+		UpperBound: apiv1.ResourceList{},
+	}
+	for _, containerRecommendation := range recommendation.ContainerRecommendations {
+		addResources(result.Target, containerRecommendation.Target)
+		addResources(result.LowerBound, containerRecommendation.LowerBound)
+		addResources(result.UpperBound, containerRecommendation.UpperBound)
+	}
+	return result
+}
+
+func addResources(sum, resources apiv1.ResourceList) {
+	for resourceName, quantity := range resources {
+		current, found := sum[resourceName]
+		if !found {
+			sum[resourceName] = quantity.DeepCopy()
+			continue
+		}
+		current.Add(quantity)
+		sum[resourceName] = current
+	}
+}
+
+// NewPodLevelSplitRecommendationProcessor constructs RecommendationProcessor that splits the pod-level
+// recommendation between containers according to the container split policy.
+func NewPodLevelSplitRecommendationProcessor() RecommendationProcessor {
+	return &podLevelSplitRecommendationProcessor{}
+}
+
+type podLevelSplitRecommendationProcessor struct{}
+
+// Apply splits the sum of container recommendations proportionally to current container requests,
+// if the VPA uses the pod-level recommendation with the ProportionalToRequests split policy.
+// Resources not requested by all recommended containers are left unchanged.
+func (p *podLevelSplitRecommendationProcessor) Apply(podRecommendation *vpa_types.RecommendedPodResources,
+	policy *vpa_types.PodResourcePolicy,
+	conditions []vpa_types.VerticalPodAutoscalerCondition,
+	pod *apiv1.Pod) (*vpa_types.RecommendedPodResources, ContainerToAnnotationsMap, error) {
+	if podRecommendation == nil ||
+		GetPodLevelScalingMode(policy) != vpa_types.PodLevelScalingModeAuto ||
+		GetContainerSplitPolicy(policy) != vpa_types.ContainerSplitPolicyProportionalToRequests {
+		return podRecommendation, nil, nil
+	}
+
+	updatedRecommendation := podRecommendation.DeepCopy()
+	containers := make([]*apiv1.Container, len(updatedRecommendation.ContainerRecommendations))
+	for i, containerRecommendation := range updatedRecommendation.ContainerRecommendations {
+		containers[i] = getContainer(containerRecommendation.ContainerName, pod)
+	}
+	annotations := ContainerToAnnotationsMap{}
+	for _, resourceName := range []apiv1.ResourceName{apiv1.ResourceCPU, apiv1.ResourceMemory} {
+		weights, ok := requestWeights(containers, resourceName)
+		if !ok {
+			continue
+		}
+		if !splitProportionally(updatedRecommendation.ContainerRecommendations, weights, resourceName,
+			func(r *vpa_types.RecommendedContainerResources) apiv1.ResourceList { return r.Target }) {
+			continue
+		}
+		for _, containerRecommendation := range updatedRecommendation.ContainerRecommendations {
+			annotations[containerRecommendation.ContainerName] = append(annotations[containerRecommendation.ContainerName],
+				fmt.Sprintf("%s split proportionally to requests", resourceName))
+		}
+		splitProportionally(updatedRecommendation.ContainerRecommendations, weights, resourceName,
+			func(r *vpa_types.RecommendedContainerResources) apiv1.ResourceList { return r.LowerBound })
+		splitProportionally(updatedRecommendation.ContainerRecommendations, weights, resourceName,
+			func(r *vpa_types.RecommendedContainerResources) apiv1.ResourceList { return r.UpperBound })
+	}
+	return updatedRecommendation, annotations, nil
+}
+
+// requestWeights returns shares of the containers in their total request for the resource.
+// Returns false if any of the containers doesn't exist in the pod or doesn't request the resource.
+func requestWeights(containers []*apiv1.Container, resourceName apiv1.ResourceName) ([]float64, bool) {
+	weights := make([]float64, len(containers))
+	total := 0.0
+	for i, container := range containers {
+		if container == nil {
+			return nil, false
+		}
+		request, found := container.Resources.Requests[resourceName]
+		if !found || request.MilliValue() <= 0 {
+			return nil, false
+		}
+		weights[i] = float64(request.MilliValue())
+		total += weights[i]
+	}
+	if total == 0 {
+		return nil, false
+	}
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights, true
+}
+
+// splitProportionally splits the sum of the resource across recommendations using weights.
+// Returns false if not all recommendations have a value for the resource.
+func splitProportionally(recommendations []vpa_types.RecommendedContainerResources, weights []float64, resourceName apiv1.ResourceName,
+	resources func(*vpa_types.RecommendedContainerResources) apiv1.ResourceList) bool {
+	total := 0.0
+	for i := range recommendations {
+		quantity, found := resources(&recommendations[i])[resourceName]
+		if !found {
+			return false
+		}
+		total += float64(quantity.MilliValue())
+	}
+	for i := range recommendations {
+		resources(&recommendations[i])[resourceName] = quantityFromMilli(resourceName, total*weights[i])
+	}
+	return true
+}
+
+func quantityFromMilli(resourceName apiv1.ResourceName, milliValue float64) resource.Quantity {
+	if resourceName == apiv1.ResourceCPU {
+		return *resource.NewMilliQuantity(int64(milliValue), resource.DecimalSI)
+	}
+	return *resource.NewQuantity(int64(milliValue/1000), resource.BinarySI)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	vpa_types "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
+	"k8s.io/autoscaler/vertical-pod-autoscaler/pkg/utils/test"
+)
+
+func TestGetPodLevelRecommendation(t *testing.T) {
+	assert.Nil(t, GetPodLevelRecommendation(nil))
+	assert.Nil(t, GetPodLevelRecommendation(&vpa_types.RecommendedPodResources{}))
+
+	recommendation := &vpa_types.RecommendedPodResources{
+		ContainerRecommendations: []vpa_types.RecommendedContainerResources{
+			{
+				ContainerName: "c1",
+				Target:        test.Resources("1", "1Gi"),
+				LowerBound:    test.Resources("500m", "512Mi"),
+				UpperBound:    test.Resources("2", "2Gi"),
+			},
+			{
+				ContainerName: "c2",
+				Target:        test.Resources("200m", "1Gi"),
+				LowerBound:    test.Resources("100m", "512Mi"),
+				UpperBound:    test.Resources("400m", "2Gi"),
+			},
+		},
+	}
+	podLevel := GetPodLevelRecommendation(recommendation)
+	assert.Equal(t, int64(1200), podLevel.Target.Cpu().MilliValue())
+	assert.Equal(t, int64(2*1024*1024*1024), podLevel.Target.Memory().Value())
+	assert.Equal(t, int64(600), podLevel.LowerBound.Cpu().MilliValue())
+	assert.Equal(t, int64(1024*1024*1024), podLevel.LowerBound.Memory().Value())
+	assert.Equal(t, int64(2400), podLevel.UpperBound.Cpu().MilliValue())
+	assert.Equal(t, int64(4*1024*1024*1024), podLevel.UpperBound.Memory().Value())
+	// Container recommendations are not modified.
+	assert.Equal(t, int64(1000), recommendation.ContainerRecommendations[0].Target.Cpu().MilliValue())
+}
+
+func TestPodLevelSplitRecommendationProcessor(t *testing.T) {
+	podLevelAuto := vpa_types.PodLevelScalingModeAuto
+	perContainer := vpa_types.ContainerSplitPolicyPerContainer
+	proportional := vpa_types.ContainerSplitPolicyProportionalToRequests
+
+	pod := test.Pod().WithName("pod").
+		AddContainer(test.Container().WithName("c1").WithCPURequest(resource.MustParse("300m")).WithMemRequest(resource.MustParse("1Gi")).Get()).
+		AddContainer(test.Container().WithName("c2").WithCPURequest(resource.MustParse("100m")).Get()).
+		Get()
+	recommendation := &vpa_types.RecommendedPodResources{
+		ContainerRecommendations: []vpa_types.RecommendedContainerResources{
+			{
+				ContainerName: "c1",
+				Target:        test.Resources("1", "1Gi"),
+			},
+			{
+				ContainerName: "c2",
+				Target:        test.Resources("1", "1Gi"),
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name        string
+		policy      *vpa_types.PodResourcePolicy
+		wantSplit   bool
+		wantTargets []apiv1.ResourceList
+	}{
+		{
+			name:   "no policy",
+			policy: nil,
+		},
+		{
+			name: "per container split",
+			policy: &vpa_types.PodResourcePolicy{
+				PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{Mode: &podLevelAuto, ContainerSplitPolicy: &perContainer},
+			},
+		},
+		{
+			name: "proportional split without pod-level mode",
+			policy: &vpa_types.PodResourcePolicy{
+				PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{ContainerSplitPolicy: &proportional},
+			},
+		},
+		{
+			name: "proportional split",
+			policy: &vpa_types.PodResourcePolicy{
+				PodLevelPolicy: &vpa_types.PodLevelResourcePolicy{Mode: &podLevelAuto, ContainerSplitPolicy: &proportional},
+			},
+			wantSplit: true,
+			// CPU is split 3:1, memory isn't requested by c2 so it's not split.
+			wantTargets: []apiv1.ResourceList{test.Resources("1500m", "1Gi"), test.Resources("500m", "1Gi")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			processor := NewPodLevelSplitRecommendationProcessor()
+			processed, annotations, err := processor.Apply(recommendation, tc.policy, nil, pod)
+			assert.NoError(t, err)
+			if !tc.wantSplit {
+				assert.Equal(t, recommendation, processed)
+				assert.Empty(t, annotations)
+				return
+			}
+			for i, want := range tc.wantTargets {
+				got := processed.ContainerRecommendations[i].Target
+				assert.Equal(t, want.Cpu().MilliValue(), got.Cpu().MilliValue())
+				assert.Equal(t, want.Memory().Value(), got.Memory().Value())
+			}
+			assert.Equal(t, []string{"cpu split proportionally to requests"}, annotations["c1"])
+			// Input recommendation is not modified.
+			assert.Equal(t, int64(1000), recommendation.ContainerRecommendations[0].Target.Cpu().MilliValue())
+		})
+	}
+}